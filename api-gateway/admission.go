@@ -0,0 +1,250 @@
+package main
+
+import (
+	"container/list"
+	"expvar"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"api-gateway/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Payment admission metrics, exposed on /debug/vars and /api/v1/admin/runtime
+var (
+	admissionAdmitted = expvar.NewInt("payment_admission_admitted")
+	admissionQueued   = expvar.NewInt("payment_admission_queued")
+	admissionRejected = expvar.NewInt("payment_admission_rejected")
+	admissionTimedOut = expvar.NewInt("payment_admission_timed_out")
+	admissionWaitMs   = expvar.NewInt("payment_admission_wait_ms_total")
+)
+
+// paymentAdmission is configured in main, nil when admission control is disabled
+var paymentAdmission *admissionQueue
+
+// admissionQueue lets at most concurrency payment creations reach payment-service at once.
+// Requests beyond that wait in a FIFO queue of at most capacity for up to maxWait, and are
+// answered 503 with Retry-After when the queue is full or their wait runs out, so a flash
+// sale doesn't turn into a thundering herd on payment-service and Midtrans.
+type admissionQueue struct {
+	concurrency int
+	capacity    int
+	maxWait     time.Duration
+
+	mu       sync.Mutex
+	inFlight int
+	waiting  *list.List    // chan struct{} per queued request, closed when it is admitted
+	avgTime  time.Duration // moving average of upstream time, for Retry-After estimates
+}
+
+// newAdmissionQueue reads PAYMENT_ADMISSION_CONCURRENCY, PAYMENT_ADMISSION_QUEUE_SIZE and
+// PAYMENT_ADMISSION_MAX_WAIT. It returns nil (admission control disabled) when no
+// concurrency is configured.
+func newAdmissionQueue() *admissionQueue {
+	concurrency, err := strconv.Atoi(os.Getenv("PAYMENT_ADMISSION_CONCURRENCY"))
+	if err != nil || concurrency <= 0 {
+		return nil
+	}
+	capacity := 100
+	if value := os.Getenv("PAYMENT_ADMISSION_QUEUE_SIZE"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 {
+			capacity = parsed
+		} else {
+			log.Printf("⚠️ Ignoring invalid PAYMENT_ADMISSION_QUEUE_SIZE=%q, using %d", value, capacity)
+		}
+	}
+
+	q := &admissionQueue{
+		concurrency: concurrency,
+		capacity:    capacity,
+		maxWait:     getEnvDuration("PAYMENT_ADMISSION_MAX_WAIT", 10*time.Second),
+		waiting:     list.New(),
+		avgTime:     time.Second,
+	}
+	log.Printf("✅ Payment admission control enabled (concurrency: %d, queue: %d, max wait: %s)", q.concurrency, q.capacity, q.maxWait)
+	return q
+}
+
+// admitPaymentCreation queues POST requests when payment admission control is enabled, other
+// methods go straight through
+func admitPaymentCreation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if paymentAdmission == nil || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		paymentAdmission.handle(c)
+	}
+}
+
+// handle admits the request, queueing it when payment-service is at its concurrency.
+// Queued requests are answered with X-Queue-Position, their position on arrival.
+func (q *admissionQueue) handle(c *gin.Context) {
+	arrived := time.Now()
+
+	q.mu.Lock()
+	if q.inFlight < q.concurrency {
+		q.inFlight++
+		q.mu.Unlock()
+		q.run(c, arrived)
+		return
+	}
+	if q.waiting.Len() >= q.capacity {
+		queued := q.waiting.Len()
+		retryAfter := q.estimateLocked(queued + 1)
+		q.mu.Unlock()
+
+		admissionRejected.Add(1)
+		q.reject(c, retryAfter, i18n.Error(c, "PAYMENT_SERVICE_BUSY", gin.H{
+			"success":      false,
+			"details":      "too many payments are being created, please retry later",
+			"queue_length": queued,
+		}))
+		return
+	}
+	admitted := make(chan struct{})
+	element := q.waiting.PushBack(admitted)
+	position := q.waiting.Len()
+	q.mu.Unlock()
+
+	admissionQueued.Add(1)
+	c.Header("X-Queue-Position", strconv.Itoa(position))
+
+	timer := time.NewTimer(q.maxWait)
+	defer timer.Stop()
+
+	select {
+	case <-admitted:
+		q.run(c, arrived)
+	case <-timer.C:
+		if q.leave(element, admitted) {
+			admissionTimedOut.Add(1)
+			q.reject(c, q.estimate(position), i18n.Error(c, "PAYMENT_SERVICE_BUSY", gin.H{
+				"success":        false,
+				"details":        "the payment wasn't created, please retry later",
+				"queue_position": position,
+			}))
+			return
+		}
+		q.run(c, arrived) // admitted while timing out
+	case <-c.Request.Context().Done():
+		if !q.leave(element, admitted) {
+			q.release(0) // admitted while the client went away, pass the slot on
+		}
+		c.Abort()
+	}
+}
+
+// run forwards an admitted request and then lets the next one in
+func (q *admissionQueue) run(c *gin.Context, arrived time.Time) {
+	admissionAdmitted.Add(1)
+	wait := time.Since(arrived)
+	admissionWaitMs.Add(wait.Milliseconds())
+	if wait > time.Millisecond {
+		c.Header("X-Queue-Wait", wait.Round(time.Millisecond).String())
+	}
+
+	started := time.Now()
+	defer func() {
+		q.release(time.Since(started))
+	}()
+	c.Next()
+}
+
+// release frees the slot of a finished request (taking upstream elapsed) by handing it to
+// the oldest queued request
+func (q *admissionQueue) release(elapsed time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if elapsed > 0 {
+		q.avgTime = (q.avgTime*9 + elapsed) / 10
+	}
+	if front := q.waiting.Front(); front != nil {
+		q.waiting.Remove(front)
+		close(front.Value.(chan struct{}))
+		return
+	}
+	q.inFlight--
+}
+
+// leave removes a request that stopped waiting from the queue. It reports false when the
+// request was admitted meanwhile and now holds a slot.
+func (q *admissionQueue) leave(element *list.Element, admitted chan struct{}) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case <-admitted:
+		return false
+	default:
+	}
+	q.waiting.Remove(element)
+	return true
+}
+
+// estimate guesses how long until a request at position would be admitted
+func (q *admissionQueue) estimate(position int) time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.estimateLocked(position)
+}
+
+func (q *admissionQueue) estimateLocked(position int) time.Duration {
+	return time.Duration(position) * q.avgTime / time.Duration(q.concurrency)
+}
+
+// reject answers 503 with Retry-After in whole seconds, at least one
+func (q *admissionQueue) reject(c *gin.Context, retryAfter time.Duration, body gin.H) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	body["retry_after"] = seconds
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, body)
+}
+
+// gatewayStats reports hedging and admission control for the admin runtime endpoint
+func gatewayStats() gin.H {
+	stats := hedgingStats()
+	for key, value := range admissionStats() {
+		stats[key] = value
+	}
+	return stats
+}
+
+// admissionStats reports the admission queue for the admin runtime endpoint
+func admissionStats() gin.H {
+	if paymentAdmission == nil {
+		return gin.H{"payment_admission": gin.H{"enabled": false}}
+	}
+
+	q := paymentAdmission
+	q.mu.Lock()
+	inFlight, queued, avgTime := q.inFlight, q.waiting.Len(), q.avgTime
+	q.mu.Unlock()
+
+	return gin.H{
+		"payment_admission": gin.H{
+			"enabled":       true,
+			"concurrency":   q.concurrency,
+			"queue_size":    q.capacity,
+			"max_wait":      q.maxWait.String(),
+			"in_flight":     inFlight,
+			"queued":        queued,
+			"avg_upstream":  avgTime.Round(time.Millisecond).String(),
+			"admitted":      admissionAdmitted.Value(),
+			"queued_total":  admissionQueued.Value(),
+			"rejected_full": admissionRejected.Value(),
+			"timed_out":     admissionTimedOut.Value(),
+			"wait_ms_total": admissionWaitMs.Value(),
+		},
+	}
+}
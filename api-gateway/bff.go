@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway/httpclient"
+	"api-gateway/i18n"
+	"api-gateway/retry"
+	"api-gateway/serviceauth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Outcome of a BFF dependency, reported per dependency in the response
+const (
+	bffOK            = "ok"
+	bffTimeout       = "timeout"
+	bffFailed        = "failed"
+	bffNotConfigured = "not_configured"
+	bffSkipped       = "skipped" // e.g. the wishlist of an anonymous viewer
+)
+
+// bffDependency is an upstream endpoint composed into a BFF payload. URL templates
+// contain {id} for the product ID.
+type bffDependency struct {
+	name     string
+	url      string
+	timeout  time.Duration
+	audience string // service token audience of our own services, empty for others
+}
+
+// productPageBFF composes the product detail page: the product (required), its reviews
+// summary and whether the viewer wishlisted it (both optional, dropped when slow or failing)
+type productPageBFF struct {
+	product  bffDependency
+	reviews  bffDependency
+	wishlist bffDependency
+	client   *http.Client
+}
+
+// bffResult is the outcome of fetching one dependency
+type bffResult struct {
+	status int
+	data   json.RawMessage
+	state  string
+}
+
+// newProductPageBFF reads BFF_REVIEWS_SUMMARY_URL, BFF_WISHLIST_URL and the per dependency
+// BFF_PRODUCT_TIMEOUT, BFF_REVIEWS_TIMEOUT and BFF_WISHLIST_TIMEOUT
+func newProductPageBFF() *productPageBFF {
+	return &productPageBFF{
+		product: bffDependency{
+			name:     "product",
+			url:      ProductServiceURL + "/api/v1/products/{id}",
+			timeout:  getEnvDuration("BFF_PRODUCT_TIMEOUT", 3*time.Second),
+			audience: serviceauth.ProductService,
+		},
+		reviews: bffDependency{
+			name:    "reviews",
+			url:     os.Getenv("BFF_REVIEWS_SUMMARY_URL"),
+			timeout: getEnvDuration("BFF_REVIEWS_TIMEOUT", 800*time.Millisecond),
+		},
+		wishlist: bffDependency{
+			name:    "wishlist",
+			url:     os.Getenv("BFF_WISHLIST_URL"),
+			timeout: getEnvDuration("BFF_WISHLIST_TIMEOUT", 800*time.Millisecond),
+		},
+		client: httpclient.New("bff", retry.Policy{MaxAttempts: 1}).Client, // Bounded by the per dependency context
+	}
+}
+
+// ProductPage handles GET /api/v1/bff/product/:id. The dependencies are fetched
+// concurrently; only a missing product fails the request.
+func (b *productPageBFF) ProductPage(c *gin.Context) {
+	productID := c.Param("id")
+	userID, authenticated := c.Get("user_id")
+
+	var product, reviews, wishlist bffResult
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		product = b.fetch(c, b.product, productID, "")
+	}()
+	go func() {
+		defer wg.Done()
+		reviews = b.fetch(c, b.reviews, productID, "")
+	}()
+	go func() {
+		defer wg.Done()
+		if !authenticated {
+			wishlist = bffResult{state: bffSkipped}
+			return
+		}
+		wishlist = b.fetch(c, b.wishlist, productID, userID.(string))
+	}()
+	wg.Wait()
+
+	if product.state != bffOK {
+		switch {
+		case product.status == http.StatusNotFound || product.status == http.StatusBadRequest:
+			c.JSON(product.status, i18n.Error(c, "PRODUCT_NOT_FOUND", gin.H{"success": false}))
+		case product.state == bffTimeout:
+			c.JSON(http.StatusGatewayTimeout, i18n.Error(c, "PRODUCT_SERVICE_TIMEOUT", gin.H{"success": false}))
+		default:
+			c.JSON(http.StatusBadGateway, i18n.Error(c, "PRODUCT_SERVICE_UNAVAILABLE", gin.H{"success": false}))
+		}
+		return
+	}
+
+	var degraded []string
+	if reviews.state != bffOK && reviews.state != bffNotConfigured {
+		degraded = append(degraded, b.reviews.name)
+	}
+	if wishlist.state != bffOK && wishlist.state != bffNotConfigured && wishlist.state != bffSkipped {
+		degraded = append(degraded, b.wishlist.name)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"product":    product.data,
+			"reviews":    reviews.data,
+			"wishlisted": wishlistFlag(wishlist.data),
+		},
+		"meta": gin.H{
+			"partial":  len(degraded) > 0,
+			"degraded": degraded,
+			"sources": gin.H{
+				b.product.name:  product.state,
+				b.reviews.name:  reviews.state,
+				b.wishlist.name: wishlist.state,
+			},
+		},
+	})
+}
+
+// fetch GETs a dependency within its timeout and unwraps the {"data": ...} envelope
+func (b *productPageBFF) fetch(c *gin.Context, dep bffDependency, productID, userID string) bffResult {
+	if dep.url == "" {
+		return bffResult{state: bffNotConfigured}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), dep.timeout)
+	defer cancel()
+
+	target := strings.ReplaceAll(dep.url, "{id}", url.PathEscape(productID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		log.Printf("⚠️ BFF %s request failed: %v", dep.name, err)
+		return bffResult{state: bffFailed}
+	}
+	req.Header.Set("Accept", "application/json")
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	if dep.audience != "" && serviceIssuer != nil {
+		if err := serviceIssuer.Sign(req, dep.audience); err != nil {
+			log.Printf("⚠️ BFF %s request failed: %v", dep.name, err)
+			return bffResult{state: bffFailed}
+		}
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			log.Printf("⚠️ BFF %s timed out after %s", dep.name, dep.timeout)
+			return bffResult{state: bffTimeout}
+		}
+		log.Printf("⚠️ BFF %s unavailable: %v", dep.name, err)
+		return bffResult{state: bffFailed}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return bffResult{status: resp.StatusCode, state: bffFailed}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return bffResult{status: resp.StatusCode, state: bffFailed}
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		log.Printf("⚠️ BFF %s returned invalid JSON: %v", dep.name, err)
+		return bffResult{status: resp.StatusCode, state: bffFailed}
+	}
+	if len(envelope.Data) == 0 {
+		envelope.Data = body
+	}
+	return bffResult{status: resp.StatusCode, data: envelope.Data, state: bffOK}
+}
+
+// wishlistFlag reads the wishlist answer, either a bare boolean or {"wishlisted": bool}.
+// nil means unknown (anonymous viewer or wishlist unavailable).
+func wishlistFlag(data json.RawMessage) *bool {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var flag bool
+	if err := json.Unmarshal(data, &flag); err == nil {
+		return &flag
+	}
+	var wrapped struct {
+		Wishlisted *bool `json:"wishlisted"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err == nil {
+		return wrapped.Wishlisted
+	}
+	return nil
+}
+
+// getEnvDuration reads a duration environment variable with a default
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+		log.Printf("⚠️ Ignoring invalid %s=%q, using %s", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// String describes the dependency for startup logs
+func (d bffDependency) String() string {
+	if d.url == "" {
+		return fmt.Sprintf("%s: not configured", d.name)
+	}
+	return fmt.Sprintf("%s: %s (timeout %s)", d.name, d.url, d.timeout)
+}
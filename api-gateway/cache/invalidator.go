@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// productUpdatedEvent is the part of the product.updated event the gateway needs
+type productUpdatedEvent struct {
+	Type string `json:"type"`
+	Data struct {
+		ProductID string `json:"product_id"`
+	} `json:"data"`
+}
+
+// Invalidator listens for product.updated events published by Product-Service
+// and drops the matching gateway cache entries
+type Invalidator struct {
+	cache *ResponseCache
+	conn  *amqp.Connection
+}
+
+// StartInvalidator connects to RabbitMQ and starts consuming product.updated events.
+// Entries still expire after their GATEWAY_CACHE_TTL_* when no broker is available.
+func StartInvalidator(rc *ResponseCache) (*Invalidator, error) {
+	if rc == nil {
+		return nil, nil
+	}
+
+	if os.Getenv("EVENT_BUS") == "kafka" {
+		return nil, fmt.Errorf("cache invalidation events are only consumed from RabbitMQ")
+	}
+
+	host := getEnv("RABBITMQ_HOST", "localhost")
+	port := getEnv("RABBITMQ_PORT", "5672")
+	username := getEnv("RABBITMQ_USERNAME", "admin")
+	password := getEnv("RABBITMQ_PASSWORD", "secret123")
+
+	conn, err := amqp.Dial(fmt.Sprintf("amqp://%s:%s@%s:%s/", username, password, host, port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	if err := ch.ExchangeDeclare("product.events", "topic", true, false, false, false, nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	// Every gateway instance holds its own queue so each one sees every event
+	queue, err := ch.QueueDeclare(
+		"",    // name (server generated)
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	if err := ch.QueueBind(queue.Name, "product.updated", "product.events", false, nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to bind queue: %w", err)
+	}
+
+	msgs, err := ch.Consume(queue.Name, "", true, true, false, false, nil)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to register consumer: %w", err)
+	}
+
+	inv := &Invalidator{
+		cache: rc,
+		conn:  conn,
+	}
+
+	go func() {
+		for msg := range msgs {
+			inv.handle(msg.Body)
+		}
+		log.Println("⚠️ Gateway cache invalidator stopped, entries now expire by TTL only")
+	}()
+
+	log.Println("🚀 Gateway cache invalidator listening for product.updated events")
+	return inv, nil
+}
+
+// handle invalidates the product referenced by a product.updated event
+func (inv *Invalidator) handle(body []byte) {
+	var event productUpdatedEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.Data.ProductID == "" {
+		log.Printf("❌ Invalid product.updated event: %s", string(body))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	inv.cache.InvalidateProduct(ctx, event.Data.ProductID)
+	log.Printf("🧹 Gateway cache invalidated for product %s", event.Data.ProductID)
+}
+
+// Close closes the RabbitMQ connection
+func (inv *Invalidator) Close() error {
+	if inv == nil {
+		return nil
+	}
+	return inv.conn.Close()
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,420 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"api-gateway/redisconn"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces gateway entries in a Redis instance shared with the services
+const keyPrefix = "gateway:cache:"
+
+// ResponseCache caches public GET responses proxied by the gateway in Redis
+type ResponseCache struct {
+	client redis.UniversalClient
+	ttls   TTLs
+	jitter float64
+
+	hits    [2]atomic.Int64 // per namespace, indexed like Namespaces
+	misses  [2]atomic.Int64
+	started time.Time
+}
+
+// Namespaces are the gateway's cached responses: product lists and details, and store pages
+var Namespaces = []string{"products", "stores"}
+
+// ErrUnknownNamespace is returned when purging a namespace the gateway doesn't cache
+var ErrUnknownNamespace = errors.New("unknown cache namespace")
+
+// namespacePatterns match the keys of each namespace, for every API version
+var namespacePatterns = map[string]string{
+	"products": keyPrefix + "/api/*/products*",
+	"stores":   keyPrefix + "/api/*/stores*",
+}
+
+// NamespaceStats describes one namespace. Keys counts its entries in Redis, hits and misses
+// are this instance's reads since it started.
+type NamespaceStats struct {
+	Name    string  `json:"name"`
+	Pattern string  `json:"pattern"`
+	Keys    int     `json:"keys"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// Stats is the admin view of the response cache
+type Stats struct {
+	Namespaces []NamespaceStats `json:"namespaces"`
+	Hits       int64            `json:"hits"`
+	Misses     int64            `json:"misses"`
+	HitRate    float64          `json:"hit_rate"`
+	TTLs       TTLs             `json:"ttls"`
+	Since      time.Time        `json:"since"`
+}
+
+// TTLs are the expirations of the cached responses per entity
+type TTLs struct {
+	Products time.Duration `json:"products"` // product lists
+	Product  time.Duration `json:"product"`  // product details and their sub-resources
+	Stores   time.Duration `json:"stores"`   // store pages
+}
+
+// TTLsFromEnv reads GATEWAY_CACHE_TTL_PRODUCTS, GATEWAY_CACHE_TTL_PRODUCT and
+// GATEWAY_CACHE_TTL_STORES, each defaulting to GATEWAY_CACHE_TTL (30s by default)
+func TTLsFromEnv() TTLs {
+	ttl := envDuration("GATEWAY_CACHE_TTL", 30*time.Second)
+	return TTLs{
+		Products: envDuration("GATEWAY_CACHE_TTL_PRODUCTS", ttl),
+		Product:  envDuration("GATEWAY_CACHE_TTL_PRODUCT", ttl),
+		Stores:   envDuration("GATEWAY_CACHE_TTL_STORES", ttl),
+	}
+}
+
+// forPath returns the TTL of the entity a cached path serves
+func (t TTLs) forPath(path string) time.Duration {
+	switch {
+	case strings.Contains(path, "/stores"):
+		return t.Stores
+	case strings.HasSuffix(strings.TrimSuffix(path, "/"), "/products"):
+		return t.Products
+	default:
+		return t.Product
+	}
+}
+
+// envDuration parses a positive duration, falling back on missing or invalid values
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(name); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// jitterFromEnv reads CACHE_TTL_JITTER, the largest fraction (0-1, 0.1 by default) taken
+// off an expiration so entries cached together don't all expire together
+func jitterFromEnv() float64 {
+	jitter := 0.1
+	if value := os.Getenv("CACHE_TTL_JITTER"); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed >= 0 && parsed <= 1 {
+			jitter = parsed
+		}
+	}
+	return jitter
+}
+
+// withJitter shortens ttl by a random fraction up to rc.jitter. It never lengthens it, so
+// the configured TTL stays the longest an entry can be served.
+func (rc *ResponseCache) withJitter(ttl time.Duration) time.Duration {
+	if rc.jitter <= 0 {
+		return ttl
+	}
+	return ttl - time.Duration(rand.Float64()*rc.jitter*float64(ttl))
+}
+
+// cachedResponse is the stored form of an upstream response
+type cachedResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// NewResponseCache connects to Redis (see redisconn.FromEnv) when GATEWAY_CACHE_ENABLED=true.
+// It returns nil when caching is disabled or Redis is unreachable; a nil cache is a no-op.
+func NewResponseCache() *ResponseCache {
+	if os.Getenv("GATEWAY_CACHE_ENABLED") != "true" {
+		return nil
+	}
+
+	ttls := TTLsFromEnv()
+	jitter := jitterFromEnv()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, topology, err := redisconn.Connect(ctx)
+	if err != nil {
+		log.Printf("⚠️ Gateway cache disabled: %v", err)
+		return nil
+	}
+
+	log.Printf("✅ Gateway response cache enabled (Redis: %s, TTL: products %s, product %s, stores %s, jitter %g)",
+		topology, ttls.Products, ttls.Product, ttls.Stores, jitter)
+	return &ResponseCache{
+		client:  client,
+		ttls:    ttls,
+		jitter:  jitter,
+		started: time.Now(),
+	}
+}
+
+// Middleware serves cached responses for anonymous GET requests and stores 200 responses.
+// Requests carrying credentials always go to the upstream service, as do requests for which
+// enabled (the feature flag rollout, nil for all) returns false. Requests sent with
+// Cache-Control: no-cache or ?nocache=true skip the cached entry and replace it with the
+// fresh response; the flag is forwarded so the services skip their caches too.
+func (rc *ResponseCache) Middleware(enabled func(*gin.Context) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rc == nil || c.Request.Method != http.MethodGet ||
+			(enabled != nil && !enabled(c)) ||
+			c.GetHeader("Authorization") != "" {
+			c.Next()
+			return
+		}
+
+		key := cacheKey(c.Request)
+
+		if bypass(c.Request) {
+			c.Header("X-Cache", "BYPASS")
+		} else {
+			raw, err := rc.client.Get(c.Request.Context(), key).Bytes()
+			if err == nil {
+				var cached cachedResponse
+				if err := json.Unmarshal(raw, &cached); err == nil {
+					rc.countRead(c.Request.URL.Path, true)
+					c.Header("X-Cache", "HIT")
+					c.Data(cached.Status, cached.ContentType, cached.Body)
+					c.Abort()
+					return
+				}
+			} else if err != redis.Nil {
+				log.Printf("⚠️ Gateway cache read failed for %s: %v", key, err)
+			}
+			rc.countRead(c.Request.URL.Path, false)
+			c.Header("X-Cache", "MISS")
+		}
+
+		writer := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		if writer.Status() != http.StatusOK {
+			return
+		}
+
+		data, err := json.Marshal(cachedResponse{
+			Status:      writer.Status(),
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		})
+		if err != nil {
+			return
+		}
+
+		ttl := rc.withJitter(rc.ttls.forPath(c.Request.URL.Path))
+		if err := rc.client.Set(context.Background(), key, data, ttl).Err(); err != nil {
+			log.Printf("⚠️ Gateway cache write failed for %s: %v", key, err)
+		}
+	}
+}
+
+// InvalidateOnWrite drops the cached product after a successful mutation proxied through the gateway
+func (rc *ResponseCache) InvalidateOnWrite() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if rc == nil || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		productID := c.Param("id")
+		if productID == "" {
+			// Wildcard routes: /seller/products/<id>/...
+			productID = strings.SplitN(strings.TrimPrefix(c.Param("path"), "/"), "/", 2)[0]
+		}
+		if productID != "" {
+			rc.InvalidateProduct(context.Background(), productID)
+		}
+	}
+}
+
+// InvalidateProduct removes a product's detail entries and every product list entry of every API version
+func (rc *ResponseCache) InvalidateProduct(ctx context.Context, productID string) {
+	if rc == nil {
+		return
+	}
+
+	for _, pattern := range productPatterns(productID) {
+		if _, err := rc.deletePattern(ctx, pattern); err != nil {
+			log.Printf("⚠️ Gateway cache invalidation failed for %s: %v", pattern, err)
+		}
+	}
+}
+
+// productPatterns match a product's detail entries and every product list entry
+func productPatterns(productID string) []string {
+	return []string{
+		keyPrefix + "/api/*/products/" + productID + "*",
+		keyPrefix + "/api/*/products",
+		keyPrefix + "/api/*/products\\?*", // ? is a wildcard in SCAN patterns
+	}
+}
+
+// Purge removes every cached response, e.g. after the sandbox data was reset
+func (rc *ResponseCache) Purge(ctx context.Context) error {
+	if rc == nil {
+		return nil
+	}
+	_, err := rc.deletePattern(ctx, keyPrefix+"*")
+	return err
+}
+
+// Stats counts the keys of every namespace in Redis and reports the read counters
+func (rc *ResponseCache) Stats(ctx context.Context) (Stats, error) {
+	stats := Stats{Namespaces: []NamespaceStats{}, TTLs: rc.ttls, Since: rc.started}
+	for i, name := range Namespaces {
+		ns := NamespaceStats{
+			Name:    name,
+			Pattern: namespacePatterns[name],
+			Hits:    rc.hits[i].Load(),
+			Misses:  rc.misses[i].Load(),
+		}
+		ns.HitRate = hitRate(ns.Hits, ns.Misses)
+
+		err := redisconn.ScanKeys(ctx, rc.client, ns.Pattern, func(keys []string) error {
+			ns.Keys += len(keys)
+			return nil
+		})
+		if err != nil {
+			return stats, fmt.Errorf("failed to count cached keys %s: %w", ns.Pattern, err)
+		}
+
+		stats.Hits += ns.Hits
+		stats.Misses += ns.Misses
+		stats.Namespaces = append(stats.Namespaces, ns)
+	}
+	stats.HitRate = hitRate(stats.Hits, stats.Misses)
+	return stats, nil
+}
+
+// PurgeNamespace removes a namespace and returns how many entries were deleted. With an id only the
+// responses about that product or store are removed, along with every product list for a
+// product since any of them may show it. "product" is accepted for "products", so purging a
+// product across services reaches the gateway too.
+func (rc *ResponseCache) PurgeNamespace(ctx context.Context, namespace, id string) (int, error) {
+	if namespace == "product" {
+		namespace = "products"
+	}
+	pattern, ok := namespacePatterns[namespace]
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrUnknownNamespace, namespace)
+	}
+
+	patterns := []string{pattern}
+	switch {
+	case id != "" && namespace == "products":
+		patterns = productPatterns(id)
+	case id != "":
+		patterns = []string{keyPrefix + "/api/*/stores/" + id + "*"}
+	}
+
+	deleted := 0
+	for _, pattern := range patterns {
+		count, err := rc.deletePattern(ctx, pattern)
+		deleted += count
+		if err != nil {
+			return deleted, fmt.Errorf("failed to purge %s: %w", pattern, err)
+		}
+	}
+	log.Printf("🗑️ Purged %d gateway %s cache entries", deleted, namespace)
+	return deleted, nil
+}
+
+// Close closes the Redis connection
+func (rc *ResponseCache) Close() error {
+	if rc == nil {
+		return nil
+	}
+	return rc.client.Close()
+}
+
+// deletePattern deletes matching keys using SCAN so large keyspaces don't block Redis, and
+// returns how many were deleted
+func (rc *ResponseCache) deletePattern(ctx context.Context, pattern string) (int, error) {
+	deleted := 0
+	err := redisconn.ScanKeys(ctx, rc.client, pattern, func(keys []string) error {
+		if err := redisconn.DeleteKeys(ctx, rc.client, keys); err != nil {
+			return err
+		}
+		deleted += len(keys)
+		return nil
+	})
+	return deleted, err
+}
+
+// countRead records a hit or miss in the namespace of path
+func (rc *ResponseCache) countRead(path string, hit bool) {
+	i := 0
+	if strings.Contains(path, "/stores") {
+		i = 1
+	}
+	if hit {
+		rc.hits[i].Add(1)
+	} else {
+		rc.misses[i].Add(1)
+	}
+}
+
+// hitRate is hits over reads, 0 before the first read
+func hitRate(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// bypass reports whether the request asks to skip the cache, with Cache-Control: no-cache
+// or ?nocache=true (or 1)
+func bypass(r *http.Request) bool {
+	if strings.Contains(r.Header.Get("Cache-Control"), "no-cache") {
+		return true
+	}
+	nocache := r.URL.Query().Get("nocache")
+	return nocache == "true" || nocache == "1"
+}
+
+// cacheKey builds the key from the path and the query with parameters sorted,
+// so ?page=1&limit=10 and ?limit=10&page=1 share an entry. The nocache flag is left out,
+// so a bypassing request refreshes the entry regular requests read.
+func cacheKey(r *http.Request) string {
+	key := keyPrefix + r.URL.Path
+	query := r.URL.Query()
+	query.Del("nocache")
+	if query := query.Encode(); query != "" {
+		key += "?" + query
+	}
+	return key
+}
+
+// bodyRecorder copies the response body while it is written to the client
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"api-gateway/cache"
+	"api-gateway/httpclient"
+	"api-gateway/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cacheAdminTimeout bounds a cache admin call across every service
+const cacheAdminTimeout = 30 * time.Second
+
+// cacheAdminService is a service whose cache the admin API inspects and purges
+type cacheAdminService struct {
+	name    string
+	client  *httpclient.Client
+	baseURL string
+}
+
+// errUnknownCacheNamespace is a service answering 404 to a purge, it doesn't cache the namespace
+var errUnknownCacheNamespace = errors.New("unknown cache namespace")
+
+// registerCacheAdminRoutes exposes GET /api/v1/admin/cache, the cache stats of the gateway
+// and every service, and DELETE /api/v1/admin/cache/:namespace, which purges the namespace
+// wherever it is cached. ?id= only purges that entry, ?service= one service (gateway,
+// product-service or payment-service).
+func registerCacheAdminRoutes(admin *gin.RouterGroup, responseCache *cache.ResponseCache) {
+	services := []cacheAdminService{
+		{name: "product-service", client: productServiceClient, baseURL: ProductServiceURL},
+		{name: "payment-service", client: paymentServiceClient, baseURL: PaymentServiceURL},
+	}
+
+	admin.GET("/cache", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), cacheAdminTimeout)
+		defer cancel()
+
+		result := gin.H{}
+		if responseCache == nil {
+			result["gateway"] = gin.H{"status": "disabled"}
+		} else if stats, err := responseCache.Stats(ctx); err != nil {
+			result["gateway"] = gin.H{"error": err.Error()}
+		} else {
+			result["gateway"] = stats
+		}
+
+		for _, service := range services {
+			data, err := callCacheAdmin(ctx, service, http.MethodGet, "/api/v1/admin/cache", c.GetHeader("X-Admin-Token"))
+			if err != nil {
+				result[service.name] = gin.H{"error": err.Error()}
+				continue
+			}
+			result[service.name] = data
+		}
+		c.JSON(http.StatusOK, result)
+	})
+
+	admin.DELETE("/cache/:namespace", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), cacheAdminTimeout)
+		defer cancel()
+
+		namespace := c.Param("namespace")
+		id := c.Query("id")
+		only := c.Query("service")
+		if only != "" && only != "gateway" && only != services[0].name && only != services[1].name {
+			c.JSON(http.StatusBadRequest, i18n.Error(c, "UNKNOWN_SERVICE", gin.H{"details": "service must be gateway, product-service or payment-service"}))
+			return
+		}
+
+		result := gin.H{}
+		purged, failed := false, false
+		if responseCache != nil && (only == "" || only == "gateway") {
+			deleted, err := responseCache.PurgeNamespace(ctx, namespace, id)
+			switch {
+			case errors.Is(err, cache.ErrUnknownNamespace):
+			case err != nil:
+				result["gateway"] = gin.H{"error": err.Error()}
+				failed = true
+			default:
+				result["gateway"] = gin.H{"deleted": deleted}
+				purged = true
+			}
+		}
+
+		path := "/api/v1/admin/cache/" + url.PathEscape(namespace)
+		if id != "" {
+			path += "?id=" + url.QueryEscape(id)
+		}
+		for _, service := range services {
+			if only != "" && only != service.name {
+				continue
+			}
+			data, err := callCacheAdmin(ctx, service, http.MethodDelete, path, c.GetHeader("X-Admin-Token"))
+			switch {
+			case errors.Is(err, errUnknownCacheNamespace):
+			case err != nil:
+				result[service.name] = gin.H{"error": err.Error()}
+				failed = true
+			default:
+				result[service.name] = data
+				purged = true
+			}
+		}
+
+		switch {
+		case failed:
+			c.JSON(http.StatusBadGateway, i18n.Error(c, "CACHE_PURGE_FAILED", gin.H{"namespace": namespace, "services": result}))
+		case !purged:
+			c.JSON(http.StatusNotFound, i18n.Error(c, "UNKNOWN_CACHE_NAMESPACE", gin.H{"details": fmt.Sprintf("no service caches %q", namespace)}))
+		default:
+			log.Printf("🗑️ Cache namespace %s purged (id %q)", namespace, id)
+			c.JSON(http.StatusOK, gin.H{"namespace": namespace, "id": id, "services": result})
+		}
+	})
+}
+
+// callCacheAdmin calls a service's cache admin API with the admin token and returns the
+// data of its {"success": true, "data": ...} answer
+func callCacheAdmin(ctx context.Context, service cacheAdminService, method, path, adminToken string) (json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, method, service.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Admin-Token", adminToken)
+
+	resp, err := doUpstream(service.client, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s unavailable: %w", service.name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s answered %d: %w", service.name, resp.StatusCode, err)
+	}
+
+	var answer struct {
+		Data    json.RawMessage `json:"data"`
+		Error   string          `json:"error"`
+		Details string          `json:"details"`
+	}
+	json.Unmarshal(body, &answer)
+	if resp.StatusCode == http.StatusNotFound && method == http.MethodDelete {
+		return nil, errUnknownCacheNamespace
+	}
+	if resp.StatusCode != http.StatusOK {
+		message := answer.Error
+		if message == "" {
+			message = http.StatusText(resp.StatusCode)
+		} else if answer.Details != "" {
+			message += ": " + answer.Details
+		}
+		return nil, fmt.Errorf("%s answered %d: %s", service.name, resp.StatusCode, message)
+	}
+	return answer.Data, nil
+}
@@ -0,0 +1,260 @@
+// Package chaos injects faults for resilience testing: latency, error responses or dropped
+// connections on upstream calls and routes, each with a probability. Faults are kept in
+// memory, managed through the admin API and expire on their own, so a forgotten fault can't
+// outlive a test session. Nothing is injected until Enable is called, which main only does
+// outside production.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scopes a fault can target
+const (
+	ScopeUpstream = "upstream" // an HTTP client by name, e.g. product_service
+	ScopeRoute    = "route"    // a path prefix, e.g. /api/v1/payments
+)
+
+// Fault types
+const (
+	TypeLatency = "latency" // delay the call, then let it through
+	TypeError   = "error"   // answer with Status without calling through
+	TypeDrop    = "drop"    // fail as if the connection was dropped
+)
+
+// TargetAll matches every upstream or route of the scope
+const TargetAll = "*"
+
+// DefaultTTL is how long a fault lasts when it is created without a ttl
+const DefaultTTL = 15 * time.Minute
+
+var (
+	// ErrDisabled is returned when managing faults while injection is disabled
+	ErrDisabled = errors.New("fault injection is disabled")
+	// ErrInvalidFault is returned for faults with an unknown scope or type or out of range values
+	ErrInvalidFault = errors.New("invalid fault")
+	// ErrDropped is the error of calls failed by a drop fault
+	ErrDropped = errors.New("chaos: connection dropped")
+)
+
+// Fault is an injected fault. Probability is the chance (0-1) that a matching call is hit.
+type Fault struct {
+	ID          string    `json:"id"`
+	Scope       string    `json:"scope"`
+	Target      string    `json:"target"`
+	Type        string    `json:"type"`
+	Probability float64   `json:"probability"`
+	LatencyMs   int       `json:"latency_ms,omitempty"` // latency faults
+	Status      int       `json:"status,omitempty"`     // error faults, 503 by default
+	Injected    int64     `json:"injected"`             // calls hit so far
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// matches reports whether the fault targets name in scope
+func (f *Fault) matches(scope, name string) bool {
+	if f.Scope != scope {
+		return false
+	}
+	if f.Target == TargetAll {
+		return true
+	}
+	if scope == ScopeRoute {
+		return name == f.Target || strings.HasPrefix(name, strings.TrimSuffix(f.Target, "/")+"/")
+	}
+	return name == f.Target
+}
+
+// Latency returns the delay of a latency fault
+func (f Fault) Latency() time.Duration {
+	return time.Duration(f.LatencyMs) * time.Millisecond
+}
+
+// injector holds the active faults, nil while injection is disabled
+type injector struct {
+	scopes map[string]bool
+
+	mu     sync.Mutex
+	faults map[string]*Fault
+	nextID int
+}
+
+var active *injector
+
+// Enable turns fault injection on for the given scopes. It must be called before the
+// server starts handling requests.
+func Enable(scopes ...string) {
+	active = &injector{
+		scopes: make(map[string]bool),
+		faults: make(map[string]*Fault),
+	}
+	for _, scope := range scopes {
+		active.scopes[scope] = true
+	}
+}
+
+// Enabled reports whether fault injection is on
+func Enabled() bool {
+	return active != nil
+}
+
+// Add validates and stores a fault, assigning its ID, creation and expiry times. ttl <= 0
+// keeps the fault for DefaultTTL.
+func Add(fault Fault, ttl time.Duration) (Fault, error) {
+	if active == nil {
+		return Fault{}, ErrDisabled
+	}
+
+	if !active.scopes[fault.Scope] {
+		return Fault{}, fmt.Errorf("%w: unknown scope %q", ErrInvalidFault, fault.Scope)
+	}
+	if fault.Target == "" {
+		return Fault{}, fmt.Errorf("%w: target is required, %q for all", ErrInvalidFault, TargetAll)
+	}
+	if fault.Probability <= 0 || fault.Probability > 1 {
+		return Fault{}, fmt.Errorf("%w: probability must be above 0 and at most 1", ErrInvalidFault)
+	}
+	switch fault.Type {
+	case TypeLatency:
+		if fault.LatencyMs <= 0 {
+			return Fault{}, fmt.Errorf("%w: latency_ms must be positive", ErrInvalidFault)
+		}
+	case TypeError:
+		if fault.Status == 0 {
+			fault.Status = http.StatusServiceUnavailable
+		}
+		if fault.Status < 400 || fault.Status > 599 {
+			return Fault{}, fmt.Errorf("%w: status must be a 4xx or 5xx code", ErrInvalidFault)
+		}
+	case TypeDrop:
+	default:
+		return Fault{}, fmt.Errorf("%w: unknown type %q", ErrInvalidFault, fault.Type)
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	active.mu.Lock()
+	defer active.mu.Unlock()
+
+	active.nextID++
+	fault.ID = strconv.Itoa(active.nextID)
+	fault.Injected = 0
+	fault.CreatedAt = time.Now()
+	fault.ExpiresAt = fault.CreatedAt.Add(ttl)
+	active.faults[fault.ID] = &fault
+	return fault, nil
+}
+
+// Remove deletes a fault, reporting whether it existed
+func Remove(id string) bool {
+	if active == nil {
+		return false
+	}
+
+	active.mu.Lock()
+	defer active.mu.Unlock()
+	_, ok := active.faults[id]
+	delete(active.faults, id)
+	return ok
+}
+
+// Clear deletes every fault and returns how many there were
+func Clear() int {
+	if active == nil {
+		return 0
+	}
+
+	active.mu.Lock()
+	defer active.mu.Unlock()
+	count := len(active.faults)
+	active.faults = make(map[string]*Fault)
+	return count
+}
+
+// List returns the faults that haven't expired, oldest first
+func List() []Fault {
+	faults := []Fault{}
+	if active == nil {
+		return faults
+	}
+
+	active.mu.Lock()
+	defer active.mu.Unlock()
+	active.expire(time.Now())
+	for _, fault := range active.faults {
+		faults = append(faults, *fault)
+	}
+	sort.Slice(faults, func(i, j int) bool { return faults[i].CreatedAt.Before(faults[j].CreatedAt) })
+	return faults
+}
+
+// Scopes returns the scopes faults can target
+func Scopes() []string {
+	scopes := []string{}
+	if active == nil {
+		return scopes
+	}
+	for scope := range active.scopes {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// expire drops the faults past their expiry, the caller holds mu
+func (in *injector) expire(now time.Time) {
+	for id, fault := range in.faults {
+		if !now.Before(fault.ExpiresAt) {
+			delete(in.faults, id)
+		}
+	}
+}
+
+// Inject rolls the faults targeting name in scope. Latency faults that hit are slept
+// through (until ctx is done); the first error or drop fault that hits is returned for the
+// caller to apply.
+func Inject(ctx context.Context, scope, name string) (*Fault, bool) {
+	if active == nil {
+		return nil, false
+	}
+
+	var delay time.Duration
+	var failure *Fault
+
+	active.mu.Lock()
+	active.expire(time.Now())
+	for _, fault := range active.faults {
+		if !fault.matches(scope, name) || rand.Float64() >= fault.Probability {
+			continue
+		}
+		if fault.Type == TypeLatency {
+			fault.Injected++
+			delay += fault.Latency()
+		} else if failure == nil {
+			fault.Injected++
+			hit := *fault
+			failure = &hit
+		}
+	}
+	active.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+	return failure, failure != nil
+}
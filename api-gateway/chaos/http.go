@@ -0,0 +1,86 @@
+package chaos
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Transport wraps next with the upstream faults targeting the client name
+func Transport(name string, next http.RoundTripper) http.RoundTripper {
+	return &faultTransport{name: name, next: next}
+}
+
+type faultTransport struct {
+	name string
+	next http.RoundTripper
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault, ok := Inject(req.Context(), ScopeUpstream, t.name)
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if fault.Type == TypeDrop {
+		return nil, ErrDropped
+	}
+
+	body := fmt.Sprintf(`{"success":false,"error":"Injected fault","details":"chaos fault %s"}`, fault.ID)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fault.Status, http.StatusText(fault.Status)),
+		StatusCode:    fault.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}, "X-Chaos-Fault": []string{fault.ID}},
+		Body:          io.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Middleware applies the route faults targeting the request path before the route's
+// handlers run, except to requests for which exempt returns true (e.g. the admin API that
+// lifts the faults). Drop faults close the client connection without an answer.
+func Middleware(exempt func(*gin.Context) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if exempt != nil && exempt(c) {
+			c.Next()
+			return
+		}
+
+		fault, ok := Inject(c.Request.Context(), ScopeRoute, c.Request.URL.Path)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if fault.Type == TypeDrop {
+			if conn, _, err := c.Writer.Hijack(); err == nil {
+				conn.Close()
+				c.Abort()
+				return
+			}
+			// HTTP/2 connections can't be hijacked, the closest is an empty bad gateway
+			c.AbortWithStatus(http.StatusBadGateway)
+			return
+		}
+
+		c.Header("X-Chaos-Fault", fault.ID)
+		c.AbortWithStatusJSON(fault.Status, gin.H{
+			"success": false,
+			"error":   "Injected fault",
+			"details": "chaos fault " + fault.ID,
+		})
+	}
+}
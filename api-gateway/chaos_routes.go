@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"api-gateway/chaos"
+	"api-gateway/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// chaosEnabled reports whether CHAOS_ENABLED=true turns fault injection on. It stays off in
+// production whatever it says.
+func chaosEnabled() bool {
+	if os.Getenv("CHAOS_ENABLED") != "true" {
+		return false
+	}
+	if appEnv() == "production" {
+		log.Println("⚠️ CHAOS_ENABLED is ignored in production")
+		return false
+	}
+	return true
+}
+
+// initChaos enables upstream and route faults when chaosEnabled. It runs before the
+// upstream clients are created so their transports pick the faults up.
+func initChaos() {
+	if !chaosEnabled() {
+		return
+	}
+	chaos.Enable(chaos.ScopeUpstream, chaos.ScopeRoute)
+	log.Printf("⚠️ Fault injection enabled, manage faults at /api/v1/admin/chaos/faults (%s)", appEnv())
+}
+
+// chaosExempt keeps health checks, admin and debug endpoints out of route faults, so a
+// fault on every route can still be lifted
+func chaosExempt(c *gin.Context) bool {
+	_, exempt := maintenanceScope(c.Request.URL.Path)
+	return exempt
+}
+
+// registerChaosRoutes exposes GET|POST|DELETE /api/v1/admin/chaos/faults and
+// DELETE /api/v1/admin/chaos/faults/:id while fault injection is enabled
+func registerChaosRoutes(admin *gin.RouterGroup) {
+	if !chaos.Enabled() {
+		return
+	}
+
+	admin.GET("/chaos/faults", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"faults": chaos.List(),
+			"scopes": chaos.Scopes(),
+		})
+	})
+
+	admin.POST("/chaos/faults", func(c *gin.Context) {
+		var req struct {
+			chaos.Fault
+			TTL int `json:"ttl"` // seconds, defaults to 15 minutes
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, i18n.Error(c, "INVALID_REQUEST", gin.H{"details": err.Error()}))
+			return
+		}
+
+		fault, err := chaos.Add(req.Fault, time.Duration(req.TTL)*time.Second)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, chaos.ErrInvalidFault) {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, i18n.Error(c, "FAULT_ADD_FAILED", gin.H{"details": err.Error()}))
+			return
+		}
+
+		log.Printf("💥 Chaos fault %s added: %s on %s %s (p=%g) until %s", fault.ID, fault.Type, fault.Scope, fault.Target, fault.Probability, fault.ExpiresAt.Format(time.RFC3339))
+		c.JSON(http.StatusCreated, gin.H{"fault": fault})
+	})
+
+	admin.DELETE("/chaos/faults/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		if !chaos.Remove(id) {
+			c.JSON(http.StatusNotFound, i18n.Error(c, "FAULT_NOT_FOUND", nil))
+			return
+		}
+
+		log.Printf("✅ Chaos fault %s removed", id)
+		c.JSON(http.StatusOK, gin.H{"id": id, "removed": true})
+	})
+
+	admin.DELETE("/chaos/faults", func(c *gin.Context) {
+		removed := chaos.Clear()
+		log.Printf("✅ %d chaos faults removed", removed)
+		c.JSON(http.StatusOK, gin.H{"removed": removed})
+	})
+}
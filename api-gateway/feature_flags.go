@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"api-gateway/flags"
+	"api-gateway/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// featureFlags is configured in main
+var featureFlags *flags.Store
+
+// newFeatureFlags defines the flags consulted by the gateway
+func newFeatureFlags() *flags.Store {
+	return flags.NewStore(flags.Flag{
+		Name:        flags.GatewayCache,
+		Description: "Serve public product reads from the gateway response cache (needs GATEWAY_CACHE_ENABLED=true)",
+		Enabled:     true,
+		Rollout:     100,
+	})
+}
+
+// gatewayCacheEnabled buckets anonymous clients by IP for the cache rollout
+func gatewayCacheEnabled(c *gin.Context) bool {
+	return featureFlags.Enabled(flags.GatewayCache, c.ClientIP())
+}
+
+// registerFlagRoutes exposes GET /api/v1/admin/flags and PUT /api/v1/admin/flags/:name
+func registerFlagRoutes(admin *gin.RouterGroup) {
+	admin.GET("/flags", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"flags": featureFlags.List()})
+	})
+
+	admin.PUT("/flags/:name", func(c *gin.Context) {
+		var req struct {
+			Enabled bool `json:"enabled"`
+			Rollout *int `json:"rollout"` // 0-100, defaults to 100
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, i18n.Error(c, "INVALID_REQUEST", gin.H{"details": err.Error()}))
+			return
+		}
+
+		rollout := 100
+		if req.Rollout != nil {
+			rollout = *req.Rollout
+		}
+
+		flag, err := featureFlags.Set(c.Request.Context(), c.Param("name"), req.Enabled, rollout)
+		if err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, flags.ErrUnknownFlag):
+				status = http.StatusNotFound
+			case errors.Is(err, flags.ErrInvalidRollout):
+				status = http.StatusBadRequest
+			case errors.Is(err, flags.ErrOverridden):
+				status = http.StatusConflict
+			case errors.Is(err, flags.ErrUnavailable):
+				status = http.StatusServiceUnavailable
+			}
+			c.JSON(status, i18n.Error(c, "FEATURE_FLAG_UPDATE_FAILED", gin.H{"details": err.Error()}))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"flag": flag})
+	})
+}
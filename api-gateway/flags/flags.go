@@ -0,0 +1,293 @@
+// Package flags provides runtime feature flags so risky features can be dark-launched and
+// rolled out to a percentage of users. Flags are stored in a Redis hash shared by the
+// services, cached in memory and refreshed periodically; FEATURE_<NAME> environment
+// variables override the stored value for a deployment.
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway/redisconn"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Flags consulted by the gateway
+const (
+	GatewayCache = "enable_gateway_cache"
+)
+
+// redisKey is the hash holding every flag, one JSON field per flag name
+const redisKey = "feature_flags"
+
+// Flag sources, reported by the admin API
+const (
+	SourceDefault = "default"
+	SourceRedis   = "redis"
+	SourceEnv     = "env"
+)
+
+var (
+	// ErrUnknownFlag is returned when setting a flag the service doesn't define
+	ErrUnknownFlag = errors.New("unknown feature flag")
+	// ErrOverridden is returned when setting a flag pinned by a FEATURE_<NAME> variable
+	ErrOverridden = errors.New("feature flag is overridden by the environment")
+	// ErrInvalidRollout is returned for rollouts outside 0-100
+	ErrInvalidRollout = errors.New("rollout must be between 0 and 100")
+	// ErrUnavailable is returned when setting a flag without Redis
+	ErrUnavailable = errors.New("feature flag storage is not available")
+)
+
+// Flag is the state of a feature flag. An enabled flag is on for Rollout percent of
+// subjects (users), chosen by a stable hash so a user keeps the same answer.
+type Flag struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Enabled     bool       `json:"enabled"`
+	Rollout     int        `json:"rollout"`
+	Source      string     `json:"source"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// storedFlag is the Redis representation of a flag
+type storedFlag struct {
+	Enabled   bool      `json:"enabled"`
+	Rollout   int       `json:"rollout"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store evaluates feature flags against an in-memory snapshot
+type Store struct {
+	client    redis.UniversalClient // nil evaluates defaults and environment overrides only
+	defaults  map[string]Flag
+	overrides map[string]storedFlag // FEATURE_<NAME>, read once at start
+	refresh   time.Duration
+
+	mu    sync.RWMutex
+	flags map[string]Flag
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewStore connects to Redis (REDIS_HOST, REDIS_PASSWORD, REDIS_DB) and creates a store for
+// the given flags. Their Enabled/Rollout are the defaults used until a value is stored in
+// Redis; when Redis is unreachable only defaults and FEATURE_<NAME> overrides apply.
+// FEATURE_FLAG_REFRESH sets how often Redis is re-read.
+func NewStore(defaults ...Flag) *Store {
+	refresh := 15 * time.Second
+	if value := os.Getenv("FEATURE_FLAG_REFRESH"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			refresh = parsed
+		} else {
+			log.Printf("⚠️ Ignoring invalid FEATURE_FLAG_REFRESH=%q", value)
+		}
+	}
+
+	s := &Store{
+		client:    connect(),
+		defaults:  make(map[string]Flag, len(defaults)),
+		overrides: make(map[string]storedFlag),
+		refresh:   refresh,
+		stopCh:    make(chan struct{}),
+	}
+	for _, flag := range defaults {
+		flag.Source = SourceDefault
+		s.defaults[flag.Name] = flag
+		if override, ok := envOverride(flag.Name); ok {
+			s.overrides[flag.Name] = override
+		}
+	}
+
+	s.flags = s.resolve(nil)
+	return s
+}
+
+// Start loads the stored flags and keeps them refreshed until Stop
+func (s *Store) Start() {
+	s.Reload(context.Background())
+	if s.client == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.Reload(context.Background())
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the refresh loop and closes the Redis connection
+func (s *Store) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		if s.client != nil {
+			s.client.Close()
+		}
+	})
+}
+
+// connect returns a Redis client (see redisconn.FromEnv), or nil when Redis is unreachable
+func connect() redis.UniversalClient {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, _, err := redisconn.Connect(ctx)
+	if err != nil {
+		log.Printf("⚠️ Feature flags read from defaults and environment only: %v", err)
+		return nil
+	}
+	return client
+}
+
+// Reload re-reads the stored flags. On a Redis error the previous snapshot is kept.
+func (s *Store) Reload(ctx context.Context) {
+	var stored map[string]string
+	if s.client != nil {
+		var err error
+		stored, err = s.client.HGetAll(ctx, redisKey).Result()
+		if err != nil {
+			log.Printf("⚠️ Failed to load feature flags, keeping previous values: %v", err)
+			return
+		}
+	}
+
+	flags := s.resolve(stored)
+	s.mu.Lock()
+	s.flags = flags
+	s.mu.Unlock()
+}
+
+// Enabled reports whether a flag is on for subject (usually the user ID). Unknown flags are off.
+func (s *Store) Enabled(name, subject string) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.RLock()
+	flag, ok := s.flags[name]
+	s.mu.RUnlock()
+
+	if !ok || !flag.Enabled || flag.Rollout <= 0 {
+		return false
+	}
+	if flag.Rollout >= 100 {
+		return true
+	}
+	return bucket(name, subject) < flag.Rollout
+}
+
+// List returns every flag sorted by name
+func (s *Store) List() []Flag {
+	s.mu.RLock()
+	list := make([]Flag, 0, len(s.flags))
+	for _, flag := range s.flags {
+		list = append(list, flag)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Set stores a flag and applies it locally at once; other instances pick it up on their
+// next refresh
+func (s *Store) Set(ctx context.Context, name string, enabled bool, rollout int) (Flag, error) {
+	if _, ok := s.defaults[name]; !ok {
+		return Flag{}, ErrUnknownFlag
+	}
+	if rollout < 0 || rollout > 100 {
+		return Flag{}, ErrInvalidRollout
+	}
+	if _, ok := s.overrides[name]; ok {
+		return Flag{}, ErrOverridden
+	}
+	if s.client == nil {
+		return Flag{}, ErrUnavailable
+	}
+
+	data, err := json.Marshal(storedFlag{Enabled: enabled, Rollout: rollout, UpdatedAt: time.Now()})
+	if err != nil {
+		return Flag{}, err
+	}
+	if err := s.client.HSet(ctx, redisKey, name, data).Err(); err != nil {
+		return Flag{}, fmt.Errorf("failed to store feature flag: %w", err)
+	}
+
+	s.Reload(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.flags[name], nil
+}
+
+// resolve builds the snapshot: defaults, overlaid with stored values, overlaid with the environment
+func (s *Store) resolve(stored map[string]string) map[string]Flag {
+	flags := make(map[string]Flag, len(s.defaults))
+	for name, flag := range s.defaults {
+		if raw, ok := stored[name]; ok {
+			var value storedFlag
+			if err := json.Unmarshal([]byte(raw), &value); err == nil {
+				updatedAt := value.UpdatedAt
+				flag.Enabled, flag.Rollout, flag.UpdatedAt = value.Enabled, value.Rollout, &updatedAt
+				flag.Source = SourceRedis
+			} else {
+				log.Printf("⚠️ Ignoring malformed stored feature flag %s: %v", name, err)
+			}
+		}
+
+		if override, ok := s.overrides[name]; ok {
+			flag.Enabled, flag.Rollout = override.Enabled, override.Rollout
+			flag.Source, flag.UpdatedAt = SourceEnv, nil
+		}
+
+		flags[name] = flag
+	}
+	return flags
+}
+
+// envOverride reads FEATURE_<NAME>: true/on, false/off or a rollout percentage
+func envOverride(name string) (storedFlag, bool) {
+	key := "FEATURE_" + strings.ToUpper(name)
+	value := strings.ToLower(strings.TrimSpace(os.Getenv(key)))
+	switch value {
+	case "":
+		return storedFlag{}, false
+	case "true", "on":
+		return storedFlag{Enabled: true, Rollout: 100}, true
+	case "false", "off":
+		return storedFlag{}, true
+	}
+
+	percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+	if err != nil || percent < 0 || percent > 100 {
+		log.Printf("⚠️ Ignoring invalid %s=%q", key, value)
+		return storedFlag{}, false
+	}
+	return storedFlag{Enabled: percent > 0, Rollout: percent}, true
+}
+
+// bucket maps a subject to 0-99, salted with the flag name so rollouts of different flags
+// don't always pick the same users
+func bucket(name, subject string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(name + ":" + subject))
+	return int(hash.Sum32() % 100)
+}
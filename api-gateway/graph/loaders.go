@@ -0,0 +1,144 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway/graph/model"
+)
+
+// loaderWait is how long a loader collects keys before fetching them, resolvers of one
+// list run concurrently and ask within it
+const loaderWait = 2 * time.Millisecond
+
+// maxProductBatch is the most IDs product-service takes in one ?ids= lookup
+const maxProductBatch = 100
+
+// result is the outcome of loading one key, ready once done is closed
+type result[V any] struct {
+	value V
+	err   error
+	done  chan struct{}
+}
+
+// loader batches the keys asked for within loaderWait into one fetch and remembers the
+// results for the rest of the request
+type loader[K comparable, V any] struct {
+	fetch    func(ctx context.Context, keys []K) (map[K]V, error)
+	maxBatch int
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	pending []K
+	timer   *time.Timer
+}
+
+func newLoader[K comparable, V any](maxBatch int, fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, maxBatch: maxBatch, results: map[K]*result[V]{}}
+}
+
+// Load returns the value of key, the zero value when the fetch didn't return it
+func (l *loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	res, ok := l.results[key]
+	if !ok {
+		res = &result[V]{done: make(chan struct{})}
+		l.results[key] = res
+		l.pending = append(l.pending, key)
+		switch {
+		case len(l.pending) >= l.maxBatch:
+			l.dispatchLocked(ctx)
+		case l.timer == nil:
+			l.timer = time.AfterFunc(loaderWait, func() {
+				l.mu.Lock()
+				defer l.mu.Unlock()
+				l.dispatchLocked(ctx)
+			})
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-res.done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// dispatchLocked fetches the pending keys in the background, l.mu must be held
+func (l *loader[K, V]) dispatchLocked(ctx context.Context) {
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	if len(l.pending) == 0 {
+		return
+	}
+	keys := l.pending
+	l.pending = nil
+
+	go func() {
+		values, err := l.fetch(ctx, keys)
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for _, key := range keys {
+			res := l.results[key]
+			res.value, res.err = values[key], err
+			close(res.done)
+		}
+	}()
+}
+
+// Loaders are the dataloaders of one graph request
+type Loaders struct {
+	products *loader[string, *model.Product]
+}
+
+type loadersKey struct{}
+
+// WithLoaders attaches fresh loaders to a request's ctx, results are never shared between
+// requests
+func (r *Resolver) WithLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &Loaders{
+		products: newLoader(maxProductBatch, r.fetchProducts),
+	})
+}
+
+// loadersFrom returns the request's loaders
+func loadersFrom(ctx context.Context) *Loaders {
+	loaders, ok := ctx.Value(loadersKey{}).(*Loaders)
+	if !ok {
+		panic("graph: request without loaders, use Resolver.WithLoaders")
+	}
+	return loaders
+}
+
+// fetchProducts looks products up with one GET /api/v1/products?ids= call. Products that
+// don't exist (or aren't approved) are missing from the map.
+func (r *Resolver) fetchProducts(ctx context.Context, ids []string) (map[string]*model.Product, error) {
+	query := url.Values{
+		"ids":        {strings.Join(ids, ",")},
+		"limit":      {strconv.Itoa(len(ids))},
+		"skip_total": {"true"},
+	}
+	var answer envelope[model.ProductConnection]
+	if err := r.ProductService.get(ctx, "/api/v1/products", query, nil, &answer); err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	products := make(map[string]*model.Product, len(answer.Data.Nodes))
+	for _, product := range answer.Data.Nodes {
+		products[product.ID] = product
+	}
+	return products, nil
+}
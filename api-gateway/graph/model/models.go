@@ -0,0 +1,75 @@
+// Package model holds the storefront graph's types. They decode the upstream JSON answers
+// directly, gqlgen binds the schema's fields to them (autobind in gqlgen.yml).
+package model
+
+import "time"
+
+// Product is a product-service product
+type Product struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Price       float64        `json:"price"`
+	FinalPrice  float64        `json:"final_price"`
+	MemberPrice float64        `json:"member_price"`
+	Stock       int            `json:"stock"`
+	IsActive    bool           `json:"is_active"`
+	Seller      Seller         `json:"user"`
+	Images      []ProductImage `json:"images"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// ProductImage is an image of a product's gallery
+type ProductImage struct {
+	ID       string `json:"id"`
+	ImageURL string `json:"image_url"`
+}
+
+// Seller is the owner of a product, as embedded by product-service
+type Seller struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// ProductConnection is a page of GET /api/v1/products
+type ProductConnection struct {
+	Nodes      []*Product `json:"products"`
+	Total      int        `json:"total"` // -1 when product-service skipped counting
+	HasMore    bool       `json:"has_more"`
+	NextCursor *string    `json:"next_cursor"`
+}
+
+// User is the signed-in user's user-service profile
+type User struct {
+	ID         string  `json:"id"`
+	Username   string  `json:"username"`
+	Email      string  `json:"email"`
+	ImageURL   *string `json:"image_url"`
+	IsVerified bool    `json:"is_verified"`
+	Locale     string  `json:"locale"`
+}
+
+// Payment is a payment-service payment, its product is resolved through the product loader
+type Payment struct {
+	ID            string        `json:"id"`
+	OrderID       string        `json:"order_id"`
+	ProductID     *string       `json:"product_id"`
+	Amount        int64         `json:"amount"`
+	AdminFee      int64         `json:"admin_fee"`
+	TotalAmount   int64         `json:"total_amount"`
+	PaymentMethod string        `json:"payment_method"`
+	Status        PaymentStatus `json:"status"`
+	VaNumber      *string       `json:"va_number"`
+	ExpiryTime    *time.Time    `json:"expiry_time"`
+	PaidAt        *time.Time    `json:"paid_at"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// PaymentConnection is a page of GET /api/v1/payments/user
+type PaymentConnection struct {
+	Nodes []*Payment `json:"payments"`
+	Total int        `json:"total"`
+	Page  int        `json:"page"`
+	Limit int        `json:"limit"`
+}
@@ -0,0 +1,16 @@
+package graph
+
+//go:generate go tool gqlgen generate
+
+// This file will not be regenerated automatically.
+//
+// It serves as dependency injection for your app, add any dependencies you require here.
+
+// Resolver resolves the storefront graph over the REST upstreams. Product lookups go
+// through the request's loaders (WithLoaders), so a list of payments fetches its products
+// in one batch.
+type Resolver struct {
+	ProductService Service
+	UserService    Service
+	PaymentService Service
+}
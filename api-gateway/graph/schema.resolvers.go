@@ -0,0 +1,115 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"api-gateway/graph/model"
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// Product is the resolver for the product field.
+func (r *paymentResolver) Product(ctx context.Context, obj *model.Payment) (*model.Product, error) {
+	if obj.ProductID == nil {
+		return nil, nil
+	}
+	return loadersFrom(ctx).products.Load(ctx, *obj.ProductID)
+}
+
+// Products is the resolver for the products field.
+func (r *queryResolver) Products(ctx context.Context, first *int, after *string, search *string, minPrice *float64, maxPrice *float64) (*model.ProductConnection, error) {
+	query := url.Values{}
+	if first != nil {
+		query.Set("limit", strconv.Itoa(min(max(*first, 1), maxProductBatch)))
+	}
+	if after != nil && *after != "" {
+		query.Set("cursor", *after)
+	}
+	if search != nil && *search != "" {
+		query.Set("search", *search)
+	}
+	if minPrice != nil {
+		query.Set("min_price", strconv.FormatFloat(*minPrice, 'f', -1, 64))
+	}
+	if maxPrice != nil {
+		query.Set("max_price", strconv.FormatFloat(*maxPrice, 'f', -1, 64))
+	}
+
+	var answer envelope[model.ProductConnection]
+	if err := r.ProductService.get(ctx, "/api/v1/products", query, nil, &answer); err != nil {
+		return nil, err
+	}
+	if answer.Data.Nodes == nil {
+		answer.Data.Nodes = []*model.Product{}
+	}
+	return &answer.Data, nil
+}
+
+// Product is the resolver for the product field.
+func (r *queryResolver) Product(ctx context.Context, id string) (*model.Product, error) {
+	if !isUUID(id) {
+		return nil, nil
+	}
+	return loadersFrom(ctx).products.Load(ctx, id)
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
+	viewer := viewerFrom(ctx)
+	if viewer.UserID == "" {
+		return nil, unauthenticated()
+	}
+
+	var answer struct {
+		User model.User `json:"user"`
+	}
+	if err := r.UserService.get(ctx, "/api/v1/user/profile", nil, viewer.authHeader(), &answer); err != nil {
+		return nil, err
+	}
+	return &answer.User, nil
+}
+
+// Payments is the resolver for the payments field.
+func (r *userResolver) Payments(ctx context.Context, obj *model.User, page *int, limit *int, status *model.PaymentStatus) (*model.PaymentConnection, error) {
+	// Only the viewer's own payments are listed, payment-service answers for the X-User-ID
+	viewer := viewerFrom(ctx)
+	if viewer.UserID == "" || viewer.UserID != obj.ID {
+		return nil, unauthenticated()
+	}
+
+	query := url.Values{}
+	if page != nil {
+		query.Set("page", strconv.Itoa(*page))
+	}
+	if limit != nil {
+		query.Set("limit", strconv.Itoa(*limit))
+	}
+	if status != nil {
+		query.Set("status", status.String())
+	}
+
+	var answer envelope[model.PaymentConnection]
+	if err := r.PaymentService.get(ctx, "/api/v1/payments/user", query, viewer.userHeader(), &answer); err != nil {
+		return nil, err
+	}
+	if answer.Data.Nodes == nil {
+		answer.Data.Nodes = []*model.Payment{}
+	}
+	return &answer.Data, nil
+}
+
+// Payment returns PaymentResolver implementation.
+func (r *Resolver) Payment() PaymentResolver { return &paymentResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type paymentResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
@@ -0,0 +1,73 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// errNotFound is an upstream answering 404
+var errNotFound = errors.New("not found")
+
+// Service is an upstream service the resolvers call
+type Service struct {
+	Name    string
+	BaseURL string
+	// Do sends a request following the service's retry policy and signs it
+	Do func(*http.Request) (*http.Response, error)
+}
+
+// get GETs path with query and decodes the answer into dest. header carries the viewer's
+// identity where the service needs it.
+func (s Service) get(ctx context.Context, path string, query url.Values, header http.Header, dest any) error {
+	target := s.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s unavailable: %w", s.Name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s answered %d: %w", s.Name, resp.StatusCode, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		var answer struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(body, &answer)
+		if answer.Error == "" {
+			answer.Error = http.StatusText(resp.StatusCode)
+		}
+		return fmt.Errorf("%s answered %d: %s", s.Name, resp.StatusCode, answer.Error)
+	}
+	if err := json.Unmarshal(body, dest); err != nil {
+		return fmt.Errorf("%s returned invalid JSON: %w", s.Name, err)
+	}
+	return nil
+}
+
+// envelope is the {"success": true, "data": ...} answer of product-service and
+// payment-service
+type envelope[T any] struct {
+	Data T `json:"data"`
+}
@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Viewer is the caller of a graph request, as authenticated by the gateway
+type Viewer struct {
+	UserID        string // empty for anonymous requests
+	Authorization string // the Bearer token, user-service checks it again for the profile
+}
+
+type viewerKey struct{}
+
+// WithViewer attaches the request's viewer to ctx
+func WithViewer(ctx context.Context, viewer Viewer) context.Context {
+	return context.WithValue(ctx, viewerKey{}, viewer)
+}
+
+// viewerFrom returns the viewer of the request, anonymous when none was attached
+func viewerFrom(ctx context.Context) Viewer {
+	viewer, _ := ctx.Value(viewerKey{}).(Viewer)
+	return viewer
+}
+
+// userHeader identifies the viewer to payment-service, like the REST proxy does
+func (v Viewer) userHeader() http.Header {
+	return http.Header{"X-User-Id": {v.UserID}}
+}
+
+// authHeader forwards the viewer's token to user-service
+func (v Viewer) authHeader() http.Header {
+	return http.Header{"Authorization": {v.Authorization}}
+}
+
+// unauthenticated is the error of fields that need a signed-in user
+func unauthenticated() error {
+	return &gqlerror.Error{
+		Message:    "authentication required",
+		Extensions: map[string]any{"code": "UNAUTHENTICATED"},
+	}
+}
+
+// isUUID reports whether id is a UUID, product-service rejects a batch holding anything else
+func isUUID(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+	for i, r := range id {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"api-gateway/graph"
+	"api-gateway/middleware"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// registerGraphQLRoutes serves the storefront graph (graph/schema.graphqls) on POST /graphql,
+// products, the signed-in user's profile and their payments in one request. GRAPHQL_ENABLED=false
+// turns it off, GRAPHQL_COMPLEXITY_LIMIT (default 500) bounds the cost of a query.
+func registerGraphQLRoutes(r *gin.Engine) {
+	if os.Getenv("GRAPHQL_ENABLED") == "false" {
+		log.Println("⚠️ GraphQL disabled")
+		return
+	}
+
+	resolver := &graph.Resolver{
+		ProductService: graph.Service{Name: "product-service", BaseURL: ProductServiceURL, Do: func(req *http.Request) (*http.Response, error) {
+			return doUpstream(productServiceClient, req)
+		}},
+		UserService: graph.Service{Name: "user-service", BaseURL: UserServiceURL, Do: func(req *http.Request) (*http.Response, error) {
+			return doUpstream(userServiceClient, req)
+		}},
+		PaymentService: graph.Service{Name: "payment-service", BaseURL: PaymentServiceURL, Do: func(req *http.Request) (*http.Response, error) {
+			return doUpstream(paymentServiceClient, req)
+		}},
+	}
+
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	complexityLimit := 500
+	if value := os.Getenv("GRAPHQL_COMPLEXITY_LIMIT"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			complexityLimit = parsed
+		} else {
+			log.Printf("⚠️ Ignoring invalid GRAPHQL_COMPLEXITY_LIMIT=%q, using %d", value, complexityLimit)
+		}
+	}
+	srv.Use(extension.FixedComplexityLimit(complexityLimit))
+
+	r.POST("/graphql", graphQLAuth(jwtKeyFunc()), func(c *gin.Context) {
+		viewer := graph.Viewer{Authorization: c.GetHeader("Authorization")}
+		if userID, ok := c.Get("user_id"); ok {
+			viewer.UserID = userID.(string)
+		}
+		ctx := resolver.WithLoaders(graph.WithViewer(c.Request.Context(), viewer))
+		srv.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	})
+	log.Println("🕸️ GraphQL enabled on POST /graphql")
+}
+
+// graphQLAuth runs AuthMiddleware for requests sending a token, so an invalid token is
+// rejected like on the REST routes. Anonymous requests go through, the graph's public
+// fields don't need a user and `me` answers an authentication error.
+func graphQLAuth(keyFunc jwt.Keyfunc) gin.HandlerFunc {
+	auth := middleware.AuthMiddleware(keyFunc)
+	return func(c *gin.Context) {
+		if strings.TrimSpace(c.GetHeader("Authorization")) == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"hash/fnv"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Hedging metrics, exposed on /debug/vars and /api/v1/admin/runtime
+var (
+	hedgeRequests = expvar.NewInt("product_hedge_requests")
+	hedgesSent    = expvar.NewInt("product_hedges_sent")
+	hedgeWins     = expvar.NewInt("product_hedge_wins")
+	hedgeFailures = expvar.NewInt("product_hedge_failures")
+)
+
+// productHedging is configured in main, nil when hedging is disabled
+var productHedging *hedger
+
+// hedger sends idempotent product reads to a sticky primary replica and, when it hasn't
+// answered within delay, a second copy to the next replica; the first response wins
+type hedger struct {
+	replicas []string
+	delay    time.Duration
+	client   *http.Client
+}
+
+// upstreamResponse is a fully read response from one attempt
+type upstreamResponse struct {
+	status int
+	header http.Header
+	body   []byte
+	hedged bool
+	err    error
+}
+
+// newHedger reads PRODUCT_HEDGE_DELAY and PRODUCT_SERVICE_REPLICAS.
+// It returns nil (hedging disabled) when no delay is configured.
+func newHedger() *hedger {
+	delay, err := time.ParseDuration(os.Getenv("PRODUCT_HEDGE_DELAY"))
+	if err != nil || delay <= 0 {
+		return nil
+	}
+
+	var replicas []string
+	for _, replica := range strings.Split(os.Getenv("PRODUCT_SERVICE_REPLICAS"), ",") {
+		if replica = strings.TrimSpace(replica); replica != "" {
+			replicas = append(replicas, strings.TrimSuffix(replica, "/"))
+		}
+	}
+	if len(replicas) == 0 {
+		// A single address still benefits when it load balances across instances
+		replicas = []string{ProductServiceURL}
+	}
+
+	log.Printf("✅ Product read hedging enabled (delay: %s, replicas: %d)", delay, len(replicas))
+	return &hedger{
+		replicas: replicas,
+		delay:    delay,
+		client:   productServiceClient.Client, // Per attempt timeout of PRODUCT_SERVICE_TIMEOUT
+	}
+}
+
+// Do performs a hedged GET for path and returns the first response received.
+// Clients stick to a primary replica chosen from their IP so replica caches stay warm.
+func (h *hedger) Do(c *gin.Context, path string, header http.Header) *upstreamResponse {
+	hedgeRequests.Add(1)
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel() // Abandon the slower attempt
+
+	primary := h.primaryFor(c.ClientIP())
+	results := make(chan *upstreamResponse, 2)
+
+	go h.attempt(ctx, h.replicas[primary]+path, header, false, results)
+
+	timer := time.NewTimer(h.delay)
+	defer timer.Stop()
+
+	sendHedge := func() {
+		hedgesSent.Add(1)
+		next := h.replicas[(primary+1)%len(h.replicas)]
+		go h.attempt(ctx, next+path, header, true, results)
+	}
+
+	pending, hedged := 1, false
+	var last *upstreamResponse
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if !hedged {
+				hedged = true
+				pending++
+				sendHedge()
+			}
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				if result.hedged {
+					hedgeWins.Add(1)
+				}
+				return result
+			}
+			last = result
+
+			// The primary failed outright, don't wait for the threshold to try elsewhere
+			if !hedged {
+				hedged = true
+				pending++
+				sendHedge()
+			}
+		}
+	}
+
+	hedgeFailures.Add(1)
+	return last
+}
+
+// attempt performs one GET and reads the whole body so it can be returned without the context
+func (h *hedger) attempt(ctx context.Context, url string, header http.Header, hedged bool, results chan<- *upstreamResponse) {
+	result := &upstreamResponse{hedged: hedged}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		result.err = err
+		results <- result
+		return
+	}
+	req.Header = header.Clone()
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		result.err = err
+		results <- result
+		return
+	}
+	defer resp.Body.Close()
+
+	result.status = resp.StatusCode
+	result.header = resp.Header
+	result.body, result.err = io.ReadAll(resp.Body)
+	results <- result
+}
+
+// primaryFor maps a client to its sticky primary replica
+func (h *hedger) primaryFor(clientIP string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(clientIP))
+	return int(hash.Sum32() % uint32(len(h.replicas)))
+}
+
+// hedgingStats reports hedge counters for the admin runtime endpoint
+func hedgingStats() gin.H {
+	if productHedging == nil {
+		return gin.H{"product_hedging": gin.H{"enabled": false}}
+	}
+
+	requests := hedgeRequests.Value()
+	hedgeRate := 0.0
+	if requests > 0 {
+		hedgeRate = float64(hedgesSent.Value()) / float64(requests)
+	}
+
+	return gin.H{
+		"product_hedging": gin.H{
+			"enabled":    true,
+			"delay":      productHedging.delay.String(),
+			"replicas":   len(productHedging.replicas),
+			"requests":   requests,
+			"hedges":     hedgesSent.Value(),
+			"hedge_wins": hedgeWins.Value(),
+			"failures":   hedgeFailures.Value(),
+			"hedge_rate": hedgeRate,
+		},
+	}
+}
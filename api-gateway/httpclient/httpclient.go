@@ -0,0 +1,196 @@
+// Package httpclient builds the HTTP clients used for calls leaving the gateway. All clients
+// share one pooled transport with bounded dial, TLS handshake and idle timeouts, enforce the
+// per attempt timeout of their retry policy, and record per client metrics on /debug/vars.
+// Setting HTTP_CLIENT_TRACE=true also logs connection timings of every request. While fault
+// injection is enabled, the upstream faults of a client apply to each of its attempts.
+package httpclient
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"api-gateway/chaos"
+	"api-gateway/retry"
+	"api-gateway/serviceauth"
+)
+
+// RetryHook is called before a request is retried, after attempt (0 based) failed with err
+type RetryHook func(req *http.Request, attempt int, err error, delay time.Duration)
+
+// Client is an http.Client that knows its name and retry policy
+type Client struct {
+	*http.Client
+	name    string
+	policy  retry.Policy
+	hooks   []RetryHook
+	metrics bool
+}
+
+// Option customises a client
+type Option func(*Client)
+
+// WithoutRedirects returns redirect responses to the caller instead of following them
+func WithoutRedirects() Option {
+	return func(c *Client) {
+		c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+}
+
+// WithRetryHook adds a hook called before every retry
+func WithRetryHook(hook RetryHook) Option {
+	return func(c *Client) {
+		c.hooks = append(c.hooks, hook)
+	}
+}
+
+// WithServiceAuth sends a service token for audience with every request. A nil issuer
+// leaves requests unsigned.
+func WithServiceAuth(issuer *serviceauth.Issuer, audience string) Option {
+	return func(c *Client) {
+		if issuer == nil {
+			return
+		}
+		c.Transport = &signingTransport{next: c.Transport, issuer: issuer, audience: audience}
+	}
+}
+
+// signingTransport sets the service token on a copy of each request
+type signingTransport struct {
+	next     http.RoundTripper
+	issuer   *serviceauth.Issuer
+	audience string
+}
+
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.issuer.Token(t.audience)
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(serviceauth.Header, token)
+	return t.next.RoundTrip(req)
+}
+
+// New creates a client on the shared transport. Each attempt may take policy.Timeout, 0
+// leaves requests bounded by their context only.
+func New(name string, policy retry.Policy, opts ...Option) *Client {
+	metrics := os.Getenv("HTTP_CLIENT_METRICS") != "false"
+	var base http.RoundTripper = sharedTransport()
+	if chaos.Enabled() {
+		base = chaos.Transport(name, base)
+	}
+	c := &Client{
+		Client: &http.Client{
+			Timeout:   policy.Timeout,
+			Transport: instrument(name, base, metrics, os.Getenv("HTTP_CLIENT_TRACE") == "true"),
+		},
+		name:    name,
+		policy:  policy,
+		metrics: metrics,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Name returns the client name used in metrics and logs
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Policy returns the client's retry policy
+func (c *Client) Policy() retry.Policy {
+	return c.policy
+}
+
+// Retry sends a bodyless request, retrying it following the policy while shouldRetry
+// reports the attempt as failed. The last response or error is returned as is.
+func (c *Client) Retry(req *http.Request, shouldRetry func(*http.Response, error) bool) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.Do(req)
+		if attempt >= c.policy.Retries() || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		if err == nil {
+			resp.Body.Close()
+			err = &StatusError{StatusCode: resp.StatusCode}
+		}
+
+		delay := c.policy.Delay(attempt)
+		if c.metrics {
+			record(c.name, "retries", 1)
+		}
+		log.Printf("⚠️ %s %s failed (attempt %d/%d), retrying in %v: %v", req.Method, req.URL.Path, attempt+1, c.policy.MaxAttempts, delay, err)
+		for _, hook := range c.hooks {
+			hook(req, attempt, err, delay)
+		}
+		time.Sleep(delay)
+	}
+}
+
+// StatusError is the error of an attempt that got a response with a failing status
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return "status " + strconv.Itoa(e.StatusCode)
+}
+
+var (
+	transportOnce sync.Once
+	transport     *http.Transport
+)
+
+// sharedTransport returns the pooled transport shared by every client, configured from
+// HTTP_CLIENT_DIAL_TIMEOUT (5s), HTTP_CLIENT_TLS_HANDSHAKE_TIMEOUT (10s),
+// HTTP_CLIENT_IDLE_CONN_TIMEOUT (90s), HTTP_CLIENT_MAX_IDLE_CONNS (200) and
+// HTTP_CLIENT_MAX_IDLE_CONNS_PER_HOST (32)
+func sharedTransport() *http.Transport {
+	transportOnce.Do(func() {
+		dialer := &net.Dialer{
+			Timeout:   envDuration("HTTP_CLIENT_DIAL_TIMEOUT", 5*time.Second),
+			KeepAlive: 30 * time.Second,
+		}
+		transport = &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			TLSHandshakeTimeout:   envDuration("HTTP_CLIENT_TLS_HANDSHAKE_TIMEOUT", 10*time.Second),
+			IdleConnTimeout:       envDuration("HTTP_CLIENT_IDLE_CONN_TIMEOUT", 90*time.Second),
+			MaxIdleConns:          envInt("HTTP_CLIENT_MAX_IDLE_CONNS", 200),
+			MaxIdleConnsPerHost:   envInt("HTTP_CLIENT_MAX_IDLE_CONNS_PER_HOST", 32),
+			ExpectContinueTimeout: 1 * time.Second,
+		}
+	})
+	return transport
+}
+
+func envDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+		log.Printf("⚠️ Ignoring invalid %s=%q, using default", key, value)
+	}
+	return defaultValue
+}
+
+func envInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			return parsed
+		}
+		log.Printf("⚠️ Ignoring invalid %s=%q, using default", key, value)
+	}
+	return defaultValue
+}
@@ -0,0 +1,104 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"expvar"
+	"log"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+)
+
+// clientVars publishes "<client>.<counter>" totals on /debug/vars: requests, errors,
+// status_2xx to status_5xx, retries and duration_ms
+var clientVars = expvar.NewMap("http_clients")
+
+// instrumentedTransport records metrics for every attempt and optionally traces connections
+type instrumentedTransport struct {
+	name    string
+	next    http.RoundTripper
+	metrics bool
+	trace   bool
+}
+
+// instrument wraps next with metrics (disabled by HTTP_CLIENT_METRICS=false) and connection
+// tracing (HTTP_CLIENT_TRACE=true)
+func instrument(name string, next http.RoundTripper, metrics, trace bool) http.RoundTripper {
+	if !metrics && !trace {
+		return next
+	}
+	return &instrumentedTransport{
+		name:    name,
+		next:    next,
+		metrics: metrics,
+		trace:   trace,
+	}
+}
+
+// RoundTrip performs one attempt
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	if t.trace {
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), t.clientTrace(req, start)))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+
+	if t.metrics {
+		record(t.name, "requests", 1)
+		record(t.name, "duration_ms", time.Since(start).Milliseconds())
+		if err != nil {
+			record(t.name, "errors", 1)
+		} else {
+			record(t.name, statusClass(resp.StatusCode), 1)
+		}
+	}
+	return resp, err
+}
+
+// clientTrace logs how long DNS, connecting, the TLS handshake and the first response byte took
+func (t *instrumentedTransport) clientTrace(req *http.Request, start time.Time) *httptrace.ClientTrace {
+	var dnsStart, connectStart, tlsStart time.Time
+	since := func(from time.Time) time.Duration {
+		return time.Since(from).Round(time.Microsecond)
+	}
+
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			log.Printf("🔎 [%s] %s %s got connection (reused: %t) after %v", t.name, req.Method, req.URL.Host, info.Reused, since(start))
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			log.Printf("🔎 [%s] DNS %s took %v (err: %v)", t.name, req.URL.Hostname(), since(dnsStart), info.Err)
+		},
+		ConnectStart: func(network, addr string) { connectStart = time.Now() },
+		ConnectDone: func(network, addr string, err error) {
+			log.Printf("🔎 [%s] connect %s took %v (err: %v)", t.name, addr, since(connectStart), err)
+		},
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			log.Printf("🔎 [%s] TLS handshake took %v (err: %v)", t.name, since(tlsStart), err)
+		},
+		GotFirstResponseByte: func() {
+			log.Printf("🔎 [%s] %s %s first byte after %v", t.name, req.Method, req.URL.Path, since(start))
+		},
+	}
+}
+
+// record adds delta to a client counter
+func record(name, counter string, delta int64) {
+	clientVars.Add(name+"."+counter, delta)
+}
+
+func statusClass(status int) string {
+	switch {
+	case status >= 500:
+		return "status_5xx"
+	case status >= 400:
+		return "status_4xx"
+	case status >= 300:
+		return "status_3xx"
+	default:
+		return "status_2xx"
+	}
+}
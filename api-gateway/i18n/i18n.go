@@ -0,0 +1,130 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale represents a supported language
+type Locale string
+
+const (
+	LocaleID Locale = "id"
+	LocaleEN Locale = "en"
+)
+
+// contextKey is the gin context key used to store the negotiated locale
+const contextKey = "locale"
+
+// DefaultLocale returns the fallback locale (configurable via DEFAULT_LOCALE)
+func DefaultLocale() Locale {
+	if locale, ok := Parse(os.Getenv("DEFAULT_LOCALE")); ok {
+		return locale
+	}
+	return LocaleID
+}
+
+// Parse converts a language tag (e.g. "en-US", "id") into a supported locale
+func Parse(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", false
+	}
+
+	// Only the primary subtag matters for our catalogs
+	if idx := strings.IndexAny(tag, "-_"); idx > 0 {
+		tag = tag[:idx]
+	}
+
+	switch Locale(tag) {
+	case LocaleID, LocaleEN:
+		return Locale(tag), true
+	}
+	return "", false
+}
+
+// Negotiate picks the best supported locale from an Accept-Language header
+func Negotiate(acceptLanguage string) Locale {
+	best := DefaultLocale()
+	bestQ := -1.0
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale, ok := Parse(fields[0])
+		if !ok {
+			continue
+		}
+
+		// Default quality is 1 when not specified
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if _, err := fmt.Sscanf(param[2:], "%g", &q); err != nil {
+					q = 0
+				}
+			}
+		}
+
+		if q > bestQ {
+			best = locale
+			bestQ = q
+		}
+	}
+
+	return best
+}
+
+// Middleware negotiates the request locale and stores it in the gin context
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := Negotiate(c.GetHeader("Accept-Language"))
+		c.Set(contextKey, locale)
+		c.Header("Content-Language", string(locale))
+		c.Next()
+	}
+}
+
+// FromContext returns the negotiated locale for the request
+func FromContext(c *gin.Context) Locale {
+	if val, exists := c.Get(contextKey); exists {
+		if locale, ok := val.(Locale); ok {
+			return locale
+		}
+	}
+	return Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// T translates a message key into the given locale, formatting any arguments
+func T(locale Locale, key string, args ...interface{}) string {
+	message, ok := catalogs[locale][key]
+	if !ok {
+		// Fall back to English, then to the key itself
+		if message, ok = catalogs[LocaleEN][key]; !ok {
+			return key
+		}
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Error is the body of a localized error response keyed by an error code. "error" stays in
+// English for logs and existing clients, "message" follows the negotiated locale and "code"
+// is stable for programmatic handling. fields (success, details, ...) are added as they are.
+func Error(c *gin.Context, code string, fields gin.H) gin.H {
+	body := gin.H{
+		"error":   T(LocaleEN, code),
+		"message": T(FromContext(c), code),
+		"code":    code,
+	}
+	for key, value := range fields {
+		body[key] = value
+	}
+	return body
+}
@@ -0,0 +1,78 @@
+package i18n
+
+// catalogs holds the translated messages of the errors the gateway answers itself, for
+// every supported locale. Keys are the error codes returned to clients.
+var catalogs = map[Locale]map[string]string{
+	LocaleEN: {
+		// Authentication
+		"AUTH_HEADER_REQUIRED": "Authorization header required",
+		"INVALID_AUTH_HEADER":  "Invalid authorization header format",
+		"INVALID_TOKEN":        "Invalid token",
+		"TOKEN_NOT_VALID":      "Token is not valid",
+		"INVALID_TOKEN_CLAIMS": "Invalid token claims",
+
+		// Upstreams
+		"REQUEST_CREATE_FAILED":       "Failed to create request",
+		"RESPONSE_READ_FAILED":        "Failed to read response",
+		"USER_SERVICE_UNAVAILABLE":    "User service unavailable",
+		"PRODUCT_SERVICE_UNAVAILABLE": "Product service unavailable",
+		"PRODUCT_SERVICE_TIMEOUT":     "Product service timed out",
+		"PAYMENT_SERVICE_UNAVAILABLE": "Payment service unavailable",
+		"PAYMENT_SERVICE_BUSY":        "Payment service is busy",
+		"PRODUCT_NOT_FOUND":           "Product not found",
+
+		// Maintenance
+		"SERVICE_MAINTENANCE": "Service under maintenance",
+
+		// Administration
+		"MAINTENANCE_ENABLE_FAILED":  "Failed to enable maintenance",
+		"MAINTENANCE_DISABLE_FAILED": "Failed to disable maintenance",
+		"ADMIN_TOKEN_REQUIRED":       "Admin token required",
+		"INVALID_REQUEST":            "Invalid request format",
+		"FEATURE_FLAG_UPDATE_FAILED": "Failed to update feature flag",
+		"UNKNOWN_SERVICE":            "Unknown service",
+		"CACHE_PURGE_FAILED":         "Failed to purge cache",
+		"UNKNOWN_CACHE_NAMESPACE":    "Unknown cache namespace",
+		"FAULT_ADD_FAILED":           "Failed to add fault",
+		"FAULT_NOT_FOUND":            "Fault not found",
+		"FIXTURES_CREATE_FAILED":     "Failed to create fixtures",
+		"SANDBOX_RESET_FAILED":       "Failed to reset sandbox data",
+		"GATEWAY_CACHE_PURGE_FAILED": "Failed to purge the gateway cache",
+	},
+	LocaleID: {
+		// Authentication
+		"AUTH_HEADER_REQUIRED": "Header Authorization wajib diisi",
+		"INVALID_AUTH_HEADER":  "Format header Authorization tidak valid",
+		"INVALID_TOKEN":        "Token tidak valid",
+		"TOKEN_NOT_VALID":      "Token sudah tidak berlaku",
+		"INVALID_TOKEN_CLAIMS": "Klaim token tidak valid",
+
+		// Upstreams
+		"REQUEST_CREATE_FAILED":       "Gagal membuat permintaan",
+		"RESPONSE_READ_FAILED":        "Gagal membaca respons",
+		"USER_SERVICE_UNAVAILABLE":    "Layanan pengguna sedang tidak tersedia",
+		"PRODUCT_SERVICE_UNAVAILABLE": "Layanan produk sedang tidak tersedia",
+		"PRODUCT_SERVICE_TIMEOUT":     "Layanan produk tidak merespons tepat waktu",
+		"PAYMENT_SERVICE_UNAVAILABLE": "Layanan pembayaran sedang tidak tersedia",
+		"PAYMENT_SERVICE_BUSY":        "Layanan pembayaran sedang sibuk, silakan coba lagi nanti",
+		"PRODUCT_NOT_FOUND":           "Produk tidak ditemukan",
+
+		// Maintenance
+		"SERVICE_MAINTENANCE": "Layanan sedang dalam pemeliharaan",
+
+		// Administration
+		"MAINTENANCE_ENABLE_FAILED":  "Gagal mengaktifkan mode maintenance",
+		"MAINTENANCE_DISABLE_FAILED": "Gagal menonaktifkan mode maintenance",
+		"ADMIN_TOKEN_REQUIRED":       "Token admin diperlukan",
+		"INVALID_REQUEST":            "Format request tidak valid",
+		"FEATURE_FLAG_UPDATE_FAILED": "Gagal memperbarui feature flag",
+		"UNKNOWN_SERVICE":            "Layanan tidak dikenal",
+		"CACHE_PURGE_FAILED":         "Gagal membersihkan cache",
+		"UNKNOWN_CACHE_NAMESPACE":    "Namespace cache tidak dikenal",
+		"FAULT_ADD_FAILED":           "Gagal menambahkan fault",
+		"FAULT_NOT_FOUND":            "Fault tidak ditemukan",
+		"FIXTURES_CREATE_FAILED":     "Gagal membuat data fixture",
+		"SANDBOX_RESET_FAILED":       "Gagal mereset data sandbox",
+		"GATEWAY_CACHE_PURGE_FAILED": "Gagal membersihkan cache gateway",
+	},
+}
@@ -0,0 +1,153 @@
+//go:build integration
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+// The integration tests run against the load test stack behind the gateway at BASE_URL
+// (`make test-integration`): release mode, the Midtrans mock, the account of
+// loadtest/seed-user.sql and the products of the product-service seed.
+
+var integrationClient = &http.Client{Timeout: 10 * time.Second}
+
+func integrationEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// call sends a JSON request to the gateway and decodes the JSON answer into out
+func call(t *testing.T, method, path, token string, body interface{}, out interface{}) int {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatalf("failed to encode %s %s: %v", method, path, err)
+		}
+	}
+	req, err := http.NewRequest(method, integrationEnv("BASE_URL", "http://localhost:5000")+path, &payload)
+	if err != nil {
+		t.Fatalf("invalid request %s %s: %v", method, path, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := integrationClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed, is the stack up (make integration-up)? %v", method, path, err)
+	}
+	defer res.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			t.Fatalf("invalid response of %s %s (%d): %v", method, path, res.StatusCode, err)
+		}
+	}
+	return res.StatusCode
+}
+
+// login returns an access token of the seeded load test account
+func login(t *testing.T) string {
+	t.Helper()
+	email := integrationEnv("LOADTEST_EMAIL", "loadtest@example.com")
+	var answer struct {
+		AccessToken string `json:"access_token"`
+	}
+	body := map[string]string{"identifier": email, "password": integrationEnv("LOADTEST_PASSWORD", "loadtest123")}
+	if status := call(t, http.MethodPost, "/api/v1/auth/login", "", body, &answer); status != http.StatusOK || answer.AccessToken == "" {
+		t.Fatalf("login as %s = %d, want 200 with a token, was loadtest/seed-user.sql applied?", email, status)
+	}
+	return answer.AccessToken
+}
+
+func TestIntegrationHealth(t *testing.T) {
+	for _, service := range []string{"user", "product", "payment"} {
+		t.Run(service, func(t *testing.T) {
+			if status := call(t, http.MethodGet, "/api/v1/"+service+"/health", "", nil, nil); status != http.StatusOK {
+				t.Errorf("GET /api/v1/%s/health = %d, want 200", service, status)
+			}
+		})
+	}
+}
+
+func TestIntegrationCheckout(t *testing.T) {
+	token := login(t)
+
+	var list struct {
+		Data struct {
+			Products []struct {
+				ID       string  `json:"id"`
+				Price    float64 `json:"price"`
+				Stock    int     `json:"stock"`
+				IsActive bool    `json:"is_active"`
+			} `json:"products"`
+		} `json:"data"`
+	}
+	if status := call(t, http.MethodGet, "/api/v1/products?limit=100", "", nil, &list); status != http.StatusOK {
+		t.Fatalf("GET /api/v1/products = %d, want 200", status)
+	}
+	productID, price := "", int64(0)
+	for _, product := range list.Data.Products {
+		if product.IsActive && product.Stock > 0 && product.Price == math.Trunc(product.Price) {
+			productID, price = product.ID, int64(product.Price)
+			break
+		}
+	}
+	if productID == "" {
+		t.Fatal("no active product with stock and a whole rupiah price, was the product seed run?")
+	}
+
+	type payment struct {
+		ID        string  `json:"id"`
+		OrderID   string  `json:"order_id"`
+		Amount    int64   `json:"amount"`
+		Status    string  `json:"status"`
+		VANumber  *string `json:"va_number"`
+		ProductID *string `json:"product_id"`
+	}
+	var created struct {
+		Success bool    `json:"success"`
+		Data    payment `json:"data"`
+	}
+	order := map[string]interface{}{
+		"product_id":     productID,
+		"amount":         price,
+		"admin_fee":      2500,
+		"payment_method": "bank_transfer",
+		"bank_type":      "bca",
+		"notes":          "integration test",
+	}
+	if status := call(t, http.MethodPost, "/api/v1/payments", token, order, &created); status != http.StatusOK || !created.Success {
+		t.Fatalf("POST /api/v1/payments = %d %+v, want 200 with success", status, created)
+	}
+	if created.Data.VANumber == nil || *created.Data.VANumber == "" {
+		t.Errorf("POST /api/v1/payments = %+v, want a VA number", created.Data)
+	}
+
+	var fetched struct {
+		Data payment `json:"data"`
+	}
+	if status := call(t, http.MethodGet, "/api/v1/payments/"+created.Data.ID, token, nil, &fetched); status != http.StatusOK {
+		t.Fatalf("GET /api/v1/payments/%s = %d, want 200", created.Data.ID, status)
+	}
+	if fetched.Data.OrderID != created.Data.OrderID || fetched.Data.Status != "PENDING" ||
+		fetched.Data.ProductID == nil || *fetched.Data.ProductID != productID {
+		t.Errorf("GET /api/v1/payments/%s = %+v, want the pending payment %s of product %s", created.Data.ID, fetched.Data, created.Data.OrderID, productID)
+	}
+}
+
+func TestIntegrationCheckoutUnauthenticated(t *testing.T) {
+	order := map[string]interface{}{"product_id": "00000000-0000-0000-0000-000000000000", "amount": 1000}
+	if status := call(t, http.MethodPost, "/api/v1/payments", "", order, nil); status != http.StatusUnauthorized {
+		t.Errorf("POST /api/v1/payments without a token = %d, want 401", status)
+	}
+}
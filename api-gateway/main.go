@@ -2,30 +2,83 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"api-gateway/apiversion"
+	"api-gateway/cache"
+	"api-gateway/chaos"
+	"api-gateway/i18n"
+	"api-gateway/maintenance"
 	"api-gateway/middleware"
+	"api-gateway/serviceauth"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
-const (
-	UserServiceURL     = "http://localhost:8081"
-	ProductServiceURL  = "http://localhost:8082"
-	PaymentServiceURL  = "http://localhost:8083"
+// Upstream services, overridden with USER_SERVICE_URL, PRODUCT_SERVICE_URL and
+// PAYMENT_SERVICE_URL (e.g. the compose service names)
+var (
+	UserServiceURL    = getEnv("USER_SERVICE_URL", "http://localhost:8081")
+	ProductServiceURL = getEnv("PRODUCT_SERVICE_URL", "http://localhost:8082")
+	PaymentServiceURL = getEnv("PAYMENT_SERVICE_URL", "http://localhost:8083")
 )
 
 func main() {
-	r := gin.Default()
+	// -healthcheck probes the running gateway, for the compose healthcheck
+	healthcheck := flag.Bool("healthcheck", false, "check /health of the running gateway and exit")
+	flag.Parse()
+	if *healthcheck {
+		if !runHealthcheck() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	r := newRouter()
+
+	// Optional Redis cache for public product reads (GATEWAY_CACHE_ENABLED=true)
+	responseCache := cache.NewResponseCache()
+	defer responseCache.Close()
+	invalidator, err := cache.StartInvalidator(responseCache)
+	if err != nil {
+		log.Printf("⚠️ Gateway cache invalidation disabled, entries expire by TTL only: %v", err)
+	}
+	defer invalidator.Close()
+
+	// Feature flags (Redis backed, FEATURE_<NAME> overrides)
+	featureFlags = newFeatureFlags()
+	featureFlags.Start()
+	defer featureFlags.Stop()
+
+	// Maintenance windows (Redis backed, managed via /api/v1/admin/maintenance)
+	maintenanceWindows = maintenance.NewStore(maintenanceScopes...)
+	maintenanceWindows.Start()
+	defer maintenanceWindows.Stop()
+
+	// Fault injection for resilience testing (CHAOS_ENABLED, never in production), before
+	// the upstream clients are created
+	initChaos()
+
+	// Upstream retry/timeout policies
+	initUpstreams()
+
+	// Hedged product reads (PRODUCT_HEDGE_DELAY)
+	productHedging = newHedger()
+
+	// Admission control for payment creation (PAYMENT_ADMISSION_CONCURRENCY)
+	paymentAdmission = newAdmissionQueue()
 
 	// CORS middleware
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
 
 		if c.Request.Method == "OPTIONS" {
@@ -36,6 +89,14 @@ func main() {
 		c.Next()
 	})
 
+	// Maintenance mode, checked before authentication and forwarding
+	r.Use(maintenanceGate())
+
+	// Injected route faults, after maintenance so a window still answers 503
+	if chaos.Enabled() {
+		r.Use(chaos.Middleware(chaosExempt))
+	}
+
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -44,124 +105,226 @@ func main() {
 		})
 	})
 
+	// Redis topology health (REDIS_MODE standalone, sentinel or cluster)
+	registerRedisHealthRoute(r)
+
+	// Routes are forwarded with their original method, path and query string; the gateway
+	// mirrors the services' layout under every API version so new endpoints need no gateway
+	// change, and /api/v2 requests reach the services' v2 handlers.
+	api := apiversion.New(r, "v1", "v2")
+
 	// User Service Routes
-	userRoutes := r.Group("/api/v1")
+	userRoutes := api.Group("")
 	{
 		// Health check for user service
-		userRoutes.GET("/user/health", proxyToUserService("GET", "/health"))
+		userRoutes.GET("/user/health", proxyToUserService("/health"))
 
 		// Authentication routes
 		authRoutes := userRoutes.Group("/auth")
 		{
-			authRoutes.POST("/register", proxyToUserService("POST", "/api/v1/auth/register"))
-			authRoutes.POST("/login", proxyToUserService("POST", "/api/v1/auth/login"))
-			authRoutes.POST("/verify-otp", proxyToUserService("POST", "/api/v1/auth/verify-otp"))
-			authRoutes.POST("/resend-otp", proxyToUserService("POST", "/api/v1/auth/resend-otp"))
-			authRoutes.POST("/refresh-token", proxyToUserService("POST", "/api/v1/auth/refresh-token"))
-			authRoutes.POST("/google-oauth", proxyToUserService("POST", "/api/v1/auth/google-oauth"))
-			authRoutes.POST("/request-reset-password", proxyToUserService("POST", "/api/v1/auth/request-reset-password"))
-			authRoutes.POST("/verify-reset-password", proxyToUserService("POST", "/api/v1/auth/verify-reset-password"))
+			authRoutes.Any("/*path", proxyToUserService(""))
 		}
 
-		// Protected user routes
+		// Protected user routes (the token is validated by user service).
+		// Parameter routes instead of a catch-all so /user/health above can coexist.
 		userProtectedRoutes := userRoutes.Group("/user")
 		{
-			userProtectedRoutes.GET("/profile", proxyToUserService("GET", "/api/v1/user/profile"))
-			userProtectedRoutes.PUT("/profile", proxyToUserService("PUT", "/api/v1/user/profile"))
+			userProtectedRoutes.Any("/:action", proxyToUserService(""))
+			userProtectedRoutes.Any("/:action/*rest", proxyToUserService(""))
 		}
+
+		// Email provider bounce and complaint webhooks (the secret is checked by user service)
+		userRoutes.POST("/webhooks/email/:provider", proxyToUserService(""))
 	}
 
 	// Product Service Routes
-	productRoutes := r.Group("/api/v1")
+	productRoutes := api.Group("")
 	{
 		// Health check for product service
-		productRoutes.GET("/product/health", proxyToProductService("GET", "/health"))
+		productRoutes.GET("/product/health", proxyToProductService("/health"))
 
-		// Product routes
+		// Public catalogue routes (read only)
 		products := productRoutes.Group("/products")
+		products.Use(responseCache.Middleware(gatewayCacheEnabled))
+		{
+			products.GET("", proxyToProductService(""))
+			products.GET("/*path", proxyToProductService(""))
+		}
+
+		// Seller routes (require authentication)
+		seller := productRoutes.Group("/seller/products")
+		seller.Use(middleware.AuthMiddleware(jwtKeyFunc()), responseCache.InvalidateOnWrite())
 		{
-			products.GET("", proxyToProductService("GET", "/api/v1/products"))
-			products.GET("/:id", proxyToProductService("GET", "/api/v1/products/:id"))
+			seller.Any("", proxyToProductService(""))
+			seller.Any("/*path", proxyToProductService(""))
+		}
+
+		// Public store pages (read only)
+		stores := productRoutes.Group("/stores")
+		stores.Use(responseCache.Middleware(gatewayCacheEnabled))
+		{
+			stores.GET("", proxyToProductService(""))
+			stores.GET("/*path", proxyToProductService(""))
+		}
+
+		// Seller store management (require authentication)
+		sellerStores := productRoutes.Group("/seller/stores")
+		sellerStores.Use(middleware.AuthMiddleware(jwtKeyFunc()), responseCache.InvalidateOnWrite())
+		{
+			sellerStores.Any("", proxyToProductService(""))
+			sellerStores.Any("/*path", proxyToProductService(""))
 		}
 	}
 
 	// Payment Service Routes
-	paymentRoutes := r.Group("/api/v1")
+	paymentRoutes := api.Group("")
 	{
 		// Health check for payment service
-		paymentRoutes.GET("/payment/health", proxyToPaymentService("GET", "/health"))
+		paymentRoutes.GET("/payment/health", proxyToPaymentService("/health"))
 
 		// Payment routes
 		payments := paymentRoutes.Group("/payments")
 		{
 			// Public routes
-			payments.GET("/config", proxyToPaymentService("GET", "/api/v1/payments/config"))
-			payments.POST("/midtrans/callback", proxyToPaymentService("POST", "/api/v1/payments/midtrans/callback"))
+			payments.GET("/config", proxyToPaymentService(""))
+			payments.POST("/midtrans/callback", proxyToPaymentService(""))
+			payments.POST("/midtrans/callback/test", proxyToPaymentService("")) // admin token checked by payment service
+			payments.GET("/links/:token", proxyToPaymentService(""))
+			payments.POST("/links/:token/pay", proxyToPaymentService(""))
+			payments.GET("/files/*key", proxyToPaymentService("")) // signed download links, checked by payment service
 
 			// Protected routes (require authentication)
-			jwtSecret := os.Getenv("JWT_SECRET")
-			if jwtSecret == "" {
-				jwtSecret = "your-super-secret-jwt-key-change-this-in-production" // Default for development
-			}
-			
 			protected := payments.Group("")
-			protected.Use(middleware.AuthMiddleware(jwtSecret))
+			protected.Use(middleware.AuthMiddleware(jwtKeyFunc()))
 			{
-				protected.POST("", proxyToPaymentService("POST", "/api/v1/payments"))
-				protected.GET("/:id/check-status", proxyToPaymentService("GET", "/api/v1/payments/:id/check-status"))
-				protected.GET("/:id", proxyToPaymentService("GET", "/api/v1/payments/:id"))
-				protected.GET("/order/:order_id", proxyToPaymentService("GET", "/api/v1/payments/order/:order_id"))
-				protected.GET("/user", proxyToPaymentService("GET", "/api/v1/payments/user"))
+				protected.Any("", admitPaymentCreation(), proxyToPaymentService(""))
+				protected.Any("/links", proxyToPaymentService(""))
+				protected.DELETE("/links/:token", proxyToPaymentService(""))
+				protected.Any("/:id", proxyToPaymentService(""))
+				protected.Any("/:id/*rest", proxyToPaymentService(""))
 			}
 		}
+
+		// A seller's view of the payments for one of their products, ownership checked by payment service
+		paymentRoutes.GET("/seller/payments", middleware.AuthMiddleware(jwtKeyFunc()), proxyToPaymentService("/api/v1/payments/seller"))
+	}
+
+	api.Mount()
+
+	// Backend-for-frontend routes composing several upstream calls into one payload
+	productPage := newProductPageBFF()
+	for _, dep := range []bffDependency{productPage.product, productPage.reviews, productPage.wishlist} {
+		log.Printf("🧩 BFF product page dependency %s", dep)
+	}
+	bffRoutes := r.Group("/api/v1/bff")
+	bffRoutes.Use(middleware.OptionalAuthMiddleware(jwtKeyFunc()))
+	{
+		bffRoutes.GET("/product/:id", productPage.ProductPage)
+	}
+
+	// Storefront graph over the same upstreams
+	registerGraphQLRoutes(r)
+
+	// Debug and runtime diagnostics endpoints (admin token required)
+	registerDebugRoutes(r)
+	admin := registerAdminRoutes(r, gatewayStats)
+	if admin != nil {
+		registerFlagRoutes(admin)
+		registerMaintenanceRoutes(admin)
+		registerChaosRoutes(admin)
+		registerCacheAdminRoutes(admin, responseCache)
 	}
 
-	log.Println("🚀 API Gateway running on http://localhost:8080")
+	// Demo environment tools (SANDBOX_TOOLS, never in production)
+	registerSandboxRoutes(r, admin, responseCache)
+
+	addr := listenAddr()
+	log.Printf("🚀 API Gateway running on http://localhost:%s (listening on %s)", getEnv("PORT", "8080"), addr)
+	log.Printf("🔗 Upstreams: user %s, product %s, payment %s", UserServiceURL, ProductServiceURL, PaymentServiceURL)
 	log.Println("📚 Available endpoints:")
 	log.Println("  POST /api/v1/auth/register     - Register new user")
 	log.Println("  POST /api/v1/auth/login        - Login user")
 	log.Println("  POST /api/v1/auth/verify-otp   - Verify OTP")
+	log.Println("  GET  /api/v1/auth/verify-email - Verify email via link")
+	log.Println("  GET  /api/v1/auth/revoke-sessions - Sign out everywhere via login alert link")
 	log.Println("  POST /api/v1/auth/resend-otp   - Resend OTP")
 	log.Println("  POST /api/v1/auth/refresh-token - Refresh JWT token")
 	log.Println("  POST /api/v1/auth/google-oauth - Google OAuth login")
 	log.Println("  POST /api/v1/auth/request-reset-password - Request password reset")
 	log.Println("  POST /api/v1/auth/verify-reset-password - Verify reset password")
+	log.Println("  GET  /api/v1/auth/check-username?u= - Check username availability")
+	log.Println("  GET  /api/v1/auth/check-email?e= - Check email availability")
 	log.Println("  GET  /api/v1/user/profile      - Get user profile (protected)")
 	log.Println("  PUT  /api/v1/user/profile      - Update user profile (protected)")
+	log.Println("  POST /api/v1/user/change-password - Change password (protected)")
+	log.Println("  POST /api/v1/webhooks/email/:provider - Email bounce and complaint webhook (ses, sendgrid)")
 	log.Println("  GET  /api/v1/products          - Get all products")
 	log.Println("  GET  /api/v1/products/:id      - Get product by ID")
-	log.Println("  POST /api/v1/payments          - Create payment")
+	log.Println("  GET  /api/v1/stores            - List stores")
+	log.Println("  *    /api/v1/seller/stores     - Manage seller stores (protected)")
+	log.Println("  GET  /api/v1/bff/product/:id   - Product page (product, reviews summary, wishlist flag)")
+	log.Println("  POST /api/v1/payments          - Create payment (queued by PAYMENT_ADMISSION_*)")
 	log.Println("  GET  /api/v1/payments/:id      - Get payment by ID")
+	log.Println("  GET  /api/v1/seller/products/:id/stock-movements - Stock audit trail (protected)")
+	log.Println("  POST /api/v1/seller/products/:id/stock - Restock or adjust stock (protected)")
 	log.Println("  GET  /api/v1/payments/:id/check-status - Check payment status from Midtrans")
+	log.Println("  GET  /api/v1/payments/:id/invoice - Download the PDF invoice of an invoice payment")
 	log.Println("  GET  /api/v1/payments/order/:id - Get payment by order ID")
+	log.Println("  GET  /api/v1/payments/orders/:ref - Get payment attempts of an order")
 	log.Println("  GET  /api/v1/payments/user     - Get user payments")
+	log.Println("  GET  /api/v1/seller/payments?product_id= - Payments for a seller's product (protected)")
 	log.Println("  GET  /api/v1/payments/config   - Get Midtrans config")
 	log.Println("  POST /api/v1/payments/midtrans/callback - Midtrans webhook")
+	log.Println("  POST /api/v1/payments/midtrans/callback/test - Simulate Midtrans callback (sandbox, admin)")
+	log.Println("  POST /api/v1/dev/fixtures      - Create end to end test user, product and payment (SANDBOX_TOOLS)")
+	log.Println("  GET  /api/v1/admin/cache         - Cache keys and hit rates of the gateway and services (admin)")
+	log.Println("  DELETE /api/v1/admin/cache/:namespace?id=&service= - Purge a cache namespace everywhere (admin)")
+	log.Println("  POST /api/v1/admin/sandbox/reset - Delete payments, reseed products, clear caches (admin, SANDBOX_TOOLS)")
+	log.Println("  *    /api/v1/admin/chaos/faults  - Inject latency, errors or dropped connections (admin, CHAOS_ENABLED)")
 	log.Println("  GET  /health                   - Health check")
+	log.Println("  GET  /health/redis             - Redis topology health (standalone, sentinel or cluster)")
+	log.Println("  *    /api/v1/{auth,user,stores,seller/products,seller/stores,payments}/... - Forwarded with original method and query")
+	log.Println("  *    /api/v2/...                   - Same routes, forwarded to the services' v2 handlers")
 
-	r.Run(":8080")
+	if err := r.Run(addr); err != nil {
+		log.Fatalf("❌ Failed to start server: %v", err)
+	}
+}
+
+// upstreamPath resolves the path and query forwarded to a service. An empty path forwards
+// the gateway path unchanged, otherwise :param placeholders are filled from the route.
+// The raw query string is always kept (e.g. verify-email?token=, products?page=2).
+func upstreamPath(c *gin.Context, path string) string {
+	if path == "" {
+		path = c.Request.URL.Path
+	} else {
+		for _, param := range c.Params {
+			path = strings.Replace(path, ":"+param.Key, param.Value, -1)
+		}
+	}
+
+	if c.Request.URL.RawQuery != "" {
+		path += "?" + c.Request.URL.RawQuery
+	}
+	return path
 }
 
 // proxyToUserService creates a proxy handler for user service
-func proxyToUserService(method, path string) gin.HandlerFunc {
+func proxyToUserService(path string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		middleware.SetUpstream(c, serviceauth.UserService)
+
 		// Read request body
 		var bodyBytes []byte
 		if c.Request.Body != nil {
 			bodyBytes, _ = io.ReadAll(c.Request.Body)
 		}
 
-		// Replace URL parameters with actual values
-		actualPath := path
-		for _, param := range c.Params {
-			actualPath = strings.Replace(actualPath, ":"+param.Key, param.Value, -1)
-		}
-
 		// Create new request to user service
-		url := UserServiceURL + actualPath
-		req, err := http.NewRequest(method, url, bytes.NewBuffer(bodyBytes))
+		url := UserServiceURL + upstreamPath(c, path)
+		req, err := http.NewRequest(c.Request.Method, url, bytes.NewBuffer(bodyBytes))
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Failed to create request"})
+			c.JSON(500, i18n.Error(c, "REQUEST_CREATE_FAILED", nil))
 			return
 		}
 
@@ -172,11 +335,13 @@ func proxyToUserService(method, path string) gin.HandlerFunc {
 			}
 		}
 
+		// Pass the client address on for login device tracking
+		req.Header.Set("X-Forwarded-For", c.ClientIP())
+
 		// Make request to user service
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		resp, err := doUpstream(userServiceClient, req)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "User service unavailable"})
+			c.JSON(500, i18n.Error(c, "USER_SERVICE_UNAVAILABLE", nil))
 			return
 		}
 		defer resp.Body.Close()
@@ -184,7 +349,7 @@ func proxyToUserService(method, path string) gin.HandlerFunc {
 		// Read response body
 		respBody, err := io.ReadAll(resp.Body)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Failed to read response"})
+			c.JSON(500, i18n.Error(c, "RESPONSE_READ_FAILED", nil))
 			return
 		}
 
@@ -201,25 +366,23 @@ func proxyToUserService(method, path string) gin.HandlerFunc {
 }
 
 // proxyToProductService creates a proxy handler for product service
-func proxyToProductService(method, path string) gin.HandlerFunc {
+func proxyToProductService(path string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		middleware.SetUpstream(c, serviceauth.ProductService)
+
 		// Read request body
 		var bodyBytes []byte
 		if c.Request.Body != nil {
 			bodyBytes, _ = io.ReadAll(c.Request.Body)
 		}
 
-		// Replace URL parameters with actual values
-		actualPath := path
-		for _, param := range c.Params {
-			actualPath = strings.Replace(actualPath, ":"+param.Key, param.Value, -1)
-		}
+		actualPath := upstreamPath(c, path)
 
 		// Create new request to product service
 		url := ProductServiceURL + actualPath
-		req, err := http.NewRequest(method, url, bytes.NewBuffer(bodyBytes))
+		req, err := http.NewRequest(c.Request.Method, url, bytes.NewBuffer(bodyBytes))
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Failed to create request"})
+			c.JSON(500, i18n.Error(c, "REQUEST_CREATE_FAILED", nil))
 			return
 		}
 
@@ -230,11 +393,34 @@ func proxyToProductService(method, path string) gin.HandlerFunc {
 			}
 		}
 
+		// Forward the authenticated user, never a client supplied one
+		req.Header.Del("X-User-ID")
+		if userID, exists := c.Get("user_id"); exists {
+			req.Header.Set("X-User-ID", userID.(string))
+		}
+
+		// Idempotent reads are hedged across replicas when enabled
+		if c.Request.Method == http.MethodGet && productHedging != nil {
+			result := productHedging.Do(c, actualPath, req.Header)
+			if result.err != nil {
+				c.JSON(500, i18n.Error(c, "PRODUCT_SERVICE_UNAVAILABLE", nil))
+				return
+			}
+
+			for key, values := range result.header {
+				for _, value := range values {
+					c.Header(key, value)
+				}
+			}
+
+			c.Data(result.status, result.header.Get("Content-Type"), result.body)
+			return
+		}
+
 		// Make request to product service
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		resp, err := doUpstream(productServiceClient, req)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Product service unavailable"})
+			c.JSON(500, i18n.Error(c, "PRODUCT_SERVICE_UNAVAILABLE", nil))
 			return
 		}
 		defer resp.Body.Close()
@@ -242,7 +428,7 @@ func proxyToProductService(method, path string) gin.HandlerFunc {
 		// Read response body
 		respBody, err := io.ReadAll(resp.Body)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Failed to read response"})
+			c.JSON(500, i18n.Error(c, "RESPONSE_READ_FAILED", nil))
 			return
 		}
 
@@ -259,25 +445,21 @@ func proxyToProductService(method, path string) gin.HandlerFunc {
 }
 
 // proxyToPaymentService creates a proxy handler for payment service
-func proxyToPaymentService(method, path string) gin.HandlerFunc {
+func proxyToPaymentService(path string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		middleware.SetUpstream(c, serviceauth.PaymentService)
+
 		// Read request body
 		var bodyBytes []byte
 		if c.Request.Body != nil {
 			bodyBytes, _ = io.ReadAll(c.Request.Body)
 		}
 
-		// Replace URL parameters with actual values
-		actualPath := path
-		for _, param := range c.Params {
-			actualPath = strings.Replace(actualPath, ":"+param.Key, param.Value, -1)
-		}
-
 		// Create new request to payment service
-		url := PaymentServiceURL + actualPath
-		req, err := http.NewRequest(method, url, bytes.NewBuffer(bodyBytes))
+		url := PaymentServiceURL + upstreamPath(c, path)
+		req, err := http.NewRequest(c.Request.Method, url, bytes.NewBuffer(bodyBytes))
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Failed to create request"})
+			c.JSON(500, i18n.Error(c, "REQUEST_CREATE_FAILED", nil))
 			return
 		}
 
@@ -288,6 +470,9 @@ func proxyToPaymentService(method, path string) gin.HandlerFunc {
 			}
 		}
 
+		// Forward the client address (Midtrans callback allowlisting relies on it)
+		req.Header.Set("X-Forwarded-For", c.ClientIP())
+
 		// Add user context headers for payment service
 		if userID, exists := c.Get("user_id"); exists {
 			req.Header.Set("X-User-ID", userID.(string))
@@ -300,10 +485,9 @@ func proxyToPaymentService(method, path string) gin.HandlerFunc {
 		}
 
 		// Make request to payment service
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		resp, err := doUpstream(paymentServiceClient, req)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Payment service unavailable"})
+			c.JSON(500, i18n.Error(c, "PAYMENT_SERVICE_UNAVAILABLE", nil))
 			return
 		}
 		defer resp.Body.Close()
@@ -311,7 +495,7 @@ func proxyToPaymentService(method, path string) gin.HandlerFunc {
 		// Read response body
 		respBody, err := io.ReadAll(resp.Body)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Failed to read response"})
+			c.JSON(500, i18n.Error(c, "RESPONSE_READ_FAILED", nil))
 			return
 		}
 
@@ -326,3 +510,38 @@ func proxyToPaymentService(method, path string) gin.HandlerFunc {
 		c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), respBody)
 	}
 }
+
+// exampleJWTSecret is the JWT_SECRET of env.example, only accepted in development
+const exampleJWTSecret = "your-super-secret-jwt-key-change-this-in-production"
+
+// jwtKeyFunc builds the token key resolver: RS256/ES256 tokens are validated against the
+// user-service JWKS, HS256 with JWT_SECRET is kept as a fallback unless JWT_ALLOW_HS256=false
+// or JWT_SECRET is unset
+func jwtKeyFunc() jwt.Keyfunc {
+	jwksURL := os.Getenv("JWKS_URL")
+	if jwksURL == "" {
+		jwksURL = UserServiceURL + "/.well-known/jwks.json"
+	}
+
+	jwksTTL := 10 * time.Minute
+	if ttl := os.Getenv("JWKS_CACHE_TTL"); ttl != "" {
+		if parsed, err := time.ParseDuration(ttl); err == nil {
+			jwksTTL = parsed
+		}
+	}
+
+	// Without JWT_SECRET there is no HS256 fallback, a default secret would be public and let
+	// anyone sign tokens for any user
+	jwtSecret := ""
+	if os.Getenv("JWT_ALLOW_HS256") != "false" {
+		jwtSecret = os.Getenv("JWT_SECRET")
+		switch {
+		case jwtSecret == "":
+			log.Println("⚠️ JWT_SECRET not set, HS256 tokens are rejected")
+		case jwtSecret == exampleJWTSecret && appEnv() != "development":
+			log.Fatalf("❌ JWT_SECRET is the example secret from env.example, set a secret of your own or JWT_ALLOW_HS256=false")
+		}
+	}
+
+	return middleware.KeyFunc(jwtSecret, middleware.NewJWKSCache(jwksURL, jwksTTL))
+}
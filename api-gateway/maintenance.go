@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"api-gateway/i18n"
+	"api-gateway/maintenance"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maintenanceWindows is configured in main
+var maintenanceWindows *maintenance.Store
+
+// maintenanceScopes are the route groups that can be put into maintenance on their own,
+// named after the first path segment under /api/<version>
+var maintenanceScopes = []string{"auth", "user", "products", "stores", "seller", "payments", "bff", "webhooks"}
+
+// defaultMaintenanceMessage is sent when a window is enabled without a message
+const defaultMaintenanceMessage = "The service is under maintenance, please retry later"
+
+// maintenanceScope returns the route group of an API path. Exempt paths (health checks,
+// admin and debug endpoints) are never put into maintenance, so operators can lift it.
+func maintenanceScope(path string) (scope string, exempt bool) {
+	if !strings.HasPrefix(path, "/api/") || strings.HasSuffix(path, "/health") {
+		return "", true
+	}
+
+	segments := strings.SplitN(strings.TrimPrefix(path, "/api/"), "/", 3)
+	if len(segments) < 2 || segments[1] == "admin" {
+		return "", true
+	}
+	return segments[1], false
+}
+
+// maintenanceGate answers 503 with Retry-After for requests in a scope under maintenance,
+// before they are authenticated or forwarded
+func maintenanceGate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, exempt := maintenanceScope(c.Request.URL.Path)
+		if exempt {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		window, ok := maintenanceWindows.Active(scope, now)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		seconds := int(math.Ceil(window.RetryAfterAt(now).Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, i18n.Error(c, "SERVICE_MAINTENANCE", gin.H{
+			"success":     false,
+			"details":     window.Message,
+			"maintenance": window,
+			"retry_after": seconds,
+		}))
+	}
+}
+
+// registerMaintenanceRoutes exposes GET /api/v1/admin/maintenance and
+// PUT|DELETE /api/v1/admin/maintenance/:scope
+func registerMaintenanceRoutes(admin *gin.RouterGroup) {
+	admin.GET("/maintenance", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"windows": maintenanceWindows.List(time.Now()),
+			"scopes":  maintenanceWindows.Scopes(),
+		})
+	})
+
+	admin.PUT("/maintenance/:scope", func(c *gin.Context) {
+		var req struct {
+			Message    string     `json:"message"`
+			RetryAfter int        `json:"retry_after"` // seconds, used when until is not set
+			Until      *time.Time `json:"until"`       // lifted automatically at this time
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, i18n.Error(c, "INVALID_REQUEST", gin.H{"details": err.Error()}))
+			return
+		}
+		if req.Message == "" {
+			req.Message = defaultMaintenanceMessage
+		}
+
+		window, err := maintenanceWindows.Enable(c.Request.Context(), maintenance.Window{
+			Scope:      c.Param("scope"),
+			Message:    req.Message,
+			RetryAfter: req.RetryAfter,
+			Until:      req.Until,
+		})
+		if err != nil {
+			c.JSON(maintenanceErrorStatus(err), i18n.Error(c, "MAINTENANCE_ENABLE_FAILED", gin.H{"details": err.Error()}))
+			return
+		}
+
+		log.Printf("🚧 Maintenance enabled for %s: %s", window.Scope, window.Message)
+		c.JSON(http.StatusOK, gin.H{"window": window})
+	})
+
+	admin.DELETE("/maintenance/:scope", func(c *gin.Context) {
+		scope := c.Param("scope")
+		removed, err := maintenanceWindows.Disable(c.Request.Context(), scope)
+		if err != nil {
+			c.JSON(maintenanceErrorStatus(err), i18n.Error(c, "MAINTENANCE_DISABLE_FAILED", gin.H{"details": err.Error()}))
+			return
+		}
+
+		if removed {
+			log.Printf("✅ Maintenance lifted for %s", scope)
+		}
+		c.JSON(http.StatusOK, gin.H{"scope": scope, "removed": removed})
+	})
+}
+
+// maintenanceErrorStatus maps maintenance store errors to response statuses
+func maintenanceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, maintenance.ErrUnknownScope):
+		return http.StatusNotFound
+	case errors.Is(err, maintenance.ErrInvalidWindow):
+		return http.StatusBadRequest
+	case errors.Is(err, maintenance.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,267 @@
+// Package maintenance keeps the gateway's maintenance windows: the whole API or single route
+// groups answer 503 while one is active, e.g. during a Midtrans outage or a database
+// migration. Windows are stored in a Redis hash so they survive restarts and reach every
+// gateway instance, cached in memory and refreshed periodically like feature flags.
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"api-gateway/redisconn"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ScopeAll puts every route group into maintenance
+const ScopeAll = "all"
+
+// redisKey is the hash holding the active windows, one JSON field per scope
+const redisKey = "gateway_maintenance"
+
+// DefaultRetryAfter is sent when a window has neither an end nor a retry hint
+const DefaultRetryAfter = 5 * time.Minute
+
+var (
+	// ErrUnknownScope is returned for scopes the gateway doesn't route
+	ErrUnknownScope = errors.New("unknown maintenance scope")
+	// ErrInvalidWindow is returned for windows ending in the past or with a negative retry hint
+	ErrInvalidWindow = errors.New("maintenance window must end in the future with a retry_after of 0 or more")
+	// ErrUnavailable is returned when changing windows without Redis
+	ErrUnavailable = errors.New("maintenance storage is not available")
+)
+
+// Window is a maintenance window of a scope. It lasts until Until, or until it is lifted
+// when Until is nil.
+type Window struct {
+	Scope      string     `json:"scope"`
+	Message    string     `json:"message"`
+	RetryAfter int        `json:"retry_after"` // seconds clients should wait when Until is nil
+	StartedAt  time.Time  `json:"started_at"`
+	Until      *time.Time `json:"until,omitempty"`
+}
+
+// active reports whether the window still applies at now
+func (w Window) active(now time.Time) bool {
+	return w.Until == nil || now.Before(*w.Until)
+}
+
+// RetryAfterAt returns how long clients should wait at now: until the window ends, or its
+// retry hint
+func (w Window) RetryAfterAt(now time.Time) time.Duration {
+	if w.Until != nil {
+		return w.Until.Sub(now)
+	}
+	if w.RetryAfter > 0 {
+		return time.Duration(w.RetryAfter) * time.Second
+	}
+	return DefaultRetryAfter
+}
+
+// Store answers which scopes are in maintenance from an in-memory snapshot
+type Store struct {
+	client  redis.UniversalClient // nil while Redis is unreachable at start, nothing is in maintenance then
+	scopes  map[string]bool
+	refresh time.Duration
+
+	mu      sync.RWMutex
+	windows map[string]Window
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewStore connects to Redis (REDIS_HOST, REDIS_PASSWORD, REDIS_DB) and creates a store for
+// ScopeAll and the given route group scopes. MAINTENANCE_REFRESH sets how often Redis is
+// re-read (default 5s), which bounds how long other instances take to follow a change.
+func NewStore(scopes ...string) *Store {
+	refresh := 5 * time.Second
+	if value := os.Getenv("MAINTENANCE_REFRESH"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			refresh = parsed
+		} else {
+			log.Printf("⚠️ Ignoring invalid MAINTENANCE_REFRESH=%q", value)
+		}
+	}
+
+	s := &Store{
+		client:  connect(),
+		scopes:  map[string]bool{ScopeAll: true},
+		refresh: refresh,
+		windows: make(map[string]Window),
+		stopCh:  make(chan struct{}),
+	}
+	for _, scope := range scopes {
+		s.scopes[scope] = true
+	}
+	return s
+}
+
+// Start loads the stored windows and keeps them refreshed until Stop
+func (s *Store) Start() {
+	s.Reload(context.Background())
+	if s.client == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.Reload(context.Background())
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the refresh loop and closes the Redis connection
+func (s *Store) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		if s.client != nil {
+			s.client.Close()
+		}
+	})
+}
+
+// connect returns a Redis client (see redisconn.FromEnv), or nil when Redis is unreachable
+func connect() redis.UniversalClient {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, _, err := redisconn.Connect(ctx)
+	if err != nil {
+		log.Printf("⚠️ Maintenance mode unavailable: %v", err)
+		return nil
+	}
+	return client
+}
+
+// Reload re-reads the stored windows. On a Redis error the previous snapshot is kept, so an
+// outage neither starts nor lifts maintenance.
+func (s *Store) Reload(ctx context.Context) {
+	if s.client == nil {
+		return
+	}
+	stored, err := s.client.HGetAll(ctx, redisKey).Result()
+	if err != nil {
+		log.Printf("⚠️ Failed to load maintenance windows, keeping previous state: %v", err)
+		return
+	}
+
+	windows := make(map[string]Window, len(stored))
+	for scope, raw := range stored {
+		var window Window
+		if err := json.Unmarshal([]byte(raw), &window); err != nil {
+			log.Printf("⚠️ Ignoring malformed maintenance window %s: %v", scope, err)
+			continue
+		}
+		window.Scope = scope
+		windows[scope] = window
+	}
+
+	s.mu.Lock()
+	s.windows = windows
+	s.mu.Unlock()
+}
+
+// Active returns the window covering scope at now, ScopeAll first
+func (s *Store) Active(scope string, now time.Time) (Window, bool) {
+	if s == nil {
+		return Window{}, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, candidate := range []string{ScopeAll, scope} {
+		if window, ok := s.windows[candidate]; ok && window.active(now) {
+			return window, true
+		}
+	}
+	return Window{}, false
+}
+
+// List returns the windows active at now sorted by scope
+func (s *Store) List(now time.Time) []Window {
+	s.mu.RLock()
+	list := make([]Window, 0, len(s.windows))
+	for _, window := range s.windows {
+		if window.active(now) {
+			list = append(list, window)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Scope < list[j].Scope })
+	return list
+}
+
+// Scopes returns the scopes that can be put into maintenance, sorted
+func (s *Store) Scopes() []string {
+	scopes := make([]string, 0, len(s.scopes))
+	for scope := range s.scopes {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// Enable stores a window for its scope, replacing any other, and applies it locally at
+// once; other instances pick it up on their next refresh. A window past its end stays in
+// Redis, ignored, until it is replaced or disabled.
+func (s *Store) Enable(ctx context.Context, window Window) (Window, error) {
+	if !s.scopes[window.Scope] {
+		return Window{}, ErrUnknownScope
+	}
+	now := time.Now()
+	if window.RetryAfter < 0 || (window.Until != nil && !window.Until.After(now)) {
+		return Window{}, ErrInvalidWindow
+	}
+	if s.client == nil {
+		return Window{}, ErrUnavailable
+	}
+
+	window.StartedAt = now
+	data, err := json.Marshal(window)
+	if err != nil {
+		return Window{}, err
+	}
+	if err := s.client.HSet(ctx, redisKey, window.Scope, data).Err(); err != nil {
+		return Window{}, fmt.Errorf("failed to store maintenance window: %w", err)
+	}
+
+	s.mu.Lock()
+	s.windows[window.Scope] = window
+	s.mu.Unlock()
+	return window, nil
+}
+
+// Disable lifts the window of a scope, reporting whether one was stored
+func (s *Store) Disable(ctx context.Context, scope string) (bool, error) {
+	if !s.scopes[scope] {
+		return false, ErrUnknownScope
+	}
+	if s.client == nil {
+		return false, ErrUnavailable
+	}
+
+	removed, err := s.client.HDel(ctx, redisKey, scope).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove maintenance window: %w", err)
+	}
+
+	s.mu.Lock()
+	delete(s.windows, scope)
+	s.mu.Unlock()
+	return removed > 0, nil
+}
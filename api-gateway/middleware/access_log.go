@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway/redact"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the request ID to the services and back to the client
+const RequestIDHeader = "X-Request-ID"
+
+// upstreamKey is the context key the proxy handlers record the upstream service under
+const upstreamKey = "upstream"
+
+// SetUpstream records the service a request is forwarded to, for the access log
+func SetUpstream(c *gin.Context, service string) {
+	c.Set(upstreamKey, service)
+}
+
+// AccessLogConfig configures the structured access log
+type AccessLogConfig struct {
+	Enabled bool
+	// Output receives one JSON object per line
+	Output io.Writer
+	// SampleRate is the fraction of 2xx responses logged, everything else is always logged
+	SampleRate float64
+}
+
+// AccessLogConfigFromEnv reads ACCESS_LOG (default on in production), ACCESS_LOG_OUTPUT
+// (stdout, stderr or a file path appended to) and ACCESS_LOG_SAMPLE_RATE (default 1)
+func AccessLogConfigFromEnv(production bool) (AccessLogConfig, error) {
+	cfg := AccessLogConfig{Enabled: production, Output: os.Stdout, SampleRate: 1}
+
+	if value := os.Getenv("ACCESS_LOG"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid ACCESS_LOG %q, expected true or false", value)
+		}
+		cfg.Enabled = enabled
+	}
+	if !cfg.Enabled {
+		return cfg, nil
+	}
+
+	if value := os.Getenv("ACCESS_LOG_SAMPLE_RATE"); value != "" {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return cfg, fmt.Errorf("invalid ACCESS_LOG_SAMPLE_RATE %q, expected a number between 0 and 1", value)
+		}
+		cfg.SampleRate = rate
+	}
+
+	switch output := os.Getenv("ACCESS_LOG_OUTPUT"); output {
+	case "", "stdout":
+	case "stderr":
+		cfg.Output = os.Stderr
+	default:
+		file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to open ACCESS_LOG_OUTPUT: %w", err)
+		}
+		cfg.Output = file
+	}
+
+	return cfg, nil
+}
+
+// accessLogEntry is one line of the access log
+type accessLogEntry struct {
+	Time       string  `json:"time"`
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	Route      string  `json:"route,omitempty"`
+	Upstream   string  `json:"upstream,omitempty"`
+	Status     int     `json:"status"`
+	DurationMs float64 `json:"duration_ms"`
+	Bytes      int     `json:"bytes"`
+	UserID     string  `json:"user_id,omitempty"`
+	RequestID  string  `json:"request_id"`
+	ClientIP   string  `json:"client_ip"`
+	UserAgent  string  `json:"user_agent,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// AccessLogger assigns every request an ID (kept from X-Request-ID when the client sent a
+// sane one), forwards it to the services and returns it to the client, and writes one JSON
+// line per request. 2xx responses are sampled at cfg.SampleRate, errors are always logged.
+func AccessLogger(cfg AccessLogConfig) gin.HandlerFunc {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(cfg.Output)
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Request.Header.Set(RequestIDHeader, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Set("request_id", requestID)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if !cfg.Enabled || (status < 300 && cfg.SampleRate < 1 && rand.Float64() >= cfg.SampleRate) {
+			return
+		}
+
+		entry := accessLogEntry{
+			Time:       start.UTC().Format(time.RFC3339Nano),
+			Method:     c.Request.Method,
+			Path:       redact.URL(c.Request.URL),
+			Route:      c.FullPath(),
+			Upstream:   c.GetString(upstreamKey),
+			Status:     status,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+			Bytes:      max(c.Writer.Size(), 0),
+			UserID:     c.GetString("user_id"),
+			RequestID:  requestID,
+			ClientIP:   c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+			Error:      c.Errors.ByType(gin.ErrorTypePrivate).String(),
+		}
+
+		mu.Lock()
+		err := encoder.Encode(entry)
+		mu.Unlock()
+		if err != nil {
+			log.Printf("⚠️ Failed to write access log: %v", err)
+		}
+	}
+}
+
+// validRequestID accepts client request IDs that are short and printable, anything else
+// could forge log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	return strings.IndexFunc(id, func(r rune) bool {
+		return r < 0x21 || r > 0x7e
+	}) < 0
+}
+
+// newRequestID returns a random 128 bit hex ID
+func newRequestID() string {
+	var b [16]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -2,13 +2,17 @@ package middleware
 
 import (
 	"net/http"
+	"os"
 	"strings"
+	"time"
+
+	"api-gateway/i18n"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWTClaims represents the JWT claims structure
+// JWTClaims represents the JWT claims structure issued by User-Service
 type JWTClaims struct {
 	UserID     string `json:"user_id"`
 	Username   string `json:"username"`
@@ -17,26 +21,39 @@ type JWTClaims struct {
 	jwt.RegisteredClaims
 }
 
+// NewTokenParser returns the parser for user tokens: exp is required, and exp, nbf and iat
+// are checked with a leeway of JWT_CLOCK_SKEW (30s by default), as User-Service does
+func NewTokenParser() *jwt.Parser {
+	clockSkew := 30 * time.Second
+	if skew := os.Getenv("JWT_CLOCK_SKEW"); skew != "" {
+		if parsed, err := time.ParseDuration(skew); err == nil && parsed >= 0 {
+			clockSkew = parsed
+		}
+	}
+
+	return jwt.NewParser(
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+}
+
 // AuthMiddleware validates JWT token and sets user context
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+func AuthMiddleware(keyFunc jwt.Keyfunc) gin.HandlerFunc {
+	parser := NewTokenParser()
+
 	return func(c *gin.Context) {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Authorization header required",
-			})
+			c.JSON(http.StatusUnauthorized, i18n.Error(c, "AUTH_HEADER_REQUIRED", gin.H{"success": false}))
 			c.Abort()
 			return
 		}
 
 		// Check if it starts with "Bearer "
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Invalid authorization header format",
-			})
+			c.JSON(http.StatusUnauthorized, i18n.Error(c, "INVALID_AUTH_HEADER", gin.H{"success": false}))
 			c.Abort()
 			return
 		}
@@ -45,29 +62,17 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
 		// Parse and validate token
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(jwtSecret), nil
-		})
+		token, err := parser.ParseWithClaims(tokenString, &JWTClaims{}, keyFunc)
 
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Invalid token",
-			})
+			c.JSON(http.StatusUnauthorized, i18n.Error(c, "INVALID_TOKEN", gin.H{"success": false}))
 			c.Abort()
 			return
 		}
 
 		// Check if token is valid
 		if !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Token is not valid",
-			})
+			c.JSON(http.StatusUnauthorized, i18n.Error(c, "TOKEN_NOT_VALID", gin.H{"success": false}))
 			c.Abort()
 			return
 		}
@@ -75,10 +80,7 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		// Extract claims
 		claims, ok := token.Claims.(*JWTClaims)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Invalid token claims",
-			})
+			c.JSON(http.StatusUnauthorized, i18n.Error(c, "INVALID_TOKEN_CLAIMS", gin.H{"success": false}))
 			c.Abort()
 			return
 		}
@@ -94,7 +96,9 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 }
 
 // OptionalAuthMiddleware validates JWT token if present but doesn't require it
-func OptionalAuthMiddleware(jwtSecret string) gin.HandlerFunc {
+func OptionalAuthMiddleware(keyFunc jwt.Keyfunc) gin.HandlerFunc {
+	parser := NewTokenParser()
+
 	return func(c *gin.Context) {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -113,13 +117,7 @@ func OptionalAuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
 		// Parse and validate token
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(jwtSecret), nil
-		})
+		token, err := parser.ParseWithClaims(tokenString, &JWTClaims{}, keyFunc)
 
 		if err != nil {
 			c.Next()
@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"api-gateway/httpclient"
+	"api-gateway/retry"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwk represents a single public key in a JSON Web Key Set
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKSCache fetches and caches the public keys published by user-service
+type JWKSCache struct {
+	url        string
+	ttl        time.Duration
+	client     *http.Client
+	mu         sync.RWMutex
+	keys       map[string]interface{}
+	fetchedAt  time.Time
+	lastForced time.Time
+}
+
+// minForcedRefreshInterval limits refetches triggered by unknown kids
+const minForcedRefreshInterval = 30 * time.Second
+
+// NewJWKSCache creates a new JWKS cache for the given URL
+func NewJWKSCache(url string, ttl time.Duration) *JWKSCache {
+	return &JWKSCache{
+		url:    url,
+		ttl:    ttl,
+		client: httpclient.New("jwks", retry.Policy{MaxAttempts: 1, Timeout: 5 * time.Second}).Client,
+		keys:   make(map[string]interface{}),
+	}
+}
+
+// GetKey returns the public key for a kid, refreshing the set when it is stale or the kid is unknown
+func (jc *JWKSCache) GetKey(kid string) (interface{}, error) {
+	jc.mu.RLock()
+	key, ok := jc.keys[kid]
+	stale := time.Since(jc.fetchedAt) > jc.ttl
+	jc.mu.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	// Unknown kid usually means the signing key was rotated, refetch (rate limited)
+	if err := jc.refresh(!ok); err != nil {
+		if ok {
+			log.Printf("⚠️ Failed to refresh JWKS, using cached keys: %v", err)
+			return key, nil
+		}
+		return nil, err
+	}
+
+	jc.mu.RLock()
+	defer jc.mu.RUnlock()
+	if key, ok := jc.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key: %q", kid)
+}
+
+// refresh fetches the key set from the JWKS endpoint
+func (jc *JWKSCache) refresh(forced bool) error {
+	jc.mu.Lock()
+	defer jc.mu.Unlock()
+
+	if forced {
+		if time.Since(jc.lastForced) < minForcedRefreshInterval {
+			return nil
+		}
+		jc.lastForced = time.Now()
+	}
+
+	resp, err := jc.client.Get(jc.url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			log.Printf("⚠️ Skipping JWKS key %s: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	jc.keys = keys
+	jc.fetchedAt = time.Now()
+	return nil
+}
+
+// publicKey converts a JWK into an RSA or ECDSA public key
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+// KeyFunc resolves verification keys: RS256/ES256 tokens are checked against the JWKS,
+// HS256 tokens against jwtSecret (dev fallback, disabled when jwtSecret is empty)
+func KeyFunc(jwtSecret string, jwks *JWKSCache) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			if jwtSecret == "" {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return []byte(jwtSecret), nil
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+			if jwks == nil {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			kid, _ := token.Header["kid"].(string)
+			key, err := jwks.GetKey(kid)
+			if err != nil {
+				return nil, err
+			}
+
+			// Make sure the alg header matches the key type
+			switch key.(type) {
+			case *rsa.PublicKey:
+				if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+					return nil, jwt.ErrSignatureInvalid
+				}
+			case *ecdsa.PublicKey:
+				if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
+					return nil, jwt.ErrSignatureInvalid
+				}
+			}
+			return key, nil
+		default:
+			return nil, jwt.ErrSignatureInvalid
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+
+	"api-gateway/redisconn"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// registerRedisHealthRoute exposes GET /health/redis: the Redis topology used by the
+// response cache, feature flags and maintenance windows, and its health as seen from this
+// instance. It answers 503 when Redis is down or misconfigured; degraded sentinels or
+// cluster still answer 200 since reads and writes go through.
+func registerRedisHealthRoute(r *gin.Engine) {
+	topology, configErr := redisconn.FromEnv()
+	var client redis.UniversalClient
+	if configErr == nil {
+		client = topology.NewClient()
+	}
+
+	r.GET("/health/redis", func(c *gin.Context) {
+		state := redisconn.State{Mode: topology.Mode, Status: "down"}
+		if configErr != nil {
+			state.Error = configErr.Error()
+		} else {
+			state = redisconn.CheckState(c.Request.Context(), client, topology)
+		}
+
+		status := http.StatusOK
+		if state.Status == "down" {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{
+			"service": "api-gateway",
+			"redis":   state,
+		})
+	})
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"api-gateway/cache"
+	"api-gateway/httpclient"
+	"api-gateway/i18n"
+	"api-gateway/retry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sandboxResetTimeout bounds a whole sandbox reset, reseeding the catalog takes a while
+const sandboxResetTimeout = 5 * time.Minute
+
+// sandboxStep is one service's part of a sandbox operation
+type sandboxStep struct {
+	service string
+	client  *httpclient.Client
+	url     string
+}
+
+// sandboxStepError is a service failing its part of a sandbox operation
+type sandboxStepError struct {
+	service string
+	status  int // 0 when the service was unreachable
+	err     error
+}
+
+func (e *sandboxStepError) Error() string {
+	if e.status != 0 {
+		return fmt.Sprintf("%s answered %d: %v", e.service, e.status, e.err)
+	}
+	return fmt.Sprintf("%s unavailable: %v", e.service, e.err)
+}
+
+// registerSandboxRoutes exposes the demo environment tools when SANDBOX_TOOLS=true outside
+// production: POST /api/v1/dev/fixtures, and POST /api/v1/admin/sandbox/reset when the
+// admin API is enabled. The services must enable SANDBOX_TOOLS too.
+func registerSandboxRoutes(r *gin.Engine, admin *gin.RouterGroup, responseCache *cache.ResponseCache) {
+	if !sandboxToolsEnabled() {
+		return
+	}
+
+	// Fixtures depend on each other (the product's seller, the payment's buyer and product),
+	// so the services are called in order
+	fixtures := []sandboxStep{
+		{service: "user-service", client: userServiceClient, url: UserServiceURL + "/api/v1/dev/fixtures"},
+		{service: "product-service", client: productServiceClient, url: ProductServiceURL + "/api/v1/dev/fixtures"},
+		{service: "payment-service", client: paymentServiceClient, url: PaymentServiceURL + "/api/v1/dev/fixtures"},
+	}
+	r.POST("/api/v1/dev/fixtures", func(c *gin.Context) {
+		data := make(map[string]json.RawMessage)
+		for _, step := range fixtures {
+			stepData, err := callSandboxStep(c.Request.Context(), step, "")
+			if err != nil {
+				c.JSON(http.StatusBadGateway, i18n.Error(c, "FIXTURES_CREATE_FAILED", gin.H{"success": false, "details": err.Error()}))
+				return
+			}
+
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(stepData, &fields); err != nil {
+				c.JSON(http.StatusBadGateway, i18n.Error(c, "FIXTURES_CREATE_FAILED", gin.H{"success": false, "details": err.Error()}))
+				return
+			}
+			for key, value := range fields {
+				data[key] = value
+			}
+		}
+
+		// The fixture product may have been cached with another stock or price
+		if err := responseCache.Purge(c.Request.Context()); err != nil {
+			log.Printf("⚠️ Failed to purge the gateway cache after creating fixtures: %v", err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"success": true, "data": data})
+	})
+	log.Printf("⚠️ Sandbox tools enabled, POST /api/v1/dev/fixtures creates end to end test data (%s)", appEnv())
+
+	if admin == nil {
+		return
+	}
+
+	// Reset routes are admin token routes, exempt from service authentication
+	resetClient := httpclient.New("sandbox_reset", retry.Policy{MaxAttempts: 1})
+	resets := []sandboxStep{
+		{service: "payment-service", client: resetClient, url: PaymentServiceURL + "/api/v1/admin/sandbox/reset"},
+		{service: "product-service", client: resetClient, url: ProductServiceURL + "/api/v1/admin/sandbox/reset"},
+	}
+	admin.POST("/sandbox/reset", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), sandboxResetTimeout)
+		defer cancel()
+
+		data := gin.H{}
+		for _, step := range resets {
+			stepData, err := callSandboxStep(ctx, step, c.GetHeader("X-Admin-Token"))
+			if err != nil {
+				c.JSON(http.StatusBadGateway, i18n.Error(c, "SANDBOX_RESET_FAILED", gin.H{"success": false, "details": err.Error(), "data": data}))
+				return
+			}
+			data[step.service] = stepData
+		}
+
+		if responseCache == nil {
+			data["gateway_cache"] = "disabled"
+		} else if err := responseCache.Purge(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, i18n.Error(c, "GATEWAY_CACHE_PURGE_FAILED", gin.H{"success": false, "details": err.Error(), "data": data}))
+			return
+		} else {
+			data["gateway_cache"] = "purged"
+		}
+
+		log.Println("🧹 Sandbox data reset")
+		c.JSON(http.StatusOK, gin.H{"success": true, "data": data})
+	})
+}
+
+// callSandboxStep POSTs to a service's sandbox endpoint and returns the data of its
+// {"success": true, "data": ...} answer. adminToken is forwarded when set.
+func callSandboxStep(ctx context.Context, step sandboxStep, adminToken string) (json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, step.url, nil)
+	if err != nil {
+		return nil, &sandboxStepError{service: step.service, err: err}
+	}
+	if adminToken != "" {
+		req.Header.Set("X-Admin-Token", adminToken)
+	}
+
+	resp, err := doUpstream(step.client, req)
+	if err != nil {
+		return nil, &sandboxStepError{service: step.service, err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &sandboxStepError{service: step.service, status: resp.StatusCode, err: err}
+	}
+
+	var answer struct {
+		Data    json.RawMessage `json:"data"`
+		Error   string          `json:"error"`
+		Details string          `json:"details"`
+	}
+	json.Unmarshal(body, &answer)
+	if resp.StatusCode != http.StatusOK {
+		message := answer.Error
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			message = "sandbox tools not enabled"
+		case message == "":
+			message = http.StatusText(resp.StatusCode)
+		case answer.Details != "":
+			message += ": " + answer.Details
+		}
+		return nil, &sandboxStepError{service: step.service, status: resp.StatusCode, err: errors.New(message)}
+	}
+	return answer.Data, nil
+}
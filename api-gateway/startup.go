@@ -0,0 +1,46 @@
+package main
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// getEnv reads an environment variable with a default
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// listenAddr is the address the gateway binds to: BIND_ADDR (all interfaces by default)
+// and PORT (8080 by default)
+func listenAddr() string {
+	return net.JoinHostPort(os.Getenv("BIND_ADDR"), getEnv("PORT", "8080"))
+}
+
+// runHealthcheck requests /health from the running gateway and reports whether it answered
+// 2xx. The image is distroless, without a shell or curl, so the compose healthcheck runs
+// the binary itself with -healthcheck.
+func runHealthcheck() bool {
+	host := os.Getenv("BIND_ADDR")
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get("http://" + net.JoinHostPort(host, getEnv("PORT", "8080")) + "/health")
+	if err != nil {
+		log.Printf("❌ Health check failed: %v", err)
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Printf("❌ Health check returned status %d", resp.StatusCode)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"api-gateway/httpclient"
+	"api-gateway/retry"
+	"api-gateway/serviceauth"
+)
+
+// Per service clients enforcing their retry/timeout policies, set up in main
+var (
+	userServiceClient    *httpclient.Client
+	productServiceClient *httpclient.Client
+	paymentServiceClient *httpclient.Client
+)
+
+// serviceIssuer mints the service tokens sent upstream, nil when SERVICE_AUTH_SECRET is unset
+var serviceIssuer *serviceauth.Issuer
+
+// initUpstreams reads USER_SERVICE_*, PRODUCT_SERVICE_* and PAYMENT_SERVICE_* policies.
+// By default requests are not retried and each attempt may take 30s. With
+// SERVICE_AUTH_SECRET every request carries a service token for the called service.
+func initUpstreams() {
+	var err error
+	serviceIssuer, err = serviceauth.IssuerFromEnv(serviceauth.Gateway)
+	if err != nil {
+		log.Fatalf("❌ Invalid service authentication configuration: %v", err)
+	}
+	if serviceIssuer != nil {
+		log.Println("🔐 Service authentication enabled, upstream requests are signed")
+	} else if appEnv() == "production" {
+		log.Fatalf("❌ SERVICE_AUTH_SECRET is required in production")
+	} else {
+		log.Println("⚠️ SERVICE_AUTH_SECRET not set, upstream requests are unsigned")
+	}
+
+	defaults := retry.Policy{
+		MaxAttempts: 1,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    1 * time.Second,
+		Multiplier:  2,
+		Timeout:     30 * time.Second,
+	}
+
+	// Redirects (e.g. verify-email) are passed through to the client
+	userServiceClient = httpclient.New("user_service", retry.FromEnv("USER_SERVICE", defaults),
+		httpclient.WithoutRedirects(), httpclient.WithServiceAuth(serviceIssuer, serviceauth.UserService))
+	productServiceClient = httpclient.New("product_service", retry.FromEnv("PRODUCT_SERVICE", defaults),
+		httpclient.WithServiceAuth(serviceIssuer, serviceauth.ProductService))
+	paymentServiceClient = httpclient.New("payment_service", retry.FromEnv("PAYMENT_SERVICE", defaults),
+		httpclient.WithServiceAuth(serviceIssuer, serviceauth.PaymentService))
+
+	for _, client := range []*httpclient.Client{userServiceClient, productServiceClient, paymentServiceClient} {
+		log.Printf("🔧 Upstream policy %s", client.Policy())
+	}
+}
+
+// doUpstream sends req following the client's policy. Only idempotent requests are retried,
+// on network errors and 502/503/504 responses; anything else is returned as is.
+func doUpstream(client *httpclient.Client, req *http.Request) (*http.Response, error) {
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+	return client.Retry(req, func(resp *http.Response, err error) bool {
+		return idempotent && retryable(resp, err)
+	})
+}
+
+// retryable reports whether an attempt failed in a way worth retrying
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+# Checks `go test -bench -benchmem` output (second file) against bench-budgets.txt (first
+# file). The best of a benchmark's runs must stay within its allocs/op and B/op budgets, and
+# a budgeted benchmark that didn't run fails too, e.g. after a rename.
+FNR == NR {
+	if ($0 ~ /^#/ || NF == 0) next
+	allocsBudget[$2] = $3 + 0
+	bytesBudget[$2] = $4 + 0
+	next
+}
+
+/^Benchmark/ {
+	name = $1
+	sub(/-[0-9]+$/, "", name) # GOMAXPROCS suffix
+	allocs = -1
+	bytes = -1
+	for (i = 2; i < NF; i++) {
+		if ($(i + 1) == "allocs/op") allocs = $i + 0
+		if ($(i + 1) == "B/op") bytes = $i + 0
+	}
+	if (allocs < 0) next
+	if (!(name in bestAllocs) || allocs < bestAllocs[name]) bestAllocs[name] = allocs
+	if (!(name in bestBytes) || bytes < bestBytes[name]) bestBytes[name] = bytes
+}
+
+END {
+	failed = 0
+	for (name in allocsBudget) {
+		if (!(name in bestAllocs)) {
+			printf "❌ %s did not run\n", name
+			failed = 1
+			continue
+		}
+		status = "✅"
+		if (bestAllocs[name] > allocsBudget[name] || bestBytes[name] > bytesBudget[name]) {
+			status = "❌"
+			failed = 1
+		}
+		printf "%s %s: %d allocs/op (budget %d), %d B/op (budget %d)\n", status, name, bestAllocs[name], allocsBudget[name], bestBytes[name], bytesBudget[name]
+	}
+	exit failed
+}
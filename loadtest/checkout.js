@@ -0,0 +1,72 @@
+// Checkout load test: creates payments through the API gateway against the Midtrans mock.
+// Thresholds come from budgets.json, k6 exits non-zero when one is exceeded.
+import http from 'k6/http';
+import { check, fail } from 'k6';
+
+const BASE_URL = __ENV.BASE_URL || 'http://localhost:5000';
+const EMAIL = __ENV.LOADTEST_EMAIL || 'loadtest@example.com';
+const PASSWORD = __ENV.LOADTEST_PASSWORD || 'loadtest123';
+
+const budgets = JSON.parse(open('./budgets.json'));
+
+export const options = {
+  scenarios: {
+    checkout: {
+      executor: 'constant-arrival-rate',
+      rate: Number(__ENV.CHECKOUT_RPS || 20),
+      timeUnit: '1s',
+      duration: __ENV.DURATION || '1m',
+      preAllocatedVUs: 20,
+      maxVUs: 100,
+    },
+  },
+  thresholds: budgets.checkout,
+};
+
+export function setup() {
+  const login = http.post(`${BASE_URL}/api/v1/auth/login`, JSON.stringify({ email: EMAIL, password: PASSWORD }), {
+    headers: { 'Content-Type': 'application/json' },
+  });
+  if (login.status !== 200) {
+    fail(`login as ${EMAIL} failed with ${login.status}, was loadtest/seed-user.sql applied?`);
+  }
+
+  const list = http.get(`${BASE_URL}/api/v1/products?limit=100`);
+  if (list.status !== 200) {
+    fail(`listing products failed with ${list.status}, was the product seed run?`);
+  }
+  const products = (list.json('data.products') || []).filter(
+    (p) => p.is_active && p.stock > 0 && Number.isInteger(p.price),
+  );
+  if (products.length === 0) {
+    fail('no active product with stock and a whole rupiah price found');
+  }
+
+  return { token: login.json('access_token'), products: products.map((p) => ({ id: p.id, price: p.price })) };
+}
+
+export default function (data) {
+  const product = data.products[Math.floor(Math.random() * data.products.length)];
+  const payload = JSON.stringify({
+    product_id: product.id,
+    amount: product.price,
+    admin_fee: 2500,
+    payment_method: 'bank_transfer',
+    bank_type: 'bca',
+    notes: 'k6 load test',
+  });
+
+  const res = http.post(`${BASE_URL}/api/v1/payments`, payload, {
+    headers: { 'Content-Type': 'application/json', Authorization: `Bearer ${data.token}` },
+    tags: { name: 'create_payment' },
+  });
+
+  check(
+    res,
+    {
+      'payment created': (r) => r.status === 200 && r.json('success') === true,
+      'has VA number': (r) => r.status === 200 && !!r.json('data.va_number'),
+    },
+    { name: 'create_payment' },
+  );
+}
@@ -0,0 +1,40 @@
+// Product listing load test: pages through GET /api/v1/products via the API gateway,
+// exercising the product-service worker pool and repository. Thresholds come from budgets.json.
+import http from 'k6/http';
+import { check } from 'k6';
+
+const BASE_URL = __ENV.BASE_URL || 'http://localhost:5000';
+
+const budgets = JSON.parse(open('./budgets.json'));
+
+export const options = {
+  scenarios: {
+    products: {
+      executor: 'constant-arrival-rate',
+      rate: Number(__ENV.PRODUCTS_RPS || 100),
+      timeUnit: '1s',
+      duration: __ENV.DURATION || '1m',
+      preAllocatedVUs: 50,
+      maxVUs: 200,
+    },
+  },
+  thresholds: budgets.products,
+};
+
+const searches = ['', 'shoes', 'shirt', 'nike', 'adidas'];
+
+export default function () {
+  const page = 1 + Math.floor(Math.random() * 10);
+  const search = searches[Math.floor(Math.random() * searches.length)];
+  const query = search ? `page=${page}&limit=20&search=${search}` : `page=${page}&limit=20`;
+
+  const res = http.get(`${BASE_URL}/api/v1/products?${query}`, { tags: { name: 'get_products' } });
+
+  check(
+    res,
+    {
+      'products listed': (r) => r.status === 200 && Array.isArray(r.json('data.products')),
+    },
+    { name: 'get_products' },
+  );
+}
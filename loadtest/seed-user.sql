@@ -0,0 +1,16 @@
+-- Verified account used by the k6 checkout scenario (password: loadtest123).
+-- Run against userdb after user-service has migrated its tables.
+INSERT INTO users (id, username, email, password_hash, type, is_verified, locale, login_alerts, created_at, updated_at)
+VALUES (
+    gen_random_uuid(),
+    'loadtest',
+    'loadtest@example.com',
+    '$2a$10$aVJcHtGlEIQ7FZ4ixhiCaO.xJPVk.wWqzpUDfs9OwNwZUPzs5fx1G',
+    'credential',
+    true,
+    'id',
+    false,
+    NOW(),
+    NOW()
+)
+ON CONFLICT (email) DO NOTHING;
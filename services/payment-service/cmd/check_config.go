@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"payment-service/internal/cache"
+	"payment-service/internal/events"
+	"payment-service/internal/redact"
+	"payment-service/internal/secrets"
+	"payment-service/internal/serviceauth"
+	"payment-service/internal/services"
+	"payment-service/internal/storage"
+	"payment-service/internal/timeutil"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// configKind is the type an environment variable must parse as
+type configKind int
+
+const (
+	kindString configKind = iota
+	kindInt
+	kindBool
+	kindDuration
+	kindURL
+	kindEnum
+)
+
+// configVar describes one environment variable of the service
+type configVar struct {
+	name     string
+	kind     configKind
+	required bool
+	secret   bool     // Redacted in the report
+	values   []string // Allowed values of a kindEnum, case-insensitive
+}
+
+// configCheck is a read only check of a dependency or of configuration loaded at startup
+type configCheck struct {
+	name     string
+	optional bool // The service runs without it, a failure is only a warning
+	run      func(ctx context.Context) error
+}
+
+// configSchema lists the environment variables of Payment-Service, see env.example
+var configSchema = []configVar{
+	{name: "DB_HOST"},
+	{name: "DB_PORT", kind: kindInt},
+	{name: "DB_USER"},
+	{name: "DB_PASSWORD", secret: true},
+	{name: "DB_NAME"},
+	{name: "DB_MAX_OPEN_CONNS", kind: kindInt},
+	{name: "DB_MAX_IDLE_CONNS", kind: kindInt},
+	{name: "DB_CONN_MAX_LIFETIME", kind: kindDuration},
+	{name: "DB_CONN_MAX_IDLE_TIME", kind: kindDuration},
+	{name: "DB_SLOW_ACQUIRE_THRESHOLD", kind: kindDuration},
+	{name: "DB_REPLICA_HOST"},
+	{name: "DB_REPLICA_PORT", kind: kindInt},
+	{name: "DB_REPLICA_USER"},
+	{name: "DB_REPLICA_PASSWORD", secret: true},
+	{name: "DB_REPLICA_NAME"},
+	{name: "REDIS_ADDR"},
+	{name: "REDIS_PASSWORD", secret: true},
+	{name: "REDIS_DB", kind: kindInt},
+	{name: "REDIS_MODE", kind: kindEnum, values: []string{cache.RedisStandalone, cache.RedisSentinel, cache.RedisCluster}},
+	{name: "REDIS_ADDRS"},
+	{name: "REDIS_MASTER_NAME"},
+	{name: "REDIS_SENTINEL_PASSWORD", secret: true},
+	{name: "EVENT_BUS", kind: kindEnum, values: []string{events.TransportRabbitMQ, events.TransportKafka}},
+	{name: "CONSUMER_MODE", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_MODE_VALIDATION", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_MODE_USER_PROFILE", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_MODE_PRODUCT_CACHE", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_MODE_WEBHOOK", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_CONCURRENCY", kind: kindInt},
+	{name: "CONSUMER_PREFETCH", kind: kindInt},
+	{name: "RABBITMQ_HOST"},
+	{name: "RABBITMQ_PORT", kind: kindInt},
+	{name: "RABBITMQ_USERNAME"},
+	{name: "RABBITMQ_PASSWORD", secret: true},
+	{name: "RABBITMQ_DECLARE_TOPOLOGY", kind: kindBool},
+	{name: "RABBITMQ_MANAGEMENT_URL", kind: kindURL},
+	{name: "KAFKA_BROKERS"},
+	{name: "KAFKA_TOPIC_PREFIX"},
+	{name: "KAFKA_TOPIC_PARTITIONS", kind: kindInt},
+	{name: "KAFKA_REPLICATION_FACTOR", kind: kindInt},
+	{name: "KAFKA_MAX_ATTEMPTS", kind: kindInt},
+	{name: "MIDTRANS_ENVIRONMENT", kind: kindEnum, values: []string{"sandbox", "production"}},
+	{name: "MIDTRANS_SERVER_KEY", secret: true},
+	{name: "MIDTRANS_CLIENT_KEY"},
+	{name: "MIDTRANS_SERVER_KEY_PROD", secret: true},
+	{name: "MIDTRANS_CLIENT_KEY_PROD"},
+	{name: "MIDTRANS_BASE_URL", kind: kindURL},
+	{name: "MIDTRANS_CHARGE_MAX_ATTEMPTS", kind: kindInt},
+	{name: "MIDTRANS_CHARGE_BASE_DELAY", kind: kindDuration},
+	{name: "MIDTRANS_CHARGE_TIMEOUT", kind: kindDuration},
+	{name: "MIDTRANS_STATUS_MAX_ATTEMPTS", kind: kindInt},
+	{name: "MIDTRANS_STATUS_TIMEOUT", kind: kindDuration},
+	{name: "INTERNAL_SERVICE_MAX_ATTEMPTS", kind: kindInt},
+	{name: "INTERNAL_SERVICE_TIMEOUT", kind: kindDuration},
+	{name: "MIDTRANS_RATE_LIMIT", kind: kindInt},
+	{name: "MIDTRANS_RATE_BURST", kind: kindInt},
+	{name: "MIDTRANS_MAX_CONCURRENCY", kind: kindInt},
+	{name: "MIDTRANS_QUEUE_TIMEOUT", kind: kindDuration},
+	{name: "MIDTRANS_BREAKER_THRESHOLD", kind: kindInt},
+	{name: "MIDTRANS_BREAKER_COOLDOWN", kind: kindDuration},
+	{name: "MIDTRANS_CALLBACK_ALLOWED_IPS"},
+	{name: "MIDTRANS_CALLBACK_MAX_AGE", kind: kindDuration},
+	{name: "PAYMENT_LINK_BASE_URL", kind: kindURL},
+	{name: "PAYMENT_LINK_TTL", kind: kindDuration},
+	{name: "DATA_ENCRYPTION_KEY", secret: true},
+	{name: "DATA_ENCRYPTION_KEY_FILE"},
+	{name: "FEATURE_FLAG_REFRESH", kind: kindDuration},
+	{name: "WEBHOOK_TIMEOUT", kind: kindDuration},
+	{name: "WEBHOOK_MAX_ATTEMPTS", kind: kindInt},
+	{name: "WEBHOOK_RETRY_DELAY", kind: kindDuration},
+	{name: "WEBHOOK_POLL_INTERVAL", kind: kindDuration},
+	{name: "HTTP_CLIENT_DIAL_TIMEOUT", kind: kindDuration},
+	{name: "HTTP_CLIENT_TLS_HANDSHAKE_TIMEOUT", kind: kindDuration},
+	{name: "HTTP_CLIENT_IDLE_CONN_TIMEOUT", kind: kindDuration},
+	{name: "HTTP_CLIENT_MAX_IDLE_CONNS", kind: kindInt},
+	{name: "HTTP_CLIENT_MAX_IDLE_CONNS_PER_HOST", kind: kindInt},
+	{name: "HTTP_CLIENT_METRICS", kind: kindBool},
+	{name: "HTTP_CLIENT_TRACE", kind: kindBool},
+	{name: "PAYMENT_EXPIRY_INTERVAL", kind: kindDuration},
+	{name: "INVOICE_SCHEDULER_INTERVAL", kind: kindDuration},
+	{name: "PAYMENT_ARCHIVE_AFTER_MONTHS", kind: kindInt},
+	{name: "PAYMENT_ARCHIVE_RETENTION_MONTHS", kind: kindInt},
+	{name: "PAYMENT_ARCHIVE_INTERVAL", kind: kindDuration},
+	{name: "PAYMENT_ARCHIVE_BATCH_SIZE", kind: kindInt},
+	{name: "INVOICE_REMINDER_BEFORE", kind: kindDuration},
+	{name: "INVOICE_MAX_DUE_DAYS", kind: kindInt},
+	{name: "STORAGE_BACKEND", kind: kindEnum, values: []string{storage.BackendLocal, storage.BackendS3, storage.BackendGCS}},
+	{name: "STORAGE_LOCAL_DIR"},
+	{name: "STORAGE_LOCAL_BASE_URL", kind: kindURL},
+	{name: "STORAGE_SIGNING_KEY", secret: true},
+	{name: "STORAGE_SIGNED_URL_TTL", kind: kindDuration},
+	{name: "STORAGE_BUCKET"},
+	{name: "STORAGE_REGION"},
+	{name: "STORAGE_ENDPOINT", kind: kindURL},
+	{name: "STORAGE_ACCESS_KEY_ID"},
+	{name: "STORAGE_SECRET_ACCESS_KEY", secret: true},
+	{name: "STORAGE_PATH_STYLE", kind: kindBool},
+	{name: "STORAGE_TIMEOUT", kind: kindDuration},
+	{name: "PAYMENT_SERVICE_URL", kind: kindURL},
+	{name: "USER_SERVICE_URL", kind: kindURL},
+	{name: "PRODUCT_SERVICE_URL", kind: kindURL},
+	{name: "PRODUCT_CACHE_TTL", kind: kindDuration},
+	{name: "PRODUCT_CACHE_CHARGE_MAX_AGE", kind: kindDuration},
+	{name: "CACHE_TTL_PAYMENT", kind: kindDuration},
+	{name: "CACHE_TTL_USER_PAYMENTS", kind: kindDuration},
+	{name: "CACHE_TTL_JITTER"},
+	{name: "CHARGE_LOCK_TTL", kind: kindDuration},
+	{name: "CHARGE_LOCK_WAIT", kind: kindDuration},
+	{name: "JWT_SECRET", secret: true},
+	{name: "JWT_ALLOW_HS256", kind: kindBool},
+	{name: "JWKS_URL", kind: kindURL},
+	{name: "JWKS_CACHE_TTL", kind: kindDuration},
+	{name: "JWT_CLOCK_SKEW", kind: kindDuration},
+	{name: "SERVICE_AUTH_SECRET", secret: true},
+	{name: "SERVICE_AUTH_TOKEN_TTL", kind: kindDuration},
+	{name: "PORT", kind: kindInt},
+	{name: "BIND_ADDR"},
+	{name: "STARTUP_WAIT_TIMEOUT", kind: kindDuration},
+	{name: "STARTUP_RETRY_INTERVAL", kind: kindDuration},
+	{name: "DEFAULT_LOCALE", kind: kindEnum, values: []string{"id", "en"}},
+	{name: "APP_ENV", kind: kindEnum, values: []string{"development", "staging", "production"}},
+	{name: "GIN_MODE", kind: kindEnum, values: []string{"debug", "release", "test"}},
+	{name: "LOG_REDACT_FIELDS"},
+	{name: "LOG_REQUEST_BODIES", kind: kindBool},
+	{name: "DISPLAY_TIMEZONE"},
+	{name: "TRUSTED_PROXIES"},
+	{name: "ENABLE_PPROF", kind: kindBool},
+	{name: "ADMIN_TOKEN", secret: true},
+	{name: "SANDBOX_TOOLS", kind: kindBool},
+	{name: "CHAOS_ENABLED", kind: kindBool},
+	{name: "PAYMENT_STATS_WINDOWS"},
+	{name: "METRICS_TOKEN", secret: true},
+	{name: "CHARGE_ADMIN_FEE", kind: kindInt},
+	{name: "CHARGE_DISCOUNT_PERCENT"},
+	{name: "CHARGE_TAX_NAME"},
+	{name: "CHARGE_TAX_PERCENT"},
+	{name: "CHARGE_TAX_ON_ADMIN_FEE", kind: kindBool},
+	{name: "CHARGE_ROUND_TO", kind: kindInt},
+	{name: "API_V1_DEPRECATED_AT"},
+	{name: "API_V1_SUNSET"},
+}
+
+// configChecks are the dependencies of Payment-Service. They only connect and authenticate:
+// nothing is migrated, declared or charged.
+func configChecks() []configCheck {
+	checks := []configCheck{
+		{name: "postgres", run: func(ctx context.Context) error {
+			dbHost, dbPort, dbUser, dbPass, dbName := databaseSettings()
+			return pingPostgres(ctx, postgresDSN(dbHost, dbUser, dbPass, dbName, dbPort))
+		}},
+	}
+
+	// Reads fall back to the primary while the replica is down, so it is optional
+	if os.Getenv("DB_REPLICA_HOST") != "" {
+		checks = append(checks, configCheck{name: "postgres replica", optional: true, run: func(ctx context.Context) error {
+			_, dbPort, dbUser, dbPass, dbName := databaseSettings()
+			return pingPostgres(ctx, replicaDSN(dbUser, dbPass, dbName, dbPort))
+		}})
+	}
+
+	// Artifact storage is optional, but once configured it must be usable
+	if os.Getenv("STORAGE_BACKEND") != "" {
+		checks = append(checks, configCheck{name: "artifact storage", run: func(ctx context.Context) error {
+			_, err := storage.FromEnv("payment-service")
+			return err
+		}})
+	}
+
+	return append(checks,
+		configCheck{name: "redis", run: func(ctx context.Context) error {
+			cacheSvc, err := cache.NewCacheService()
+			if err != nil {
+				return err
+			}
+			return cacheSvc.Close()
+		}},
+		configCheck{name: "event bus", run: func(ctx context.Context) error {
+			return events.Ping()
+		}},
+		configCheck{name: "midtrans", run: func(ctx context.Context) error {
+			return services.NewMidtransService().Ping(ctx)
+		}},
+		configCheck{name: "charge lines", run: func(ctx context.Context) error {
+			_, err := services.NewChargeBuilderFromEnv()
+			return err
+		}},
+		configCheck{name: "display timezone", run: func(ctx context.Context) error {
+			return timeutil.Configure()
+		}},
+		configCheck{name: "service auth", run: func(ctx context.Context) error {
+			_, err := serviceauth.VerifierFromEnv(serviceauth.PaymentService)
+			return err
+		}},
+		configCheck{name: "production safety", run: func(ctx context.Context) error {
+			dataBox, err := secrets.FromEnv()
+			if err != nil {
+				return err
+			}
+			return validateConfig(services.NewMidtransService(), dataBox)
+		}},
+	)
+}
+
+// runCheckConfig validates the environment against configSchema, runs configChecks and
+// prints a report with secrets redacted. It reports whether everything required passed, so
+// it can gate a deploy or run as an init container.
+func runCheckConfig(service string) bool {
+	ok := true
+
+	fmt.Printf("🔎 %s configuration\n", service)
+	for _, v := range configSchema {
+		value, set := os.LookupEnv(v.name)
+		if err := v.validate(value); err != nil {
+			fmt.Printf("  ❌ %s=%s: %v\n", v.name, v.display(value), err)
+			ok = false
+			continue
+		}
+		if !set || value == "" {
+			fmt.Printf("  ·  %s (default)\n", v.name)
+			continue
+		}
+		fmt.Printf("  ✅ %s=%s\n", v.name, v.display(value))
+	}
+
+	fmt.Println("🔌 Dependencies")
+	for _, check := range configChecks() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		start := time.Now()
+		err := check.run(ctx)
+		cancel()
+
+		switch {
+		case err == nil:
+			fmt.Printf("  ✅ %s (%s)\n", check.name, time.Since(start).Round(time.Millisecond))
+		case check.optional:
+			fmt.Printf("  ⚠️ %s (optional): %v\n", check.name, err)
+		default:
+			fmt.Printf("  ❌ %s: %v\n", check.name, err)
+			ok = false
+		}
+	}
+
+	if !ok {
+		fmt.Println("❌ Configuration check failed")
+		return false
+	}
+	fmt.Println("✅ Configuration check passed")
+	return true
+}
+
+// validate checks value against the variable's kind, an empty value means the default
+func (v configVar) validate(value string) error {
+	if value == "" {
+		if v.required {
+			return fmt.Errorf("required")
+		}
+		return nil
+	}
+
+	switch v.kind {
+	case kindInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("not an integer")
+		}
+	case kindBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("not a boolean")
+		}
+	case kindDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("not a duration (e.g. 30s, 15m, 24h)")
+		}
+	case kindURL:
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("not an absolute URL")
+		}
+	case kindEnum:
+		for _, allowed := range v.values {
+			if strings.EqualFold(value, allowed) {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %s", strings.Join(v.values, ", "))
+	}
+	return nil
+}
+
+// display returns value as printed in the report: secrets are redacted and URLs lose their
+// password
+func (v configVar) display(value string) string {
+	if value == "" {
+		return value
+	}
+	if v.secret {
+		return redact.Redacted
+	}
+	if v.kind == kindURL {
+		if parsed, err := url.Parse(value); err == nil {
+			return parsed.Redacted()
+		}
+	}
+	return value
+}
+
+// pingPostgres opens a short lived connection to dsn and pings it
+func pingPostgres(ctx context.Context, dsn string) error {
+	db, err := gorm.Open(postgres.Open(dsn+" connect_timeout=5"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	return sqlDB.PingContext(ctx)
+}
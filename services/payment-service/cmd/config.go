@@ -0,0 +1,25 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"payment-service/internal/secrets"
+	"payment-service/internal/services"
+)
+
+// validateConfig refuses production configuration that is only meant for development:
+// the shared Midtrans sandbox keys, sensitive payment data stored unencrypted, or accepting
+// calls that don't come through the gateway
+func validateConfig(midtransSvc *services.MidtransService, dataBox *secrets.Box) error {
+	production := appEnv() == "production"
+
+	problems := []error{midtransSvc.Validate(production)}
+	if production && dataBox == nil {
+		problems = append(problems, errors.New("DATA_ENCRYPTION_KEY or DATA_ENCRYPTION_KEY_FILE is required in production"))
+	}
+	if production && os.Getenv("SERVICE_AUTH_SECRET") == "" {
+		problems = append(problems, errors.New("SERVICE_AUTH_SECRET is required in production"))
+	}
+	return errors.Join(problems...)
+}
@@ -1,70 +1,123 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"payment-service/internal/apiversion"
 	"payment-service/internal/cache"
+	"payment-service/internal/chaos"
 	"payment-service/internal/consumers"
+	"payment-service/internal/dbpool"
 	"payment-service/internal/events"
+	"payment-service/internal/flags"
 	"payment-service/internal/handlers"
+	"payment-service/internal/middleware"
 	"payment-service/internal/models"
 	"payment-service/internal/repository"
+	"payment-service/internal/secrets"
+	"payment-service/internal/serviceauth"
 	"payment-service/internal/services"
+	"payment-service/internal/storage"
+	"payment-service/internal/timeutil"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var (
-	DB *gorm.DB
+	DB        *gorm.DB
+	ReplicaDB *gorm.DB // optional read replica for listings and stats, nil when not configured
 )
 
-func initDB() {
-	// Load .env for main application configuration
-	if err := godotenv.Load(); err != nil {
-		log.Println("⚠️ .env file not found in main, using system env")
-	}
-
+// databaseSettings returns the primary's DB_* settings with their defaults
+func databaseSettings() (dbHost, dbPort, dbUser, dbPass, dbName string) {
 	// Get database configuration from environment
-	dbHost := os.Getenv("DB_HOST")
+	dbHost = os.Getenv("DB_HOST")
 	if dbHost == "" {
 		dbHost = "localhost"
 	}
 
-	dbPort := os.Getenv("DB_PORT")
+	dbPort = os.Getenv("DB_PORT")
 	if dbPort == "" {
 		dbPort = "5432"
 	}
 
-	dbUser := os.Getenv("DB_USER")
+	dbUser = os.Getenv("DB_USER")
 	if dbUser == "" {
 		dbUser = "postgres"
 	}
 
-	dbPass := os.Getenv("DB_PASSWORD")
+	dbPass = os.Getenv("DB_PASSWORD")
 	if dbPass == "" {
 		dbPass = "password"
 	}
 
-	dbName := os.Getenv("DB_NAME")
+	dbName = os.Getenv("DB_NAME")
 	if dbName == "" {
 		dbName = "microservice_db"
 	}
+	return dbHost, dbPort, dbUser, dbPass, dbName
+}
 
-	// Connection string
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		dbHost, dbUser, dbPass, dbName, dbPort,
+// nonNegativeEnvInt reads an integer environment variable, exiting on invalid values
+func nonNegativeEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		log.Fatalf("❌ Invalid %s=%q", key, value)
+	}
+	return parsed
+}
+
+// postgresDSN builds a PostgreSQL connection string
+func postgresDSN(host, user, pass, name, port string) string {
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=UTC",
+		host, user, pass, name, port,
 	)
+}
+
+// dbPoolDefaults size the primary and replica pools unless DB_MAX_OPEN_CONNS and the other
+// pool settings are set
+var dbPoolDefaults = dbpool.Config{
+	MaxOpen:     100,
+	MaxIdle:     10,
+	MaxLifetime: time.Hour,
+	SlowAcquire: 100 * time.Millisecond,
+}
+
+func initDB() {
+	// Load .env for main application configuration
+	if err := godotenv.Load(); err != nil {
+		log.Println("⚠️ .env file not found in main, using system env")
+	}
 
-	// Connect to database
-	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	dbHost, dbPort, dbUser, dbPass, dbName := databaseSettings()
+
+	// Connection string
+	dsn := postgresDSN(dbHost, dbUser, dbPass, dbName, dbPort)
+
+	// Connect to database, waiting for it to accept connections
+	err := waitFor("Database", func() error {
+		var err error
+		DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
+			NowFunc: timeutil.NowUTC, // Store timestamps in UTC
+		})
+		return err
+	})
 	if err != nil {
 		log.Fatalf("❌ Failed to connect to database: %v", err)
 	}
@@ -75,42 +128,200 @@ func initDB() {
 		log.Fatalf("❌ Failed to get underlying sql.DB: %v", err)
 	}
 
-	// Configure connection pool
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	// Configure connection pool (DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS, DB_CONN_MAX_*)
+	poolConfig := dbpool.ConfigFromEnv(dbPoolDefaults)
+	dbpool.Register("primary", sqlDB, poolConfig)
+	log.Printf("✅ Database pool configured: %s", poolConfig)
 
 	log.Println("✅ Connected to database successfully")
 
-	// Auto migrate the schema (only Payment table, no foreign key constraints)
-	if err := DB.AutoMigrate(&models.Payment{}); err != nil {
+	// Auto migrate owned tables and read models only (no foreign key constraints)
+	if err := DB.AutoMigrate(append(models.OwnedModels(), models.ReadModels()...)...); err != nil {
 		log.Fatalf("❌ Failed to migrate database: %v", err)
 	}
 
 	log.Println("✅ Database migration completed")
+
+	initReplica(dbUser, dbPass, dbName, dbPort)
+}
+
+// initReplica opens the read replica at DB_REPLICA_HOST, the other DB_REPLICA_* settings
+// default to the primary's. It is not pinged at startup: while the replica is down reads fall
+// back to the primary, and use it again once it is back.
+func initReplica(dbUser, dbPass, dbName, dbPort string) {
+	dsn := replicaDSN(dbUser, dbPass, dbName, dbPort)
+	if dsn == "" {
+		log.Println("ℹ️ DB_REPLICA_HOST not set, all reads go to the primary database")
+		return
+	}
+
+	replica, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		NowFunc:              timeutil.NowUTC,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		log.Printf("⚠️ Invalid read replica configuration, all reads go to the primary database: %v", err)
+		return
+	}
+
+	if sqlDB, err := replica.DB(); err == nil {
+		dbpool.Register("replica", sqlDB, dbpool.ConfigFromEnv(dbPoolDefaults))
+	}
+
+	ReplicaDB = replica
+	log.Printf("✅ Read replica configured at %s, listings and stats fall back to the primary while it is unreachable", os.Getenv("DB_REPLICA_HOST"))
+}
+
+// replicaDSN builds the read replica's connection string from the DB_REPLICA_* settings
+// over the primary's, or returns "" when DB_REPLICA_HOST is not set
+func replicaDSN(dbUser, dbPass, dbName, dbPort string) string {
+	host := os.Getenv("DB_REPLICA_HOST")
+	if host == "" {
+		return ""
+	}
+
+	if value := os.Getenv("DB_REPLICA_PORT"); value != "" {
+		dbPort = value
+	}
+	if value := os.Getenv("DB_REPLICA_USER"); value != "" {
+		dbUser = value
+	}
+	if value := os.Getenv("DB_REPLICA_PASSWORD"); value != "" {
+		dbPass = value
+	}
+	if value := os.Getenv("DB_REPLICA_NAME"); value != "" {
+		dbName = value
+	}
+
+	return postgresDSN(host, dbUser, dbPass, dbName, dbPort)
 }
 
 func main() {
+	// -healthcheck probes the running service, for the compose healthcheck
+	healthcheck := flag.Bool("healthcheck", false, "check /health of the running service and exit")
+	flag.Parse()
+	if *healthcheck {
+		godotenv.Load()
+		if !runHealthcheck(servicePort()) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// check-config validates the environment and dependencies, for an init container or a
+	// pre-deploy gate
+	if flag.Arg(0) == "check-config" {
+		godotenv.Load()
+		if !runCheckConfig("Payment Service") {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// verify-topology compares the broker with the exchanges, queues and bindings the
+	// services expect
+	if flag.Arg(0) == "verify-topology" {
+		godotenv.Load()
+		if !runVerifyTopology("Payment Service") {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Display timezone for API responses (DISPLAY_TIMEZONE)
+	if err := timeutil.Configure(); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	log.Printf("🕒 Serializing times in %s", timeutil.Display())
+
 	// Initialize database
 	initDB()
 
+	// Warn when queries wait for a pool connection (DB_SLOW_ACQUIRE_THRESHOLD)
+	poolMonitor := dbpool.NewMonitor(10 * time.Second)
+	poolMonitor.Start()
+	defer poolMonitor.Stop()
+
+	// Fault injection for resilience testing (CHAOS_ENABLED, never in production), before
+	// the HTTP clients are created and the consumers subscribe
+	if chaosEnabled() {
+		chaos.Enable(chaos.ScopeUpstream, chaos.ScopeConsumer)
+		log.Printf("⚠️ Fault injection enabled, manage faults at /api/v1/admin/chaos/faults (%s)", appEnv())
+	}
+
 	// Initialize Redis cache
-	cacheSvc, err := cache.NewCacheService()
+	var cacheSvc *cache.CacheService
+	err := waitFor("Redis", func() error {
+		var err error
+		cacheSvc, err = cache.NewCacheService()
+		return err
+	})
 	if err != nil {
 		log.Fatalf("❌ Failed to initialize cache service: %v", err)
 	}
 	defer cacheSvc.Close()
 
 	// Initialize RabbitMQ events
-	eventSvc, err := events.NewEventService()
+	var eventSvc *events.EventService
+	err = waitFor("RabbitMQ", func() error {
+		var err error
+		eventSvc, err = events.NewEventService()
+		return err
+	})
 	if err != nil {
 		log.Fatalf("❌ Failed to initialize event service: %v", err)
 	}
 	defer eventSvc.Close()
 
+	// Keep a log of published events for replay
+	eventLogRepo := repository.NewEventLogRepository(DB)
+	eventSvc.SetEventLog(eventLogRepo)
+
 	// Initialize services
 	midtransSvc := services.NewMidtransService()
+
+	// Discount, tax and rounding lines of every charge (CHARGE_*)
+	chargeBuilder, err := services.NewChargeBuilderFromEnv()
+	if err != nil {
+		log.Fatalf("❌ Invalid charge configuration: %v", err)
+	}
+	midtransSvc.SetChargeBuilder(chargeBuilder)
+
+	// Sensitive data at rest (store server keys, raw Midtrans responses) is encrypted with
+	// DATA_ENCRYPTION_KEY
+	dataBox, err := secrets.FromEnv()
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize data encryption: %v", err)
+	}
+	if dataBox == nil {
+		log.Println("⚠️ DATA_ENCRYPTION_KEY not set, Midtrans responses are stored unencrypted and stores can't configure their own Midtrans credentials")
+	}
+
+	// Refuse to run production on development-only configuration
+	if err := validateConfig(midtransSvc, dataBox); err != nil {
+		log.Fatalf("❌ Refusing to start with unsafe configuration: %v", err)
+	}
+
+	storeCredentials := repository.NewStoreCredentialsRepository(DB)
 	paymentRepo := repository.NewPaymentRepository(DB)
+	paymentRepo.SetEncryption(dataBox)
+	paymentRepo.SetReplica(ReplicaDB)
+	if err := paymentRepo.EnsureOrderGroups(context.Background()); err != nil {
+		log.Fatalf("❌ Failed to set up payment order groups: %v", err)
+	}
+	if err := paymentRepo.EnsureSearchIndexes(context.Background()); err != nil {
+		log.Printf("⚠️ Payment search will not use trigram indexes: %v", err)
+	}
+	if fixed, err := paymentRepo.NormalizeMidtransTimes(context.Background()); err != nil {
+		log.Printf("⚠️ Failed to normalize stored Midtrans times: %v", err)
+	} else if fixed > 0 {
+		log.Printf("🕒 Corrected %d payments with Midtrans times stored without their zone", fixed)
+	}
+	if encrypted, err := paymentRepo.EncryptMidtransResponses(context.Background()); err != nil {
+		log.Printf("⚠️ Failed to encrypt stored Midtrans responses: %v", err)
+	} else if encrypted > 0 {
+		log.Printf("🔒 Encrypted %d stored Midtrans responses", encrypted)
+	}
 
 	// Initialize validation consumer
 	validationConsumer := consumers.NewValidationConsumer(eventSvc, paymentRepo)
@@ -118,6 +329,40 @@ func main() {
 		log.Fatalf("❌ Failed to start validation consumer: %v", err)
 	}
 
+	// Keep the user read model in sync with User-Service
+	userProfileRepo := repository.NewUserProfileRepository(DB)
+	userProfileConsumer := consumers.NewUserProfileConsumer(eventSvc, userProfileRepo)
+	if err := userProfileConsumer.Start(); err != nil {
+		log.Fatalf("❌ Failed to start user profile consumer: %v", err)
+	}
+
+	// Drop cached payments when their product changes in Product-Service
+	productCacheConsumer := consumers.NewProductCacheConsumer(eventSvc, paymentRepo, cacheSvc)
+	if err := productCacheConsumer.Start(); err != nil {
+		log.Fatalf("❌ Failed to start product cache consumer: %v", err)
+	}
+
+	// Initialize merchant webhooks
+	webhookRepo := repository.NewWebhookRepository(DB)
+	webhookSvc := services.NewWebhookService(webhookRepo)
+	webhookSvc.Start()
+	defer webhookSvc.Stop()
+
+	webhookConsumer := consumers.NewWebhookConsumer(eventSvc, webhookSvc)
+	if err := webhookConsumer.Start(); err != nil {
+		log.Fatalf("❌ Failed to start webhook consumer: %v", err)
+	}
+
+	// Feature flags, stored in Redis next to the cache (FEATURE_<NAME> overrides)
+	flagStore := flags.NewStore(cacheSvc.Client(), flags.Flag{
+		Name:        flags.PaymentLinks,
+		Description: "Allow purchasers to create shareable payment links",
+		Enabled:     true,
+		Rollout:     100,
+	})
+	flagStore.Start()
+	defer flagStore.Stop()
+
 	// Get service URLs from environment
 	userServiceURL := os.Getenv("USER_SERVICE_URL")
 	if userServiceURL == "" {
@@ -132,16 +377,131 @@ func main() {
 	// Initialize handlers
 	paymentHandler := handlers.NewPaymentHandler(
 		paymentRepo,
+		userProfileRepo,
+		repository.NewCallbackRepository(DB),
+		storeCredentials,
 		midtransSvc,
+		dataBox,
 		eventSvc,
 		cacheSvc,
 		userServiceURL,
 		productServiceURL,
 		validationConsumer,
 	)
+	paymentHandler.SetChargeBuilder(chargeBuilder)
+
+	// Generated artifacts (settled invoices, export links) in STORAGE_BACKEND, download links
+	// valid for STORAGE_SIGNED_URL_TTL
+	artifactStore, err := storage.FromEnv("payment-service")
+	if err != nil {
+		log.Fatalf("❌ Invalid storage configuration: %v", err)
+	}
+	if artifactStore != nil {
+		signedURLTTL := 15 * time.Minute
+		if value := os.Getenv("STORAGE_SIGNED_URL_TTL"); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed <= 0 {
+				log.Fatalf("❌ Invalid STORAGE_SIGNED_URL_TTL=%q", value)
+			}
+			signedURLTTL = parsed
+		}
+		paymentHandler.SetArtifactStore(artifactStore, signedURLTTL)
+		fmt.Printf("🗄️ Artifact storage enabled (%s)\n", os.Getenv("STORAGE_BACKEND"))
+	}
+
+	// Service to service authentication (SERVICE_AUTH_SECRET): calls to user-service and
+	// product-service are signed, calls to this service must be
+	serviceIssuer, err := serviceauth.IssuerFromEnv(serviceauth.PaymentService)
+	if err != nil {
+		log.Fatalf("❌ Invalid service authentication configuration: %v", err)
+	}
+	serviceVerifier, err := serviceauth.VerifierFromEnv(serviceauth.PaymentService)
+	if err != nil {
+		log.Fatalf("❌ Invalid service authentication configuration: %v", err)
+	}
+	if serviceVerifier != nil {
+		paymentHandler.SetServiceAuth(serviceIssuer)
+		fmt.Println("🔐 Service authentication enabled")
+	} else {
+		fmt.Println("⚠️ SERVICE_AUTH_SECRET not set, accepting calls from anywhere")
+	}
+	// Expire overdue pending payments at Midtrans too (PAYMENT_EXPIRY_INTERVAL, 0 disables)
+	expiryInterval := time.Minute
+	if value := os.Getenv("PAYMENT_EXPIRY_INTERVAL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			log.Fatalf("❌ Invalid PAYMENT_EXPIRY_INTERVAL=%q", value)
+		}
+		expiryInterval = parsed
+	}
+	if expiryInterval > 0 {
+		paymentExpirer := handlers.NewPaymentExpirer(paymentHandler, expiryInterval)
+		paymentExpirer.Start()
+		defer paymentExpirer.Stop()
+	}
+
+	// Invoice reminders and overdue transitions (INVOICE_SCHEDULER_INTERVAL, 0 disables),
+	// reminding INVOICE_REMINDER_BEFORE the due date
+	invoiceInterval := 5 * time.Minute
+	if value := os.Getenv("INVOICE_SCHEDULER_INTERVAL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			log.Fatalf("❌ Invalid INVOICE_SCHEDULER_INTERVAL=%q", value)
+		}
+		invoiceInterval = parsed
+	}
+	invoiceReminderBefore := 72 * time.Hour
+	if value := os.Getenv("INVOICE_REMINDER_BEFORE"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("❌ Invalid INVOICE_REMINDER_BEFORE=%q", value)
+		}
+		invoiceReminderBefore = parsed
+	}
+	if invoiceInterval > 0 {
+		invoiceScheduler := handlers.NewInvoiceScheduler(paymentHandler, invoiceInterval, invoiceReminderBefore)
+		invoiceScheduler.Start()
+		defer invoiceScheduler.Stop()
+	}
+
+	// Archive settled payments older than PAYMENT_ARCHIVE_AFTER_MONTHS (0 disables) and delete
+	// archived ones older than PAYMENT_ARCHIVE_RETENTION_MONTHS (0 keeps them)
+	archiveAfter := nonNegativeEnvInt("PAYMENT_ARCHIVE_AFTER_MONTHS", 0)
+	archiveRetention := nonNegativeEnvInt("PAYMENT_ARCHIVE_RETENTION_MONTHS", 0)
+	if archiveRetention > 0 && archiveRetention <= archiveAfter {
+		log.Fatalf("❌ PAYMENT_ARCHIVE_RETENTION_MONTHS must be 0 or longer than PAYMENT_ARCHIVE_AFTER_MONTHS")
+	}
+	if archiveAfter > 0 {
+		archiveInterval := 24 * time.Hour
+		if value := os.Getenv("PAYMENT_ARCHIVE_INTERVAL"); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed <= 0 {
+				log.Fatalf("❌ Invalid PAYMENT_ARCHIVE_INTERVAL=%q", value)
+			}
+			archiveInterval = parsed
+		}
+		paymentArchiver := handlers.NewPaymentArchiver(paymentRepo, cacheSvc, archiveAfter, archiveRetention, archiveInterval, nonNegativeEnvInt("PAYMENT_ARCHIVE_BATCH_SIZE", 500))
+		paymentArchiver.Start()
+		defer paymentArchiver.Stop()
+	}
+
+	paymentLinkHandler := handlers.NewPaymentLinkHandler(paymentHandler, repository.NewPaymentLinkRepository(DB), flagStore)
+	webhookHandler := handlers.NewWebhookHandler(webhookRepo, webhookSvc)
+	eventHandler := handlers.NewEventHandler(eventLogRepo, eventSvc)
+	flagHandler := handlers.NewFlagHandler(flagStore)
+	storeCredentialsHandler := handlers.NewStoreCredentialsHandler(paymentHandler, storeCredentials, dataBox)
+	sandboxHandler := handlers.NewSandboxHandler(paymentRepo, cacheSvc)
+	cacheHandler := handlers.NewCacheHandler(cacheSvc)
+
+	statsWindows, err := handlers.StatsWindowsFromEnv()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	statsHandler := handlers.NewPaymentStatsHandler(paymentRepo, statsWindows, 30*time.Second)
+	metricsToken := os.Getenv("METRICS_TOKEN")
 
 	// Initialize Gin router
-	r := gin.Default()
+	r := newRouter()
 
 	// CORS middleware
 	r.Use(func(c *gin.Context) {
@@ -157,6 +517,22 @@ func main() {
 		c.Next()
 	})
 
+	// Everything but health checks, Midtrans callbacks, signed downloads and admin token
+	// routes must come through the gateway or another service
+	r.Use(middleware.ServiceAuth(serviceVerifier,
+		"/health",
+		"/api/v1/payments/midtrans/callback",
+		"/api/v2/payments/midtrans/callback",
+		"/api/v1/payments/files/",
+		"/api/v2/payments/files/",
+		"/api/v1/admin/",
+		"/debug/",
+		"/metrics",
+	))
+
+	// ?nocache=true skips and refreshes the payment cache (ADMIN_TOKEN in production)
+	r.Use(cacheBypass())
+
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
 		// Check database connection
@@ -180,7 +556,7 @@ func main() {
 		}
 
 		// Check Redis connection
-		if err := cacheSvc.HealthCheck(); err != nil {
+		if err := cacheSvc.HealthCheck(c.Request.Context()); err != nil {
 			c.JSON(500, gin.H{
 				"status":  "error",
 				"service": "payment-service",
@@ -206,47 +582,236 @@ func main() {
 		})
 	})
 
-	// API routes
-	api := r.Group("/api/v1")
+	// Redis topology health: the sentinel master or cluster state, 503 when Redis is down
+	r.GET("/health/redis", func(c *gin.Context) {
+		state := cacheSvc.RedisState(c.Request.Context())
+		status := 200
+		if state.Status == "down" {
+			status = 503
+		}
+		c.JSON(status, gin.H{
+			"success": state.Status != "down",
+			"service": "payment-service",
+			"data":    state,
+		})
+	})
+
+	// API routes, served under /api/v1 and /api/v2. v2 overrides the routes whose response
+	// shape changed; their v1 versions are deprecated (API_V1_DEPRECATED_AT, API_V1_SUNSET).
+	api := apiversion.New(r, "v1", "v2")
+	api.Deprecate("v1", apiversion.DeprecationFromEnv("v1"))
 	{
 		// Payment routes
 		payments := api.Group("/payments")
 		{
 			// Public routes
 			payments.GET("/config", paymentHandler.GetMidtransConfig)
-			payments.POST("/midtrans/callback", paymentHandler.MidtransCallback)
+			payments.POST("/midtrans/callback",
+				middleware.SourceAllowlist("Midtrans callback", os.Getenv("MIDTRANS_CALLBACK_ALLOWED_IPS")),
+				paymentHandler.MidtransCallback)
+
+			// Signed test callbacks for QA, never against production Midtrans
+			if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" && appEnv() != "production" && midtransSvc.GetEnvironment() == "sandbox" {
+				payments.POST("/midtrans/callback/test", adminAuthMiddleware(adminToken), paymentHandler.SimulateMidtransCallback)
+				log.Printf("⚠️ Midtrans callback simulator enabled at /api/{v1,v2}/payments/midtrans/callback/test (%s, admin token required)", appEnv())
+			}
+
+			// Payment links are paid by whoever holds the token
+			payments.GET("/links/:token", paymentLinkHandler.GetLinkPage)
+			payments.POST("/links/:token/pay", paymentLinkHandler.PayLink)
+
+			// Signed download links of the local storage backend, the signature is the auth
+			if localStore := storage.AsLocal(artifactStore); localStore != nil {
+				payments.GET("/files/*key", func(c *gin.Context) {
+					localStore.ServeSigned(c.Writer, c.Request, strings.TrimPrefix(c.Param("key"), "/"))
+				})
+			}
 
 			// Protected routes (require authentication)
 			protected := payments.Group("")
-			// protected.Use(authMiddleware()) // Add auth middleware here
+			protected.Use(middleware.AuthMiddleware(jwtKeyFunc(userServiceURL)))
 			{
 				protected.POST("", paymentHandler.CreatePayment)
 				protected.GET("/:id/check-status", paymentHandler.CheckPaymentStatus)
-				protected.GET("/:id", paymentHandler.GetPayment)
-				protected.GET("/order/:order_id", paymentHandler.GetPaymentByOrderID)
+				protected.POST("/:id/cancel", paymentHandler.CancelPayment)
+				protected.GET("/:id/invoice", paymentHandler.GetInvoicePDF)
+				protected.GET("/:id", paymentHandler.GetPayment).
+					Version("v2", paymentHandler.GetPaymentV2)
+				protected.GET("/order/:order_id", paymentHandler.GetPaymentByOrderID).
+					Version("v2", paymentHandler.GetPaymentByOrderIDV2)
+				protected.GET("/orders/:order_ref", paymentHandler.GetOrderAttempts)
 				protected.GET("/user", paymentHandler.GetUserPayments)
+				protected.GET("/user/export", paymentHandler.ExportUserPayments)
+				protected.GET("/seller", paymentHandler.GetSellerPayments)
+				protected.GET("/archived", paymentHandler.GetArchivedPayments)
+				protected.GET("/archived/:id", paymentHandler.GetArchivedPayment)
+				protected.POST("/links", paymentLinkHandler.CreateLink)
+				protected.GET("/links", paymentLinkHandler.ListLinks)
+				protected.DELETE("/links/:token", paymentLinkHandler.CancelLink)
+				protected.GET("/stores/:id/midtrans", storeCredentialsHandler.GetCredentials)
+				protected.PUT("/stores/:id/midtrans", storeCredentialsHandler.PutCredentials)
+				protected.DELETE("/stores/:id/midtrans", storeCredentialsHandler.DeleteCredentials)
 			}
 		}
 	}
+	api.Mount()
 
-	// Get port from environment
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8083"
+	// Demo environment tools (SANDBOX_TOOLS, never in production)
+	sandboxEnabled := sandboxToolsEnabled()
+	if sandboxEnabled {
+		r.POST("/api/v1/dev/fixtures", sandboxHandler.CreateFixtures)
+		log.Printf("⚠️ Sandbox tools enabled, POST /api/v1/dev/fixtures creates the fixture payment (%s)", appEnv())
+	}
+
+	// Get port and bind address from environment
+	port := servicePort()
+	addr := listenAddr(port)
+
+	// Prometheus metrics, scraped with METRICS_TOKEN as bearer token
+	if metricsToken != "" {
+		r.GET("/metrics", metricsAuthMiddleware(metricsToken), statsHandler.Metrics)
+	} else {
+		log.Println("⚠️ METRICS_TOKEN not set, /metrics disabled")
+	}
+
+	// Debug and runtime diagnostics endpoints (admin token required)
+	registerDebugRoutes(r)
+	admin := registerAdminRoutes(r, func() gin.H {
+		return gin.H{
+			"rabbitmq": gin.H{
+				"connected": eventSvc.IsConnected(),
+			},
+			"validation_consumer": gin.H{
+				"pending_validations": validationConsumer.PendingCount(),
+			},
+			"midtrans": midtransSvc.LimiterStats(),
+		}
+	})
+	if admin != nil {
+		// Merchant webhook management
+		webhooks := admin.Group("/webhooks")
+		{
+			webhooks.GET("", webhookHandler.ListEndpoints)
+			webhooks.POST("", webhookHandler.CreateEndpoint)
+			webhooks.GET("/:id", webhookHandler.GetEndpoint)
+			webhooks.PUT("/:id", webhookHandler.UpdateEndpoint)
+			webhooks.DELETE("/:id", webhookHandler.DeleteEndpoint)
+			webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+		}
+
+		// Published event log and replay
+		admin.GET("/events", eventHandler.ListEvents)
+		admin.POST("/events/replay", eventHandler.ReplayEvents)
+
+		// Feature flags
+		admin.GET("/flags", flagHandler.ListFlags)
+		admin.PUT("/flags/:name", flagHandler.UpdateFlag)
+
+		// Payment dashboards
+		admin.GET("/payments/stats", statsHandler.GetStats)
+		admin.GET("/payments/stats/methods", statsHandler.GetMethodStats)
+		admin.GET("/payments", paymentHandler.AdminListPayments)
+		admin.GET("/payments/archived", paymentHandler.AdminListArchivedPayments)
+		admin.GET("/payments/filters", paymentHandler.ListSavedPaymentFilters)
+		admin.POST("/payments/:id/settle", paymentHandler.SettleInvoice)
+
+		// Cache inspection and purges
+		admin.GET("/cache", cacheHandler.GetCacheStats)
+		admin.DELETE("/cache/:namespace", cacheHandler.PurgeCache)
+
+		if sandboxEnabled {
+			admin.POST("/sandbox/reset", sandboxHandler.ResetSandbox)
+		}
+
+		// Fault injection (CHAOS_ENABLED)
+		if chaos.Enabled() {
+			chaosHandler := handlers.NewChaosHandler()
+			admin.GET("/chaos/faults", chaosHandler.ListFaults)
+			admin.POST("/chaos/faults", chaosHandler.AddFault)
+			admin.DELETE("/chaos/faults", chaosHandler.ClearFaults)
+			admin.DELETE("/chaos/faults/:id", chaosHandler.RemoveFault)
+		}
+	} else {
+		log.Println("⚠️ ADMIN_TOKEN not set, webhook, event replay, feature flag, payment stats and cache admin API disabled")
 	}
 
-	log.Printf("🚀 Payment Service running on http://localhost:%s", port)
+	log.Printf("🚀 Payment Service running on http://localhost:%s (listening on %s)", port, addr)
 	log.Printf("📚 Available endpoints:")
 	log.Printf("  POST /api/v1/payments              - Create payment")
 	log.Printf("  GET  /api/v1/payments/:id          - Get payment by ID")
 	log.Printf("  GET  /api/v1/payments/:id/check-status - Check payment status from Midtrans")
 	log.Printf("  GET  /api/v1/payments/order/:id    - Get payment by order ID")
 	log.Printf("  GET  /api/v1/payments/user         - Get user payments")
+	log.Printf("  GET  /api/v1/payments/seller?product_id= - Payments for one of the seller's products")
+	log.Printf("  GET  /api/v1/payments/archived     - Get the user's archived payments")
 	log.Printf("  GET  /api/v1/payments/config       - Get Midtrans config")
+	log.Printf("  POST /api/v1/payments/links        - Create payment link")
+	log.Printf("  GET  /api/v1/payments/links/:token - View payment link (public)")
+	log.Printf("  POST /api/v1/payments/links/:token/pay - Pay payment link (public)")
+	log.Printf("  *    /api/v1/payments/stores/:id/midtrans - Store's own Midtrans credentials (store owner)")
 	log.Printf("  POST /api/v1/payments/midtrans/callback - Midtrans webhook")
+	log.Printf("  POST /api/v1/payments/midtrans/callback/test - Simulate a signed Midtrans callback (sandbox, admin)")
+	log.Printf("  *    /api/v1/admin/webhooks          - Manage merchant webhooks (admin)")
+	log.Printf("  POST /api/v1/admin/events/replay    - Replay logged events (admin)")
+	log.Printf("  PUT  /api/v1/admin/flags/:name      - Flip a feature flag (admin)")
+	log.Printf("  GET  /api/v1/admin/payments/stats/methods - Success, failure and expiry rates per method (admin)")
+	log.Printf("  GET  /api/v1/admin/payments          - Search every user's payments, ?filter= applies a saved filter (admin)")
+	log.Printf("  GET  /api/v1/admin/payments/archived - Search every user's archived payments (admin)")
+	log.Printf("  GET  /api/v1/admin/cache            - Cached keys and hit rates per namespace (admin)")
+	log.Printf("  DELETE /api/v1/admin/cache/:namespace?id= - Purge a cache namespace or entry (admin)")
+	log.Printf("  POST /api/v1/admin/sandbox/reset    - Delete every payment and clear the cache (admin, SANDBOX_TOOLS)")
+	log.Printf("  *    /api/v1/admin/chaos/faults     - Inject upstream and consumer faults (admin, CHAOS_ENABLED)")
+	log.Printf("  POST /api/v1/dev/fixtures           - Create the end to end test payment (SANDBOX_TOOLS)")
+	log.Printf("  GET  /metrics                      - Prometheus metrics (METRICS_TOKEN)")
 	log.Printf("  GET  /health                       - Health check")
+	log.Printf("  GET  /health/redis                 - Redis topology health (standalone, sentinel or cluster)")
 
-	if err := r.Run(":" + port); err != nil {
+	if err := r.Run(addr); err != nil {
 		log.Fatalf("❌ Failed to start server: %v", err)
 	}
 }
+
+// exampleJWTSecret is the JWT_SECRET of env.example, only accepted in development
+const exampleJWTSecret = "your-super-secret-jwt-key-change-this-in-production"
+
+// jwtKeyFunc builds the token key resolver: RS256/ES256 tokens are validated against the
+// user-service JWKS, HS256 with JWT_SECRET is kept as a fallback unless JWT_ALLOW_HS256=false
+// or JWT_SECRET is unset
+func jwtKeyFunc(userServiceURL string) jwt.Keyfunc {
+	jwksURL := os.Getenv("JWKS_URL")
+	if jwksURL == "" {
+		jwksURL = userServiceURL + "/.well-known/jwks.json"
+	}
+
+	jwksTTL := 10 * time.Minute
+	if ttl := os.Getenv("JWKS_CACHE_TTL"); ttl != "" {
+		if parsed, err := time.ParseDuration(ttl); err == nil {
+			jwksTTL = parsed
+		}
+	}
+
+	// Without JWT_SECRET there is no HS256 fallback, a default secret would be public and let
+	// anyone sign tokens for any user
+	jwtSecret := ""
+	if os.Getenv("JWT_ALLOW_HS256") != "false" {
+		jwtSecret = os.Getenv("JWT_SECRET")
+		switch {
+		case jwtSecret == "":
+			log.Println("⚠️ JWT_SECRET not set, HS256 tokens are rejected")
+		case jwtSecret == exampleJWTSecret && appEnv() != "development":
+			log.Fatalf("❌ JWT_SECRET is the example secret from env.example, set a secret of your own or JWT_ALLOW_HS256=false")
+		}
+	}
+
+	return middleware.KeyFunc(jwtSecret, middleware.NewJWKSCache(jwksURL, jwksTTL))
+}
+
+// servicePort returns PORT, 8083 by default
+func servicePort() string {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8083"
+	}
+	return port
+}
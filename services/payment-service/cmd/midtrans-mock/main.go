@@ -0,0 +1,172 @@
+// Command midtrans-mock is a minimal stand-in for the Midtrans Core API used by the load
+// tests. It accepts charges, remembers their gross amount and answers status checks, with
+// a configurable latency so checkout can be measured without calling the sandbox.
+//
+// Start it and point the payment service at it:
+//
+//	go run ./cmd/midtrans-mock
+//	MIDTRANS_BASE_URL=http://localhost:5090/v2
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"payment-service/internal/timeutil"
+
+	"github.com/google/uuid"
+)
+
+// chargeRequest holds the fields of a Midtrans charge the mock needs
+type chargeRequest struct {
+	PaymentType        string `json:"payment_type"`
+	TransactionDetails struct {
+		OrderID     string `json:"order_id"`
+		GrossAmount int64  `json:"gross_amount"`
+	} `json:"transaction_details"`
+	BankTransfer *struct {
+		Bank string `json:"bank"`
+	} `json:"bank_transfer,omitempty"`
+}
+
+// transaction is what the mock remembers about a charge
+type transaction struct {
+	ID          string
+	OrderID     string
+	GrossAmount string
+	PaymentType string
+	Time        string
+}
+
+type mock struct {
+	latency      time.Duration
+	transactions sync.Map // order ID -> *transaction
+	charges      atomic.Int64
+	statusChecks atomic.Int64
+}
+
+func main() {
+	port := os.Getenv("MOCK_PORT")
+	if port == "" {
+		port = "5090"
+	}
+
+	latency := 50 * time.Millisecond
+	if value := os.Getenv("MOCK_LATENCY"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			log.Fatalf("❌ Invalid MOCK_LATENCY=%q", value)
+		}
+		latency = parsed
+	}
+
+	m := &mock{latency: latency}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v2/charge", m.charge)
+	mux.HandleFunc("GET /v2/{order_id}/status", m.status)
+	mux.HandleFunc("GET /stats", m.stats)
+
+	log.Printf("🧪 Midtrans mock running on http://localhost:%s (latency: %s)", port, latency)
+	log.Printf("   Set MIDTRANS_BASE_URL=http://localhost:%s/v2 in the payment service", port)
+	if err := http.ListenAndServe(":"+port, mux); err != nil {
+		log.Fatalf("❌ Failed to start Midtrans mock: %v", err)
+	}
+}
+
+// charge accepts any charge and answers like Midtrans does for a pending payment
+func (m *mock) charge(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(m.latency)
+	m.charges.Add(1)
+
+	var req chargeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TransactionDetails.OrderID == "" {
+		writeJSON(w, map[string]interface{}{
+			"status_code":    "400",
+			"status_message": "Invalid charge request",
+		})
+		return
+	}
+
+	tx := &transaction{
+		ID:          uuid.New().String(),
+		OrderID:     req.TransactionDetails.OrderID,
+		GrossAmount: fmt.Sprintf("%d.00", req.TransactionDetails.GrossAmount),
+		PaymentType: req.PaymentType,
+		Time:        time.Now().In(timeutil.MidtransLocation).Format("2006-01-02 15:04:05"),
+	}
+	m.transactions.Store(tx.OrderID, tx)
+
+	resp := tx.response("201", "Success, transaction is created")
+	resp["expiry_time"] = time.Now().In(timeutil.MidtransLocation).Add(24 * time.Hour).Format("2006-01-02 15:04:05")
+
+	switch req.PaymentType {
+	case "bank_transfer":
+		bank := "bca"
+		if req.BankTransfer != nil && req.BankTransfer.Bank != "" {
+			bank = req.BankTransfer.Bank
+		}
+		resp["va_numbers"] = []map[string]string{{"bank": bank, "va_number": fmt.Sprintf("%011d", m.charges.Load())}}
+	case "gopay", "qris", "shopeepay":
+		resp["actions"] = []map[string]string{{
+			"name":   "generate-qr-code",
+			"method": "GET",
+			"url":    "http://" + r.Host + "/v2/qris/" + tx.ID + "/qr-code",
+		}}
+	case "cstore":
+		resp["payment_code"] = fmt.Sprintf("%012d", m.charges.Load())
+	}
+
+	writeJSON(w, resp)
+}
+
+// status reports charges made against the mock as pending
+func (m *mock) status(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(m.latency)
+	m.statusChecks.Add(1)
+
+	value, ok := m.transactions.Load(r.PathValue("order_id"))
+	if !ok {
+		writeJSON(w, map[string]interface{}{
+			"status_code":    "404",
+			"status_message": "Transaction doesn't exist.",
+		})
+		return
+	}
+	writeJSON(w, value.(*transaction).response("200", "Success, transaction is found"))
+}
+
+// stats reports how many calls the mock has served
+func (m *mock) stats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"latency":       m.latency.String(),
+		"charges":       m.charges.Load(),
+		"status_checks": m.statusChecks.Load(),
+	})
+}
+
+func (tx *transaction) response(statusCode, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"status_code":        statusCode,
+		"status_message":     message,
+		"transaction_id":     tx.ID,
+		"order_id":           tx.OrderID,
+		"gross_amount":       tx.GrossAmount,
+		"currency":           "IDR",
+		"payment_type":       tx.PaymentType,
+		"transaction_time":   tx.Time,
+		"transaction_status": "pending",
+		"fraud_status":       "accept",
+	}
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+
+	"payment-service/internal/events"
+)
+
+// runVerifyTopology compares the broker with the shared event topology, reporting whether
+// it matches. Warnings don't fail it.
+func runVerifyTopology(service string) bool {
+	fmt.Printf("🔎 %s event topology\n", service)
+
+	report, err := events.VerifyTopology()
+	if err != nil {
+		fmt.Printf("  ❌ %v\n", err)
+		fmt.Println("❌ Topology check failed")
+		return false
+	}
+
+	for _, warning := range report.Warnings {
+		fmt.Printf("  ⚠️ %s\n", warning)
+	}
+	for _, problem := range report.Problems {
+		fmt.Printf("  ❌ %s\n", problem)
+	}
+
+	if len(report.Problems) > 0 {
+		fmt.Printf("❌ Topology check failed, %d mismatches\n", len(report.Problems))
+		return false
+	}
+	fmt.Println("✅ Topology check passed")
+	return true
+}
@@ -0,0 +1,192 @@
+// Package apiversion mounts one route table under several API versions (/api/v1, /api/v2, ...).
+// Routes are declared once and served by every version unless limited with Only; a route can
+// swap its handlers for a version with Version, e.g. to return a new response shape from v2
+// while v1 keeps the old one. Versions slated for removal advertise it with the Deprecation,
+// Sunset and Link (rel="successor-version") response headers.
+package apiversion
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKey holds the version a request was routed under
+const ContextKey = "api_version"
+
+// Deprecation describes when a version of a route was deprecated and when it goes away.
+// Zero times are left out of the headers.
+type Deprecation struct {
+	Since  time.Time
+	Sunset time.Time
+}
+
+// DeprecationFromEnv reads API_<VERSION>_DEPRECATED_AT and API_<VERSION>_SUNSET as
+// YYYY-MM-DD or RFC3339 dates. Invalid values are ignored with a warning.
+func DeprecationFromEnv(version string) Deprecation {
+	prefix := "API_" + strings.ToUpper(version)
+	return Deprecation{
+		Since:  envDate(prefix + "_DEPRECATED_AT"),
+		Sunset: envDate(prefix + "_SUNSET"),
+	}
+}
+
+func envDate(key string) time.Time {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	log.Printf("⚠️ Invalid %s %q, expected YYYY-MM-DD or RFC3339", key, value)
+	return time.Time{}
+}
+
+// FromContext returns the version the request was routed under, empty outside versioned routes
+func FromContext(c *gin.Context) string {
+	return c.GetString(ContextKey)
+}
+
+// Router collects routes and mounts them under /api/<version> for every version
+type Router struct {
+	engine       gin.IRouter
+	versions     []string
+	routes       []*Route
+	deprecations map[string]Deprecation
+}
+
+// New creates a router serving the given versions, oldest first
+func New(engine gin.IRouter, versions ...string) *Router {
+	return &Router{
+		engine:       engine,
+		versions:     versions,
+		deprecations: make(map[string]Deprecation),
+	}
+}
+
+// Versions returns the served versions, oldest first
+func (r *Router) Versions() []string {
+	return r.versions
+}
+
+// Deprecate marks every route of a version that a newer version overrides as deprecated.
+// Routes served unchanged by all versions are not affected.
+func (r *Router) Deprecate(version string, deprecation Deprecation) {
+	r.deprecations[version] = deprecation
+}
+
+// Group returns a route group relative to /api/<version>
+func (r *Router) Group(path string, handlers ...gin.HandlerFunc) *Group {
+	return &Group{router: r, path: path, middleware: handlers}
+}
+
+// Mount registers the collected routes on the engine, once per version.
+// Routes added after Mount are not served.
+func (r *Router) Mount() {
+	for _, version := range r.versions {
+		base := r.engine.Group("/api/" + version)
+		for _, route := range r.routes {
+			handlers := route.handlersFor(version)
+			if handlers == nil {
+				continue
+			}
+
+			chain := []gin.HandlerFunc{tagVersion(version)}
+			if deprecation, ok := r.deprecationFor(route, version); ok {
+				chain = append(chain, deprecationHeaders(deprecation, version, r.successorFor(route, version)))
+			}
+			chain = append(chain, route.middleware...)
+			chain = append(chain, handlers...)
+
+			if route.method == anyMethod {
+				base.Any(route.path, chain...)
+			} else {
+				base.Handle(route.method, route.path, chain...)
+			}
+		}
+	}
+}
+
+// deprecationFor returns the deprecation of a route in a version: the route's own, or the
+// router's when a newer version overrides the route
+func (r *Router) deprecationFor(route *Route, version string) (Deprecation, bool) {
+	if deprecation, ok := route.deprecations[version]; ok {
+		return deprecation, true
+	}
+	deprecation, ok := r.deprecations[version]
+	if !ok {
+		return Deprecation{}, false
+	}
+	for _, newer := range r.newerVersions(version) {
+		if _, overridden := route.overrides[newer]; overridden {
+			return deprecation, true
+		}
+	}
+	return Deprecation{}, false
+}
+
+// successorFor returns the next version serving the route, preferring one that overrides it
+func (r *Router) successorFor(route *Route, version string) string {
+	successor := ""
+	for _, newer := range r.newerVersions(version) {
+		if route.handlersFor(newer) == nil {
+			continue
+		}
+		if _, overridden := route.overrides[newer]; overridden {
+			return newer
+		}
+		if successor == "" {
+			successor = newer
+		}
+	}
+	return successor
+}
+
+func (r *Router) newerVersions(version string) []string {
+	for i, v := range r.versions {
+		if v == version {
+			return r.versions[i+1:]
+		}
+	}
+	return nil
+}
+
+func tagVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ContextKey, version)
+		c.Next()
+	}
+}
+
+// deprecationHeaders sets Deprecation (RFC 9745), Sunset (RFC 8594) and a successor-version
+// link pointing at the same path under the successor version
+func deprecationHeaders(deprecation Deprecation, version, successor string) gin.HandlerFunc {
+	value := "true"
+	if !deprecation.Since.IsZero() {
+		value = "@" + strconv.FormatInt(deprecation.Since.Unix(), 10)
+	}
+	sunset := ""
+	if !deprecation.Sunset.IsZero() {
+		sunset = deprecation.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", value)
+		if sunset != "" {
+			c.Header("Sunset", sunset)
+		}
+		if successor != "" {
+			path := strings.Replace(c.Request.URL.Path, "/api/"+version, "/api/"+successor, 1)
+			c.Header("Link", "<"+path+">; rel=\"successor-version\"")
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrLockBusy is returned when a lock is still held by someone else after waiting
+var ErrLockBusy = errors.New("lock is held by another request")
+
+// lockPollInterval is how often a busy lock is tried again
+const lockPollInterval = 50 * time.Millisecond
+
+// releaseScript deletes a lock only while it still holds the owner's token, so a holder
+// whose lock expired can't release the next holder's
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Lock takes the lock lock:<name> for at most ttl, waiting up to wait while someone else
+// holds it, and returns the function releasing it. The ttl bounds how long a crashed holder
+// blocks the others. Locks live outside the cache namespaces, purges leave them alone.
+func (cs *CacheService) Lock(ctx context.Context, name string, ttl, wait time.Duration) (func(), error) {
+	var token [16]byte
+	if _, err := rand.Read(token[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	key := "lock:" + name
+	value := hex.EncodeToString(token[:])
+
+	deadline := time.Now().Add(wait)
+	for {
+		acquired, err := cs.client.SetNX(ctx, key, value, ttl).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to take lock %s: %w", name, err)
+		}
+		if acquired {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: %s", ErrLockBusy, name)
+		}
+		select {
+		case <-time.After(lockPollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return func() {
+		// The request may be gone by now, the lock is released regardless
+		if err := releaseScript.Run(context.Background(), cs.client, []string{key}, value).Err(); err != nil {
+			fmt.Printf("⚠️ Failed to release lock %s, it expires in %s: %v\n", name, ttl, err)
+		}
+	}, nil
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Namespaces are the key prefixes of the payment cache, each entry is stored as
+// <namespace>:<id> (payment:order:<order_id> for payments looked up by order)
+var Namespaces = []string{"payment", "user:payments", "midtrans:transaction", "product"}
+
+// ErrUnknownNamespace is returned when purging a namespace that isn't in Namespaces
+var ErrUnknownNamespace = errors.New("unknown cache namespace")
+
+// namespaceCounters count the reads of one namespace
+type namespaceCounters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NamespaceStats describes one namespace. Keys counts its entries in Redis, hits and misses
+// are this instance's reads since it started.
+type NamespaceStats struct {
+	Name    string  `json:"name"`
+	Pattern string  `json:"pattern"`
+	Keys    int     `json:"keys"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// Stats is the admin view of the cache
+type Stats struct {
+	Namespaces []NamespaceStats `json:"namespaces"`
+	Hits       int64            `json:"hits"`
+	Misses     int64            `json:"misses"`
+	HitRate    float64          `json:"hit_rate"`
+	Since      time.Time        `json:"since"`
+}
+
+// countRead records a hit or miss in namespace
+func (cs *CacheService) countRead(namespace string, hit bool) {
+	counters, ok := cs.namespaces[namespace]
+	if !ok {
+		return
+	}
+	if hit {
+		counters.hits.Add(1)
+	} else {
+		counters.misses.Add(1)
+	}
+}
+
+// Stats counts the keys of every namespace in Redis and reports the read counters
+func (cs *CacheService) Stats(ctx context.Context) (Stats, error) {
+	stats := Stats{Namespaces: []NamespaceStats{}, Since: cs.started}
+	for _, name := range Namespaces {
+		counters := cs.namespaces[name]
+		ns := NamespaceStats{
+			Name:    name,
+			Pattern: name + ":*",
+			Hits:    counters.hits.Load(),
+			Misses:  counters.misses.Load(),
+		}
+		ns.HitRate = hitRate(ns.Hits, ns.Misses)
+
+		err := scanKeys(ctx, cs.client, ns.Pattern, func(keys []string) error {
+			ns.Keys += len(keys)
+			return nil
+		})
+		if err != nil {
+			return stats, fmt.Errorf("failed to count cached keys %s: %w", ns.Pattern, err)
+		}
+
+		stats.Hits += ns.Hits
+		stats.Misses += ns.Misses
+		stats.Namespaces = append(stats.Namespaces, ns)
+	}
+	stats.HitRate = hitRate(stats.Hits, stats.Misses)
+	return stats, nil
+}
+
+// Purge removes a namespace from Redis. With an id only <namespace>:<id> and its variants
+// (<namespace>:<id>:*, e.g. a user's filtered payment lists) are removed. It returns how many
+// keys were deleted.
+func (cs *CacheService) Purge(ctx context.Context, namespace, id string) (int, error) {
+	if _, ok := cs.namespaces[namespace]; !ok {
+		return 0, fmt.Errorf("%w %q", ErrUnknownNamespace, namespace)
+	}
+
+	pattern := namespace + ":*"
+	deleted := 0
+	if id != "" {
+		key := namespace + ":" + id
+		pattern = key + ":*"
+		count, err := cs.client.Del(ctx, key).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to purge %s: %w", key, err)
+		}
+		deleted += int(count)
+	}
+
+	err := scanKeys(ctx, cs.client, pattern, func(keys []string) error {
+		if _, err := cs.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			deleteKeys(ctx, pipe, keys)
+			return nil
+		}); err != nil {
+			return err
+		}
+		deleted += len(keys)
+		return nil
+	})
+	if err != nil {
+		return deleted, fmt.Errorf("failed to purge %s: %w", pattern, err)
+	}
+
+	log.Printf("🗑️ Purged %d %s cache entries", deleted, namespace)
+	return deleted, nil
+}
+
+// hitRate is hits over reads, 0 before the first read
+func hitRate(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,10 +13,35 @@ import (
 
 // CacheService handles Redis caching operations
 type CacheService struct {
-	client *redis.Client
-	ctx    context.Context
+	client   redis.UniversalClient
+	topology RedisTopology
+	jitter   float64 // CACHE_TTL_JITTER, see withJitter
+
+	namespaces map[string]*namespaceCounters // reads per namespace, see Stats
+	started    time.Time
+}
+
+// Cache is the payment cache used by handlers. Every call takes the request context so
+// Redis round trips are abandoned with the request.
+type Cache interface {
+	SetPayment(ctx context.Context, paymentID string, data interface{}, expiration time.Duration) error
+	GetPayment(ctx context.Context, paymentID string, dest interface{}) error
+	SetPaymentByOrderID(ctx context.Context, orderID string, data interface{}, expiration time.Duration) error
+	GetPaymentByOrderID(ctx context.Context, orderID string, dest interface{}) error
+	SetPaymentEntries(ctx context.Context, paymentID, orderID string, data interface{}, expiration time.Duration) error
+	SetUserPayments(ctx context.Context, userID string, data interface{}, expiration time.Duration) error
+	GetUserPayments(ctx context.Context, userID string, dest interface{}) error
+	DeleteUserPayments(ctx context.Context, userID string) error
+	InvalidatePaymentCache(ctx context.Context, paymentID, orderID, userID string) error
+	SetProduct(ctx context.Context, productID string, data interface{}, expiration time.Duration) error
+	GetProduct(ctx context.Context, productID string, dest interface{}) error
+	DeleteProduct(ctx context.Context, productID string) error
+	Lock(ctx context.Context, name string, ttl, wait time.Duration) (func(), error)
 }
 
+// Ensure CacheService implements Cache
+var _ Cache = (*CacheService)(nil)
+
 // NewCacheService creates a new cache service
 func NewCacheService() (*CacheService, error) {
 	// Load .env file
@@ -25,49 +49,39 @@ func NewCacheService() (*CacheService, error) {
 		log.Println("⚠️ .env file not found in cache package, using system env")
 	}
 
-	// Get Redis configuration from environment
-	addr := os.Getenv("REDIS_ADDR")
-	if addr == "" {
-		addr = "localhost:6379"
-	}
-
-	password := os.Getenv("REDIS_PASSWORD")
-	if password == "" {
-		password = ""
-	}
-
-	db := 0
-	if os.Getenv("REDIS_DB") != "" {
-		if _, err := fmt.Sscanf(os.Getenv("REDIS_DB"), "%d", &db); err != nil {
-			log.Printf("⚠️ Invalid REDIS_DB value, using default: %d", db)
-		}
+	// Get the Redis topology from environment (REDIS_MODE standalone, sentinel or cluster)
+	topology, err := RedisTopologyFromEnv()
+	if err != nil {
+		return nil, err
 	}
 
 	// Create Redis client
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: password,
-		DB:       db,
-	})
-
-	ctx := context.Background()
+	rdb := topology.NewClient()
 
 	// Test connection
-	_, err := rdb.Ping(ctx).Result()
+	_, err = rdb.Ping(context.Background()).Result()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		rdb.Close()
+		return nil, fmt.Errorf("failed to connect to Redis (%s): %w", topology, err)
 	}
 
-	log.Println("✅ Connected to Redis successfully")
+	log.Printf("✅ Connected to Redis successfully (%s)", topology)
 
-	return &CacheService{
-		client: rdb,
-		ctx:    ctx,
-	}, nil
+	cs := &CacheService{
+		client:     rdb,
+		topology:   topology,
+		jitter:     jitterFromEnv(),
+		namespaces: make(map[string]*namespaceCounters),
+		started:    time.Now(),
+	}
+	for _, namespace := range Namespaces {
+		cs.namespaces[namespace] = &namespaceCounters{}
+	}
+	return cs, nil
 }
 
 // SetPayment caches payment data
-func (cs *CacheService) SetPayment(paymentID string, data interface{}, expiration time.Duration) error {
+func (cs *CacheService) SetPayment(ctx context.Context, paymentID string, data interface{}, expiration time.Duration) error {
 	key := fmt.Sprintf("payment:%s", paymentID)
 	
 	jsonData, err := json.Marshal(data)
@@ -75,7 +89,7 @@ func (cs *CacheService) SetPayment(paymentID string, data interface{}, expiratio
 		return fmt.Errorf("failed to marshal payment data: %w", err)
 	}
 
-	err = cs.client.Set(cs.ctx, key, jsonData, expiration).Err()
+	err = cs.client.Set(ctx, key, jsonData, cs.withJitter(expiration)).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache payment: %w", err)
 	}
@@ -85,10 +99,15 @@ func (cs *CacheService) SetPayment(paymentID string, data interface{}, expiratio
 }
 
 // GetPayment retrieves payment data from cache
-func (cs *CacheService) GetPayment(paymentID string, dest interface{}) error {
+func (cs *CacheService) GetPayment(ctx context.Context, paymentID string, dest interface{}) error {
+	if bypassed(ctx) {
+		return ErrBypassed
+	}
+
 	key := fmt.Sprintf("payment:%s", paymentID)
 	
-	val, err := cs.client.Get(cs.ctx, key).Result()
+	val, err := cs.client.Get(ctx, key).Result()
+	cs.countRead("payment", err == nil)
 	if err != nil {
 		if err == redis.Nil {
 			return fmt.Errorf("payment not found in cache")
@@ -105,10 +124,10 @@ func (cs *CacheService) GetPayment(paymentID string, dest interface{}) error {
 }
 
 // DeletePayment removes payment from cache
-func (cs *CacheService) DeletePayment(paymentID string) error {
+func (cs *CacheService) DeletePayment(ctx context.Context, paymentID string) error {
 	key := fmt.Sprintf("payment:%s", paymentID)
 	
-	err := cs.client.Del(cs.ctx, key).Err()
+	err := cs.client.Del(ctx, key).Err()
 	if err != nil {
 		return fmt.Errorf("failed to delete payment from cache: %w", err)
 	}
@@ -118,7 +137,7 @@ func (cs *CacheService) DeletePayment(paymentID string) error {
 }
 
 // SetPaymentByOrderID caches payment data by order ID
-func (cs *CacheService) SetPaymentByOrderID(orderID string, data interface{}, expiration time.Duration) error {
+func (cs *CacheService) SetPaymentByOrderID(ctx context.Context, orderID string, data interface{}, expiration time.Duration) error {
 	key := fmt.Sprintf("payment:order:%s", orderID)
 	
 	jsonData, err := json.Marshal(data)
@@ -126,7 +145,7 @@ func (cs *CacheService) SetPaymentByOrderID(orderID string, data interface{}, ex
 		return fmt.Errorf("failed to marshal payment data: %w", err)
 	}
 
-	err = cs.client.Set(cs.ctx, key, jsonData, expiration).Err()
+	err = cs.client.Set(ctx, key, jsonData, cs.withJitter(expiration)).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache payment by order ID: %w", err)
 	}
@@ -136,10 +155,15 @@ func (cs *CacheService) SetPaymentByOrderID(orderID string, data interface{}, ex
 }
 
 // GetPaymentByOrderID retrieves payment data by order ID from cache
-func (cs *CacheService) GetPaymentByOrderID(orderID string, dest interface{}) error {
+func (cs *CacheService) GetPaymentByOrderID(ctx context.Context, orderID string, dest interface{}) error {
+	if bypassed(ctx) {
+		return ErrBypassed
+	}
+
 	key := fmt.Sprintf("payment:order:%s", orderID)
 	
-	val, err := cs.client.Get(cs.ctx, key).Result()
+	val, err := cs.client.Get(ctx, key).Result()
+	cs.countRead("payment", err == nil)
 	if err != nil {
 		if err == redis.Nil {
 			return fmt.Errorf("payment not found in cache")
@@ -155,11 +179,33 @@ func (cs *CacheService) GetPaymentByOrderID(orderID string, dest interface{}) er
 	return nil
 }
 
+// SetPaymentEntries caches a payment under both its ID and order ID in a single pipeline
+func (cs *CacheService) SetPaymentEntries(ctx context.Context, paymentID, orderID string, data interface{}, expiration time.Duration) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment data: %w", err)
+	}
+
+	// Both entries expire together, the order entry never outlives the payment entry
+	expiration = cs.withJitter(expiration)
+	_, err = cs.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, fmt.Sprintf("payment:%s", paymentID), jsonData, expiration)
+		pipe.Set(ctx, fmt.Sprintf("payment:order:%s", orderID), jsonData, expiration)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cache payment: %w", err)
+	}
+
+	log.Printf("💾 Cached payment: %s (order %s)", paymentID, orderID)
+	return nil
+}
+
 // DeletePaymentByOrderID removes payment by order ID from cache
-func (cs *CacheService) DeletePaymentByOrderID(orderID string) error {
+func (cs *CacheService) DeletePaymentByOrderID(ctx context.Context, orderID string) error {
 	key := fmt.Sprintf("payment:order:%s", orderID)
 	
-	err := cs.client.Del(cs.ctx, key).Err()
+	err := cs.client.Del(ctx, key).Err()
 	if err != nil {
 		return fmt.Errorf("failed to delete payment from cache: %w", err)
 	}
@@ -169,7 +215,7 @@ func (cs *CacheService) DeletePaymentByOrderID(orderID string) error {
 }
 
 // SetUserPayments caches user payments list
-func (cs *CacheService) SetUserPayments(userID string, data interface{}, expiration time.Duration) error {
+func (cs *CacheService) SetUserPayments(ctx context.Context, userID string, data interface{}, expiration time.Duration) error {
 	key := fmt.Sprintf("user:payments:%s", userID)
 	
 	jsonData, err := json.Marshal(data)
@@ -177,7 +223,7 @@ func (cs *CacheService) SetUserPayments(userID string, data interface{}, expirat
 		return fmt.Errorf("failed to marshal user payments data: %w", err)
 	}
 
-	err = cs.client.Set(cs.ctx, key, jsonData, expiration).Err()
+	err = cs.client.Set(ctx, key, jsonData, cs.withJitter(expiration)).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache user payments: %w", err)
 	}
@@ -187,10 +233,15 @@ func (cs *CacheService) SetUserPayments(userID string, data interface{}, expirat
 }
 
 // GetUserPayments retrieves user payments from cache
-func (cs *CacheService) GetUserPayments(userID string, dest interface{}) error {
+func (cs *CacheService) GetUserPayments(ctx context.Context, userID string, dest interface{}) error {
+	if bypassed(ctx) {
+		return ErrBypassed
+	}
+
 	key := fmt.Sprintf("user:payments:%s", userID)
 	
-	val, err := cs.client.Get(cs.ctx, key).Result()
+	val, err := cs.client.Get(ctx, key).Result()
+	cs.countRead("user:payments", err == nil)
 	if err != nil {
 		if err == redis.Nil {
 			return fmt.Errorf("user payments not found in cache")
@@ -206,11 +257,15 @@ func (cs *CacheService) GetUserPayments(userID string, dest interface{}) error {
 	return nil
 }
 
-// DeleteUserPayments removes user payments from cache
-func (cs *CacheService) DeleteUserPayments(userID string) error {
-	key := fmt.Sprintf("user:payments:%s", userID)
-	
-	err := cs.client.Del(cs.ctx, key).Err()
+// DeleteUserPayments removes every cached page of a user's payments
+func (cs *CacheService) DeleteUserPayments(ctx context.Context, userID string) error {
+	keys, err := cs.userPaymentKeys(ctx, userID)
+	if err == nil {
+		_, err = cs.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			deleteKeys(ctx, pipe, keys)
+			return nil
+		})
+	}
 	if err != nil {
 		return fmt.Errorf("failed to delete user payments from cache: %w", err)
 	}
@@ -219,8 +274,20 @@ func (cs *CacheService) DeleteUserPayments(userID string) error {
 	return nil
 }
 
+// userPaymentKeys returns user:payments:<userID> and every filtered/paginated variant
+// (user:payments:<userID>:<query>) found with SCAN, on every master of a cluster
+func (cs *CacheService) userPaymentKeys(ctx context.Context, userID string) ([]string, error) {
+	keys := []string{fmt.Sprintf("user:payments:%s", userID)}
+
+	err := scanKeys(ctx, cs.client, fmt.Sprintf("user:payments:%s:*", userID), func(found []string) error {
+		keys = append(keys, found...)
+		return nil
+	})
+	return keys, err
+}
+
 // SetMidtransTransaction caches Midtrans transaction data
-func (cs *CacheService) SetMidtransTransaction(transactionID string, data interface{}, expiration time.Duration) error {
+func (cs *CacheService) SetMidtransTransaction(ctx context.Context, transactionID string, data interface{}, expiration time.Duration) error {
 	key := fmt.Sprintf("midtrans:transaction:%s", transactionID)
 	
 	jsonData, err := json.Marshal(data)
@@ -228,7 +295,7 @@ func (cs *CacheService) SetMidtransTransaction(transactionID string, data interf
 		return fmt.Errorf("failed to marshal Midtrans transaction data: %w", err)
 	}
 
-	err = cs.client.Set(cs.ctx, key, jsonData, expiration).Err()
+	err = cs.client.Set(ctx, key, jsonData, cs.withJitter(expiration)).Err()
 	if err != nil {
 		return fmt.Errorf("failed to cache Midtrans transaction: %w", err)
 	}
@@ -238,10 +305,15 @@ func (cs *CacheService) SetMidtransTransaction(transactionID string, data interf
 }
 
 // GetMidtransTransaction retrieves Midtrans transaction from cache
-func (cs *CacheService) GetMidtransTransaction(transactionID string, dest interface{}) error {
+func (cs *CacheService) GetMidtransTransaction(ctx context.Context, transactionID string, dest interface{}) error {
+	if bypassed(ctx) {
+		return ErrBypassed
+	}
+
 	key := fmt.Sprintf("midtrans:transaction:%s", transactionID)
 	
-	val, err := cs.client.Get(cs.ctx, key).Result()
+	val, err := cs.client.Get(ctx, key).Result()
+	cs.countRead("midtrans:transaction", err == nil)
 	if err != nil {
 		if err == redis.Nil {
 			return fmt.Errorf("Midtrans transaction not found in cache")
@@ -257,28 +329,155 @@ func (cs *CacheService) GetMidtransTransaction(transactionID string, dest interf
 	return nil
 }
 
-// InvalidatePaymentCache invalidates all payment-related cache entries
-func (cs *CacheService) InvalidatePaymentCache(paymentID, orderID, userID string) error {
+// SetProduct caches a product looked up from Product-Service
+func (cs *CacheService) SetProduct(ctx context.Context, productID string, data interface{}, expiration time.Duration) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal product data: %w", err)
+	}
+
+	if err := cs.client.Set(ctx, "product:"+productID, jsonData, cs.withJitter(expiration)).Err(); err != nil {
+		return fmt.Errorf("failed to cache product: %w", err)
+	}
+	return nil
+}
+
+// GetProduct retrieves a cached product lookup
+func (cs *CacheService) GetProduct(ctx context.Context, productID string, dest interface{}) error {
+	if bypassed(ctx) {
+		return ErrBypassed
+	}
+
+	val, err := cs.client.Get(ctx, "product:"+productID).Bytes()
+	cs.countRead("product", err == nil)
+	if err != nil {
+		if err == redis.Nil {
+			return fmt.Errorf("product not found in cache")
+		}
+		return fmt.Errorf("failed to get product from cache: %w", err)
+	}
+
+	if err := json.Unmarshal(val, dest); err != nil {
+		return fmt.Errorf("failed to unmarshal product data: %w", err)
+	}
+	return nil
+}
+
+// DeleteProduct drops a cached product lookup, e.g. when Product-Service reports a change
+func (cs *CacheService) DeleteProduct(ctx context.Context, productID string) error {
+	if err := cs.client.Del(ctx, "product:"+productID).Err(); err != nil {
+		return fmt.Errorf("failed to delete product from cache: %w", err)
+	}
+	return nil
+}
+
+// InvalidatePaymentCache invalidates all payment-related cache entries in one MULTI/EXEC,
+// so readers never see the payment key removed while the order key is still cached. On a
+// cluster the transaction is per slot, keys in other slots are deleted in their own.
+func (cs *CacheService) InvalidatePaymentCache(ctx context.Context, paymentID, orderID, userID string) error {
 	keys := []string{
 		fmt.Sprintf("payment:%s", paymentID),
 		fmt.Sprintf("payment:order:%s", orderID),
-		fmt.Sprintf("user:payments:%s", userID),
 	}
 
-	for _, key := range keys {
-		err := cs.client.Del(cs.ctx, key).Err()
+	userKeys, err := cs.userPaymentKeys(ctx, userID)
+	if err != nil {
+		log.Printf("⚠️ Failed to list cached payments of user %s: %v", userID, err)
+	}
+	keys = append(keys, userKeys...)
+
+	if _, err := cs.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		deleteKeys(ctx, pipe, keys)
+		return nil
+	}); err != nil {
+		log.Printf("⚠️ Failed to invalidate payment cache for payment %s: %v", paymentID, err)
+		return fmt.Errorf("failed to invalidate payment cache: %w", err)
+	}
+
+	log.Printf("🗑️ Invalidated payment cache for payment: %s", paymentID)
+	return nil
+}
+
+// PaymentKeys identifies the cache entries of one payment
+type PaymentKeys struct {
+	PaymentID string
+	OrderID   string
+	UserID    string
+}
+
+// InvalidatePaymentsCache invalidates the entries of many payments, and the lists of their
+// users, in one MULTI/EXEC
+func (cs *CacheService) InvalidatePaymentsCache(ctx context.Context, payments []PaymentKeys) error {
+	if len(payments) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(payments)*2)
+	users := make(map[string]bool)
+	for _, payment := range payments {
+		keys = append(keys,
+			fmt.Sprintf("payment:%s", payment.PaymentID),
+			fmt.Sprintf("payment:order:%s", payment.OrderID),
+		)
+		if users[payment.UserID] {
+			continue
+		}
+		users[payment.UserID] = true
+
+		userKeys, err := cs.userPaymentKeys(ctx, payment.UserID)
 		if err != nil {
-			log.Printf("⚠️ Failed to delete cache key %s: %v", key, err)
+			log.Printf("⚠️ Failed to list cached payments of user %s: %v", payment.UserID, err)
 		}
+		keys = append(keys, userKeys...)
 	}
 
-	log.Printf("🗑️ Invalidated payment cache for payment: %s", paymentID)
+	if _, err := cs.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		deleteKeys(ctx, pipe, keys)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to invalidate payments cache: %w", err)
+	}
+
+	log.Printf("🗑️ Invalidated cache for %d payments of %d users", len(payments), len(users))
 	return nil
 }
 
+// paymentKeyPatterns match every entry of the payment cache, leaving the feature flags and
+// anything else sharing the Redis database alone
+var paymentKeyPatterns = []string{"payment:*", "user:payments:*", "midtrans:transaction:*", "product:*"}
+
+// ClearPayments removes every cached payment, payment list, Midtrans transaction and product
+// lookup, returning how many keys were deleted
+func (cs *CacheService) ClearPayments(ctx context.Context) (int, error) {
+	deleted := 0
+	for _, pattern := range paymentKeyPatterns {
+		err := scanKeys(ctx, cs.client, pattern, func(keys []string) error {
+			if _, err := cs.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+				deleteKeys(ctx, pipe, keys)
+				return nil
+			}); err != nil {
+				return err
+			}
+			deleted += len(keys)
+			return nil
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to clear cached keys %s: %w", pattern, err)
+		}
+	}
+
+	log.Printf("🗑️ Cleared %d payment cache entries", deleted)
+	return deleted, nil
+}
+
+// Client exposes the Redis connection to components sharing it, such as feature flags
+func (cs *CacheService) Client() redis.UniversalClient {
+	return cs.client
+}
+
 // HealthCheck checks if Redis connection is healthy
-func (cs *CacheService) HealthCheck() error {
-	_, err := cs.client.Ping(cs.ctx).Result()
+func (cs *CacheService) HealthCheck(ctx context.Context) error {
+	_, err := cs.client.Ping(ctx).Result()
 	if err != nil {
 		return fmt.Errorf("Redis health check failed: %w", err)
 	}
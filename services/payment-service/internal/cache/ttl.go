@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"time"
+)
+
+// TTLs are the Redis expirations of the cached payment entities. Product lookups have their
+// own PRODUCT_CACHE_TTL.
+type TTLs struct {
+	Payment      time.Duration // payment:<id> and payment:order:<order_id>, CACHE_TTL_PAYMENT
+	UserPayments time.Duration // user:payments:<user_id>[:<query>], CACHE_TTL_USER_PAYMENTS
+}
+
+// TTLsFromEnv reads CACHE_TTL_PAYMENT (default 1h) and CACHE_TTL_USER_PAYMENTS (default 30m)
+func TTLsFromEnv() TTLs {
+	return TTLs{
+		Payment:      envDuration("CACHE_TTL_PAYMENT", time.Hour),
+		UserPayments: envDuration("CACHE_TTL_USER_PAYMENTS", 30*time.Minute),
+	}
+}
+
+func envDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("⚠️ Ignoring invalid %s=%q", key, value)
+		return defaultValue
+	}
+	return parsed
+}
+
+// jitterFromEnv reads CACHE_TTL_JITTER, the fraction (0-1, default 0.1) expirations are
+// shortened by at most
+func jitterFromEnv() float64 {
+	value := os.Getenv("CACHE_TTL_JITTER")
+	if value == "" {
+		return 0.1
+	}
+	jitter, err := strconv.ParseFloat(value, 64)
+	if err != nil || jitter < 0 || jitter > 1 {
+		log.Printf("⚠️ Ignoring invalid CACHE_TTL_JITTER=%q", value)
+		return 0.1
+	}
+	return jitter
+}
+
+// withJitter shortens expiration by a random fraction up to the service's jitter, so entries
+// cached at the same time (e.g. a batch of settled payments) don't all expire together. The
+// configured TTL stays the upper bound of how stale an entry can get.
+func (cs *CacheService) withJitter(expiration time.Duration) time.Duration {
+	if expiration <= 0 || cs.jitter <= 0 {
+		return expiration
+	}
+	return expiration - time.Duration(rand.Float64()*cs.jitter*float64(expiration))
+}
+
+// ErrBypassed is returned by reads of a context marked with WithBypass
+var ErrBypassed = errors.New("cache bypassed")
+
+type bypassKey struct{}
+
+// WithBypass marks ctx so reads miss the cache, loading fresh values that replace the cached
+// ones. It serves the nocache debugging flag.
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// bypassed reports whether ctx was marked with WithBypass
+func bypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassKey{}).(bool)
+	return bypass
+}
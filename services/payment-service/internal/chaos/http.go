@@ -0,0 +1,48 @@
+package chaos
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Transport wraps next with the upstream faults targeting the client name
+func Transport(name string, next http.RoundTripper) http.RoundTripper {
+	return &faultTransport{name: name, next: next}
+}
+
+type faultTransport struct {
+	name string
+	next http.RoundTripper
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault, ok := Inject(req.Context(), ScopeUpstream, t.name)
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if fault.Type == TypeDrop {
+		return nil, ErrDropped
+	}
+
+	body := fmt.Sprintf(`{"error":"Injected fault","details":"chaos fault %s"}`, fault.ID)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fault.Status, http.StatusText(fault.Status)),
+		StatusCode:    fault.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}, "X-Chaos-Fault": []string{fault.ID}},
+		Body:          io.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,110 @@
+package consumers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"payment-service/internal/cache"
+	"payment-service/internal/events"
+	"payment-service/internal/models"
+	"payment-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ProductCacheConsumer drops the cached product lookup and cached payment responses when
+// Product-Service reports a product change, so the next checkout fetches the product again
+// and the next read rebuilds the payments instead of serving an outdated product for an hour
+type ProductCacheConsumer struct {
+	eventSvc *events.EventService
+	repo     *repository.PaymentRepository
+	cacheSvc *cache.CacheService
+	mode     events.ConsumerMode // CONSUMER_MODE_PRODUCT_CACHE
+}
+
+// NewProductCacheConsumer creates a new product cache consumer
+func NewProductCacheConsumer(eventSvc *events.EventService, repo *repository.PaymentRepository, cacheSvc *cache.CacheService) *ProductCacheConsumer {
+	return &ProductCacheConsumer{
+		eventSvc: eventSvc,
+		repo:     repo,
+		cacheSvc: cacheSvc,
+		mode:     events.ConsumerModeFromEnv("product_cache"),
+	}
+}
+
+// Start starts consuming product events
+func (pc *ProductCacheConsumer) Start() error {
+	err := pc.eventSvc.Subscribe(pc.mode.Queue("payment.product_cache.queue"), []events.Binding{
+		{Exchange: "product.events", RoutingKey: "product.updated"},
+		{Exchange: "product.events", RoutingKey: "product.stock.reduced"},
+	}, pc.processMessage, events.OrderedByDataField("product_id"))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to product events: %w", err)
+	}
+
+	log.Printf("🚀 Payment-Service product cache consumer started (%s)", pc.mode)
+	return nil
+}
+
+// processMessage invalidates the cached lookup and payments of the product carried by a
+// product event
+func (pc *ProductCacheConsumer) processMessage(msg events.Message) error {
+	var event events.Event
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Printf("❌ Failed to unmarshal product event: %v", err)
+		return fmt.Errorf("%w: %v", events.ErrReject, err)
+	}
+
+	productData, ok := event.Data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: invalid product data format", events.ErrReject)
+	}
+
+	productIDStr, _ := productData["product_id"].(string)
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		return fmt.Errorf("%w: invalid product_id %q", events.ErrReject, productIDStr)
+	}
+
+	effects := events.NewEffects("product_cache", pc.mode, msg)
+	defer effects.Log()
+
+	err = effects.Apply(events.Change{Action: "invalidate", Target: "cache/product " + productIDStr}, func() error {
+		return pc.cacheSvc.DeleteProduct(context.Background(), productIDStr)
+	})
+	if err != nil {
+		log.Printf("❌ Failed to invalidate cached product %s: %v", productIDStr, err)
+		return err
+	}
+
+	invalidated := 0
+	err = pc.repo.ForEachProductPayment(context.Background(), productID, func(payments []models.Payment) error {
+		keys := make([]cache.PaymentKeys, 0, len(payments))
+		for _, payment := range payments {
+			keys = append(keys, cache.PaymentKeys{
+				PaymentID: payment.ID.String(),
+				OrderID:   payment.OrderID,
+				UserID:    payment.UserID.String(),
+			})
+		}
+		err := effects.Apply(events.Change{Action: "invalidate", Target: "cache/payments of product " + productIDStr, After: len(keys)}, func() error {
+			return pc.cacheSvc.InvalidatePaymentsCache(context.Background(), keys)
+		})
+		if err != nil {
+			return err
+		}
+		invalidated += len(keys)
+		return nil
+	})
+	if err != nil {
+		log.Printf("❌ Failed to invalidate payments of product %s: %v", productIDStr, err)
+		return err
+	}
+
+	if invalidated > 0 && !effects.Shadow() {
+		log.Printf("🧹 Invalidated %d cached payments of product %s from %s", invalidated, productIDStr, msg.RoutingKey)
+	}
+	return nil
+}
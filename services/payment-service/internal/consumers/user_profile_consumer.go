@@ -0,0 +1,152 @@
+package consumers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"payment-service/internal/events"
+	"payment-service/internal/models"
+	"payment-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// UserProfileConsumer keeps the user read model in sync with User-Service events, anonymizing
+// deleted users
+type UserProfileConsumer struct {
+	eventSvc *events.EventService
+	repo     *repository.UserProfileRepository
+	mode     events.ConsumerMode // CONSUMER_MODE_USER_PROFILE
+}
+
+// NewUserProfileConsumer creates a new user profile consumer
+func NewUserProfileConsumer(eventSvc *events.EventService, repo *repository.UserProfileRepository) *UserProfileConsumer {
+	return &UserProfileConsumer{
+		eventSvc: eventSvc,
+		repo:     repo,
+		mode:     events.ConsumerModeFromEnv("user_profile"),
+	}
+}
+
+// Start starts consuming user events
+func (uc *UserProfileConsumer) Start() error {
+	err := uc.eventSvc.Subscribe(uc.mode.Queue("payment.user_profile.queue"), []events.Binding{
+		{Exchange: "user.events", RoutingKey: "user.registered"},
+		{Exchange: "user.events", RoutingKey: "user.verified"},
+		{Exchange: "user.events", RoutingKey: "user.updated"},
+		{Exchange: "user.events", RoutingKey: "user.deleted"},
+	}, uc.processMessage, events.OrderedByDataField("user_id"))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to user events: %w", err)
+	}
+
+	log.Printf("🚀 Payment-Service user profile consumer started (%s)", uc.mode)
+	return nil
+}
+
+// processMessage upserts the profile carried by a user event
+func (uc *UserProfileConsumer) processMessage(msg events.Message) error {
+	var event events.Event
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Printf("❌ Failed to unmarshal user event: %v", err)
+		return fmt.Errorf("%w: %v", events.ErrReject, err)
+	}
+
+	userData, ok := event.Data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: invalid user data format", events.ErrReject)
+	}
+
+	userIDStr, _ := userData["user_id"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return fmt.Errorf("%w: invalid user_id %q", events.ErrReject, userIDStr)
+	}
+
+	if msg.RoutingKey == "user.deleted" {
+		return uc.anonymize(msg, userID)
+	}
+
+	username, _ := userData["username"].(string)
+	email, _ := userData["email"].(string)
+
+	profile := &models.UserProfile{ID: userID, Username: username, Email: email}
+	// user.updated carries the verified phone number, empty once it was removed
+	if phoneNumber, ok := userData["phone_number"].(string); ok {
+		profile.PhoneNumber = &phoneNumber
+	}
+
+	effects := events.NewEffects("user_profile", uc.mode, msg)
+	defer effects.Log()
+
+	err = effects.Apply(profileChange(uc.repo, profile), func() error {
+		return uc.repo.Upsert(profile)
+	})
+	if err != nil {
+		log.Printf("❌ Failed to update user profile %s: %v", userIDStr, err)
+		return err
+	}
+	if effects.Shadow() {
+		return nil
+	}
+
+	log.Printf("👤 User profile %s updated from %s", userIDStr, msg.RoutingKey)
+	return nil
+}
+
+// anonymize clears the contact data of a deleted user, receipts and reminders for the user's
+// payments are no longer sent
+func (uc *UserProfileConsumer) anonymize(msg events.Message, userID uuid.UUID) error {
+	effects := events.NewEffects("user_profile", uc.mode, msg)
+	defer effects.Log()
+
+	change := events.Change{
+		Action: "anonymize",
+		Target: "user_profile/" + userID.String(),
+		After:  map[string]interface{}{"username": models.AnonymizedUsername, "email": ""},
+	}
+	if stored, err := uc.repo.GetByID(userID); err == nil {
+		if stored.Anonymized() {
+			return nil // redelivered
+		}
+		change.Before = map[string]interface{}{"username": stored.Username, "email": stored.Email}
+	}
+
+	err := effects.Apply(change, func() error {
+		return uc.repo.Anonymize(userID)
+	})
+	if err != nil {
+		log.Printf("❌ Failed to anonymize user profile %s: %v", userID, err)
+		return err
+	}
+	if effects.Shadow() {
+		return nil
+	}
+
+	log.Printf("👤 User profile %s anonymized after user.deleted", userID)
+	return nil
+}
+
+// profileChange describes the upsert of profile against the stored profile. A missing phone
+// number keeps the stored one, see UserProfileRepository.Upsert.
+func profileChange(repo *repository.UserProfileRepository, profile *models.UserProfile) events.Change {
+	after := map[string]interface{}{"username": profile.Username, "email": profile.Email}
+	if profile.PhoneNumber != nil {
+		after["phone_number"] = *profile.PhoneNumber
+	}
+	change := events.Change{
+		Action: "insert",
+		Target: "user_profile/" + profile.ID.String(),
+		After:  after,
+	}
+	if stored, err := repo.GetByID(profile.ID); err == nil {
+		before := map[string]interface{}{"username": stored.Username, "email": stored.Email}
+		if profile.PhoneNumber != nil && stored.PhoneNumber != nil {
+			before["phone_number"] = *stored.PhoneNumber
+		}
+		change.Action = "update"
+		change.Before = before
+	}
+	return change
+}
@@ -1,6 +1,7 @@
 package consumers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -11,7 +12,6 @@ import (
 	"payment-service/internal/repository"
 
 	"github.com/google/uuid"
-	"github.com/streadway/amqp"
 )
 
 // ValidationConsumer handles validation responses from other services
@@ -21,6 +21,7 @@ type ValidationConsumer struct {
 	// Map to track pending validations
 	pendingValidations map[string]*PendingValidation
 	mu                sync.RWMutex
+	mode              events.ConsumerMode // CONSUMER_MODE_VALIDATION
 }
 
 // PendingValidation tracks a pending validation request
@@ -50,79 +51,22 @@ func NewValidationConsumer(eventSvc *events.EventService, paymentRepo *repositor
 		eventSvc:          eventSvc,
 		paymentRepo:       paymentRepo,
 		pendingValidations: make(map[string]*PendingValidation),
+		mode:              events.ConsumerModeFromEnv("validation"),
 	}
 }
 
 // Start starts consuming validation response events
 func (vc *ValidationConsumer) Start() error {
-	channel := vc.eventSvc.GetChannel()
-	
-	// Declare queue for validation responses
-	queueName := "payment.validation.queue"
-	_, err := channel.QueueDeclare(
-		queueName, // name
-		true,      // durable
-		false,     // delete when unused
-		false,     // exclusive
-		false,     // no-wait
-		nil,       // arguments
-	)
-	if err != nil {
-		return fmt.Errorf("failed to declare queue: %w", err)
-	}
-
-	// Bind queue to product.events exchange with validation response routing key
-	err = channel.QueueBind(
-		queueName,                      // queue name
-		"product.validation.response",  // routing key
-		"product.events",               // exchange
-		false,                          // no-wait
-		nil,                            // arguments
-	)
-	if err != nil {
-		return fmt.Errorf("failed to bind product validation queue: %w", err)
-	}
-
-	// Bind queue to user.events exchange with validation response routing key
-	err = channel.QueueBind(
-		queueName,                    // queue name
-		"user.validation.response",   // routing key
-		"user.events",                // exchange
-		false,                        // no-wait
-		nil,                          // arguments
-	)
+	// Consume validation responses from product and user services
+	err := vc.eventSvc.Subscribe(vc.mode.Queue("payment.validation.queue"), []events.Binding{
+		{Exchange: "product.events", RoutingKey: "product.validation.response"},
+		{Exchange: "user.events", RoutingKey: "user.validation.response"},
+	}, vc.processMessage, events.OrderedByDataField("payment_id"))
 	if err != nil {
-		return fmt.Errorf("failed to bind user validation queue: %w", err)
+		return fmt.Errorf("failed to subscribe to validation responses: %w", err)
 	}
 
-	// Set QoS to process one message at a time
-	err = channel.Qos(1, 0, false)
-	if err != nil {
-		return fmt.Errorf("failed to set QoS: %w", err)
-	}
-
-	// Start consuming messages
-	msgs, err := channel.Consume(
-		queueName, // queue
-		"",        // consumer
-		false,     // auto-ack
-		false,     // exclusive
-		false,     // no-local
-		false,     // no-wait
-		nil,       // args
-	)
-	if err != nil {
-		return fmt.Errorf("failed to register consumer: %w", err)
-	}
-
-	log.Println("🚀 Payment-Service validation consumer started")
-
-	// Process messages in a goroutine
-	go func() {
-		for msg := range msgs {
-			vc.processMessage(msg)
-		}
-	}()
+	log.Printf("🚀 Payment-Service validation consumer started (%s)", vc.mode)
 
 	// Start cleanup routine for expired validations
 	go vc.cleanupExpiredValidations()
@@ -131,33 +75,34 @@ func (vc *ValidationConsumer) Start() error {
 }
 
 // processMessage processes a single message
-func (vc *ValidationConsumer) processMessage(msg amqp.Delivery) {
+func (vc *ValidationConsumer) processMessage(msg events.Message) error {
 	log.Printf("📨 Received validation response: %s", msg.RoutingKey)
 
 	// Parse the event
 	var event events.Event
 	if err := json.Unmarshal(msg.Body, &event); err != nil {
 		log.Printf("❌ Failed to unmarshal event: %v", err)
-		msg.Nack(false, false) // Reject message without requeue
-		return
+		return fmt.Errorf("%w: %v", events.ErrReject, err) // Reject message without requeue
 	}
 
+	effects := events.NewEffects("validation", vc.mode, msg)
+	defer effects.Log()
+
 	// Handle different event types
 	switch event.Type {
 	case "product.validation.response":
-		vc.handleProductValidationResponse(event)
+		vc.handleProductValidationResponse(event, effects)
 	case "user.validation.response":
-		vc.handleUserValidationResponse(event)
+		vc.handleUserValidationResponse(event, effects)
 	default:
 		log.Printf("⚠️ Unknown event type: %s", event.Type)
 	}
 
-	// Acknowledge message
-	msg.Ack(false)
+	return nil
 }
 
 // handleProductValidationResponse handles product validation response
-func (vc *ValidationConsumer) handleProductValidationResponse(event events.Event) {
+func (vc *ValidationConsumer) handleProductValidationResponse(event events.Event, effects *events.Effects) {
 	log.Printf("📦 Processing product validation response")
 
 	// Parse validation response
@@ -186,6 +131,18 @@ func (vc *ValidationConsumer) handleProductValidationResponse(event events.Event
 		return
 	}
 
+	if effects.Shadow() {
+		updated := *pending
+		vc.mu.Unlock()
+		before := updated.ProductStatus
+		updated.ProductValidated = true
+		updated.ProductStatus = status
+		updated.ProductMessage = message
+		updated.ProductStock = int(stock)
+		vc.recordValidation(effects, &updated, "product_status", before, status)
+		return
+	}
+
 	pending.ProductValidated = true
 	pending.ProductStatus = status
 	pending.ProductMessage = message
@@ -199,7 +156,7 @@ func (vc *ValidationConsumer) handleProductValidationResponse(event events.Event
 }
 
 // handleUserValidationResponse handles user validation response
-func (vc *ValidationConsumer) handleUserValidationResponse(event events.Event) {
+func (vc *ValidationConsumer) handleUserValidationResponse(event events.Event, effects *events.Effects) {
 	log.Printf("👤 Processing user validation response")
 
 	// Parse validation response
@@ -227,6 +184,17 @@ func (vc *ValidationConsumer) handleUserValidationResponse(event events.Event) {
 		return
 	}
 
+	if effects.Shadow() {
+		updated := *pending
+		vc.mu.Unlock()
+		before := updated.UserStatus
+		updated.UserValidated = true
+		updated.UserStatus = status
+		updated.UserMessage = message
+		vc.recordValidation(effects, &updated, "user_status", before, status)
+		return
+	}
+
 	pending.UserValidated = true
 	pending.UserStatus = status
 	pending.UserMessage = message
@@ -273,6 +241,27 @@ func (vc *ValidationConsumer) checkValidationComplete(paymentID string) {
 	}
 }
 
+// recordValidation records the update of a pending validation by a shadow consumer, and the
+// order event checkValidationComplete would publish once both responses are in
+func (vc *ValidationConsumer) recordValidation(effects *events.Effects, updated *PendingValidation, field, before, after string) {
+	effects.Record(events.Change{
+		Action: "update",
+		Target: "pending_validation/" + updated.PaymentID + "." + field,
+		Before: before,
+		After:  after,
+	})
+	if !updated.ProductValidated || !updated.UserValidated {
+		return
+	}
+
+	outcome := "order.failed"
+	if updated.ProductStatus == "PRODUCT_OK" && updated.UserStatus == "USER_OK" {
+		outcome = "order.completed"
+	}
+	effects.Record(events.Change{Action: "delete", Target: "pending_validation/" + updated.PaymentID})
+	effects.Record(events.Change{Action: "publish", Target: outcome + "/" + updated.PaymentID, After: updated.OrderID})
+}
+
 // handleValidationSuccess handles successful validation
 func (vc *ValidationConsumer) handleValidationSuccess(pending *PendingValidation) {
 	log.Printf("🎉 Validation successful for payment %s, proceeding with payment creation", pending.PaymentID)
@@ -284,6 +273,7 @@ func (vc *ValidationConsumer) handleValidationSuccess(pending *PendingValidation
 	
 	// For now, we'll just publish an order completed event (this would normally happen after Midtrans success)
 	vc.eventSvc.PublishOrderCompleted(
+		context.Background(),
 		pending.PaymentID,
 		pending.OrderID,
 		pending.UserID,
@@ -309,6 +299,7 @@ func (vc *ValidationConsumer) handleValidationFailure(pending *PendingValidation
 	
 	// Publish order failed event
 	vc.eventSvc.PublishOrderFailed(
+		context.Background(),
 		pending.PaymentID,
 		pending.OrderID,
 		pending.UserID,
@@ -328,6 +319,13 @@ func (vc *ValidationConsumer) handleValidationFailure(pending *PendingValidation
 	)
 }
 
+// PendingCount returns the number of validations still waiting for responses
+func (vc *ValidationConsumer) PendingCount() int {
+	vc.mu.RLock()
+	defer vc.mu.RUnlock()
+	return len(vc.pendingValidations)
+}
+
 // AddPendingValidation adds a pending validation to track
 func (vc *ValidationConsumer) AddPendingValidation(paymentID, orderID, userID, productID string, quantity int, amount, totalAmount int64, paymentMethod string) {
 	vc.mu.Lock()
@@ -0,0 +1,112 @@
+package consumers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"payment-service/internal/events"
+	"payment-service/internal/models"
+	"payment-service/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// WebhookConsumer forwards payment events to merchant webhook endpoints
+type WebhookConsumer struct {
+	eventSvc   *events.EventService
+	webhookSvc *services.WebhookService
+	mode       events.ConsumerMode // CONSUMER_MODE_WEBHOOK
+}
+
+// NewWebhookConsumer creates a new webhook consumer
+func NewWebhookConsumer(eventSvc *events.EventService, webhookSvc *services.WebhookService) *WebhookConsumer {
+	return &WebhookConsumer{
+		eventSvc:   eventSvc,
+		webhookSvc: webhookSvc,
+		mode:       events.ConsumerModeFromEnv("webhook"),
+	}
+}
+
+// Start starts consuming payment events that merchants can subscribe to
+func (wc *WebhookConsumer) Start() error {
+	// Consume every payment event merchants can subscribe to
+	var bindings []events.Binding
+	for _, routingKey := range models.WebhookEventTypes {
+		bindings = append(bindings, events.Binding{Exchange: "payment.events", RoutingKey: routingKey})
+	}
+
+	if err := wc.eventSvc.Subscribe(wc.mode.Queue("payment.webhook.queue"), bindings, wc.processMessage, events.OrderedByDataField("payment_id")); err != nil {
+		return fmt.Errorf("failed to subscribe to payment events: %w", err)
+	}
+
+	log.Printf("🚀 Payment-Service webhook consumer started (%s)", wc.mode)
+
+	return nil
+}
+
+// processMessage queues webhook deliveries for a single payment event
+func (wc *WebhookConsumer) processMessage(msg events.Message) error {
+	log.Printf("📨 Received payment event for webhooks: %s", msg.RoutingKey)
+
+	// Parse the event
+	var event events.Event
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Printf("❌ Failed to unmarshal event: %v", err)
+		return fmt.Errorf("%w: %v", events.ErrReject, err) // Reject message without requeue
+	}
+
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		log.Printf("❌ Failed to marshal event data: %v", err)
+		return fmt.Errorf("%w: %v", events.ErrReject, err)
+	}
+
+	paymentID := ""
+	if eventData, ok := event.Data.(map[string]interface{}); ok {
+		paymentID, _ = eventData["payment_id"].(string)
+	}
+
+	occurredAt := time.Now()
+	if event.Timestamp > 0 {
+		occurredAt = time.Unix(event.Timestamp, 0)
+	}
+
+	// Reuse the bus message ID so replayed events keep the same webhook event ID
+	eventID, err := uuid.Parse(msg.ID)
+	if err != nil {
+		eventID = uuid.New()
+	}
+
+	if wc.mode == events.ConsumerModeShadow {
+		return wc.shadowDispatch(msg, eventID, event.Type, paymentID)
+	}
+
+	if err := wc.webhookSvc.Dispatch(eventID, event.Type, paymentID, data, occurredAt); err != nil {
+		log.Printf("❌ Failed to dispatch webhooks for %s: %v", event.Type, err)
+		return err // Requeue, the database may be temporarily unavailable
+	}
+
+	return nil
+}
+
+// shadowDispatch logs the deliveries Dispatch would queue for the event
+func (wc *WebhookConsumer) shadowDispatch(msg events.Message, eventID uuid.UUID, eventType, paymentID string) error {
+	effects := events.NewEffects("webhook", wc.mode, msg)
+	defer effects.Log()
+
+	endpoints, err := wc.webhookSvc.Subscribers(eventType)
+	if err != nil {
+		log.Printf("❌ Failed to get webhook subscribers of %s: %v", eventType, err)
+		return err
+	}
+	for _, endpoint := range endpoints {
+		effects.Record(events.Change{
+			Action: "insert",
+			Target: "webhook_endpoint/" + endpoint.ID.String() + ".deliveries",
+			After:  map[string]string{"event_id": eventID.String(), "event_type": eventType, "payment_id": paymentID},
+		})
+	}
+	return nil
+}
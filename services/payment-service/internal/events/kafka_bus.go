@@ -0,0 +1,349 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Kafka headers carrying the RabbitMQ style message metadata
+const (
+	kafkaHeaderRoutingKey = "routing-key"
+	kafkaHeaderMessageID  = "message-id"
+	kafkaHeaderTopic      = "x-original-topic" // of dead lettered messages
+	kafkaHeaderError      = "x-error"          // of dead lettered messages
+)
+
+// DeadLetterSuffix names the topic a consumer group's failed messages end up in
+const DeadLetterSuffix = ".dead-letter"
+
+// kafkaBus implements Bus on Kafka. Each exchange maps to a topic, the routing key
+// travels in a header and bindings are matched on the consumer side.
+type kafkaBus struct {
+	brokers     []string
+	topicPrefix string
+	writer      *kafka.Writer
+	maxAttempts int // handler calls before a failing message is dead lettered, 0 for no limit
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	readers []*kafka.Reader
+}
+
+// newKafkaBus creates a Kafka writer and makes sure a topic exists for every exchange
+func newKafkaBus(exchanges []string) (*kafkaBus, error) {
+	brokers := kafkaBrokers()
+
+	kb := &kafkaBus{
+		brokers:     brokers,
+		topicPrefix: os.Getenv("KAFKA_TOPIC_PREFIX"),
+		maxAttempts: getEnvInt("KAFKA_MAX_ATTEMPTS", 10),
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+	}
+	kb.ctx, kb.cancel = context.WithCancel(context.Background())
+
+	if err := kb.createTopics(exchanges); err != nil {
+		kb.writer.Close()
+		return nil, err
+	}
+
+	log.Printf("✅ Connected to Kafka successfully (%s)", strings.Join(brokers, ","))
+	return kb, nil
+}
+
+// kafkaBrokers returns the KAFKA_BROKERS addresses
+func kafkaBrokers() []string {
+	brokersEnv := os.Getenv("KAFKA_BROKERS")
+	if brokersEnv == "" {
+		brokersEnv = "localhost:9092"
+	}
+
+	var brokers []string
+	for _, broker := range strings.Split(brokersEnv, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+	return brokers
+}
+
+// pingKafka connects to the first broker and reads the cluster's brokers, creating nothing
+func pingKafka() error {
+	conn, err := kafka.Dial("tcp", kafkaBrokers()[0])
+	if err != nil {
+		return fmt.Errorf("failed to connect to Kafka: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Brokers(); err != nil {
+		return fmt.Errorf("failed to read Kafka brokers: %w", err)
+	}
+	return nil
+}
+
+// createTopics creates the topics backing the exchanges, existing topics are left untouched
+func (kb *kafkaBus) createTopics(exchanges []string) error {
+	conn, err := kafka.Dial("tcp", kb.brokers[0])
+	if err != nil {
+		return fmt.Errorf("failed to connect to Kafka: %w", err)
+	}
+	defer conn.Close()
+
+	controller, err := conn.Controller()
+	if err != nil {
+		return fmt.Errorf("failed to get Kafka controller: %w", err)
+	}
+
+	controllerConn, err := kafka.Dial("tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
+	if err != nil {
+		return fmt.Errorf("failed to connect to Kafka controller: %w", err)
+	}
+	defer controllerConn.Close()
+
+	partitions := getEnvInt("KAFKA_TOPIC_PARTITIONS", 3)
+	replication := getEnvInt("KAFKA_REPLICATION_FACTOR", 1)
+
+	topics := make([]kafka.TopicConfig, 0, len(exchanges))
+	for _, exchange := range exchanges {
+		topics = append(topics, kafka.TopicConfig{
+			Topic:             kb.topic(exchange),
+			NumPartitions:     partitions,
+			ReplicationFactor: replication,
+		})
+	}
+
+	if err := controllerConn.CreateTopics(topics...); err != nil && !errors.Is(err, kafka.TopicAlreadyExists) {
+		return fmt.Errorf("failed to create Kafka topics: %w", err)
+	}
+	return nil
+}
+
+// topic returns the Kafka topic for an exchange
+func (kb *kafkaBus) topic(exchange string) string {
+	return kb.topicPrefix + exchange
+}
+
+// Publish writes a message to the exchange topic, keyed by its aggregate so the messages of
+// one aggregate share a partition and keep their order. Messages without one are keyed by ID.
+func (kb *kafkaBus) Publish(ctx context.Context, msg Message) error {
+	headers := []kafka.Header{
+		{Key: kafkaHeaderRoutingKey, Value: []byte(msg.RoutingKey)},
+		{Key: kafkaHeaderMessageID, Value: []byte(msg.ID)},
+	}
+	for key, value := range msg.Headers {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(fmt.Sprint(value))})
+	}
+
+	// Bounded by the caller's deadline, 10s at most, and abandoned when the bus closes
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	stop := context.AfterFunc(kb.ctx, cancel)
+	defer stop()
+
+	key := msg.Key
+	if key == "" {
+		key = msg.ID
+	}
+
+	return kb.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   kb.topic(msg.Exchange),
+		Key:     []byte(key),
+		Value:   msg.Body,
+		Headers: headers,
+		Time:    msg.Timestamp,
+	})
+}
+
+// Subscribe joins the consumer group named after the queue and reads every bound topic,
+// with one reader per unit of concurrency sharing the group's partitions. Messages whose
+// routing key matches no binding are committed and skipped. A failed message is retried
+// with backoff before the partition moves on, preserving order, and after KAFKA_MAX_ATTEMPTS
+// failed attempts moved to the <queue>.dead-letter topic.
+func (kb *kafkaBus) Subscribe(queue string, bindings []Binding, handler Handler, opts ...SubscribeOption) error {
+	cfg := newSubscribeConfig(queue, opts)
+
+	topicSet := make(map[string]bool)
+	var topics []string
+	for _, binding := range bindings {
+		topic := kb.topic(binding.Exchange)
+		if !topicSet[topic] {
+			topicSet[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+
+	for i := 0; i < cfg.concurrency; i++ {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:     kb.brokers,
+			GroupID:     kb.topicPrefix + queue,
+			GroupTopics: topics,
+			MaxWait:     time.Second,
+		})
+
+		kb.mu.Lock()
+		kb.readers = append(kb.readers, reader)
+		kb.mu.Unlock()
+
+		go kb.read(reader, queue, bindings, handler)
+	}
+
+	return nil
+}
+
+// read processes the messages of the partitions assigned to reader until the bus closes
+func (kb *kafkaBus) read(reader *kafka.Reader, queue string, bindings []Binding, handler Handler) {
+	for {
+		km, err := reader.FetchMessage(kb.ctx)
+		if err != nil {
+			if kb.ctx.Err() != nil {
+				return
+			}
+			log.Printf("❌ Kafka fetch failed for %s: %v", queue, err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		msg := Message{
+			Exchange:  strings.TrimPrefix(km.Topic, kb.topicPrefix),
+			Key:       string(km.Key),
+			Body:      km.Value,
+			Headers:   make(map[string]interface{}),
+			Timestamp: km.Time,
+		}
+		for _, header := range km.Headers {
+			switch header.Key {
+			case kafkaHeaderRoutingKey:
+				msg.RoutingKey = string(header.Value)
+			case kafkaHeaderMessageID:
+				msg.ID = string(header.Value)
+			default:
+				msg.Headers[header.Key] = string(header.Value)
+			}
+		}
+
+		if bindingsMatch(bindings, msg) {
+			kb.handle(queue, handler, msg)
+		}
+
+		if err := reader.CommitMessages(kb.ctx, km); err != nil && kb.ctx.Err() == nil {
+			log.Printf("❌ Kafka commit failed for %s: %v", queue, err)
+		}
+	}
+}
+
+// handle runs the handler until it succeeds or rejects the message. A message still failing
+// after maxAttempts is dead lettered, it is retried on when that fails too.
+func (kb *kafkaBus) handle(queue string, handler Handler, msg Message) {
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		err := handler(msg)
+		if err == nil || errors.Is(err, ErrReject) {
+			return
+		}
+
+		if kb.maxAttempts > 0 && attempt >= kb.maxAttempts {
+			dlqErr := kb.deadLetter(queue, msg, err)
+			if dlqErr == nil {
+				log.Printf("☠️ Handler for %s failed %d times on %s, moved message %s to %s%s: %v", queue, attempt, msg.RoutingKey, msg.ID, queue, DeadLetterSuffix, err)
+				return
+			}
+			log.Printf("❌ Failed to dead letter message %s of %s: %v", msg.ID, queue, dlqErr)
+		}
+
+		log.Printf("⚠️ Handler for %s failed on %s, retrying in %s: %v", queue, msg.RoutingKey, backoff, err)
+		select {
+		case <-kb.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// deadLetter writes a failed message to the queue's dead letter topic with the topic it came
+// from and the handler error, keeping its key, body and headers
+func (kb *kafkaBus) deadLetter(queue string, msg Message, handlerErr error) error {
+	headers := []kafka.Header{
+		{Key: kafkaHeaderRoutingKey, Value: []byte(msg.RoutingKey)},
+		{Key: kafkaHeaderMessageID, Value: []byte(msg.ID)},
+		{Key: kafkaHeaderTopic, Value: []byte(kb.topic(msg.Exchange))},
+		{Key: kafkaHeaderError, Value: []byte(handlerErr.Error())},
+	}
+	for key, value := range msg.Headers {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(fmt.Sprint(value))})
+	}
+
+	ctx, cancel := context.WithTimeout(kb.ctx, 10*time.Second)
+	defer cancel()
+
+	return kb.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   kb.topicPrefix + queue + DeadLetterSuffix,
+		Key:     []byte(msg.Key),
+		Value:   msg.Body,
+		Headers: headers,
+		Time:    msg.Timestamp,
+	})
+}
+
+// bindingsMatch reports whether any binding routes the message
+func bindingsMatch(bindings []Binding, msg Message) bool {
+	for _, binding := range bindings {
+		if binding.Exchange == msg.Exchange && matchRoutingKey(binding.RoutingKey, msg.RoutingKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsConnected reports whether a Kafka broker is reachable
+func (kb *kafkaBus) IsConnected() bool {
+	return kb.ctx.Err() == nil && kb.HealthCheck() == nil
+}
+
+// HealthCheck checks that a broker answers a metadata request
+func (kb *kafkaBus) HealthCheck() error {
+	ctx, cancel := context.WithTimeout(kb.ctx, 3*time.Second)
+	defer cancel()
+
+	conn, err := kafka.DialContext(ctx, "tcp", kb.brokers[0])
+	if err != nil {
+		return fmt.Errorf("Kafka health check failed: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Brokers(); err != nil {
+		return fmt.Errorf("Kafka health check failed: %w", err)
+	}
+	return nil
+}
+
+// Close stops the consumers and flushes the writer
+func (kb *kafkaBus) Close() error {
+	kb.cancel()
+
+	kb.mu.Lock()
+	for _, reader := range kb.readers {
+		reader.Close()
+	}
+	kb.mu.Unlock()
+
+	return kb.writer.Close()
+}
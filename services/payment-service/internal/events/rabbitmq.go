@@ -1,21 +1,28 @@
 package events
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
+	"payment-service/internal/chaos"
+	"payment-service/internal/models"
+
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
-	"github.com/streadway/amqp"
 )
 
-// EventService handles RabbitMQ event publishing
+// EventService handles event publishing and consuming on the configured bus
 type EventService struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
+	bus      Bus
+	eventLog EventLogStore
+}
+
+// EventLogStore persists published events so they can be replayed later
+type EventLogStore interface {
+	Create(ctx context.Context, eventLog *models.EventLog) error
 }
 
 // Event represents a generic event structure
@@ -62,6 +69,30 @@ type PaymentSuccessEvent struct {
 	TotalAmount   int64  `json:"total_amount"`
 	PaymentMethod string `json:"payment_method"`
 	PaidAt        string `json:"paid_at"`
+	OrderSummary
+}
+
+// OrderSummary is the order detail carried by payment.success for receipt and sale emails
+type OrderSummary struct {
+	ProductName string `json:"product_name,omitempty"`
+	SellerID    string `json:"seller_id,omitempty"` // Product owner
+	Quantity    int    `json:"quantity"`
+	Currency    string `json:"currency"`
+	Bank        string `json:"bank,omitempty"`      // Bank or convenience store
+	VANumber    string `json:"va_number,omitempty"` // Masked, last four digits only
+}
+
+// InvoiceReminderEvent asks for the email reminding the buyer of an unpaid invoice, before its
+// due date or once it is overdue
+type InvoiceReminderEvent struct {
+	PaymentID   string `json:"payment_id"`
+	OrderID     string `json:"order_id"`
+	UserID      string `json:"user_id"`
+	ProductName string `json:"product_name,omitempty"`
+	TotalAmount int64  `json:"total_amount"`
+	Currency    string `json:"currency"`
+	DueDate     string `json:"due_date"` // RFC3339
+	Overdue     bool   `json:"overdue"`
 }
 
 // PaymentFailedEvent represents failed payment event
@@ -76,9 +107,22 @@ type PaymentFailedEvent struct {
 	FailureReason string `json:"failure_reason"`
 }
 
+// PaymentRefundedEvent represents refunded payment event
+type PaymentRefundedEvent struct {
+	PaymentID     string `json:"payment_id"`
+	OrderID       string `json:"order_id"`
+	UserID        string `json:"user_id"`
+	ProductID     string `json:"product_id,omitempty"`
+	Amount        int64  `json:"amount"`
+	TotalAmount   int64  `json:"total_amount"`
+	PaymentMethod string `json:"payment_method"`
+	RefundedAt    string `json:"refunded_at"`
+}
+
 // StockReductionEvent represents stock reduction event for successful payments
 type StockReductionEvent struct {
 	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"` // Empty for products without variants
 	Quantity  int    `json:"quantity"`
 	OrderID   string `json:"order_id"`
 	UserID    string `json:"user_id"`
@@ -141,6 +185,20 @@ type OrderFailedEvent struct {
 	FailureReason string `json:"failure_reason"`
 }
 
+// EventPublisher publishes payment events consumed by other services
+type EventPublisher interface {
+	PublishPaymentCreated(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod, status string) error
+	PublishPaymentStatusUpdated(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, oldStatus, newStatus string, amount, totalAmount int64, paymentMethod string, paidAt *time.Time) error
+	PublishPaymentSuccess(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod string, paidAt time.Time, summary OrderSummary) error
+	PublishPaymentFailed(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod, failureReason string) error
+	PublishPaymentRefunded(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod string, refundedAt time.Time) error
+	PublishStockReduction(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID, quantity int, orderID, userID string) error
+	PublishInvoiceReminder(ctx context.Context, reminder InvoiceReminderEvent) error
+}
+
+// Ensure EventService implements EventPublisher
+var _ EventPublisher = (*EventService)(nil)
+
 // NewEventService creates a new event service
 func NewEventService() (*EventService, error) {
 	// Load .env file
@@ -148,72 +206,19 @@ func NewEventService() (*EventService, error) {
 		log.Println("⚠️ .env file not found in events package, using system env")
 	}
 
-	// Get RabbitMQ configuration from environment
-	host := os.Getenv("RABBITMQ_HOST")
-	if host == "" {
-		host = "localhost"
-	}
-
-	port := os.Getenv("RABBITMQ_PORT")
-	if port == "" {
-		port = "5672"
-	}
-
-	username := os.Getenv("RABBITMQ_USERNAME")
-	if username == "" {
-		username = "guest"
-	}
-
-	password := os.Getenv("RABBITMQ_PASSWORD")
-	if password == "" {
-		password = "guest"
-	}
-
-	// Create connection URL
-	url := fmt.Sprintf("amqp://%s:%s@%s:%s/", username, password, host, port)
-
-	// Connect to RabbitMQ
-	conn, err := amqp.Dial(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
-	}
-
-	// Create channel
-	ch, err := conn.Channel()
+	// Connect to the configured transport and declare exchanges
+	bus, err := NewBus([]string{"payment.events", "product.events", "user.events", "notification.events"})
 	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to open channel: %w", err)
+		return nil, err
 	}
 
-	// Declare exchanges
-	exchanges := []string{"payment.events", "product.events", "notification.events"}
-	for _, exchange := range exchanges {
-		if err := ch.ExchangeDeclare(
-			exchange, // name
-			"topic",  // type
-			true,     // durable
-			false,    // auto-deleted
-			false,    // internal
-			false,    // no-wait
-			nil,      // arguments
-		); err != nil {
-			ch.Close()
-			conn.Close()
-			return nil, fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
-		}
-	}
-
-
-	log.Println("✅ Connected to RabbitMQ successfully")
-
 	return &EventService{
-		conn:    conn,
-		channel: ch,
+		bus: bus,
 	}, nil
 }
 
 // PublishPaymentCreated publishes payment creation event
-func (es *EventService) PublishPaymentCreated(paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod, status string) error {
+func (es *EventService) PublishPaymentCreated(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod, status string) error {
 	productIDStr := ""
 	if productID != nil {
 		productIDStr = productID.String()
@@ -235,11 +240,11 @@ func (es *EventService) PublishPaymentCreated(paymentID, orderID, userID string,
 		Timestamp: time.Now().Unix(),
 	}
 
-	return es.publishEvent("payment.events", "payment.created", event)
+	return es.publishEvent(ctx, "payment.events", "payment.created", event)
 }
 
 // PublishPaymentStatusUpdated publishes payment status update event
-func (es *EventService) PublishPaymentStatusUpdated(paymentID, orderID, userID string, productID *uuid.UUID, oldStatus, newStatus string, amount, totalAmount int64, paymentMethod string, paidAt *time.Time) error {
+func (es *EventService) PublishPaymentStatusUpdated(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, oldStatus, newStatus string, amount, totalAmount int64, paymentMethod string, paidAt *time.Time) error {
 	productIDStr := ""
 	if productID != nil {
 		productIDStr = productID.String()
@@ -268,11 +273,11 @@ func (es *EventService) PublishPaymentStatusUpdated(paymentID, orderID, userID s
 		Timestamp: time.Now().Unix(),
 	}
 
-	return es.publishEvent("payment.events", "payment.status.updated", event)
+	return es.publishEvent(ctx, "payment.events", "payment.status.updated", event)
 }
 
 // PublishPaymentSuccess publishes successful payment event
-func (es *EventService) PublishPaymentSuccess(paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod string, paidAt time.Time) error {
+func (es *EventService) PublishPaymentSuccess(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod string, paidAt time.Time, summary OrderSummary) error {
 	productIDStr := ""
 	if productID != nil {
 		productIDStr = productID.String()
@@ -290,15 +295,16 @@ func (es *EventService) PublishPaymentSuccess(paymentID, orderID, userID string,
 			TotalAmount:   totalAmount,
 			PaymentMethod: paymentMethod,
 			PaidAt:        paidAt.Format(time.RFC3339),
+			OrderSummary:  summary,
 		},
 		Timestamp: time.Now().Unix(),
 	}
 
-	return es.publishEvent("payment.events", "payment.success", event)
+	return es.publishEvent(ctx, "payment.events", "payment.success", event)
 }
 
 // PublishPaymentFailed publishes failed payment event
-func (es *EventService) PublishPaymentFailed(paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod, failureReason string) error {
+func (es *EventService) PublishPaymentFailed(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod, failureReason string) error {
 	productIDStr := ""
 	if productID != nil {
 		productIDStr = productID.String()
@@ -320,28 +326,71 @@ func (es *EventService) PublishPaymentFailed(paymentID, orderID, userID string,
 		Timestamp: time.Now().Unix(),
 	}
 
-	return es.publishEvent("payment.events", "payment.failed", event)
+	return es.publishEvent(ctx, "payment.events", "payment.failed", event)
 }
 
-// PublishStockReduction publishes stock reduction event
-func (es *EventService) PublishStockReduction(productID uuid.UUID, quantity int, orderID, userID string) error {
+// PublishPaymentRefunded publishes refunded payment event
+func (es *EventService) PublishPaymentRefunded(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod string, refundedAt time.Time) error {
+	productIDStr := ""
+	if productID != nil {
+		productIDStr = productID.String()
+	}
+
 	event := Event{
-		Type:   "product.stock.reduced",
+		Type:   "payment.refunded",
 		UserID: userID,
-		Data: StockReductionEvent{
-			ProductID: productID.String(),
-			Quantity:  quantity,
-			OrderID:   orderID,
-			UserID:    userID,
+		Data: PaymentRefundedEvent{
+			PaymentID:     paymentID,
+			OrderID:       orderID,
+			UserID:        userID,
+			ProductID:     productIDStr,
+			Amount:        amount,
+			TotalAmount:   totalAmount,
+			PaymentMethod: paymentMethod,
+			RefundedAt:    refundedAt.Format(time.RFC3339),
 		},
 		Timestamp: time.Now().Unix(),
 	}
 
-	return es.publishEvent("product.events", "product.stock.reduced", event)
+	return es.publishEvent(ctx, "payment.events", "payment.refunded", event)
+}
+
+// PublishStockReduction publishes stock reduction event, of the variant when one was bought
+func (es *EventService) PublishStockReduction(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID, quantity int, orderID, userID string) error {
+	data := StockReductionEvent{
+		ProductID: productID.String(),
+		Quantity:  quantity,
+		OrderID:   orderID,
+		UserID:    userID,
+	}
+	if variantID != nil {
+		data.VariantID = variantID.String()
+	}
+
+	event := Event{
+		Type:      "product.stock.reduced",
+		UserID:    userID,
+		Data:      data,
+		Timestamp: time.Now().Unix(),
+	}
+
+	return es.publishEvent(ctx, "product.events", "product.stock.reduced", event)
+}
+
+// PublishInvoiceReminder publishes an invoice reminder event for the reminder email
+func (es *EventService) PublishInvoiceReminder(ctx context.Context, reminder InvoiceReminderEvent) error {
+	event := Event{
+		Type:      "payment.invoice.reminder",
+		UserID:    reminder.UserID,
+		Data:      reminder,
+		Timestamp: time.Now().Unix(),
+	}
+
+	return es.publishEvent(ctx, "payment.events", "payment.invoice.reminder", event)
 }
 
 // PublishCheckoutInit publishes checkout initialization event
-func (es *EventService) PublishCheckoutInit(paymentID, orderID, userID string, productID *uuid.UUID, quantity int, amount, totalAmount int64, paymentMethod string) error {
+func (es *EventService) PublishCheckoutInit(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, quantity int, amount, totalAmount int64, paymentMethod string) error {
 	productIDStr := ""
 	if productID != nil {
 		productIDStr = productID.String()
@@ -363,11 +412,11 @@ func (es *EventService) PublishCheckoutInit(paymentID, orderID, userID string, p
 		Timestamp: time.Now().Unix(),
 	}
 
-	return es.publishEvent("payment.events", "checkout.init", event)
+	return es.publishEvent(ctx, "payment.events", "checkout.init", event)
 }
 
 // PublishOrderCompleted publishes order completion event
-func (es *EventService) PublishOrderCompleted(paymentID, orderID, userID string, productID *uuid.UUID, quantity int, amount, totalAmount int64, paymentMethod string, paidAt time.Time) error {
+func (es *EventService) PublishOrderCompleted(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, quantity int, amount, totalAmount int64, paymentMethod string, paidAt time.Time) error {
 	productIDStr := ""
 	if productID != nil {
 		productIDStr = productID.String()
@@ -390,11 +439,11 @@ func (es *EventService) PublishOrderCompleted(paymentID, orderID, userID string,
 		Timestamp: time.Now().Unix(),
 	}
 
-	return es.publishEvent("payment.events", "order.completed", event)
+	return es.publishEvent(ctx, "payment.events", "order.completed", event)
 }
 
 // PublishOrderFailed publishes order failure event
-func (es *EventService) PublishOrderFailed(paymentID, orderID, userID string, productID *uuid.UUID, quantity int, amount, totalAmount int64, paymentMethod, failureReason string) error {
+func (es *EventService) PublishOrderFailed(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, quantity int, amount, totalAmount int64, paymentMethod, failureReason string) error {
 	productIDStr := ""
 	if productID != nil {
 		productIDStr = productID.String()
@@ -417,76 +466,140 @@ func (es *EventService) PublishOrderFailed(paymentID, orderID, userID string, pr
 		Timestamp: time.Now().Unix(),
 	}
 
-	return es.publishEvent("payment.events", "order.failed", event)
+	return es.publishEvent(ctx, "payment.events", "order.failed", event)
+}
+
+// SetEventLog enables persisting every published event to the given store
+func (es *EventService) SetEventLog(store EventLogStore) {
+	es.eventLog = store
+}
+
+// aggregateKeyField is the event data field payment events are keyed by on the bus, the
+// events of one order stay in order on Kafka
+const aggregateKeyField = "order_id"
+
+// exchangeKeyFields key the events of another service's exchange like that service does:
+// product.events by product, so stock reductions share the product's partition with
+// product-service's own events
+var exchangeKeyFields = map[string]string{
+	"product.events": "product_id",
+}
+
+// aggregateKey returns the bus key of an event published to exchange
+func aggregateKey(exchange string, body []byte) string {
+	if field, ok := exchangeKeyFields[exchange]; ok {
+		return dataField(body, field)
+	}
+	return dataField(body, aggregateKeyField)
 }
 
 // publishEvent publishes a generic event
-func (es *EventService) publishEvent(exchange, routingKey string, event Event) error {
+func (es *EventService) publishEvent(ctx context.Context, exchange, routingKey string, event Event) error {
 	// Marshal event to JSON
 	body, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
+	eventID := uuid.New()
+	publishedAt := time.Now()
+
 	// Publish message
-	err = es.channel.Publish(
-		exchange,   // exchange
-		routingKey, // routing key
-		false,      // mandatory
-		false,      // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-			Timestamp:   time.Now(),
-		},
-	)
+	err = es.bus.Publish(ctx, Message{
+		ID:         eventID.String(),
+		Exchange:   exchange,
+		RoutingKey: routingKey,
+		Key:        aggregateKey(exchange, body),
+		Body:       body,
+		Timestamp:  publishedAt,
+	})
 
 	if err != nil {
 		return fmt.Errorf("failed to publish event: %w", err)
 	}
 
 	log.Printf("📤 Published event: %s to %s", routingKey, exchange)
+
+	// Keep a copy for replay, a failure here must not fail the publish
+	if es.eventLog != nil {
+		if err := es.eventLog.Create(ctx, &models.EventLog{
+			ID:          eventID,
+			Exchange:    exchange,
+			RoutingKey:  routingKey,
+			EventType:   event.Type,
+			Payload:     string(body),
+			PublishedAt: publishedAt,
+		}); err != nil {
+			log.Printf("⚠️ Failed to log event %s: %v", eventID, err)
+		}
+	}
+
 	return nil
 }
 
-// Close closes the RabbitMQ connection
-func (es *EventService) Close() error {
-	if es.channel != nil {
-		es.channel.Close()
-	}
-	if es.conn != nil {
-		return es.conn.Close()
+// Republish publishes a logged event again with its original message ID and payload.
+// Replayed messages carry the x-replayed header so consumers can tell them apart.
+func (es *EventService) Republish(ctx context.Context, eventLog *models.EventLog) error {
+	err := es.bus.Publish(ctx, Message{
+		ID:         eventLog.ID.String(),
+		Exchange:   eventLog.Exchange,
+		RoutingKey: eventLog.RoutingKey,
+		Key:        aggregateKey(eventLog.Exchange, []byte(eventLog.Payload)),
+		Body:       []byte(eventLog.Payload),
+		Timestamp:  eventLog.PublishedAt,
+		Headers: map[string]interface{}{
+			"x-replayed": true,
+		},
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to republish event %s: %w", eventLog.ID, err)
 	}
+
+	log.Printf("🔁 Replayed event: %s (%s) to %s", eventLog.RoutingKey, eventLog.ID, eventLog.Exchange)
 	return nil
 }
 
-// GetChannel returns the RabbitMQ channel for consumers
-func (es *EventService) GetChannel() *amqp.Channel {
-	return es.channel
+// Subscribe consumes the given bindings from a durable queue (a consumer group on Kafka),
+// warning when they drifted from Topology. The queue's consumer faults apply while fault
+// injection is enabled.
+func (es *EventService) Subscribe(queue string, bindings []Binding, handler Handler, opts ...SubscribeOption) error {
+	for _, drift := range Topology.CheckSubscription(queue, bindings) {
+		log.Printf("⚠️ Topology drift: %s", drift)
+	}
+	if chaos.Enabled() {
+		handler = withFaults(queue, handler)
+	}
+	return es.bus.Subscribe(queue, bindings, handler, opts...)
 }
 
-// HealthCheck checks if RabbitMQ connection is healthy
-func (es *EventService) HealthCheck() error {
-	if es.conn == nil || es.channel == nil {
-		return fmt.Errorf("RabbitMQ connection not initialized")
+// withFaults applies the consumer faults targeting queue: error faults fail the message so
+// it is redelivered, drop faults reject it as if it was lost
+func withFaults(queue string, handler Handler) Handler {
+	return func(msg Message) error {
+		fault, ok := chaos.Inject(context.Background(), chaos.ScopeConsumer, queue)
+		if !ok {
+			return handler(msg)
+		}
+		if fault.Type == chaos.TypeDrop {
+			log.Printf("💥 Chaos fault %s dropped %s message %s", fault.ID, queue, msg.ID)
+			return fmt.Errorf("%w: chaos fault %s", ErrReject, fault.ID)
+		}
+		return fmt.Errorf("chaos fault %s failed the message", fault.ID)
 	}
+}
 
-	// Try to declare a temporary queue to test connection
-	_, err := es.channel.QueueDeclare(
-		"health_check", // name
-		false,          // durable
-		true,           // delete when unused
-		true,           // exclusive
-		false,          // no-wait
-		nil,            // arguments
-	)
-
-	if err != nil {
-		return fmt.Errorf("RabbitMQ health check failed: %w", err)
-	}
+// Close closes the bus connection
+func (es *EventService) Close() error {
+	return es.bus.Close()
+}
 
-	// Clean up the temporary queue
-	es.channel.QueueDelete("health_check", false, false, false)
+// IsConnected reports whether the bus connection is open
+func (es *EventService) IsConnected() bool {
+	return es.bus.IsConnected()
+}
 
-	return nil
+// HealthCheck checks if the bus connection is healthy
+func (es *EventService) HealthCheck() error {
+	return es.bus.HealthCheck()
 }
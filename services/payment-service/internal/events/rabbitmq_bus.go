@@ -0,0 +1,255 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/streadway/amqp"
+)
+
+// rabbitMQBus implements Bus on RabbitMQ topic exchanges
+type rabbitMQBus struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+}
+
+// newRabbitMQBus connects to RabbitMQ and declares the topic exchanges
+func newRabbitMQBus(exchanges []string) (*rabbitMQBus, error) {
+	// Connect to RabbitMQ
+	conn, err := amqp.Dial(rabbitMQURL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	// Create channel
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	// Declare exchanges
+	for _, exchange := range exchanges {
+		if err := ch.ExchangeDeclare(
+			exchange, // name
+			"topic",  // type
+			true,     // durable
+			false,    // auto-deleted
+			false,    // internal
+			false,    // no-wait
+			nil,      // arguments
+		); err != nil {
+			ch.Close()
+			conn.Close()
+			return nil, fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
+		}
+	}
+
+	// Declare the queues of every service, so nothing published is lost before they start
+	if err := declareTopology(ch); err != nil {
+		ch.Close()
+		conn.Close()
+		return nil, err
+	}
+
+	log.Println("✅ Connected to RabbitMQ successfully")
+
+	return &rabbitMQBus{
+		conn:    conn,
+		channel: ch,
+	}, nil
+}
+
+// rabbitMQURL builds the broker URL from the RABBITMQ_* settings
+func rabbitMQURL() string {
+	// Get RabbitMQ configuration from environment
+	host := os.Getenv("RABBITMQ_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+
+	port := os.Getenv("RABBITMQ_PORT")
+	if port == "" {
+		port = "5672"
+	}
+
+	username := os.Getenv("RABBITMQ_USERNAME")
+	if username == "" {
+		username = "guest"
+	}
+
+	password := os.Getenv("RABBITMQ_PASSWORD")
+	if password == "" {
+		password = "guest"
+	}
+	return fmt.Sprintf("amqp://%s:%s@%s:%s/", username, password, host, port)
+}
+
+// pingRabbitMQ connects to the broker and disconnects again, declaring nothing
+func pingRabbitMQ() error {
+	conn, err := amqp.Dial(rabbitMQURL())
+	if err != nil {
+		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+	return conn.Close()
+}
+
+// Publish publishes a message to its exchange with its routing key. The AMQP client has no
+// context support, so ctx is only checked before the message is handed to the channel.
+func (rb *rabbitMQBus) Publish(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return rb.channel.Publish(
+		msg.Exchange,   // exchange
+		msg.RoutingKey, // routing key
+		false,          // mandatory
+		false,          // immediate
+		amqp.Publishing{
+			ContentType: "application/json",
+			MessageId:   msg.ID,
+			Body:        msg.Body,
+			Timestamp:   msg.Timestamp,
+			Headers:     amqp.Table(msg.Headers),
+		},
+	)
+}
+
+// Subscribe declares a durable queue (auto-deleted for shadow consumers), binds it and
+// consumes it on a dedicated channel
+func (rb *rabbitMQBus) Subscribe(queue string, bindings []Binding, handler Handler, opts ...SubscribeOption) error {
+	cfg := newSubscribeConfig(queue, opts)
+
+	// A channel per consumer keeps QoS settings independent
+	ch, err := rb.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	// Shadow queues only matter while a shadow consumer reads them
+	shadow := strings.HasSuffix(queue, ShadowQueueSuffix)
+	_, err = ch.QueueDeclare(
+		queue,   // name
+		!shadow, // durable
+		shadow,  // delete when unused
+		false,   // exclusive
+		false,   // no-wait
+		nil,     // arguments
+	)
+	if err != nil {
+		ch.Close()
+		return fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	for _, binding := range bindings {
+		err = ch.QueueBind(
+			queue,              // queue name
+			binding.RoutingKey, // routing key
+			binding.Exchange,   // exchange
+			false,              // no-wait
+			nil,                // arguments
+		)
+		if err != nil {
+			ch.Close()
+			return fmt.Errorf("failed to bind queue to %s on %s: %w", binding.RoutingKey, binding.Exchange, err)
+		}
+	}
+
+	// Prefetch bounds the unacknowledged deliveries in flight, one by default
+	if err := ch.Qos(cfg.prefetch, 0, false); err != nil {
+		ch.Close()
+		return fmt.Errorf("failed to set QoS: %w", err)
+	}
+
+	msgs, err := ch.Consume(
+		queue, // queue
+		"",    // consumer
+		false, // auto-ack
+		false, // exclusive
+		false, // no-local
+		false, // no-wait
+		nil,   // args
+	)
+	if err != nil {
+		ch.Close()
+		return fmt.Errorf("failed to register consumer: %w", err)
+	}
+
+	// Deliveries are handled by cfg.concurrency workers, acknowledged by the worker handling
+	// them; the channel stops delivering once cfg.prefetch of them are unacknowledged
+	go func() {
+		workers := newDispatcher(cfg.concurrency, cfg.orderKey)
+		defer workers.close()
+
+		for delivery := range msgs {
+			msg := Message{
+				ID:         delivery.MessageId,
+				Exchange:   delivery.Exchange,
+				RoutingKey: delivery.RoutingKey,
+				Body:       delivery.Body,
+				Headers:    delivery.Headers,
+				Timestamp:  delivery.Timestamp,
+			}
+
+			workers.dispatch(msg, func() {
+				err := handler(msg)
+				switch {
+				case err == nil:
+					delivery.Ack(false)
+				case errors.Is(err, ErrReject):
+					delivery.Nack(false, false) // Reject message without requeue
+				default:
+					delivery.Nack(false, true) // Requeue for another attempt
+				}
+			})
+		}
+	}()
+
+	return nil
+}
+
+// IsConnected reports whether the RabbitMQ connection is open
+func (rb *rabbitMQBus) IsConnected() bool {
+	return rb.conn != nil && !rb.conn.IsClosed()
+}
+
+// HealthCheck checks if RabbitMQ connection is healthy
+func (rb *rabbitMQBus) HealthCheck() error {
+	if rb.conn == nil || rb.channel == nil {
+		return fmt.Errorf("RabbitMQ connection not initialized")
+	}
+
+	// Try to declare a temporary queue to test connection
+	_, err := rb.channel.QueueDeclare(
+		"health_check", // name
+		false,          // durable
+		true,           // delete when unused
+		true,           // exclusive
+		false,          // no-wait
+		nil,            // arguments
+	)
+
+	if err != nil {
+		return fmt.Errorf("RabbitMQ health check failed: %w", err)
+	}
+
+	// Clean up the temporary queue
+	rb.channel.QueueDelete("health_check", false, false, false)
+
+	return nil
+}
+
+// Close closes the RabbitMQ connection
+func (rb *rabbitMQBus) Close() error {
+	if rb.channel != nil {
+		rb.channel.Close()
+	}
+	if rb.conn != nil {
+		return rb.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package events
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+)
+
+// ConsumerMode selects whether a consumer applies the messages it consumes
+type ConsumerMode string
+
+// Consumer modes, set with CONSUMER_MODE and per consumer with CONSUMER_MODE_<NAME>
+const (
+	// ConsumerModeActive applies every message (the default)
+	ConsumerModeActive ConsumerMode = "active"
+	// ConsumerModeShadow consumes a copy of the messages, computes what they would change
+	// and logs it without writing, publishing or sending anything
+	ConsumerModeShadow ConsumerMode = "shadow"
+)
+
+// ShadowQueueSuffix names the queue of shadow consumers after the active one
+const ShadowQueueSuffix = ".shadow"
+
+// ConsumerModeFromEnv returns the mode of the named consumer: CONSUMER_MODE_<NAME> (e.g.
+// CONSUMER_MODE_PRODUCT_CACHE), then CONSUMER_MODE, then active
+func ConsumerModeFromEnv(name string) ConsumerMode {
+	key := "CONSUMER_MODE_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+	for _, key := range []string{key, "CONSUMER_MODE"} {
+		value := os.Getenv(key)
+		switch ConsumerMode(strings.ToLower(value)) {
+		case "":
+			continue
+		case ConsumerModeActive:
+			return ConsumerModeActive
+		case ConsumerModeShadow:
+			return ConsumerModeShadow
+		default:
+			log.Printf("⚠️ Ignoring invalid %s=%q, expected active or shadow", key, value)
+		}
+	}
+	return ConsumerModeActive
+}
+
+// Queue returns the queue a consumer in this mode reads. Shadow consumers get a queue of
+// their own bound to the same routing keys, so they see every message without taking any
+// from the active consumers.
+func (m ConsumerMode) Queue(queue string) string {
+	if m == ConsumerModeShadow {
+		return queue + ShadowQueueSuffix
+	}
+	return queue
+}
+
+// Change is one effect of a message: a row written, an event published, a cache dropped
+type Change struct {
+	Action string      `json:"action"` // e.g. update, insert, publish
+	Target string      `json:"target"` // what changes, e.g. payment/<id>.status
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Effects applies the changes of one consumed message. In shadow mode changes are recorded
+// instead of applied, and Log writes them as one JSON line to compare with what the active
+// consumer did.
+type Effects struct {
+	consumer string
+	mode     ConsumerMode
+	msg      Message
+	changes  []Change
+}
+
+// NewEffects collects the changes the named consumer makes for msg
+func NewEffects(consumer string, mode ConsumerMode, msg Message) *Effects {
+	return &Effects{consumer: consumer, mode: mode, msg: msg}
+}
+
+// Shadow reports whether changes are only recorded
+func (e *Effects) Shadow() bool {
+	return e.mode == ConsumerModeShadow
+}
+
+// Record notes a change that was computed without applying it, e.g. by a rolled back
+// transaction
+func (e *Effects) Record(change Change) {
+	e.changes = append(e.changes, change)
+}
+
+// Apply records change and runs apply to make it, unless in shadow mode
+func (e *Effects) Apply(change Change, apply func() error) error {
+	e.Record(change)
+	if e.Shadow() {
+		return nil
+	}
+	return apply()
+}
+
+// Log writes the changes of a shadow run, active runs log nothing
+func (e *Effects) Log() {
+	if !e.Shadow() {
+		return
+	}
+	changes := e.changes
+	if changes == nil {
+		changes = []Change{}
+	}
+	line, err := json.Marshal(map[string]interface{}{
+		"shadow":      true,
+		"consumer":    e.consumer,
+		"message_id":  e.msg.ID,
+		"routing_key": e.msg.RoutingKey,
+		"changes":     changes,
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to log shadow changes of %s: %v", e.consumer, err)
+		return
+	}
+	log.Printf("👥 %s", line)
+}
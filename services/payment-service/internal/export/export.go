@@ -0,0 +1,53 @@
+// Package export writes tabular reports (CSV or XLSX) row by row so large
+// histories can be streamed to the client without buffering them in memory.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Supported formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// RowWriter writes a header followed by any number of rows
+type RowWriter interface {
+	WriteRow(cells []string) error
+	// Flush pushes buffered rows to the underlying writer
+	Flush() error
+	// Close finishes the document; nothing may be written afterwards
+	Close() error
+}
+
+// NewRowWriter creates a writer for format
+func NewRowWriter(format string, w io.Writer, sheetName string) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, sheetName)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// sanitizeCell neutralises values a spreadsheet would evaluate as a formula
+// (user supplied notes such as "=HYPERLINK(...)")
+func sanitizeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
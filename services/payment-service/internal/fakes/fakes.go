@@ -0,0 +1,322 @@
+// Package fakes provides in-memory implementations of the payment handler
+// dependencies so handlers can be exercised without Redis, RabbitMQ or Midtrans.
+package fakes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"payment-service/internal/cache"
+	"payment-service/internal/events"
+	"payment-service/internal/models"
+	"payment-service/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// Ensure fakes implement the handler interfaces
+var (
+	_ services.PaymentGateway = (*Midtrans)(nil)
+	_ cache.Cache             = (*Cache)(nil)
+	_ events.EventPublisher   = (*EventPublisher)(nil)
+)
+
+// Midtrans is a fake payment gateway returning canned responses
+type Midtrans struct {
+	ChargeResponse *services.MidtransChargeResponse
+	ChargeErr      error
+	StatusResponse *services.MidtransStatusResponse
+	StatusErr      error
+	CancelErr      error
+	ExpireErr      error
+	ValidSignature bool
+	ClientKey      string
+	Environment    string
+
+	mu              sync.Mutex
+	Charges         []*models.Payment
+	Cancelled       []string // order IDs passed to CancelPayment
+	Expired         []string // order IDs passed to ExpirePayment
+	StoreServerKeys []string // keys passed to WithCredentials
+}
+
+// CreatePayment records the charge and returns the configured response
+func (m *Midtrans) CreatePayment(payment *models.Payment, user *models.UserProfile, product *models.Product) (*services.MidtransChargeResponse, error) {
+	m.mu.Lock()
+	m.Charges = append(m.Charges, payment)
+	m.mu.Unlock()
+
+	if m.ChargeErr != nil {
+		return nil, m.ChargeErr
+	}
+	return m.ChargeResponse, nil
+}
+
+// GetPaymentStatus returns the configured status response
+func (m *Midtrans) GetPaymentStatus(orderID string) (*services.MidtransStatusResponse, error) {
+	if m.StatusErr != nil {
+		return nil, m.StatusErr
+	}
+	return m.StatusResponse, nil
+}
+
+// CancelPayment records the cancelled order and returns the configured error
+func (m *Midtrans) CancelPayment(orderID string) error {
+	m.mu.Lock()
+	m.Cancelled = append(m.Cancelled, orderID)
+	m.mu.Unlock()
+	return m.CancelErr
+}
+
+// ExpirePayment records the expired order and returns the configured error
+func (m *Midtrans) ExpirePayment(orderID string) error {
+	m.mu.Lock()
+	m.Expired = append(m.Expired, orderID)
+	m.mu.Unlock()
+	return m.ExpireErr
+}
+
+// VerifySignature returns the configured signature result
+func (m *Midtrans) VerifySignature(orderID, statusCode, grossAmount, signatureKey string) bool {
+	return m.ValidSignature
+}
+
+// SignCallback returns a fixed signature, VerifySignature decides whether it is accepted
+func (m *Midtrans) SignCallback(orderID, statusCode, grossAmount string) string {
+	return "fake-signature"
+}
+
+// MapMidtransStatusToPaymentStatus uses the real Midtrans status mapping
+func (m *Midtrans) MapMidtransStatusToPaymentStatus(midtransStatus string) models.PaymentStatus {
+	return (&services.MidtransService{}).MapMidtransStatusToPaymentStatus(midtransStatus)
+}
+
+// GetClientKey returns the configured client key
+func (m *Midtrans) GetClientKey() string {
+	return m.ClientKey
+}
+
+// GetEnvironment returns the configured environment
+func (m *Midtrans) GetEnvironment() string {
+	return m.Environment
+}
+
+// WithCredentials records the store keys and returns the same fake, so charges made with
+// store credentials are visible in Charges
+func (m *Midtrans) WithCredentials(serverKey, clientKey string) services.PaymentGateway {
+	m.mu.Lock()
+	m.StoreServerKeys = append(m.StoreServerKeys, serverKey)
+	m.mu.Unlock()
+	return m
+}
+
+// Cache is an in-memory cache storing values as JSON like the Redis implementation
+type Cache struct {
+	mu    sync.Mutex
+	items map[string][]byte
+	locks map[string]time.Time // lock name to expiry
+}
+
+// NewCache creates an empty in-memory cache
+func NewCache() *Cache {
+	return &Cache{items: make(map[string][]byte), locks: make(map[string]time.Time)}
+}
+
+func (c *Cache) set(key string, data interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = jsonData
+	return nil
+}
+
+func (c *Cache) get(key string, dest interface{}) error {
+	c.mu.Lock()
+	jsonData, ok := c.items[key]
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("cache miss")
+	}
+	return json.Unmarshal(jsonData, dest)
+}
+
+func (c *Cache) delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.items, key)
+	}
+}
+
+func (c *Cache) deletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.items, key)
+		}
+	}
+}
+
+// SetPayment caches a payment by ID
+func (c *Cache) SetPayment(ctx context.Context, paymentID string, data interface{}, expiration time.Duration) error {
+	return c.set("payment:"+paymentID, data)
+}
+
+// GetPayment reads a cached payment by ID
+func (c *Cache) GetPayment(ctx context.Context, paymentID string, dest interface{}) error {
+	return c.get("payment:"+paymentID, dest)
+}
+
+// SetPaymentByOrderID caches a payment by order ID
+func (c *Cache) SetPaymentByOrderID(ctx context.Context, orderID string, data interface{}, expiration time.Duration) error {
+	return c.set("payment:order:"+orderID, data)
+}
+
+// GetPaymentByOrderID reads a cached payment by order ID
+func (c *Cache) GetPaymentByOrderID(ctx context.Context, orderID string, dest interface{}) error {
+	return c.get("payment:order:"+orderID, dest)
+}
+
+// SetPaymentEntries caches a payment by ID and order ID
+func (c *Cache) SetPaymentEntries(ctx context.Context, paymentID, orderID string, data interface{}, expiration time.Duration) error {
+	if err := c.set("payment:"+paymentID, data); err != nil {
+		return err
+	}
+	return c.set("payment:order:"+orderID, data)
+}
+
+// SetUserPayments caches a user's payment list
+func (c *Cache) SetUserPayments(ctx context.Context, userID string, data interface{}, expiration time.Duration) error {
+	return c.set("payments:user:"+userID, data)
+}
+
+// GetUserPayments reads a cached user's payment list
+func (c *Cache) GetUserPayments(ctx context.Context, userID string, dest interface{}) error {
+	return c.get("payments:user:"+userID, dest)
+}
+
+// DeleteUserPayments removes every cached page of a user's payment list
+func (c *Cache) DeleteUserPayments(ctx context.Context, userID string) error {
+	c.deletePrefix("payments:user:" + userID)
+	return nil
+}
+
+// InvalidatePaymentCache removes every cached entry for a payment
+func (c *Cache) InvalidatePaymentCache(ctx context.Context, paymentID, orderID, userID string) error {
+	c.delete("payment:"+paymentID, "payment:order:"+orderID)
+	c.deletePrefix("payments:user:" + userID)
+	return nil
+}
+
+// SetProduct caches a product lookup
+func (c *Cache) SetProduct(ctx context.Context, productID string, data interface{}, expiration time.Duration) error {
+	return c.set("product:"+productID, data)
+}
+
+// GetProduct reads a cached product lookup
+func (c *Cache) GetProduct(ctx context.Context, productID string, dest interface{}) error {
+	return c.get("product:"+productID, dest)
+}
+
+// DeleteProduct removes a cached product lookup
+func (c *Cache) DeleteProduct(ctx context.Context, productID string) error {
+	c.delete("product:" + productID)
+	return nil
+}
+
+// Lock takes an in-memory lock, waiting up to wait while it is held
+func (c *Cache) Lock(ctx context.Context, name string, ttl, wait time.Duration) (func(), error) {
+	deadline := time.Now().Add(wait)
+	for {
+		c.mu.Lock()
+		if expiry, held := c.locks[name]; !held || time.Now().After(expiry) {
+			expiry = time.Now().Add(ttl)
+			c.locks[name] = expiry
+			c.mu.Unlock()
+			return func() {
+				c.mu.Lock()
+				defer c.mu.Unlock()
+				if c.locks[name] == expiry {
+					delete(c.locks, name)
+				}
+			}, nil
+		}
+		c.mu.Unlock()
+
+		if time.Now().After(deadline) {
+			return nil, cache.ErrLockBusy
+		}
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// PublishedEvent is an event captured by EventPublisher
+type PublishedEvent struct {
+	Type      string
+	PaymentID string
+	OrderID   string
+	UserID    string
+	Status    string
+}
+
+// EventPublisher records published events instead of sending them to RabbitMQ
+type EventPublisher struct {
+	Err error
+
+	mu     sync.Mutex
+	Events []PublishedEvent
+}
+
+func (e *EventPublisher) record(event PublishedEvent) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.Events = append(e.Events, event)
+	return e.Err
+}
+
+// PublishPaymentCreated records a payment.created event
+func (e *EventPublisher) PublishPaymentCreated(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod, status string) error {
+	return e.record(PublishedEvent{Type: "payment.created", PaymentID: paymentID, OrderID: orderID, UserID: userID, Status: status})
+}
+
+// PublishPaymentStatusUpdated records a payment.status.updated event
+func (e *EventPublisher) PublishPaymentStatusUpdated(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, oldStatus, newStatus string, amount, totalAmount int64, paymentMethod string, paidAt *time.Time) error {
+	return e.record(PublishedEvent{Type: "payment.status.updated", PaymentID: paymentID, OrderID: orderID, UserID: userID, Status: newStatus})
+}
+
+// PublishPaymentSuccess records a payment.success event
+func (e *EventPublisher) PublishPaymentSuccess(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod string, paidAt time.Time, summary events.OrderSummary) error {
+	return e.record(PublishedEvent{Type: "payment.success", PaymentID: paymentID, OrderID: orderID, UserID: userID, Status: string(models.PaymentStatusSuccess)})
+}
+
+// PublishPaymentFailed records a payment.failed event
+func (e *EventPublisher) PublishPaymentFailed(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod, failureReason string) error {
+	return e.record(PublishedEvent{Type: "payment.failed", PaymentID: paymentID, OrderID: orderID, UserID: userID, Status: string(models.PaymentStatusFailed)})
+}
+
+// PublishPaymentRefunded records a payment.refunded event
+func (e *EventPublisher) PublishPaymentRefunded(ctx context.Context, paymentID, orderID, userID string, productID *uuid.UUID, amount, totalAmount int64, paymentMethod string, refundedAt time.Time) error {
+	return e.record(PublishedEvent{Type: "payment.refunded", PaymentID: paymentID, OrderID: orderID, UserID: userID, Status: string(models.PaymentStatusRefunded)})
+}
+
+// PublishStockReduction records a stock.reduction event
+func (e *EventPublisher) PublishStockReduction(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID, quantity int, orderID, userID string) error {
+	return e.record(PublishedEvent{Type: "stock.reduction", OrderID: orderID, UserID: userID})
+}
+
+// PublishInvoiceReminder records a payment.invoice.reminder event
+func (e *EventPublisher) PublishInvoiceReminder(ctx context.Context, reminder events.InvoiceReminderEvent) error {
+	return e.record(PublishedEvent{Type: "payment.invoice.reminder", PaymentID: reminder.PaymentID, OrderID: reminder.OrderID, UserID: reminder.UserID})
+}
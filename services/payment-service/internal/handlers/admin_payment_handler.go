@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"payment-service/internal/models"
+	"payment-service/internal/timeutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// savedPaymentFilter is a named filter of the admin dashboard, resolved against the current
+// time in the display zone (DISPLAY_TIMEZONE) when it is used
+type savedPaymentFilter struct {
+	name        string
+	description string
+	status      models.PaymentStatus
+	since       func(now time.Time) time.Time
+}
+
+// savedPaymentFilters are offered by GET /api/v1/admin/payments/filters and applied with
+// ?filter=<name> on GET /api/v1/admin/payments
+var savedPaymentFilters = []savedPaymentFilter{
+	{
+		name:        "failed-today",
+		description: "Payments created today that failed",
+		status:      models.PaymentStatusFailed,
+		since:       startOfDay,
+	},
+	{
+		name:        "expired-this-week",
+		description: "Payments created this week (since Monday) that expired",
+		status:      models.PaymentStatusExpired,
+		since:       startOfWeek,
+	},
+}
+
+// startOfDay returns midnight of now's day
+func startOfDay(now time.Time) time.Time {
+	year, month, day := now.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+}
+
+// startOfWeek returns midnight of the Monday of now's week
+func startOfWeek(now time.Time) time.Time {
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	return startOfDay(now).AddDate(0, 0, -daysSinceMonday)
+}
+
+// lookupSavedPaymentFilter returns the saved filter called name
+func lookupSavedPaymentFilter(name string) (savedPaymentFilter, bool) {
+	for _, filter := range savedPaymentFilters {
+		if filter.name == name {
+			return filter, true
+		}
+	}
+	return savedPaymentFilter{}, false
+}
+
+// ListSavedPaymentFilters returns the saved filters with the status and from they currently
+// resolve to (admin only)
+func (ph *PaymentHandler) ListSavedPaymentFilters(c *gin.Context) {
+	now := time.Now().In(timeutil.Display())
+
+	filters := make([]gin.H, len(savedPaymentFilters))
+	for i, filter := range savedPaymentFilters {
+		filters[i] = gin.H{
+			"name":        filter.name,
+			"description": filter.description,
+			"params": gin.H{
+				"status": filter.status,
+				"from":   filter.since(now).Format(time.RFC3339),
+			},
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    filters,
+	})
+}
+
+// AdminListPayments lists the payments of every user, newest first (admin only). It takes
+// the filters of GET /payments/user, user_id, and filter=<saved filter>; explicit status and
+// from take precedence over the saved filter's. q matches order IDs, notes and VA numbers.
+func (ph *PaymentHandler) AdminListPayments(c *gin.Context) {
+	query, err := parseUserPaymentQuery(c)
+	if err == nil {
+		err = applyAdminPaymentFilters(c, &query)
+	}
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_QUERY", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	payments, total, err := ph.paymentRepo.GetAll(c.Request.Context(), query)
+	if err != nil {
+		fmt.Printf("❌ Failed to list payments for admin: %v\n", err)
+		respondError(c, http.StatusInternalServerError, "PAYMENTS_FETCH_FAILED", nil)
+		return
+	}
+
+	paymentResponses := make([]models.PaymentResponse, len(payments))
+	for i, payment := range payments {
+		paymentResponses[i] = payment.ToResponse()
+		if payment.MidtransAction != nil {
+			var actions []models.MidtransAction
+			if err := json.Unmarshal([]byte(*payment.MidtransAction), &actions); err == nil {
+				paymentResponses[i].Actions = actions
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": models.PaymentListResponse{
+			Payments: paymentResponses,
+			Total:    total,
+			Page:     query.Page,
+			Limit:    query.Limit,
+			HasMore:  int64(query.Page*query.Limit) < total,
+		},
+	})
+}
+
+// applyAdminPaymentFilters adds user_id and the saved filter to query
+func applyAdminPaymentFilters(c *gin.Context, query *models.PaymentQuery) error {
+	if value := c.Query("user_id"); value != "" {
+		userID, err := uuid.Parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid user_id %q", value)
+		}
+		query.UserID = &userID
+	}
+
+	name := c.Query("filter")
+	if name == "" {
+		return nil
+	}
+	filter, ok := lookupSavedPaymentFilter(name)
+	if !ok {
+		return fmt.Errorf("unknown filter %q", name)
+	}
+
+	if query.Status == nil {
+		status := filter.status
+		query.Status = &status
+	}
+	if query.From == nil {
+		from := filter.since(time.Now().In(timeutil.Display()))
+		query.From = &from
+		if query.To != nil && !from.Before(*query.To) {
+			return fmt.Errorf("filter %q starts after to", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"payment-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetArchivedPayments handles GET /api/v1/payments/archived: the user's payments moved to
+// the archive by the archival job, newest first, with the filters and pagination of
+// GET /payments/user (q only matches order IDs)
+func (ph *PaymentHandler) GetArchivedPayments(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return
+	}
+
+	query, err := parseUserPaymentQuery(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_QUERY", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+	query.UserID = &userID
+
+	ph.listArchivedPayments(c, query)
+}
+
+// AdminListArchivedPayments lists the archived payments of every user (admin only), with
+// the filters of GET /api/v1/admin/payments
+func (ph *PaymentHandler) AdminListArchivedPayments(c *gin.Context) {
+	query, err := parseUserPaymentQuery(c)
+	if err == nil {
+		err = applyAdminPaymentFilters(c, &query)
+	}
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_QUERY", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ph.listArchivedPayments(c, query)
+}
+
+// listArchivedPayments writes a page of the archived payments matching query
+func (ph *PaymentHandler) listArchivedPayments(c *gin.Context, query models.PaymentQuery) {
+	archived, total, err := ph.paymentRepo.GetArchived(c.Request.Context(), query)
+	if err != nil {
+		fmt.Printf("❌ Failed to list archived payments: %v\n", err)
+		respondError(c, http.StatusInternalServerError, "ARCHIVED_PAYMENTS_FETCH_FAILED", nil)
+		return
+	}
+
+	responses := make([]models.ArchivedPaymentResponse, 0, len(archived))
+	for _, entry := range archived {
+		response, err := archivedPaymentResponse(entry)
+		if err != nil {
+			fmt.Printf("⚠️ Skipping unreadable archived payment: %v\n", err)
+			continue
+		}
+		responses = append(responses, response)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": models.ArchivedPaymentListResponse{
+			Payments: responses,
+			Total:    total,
+			Page:     query.Page,
+			Limit:    query.Limit,
+			HasMore:  int64(query.Page*query.Limit) < total,
+		},
+	})
+}
+
+// GetArchivedPayment handles GET /api/v1/payments/archived/:id for the payment's owner
+func (ph *PaymentHandler) GetArchivedPayment(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return
+	}
+
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PAYMENT_ID", nil)
+		return
+	}
+
+	archived, err := ph.paymentRepo.GetArchivedByID(c.Request.Context(), paymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "ARCHIVED_PAYMENT_NOT_FOUND", nil)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "ARCHIVED_PAYMENT_FETCH_FAILED", nil)
+		return
+	}
+
+	// Someone else's payment is reported as missing, not forbidden, so IDs can't be probed
+	if archived.UserID != userID {
+		respondError(c, http.StatusNotFound, "ARCHIVED_PAYMENT_NOT_FOUND", nil)
+		return
+	}
+
+	response, err := archivedPaymentResponse(*archived)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ARCHIVED_PAYMENT_READ_FAILED", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// archivedPaymentResponse decodes an archived payment into the usual payment response
+func archivedPaymentResponse(archived models.ArchivedPayment) (models.ArchivedPaymentResponse, error) {
+	payment, err := archived.Payment()
+	if err != nil {
+		return models.ArchivedPaymentResponse{}, err
+	}
+	return models.ArchivedPaymentResponse{
+		PaymentResponse: payment.ToResponse(),
+		ArchivedAt:      archived.ArchivedAt,
+	}, nil
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"payment-service/internal/models"
+	"payment-service/internal/services"
+	"payment-service/internal/timeutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// simulatedStatusCodes maps the transaction statuses that can be simulated to the status_code
+// Midtrans sends with them
+var simulatedStatusCodes = map[string]string{
+	"pending":        "201",
+	"settlement":     "200",
+	"capture":        "200",
+	"deny":           "202",
+	"cancel":         "202",
+	"expire":         "202",
+	"refund":         "200",
+	"partial_refund": "200",
+}
+
+// SimulateCallbackRequest is the body of POST /api/v1/payments/midtrans/callback/test
+type SimulateCallbackRequest struct {
+	OrderID           string `json:"order_id" binding:"required"`
+	TransactionStatus string `json:"transaction_status" binding:"required"`
+	FraudStatus       string `json:"fraud_status"`
+}
+
+// SimulateMidtransCallback handles POST /api/v1/payments/midtrans/callback/test. It builds the
+// callback Midtrans would send for the order and status, signed with the order's server key,
+// and runs it through the production callback handling so QA can test settlement and expiry
+// flows. The sandbox still reports the real transaction as pending, so the simulated status
+// stands in for Midtrans' status answer. The route is only registered outside production
+// against the Midtrans sandbox.
+func (ph *PaymentHandler) SimulateMidtransCallback(c *gin.Context) {
+	var req SimulateCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST_BODY", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	statusCode, ok := simulatedStatusCodes[req.TransactionStatus]
+	if !ok {
+		respondError(c, http.StatusBadRequest, "UNSUPPORTED_TRANSACTION_STATUS", gin.H{
+			"details": "use pending, settlement, capture, deny, cancel, expire, refund or partial_refund",
+		})
+		return
+	}
+
+	payment, err := ph.paymentRepo.GetByOrderID(c.Request.Context(), req.OrderID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "PAYMENT_NOT_FOUND", nil)
+		return
+	}
+
+	gateway, err := ph.gatewayFor(c.Request.Context(), payment.StoreID)
+	if err != nil {
+		fmt.Printf("❌ Failed to load Midtrans credentials for order %s: %v\n", req.OrderID, err)
+		respondError(c, http.StatusInternalServerError, "STORE_SETTINGS_FAILED", nil)
+		return
+	}
+
+	fraudStatus := req.FraudStatus
+	if fraudStatus == "" {
+		fraudStatus = "accept"
+	}
+
+	now := time.Now().In(timeutil.MidtransLocation).Format("2006-01-02 15:04:05")
+	grossAmount := payment.Total().Decimal()
+	callback := models.MidtransCallbackRequest{
+		OrderID:           payment.OrderID,
+		StatusCode:        statusCode,
+		GrossAmount:       grossAmount,
+		SignatureKey:      gateway.SignCallback(payment.OrderID, statusCode, grossAmount),
+		TransactionStatus: req.TransactionStatus,
+		FraudStatus:       fraudStatus,
+		PaymentType:       payment.PaymentType,
+		TransactionID:     "simulated-" + uuid.New().String(),
+		TransactionTime:   now,
+	}
+	if callback.PaymentType == "" {
+		callback.PaymentType = string(payment.PaymentMethod)
+	}
+	if statusCode == "200" {
+		callback.PaidAt = now
+		callback.SettlementTime = now
+	}
+	if payment.MidtransTransactionID != nil && *payment.MidtransTransactionID != "" {
+		callback.TransactionID = *payment.MidtransTransactionID
+	}
+
+	// What Midtrans' status API would answer after sending the callback. The actions stored at
+	// charge time are kept, the QR code and deeplinks don't change with the status.
+	status := &services.MidtransStatusResponse{
+		StatusCode:        callback.StatusCode,
+		StatusMessage:     "Simulated transaction status",
+		TransactionID:     callback.TransactionID,
+		OrderID:           callback.OrderID,
+		GrossAmount:       callback.GrossAmount,
+		PaymentType:       callback.PaymentType,
+		TransactionTime:   callback.TransactionTime,
+		TransactionStatus: callback.TransactionStatus,
+		FraudStatus:       callback.FraudStatus,
+		PaidAt:            callback.PaidAt,
+	}
+	if payment.MidtransAction != nil {
+		json.Unmarshal([]byte(*payment.MidtransAction), &status.Actions)
+	}
+
+	body, err := json.Marshal(callback)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "CALLBACK_BUILD_FAILED", nil)
+		return
+	}
+
+	fmt.Printf("🧪 Simulating Midtrans callback for order: %s, status: %s\n", payment.OrderID, req.TransactionStatus)
+
+	// Replay it through the production callback handler
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	ph.handleMidtransCallback(c, status)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"payment-service/internal/chaos"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChaosHandler manages the injected faults, registered only while fault injection is enabled
+type ChaosHandler struct{}
+
+// NewChaosHandler creates a new fault injection handler
+func NewChaosHandler() *ChaosHandler {
+	return &ChaosHandler{}
+}
+
+// AddFaultRequest is a fault to inject, kept for TTL seconds (15 minutes by default)
+type AddFaultRequest struct {
+	chaos.Fault
+	TTL int `json:"ttl"`
+}
+
+// ListFaults returns the active faults and the scopes they can target
+func (ch *ChaosHandler) ListFaults(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"faults": chaos.List(),
+			"scopes": chaos.Scopes(),
+		},
+	})
+}
+
+// AddFault injects a fault on an upstream client (e.g. midtrans_charge, internal_service)
+// or an event queue (e.g. payment.validation.queue), * targeting all of them
+func (ch *ChaosHandler) AddFault(c *gin.Context) {
+	var req AddFaultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	fault, err := chaos.Add(req.Fault, time.Duration(req.TTL)*time.Second)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, chaos.ErrInvalidFault) {
+			status = http.StatusBadRequest
+		}
+		respondError(c, status, "FAULT_ADD_FAILED", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log.Printf("💥 Chaos fault %s added: %s on %s %s (p=%g) until %s", fault.ID, fault.Type, fault.Scope, fault.Target, fault.Probability, fault.ExpiresAt.Format(time.RFC3339))
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    fault,
+	})
+}
+
+// RemoveFault lifts one fault
+func (ch *ChaosHandler) RemoveFault(c *gin.Context) {
+	id := c.Param("id")
+	if !chaos.Remove(id) {
+		respondError(c, http.StatusNotFound, "FAULT_NOT_FOUND", nil)
+		return
+	}
+
+	log.Printf("✅ Chaos fault %s removed", id)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Fault removed",
+	})
+}
+
+// ClearFaults lifts every fault
+func (ch *ChaosHandler) ClearFaults(c *gin.Context) {
+	removed := chaos.Clear()
+	log.Printf("✅ %d chaos faults removed", removed)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"removed": removed,
+		},
+	})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"payment-service/internal/cache"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ErrCodePriceChanged is the error code of charges whose amount no longer matches the price
+// of the product, the response carries the current price for the buyer to confirm
+const ErrCodePriceChanged = "PRICE_CHANGED"
+
+// chargeLockConfigFromEnv reads CHARGE_LOCK_TTL (default 30s, 0 disables the lock), the
+// longest a checkout holds its lock, and CHARGE_LOCK_WAIT (default 5s), how long another
+// checkout of the same product waits for it
+func chargeLockConfigFromEnv() (ttl, wait time.Duration) {
+	ttl = 30 * time.Second
+	if value := os.Getenv("CHARGE_LOCK_TTL"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			ttl = parsed
+		} else {
+			fmt.Printf("⚠️ Ignoring invalid CHARGE_LOCK_TTL=%q\n", value)
+		}
+	}
+
+	wait = 5 * time.Second
+	if value := os.Getenv("CHARGE_LOCK_WAIT"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			wait = parsed
+		} else {
+			fmt.Printf("⚠️ Ignoring invalid CHARGE_LOCK_WAIT=%q\n", value)
+		}
+	}
+	return ttl, wait
+}
+
+// lockCharge takes the checkout lock of a product's stock, the variant's for products with
+// variants, so checkouts competing for the same units, from any buyer, are verified and
+// charged one after the other against the price and stock at that moment. It writes the
+// response and returns false when another checkout holds the lock past CHARGE_LOCK_WAIT.
+// When Redis fails the checkout goes on unlocked.
+func (ph *PaymentHandler) lockCharge(c *gin.Context, productID uuid.UUID, variantID *uuid.UUID) (func(), bool) {
+	if ph.chargeLockTTL == 0 {
+		return func() {}, true
+	}
+
+	name := "charge:" + productID.String()
+	if variantID != nil {
+		name += ":" + variantID.String()
+	}
+	release, err := ph.cacheSvc.Lock(c.Request.Context(), name, ph.chargeLockTTL, ph.chargeLockWait)
+	if errors.Is(err, cache.ErrLockBusy) {
+		respondError(c, http.StatusConflict, "CHECKOUT_IN_PROGRESS", gin.H{
+			"details": "another payment for this product is being created, try again shortly",
+		})
+		return nil, false
+	}
+	if err != nil {
+		fmt.Printf("⚠️ Checkout lock unavailable, charging product %s unlocked: %v\n", productID, err)
+		return func() {}, true
+	}
+	return release, true
+}
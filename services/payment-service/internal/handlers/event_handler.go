@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"payment-service/internal/events"
+	"payment-service/internal/models"
+	"payment-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxReplayEvents caps how many events a single replay request may publish
+const maxReplayEvents = 1000
+
+// EventHandler handles admin access to the published event log
+type EventHandler struct {
+	eventLogRepo *repository.EventLogRepository
+	eventSvc     *events.EventService
+}
+
+// NewEventHandler creates a new event handler
+func NewEventHandler(eventLogRepo *repository.EventLogRepository, eventSvc *events.EventService) *EventHandler {
+	return &EventHandler{
+		eventLogRepo: eventLogRepo,
+		eventSvc:     eventSvc,
+	}
+}
+
+// ListEvents returns logged events, filtered by ?from=&to= (RFC3339), ?routing_key= and ?limit=
+func (eh *EventHandler) ListEvents(c *gin.Context) {
+	filter := repository.EventLogFilter{
+		RoutingKey: c.Query("routing_key"),
+		Limit:      100,
+	}
+
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, "INVALID_TIME", gin.H{
+					"details": param,
+				})
+				return
+			}
+			*target = &parsed
+		}
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= maxReplayEvents {
+			filter.Limit = parsed
+		}
+	}
+
+	eventLogs, err := eh.eventLogRepo.List(filter)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "EVENTS_FETCH_FAILED", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    eventLogs,
+		"count":   len(eventLogs),
+	})
+}
+
+// ReplayEvents publishes logged events back onto RabbitMQ, selected by ID or by time range
+func (eh *EventHandler) ReplayEvents(c *gin.Context) {
+	var req models.ReplayEventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	filter := repository.EventLogFilter{Limit: maxReplayEvents + 1}
+
+	switch {
+	case len(req.EventIDs) > 0:
+		for _, idStr := range req.EventIDs {
+			id, err := uuid.Parse(idStr)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, "INVALID_EVENT_ID", gin.H{
+					"details": idStr,
+				})
+				return
+			}
+			filter.IDs = append(filter.IDs, id)
+		}
+	case req.From != nil && req.To != nil:
+		if req.To.Before(*req.From) {
+			respondError(c, http.StatusBadRequest, "INVALID_TIME_RANGE", nil)
+			return
+		}
+		filter.From = req.From
+		filter.To = req.To
+		filter.RoutingKey = req.RoutingKey
+	default:
+		respondError(c, http.StatusBadRequest, "EVENT_SELECTION_REQUIRED", nil)
+		return
+	}
+
+	eventLogs, err := eh.eventLogRepo.List(filter)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "EVENTS_FETCH_FAILED", nil)
+		return
+	}
+
+	if len(eventLogs) > maxReplayEvents {
+		respondError(c, http.StatusBadRequest, "TOO_MANY_EVENTS", gin.H{
+			"details": "At most " + strconv.Itoa(maxReplayEvents) + " events can be replayed per request",
+		})
+		return
+	}
+
+	if req.DryRun {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"dry_run": true,
+			"data":    eventLogs,
+			"count":   len(eventLogs),
+		})
+		return
+	}
+
+	replayed := 0
+	var failed []gin.H
+	for i := range eventLogs {
+		eventLog := &eventLogs[i]
+		if err := eh.eventSvc.Republish(c.Request.Context(), eventLog); err != nil {
+			failed = append(failed, gin.H{"id": eventLog.ID, "error": err.Error()})
+			continue
+		}
+		eh.eventLogRepo.MarkReplayed(eventLog.ID, time.Now())
+		replayed++
+	}
+
+	status := http.StatusOK
+	if len(failed) > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	c.JSON(status, gin.H{
+		"success":  len(failed) == 0,
+		"replayed": replayed,
+		"failed":   failed,
+	})
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"payment-service/internal/export"
+	"payment-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// exportBatchSize is the number of payments read and written per round trip
+const exportBatchSize = 500
+
+// exportColumns is the header of the payment history export
+var exportColumns = []string{
+	"created_at", "order_id", "status", "payment_method", "payment_type",
+	"amount", "admin_fee", "total_amount", "paid_at", "midtrans_transaction_id", "notes",
+}
+
+// ExportUserPayments streams the authenticated user's payment history as CSV or XLSX
+// (?format=csv|xlsx). It accepts the same filters as GetUserPayments, without pagination.
+// With ?delivery=link the export is stored and a signed download URL is returned instead.
+func (ph *PaymentHandler) ExportUserPayments(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", export.FormatCSV))
+	if format != export.FormatCSV && format != export.FormatXLSX {
+		respondError(c, http.StatusBadRequest, "INVALID_EXPORT_FORMAT", gin.H{
+			"details": "format must be csv or xlsx",
+		})
+		return
+	}
+
+	query, err := parseUserPaymentQuery(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_QUERY", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+	query.UserID = &userID
+
+	filename := fmt.Sprintf("payments-%s.%s", time.Now().Format("20060102"), format)
+	if c.Query("delivery") == "link" {
+		ph.exportPaymentsLink(c, userID, query, format, filename)
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	// Headers are sent at this point, failures can only end the stream early
+	rows, err := ph.writePaymentExport(c.Request.Context(), c.Writer, format, query, c.Writer.Flush)
+	if err != nil {
+		fmt.Printf("❌ Payment export for user %s aborted after %d rows: %v\n", userID, rows, err)
+		return
+	}
+
+	fmt.Printf("📤 Exported %d payments for user %s as %s\n", rows, userID, format)
+}
+
+// exportPaymentsLink stores the export in the artifact storage and answers a signed URL to
+// download it, valid for STORAGE_SIGNED_URL_TTL
+func (ph *PaymentHandler) exportPaymentsLink(c *gin.Context, userID uuid.UUID, query models.PaymentQuery, format, filename string) {
+	if ph.artifacts == nil {
+		respondError(c, http.StatusBadRequest, "EXPORT_LINKS_UNAVAILABLE", gin.H{
+			"details": "storage is not configured, download the export directly",
+		})
+		return
+	}
+
+	// Spooled to disk, exports can be larger than what should be held in memory
+	tmp, err := os.CreateTemp("", "payment-export-*")
+	if err != nil {
+		fmt.Printf("❌ Failed to create payment export file: %v\n", err)
+		respondError(c, http.StatusInternalServerError, "PAYMENTS_EXPORT_FAILED", nil)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	rows, err := ph.writePaymentExport(c.Request.Context(), tmp, format, query, func() {})
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	key := fmt.Sprintf("exports/%s/%s/%s", userID, uuid.New(), filename)
+	if err == nil {
+		err = ph.artifacts.Put(c.Request.Context(), key, tmp, export.ContentType(format))
+	}
+	var url string
+	if err == nil {
+		url, err = ph.artifacts.SignedURL(c.Request.Context(), key, ph.signedURLTTL)
+	}
+	if err != nil {
+		fmt.Printf("❌ Failed to store payment export of user %s (%d rows): %v\n", userID, rows, err)
+		respondError(c, http.StatusInternalServerError, "PAYMENTS_EXPORT_FAILED", nil)
+		return
+	}
+
+	fmt.Printf("📤 Stored export of %d payments for user %s as %s\n", rows, userID, key)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"url":        url,
+			"filename":   filename,
+			"rows":       rows,
+			"expires_at": time.Now().Add(ph.signedURLTTL),
+		},
+	})
+}
+
+// writePaymentExport writes the payments matching query to w as format, calling flush after
+// each batch. It returns the number of rows written.
+func (ph *PaymentHandler) writePaymentExport(ctx context.Context, w io.Writer, format string, query models.PaymentQuery, flush func()) (int, error) {
+	writer, err := export.NewRowWriter(format, w, "Payments")
+	if err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	if err := writer.WriteRow(exportColumns); err != nil {
+		return 0, err
+	}
+	err = ph.paymentRepo.StreamPayments(ctx, query, exportBatchSize, func(payments []models.Payment) error {
+		for i := range payments {
+			if err := writer.WriteRow(exportRow(&payments[i])); err != nil {
+				return err
+			}
+		}
+		rows += len(payments)
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		flush()
+		return nil
+	})
+	if err != nil {
+		return rows, err
+	}
+	return rows, writer.Close()
+}
+
+// exportRow formats a payment as export cells (amounts in rupiah, times in RFC3339)
+func exportRow(payment *models.Payment) []string {
+	return []string{
+		payment.CreatedAt.Format(time.RFC3339),
+		payment.OrderID,
+		string(payment.Status),
+		string(payment.PaymentMethod),
+		payment.PaymentType,
+		strconv.FormatInt(payment.Amount, 10),
+		strconv.FormatInt(payment.AdminFee, 10),
+		strconv.FormatInt(payment.TotalAmount, 10),
+		formatOptionalTime(payment.PaidAt),
+		stringValue(payment.MidtransTransactionID),
+		stringValue(payment.Notes),
+	}
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
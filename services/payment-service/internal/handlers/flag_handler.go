@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"payment-service/internal/flags"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FlagHandler handles admin access to feature flags
+type FlagHandler struct {
+	flags *flags.Store
+}
+
+// NewFlagHandler creates a new feature flag handler
+func NewFlagHandler(store *flags.Store) *FlagHandler {
+	return &FlagHandler{flags: store}
+}
+
+// UpdateFlagRequest flips a flag and optionally changes its rollout percentage
+type UpdateFlagRequest struct {
+	Enabled bool `json:"enabled"`
+	Rollout *int `json:"rollout,omitempty"` // 0-100, defaults to 100
+}
+
+// ListFlags returns every feature flag with its current state and source
+func (fh *FlagHandler) ListFlags(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    fh.flags.List(),
+	})
+}
+
+// UpdateFlag stores a flag; other instances apply it within FEATURE_FLAG_REFRESH
+func (fh *FlagHandler) UpdateFlag(c *gin.Context) {
+	var req UpdateFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rollout := 100
+	if req.Rollout != nil {
+		rollout = *req.Rollout
+	}
+
+	flag, err := fh.flags.Set(c.Request.Context(), c.Param("name"), req.Enabled, rollout)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, flags.ErrUnknownFlag):
+			status = http.StatusNotFound
+		case errors.Is(err, flags.ErrInvalidRollout):
+			status = http.StatusBadRequest
+		case errors.Is(err, flags.ErrOverridden):
+			status = http.StatusConflict
+		case errors.Is(err, flags.ErrUnavailable):
+			status = http.StatusServiceUnavailable
+		}
+		respondError(c, status, "FEATURE_FLAG_UPDATE_FAILED", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    flag,
+	})
+}
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"payment-service/internal/events"
+	"payment-service/internal/invoice"
+	"payment-service/internal/models"
+	"payment-service/internal/money"
+	"payment-service/internal/repository"
+	"payment-service/internal/storage"
+	"payment-service/internal/timeutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// invoiceMaxDueFromEnv returns how far ahead an invoice may be due, INVOICE_MAX_DUE_DAYS
+// (default 90)
+func invoiceMaxDueFromEnv() time.Duration {
+	days := 90
+	if value := os.Getenv("INVOICE_MAX_DUE_DAYS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			days = parsed
+		} else {
+			fmt.Printf("⚠️ Ignoring invalid INVOICE_MAX_DUE_DAYS=%q\n", value)
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// parseInvoiceDueDate parses the due_date of a new invoice. A date is due at the end of that
+// day in the display zone. It must be in the future and within INVOICE_MAX_DUE_DAYS. On
+// failure the error response is written.
+func (ph *PaymentHandler) parseInvoiceDueDate(c *gin.Context, value *string) (time.Time, bool) {
+	if value == nil || *value == "" {
+		respondError(c, http.StatusBadRequest, "INVALID_DUE_DATE", gin.H{
+			"details": "due_date is required for invoice payments",
+		})
+		return time.Time{}, false
+	}
+
+	dueDate, dateOnly, err := parseDateParam(*value)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_DUE_DATE", gin.H{
+			"details": err.Error(),
+		})
+		return time.Time{}, false
+	}
+	if dateOnly {
+		dueDate = dueDate.AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	now := time.Now()
+	if !dueDate.After(now) || dueDate.After(now.Add(ph.invoiceMaxDue)) {
+		respondError(c, http.StatusBadRequest, "INVALID_DUE_DATE", gin.H{
+			"details": fmt.Sprintf("due_date must be in the next %d days", int(ph.invoiceMaxDue.Hours()/24)),
+		})
+		return time.Time{}, false
+	}
+	return dueDate.UTC(), true
+}
+
+// createInvoice stores a new invoice payment and writes the response. Invoices aren't charged
+// at Midtrans, they stay pending (then overdue) until an admin records their settlement.
+func (ph *PaymentHandler) createInvoice(c *gin.Context, payment *models.Payment, product *models.Product, priorAttempts []models.Payment) {
+	payment.StoreID = product.StoreID
+	payment.PaymentType = "invoice"
+
+	if err := ph.paymentRepo.Create(c.Request.Context(), payment); err != nil {
+		fmt.Printf("❌ Failed to create invoice %s: %v\n", payment.OrderID, err)
+		respondError(c, http.StatusInternalServerError, "PAYMENT_CREATE_FAILED", nil)
+		return
+	}
+	fmt.Printf("🧾 Invoice %s of %s created, due %s\n", payment.OrderID, money.New(payment.TotalAmount, payment.Currency), payment.DueDate.Format(time.RFC3339))
+
+	ph.cacheSvc.SetPaymentEntries(c.Request.Context(), payment.ID.String(), payment.OrderID, payment.ToResponse(), ph.cacheTTLs.Payment)
+
+	ph.eventSvc.PublishPaymentCreated(
+		eventContext(c),
+		payment.ID.String(),
+		payment.OrderID,
+		payment.UserID.String(),
+		payment.ProductID,
+		payment.Amount,
+		payment.TotalAmount,
+		string(payment.PaymentMethod),
+		string(payment.Status),
+	)
+
+	ph.cacheSvc.DeleteUserPayments(c.Request.Context(), payment.UserID.String())
+
+	// The new attempt replaces the pending ones, as for charged payments
+	ph.cancelPriorAttempts(c, priorAttempts)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"payment_id":     payment.ID,
+			"order_id":       payment.OrderID,
+			"amount":         payment.TotalAmount,
+			"payment_method": payment.PaymentMethod,
+			"status":         payment.Status,
+			"due_date":       payment.DueDate,
+			"invoice_url":    "/api/v1/payments/" + payment.ID.String() + "/invoice",
+		},
+	})
+}
+
+// GetInvoicePDF downloads the invoice of an invoice payment as PDF (GET /payments/:id/invoice).
+// Invoices of other users are reported as not found. Settled invoices are served from the
+// artifact store when there is one, open invoices are rendered on every download.
+func (ph *PaymentHandler) GetInvoicePDF(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return
+	}
+
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PAYMENT_ID", nil)
+		return
+	}
+
+	payment, err := ph.paymentRepo.GetByID(c.Request.Context(), paymentID)
+	if err != nil || payment.UserID != userID || !payment.IsInvoice() {
+		respondError(c, http.StatusNotFound, "INVOICE_NOT_FOUND", nil)
+		return
+	}
+
+	filename := fmt.Sprintf("invoice-%s.pdf", payment.OrderID)
+	archived := ph.artifacts != nil && payment.Status == models.PaymentStatusSuccess
+	if archived {
+		stored, err := ph.artifacts.Get(c.Request.Context(), invoiceKey(payment))
+		if err == nil {
+			defer stored.Close()
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+			c.DataFromReader(http.StatusOK, -1, "application/pdf", stored, nil)
+			return
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			fmt.Printf("⚠️ Failed to get archived invoice %s, rendering it: %v\n", payment.OrderID, err)
+		}
+	}
+
+	var pdf bytes.Buffer
+	if err := invoice.Render(&pdf, ph.invoiceDocument(payment)); err != nil {
+		fmt.Printf("❌ Failed to render invoice %s: %v\n", payment.OrderID, err)
+		respondError(c, http.StatusInternalServerError, "INVOICE_GENERATE_FAILED", nil)
+		return
+	}
+	if archived {
+		// Settled before the store was configured, or its archiving failed
+		if err := ph.artifacts.Put(c.Request.Context(), invoiceKey(payment), bytes.NewReader(pdf.Bytes()), "application/pdf"); err != nil {
+			fmt.Printf("⚠️ Failed to archive invoice %s: %v\n", payment.OrderID, err)
+		}
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/pdf", pdf.Bytes())
+}
+
+// invoiceKey is where the PDF of a settled invoice is archived
+func invoiceKey(payment *models.Payment) string {
+	return "invoices/" + payment.OrderID + ".pdf"
+}
+
+// archiveInvoice renders a settled invoice into the artifact store, so later downloads show
+// the invoice as it was settled
+func (ph *PaymentHandler) archiveInvoice(ctx context.Context, payment *models.Payment) {
+	if ph.artifacts == nil {
+		return
+	}
+	var pdf bytes.Buffer
+	if err := invoice.Render(&pdf, ph.invoiceDocument(payment)); err != nil {
+		fmt.Printf("❌ Failed to render invoice %s: %v\n", payment.OrderID, err)
+		return
+	}
+	if err := ph.artifacts.Put(ctx, invoiceKey(payment), &pdf, "application/pdf"); err != nil {
+		fmt.Printf("⚠️ Failed to archive invoice %s: %v\n", payment.OrderID, err)
+		return
+	}
+	fmt.Printf("🗄️ Invoice %s archived\n", payment.OrderID)
+}
+
+// invoiceDocument collects the content of a payment's invoice. The customer and product are
+// looked up for their names; the invoice is rendered without them if that fails.
+func (ph *PaymentHandler) invoiceDocument(payment *models.Payment) invoice.Invoice {
+	display := timeutil.Display()
+	doc := invoice.Invoice{
+		Number:   payment.OrderID,
+		Status:   string(payment.Status),
+		IssuedAt: payment.CreatedAt.In(display),
+		Total:    money.New(payment.TotalAmount, payment.Currency),
+	}
+	if payment.DueDate != nil {
+		doc.DueDate = payment.DueDate.In(display)
+	}
+	if payment.PaidAt != nil {
+		paidAt := payment.PaidAt.In(display)
+		doc.PaidAt = &paidAt
+	}
+	if payment.SettlementReference != nil {
+		doc.Reference = *payment.SettlementReference
+	}
+
+	if user, err := ph.getUserFromService(payment.UserID); err == nil {
+		doc.BillTo = []string{user.Username, user.Email}
+	} else {
+		fmt.Printf("⚠️ Failed to get customer of invoice %s: %v\n", payment.OrderID, err)
+	}
+
+	description := "Order " + payment.OrderRef
+	if payment.ProductID != nil {
+		if product, err := ph.getProductFromService(*payment.ProductID); err == nil {
+			description = product.Name
+		} else {
+			fmt.Printf("⚠️ Failed to get product of invoice %s: %v\n", payment.OrderID, err)
+		}
+	}
+
+	doc.Items = []invoice.Item{{Description: description, Quantity: payment.ItemQuantity(), Amount: money.New(payment.Amount, payment.Currency)}}
+	if payment.AdminFee != 0 {
+		doc.Items = append(doc.Items, invoice.Item{Description: "Admin fee", Amount: money.New(payment.AdminFee, payment.Currency)})
+	}
+	if payment.DiscountAmount != 0 {
+		doc.Items = append(doc.Items, invoice.Item{Description: "Discount", Amount: money.New(-payment.DiscountAmount, payment.Currency)})
+	}
+	if payment.TaxAmount != 0 {
+		doc.Items = append(doc.Items, invoice.Item{Description: "Tax", Amount: money.New(payment.TaxAmount, payment.Currency)})
+	}
+	if payment.RoundingAmount != 0 {
+		doc.Items = append(doc.Items, invoice.Item{Description: "Rounding", Amount: money.New(payment.RoundingAmount, payment.Currency)})
+	}
+	return doc
+}
+
+// SettleInvoice records the manual settlement of a pending or overdue invoice (admin only,
+// POST /admin/payments/:id/settle). The invoice becomes SUCCESS and the same events as a
+// Midtrans settlement are published.
+func (ph *PaymentHandler) SettleInvoice(c *gin.Context) {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PAYMENT_ID", nil)
+		return
+	}
+
+	var req models.SettleInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+	paidAt := time.Now()
+	if req.PaidAt != nil {
+		parsed, err := time.Parse(time.RFC3339, *req.PaidAt)
+		if err != nil || parsed.After(paidAt) {
+			respondError(c, http.StatusBadRequest, "INVALID_PAID_AT", gin.H{
+				"details": "paid_at must be an RFC3339 time in the past",
+			})
+			return
+		}
+		paidAt = parsed
+	}
+
+	payment, err := ph.paymentRepo.GetByID(c.Request.Context(), paymentID)
+	if err != nil || !payment.IsInvoice() {
+		respondError(c, http.StatusNotFound, "INVOICE_NOT_FOUND", nil)
+		return
+	}
+
+	settled, err := ph.paymentRepo.SettleInvoice(c.Request.Context(), payment.ID, req.Reference, paidAt.UTC())
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderAlreadyPaid) {
+			respondError(c, http.StatusConflict, "ORDER_ALREADY_PAID", gin.H{
+				"details": err.Error(),
+			})
+			return
+		}
+		fmt.Printf("❌ Failed to settle invoice %s: %v\n", payment.OrderID, err)
+		respondError(c, http.StatusInternalServerError, "INVOICE_SETTLE_FAILED", nil)
+		return
+	}
+	if !settled {
+		respondError(c, http.StatusConflict, "INVOICE_NOT_OPEN", gin.H{
+			"details": "invoice is " + string(payment.Status),
+		})
+		return
+	}
+	fmt.Printf("🧾 Invoice %s settled, reference %s\n", payment.OrderID, req.Reference)
+
+	ph.cacheSvc.InvalidatePaymentCache(c.Request.Context(), payment.ID.String(), payment.OrderID, payment.UserID.String())
+
+	ph.eventSvc.PublishPaymentStatusUpdated(
+		eventContext(c),
+		payment.ID.String(),
+		payment.OrderID,
+		payment.UserID.String(),
+		payment.ProductID,
+		string(payment.Status),
+		string(models.PaymentStatusSuccess),
+		payment.Amount,
+		payment.TotalAmount,
+		string(payment.PaymentMethod),
+		&paidAt,
+	)
+	ph.eventSvc.PublishPaymentSuccess(
+		eventContext(c),
+		payment.ID.String(),
+		payment.OrderID,
+		payment.UserID.String(),
+		payment.ProductID,
+		payment.Amount,
+		payment.TotalAmount,
+		string(payment.PaymentMethod),
+		paidAt,
+		ph.orderSummary(payment),
+	)
+	if payment.ProductID != nil {
+		ph.eventSvc.PublishStockReduction(
+			eventContext(c),
+			*payment.ProductID,
+			payment.VariantID,
+			payment.ItemQuantity(),
+			payment.OrderID,
+			payment.UserID.String(),
+		)
+	}
+
+	updatedPayment, err := ph.paymentRepo.GetByID(c.Request.Context(), payment.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "PAYMENT_REFRESH_FAILED", nil)
+		return
+	}
+	// Rendering looks up the customer and product, don't hold up the response for it
+	go ph.archiveInvoice(context.Background(), updatedPayment)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    updatedPayment.ToResponse(),
+	})
+}
+
+// publishInvoiceReminder asks for the reminder email of an unpaid invoice, before its due
+// date or once it is overdue. Buyers deleted in User-Service aren't reminded.
+func (ph *PaymentHandler) publishInvoiceReminder(ctx context.Context, payment *models.Payment, overdue bool) error {
+	if profile, err := ph.userProfiles.GetByID(payment.UserID); err == nil && profile.Anonymized() {
+		fmt.Printf("🔕 Skipped reminder of invoice %s, the buyer was deleted\n", payment.OrderID)
+		return nil
+	}
+
+	reminder := events.InvoiceReminderEvent{
+		PaymentID:   payment.ID.String(),
+		OrderID:     payment.OrderID,
+		UserID:      payment.UserID.String(),
+		TotalAmount: payment.TotalAmount,
+		Currency:    payment.Currency,
+		Overdue:     overdue,
+	}
+	if payment.DueDate != nil {
+		reminder.DueDate = payment.DueDate.Format(time.RFC3339)
+	}
+	if payment.ProductID != nil {
+		if product, err := ph.getProductFromService(*payment.ProductID); err == nil {
+			reminder.ProductName = product.Name
+		}
+	}
+	return ph.eventSvc.PublishInvoiceReminder(ctx, reminder)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"payment-service/internal/models"
+)
+
+// InvoiceScheduler sends the due date reminders of unpaid invoices and marks them OVERDUE
+// once their due date passes. Overdue invoices can still be settled.
+type InvoiceScheduler struct {
+	payments     *PaymentHandler
+	interval     time.Duration
+	remindBefore time.Duration
+	batchSize    int
+	stop         chan struct{}
+}
+
+// NewInvoiceScheduler creates a scheduler checking every interval, reminding remindBefore
+// the due date
+func NewInvoiceScheduler(payments *PaymentHandler, interval, remindBefore time.Duration) *InvoiceScheduler {
+	return &InvoiceScheduler{
+		payments:     payments,
+		interval:     interval,
+		remindBefore: remindBefore,
+		batchSize:    100,
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start launches the background worker
+func (is *InvoiceScheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(is.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-is.stop:
+				return
+			case <-ticker.C:
+			}
+			is.remindDue()
+			is.markOverdue()
+		}
+	}()
+
+	fmt.Printf("🚀 Invoice scheduler started (interval: %s, reminders %s before due)\n", is.interval, is.remindBefore)
+}
+
+// Stop stops the background worker
+func (is *InvoiceScheduler) Stop() {
+	close(is.stop)
+}
+
+// remindDue sends the reminder of one batch of invoices due soon. The reminder is recorded
+// first so replicas don't send it twice; if publishing fails it isn't retried, the overdue
+// reminder still goes out.
+func (is *InvoiceScheduler) remindDue() {
+	ctx, cancel := context.WithTimeout(context.Background(), is.interval)
+	defer cancel()
+
+	due, err := is.payments.paymentRepo.GetInvoicesToRemind(ctx, time.Now().Add(is.remindBefore), is.batchSize)
+	if err != nil {
+		fmt.Printf("❌ Failed to get invoices to remind: %v\n", err)
+		return
+	}
+
+	reminded := 0
+	for i := range due {
+		marked, err := is.payments.paymentRepo.MarkInvoiceReminded(ctx, due[i].ID)
+		if err != nil {
+			fmt.Printf("❌ Failed to record reminder of invoice %s: %v\n", due[i].OrderID, err)
+			continue
+		}
+		if !marked {
+			continue // another replica sent it
+		}
+		if err := is.payments.publishInvoiceReminder(context.Background(), &due[i], false); err != nil {
+			fmt.Printf("⚠️ Failed to publish reminder of invoice %s: %v\n", due[i].OrderID, err)
+			continue
+		}
+		reminded++
+	}
+	if reminded > 0 {
+		fmt.Printf("🧾 Sent %d invoice due date reminders\n", reminded)
+	}
+}
+
+// markOverdue marks one batch of invoices past their due date OVERDUE and sends their overdue
+// reminder
+func (is *InvoiceScheduler) markOverdue() {
+	ctx, cancel := context.WithTimeout(context.Background(), is.interval)
+	defer cancel()
+
+	overdue, err := is.payments.paymentRepo.GetOverdueInvoices(ctx, is.batchSize)
+	if err != nil {
+		fmt.Printf("❌ Failed to get overdue invoices: %v\n", err)
+		return
+	}
+
+	marked := 0
+	for i := range overdue {
+		payment := &overdue[i]
+		changed, err := is.payments.paymentRepo.MarkOverdue(ctx, payment.ID)
+		if err != nil {
+			fmt.Printf("❌ Failed to mark invoice %s overdue: %v\n", payment.OrderID, err)
+			continue
+		}
+		if !changed {
+			continue // settled or marked by another replica meanwhile
+		}
+		marked++
+
+		eventCtx := context.Background()
+		is.payments.cacheSvc.InvalidatePaymentCache(eventCtx, payment.ID.String(), payment.OrderID, payment.UserID.String())
+		is.payments.eventSvc.PublishPaymentStatusUpdated(
+			eventCtx,
+			payment.ID.String(),
+			payment.OrderID,
+			payment.UserID.String(),
+			payment.ProductID,
+			string(models.PaymentStatusPending),
+			string(models.PaymentStatusOverdue),
+			payment.Amount,
+			payment.TotalAmount,
+			string(payment.PaymentMethod),
+			nil,
+		)
+		if err := is.payments.publishInvoiceReminder(eventCtx, payment, true); err != nil {
+			fmt.Printf("⚠️ Failed to publish overdue reminder of invoice %s: %v\n", payment.OrderID, err)
+		}
+	}
+	if marked > 0 {
+		fmt.Printf("⏰ Marked %d of %d invoices overdue\n", marked, len(overdue))
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"payment-service/internal/cache"
+	"payment-service/internal/repository"
+)
+
+// PaymentArchiver keeps the payments table bounded: settled payments older than archiveAfter
+// months are moved to payments_archive, where they stay readable through the archive
+// endpoints, and archived payments older than retention months are deleted for good.
+type PaymentArchiver struct {
+	paymentRepo  *repository.PaymentRepository
+	cacheSvc     cache.Cache
+	archiveAfter int // months
+	retention    int // months, 0 keeps archived payments forever
+	interval     time.Duration
+	batchSize    int
+	stop         chan struct{}
+}
+
+// NewPaymentArchiver creates an archiver running every interval, moving batchSize payments
+// per transaction (500 when not positive)
+func NewPaymentArchiver(paymentRepo *repository.PaymentRepository, cacheSvc cache.Cache, archiveAfter, retention int, interval time.Duration, batchSize int) *PaymentArchiver {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &PaymentArchiver{
+		paymentRepo:  paymentRepo,
+		cacheSvc:     cacheSvc,
+		archiveAfter: archiveAfter,
+		retention:    retention,
+		interval:     interval,
+		batchSize:    batchSize,
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start launches the background worker, which runs once right away
+func (pa *PaymentArchiver) Start() {
+	go func() {
+		ticker := time.NewTicker(pa.interval)
+		defer ticker.Stop()
+
+		for {
+			pa.run()
+			select {
+			case <-pa.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	fmt.Printf("🚀 Payment archival worker started (after %d months, retention %d months, interval: %s)\n", pa.archiveAfter, pa.retention, pa.interval)
+}
+
+// Stop stops the background worker
+func (pa *PaymentArchiver) Stop() {
+	close(pa.stop)
+}
+
+// run archives every due payment batch by batch, then purges the archive past retention
+func (pa *PaymentArchiver) run() {
+	ctx, cancel := context.WithTimeout(context.Background(), pa.interval)
+	defer cancel()
+
+	now := time.Now()
+	archived := 0
+	for ctx.Err() == nil {
+		batch, err := pa.paymentRepo.ArchivePayments(ctx, now.AddDate(0, -pa.archiveAfter, 0), pa.batchSize)
+		if err != nil {
+			fmt.Printf("❌ Failed to archive payments: %v\n", err)
+			break
+		}
+		for _, payment := range batch {
+			// Cached responses would keep serving the payment from the live endpoints
+			if err := pa.cacheSvc.InvalidatePaymentCache(ctx, payment.ID.String(), payment.OrderID, payment.UserID.String()); err != nil {
+				fmt.Printf("⚠️ Archived payment %s may be served from cache: %v\n", payment.OrderID, err)
+			}
+		}
+		archived += len(batch)
+		if len(batch) < pa.batchSize {
+			break
+		}
+	}
+	if archived > 0 {
+		fmt.Printf("📦 Archived %d payments older than %d months\n", archived, pa.archiveAfter)
+	}
+
+	if pa.retention <= 0 {
+		return
+	}
+	var purged int64
+	for ctx.Err() == nil {
+		deleted, err := pa.paymentRepo.PurgeArchivedPayments(ctx, now.AddDate(0, -pa.retention, 0), pa.batchSize)
+		if err != nil {
+			fmt.Printf("❌ Failed to purge archived payments: %v\n", err)
+			break
+		}
+		purged += deleted
+		if deleted < int64(pa.batchSize) {
+			break
+		}
+	}
+	if purged > 0 {
+		fmt.Printf("🗑️ Deleted %d archived payments past the %d month retention\n", purged, pa.retention)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"payment-service/internal/models"
+)
+
+// PaymentExpirer expires pending payments past their expiry time. Midtrans expires them on
+// its side eventually, but until it does their VA numbers and payment codes stay payable, so
+// they are expired there explicitly and their stock is released right away.
+type PaymentExpirer struct {
+	payments  *PaymentHandler
+	interval  time.Duration
+	batchSize int
+	stop      chan struct{}
+}
+
+// NewPaymentExpirer creates an expirer checking every interval
+func NewPaymentExpirer(payments *PaymentHandler, interval time.Duration) *PaymentExpirer {
+	return &PaymentExpirer{
+		payments:  payments,
+		interval:  interval,
+		batchSize: 100,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start launches the background worker
+func (pe *PaymentExpirer) Start() {
+	go func() {
+		ticker := time.NewTicker(pe.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-pe.stop:
+				return
+			case <-ticker.C:
+			}
+			pe.expireDue()
+		}
+	}()
+
+	fmt.Printf("🚀 Payment expiration worker started (interval: %s)\n", pe.interval)
+}
+
+// Stop stops the background worker
+func (pe *PaymentExpirer) Stop() {
+	close(pe.stop)
+}
+
+// expireDue expires one batch of overdue payments. Payments Midtrans refuses to expire, e.g.
+// because they were paid and the callback is late, are left for the callback and retried on
+// the next tick.
+func (pe *PaymentExpirer) expireDue() {
+	ctx, cancel := context.WithTimeout(context.Background(), pe.interval)
+	defer cancel()
+
+	due, err := pe.payments.paymentRepo.GetExpiredPayments(ctx, pe.batchSize)
+	if err != nil {
+		fmt.Printf("❌ Failed to get expired payments: %v\n", err)
+		return
+	}
+
+	expired := 0
+	for i := range due {
+		if pe.payments.closePending(context.Background(), &due[i], models.PaymentStatusExpired, "") {
+			expired++
+		}
+	}
+	if expired > 0 {
+		fmt.Printf("⌛ Expired %d of %d overdue payments\n", expired, len(due))
+	}
+}
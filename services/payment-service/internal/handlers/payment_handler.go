@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -12,9 +15,18 @@ import (
 	"payment-service/internal/cache"
 	"payment-service/internal/consumers"
 	"payment-service/internal/events"
+	"payment-service/internal/httpclient"
 	"payment-service/internal/models"
+	"payment-service/internal/pagination"
+	"payment-service/internal/redact"
+	"payment-service/internal/money"
 	"payment-service/internal/repository"
+	"payment-service/internal/retry"
+	"payment-service/internal/secrets"
+	"payment-service/internal/serviceauth"
 	"payment-service/internal/services"
+	"payment-service/internal/storage"
+	"payment-service/internal/timeutil"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -23,41 +35,107 @@ import (
 // PaymentHandler handles payment-related HTTP requests
 type PaymentHandler struct {
 	paymentRepo   *repository.PaymentRepository
-	midtransSvc   *services.MidtransService
-	eventSvc      *events.EventService
-	cacheSvc      *cache.CacheService
+	userProfiles  *repository.UserProfileRepository
+	callbackRepo  *repository.CallbackRepository
+	storeCredentials *repository.StoreCredentialsRepository
+	midtransSvc   services.PaymentGateway
+	credentialBox *secrets.Box // decrypts store server keys, nil without DATA_ENCRYPTION_KEY
+	eventSvc      events.EventPublisher
+	cacheSvc      cache.Cache
 	userServiceURL string
 	productServiceURL string
+	cacheTTLs     cache.TTLs // CACHE_TTL_PAYMENT, CACHE_TTL_USER_PAYMENTS
+	productCacheTTL     time.Duration // 0 disables caching product lookups
+	productChargeMaxAge time.Duration // oldest cached product a charge may rely on
+	chargeLockTTL  time.Duration // longest a checkout holds its lock, 0 disables it
+	chargeLockWait time.Duration // how long a concurrent checkout waits for the lock
 	validationConsumer *consumers.ValidationConsumer
+	serviceClient  *httpclient.Client
+	serviceAuth    *serviceauth.Issuer // signs calls to the other services, nil when disabled
+	callbackMaxAge time.Duration // 0 accepts callbacks of any age
+	charges        *services.ChargeBuilder
+	invoiceMaxDue  time.Duration // how far ahead invoices may be due
+	artifacts      storage.Storage // stores invoices and exports, nil without STORAGE_BACKEND
+	signedURLTTL   time.Duration   // validity of artifact download links
 }
 
 // NewPaymentHandler creates a new payment handler
 func NewPaymentHandler(
 	paymentRepo *repository.PaymentRepository,
-	midtransSvc *services.MidtransService,
-	eventSvc *events.EventService,
-	cacheSvc *cache.CacheService,
+	userProfiles *repository.UserProfileRepository,
+	callbackRepo *repository.CallbackRepository,
+	storeCredentials *repository.StoreCredentialsRepository,
+	midtransSvc services.PaymentGateway,
+	credentialBox *secrets.Box,
+	eventSvc events.EventPublisher,
+	cacheSvc cache.Cache,
 	userServiceURL, productServiceURL string,
 	validationConsumer *consumers.ValidationConsumer,
 ) *PaymentHandler {
+	// Lookups against User-Service and Product-Service (INTERNAL_SERVICE_*)
+	servicePolicy := retry.FromEnv("INTERNAL_SERVICE", retry.Policy{
+		MaxAttempts: 2,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Multiplier:  2,
+		Timeout:     10 * time.Second,
+	})
+	fmt.Printf("🔧 Retry policy %s\n", servicePolicy)
+
+	// Callbacks reporting a change older than MIDTRANS_CALLBACK_MAX_AGE are rejected
+	var callbackMaxAge time.Duration
+	if value := os.Getenv("MIDTRANS_CALLBACK_MAX_AGE"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			callbackMaxAge = parsed
+		} else {
+			fmt.Printf("⚠️ Ignoring invalid MIDTRANS_CALLBACK_MAX_AGE=%q\n", value)
+		}
+	}
+
+	productCacheTTL, productChargeMaxAge := productCacheConfigFromEnv()
+	chargeLockTTL, chargeLockWait := chargeLockConfigFromEnv()
+
 	return &PaymentHandler{
 		paymentRepo:       paymentRepo,
+		userProfiles:      userProfiles,
+		callbackRepo:      callbackRepo,
+		storeCredentials:  storeCredentials,
 		midtransSvc:       midtransSvc,
+		credentialBox:     credentialBox,
 		eventSvc:          eventSvc,
 		cacheSvc:          cacheSvc,
 		userServiceURL:    userServiceURL,
 		productServiceURL: productServiceURL,
+		cacheTTLs:         cache.TTLsFromEnv(),
+		productCacheTTL:   productCacheTTL,
+		productChargeMaxAge: productChargeMaxAge,
+		chargeLockTTL:     chargeLockTTL,
+		chargeLockWait:    chargeLockWait,
 		validationConsumer: validationConsumer,
+		serviceClient:     httpclient.New("internal_service", servicePolicy),
+		callbackMaxAge:    callbackMaxAge,
+		charges:           services.NewChargeBuilder(services.ChargeConfig{}),
+		invoiceMaxDue:     invoiceMaxDueFromEnv(),
 	}
 }
 
+// SetArtifactStore stores settled invoices and export links in artifacts, their download
+// links valid for signedURLTTL
+func (ph *PaymentHandler) SetArtifactStore(artifacts storage.Storage, signedURLTTL time.Duration) {
+	ph.artifacts = artifacts
+	ph.signedURLTTL = signedURLTTL
+}
+
+// SetChargeBuilder sets the builder computing discount, tax and rounding of new payments
+func (ph *PaymentHandler) SetChargeBuilder(charges *services.ChargeBuilder) {
+	ph.charges = charges
+}
+
 // CreatePayment creates a new payment using event-driven architecture
 func (ph *PaymentHandler) CreatePayment(c *gin.Context) {
 	var req models.CreatePaymentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request format",
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{
 			"details": err.Error(),
 		})
 		return
@@ -66,93 +144,426 @@ func (ph *PaymentHandler) CreatePayment(c *gin.Context) {
 	// Get user ID from header (set by API Gateway)
 	userIDStr := c.GetHeader("X-User-ID")
 	if userIDStr == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "User not authenticated",
-		})
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
 		return
 	}
 
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid user ID",
+		respondError(c, http.StatusBadRequest, "INVALID_USER_ID", nil)
+		return
+	}
+
+	// Retrying an order with another method: it must be the user's and not paid yet
+	var priorAttempts []models.Payment
+	if req.OrderRef != nil && *req.OrderRef != "" {
+		attempts, ok := ph.loadOrderAttempts(c, userID, *req.OrderRef)
+		if !ok {
+			return
+		}
+		for _, attempt := range attempts {
+			if attempt.Status == models.PaymentStatusSuccess {
+				respondError(c, http.StatusConflict, "ORDER_ALREADY_PAID", gin.H{
+					"details": "payment " + attempt.OrderID + " of this order succeeded",
+				})
+				return
+			}
+		}
+		priorAttempts = attempts
+	}
+
+	// One unit unless more are asked for, checked against the stock below
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	if quantity < 0 {
+		respondError(c, http.StatusBadRequest, "INVALID_QUANTITY", gin.H{
+			"details": "quantity must be at least 1",
+		})
+		return
+	}
+
+	// Calculate total amount (amounts are whole rupiah, checked for overflow)
+	if req.Amount <= 0 || req.AdminFee < 0 {
+		respondError(c, http.StatusBadRequest, "INVALID_AMOUNT", gin.H{
+			"details": "amount must be positive and admin_fee must not be negative",
+		})
+		return
+	}
+	charge, err := ph.charges.Build(money.New(req.Amount, money.IDR), money.New(req.AdminFee, money.IDR))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_AMOUNT", gin.H{
+			"details": err.Error(),
 		})
 		return
 	}
+	totalAmount := charge.Total.Minor
 
-	// Calculate total amount (amounts are in rupiah)
-	totalAmount := req.Amount + req.AdminFee
+	// Invoices are paid by a due date instead of at Midtrans
+	var dueDate *time.Time
+	if req.PaymentMethod == models.PaymentMethodInvoice {
+		parsed, ok := ph.parseInvoiceDueDate(c, req.DueDate)
+		if !ok {
+			return
+		}
+		dueDate = &parsed
+	}
 
 	// Generate order ID and payment ID
 	orderID := fmt.Sprintf("Order_%d", time.Now().UnixNano())
 	paymentID := uuid.New().String()
 	
 	// Log payment details for debugging
-	fmt.Printf("🔍 Event-Driven Payment Details - Amount: %d, AdminFee: %d, TotalAmount: %d, PaymentMethod: %s\n", 
-		req.Amount, req.AdminFee, totalAmount, req.PaymentMethod)
+	fmt.Printf("🔍 Event-Driven Payment Details - Quantity: %d, Amount: %d, AdminFee: %d, TotalAmount: %d, PaymentMethod: %s\n", 
+		quantity, req.Amount, charge.AdminFee.Minor, totalAmount, req.PaymentMethod)
 
 	// Get user data from user service (for Midtrans)
 	fmt.Printf("🔍 Getting user data for userID: %s from service: %s\n", userID.String(), ph.userServiceURL)
 	user, err := ph.getUserFromService(userID)
 	if err != nil {
 		fmt.Printf("❌ Failed to get user data: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to get user data",
+		respondError(c, http.StatusInternalServerError, "USER_LOOKUP_FAILED", gin.H{
 			"details": err.Error(),
 		})
 		return
 	}
-	fmt.Printf("✅ Successfully got user data: %+v\n", user)
+	fmt.Printf("✅ Successfully got user data for userID: %s\n", user.ID)
 
-	// Get product data from product service (for Midtrans)
-	product, err := ph.getProductFromService(*req.ProductID)
+	// Get product data from product service (for Midtrans), a cached copy only when recent
+	product, err := ph.getProductForCharge(*req.ProductID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Product not found",
-		})
+		respondError(c, http.StatusBadRequest, "PRODUCT_NOT_FOUND", nil)
 		return
 	}
 
-	// Check if product is active and has stock
-	if !product.IsActive {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Product is not active",
+	// Products with variants are bought one variant at a time, products without them ignore
+	// variant_id so older clients keep working
+	var variant *models.ProductVariant
+	if product.HasVariants() {
+		if req.VariantID == nil {
+			respondError(c, http.StatusBadRequest, "VARIANT_REQUIRED", gin.H{
+				"details": "the product has variants, choose one with variant_id",
+			})
+			return
+		}
+		variant = product.Variant(*req.VariantID)
+		if variant == nil {
+			respondError(c, http.StatusBadRequest, "VARIANT_NOT_FOUND", nil)
+			return
+		}
+	}
+
+	// From here to the Midtrans charge the checkouts of the product (variant) run one at a
+	// time, and price and stock are read from Product-Service's database rather than a cache,
+	// so the amount in item_details is the price at charge time and the stock isn't sold twice
+	var variantID *uuid.UUID
+	if variant != nil {
+		variantID = &variant.ID
+	}
+	release, ok := ph.lockCharge(c, product.ID, variantID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	// Buyers are signed in, so member-only pricing rules apply
+	if !ph.checkAmount(c, product, variant, quantity, req.Amount, true) {
+		return
+	}
+
+	// Check if product is active and has stock for the quantity before charging, the product
+	// itself may come from Product-Service's cache so ask the availability endpoint for
+	// current stock. Without an answer nothing is charged, the cached stock may be gone.
+	availability, err := ph.getProductAvailability(*req.ProductID, variantID, quantity)
+	if err != nil {
+		fmt.Printf("⚠️ Availability check failed for product %s: %v\n", product.ID, err)
+		respondError(c, http.StatusServiceUnavailable, "STOCK_UNAVAILABLE", gin.H{
+			"details": "the current stock could not be verified, try again shortly",
 		})
 		return
 	}
+	if availability.IsActive != product.IsActive {
+		ph.dropStaleProduct(product.ID, "active state changed")
+	}
 
-	if product.Stock <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Product is out of stock",
+	if !availability.IsActive {
+		respondError(c, http.StatusBadRequest, "PRODUCT_INACTIVE", nil)
+		return
+	}
+
+	// Units of pending payments are still in Product-Service's stock until they're paid
+	pending, err := ph.paymentRepo.PendingQuantity(c.Request.Context(), product.ID, variantID)
+	if err != nil {
+		fmt.Printf("⚠️ Pending quantity of product %s unavailable: %v\n", product.ID, err)
+		respondError(c, http.StatusServiceUnavailable, "STOCK_UNAVAILABLE", gin.H{
+			"details": "the current stock could not be verified, try again shortly",
 		})
 		return
 	}
+	if pending > 0 {
+		availability.MaxQuantity = max(availability.MaxQuantity-pending, 0)
+		availability.InStock = quantity <= availability.MaxQuantity
+	}
+
+	if !availability.InStock {
+		if availability.MaxQuantity > 0 {
+			respondError(c, http.StatusBadRequest, "INSUFFICIENT_STOCK", gin.H{
+				"details":      fmt.Sprintf("only %d available", availability.MaxQuantity),
+				"max_quantity": availability.MaxQuantity,
+			})
+			return
+		}
+		respondError(c, http.StatusBadRequest, "OUT_OF_STOCK", nil)
+		return
+	}
 
 	// Create payment record (without Midtrans data yet)
 	payment := &models.Payment{
 		ID:            uuid.MustParse(paymentID),
 		OrderID:       orderID,
+		OrderRef:      orderID,
 		UserID:        userID,
 		ProductID:     req.ProductID,
+		VariantID:     variantID,
+		Quantity:      quantity,
 		Amount:        req.Amount,
-		AdminFee:      req.AdminFee,
+		AdminFee:      charge.AdminFee.Minor,
+		DiscountAmount: charge.Discount.Minor,
+		TaxAmount:     charge.Tax.Minor,
+		RoundingAmount: charge.Rounding.Minor,
 		TotalAmount:   totalAmount,
+		Currency:      charge.Total.Currency,
 		PaymentMethod: req.PaymentMethod,
 		PaymentType:   "midtrans",
 		Status:        models.PaymentStatusPending,
 		Notes:         req.Notes,
 		BankType:      req.BankType,  // Store bank type for bank transfer payments
 		StoreType:     req.StoreType, // Store store type for cstore payments
+		DueDate:       dueDate,
+	}
+
+	if priorAttempts != nil {
+		payment.OrderRef = *req.OrderRef
+	}
+
+	if payment.IsInvoice() {
+		ph.createInvoice(c, payment, product, priorAttempts)
+		return
+	}
+
+	updatedPayment, midtransResp, ok := ph.chargePayment(c, payment, user, product)
+	if !ok {
+		return
+	}
+
+	// The new attempt replaces the pending ones, so an abandoned VA can't be paid as well
+	ph.cancelPriorAttempts(c, priorAttempts)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    chargeResponseData(updatedPayment, midtransResp),
+	})
+}
+
+// GetOrderAttempts lists the attempts to pay an order (GET /payments/orders/:order_ref)
+func (ph *PaymentHandler) GetOrderAttempts(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return
+	}
+
+	orderRef := c.Param("order_ref")
+	attempts, ok := ph.loadOrderAttempts(c, userID, orderRef)
+	if !ok {
+		return
+	}
+
+	response := models.OrderAttemptsResponse{
+		OrderRef: orderRef,
+		Attempts: make([]models.PaymentResponse, len(attempts)),
+	}
+	for i, attempt := range attempts {
+		response.Attempts[i] = attempt.ToResponse()
+		if attempt.MidtransAction != nil {
+			var actions []models.MidtransAction
+			if err := json.Unmarshal([]byte(*attempt.MidtransAction), &actions); err == nil {
+				response.Attempts[i].Actions = actions
+			}
+		}
+		if attempt.Status == models.PaymentStatusSuccess {
+			response.Paid = true
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// loadOrderAttempts loads the attempts of an order belonging to the user, newest first. Orders
+// of other users are reported as not found. On failure the error response is written.
+func (ph *PaymentHandler) loadOrderAttempts(c *gin.Context, userID uuid.UUID, orderRef string) ([]models.Payment, bool) {
+	attempts, err := ph.paymentRepo.GetByOrderRef(c.Request.Context(), orderRef)
+	if err != nil {
+		fmt.Printf("❌ Failed to get attempts of order %s: %v\n", orderRef, err)
+		respondError(c, http.StatusInternalServerError, "ORDER_LOOKUP_FAILED", nil)
+		return nil, false
+	}
+	if len(attempts) == 0 || attempts[0].UserID != userID {
+		respondError(c, http.StatusNotFound, "ORDER_NOT_FOUND", nil)
+		return nil, false
+	}
+	return attempts, true
+}
+
+// userCancelledReason is the failure_reason of payments cancelled by their buyer
+const userCancelledReason = "user_cancelled"
+
+// CancelPayment lets the buyer cancel a pending payment (POST /payments/:id/cancel). It is
+// cancelled at Midtrans and here; the payment.failed it publishes releases the stock held
+// for the order. Payments that were paid or closed meanwhile are a conflict.
+func (ph *PaymentHandler) CancelPayment(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return
+	}
+
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PAYMENT_ID", nil)
+		return
+	}
+
+	payment, err := ph.paymentRepo.GetByID(c.Request.Context(), paymentID)
+	if err != nil || payment.UserID != userID {
+		respondError(c, http.StatusNotFound, "PAYMENT_NOT_FOUND", nil)
+		return
+	}
+
+	if payment.Status != models.PaymentStatusPending {
+		respondError(c, http.StatusConflict, "PAYMENT_NOT_PENDING", gin.H{
+			"details": "payment is " + string(payment.Status),
+		})
+		return
+	}
+
+	if !ph.closePending(eventContext(c), payment, models.PaymentStatusCancelled, userCancelledReason) {
+		// A callback closed it first, or Midtrans refused, e.g. because it was just paid
+		if current, err := ph.paymentRepo.GetByID(c.Request.Context(), paymentID); err == nil && current.Status != models.PaymentStatusPending {
+			respondError(c, http.StatusConflict, "PAYMENT_NOT_PENDING", gin.H{
+				"details": "payment is " + string(current.Status),
+			})
+			return
+		}
+		respondError(c, http.StatusBadGateway, "PAYMENT_CANCEL_FAILED", nil)
+		return
+	}
+
+	payment.Status = models.PaymentStatusCancelled
+	payment.UpdatedAt = time.Now()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    payment.ToResponse(),
+	})
+}
+
+// cancelPriorAttempts cancels the pending attempts of an order at Midtrans and here. Attempts
+// Midtrans refuses to cancel stay pending; if one is paid anyway it can't mark the order paid
+// twice, see PaymentRepository.UpdateStatus.
+func (ph *PaymentHandler) cancelPriorAttempts(c *gin.Context, attempts []models.Payment) {
+	for i := range attempts {
+		if attempts[i].Status == models.PaymentStatusPending {
+			ph.closePending(eventContext(c), &attempts[i], models.PaymentStatusCancelled, "")
+		}
+	}
+}
+
+// closePending cancels or expires (status) a pending payment at Midtrans so it can no longer
+// be paid, then here, and publishes the change; payment.failed carries reason, or the status
+// without one. Transactions Midtrans doesn't know are closed here only, as are invoices; ones
+// it refuses to close stay pending. It reports whether the payment was closed.
+func (ph *PaymentHandler) closePending(ctx context.Context, payment *models.Payment, status models.PaymentStatus, reason string) bool {
+	if !payment.IsInvoice() {
+		gateway, err := ph.gatewayFor(ctx, payment.StoreID)
+		if err != nil {
+			fmt.Printf("⚠️ Failed to load Midtrans credentials to close order %s: %v\n", payment.OrderID, err)
+			return false
+		}
+
+		closeTransaction := gateway.CancelPayment
+		if status == models.PaymentStatusExpired {
+			closeTransaction = gateway.ExpirePayment
+		}
+		if err := closeTransaction(payment.OrderID); err != nil && !errors.Is(err, services.ErrTransactionNotFound) {
+			fmt.Printf("⚠️ Midtrans didn't close order %s as %s: %v\n", payment.OrderID, status, err)
+			return false
+		}
+	}
+
+	closed, err := ph.paymentRepo.ClosePending(ctx, payment.ID, status)
+	if err != nil {
+		fmt.Printf("❌ Failed to mark payment %s %s: %v\n", payment.ID, status, err)
+		return false
+	}
+	if !closed {
+		return false // a callback got there first
+	}
+	fmt.Printf("🚫 Order %s of %s is now %s\n", payment.OrderID, payment.OrderRef, status)
+	if reason == "" {
+		reason = string(status)
+	}
+
+	ph.cacheSvc.InvalidatePaymentCache(ctx, payment.ID.String(), payment.OrderID, payment.UserID.String())
+	ph.eventSvc.PublishPaymentStatusUpdated(
+		ctx,
+		payment.ID.String(),
+		payment.OrderID,
+		payment.UserID.String(),
+		payment.ProductID,
+		string(models.PaymentStatusPending),
+		string(status),
+		payment.Amount,
+		payment.TotalAmount,
+		string(payment.PaymentMethod),
+		nil,
+	)
+	ph.eventSvc.PublishPaymentFailed(
+		ctx,
+		payment.ID.String(),
+		payment.OrderID,
+		payment.UserID.String(),
+		payment.ProductID,
+		payment.Amount,
+		payment.TotalAmount,
+		string(payment.PaymentMethod),
+		reason,
+	)
+	return true
+}
+
+// chargePayment creates the Midtrans charge for a new payment, stores it with the Midtrans
+// data, caches it and publishes payment.created. On failure the error response is written.
+func (ph *PaymentHandler) chargePayment(c *gin.Context, payment *models.Payment, user *models.UserProfile, product *models.Product) (*models.Payment, *services.MidtransChargeResponse, bool) {
+	// A payment not retrying an earlier order starts its own
+	if payment.OrderRef == "" {
+		payment.OrderRef = payment.OrderID
+	}
+
+	// Charge with the Midtrans keys of the product's store, if it has its own
+	payment.StoreID = product.StoreID
+	gateway, err := ph.gatewayFor(c.Request.Context(), payment.StoreID)
+	if err != nil {
+		fmt.Printf("❌ Failed to load Midtrans credentials for store %v: %v\n", payment.StoreID, err)
+		respondError(c, http.StatusInternalServerError, "STORE_SETTINGS_FAILED", nil)
+		return nil, nil, false
 	}
 
 	// Create payment with Midtrans first (before saving to database)
-	midtransResp, err := ph.midtransSvc.CreatePayment(payment, user, product)
+	midtransResp, err := gateway.CreatePayment(payment, user, product)
 	if err != nil {
 		// Check if it's a 505 or 500 error from Midtrans (VA number creation failed or system issues)
 		if strings.Contains(err.Error(), "505") || 
@@ -160,29 +571,21 @@ func (ph *PaymentHandler) CreatePayment(c *gin.Context) {
 		   strings.Contains(err.Error(), "Unable to create va_number") ||
 		   strings.Contains(err.Error(), "system is recovering") ||
 		   strings.Contains(err.Error(), "service unavailable") {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"success": false,
-				"error":   "Payment method temporarily unavailable",
-				"message": "Metode pembayaran sedang maintenance, silakan pilih metode lain (BNI, BCA, BRI, Mandiri, GoPay, QRIS, atau Credit Card)",
+			respondErrorWithMessage(c, http.StatusServiceUnavailable, "PAYMENT_METHOD_UNAVAILABLE", "PAYMENT_METHOD_UNAVAILABLE_HINT", gin.H{
 				"details": err.Error(),
 			})
 		} else {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error":   "Failed to create payment with Midtrans",
+			respondError(c, http.StatusBadRequest, "MIDTRANS_CHARGE_FAILED", gin.H{
 				"details": err.Error(),
 			})
 		}
-		return
+		return nil, nil, false
 	}
 
 	// Save payment to database only after successful Midtrans response
-	if err := ph.paymentRepo.Create(payment); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to create payment",
-		})
-		return
+	if err := ph.paymentRepo.Create(c.Request.Context(), payment); err != nil {
+		respondError(c, http.StatusInternalServerError, "PAYMENT_CREATE_FAILED", nil)
+		return nil, nil, false
 	}
 
 	// Update payment with Midtrans response
@@ -198,18 +601,18 @@ func (ph *PaymentHandler) CreatePayment(c *gin.Context) {
 	if len(midtransResp.VANumbers) > 0 {
 		midtransData["va_number"] = midtransResp.VANumbers[0].VANumber
 		midtransData["bank_type"] = midtransResp.VANumbers[0].Bank
-		fmt.Printf("🔍 Storing VA Number: %s, Bank: %s\n", midtransResp.VANumbers[0].VANumber, midtransResp.VANumbers[0].Bank)
+		fmt.Printf("🔍 Storing VA Number: %s, Bank: %s\n", redact.Tail(midtransResp.VANumbers[0].VANumber), midtransResp.VANumbers[0].Bank)
 	} else {
 		fmt.Printf("⚠️ No VA Numbers found in Midtrans response\n")
 	}
 
 	if midtransResp.PaymentCode != "" {
 		midtransData["payment_code"] = midtransResp.PaymentCode
-		fmt.Printf("🔍 Storing Payment Code: %s\n", midtransResp.PaymentCode)
+		fmt.Printf("🔍 Storing Payment Code: %s\n", redact.Tail(midtransResp.PaymentCode))
 		// For cstore payments, also store payment_code as va_number for easier copying
 		if payment.PaymentMethod == models.PaymentMethodCstore {
 			midtransData["va_number"] = midtransResp.PaymentCode
-			fmt.Printf("🔍 Storing Payment Code as VA Number for cstore: %s\n", midtransResp.PaymentCode)
+			fmt.Printf("🔍 Storing Payment Code as VA Number for cstore: %s\n", redact.Tail(midtransResp.PaymentCode))
 		}
 	} else {
 		fmt.Printf("⚠️ No Payment Code found in Midtrans response\n")
@@ -221,40 +624,14 @@ func (ph *PaymentHandler) CreatePayment(c *gin.Context) {
 	}
 
 	if midtransResp.ExpiryTime != "" {
-		// Try different time formats from Midtrans
-		timeFormats := []string{
-			time.RFC3339,                    // "2006-01-02T15:04:05Z07:00"
-			"2006-01-02 15:04:05",          // "2025-09-29 20:47:00"
-			"2006-01-02T15:04:05",          // "2025-09-29T20:47:00"
-		}
-		
-		var expiryTime time.Time
-		var err error
-		for _, format := range timeFormats {
-			expiryTime, err = time.Parse(format, midtransResp.ExpiryTime)
-			if err == nil {
-				midtransData["expiry_time"] = expiryTime
-				break
-			}
+		if expiryTime, err := timeutil.ParseMidtrans(midtransResp.ExpiryTime); err == nil {
+			midtransData["expiry_time"] = expiryTime
 		}
 	}
 
 	if midtransResp.PaidAt != "" {
-		// Try different time formats from Midtrans
-		timeFormats := []string{
-			time.RFC3339,                    // "2006-01-02T15:04:05Z07:00"
-			"2006-01-02 15:04:05",          // "2025-09-29 20:47:00"
-			"2006-01-02T15:04:05",          // "2025-09-29T20:47:00"
-		}
-		
-		var paidAt time.Time
-		var err error
-		for _, format := range timeFormats {
-			paidAt, err = time.Parse(format, midtransResp.PaidAt)
-			if err == nil {
-				midtransData["paid_at"] = paidAt
-				break
-			}
+		if paidAt, err := timeutil.ParseMidtrans(midtransResp.PaidAt); err == nil {
+			midtransData["paid_at"] = paidAt
 		}
 	}
 
@@ -267,36 +644,26 @@ func (ph *PaymentHandler) CreatePayment(c *gin.Context) {
 	}
 
 	// Log the data being saved
-	fmt.Printf("🔍 Updating payment with Midtrans data: %+v\n", midtransData)
+	fmt.Printf("🔍 Updating payment %s with Midtrans data\n", payment.ID)
 	
-	if err := ph.paymentRepo.UpdateMidtransData(payment.ID, midtransData); err != nil {
+	updatedPayment, err := ph.paymentRepo.UpdateMidtransData(c.Request.Context(), payment.ID, midtransData)
+	if err != nil {
 		fmt.Printf("❌ Failed to update payment with Midtrans data: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to update payment with Midtrans data",
-		})
-		return
+		respondError(c, http.StatusInternalServerError, "PAYMENT_UPDATE_FAILED", nil)
+		return nil, nil, false
 	}
 	
 	fmt.Printf("✅ Successfully updated payment with Midtrans data\n")
 
-	// Wait for VA number to be saved in database with retry mechanism
-	updatedPayment, err := ph.waitForPaymentData(payment.ID, 5, 1*time.Second)
-	if err != nil {
-		fmt.Printf("⚠️ Failed to get updated payment data after retries: %v\n", err)
-		// Fallback to original payment data
-		updatedPayment = payment
-	}
-
 	// Cache payment data
 	paymentResponse := updatedPayment.ToResponse()
 	paymentResponse.Actions = ph.convertMidtransActions(midtransResp.Actions)
 	
-	ph.cacheSvc.SetPayment(payment.ID.String(), paymentResponse, 1*time.Hour)
-	ph.cacheSvc.SetPaymentByOrderID(payment.OrderID, paymentResponse, 1*time.Hour)
+	ph.cacheSvc.SetPaymentEntries(c.Request.Context(), payment.ID.String(), payment.OrderID, paymentResponse, ph.cacheTTLs.Payment)
 
 	// Publish payment created event (optional for other services)
 	ph.eventSvc.PublishPaymentCreated(
+		eventContext(c),
 		payment.ID.String(),
 		payment.OrderID,
 		payment.UserID.String(),
@@ -308,57 +675,75 @@ func (ph *PaymentHandler) CreatePayment(c *gin.Context) {
 	)
 
 	// Invalidate user payments cache
-	ph.cacheSvc.DeleteUserPayments(payment.UserID.String())
+	ph.cacheSvc.DeleteUserPayments(c.Request.Context(), payment.UserID.String())
 
-	// Use updated payment data for response
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data": gin.H{
-			"payment_id":     updatedPayment.ID,
-			"order_id":       updatedPayment.OrderID,
-			"amount":         updatedPayment.TotalAmount,
-			"payment_method": updatedPayment.PaymentMethod,
-			"status":         updatedPayment.Status,
-			"actions":        midtransResp.Actions,
-			"va_number":      updatedPayment.VANumber,
-			"bank_type":      updatedPayment.BankType,
-			"payment_code":   updatedPayment.PaymentCode,
-			"expiry_time":    updatedPayment.ExpiryTime,
-			"redirect_url":   updatedPayment.SnapRedirectURL,
-		},
-	})
+	return updatedPayment, midtransResp, true
+}
+
+// chargeResponseData is the payload returned after a successful charge
+func chargeResponseData(updatedPayment *models.Payment, midtransResp *services.MidtransChargeResponse) gin.H {
+	return gin.H{
+		"payment_id":     updatedPayment.ID,
+		"order_id":       updatedPayment.OrderID,
+		"amount":         updatedPayment.TotalAmount,
+		"payment_method": updatedPayment.PaymentMethod,
+		"status":         updatedPayment.Status,
+		"actions":        midtransResp.Actions,
+		"va_number":      updatedPayment.VANumber,
+		"bank_type":      updatedPayment.BankType,
+		"payment_code":   updatedPayment.PaymentCode,
+		"expiry_time":    updatedPayment.ExpiryTime,
+		"redirect_url":   updatedPayment.SnapRedirectURL,
+	}
+}
+
+// eventContext is used to publish events about changes already committed: it keeps the
+// request's values but not its cancellation, so a client going away can't drop the event
+func eventContext(c *gin.Context) context.Context {
+	return context.WithoutCancel(c.Request.Context())
 }
 
 // GetPayment retrieves a payment by ID
 func (ph *PaymentHandler) GetPayment(c *gin.Context) {
+	if paymentResponse, ok := ph.paymentByID(c); ok {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    paymentResponse,
+		})
+	}
+}
+
+// GetPaymentV2 retrieves a payment by ID in the v2 response shape
+func (ph *PaymentHandler) GetPaymentV2(c *gin.Context) {
+	if paymentResponse, ok := ph.paymentByID(c); ok {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    paymentResponse.V2(),
+		})
+	}
+}
+
+// paymentByID loads the payment of the :id parameter, from cache when possible.
+// It writes the error response and returns false when the payment can't be loaded.
+func (ph *PaymentHandler) paymentByID(c *gin.Context) (models.PaymentResponse, bool) {
 	paymentIDStr := c.Param("id")
 	paymentID, err := uuid.Parse(paymentIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid payment ID",
-		})
-		return
+		respondError(c, http.StatusBadRequest, "INVALID_PAYMENT_ID", nil)
+		return models.PaymentResponse{}, false
 	}
 
 	// Try to get from cache first
 	var paymentResponse models.PaymentResponse
-	if err := ph.cacheSvc.GetPayment(paymentID.String(), &paymentResponse); err == nil {
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"data":    paymentResponse,
-		})
-		return
+	if err := ph.cacheSvc.GetPayment(c.Request.Context(), paymentID.String(), &paymentResponse); err == nil {
+		return paymentResponse, true
 	}
 
 	// Get from database
-	payment, err := ph.paymentRepo.GetByID(paymentID)
+	payment, err := ph.paymentRepo.GetByID(c.Request.Context(), paymentID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error":   "Payment not found",
-		})
-		return
+		respondError(c, http.StatusNotFound, "PAYMENT_NOT_FOUND", nil)
+		return models.PaymentResponse{}, false
 	}
 
 	paymentResponse = payment.ToResponse()
@@ -372,36 +757,47 @@ func (ph *PaymentHandler) GetPayment(c *gin.Context) {
 	}
 
 	// Cache the response
-	ph.cacheSvc.SetPayment(payment.ID.String(), paymentResponse, 1*time.Hour)
+	ph.cacheSvc.SetPayment(c.Request.Context(), payment.ID.String(), paymentResponse, ph.cacheTTLs.Payment)
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    paymentResponse,
-	})
+	return paymentResponse, true
 }
 
 // GetPaymentByOrderID retrieves a payment by order ID
 func (ph *PaymentHandler) GetPaymentByOrderID(c *gin.Context) {
-	orderID := c.Param("order_id")
-
-	// Try to get from cache first
-	var paymentResponse models.PaymentResponse
-	if err := ph.cacheSvc.GetPaymentByOrderID(orderID, &paymentResponse); err == nil {
+	if paymentResponse, ok := ph.paymentByOrderID(c); ok {
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"data":    paymentResponse,
 		})
-		return
+	}
+}
+
+// GetPaymentByOrderIDV2 retrieves a payment by order ID in the v2 response shape
+func (ph *PaymentHandler) GetPaymentByOrderIDV2(c *gin.Context) {
+	if paymentResponse, ok := ph.paymentByOrderID(c); ok {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    paymentResponse.V2(),
+		})
+	}
+}
+
+// paymentByOrderID loads the payment of the :order_id parameter, from cache when possible.
+// It writes the error response and returns false when the payment can't be loaded.
+func (ph *PaymentHandler) paymentByOrderID(c *gin.Context) (models.PaymentResponse, bool) {
+	orderID := c.Param("order_id")
+
+	// Try to get from cache first
+	var paymentResponse models.PaymentResponse
+	if err := ph.cacheSvc.GetPaymentByOrderID(c.Request.Context(), orderID, &paymentResponse); err == nil {
+		return paymentResponse, true
 	}
 
 	// Get from database
-	payment, err := ph.paymentRepo.GetByOrderID(orderID)
+	payment, err := ph.paymentRepo.GetByOrderID(c.Request.Context(), orderID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error":   "Payment not found",
-		})
-		return
+		respondError(c, http.StatusNotFound, "PAYMENT_NOT_FOUND", nil)
+		return models.PaymentResponse{}, false
 	}
 
 	paymentResponse = payment.ToResponse()
@@ -415,12 +811,9 @@ func (ph *PaymentHandler) GetPaymentByOrderID(c *gin.Context) {
 	}
 
 	// Cache the response
-	ph.cacheSvc.SetPaymentByOrderID(payment.OrderID, paymentResponse, 1*time.Hour)
+	ph.cacheSvc.SetPaymentByOrderID(c.Request.Context(), payment.OrderID, paymentResponse, ph.cacheTTLs.Payment)
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    paymentResponse,
-	})
+	return paymentResponse, true
 }
 
 // GetUserPayments retrieves payments for a user
@@ -428,30 +821,31 @@ func (ph *PaymentHandler) GetUserPayments(c *gin.Context) {
 	// Get user ID from header (set by API Gateway)
 	userIDStr := c.GetHeader("X-User-ID")
 	if userIDStr == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "User not authenticated",
-		})
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
 		return
 	}
 
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid user ID",
-		})
+		respondError(c, http.StatusBadRequest, "INVALID_USER_ID", nil)
 		return
 	}
 
 	// Parse query parameters
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	query, err := parseUserPaymentQuery(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_QUERY", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+	query.UserID = &userID
+	page, limit := query.Page, query.Limit
 
-	// Try to get from cache first
-	cacheKey := fmt.Sprintf("%s_%d_%d", userID.String(), page, limit)
+	// Try to get from cache first (every filter is part of the key)
+	cacheKey := pagination.CacheKey(userID.String(), query)
 	var paymentsResponse models.PaymentListResponse
-	if err := ph.cacheSvc.GetUserPayments(cacheKey, &paymentsResponse); err == nil {
+	if err := ph.cacheSvc.GetUserPayments(c.Request.Context(), cacheKey, &paymentsResponse); err == nil {
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"data":    paymentsResponse,
@@ -460,12 +854,9 @@ func (ph *PaymentHandler) GetUserPayments(c *gin.Context) {
 	}
 
 	// Get from database
-	payments, total, err := ph.paymentRepo.GetByUserID(userID, page, limit)
+	payments, total, err := ph.paymentRepo.GetAll(c.Request.Context(), query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to get payments",
-		})
+		respondError(c, http.StatusInternalServerError, "PAYMENTS_FETCH_FAILED", nil)
 		return
 	}
 
@@ -483,81 +874,220 @@ func (ph *PaymentHandler) GetUserPayments(c *gin.Context) {
 		}
 	}
 
-	paymentsResponse = models.PaymentListResponse{
-		Payments: paymentResponses,
-		Total:    total,
-		Page:     page,
-		Limit:    limit,
-		HasMore:  int64(page*limit) < total,
+	paymentsResponse = models.PaymentListResponse{
+		Payments: paymentResponses,
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+		HasMore:  int64(page*limit) < total,
+	}
+
+	// Cache the response
+	ph.cacheSvc.SetUserPayments(c.Request.Context(), cacheKey, paymentsResponse, ph.cacheTTLs.UserPayments)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    paymentsResponse,
+	})
+}
+
+// parseUserPaymentQuery reads the pagination and search filters of GET /payments/user:
+// status, payment_method, order_id, from/to (YYYY-MM-DD, inclusive, or RFC3339) and q
+func parseUserPaymentQuery(c *gin.Context) (models.PaymentQuery, error) {
+	query := models.PaymentQuery{
+		Search: strings.TrimSpace(c.Query("q")),
+	}
+
+	query.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	query.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 || query.Limit > 100 {
+		query.Limit = 10
+	}
+
+	if value := c.Query("status"); value != "" {
+		status := models.PaymentStatus(strings.ToUpper(value))
+		if !status.IsValid() {
+			return query, fmt.Errorf("unknown status %q", value)
+		}
+		query.Status = &status
+	}
+
+	if value := c.Query("payment_method"); value != "" {
+		method := models.PaymentMethod(strings.ToLower(value))
+		if !method.IsValid() {
+			return query, fmt.Errorf("unknown payment_method %q", value)
+		}
+		query.PaymentMethod = &method
+	}
+
+	if value := strings.TrimSpace(c.Query("order_id")); value != "" {
+		query.OrderID = &value
+	}
+
+	if value := c.Query("from"); value != "" {
+		from, _, err := parseDateParam(value)
+		if err != nil {
+			return query, fmt.Errorf("invalid from: %w", err)
+		}
+		query.From = &from
+	}
+
+	if value := c.Query("to"); value != "" {
+		to, dateOnly, err := parseDateParam(value)
+		if err != nil {
+			return query, fmt.Errorf("invalid to: %w", err)
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1) // Include the whole day
+		}
+		query.To = &to
+	}
+
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return query, fmt.Errorf("from must be before to")
 	}
 
-	// Cache the response
-	ph.cacheSvc.SetUserPayments(cacheKey, paymentsResponse, 30*time.Minute)
+	if len(query.Search) > 100 {
+		return query, fmt.Errorf("q must be at most 100 characters")
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    paymentsResponse,
-	})
+	return query, nil
+}
+
+// parseDateParam parses a YYYY-MM-DD date or an RFC3339 timestamp
+func parseDateParam(value string) (time.Time, bool, error) {
+	if date, err := timeutil.ParseDate(value); err == nil {
+		return date, true, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("expected YYYY-MM-DD or RFC3339, got %q", value)
+	}
+	return parsed, false, nil
 }
 
 // MidtransCallback handles Midtrans webhook callback
 func (ph *PaymentHandler) MidtransCallback(c *gin.Context) {
+	ph.handleMidtransCallback(c, nil)
+}
+
+// handleMidtransCallback verifies a callback and applies the order's status. The status is
+// asked from Midtrans rather than trusted from the callback, except for simulated callbacks,
+// which pass the status they simulate as simulated.
+func (ph *PaymentHandler) handleMidtransCallback(c *gin.Context, simulated *services.MidtransStatusResponse) {
 	var req models.MidtransCallbackRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		fmt.Printf("❌ Invalid callback format: %v\n", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid callback format",
-		})
+		respondError(c, http.StatusBadRequest, "INVALID_CALLBACK", nil)
 		return
 	}
 
 	// Log callback received
 	fmt.Printf("📞 Midtrans callback received for order: %s, status: %s\n", req.OrderID, req.TransactionStatus)
 
-	// Verify signature
-	if !ph.midtransSvc.VerifySignature(req.OrderID, req.StatusCode, req.GrossAmount, req.SignatureKey) {
+	// Verify signature with the keys the order was charged with
+	gateway, err := ph.gatewayForOrder(c.Request.Context(), req.OrderID)
+	if err != nil {
+		fmt.Printf("❌ Failed to load Midtrans credentials for order %s: %v\n", req.OrderID, err)
+		respondError(c, http.StatusInternalServerError, "STORE_SETTINGS_FAILED", nil)
+		return
+	}
+	if !gateway.VerifySignature(req.OrderID, req.StatusCode, req.GrossAmount, req.SignatureKey) {
 		fmt.Printf("❌ Invalid signature for order: %s\n", req.OrderID)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid signature",
+		respondError(c, http.StatusBadRequest, "INVALID_SIGNATURE", nil)
+		return
+	}
+
+	// Reject stale notifications, a valid signature alone doesn't stop replays. The age counts
+	// from the reported change (settlement, expiry, refund), not from the charge.
+	if ph.callbackMaxAge > 0 {
+		now := time.Now()
+		eventTime, ok := req.EventTime(now)
+		if !ok || now.Sub(eventTime) > ph.callbackMaxAge {
+			fmt.Printf("❌ Rejected stale callback for order: %s (transaction_time: %q, settlement_time: %q)\n", req.OrderID, req.TransactionTime, req.SettlementTime)
+			respondError(c, http.StatusBadRequest, "CALLBACK_EXPIRED", nil)
+			return
+		}
+	}
+
+	// Process each (order, status, transaction time) once. The claim is released when
+	// processing fails so Midtrans' retry of the same notification isn't ignored.
+	callback := &models.MidtransCallback{
+		Fingerprint:       models.CallbackFingerprint(req.OrderID, req.TransactionStatus, req.TransactionTime),
+		OrderID:           req.OrderID,
+		TransactionStatus: req.TransactionStatus,
+		TransactionTime:   req.TransactionTime,
+		SourceIP:          c.ClientIP(),
+	}
+	claimed, err := ph.callbackRepo.Claim(callback)
+	if err != nil {
+		fmt.Printf("❌ Failed to record callback for order %s: %v\n", req.OrderID, err)
+		respondError(c, http.StatusInternalServerError, "CALLBACK_RECORD_FAILED", nil)
+		return
+	}
+	if !claimed {
+		fmt.Printf("🔁 Duplicate callback ignored for order: %s, status: %s\n", req.OrderID, req.TransactionStatus)
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Duplicate callback ignored",
 		})
 		return
 	}
+	processed := false
+	defer func() {
+		if processed {
+			return
+		}
+		if err := ph.callbackRepo.Release(callback.ID); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+		}
+	}()
 
 	// Get payment from database
-	payment, err := ph.paymentRepo.GetByOrderID(req.OrderID)
+	payment, err := ph.paymentRepo.GetByOrderID(c.Request.Context(), req.OrderID)
 	if err != nil {
 		fmt.Printf("❌ Payment not found for order: %s, error: %v\n", req.OrderID, err)
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error":   "Payment not found",
-		})
+		respondError(c, http.StatusNotFound, "PAYMENT_NOT_FOUND", nil)
 		return
 	}
 
 	fmt.Printf("🔍 Found payment: %s, current status: %s\n", payment.ID.String(), payment.Status)
 
-	// Get detailed status from Midtrans with retry mechanism
-	var statusResp *services.MidtransStatusResponse
-	maxRetries := 3
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		statusResp, err = ph.midtransSvc.GetPaymentStatus(req.OrderID)
-		if err == nil {
-			break
+	// The notified amount must match what we charged before any status transition
+	if err := verifyGrossAmount(payment, req.GrossAmount); err != nil {
+		fmt.Printf("❌ Rejected callback for order %s: %v\n", req.OrderID, err)
+		respondError(c, http.StatusBadRequest, "GROSS_AMOUNT_MISMATCH", nil)
+		return
+	}
+
+	// Get detailed status from Midtrans with retry mechanism, simulated callbacks bring theirs
+	statusResp := simulated
+	if statusResp == nil {
+		maxRetries := 3
+		for attempt := 0; attempt < maxRetries; attempt++ {
+			statusResp, err = gateway.GetPaymentStatus(req.OrderID)
+			if err == nil {
+				break
+			}
+			fmt.Printf("⚠️ Attempt %d: Failed to get payment status from Midtrans: %v\n", attempt+1, err)
+			if attempt < maxRetries-1 {
+				time.Sleep(time.Duration(attempt+1) * time.Second)
+			}
 		}
-		fmt.Printf("⚠️ Attempt %d: Failed to get payment status from Midtrans: %v\n", attempt+1, err)
-		if attempt < maxRetries-1 {
-			time.Sleep(time.Duration(attempt+1) * time.Second)
+
+		if err != nil {
+			fmt.Printf("❌ Failed to get payment status from Midtrans after %d attempts: %v\n", maxRetries, err)
+			respondError(c, http.StatusInternalServerError, "MIDTRANS_STATUS_FAILED", nil)
+			return
 		}
 	}
 
-	if err != nil {
-		fmt.Printf("❌ Failed to get payment status from Midtrans after %d attempts: %v\n", maxRetries, err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to get payment status from Midtrans",
-		})
+	if err := verifyGrossAmount(payment, statusResp.GrossAmount); err != nil {
+		fmt.Printf("❌ Midtrans status for order %s doesn't match the payment: %v\n", req.OrderID, err)
+		respondError(c, http.StatusConflict, "GROSS_AMOUNT_MISMATCH", nil)
 		return
 	}
 
@@ -567,14 +1097,17 @@ func (ph *PaymentHandler) MidtransCallback(c *gin.Context) {
 
 	fmt.Printf("🔄 Status change: %s -> %s (Midtrans: %s)\n", oldStatus, newStatus, statusResp.TransactionStatus)
 
-	// Update payment status
-	if err := ph.paymentRepo.UpdateStatus(payment.ID, newStatus); err != nil {
-		fmt.Printf("❌ Failed to update payment status: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to update payment status",
-		})
-		return
+	// Update payment status, only the request that actually changes the row publishes events
+	changed, err := ph.paymentRepo.UpdateStatus(c.Request.Context(), payment.ID, newStatus)
+	if err != nil {
+		if !errors.Is(err, repository.ErrOrderAlreadyPaid) {
+			fmt.Printf("❌ Failed to update payment status: %v\n", err)
+			respondError(c, http.StatusInternalServerError, "PAYMENT_STATUS_UPDATE_FAILED", nil)
+			return
+		}
+		// Paid twice: keep the status, the Midtrans data below records the settlement
+		fmt.Printf("🚨 Order %s was already paid by another attempt, payment %s must be refunded\n", payment.OrderRef, payment.OrderID)
+		newStatus = oldStatus
 	}
 
 	// Update Midtrans data
@@ -590,12 +1123,12 @@ func (ph *PaymentHandler) MidtransCallback(c *gin.Context) {
 	if len(statusResp.VANumbers) > 0 {
 		midtransData["va_number"] = statusResp.VANumbers[0].VANumber
 		midtransData["bank_type"] = statusResp.VANumbers[0].Bank
-		fmt.Printf("🔍 Updated VA Number: %s, Bank: %s\n", statusResp.VANumbers[0].VANumber, statusResp.VANumbers[0].Bank)
+		fmt.Printf("🔍 Updated VA Number: %s, Bank: %s\n", redact.Tail(statusResp.VANumbers[0].VANumber), statusResp.VANumbers[0].Bank)
 	}
 
 	if statusResp.PaymentCode != "" {
 		midtransData["payment_code"] = statusResp.PaymentCode
-		fmt.Printf("🔍 Updated Payment Code: %s\n", statusResp.PaymentCode)
+		fmt.Printf("🔍 Updated Payment Code: %s\n", redact.Tail(statusResp.PaymentCode))
 		// For cstore payments, also store payment_code as va_number for easier copying
 		if payment.PaymentMethod == models.PaymentMethodCstore {
 			midtransData["va_number"] = statusResp.PaymentCode
@@ -605,46 +1138,20 @@ func (ph *PaymentHandler) MidtransCallback(c *gin.Context) {
 	if statusResp.PermataVANumber != "" {
 		midtransData["va_number"] = statusResp.PermataVANumber
 		midtransData["bank_type"] = "permata"
-		fmt.Printf("🔍 Updated Permata VA Number: %s\n", statusResp.PermataVANumber)
+		fmt.Printf("🔍 Updated Permata VA Number: %s\n", redact.Tail(statusResp.PermataVANumber))
 	}
 
 	if statusResp.ExpiryTime != "" {
-		// Try different time formats from Midtrans
-		timeFormats := []string{
-			time.RFC3339,                    // "2006-01-02T15:04:05Z07:00"
-			"2006-01-02 15:04:05",          // "2025-09-29 20:47:00"
-			"2006-01-02T15:04:05",          // "2025-09-29T20:47:00"
-		}
-		
-		var expiryTime time.Time
-		var err error
-		for _, format := range timeFormats {
-			expiryTime, err = time.Parse(format, statusResp.ExpiryTime)
-			if err == nil {
-				midtransData["expiry_time"] = expiryTime
-				fmt.Printf("🔍 Updated Expiry Time: %s\n", expiryTime.Format(time.RFC3339))
-				break
-			}
+		if expiryTime, err := timeutil.ParseMidtrans(statusResp.ExpiryTime); err == nil {
+			midtransData["expiry_time"] = expiryTime
+			fmt.Printf("🔍 Updated Expiry Time: %s\n", expiryTime.Format(time.RFC3339))
 		}
 	}
 
 	if statusResp.PaidAt != "" {
-		// Try different time formats from Midtrans
-		timeFormats := []string{
-			time.RFC3339,                    // "2006-01-02T15:04:05Z07:00"
-			"2006-01-02 15:04:05",          // "2025-09-29 20:47:00"
-			"2006-01-02T15:04:05",          // "2025-09-29T20:47:00"
-		}
-		
-		var paidAt time.Time
-		var err error
-		for _, format := range timeFormats {
-			paidAt, err = time.Parse(format, statusResp.PaidAt)
-			if err == nil {
-				midtransData["paid_at"] = paidAt
-				fmt.Printf("🔍 Updated Paid At: %s\n", paidAt.Format(time.RFC3339))
-				break
-			}
+		if paidAt, err := timeutil.ParseMidtrans(statusResp.PaidAt); err == nil {
+			midtransData["paid_at"] = paidAt
+			fmt.Printf("🔍 Updated Paid At: %s\n", paidAt.Format(time.RFC3339))
 		}
 	} else if newStatus == models.PaymentStatusSuccess && payment.PaidAt == nil {
 		// If payment is successful but no paid_at from Midtrans, set it to current time
@@ -653,20 +1160,21 @@ func (ph *PaymentHandler) MidtransCallback(c *gin.Context) {
 	}
 
 	// Update Midtrans data in database
-	if err := ph.paymentRepo.UpdateMidtransData(payment.ID, midtransData); err != nil {
+	if _, err := ph.paymentRepo.UpdateMidtransData(c.Request.Context(), payment.ID, midtransData); err != nil {
 		fmt.Printf("❌ Failed to update Midtrans data: %v\n", err)
 		// Don't return error here, just log it
 	}
 
 	// Invalidate cache
-	ph.cacheSvc.InvalidatePaymentCache(payment.ID.String(), payment.OrderID, payment.UserID.String())
+	ph.cacheSvc.InvalidatePaymentCache(c.Request.Context(), payment.ID.String(), payment.OrderID, payment.UserID.String())
 	fmt.Printf("🗑️ Invalidated cache for payment: %s\n", payment.ID.String())
 
 	// Publish events based on status change
-	if newStatus != oldStatus {
+	if changed {
 		fmt.Printf("📢 Publishing status change event: %s -> %s\n", oldStatus, newStatus)
 		
 		ph.eventSvc.PublishPaymentStatusUpdated(
+			eventContext(c),
 			payment.ID.String(),
 			payment.OrderID,
 			payment.UserID.String(),
@@ -682,6 +1190,7 @@ func (ph *PaymentHandler) MidtransCallback(c *gin.Context) {
 		if newStatus == models.PaymentStatusSuccess {
 			fmt.Printf("🎉 Payment successful! Publishing success event\n")
 			ph.eventSvc.PublishPaymentSuccess(
+				eventContext(c),
 				payment.ID.String(),
 				payment.OrderID,
 				payment.UserID.String(),
@@ -690,13 +1199,16 @@ func (ph *PaymentHandler) MidtransCallback(c *gin.Context) {
 				payment.TotalAmount,
 				string(payment.PaymentMethod),
 				time.Now(),
+				ph.orderSummary(payment),
 			)
 
 			// Publish stock reduction event
 			if payment.ProductID != nil {
 				ph.eventSvc.PublishStockReduction(
+					eventContext(c),
 					*payment.ProductID,
-					1, // Assuming quantity 1
+					payment.VariantID,
+					payment.ItemQuantity(),
 					payment.OrderID,
 					payment.UserID.String(),
 				)
@@ -705,6 +1217,7 @@ func (ph *PaymentHandler) MidtransCallback(c *gin.Context) {
 		} else if newStatus == models.PaymentStatusFailed || newStatus == models.PaymentStatusCancelled || newStatus == models.PaymentStatusExpired {
 			fmt.Printf("❌ Payment failed/cancelled/expired! Publishing failure event\n")
 			ph.eventSvc.PublishPaymentFailed(
+				eventContext(c),
 				payment.ID.String(),
 				payment.OrderID,
 				payment.UserID.String(),
@@ -714,11 +1227,25 @@ func (ph *PaymentHandler) MidtransCallback(c *gin.Context) {
 				string(payment.PaymentMethod),
 				string(newStatus),
 			)
+		} else if newStatus == models.PaymentStatusRefunded {
+			fmt.Printf("💸 Payment refunded! Publishing refund event\n")
+			ph.eventSvc.PublishPaymentRefunded(
+				eventContext(c),
+				payment.ID.String(),
+				payment.OrderID,
+				payment.UserID.String(),
+				payment.ProductID,
+				payment.Amount,
+				payment.TotalAmount,
+				string(payment.PaymentMethod),
+				time.Now(),
+			)
 		}
 	} else {
-		fmt.Printf("ℹ️ No status change detected\n")
+		fmt.Printf("ℹ️ No status change detected (payment already %s)\n", newStatus)
 	}
 
+	processed = true
 	fmt.Printf("✅ Callback processed successfully for order: %s\n", req.OrderID)
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -726,13 +1253,27 @@ func (ph *PaymentHandler) MidtransCallback(c *gin.Context) {
 	})
 }
 
-// GetMidtransConfig returns Midtrans configuration for frontend
+// GetMidtransConfig returns Midtrans configuration for frontend. With ?store_id= the
+// client key of a store charging with its own credentials is returned.
 func (ph *PaymentHandler) GetMidtransConfig(c *gin.Context) {
+	gateway := ph.midtransSvc
+	if value := c.Query("store_id"); value != "" {
+		storeID, err := uuid.Parse(value)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_STORE_ID", nil)
+			return
+		}
+		if gateway, err = ph.gatewayFor(c.Request.Context(), &storeID); err != nil {
+			respondError(c, http.StatusInternalServerError, "STORE_SETTINGS_FAILED", nil)
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
-			"client_key":  ph.midtransSvc.GetClientKey(),
-			"environment": ph.midtransSvc.GetEnvironment(),
+			"client_key":  gateway.GetClientKey(),
+			"environment": gateway.GetEnvironment(),
 		},
 	})
 }
@@ -742,29 +1283,45 @@ func (ph *PaymentHandler) CheckPaymentStatus(c *gin.Context) {
 	paymentIDStr := c.Param("id")
 	paymentID, err := uuid.Parse(paymentIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid payment ID",
-		})
+		respondError(c, http.StatusBadRequest, "INVALID_PAYMENT_ID", nil)
 		return
 	}
 
 	// Get payment from database
-	payment, err := ph.paymentRepo.GetByID(paymentID)
+	payment, err := ph.paymentRepo.GetByID(c.Request.Context(), paymentID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error":   "Payment not found",
+		respondError(c, http.StatusNotFound, "PAYMENT_NOT_FOUND", nil)
+		return
+	}
+
+	// Invoices aren't at Midtrans, their status only changes here
+	if payment.IsInvoice() {
+		c.JSON(http.StatusOK, gin.H{
+			"success":        true,
+			"data":           payment.ToResponse(),
+			"status_changed": false,
+			"old_status":     string(payment.Status),
+			"new_status":     string(payment.Status),
 		})
 		return
 	}
 
 	// Get detailed status from Midtrans
-	statusResp, err := ph.midtransSvc.GetPaymentStatus(payment.OrderID)
+	gateway, err := ph.gatewayFor(c.Request.Context(), payment.StoreID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to get payment status from Midtrans",
+		respondError(c, http.StatusInternalServerError, "STORE_SETTINGS_FAILED", nil)
+		return
+	}
+	statusResp, err := gateway.GetPaymentStatus(payment.OrderID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "MIDTRANS_STATUS_FAILED", nil)
+		return
+	}
+
+	if err := verifyGrossAmount(payment, statusResp.GrossAmount); err != nil {
+		fmt.Printf("❌ Midtrans status for order %s doesn't match the payment: %v\n", payment.OrderID, err)
+		respondError(c, http.StatusConflict, "GROSS_AMOUNT_MISMATCH", gin.H{
+			"details": err.Error(),
 		})
 		return
 	}
@@ -778,11 +1335,16 @@ func (ph *PaymentHandler) CheckPaymentStatus(c *gin.Context) {
 
 	// Update payment status if changed
 	if newStatus != oldStatus {
-		if err := ph.paymentRepo.UpdateStatus(payment.ID, newStatus); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"error":   "Failed to update payment status",
-			})
+		changed, err := ph.paymentRepo.UpdateStatus(c.Request.Context(), payment.ID, newStatus)
+		if err != nil {
+			if errors.Is(err, repository.ErrOrderAlreadyPaid) {
+				fmt.Printf("🚨 Order %s was already paid by another attempt, payment %s must be refunded\n", payment.OrderRef, payment.OrderID)
+				respondError(c, http.StatusConflict, "ORDER_ALREADY_PAID", gin.H{
+					"details": err.Error(),
+				})
+				return
+			}
+			respondError(c, http.StatusInternalServerError, "PAYMENT_STATUS_UPDATE_FAILED", nil)
 			return
 		}
 
@@ -814,100 +1376,103 @@ func (ph *PaymentHandler) CheckPaymentStatus(c *gin.Context) {
 		}
 
 		if statusResp.ExpiryTime != "" {
-			timeFormats := []string{
-				time.RFC3339,
-				"2006-01-02 15:04:05",
-				"2006-01-02T15:04:05",
-			}
-			
-			for _, format := range timeFormats {
-				if expiryTime, err := time.Parse(format, statusResp.ExpiryTime); err == nil {
-					midtransData["expiry_time"] = expiryTime
-					break
-				}
+			if expiryTime, err := timeutil.ParseMidtrans(statusResp.ExpiryTime); err == nil {
+				midtransData["expiry_time"] = expiryTime
 			}
 		}
 
 		if statusResp.PaidAt != "" {
-			timeFormats := []string{
-				time.RFC3339,
-				"2006-01-02 15:04:05",
-				"2006-01-02T15:04:05",
-			}
-			
-			for _, format := range timeFormats {
-				if paidAt, err := time.Parse(format, statusResp.PaidAt); err == nil {
-					midtransData["paid_at"] = paidAt
-					break
-				}
+			if paidAt, err := timeutil.ParseMidtrans(statusResp.PaidAt); err == nil {
+				midtransData["paid_at"] = paidAt
 			}
 		} else if newStatus == models.PaymentStatusSuccess && payment.PaidAt == nil {
 			midtransData["paid_at"] = time.Now()
 		}
 
-		ph.paymentRepo.UpdateMidtransData(payment.ID, midtransData)
+		if _, err := ph.paymentRepo.UpdateMidtransData(c.Request.Context(), payment.ID, midtransData); err != nil {
+			fmt.Printf("❌ Failed to update Midtrans data: %v\n", err)
+		}
 
 		// Invalidate cache
-		ph.cacheSvc.InvalidatePaymentCache(payment.ID.String(), payment.OrderID, payment.UserID.String())
-
-		// Publish events based on status change
-		ph.eventSvc.PublishPaymentStatusUpdated(
-			payment.ID.String(),
-			payment.OrderID,
-			payment.UserID.String(),
-			payment.ProductID,
-			string(oldStatus),
-			string(newStatus),
-			payment.Amount,
-			payment.TotalAmount,
-			string(payment.PaymentMethod),
-			payment.PaidAt,
-		)
+		ph.cacheSvc.InvalidatePaymentCache(c.Request.Context(), payment.ID.String(), payment.OrderID, payment.UserID.String())
 
-		if newStatus == models.PaymentStatusSuccess {
-			ph.eventSvc.PublishPaymentSuccess(
+		// Publish events only if this check changed the row, a callback may have won the race
+		if !changed {
+			fmt.Printf("ℹ️ Payment %s already %s, events were published by the other update\n", payment.ID.String(), newStatus)
+		} else {
+			ph.eventSvc.PublishPaymentStatusUpdated(
+				eventContext(c),
 				payment.ID.String(),
 				payment.OrderID,
 				payment.UserID.String(),
 				payment.ProductID,
+				string(oldStatus),
+				string(newStatus),
 				payment.Amount,
 				payment.TotalAmount,
 				string(payment.PaymentMethod),
-				time.Now(),
+				payment.PaidAt,
 			)
 
-			// Publish stock reduction event
-			if payment.ProductID != nil {
-				ph.eventSvc.PublishStockReduction(
-					*payment.ProductID,
-					1,
+			if newStatus == models.PaymentStatusSuccess {
+				ph.eventSvc.PublishPaymentSuccess(
+					eventContext(c),
+					payment.ID.String(),
+					payment.OrderID,
+					payment.UserID.String(),
+					payment.ProductID,
+					payment.Amount,
+					payment.TotalAmount,
+					string(payment.PaymentMethod),
+					time.Now(),
+					ph.orderSummary(payment),
+				)
+
+				// Publish stock reduction event
+				if payment.ProductID != nil {
+					ph.eventSvc.PublishStockReduction(
+						eventContext(c),
+						*payment.ProductID,
+						payment.VariantID,
+						payment.ItemQuantity(),
+						payment.OrderID,
+						payment.UserID.String(),
+					)
+				}
+			} else if newStatus == models.PaymentStatusFailed || newStatus == models.PaymentStatusCancelled || newStatus == models.PaymentStatusExpired {
+				ph.eventSvc.PublishPaymentFailed(
+					eventContext(c),
+					payment.ID.String(),
 					payment.OrderID,
 					payment.UserID.String(),
+					payment.ProductID,
+					payment.Amount,
+					payment.TotalAmount,
+					string(payment.PaymentMethod),
+					string(newStatus),
+				)
+			} else if newStatus == models.PaymentStatusRefunded {
+				ph.eventSvc.PublishPaymentRefunded(
+					eventContext(c),
+					payment.ID.String(),
+					payment.OrderID,
+					payment.UserID.String(),
+					payment.ProductID,
+					payment.Amount,
+					payment.TotalAmount,
+					string(payment.PaymentMethod),
+					time.Now(),
 				)
 			}
-		} else if newStatus == models.PaymentStatusFailed || newStatus == models.PaymentStatusCancelled || newStatus == models.PaymentStatusExpired {
-			ph.eventSvc.PublishPaymentFailed(
-				payment.ID.String(),
-				payment.OrderID,
-				payment.UserID.String(),
-				payment.ProductID,
-				payment.Amount,
-				payment.TotalAmount,
-				string(payment.PaymentMethod),
-				string(newStatus),
-			)
 		}
 
 		fmt.Printf("✅ Status updated from %s to %s\n", oldStatus, newStatus)
 	}
 
 	// Get updated payment data
-	updatedPayment, err := ph.paymentRepo.GetByID(paymentID)
+	updatedPayment, err := ph.paymentRepo.GetByID(c.Request.Context(), paymentID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to get updated payment data",
-		})
+		respondError(c, http.StatusInternalServerError, "PAYMENT_REFRESH_FAILED", nil)
 		return
 	}
 
@@ -922,7 +1487,7 @@ func (ph *PaymentHandler) CheckPaymentStatus(c *gin.Context) {
 	}
 
 	// Cache the response
-	ph.cacheSvc.SetPayment(payment.ID.String(), paymentResponse, 1*time.Hour)
+	ph.cacheSvc.SetPayment(c.Request.Context(), payment.ID.String(), paymentResponse, ph.cacheTTLs.Payment)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -935,7 +1500,13 @@ func (ph *PaymentHandler) CheckPaymentStatus(c *gin.Context) {
 
 // Helper methods
 
-func (ph *PaymentHandler) getUserFromService(userID uuid.UUID) (*models.User, error) {
+// getUserFromService returns the user from the local read model, falling back to
+// User-Service (and caching the result) for users not seen in events yet
+func (ph *PaymentHandler) getUserFromService(userID uuid.UUID) (*models.UserProfile, error) {
+	if profile, err := ph.userProfiles.GetByID(userID); err == nil {
+		return profile, nil
+	}
+
 	// Make HTTP request to user service
 	url := fmt.Sprintf("%s/api/v1/users/%s", ph.userServiceURL, userID.String())
 	fmt.Printf("🔍 Making request to user service: %s\n", url)
@@ -951,8 +1522,7 @@ func (ph *PaymentHandler) getUserFromService(userID uuid.UUID) (*models.User, er
 	req.Header.Set("Accept", "application/json")
 	
 	// Make request
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := ph.doServiceRequest(req)
 	if err != nil {
 		fmt.Printf("❌ Failed to make request to user service: %v\n", err)
 		return nil, fmt.Errorf("failed to make request to user service: %w", err)
@@ -963,7 +1533,7 @@ func (ph *PaymentHandler) getUserFromService(userID uuid.UUID) (*models.User, er
 	if resp.StatusCode != http.StatusOK {
 		// Read response body for error details
 		body, _ := io.ReadAll(resp.Body)
-		fmt.Printf("❌ User service error response: %s\n", string(body))
+		fmt.Printf("❌ User service error response: %s\n", redact.JSON(body))
 		return nil, fmt.Errorf("user service returned status %d: %s", resp.StatusCode, string(body))
 	}
 	
@@ -971,9 +1541,10 @@ func (ph *PaymentHandler) getUserFromService(userID uuid.UUID) (*models.User, er
 	var userResp struct {
 		Success bool `json:"success"`
 		Data    struct {
-			ID       string `json:"id"`
-			Username string `json:"username"`
-			Email    string `json:"email"`
+			ID          string `json:"id"`
+			Username    string `json:"username"`
+			Email       string `json:"email"`
+			PhoneNumber string `json:"phone_number"`
 		} `json:"data"`
 	}
 	
@@ -991,14 +1562,21 @@ func (ph *PaymentHandler) getUserFromService(userID uuid.UUID) (*models.User, er
 		return nil, fmt.Errorf("invalid user ID format: %w", err)
 	}
 	
-	return &models.User{
-		ID:       userUUID,
-		Username: userResp.Data.Username,
-		Email:    userResp.Data.Email,
-	}, nil
+	profile := &models.UserProfile{
+		ID:          userUUID,
+		Username:    userResp.Data.Username,
+		Email:       userResp.Data.Email,
+		PhoneNumber: &userResp.Data.PhoneNumber,
+	}
+	if err := ph.userProfiles.Upsert(profile); err != nil {
+		fmt.Printf("⚠️ Failed to cache user profile: %v\n", err)
+	}
+
+	return profile, nil
 }
 
-func (ph *PaymentHandler) getProductFromService(productID uuid.UUID) (*models.Product, error) {
+// fetchProduct looks a product up from Product-Service, bypassing the product cache
+func (ph *PaymentHandler) fetchProduct(productID uuid.UUID) (*models.Product, error) {
 	// Make HTTP request to product service
 	url := fmt.Sprintf("%s/api/v1/products/%s", ph.productServiceURL, productID.String())
 	
@@ -1012,13 +1590,15 @@ func (ph *PaymentHandler) getProductFromService(productID uuid.UUID) (*models.Pr
 	req.Header.Set("Accept", "application/json")
 	
 	// Make request
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := ph.doServiceRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request to product service: %w", err)
 	}
 	defer resp.Body.Close()
 	
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errProductNotFound
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("product service returned status %d", resp.StatusCode)
 	}
@@ -1030,9 +1610,12 @@ func (ph *PaymentHandler) getProductFromService(productID uuid.UUID) (*models.Pr
 			ID          string  `json:"id"`
 			Name        string  `json:"name"`
 			Description string  `json:"description"`
-			Price       float64 `json:"price"`
+			Price       money.Major `json:"price"`
 			Stock       int     `json:"stock"`
 			IsActive    bool    `json:"is_active"`
+			StoreID     *uuid.UUID `json:"store_id"`
+			UserID      uuid.UUID  `json:"user_id"`
+			Variants    []models.ProductVariant `json:"variants"`
 		} `json:"data"`
 	}
 	
@@ -1050,14 +1633,178 @@ func (ph *PaymentHandler) getProductFromService(productID uuid.UUID) (*models.Pr
 		return nil, fmt.Errorf("invalid product ID format: %w", err)
 	}
 	
-	return &models.Product{
+	product := &models.Product{
 		ID:          productUUID,
 		Name:        productResp.Data.Name,
 		Description: productResp.Data.Description,
 		Price:       productResp.Data.Price,
 		Stock:       productResp.Data.Stock,
 		IsActive:    productResp.Data.IsActive,
-	}, nil
+		StoreID:     productResp.Data.StoreID,
+		OwnerID:     productResp.Data.UserID,
+		Variants:    productResp.Data.Variants,
+	}
+	return product, nil
+}
+
+func (ph *PaymentHandler) getProductAvailability(productID uuid.UUID, variantID *uuid.UUID, quantity int) (*models.ProductAvailability, error) {
+	url := fmt.Sprintf("%s/api/v1/products/%s/availability?quantity=%d&nocache=true", ph.productServiceURL, productID.String(), quantity)
+	if variantID != nil {
+		url += "&variant_id=" + variantID.String()
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := ph.doServiceRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request to product service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("product service returned status %d", resp.StatusCode)
+	}
+
+	var availabilityResp struct {
+		Success bool                       `json:"success"`
+		Data    models.ProductAvailability `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&availabilityResp); err != nil {
+		return nil, fmt.Errorf("failed to decode availability response: %w", err)
+	}
+	if !availabilityResp.Success {
+		return nil, fmt.Errorf("product service returned error")
+	}
+
+	return &availabilityResp.Data, nil
+}
+
+// getProductQuote asks Product-Service for the price of quantity units after pricing rules,
+// at member prices for signed-in customers. A variant's price override replaces the base price.
+func (ph *PaymentHandler) getProductQuote(productID uuid.UUID, variantID *uuid.UUID, quantity int, member bool) (*models.PriceQuote, error) {
+	url := fmt.Sprintf("%s/api/v1/products/%s/price?quantity=%d&member=%t&nocache=true", ph.productServiceURL, productID.String(), quantity, member)
+	if variantID != nil {
+		url += "&variant_id=" + variantID.String()
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := ph.doServiceRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request to product service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("product service returned status %d", resp.StatusCode)
+	}
+
+	var quoteResp struct {
+		Success bool              `json:"success"`
+		Data    models.PriceQuote `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&quoteResp); err != nil {
+		return nil, fmt.Errorf("failed to decode price response: %w", err)
+	}
+	if !quoteResp.Success {
+		return nil, fmt.Errorf("product service returned error")
+	}
+
+	return &quoteResp.Data, nil
+}
+
+// checkAmount rejects amounts that differ from the price of quantity units after pricing rules
+// with PRICE_CHANGED and the current price, the buyer saw a stale price and confirms the new
+// one. The price is never taken from the (possibly cached) product: when Product-Service
+// can't quote it the request fails with 503.
+func (ph *PaymentHandler) checkAmount(c *gin.Context, product *models.Product, variant *models.ProductVariant, quantity int, amount int64, member bool) bool {
+	unitPrice := product.Price
+	var variantID *uuid.UUID
+	if variant != nil {
+		variantID = &variant.ID
+		if variant.PriceOverride != nil {
+			unitPrice = *variant.PriceOverride
+		}
+	}
+
+	quote, err := ph.getProductQuote(product.ID, variantID, quantity, member)
+	if err != nil {
+		fmt.Printf("⚠️ Price quote failed for product %s: %v\n", product.ID, err)
+		respondError(c, http.StatusServiceUnavailable, "PRICE_UNAVAILABLE", gin.H{
+			"details": "the current price could not be verified, try again shortly",
+		})
+		return false
+	}
+	if !quote.BasePrice.Equal(unitPrice.Money) {
+		ph.dropStaleProduct(product.ID, "price changed")
+	}
+	unitPrice = quote.UnitPrice
+	if quote.Rule != nil {
+		fmt.Printf("🏷️ Pricing rule %q applies to product %s: %d -> %d\n", quote.Rule.Name, product.ID, quote.BasePrice.Minor, quote.Total.Minor)
+	}
+
+	expectedAmount := quote.Total.Money
+	if !expectedAmount.IsPositive() {
+		respondError(c, http.StatusBadGateway, "INVALID_PRODUCT_PRICE", gin.H{
+			"details": "price total " + expectedAmount.String() + " is not positive",
+		})
+		return false
+	}
+	if amount != expectedAmount.Minor {
+		fmt.Printf("🏷️ Price of product %s changed: requested %d, now %d\n", product.ID, amount, expectedAmount.Minor)
+		respondError(c, http.StatusConflict, ErrCodePriceChanged, gin.H{
+			"details": fmt.Sprintf("expected amount %d", expectedAmount.Minor),
+			"data": gin.H{
+				"product_id":       product.ID,
+				"variant_id":       variantID,
+				"quantity":         quantity,
+				"unit_price":       unitPrice,
+				"amount":           expectedAmount.Minor,
+				"requested_amount": amount,
+			},
+		})
+		return false
+	}
+	return true
+}
+
+// orderSummary collects the order details sent with payment.success for receipt emails. The
+// product is looked up again for its current name and owner; if that fails the emails are
+// sent without them.
+func (ph *PaymentHandler) orderSummary(payment *models.Payment) events.OrderSummary {
+	summary := events.OrderSummary{
+		Quantity: payment.ItemQuantity(),
+		Currency: payment.Currency,
+	}
+	if payment.BankType != nil {
+		summary.Bank = *payment.BankType
+	} else if payment.StoreType != nil {
+		summary.Bank = *payment.StoreType
+	}
+	if payment.VANumber != nil {
+		summary.VANumber = redact.Tail(*payment.VANumber)
+	}
+
+	if payment.ProductID != nil {
+		product, err := ph.getProductFromService(*payment.ProductID)
+		if err != nil {
+			fmt.Printf("⚠️ Failed to get product for order summary of %s: %v\n", payment.OrderID, err)
+			return summary
+		}
+		summary.ProductName = product.Name
+		if product.OwnerID != uuid.Nil {
+			summary.SellerID = product.OwnerID.String()
+		}
+	}
+	return summary
 }
 
 func (ph *PaymentHandler) marshalToJSON(data interface{}) string {
@@ -1077,51 +1824,51 @@ func (ph *PaymentHandler) convertMidtransActions(actions []services.MidtransActi
 	return result
 }
 
-// waitForPaymentData waits for payment data to be saved in database
-func (ph *PaymentHandler) waitForPaymentData(paymentID uuid.UUID, maxRetries int, delay time.Duration) (*models.Payment, error) {
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		payment, err := ph.paymentRepo.GetByIDWithoutRelations(paymentID)
-		if err != nil {
-			fmt.Printf("⚠️ Attempt %d: Failed to get payment data: %v\n", attempt+1, err)
-			if attempt < maxRetries-1 {
-				time.Sleep(delay)
-				continue
-			}
-			return nil, err
-		}
+// gatewayFor returns the Midtrans gateway for a store: its own keys when configured, the
+// platform keys otherwise. Stored keys that can't be decrypted are an error rather than a
+// silent fallback, so a store's payments never land in the platform account by accident.
+func (ph *PaymentHandler) gatewayFor(ctx context.Context, storeID *uuid.UUID) (services.PaymentGateway, error) {
+	if storeID == nil || ph.storeCredentials == nil {
+		return ph.midtransSvc, nil
+	}
 
-		// Check if VA number or payment code is available based on payment method
-		hasRequiredData := false
-		switch payment.PaymentMethod {
-		case models.PaymentMethodBankTransfer, models.PaymentMethodPermata:
-			// For bank transfer, check if VA number exists
-			if payment.VANumber != nil && *payment.VANumber != "" {
-				hasRequiredData = true
-				fmt.Printf("✅ VA Number found: %s\n", *payment.VANumber)
-			}
-		case models.PaymentMethodCstore:
-			// For cstore, check if payment code exists
-			if payment.PaymentCode != nil && *payment.PaymentCode != "" {
-				hasRequiredData = true
-				fmt.Printf("✅ Payment Code found: %s\n", *payment.PaymentCode)
-			}
-		case models.PaymentMethodGoPay, models.PaymentMethodQRIS, models.PaymentMethodCreditCard:
-			// For these methods, we don't need to wait for specific data
-			hasRequiredData = true
-		default:
-			hasRequiredData = true
-		}
+	credentials, err := ph.storeCredentials.Get(ctx, *storeID)
+	if errors.Is(err, repository.ErrStoreCredentialsNotFound) {
+		return ph.midtransSvc, nil
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		if hasRequiredData {
-			fmt.Printf("✅ Payment data is ready for response\n")
-			return payment, nil
-		}
+	if ph.credentialBox == nil {
+		return nil, fmt.Errorf("store %s has Midtrans credentials but DATA_ENCRYPTION_KEY is not set", storeID)
+	}
+	serverKey, err := ph.credentialBox.Decrypt(credentials.ServerKeyEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt Midtrans credentials of store %s: %w", storeID, err)
+	}
+	return ph.midtransSvc.WithCredentials(serverKey, credentials.ClientKey), nil
+}
 
-		fmt.Printf("⏳ Attempt %d: Payment data not ready yet, retrying...\n", attempt+1)
-		if attempt < maxRetries-1 {
-			time.Sleep(delay)
-		}
+// gatewayForOrder returns the gateway an order was charged with. Unknown orders get the
+// platform gateway, the caller reports them once the signature is checked.
+func (ph *PaymentHandler) gatewayForOrder(ctx context.Context, orderID string) (services.PaymentGateway, error) {
+	payment, err := ph.paymentRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return ph.midtransSvc, nil
 	}
+	return ph.gatewayFor(ctx, payment.StoreID)
+}
 
-	return nil, fmt.Errorf("payment data not ready after %d attempts", maxRetries)
+// verifyGrossAmount checks a Midtrans gross_amount against the payment's stored total
+func verifyGrossAmount(payment *models.Payment, grossAmount string) error {
+	total := payment.Total()
+	gross, err := money.ParseDecimal(grossAmount, total.Currency)
+	if err != nil {
+		return fmt.Errorf("invalid gross_amount: %w", err)
+	}
+	if !gross.Equal(total) {
+		return fmt.Errorf("gross_amount %s does not match payment total %s", gross, total)
+	}
+	return nil
 }
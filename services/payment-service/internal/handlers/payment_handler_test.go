@@ -0,0 +1,532 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"payment-service/internal/fakes"
+	"payment-service/internal/handlers"
+	"payment-service/internal/models"
+	"payment-service/internal/repository"
+	"payment-service/internal/services"
+	"payment-service/internal/timeutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testPrice is the price of the product the fake Product-Service sells
+const testPrice = 50000
+
+// testEnv is a payment handler over an in-memory database, the fakes and a fake
+// User-Service and Product-Service
+type testEnv struct {
+	handler  *handlers.PaymentHandler
+	router   *gin.Engine
+	db       *gorm.DB
+	midtrans *fakes.Midtrans
+	events   *fakes.EventPublisher
+	cache    *fakes.Cache
+	userID   uuid.UUID
+	product  uuid.UUID
+}
+
+func newTestEnv(tb testing.TB) *testEnv {
+	tb.Helper()
+	gin.SetMode(gin.TestMode)
+
+	env := &testEnv{
+		db:       newTestDB(tb),
+		midtrans: &fakes.Midtrans{ValidSignature: true},
+		events:   &fakes.EventPublisher{},
+		cache:    fakes.NewCache(),
+		userID:   uuid.New(),
+		product:  uuid.New(),
+	}
+
+	// The buyer's profile is already known, so User-Service isn't asked
+	phone := "+6281234567890"
+	profiles := repository.NewUserProfileRepository(env.db)
+	if err := profiles.Upsert(&models.UserProfile{ID: env.userID, Username: "buyer", Email: "buyer@example.com", PhoneNumber: &phone}); err != nil {
+		tb.Fatalf("failed to seed user profile: %v", err)
+	}
+
+	productService := httptest.NewServer(fakeProductService(env.product))
+	tb.Cleanup(productService.Close)
+
+	env.handler = handlers.NewPaymentHandler(
+		repository.NewPaymentRepository(env.db),
+		profiles,
+		repository.NewCallbackRepository(env.db),
+		nil,
+		env.midtrans,
+		nil,
+		env.events,
+		env.cache,
+		"http://user-service.invalid",
+		productService.URL,
+		nil,
+	)
+
+	env.router = gin.New()
+	env.router.POST("/payments", env.handler.CreatePayment)
+	env.router.POST("/midtrans/callback", env.handler.MidtransCallback)
+	return env
+}
+
+// newTestDB opens an in-memory SQLite database with the service's tables. SQLite has no
+// gen_random_uuid(), the models' BeforeCreate hooks set the IDs instead.
+func newTestDB(tb testing.TB) *gorm.DB {
+	tb.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		tb.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		tb.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1) // every connection would get its own in-memory database
+	tb.Cleanup(func() { sqlDB.Close() })
+
+	tables := append(models.OwnedModels(), models.ReadModels()...)
+	for _, table := range tables {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(table); err != nil {
+			tb.Fatalf("failed to parse %T: %v", table, err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DefaultValue == "gen_random_uuid()" {
+				field.HasDefaultValue = false
+				field.DefaultValue = ""
+			}
+		}
+	}
+	if err := db.AutoMigrate(tables...); err != nil {
+		tb.Fatalf("failed to migrate database: %v", err)
+	}
+	return db
+}
+
+// fakeProductService answers the product, price and availability lookups of one product
+// at testPrice with 10 in stock
+func fakeProductService(productID uuid.UUID) http.Handler {
+	mux := http.NewServeMux()
+	base := "/api/v1/products/" + productID.String()
+	mux.HandleFunc(base, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"success":true,"data":{"id":%q,"name":"Kopi Gayo","price":%d,"stock":10,"is_active":true,"user_id":%q}}`,
+			productID, testPrice, uuid.Nil)
+	})
+	mux.HandleFunc(base+"/price", func(w http.ResponseWriter, r *http.Request) {
+		var quantity int
+		fmt.Sscan(r.URL.Query().Get("quantity"), &quantity)
+		fmt.Fprintf(w, `{"success":true,"data":{"base_price":%d,"unit_price":%d,"total":%d}}`,
+			testPrice, testPrice, testPrice*quantity)
+	})
+	mux.HandleFunc(base+"/availability", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":true,"data":{"in_stock":true,"max_quantity":10,"is_active":true}}`)
+	})
+	return mux
+}
+
+// do sends a JSON request through the env's router
+func (env *testEnv) do(method, path string, body any) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", env.userID.String())
+	rec := httptest.NewRecorder()
+	env.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// createPayment charges the product once with method, returning the stored payment
+func (env *testEnv) createPayment(tb testing.TB, method models.PaymentMethod, extra map[string]any) *models.Payment {
+	tb.Helper()
+	body := map[string]any{
+		"product_id":     env.product,
+		"amount":         testPrice,
+		"payment_method": method,
+	}
+	for key, value := range extra {
+		body[key] = value
+	}
+	rec := env.do(http.MethodPost, "/payments", body)
+	if rec.Code != http.StatusOK {
+		tb.Fatalf("CreatePayment = %d %s, want 200", rec.Code, rec.Body)
+	}
+
+	var answer struct {
+		Data struct {
+			OrderID string `json:"order_id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &answer); err != nil {
+		tb.Fatalf("invalid response %s: %v", rec.Body, err)
+	}
+	var payment models.Payment
+	if err := env.db.First(&payment, "order_id = ?", answer.Data.OrderID).Error; err != nil {
+		tb.Fatalf("payment %s not stored: %v", answer.Data.OrderID, err)
+	}
+	return &payment
+}
+
+func (env *testEnv) eventTypes() []string {
+	var types []string
+	for _, event := range env.events.Events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestCreatePaymentMethods(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     models.PaymentMethod
+		extra      map[string]any
+		response   services.MidtransChargeResponse
+		wantVA     string
+		wantBank   string
+		wantCode   string
+		wantAction string
+	}{
+		{
+			name:   "bank transfer stores the VA number",
+			method: models.PaymentMethodBankTransfer,
+			extra:  map[string]any{"bank_type": "bca"},
+			response: services.MidtransChargeResponse{
+				TransactionID:     "trx-va",
+				TransactionStatus: "pending",
+				VANumbers:         []services.VANumber{{Bank: "bca", VANumber: "12345678901"}},
+				ExpiryTime:        "2030-01-02 15:04:05",
+			},
+			wantVA:   "12345678901",
+			wantBank: "bca",
+		},
+		{
+			name:   "cstore stores the payment code as VA number",
+			method: models.PaymentMethodCstore,
+			extra:  map[string]any{"store_type": "alfamart"},
+			response: services.MidtransChargeResponse{
+				TransactionID:     "trx-cstore",
+				TransactionStatus: "pending",
+				PaymentCode:       "ALFA123456",
+			},
+			wantVA:   "ALFA123456",
+			wantCode: "ALFA123456",
+		},
+		{
+			name:   "gopay stores the QR code URL",
+			method: models.PaymentMethodGoPay,
+			response: services.MidtransChargeResponse{
+				TransactionID:     "trx-gopay",
+				TransactionStatus: "pending",
+				Actions: []services.MidtransAction{
+					{Name: "generate-qr-code", Method: "GET", URL: "https://api.sandbox.midtrans.com/qr"},
+					{Name: "deeplink-redirect", Method: "GET", URL: "gojek://gopay"},
+				},
+			},
+			wantAction: "https://api.sandbox.midtrans.com/qr",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.midtrans.ChargeResponse = &tt.response
+
+			payment := env.createPayment(t, tt.method, tt.extra)
+
+			if len(env.midtrans.Charges) != 1 {
+				t.Fatalf("Midtrans charged %d times, want 1", len(env.midtrans.Charges))
+			}
+			if payment.Status != models.PaymentStatusPending || payment.TotalAmount != testPrice {
+				t.Errorf("payment is %s for %d, want PENDING for %d", payment.Status, payment.TotalAmount, testPrice)
+			}
+			if got := deref(payment.MidtransTransactionID); got != tt.response.TransactionID {
+				t.Errorf("transaction ID = %q, want %q", got, tt.response.TransactionID)
+			}
+			if got := deref(payment.VANumber); got != tt.wantVA {
+				t.Errorf("va_number = %q, want %q", got, tt.wantVA)
+			}
+			if tt.wantBank != "" && deref(payment.BankType) != tt.wantBank {
+				t.Errorf("bank_type = %q, want %q", deref(payment.BankType), tt.wantBank)
+			}
+			if got := deref(payment.PaymentCode); got != tt.wantCode {
+				t.Errorf("payment_code = %q, want %q", got, tt.wantCode)
+			}
+			if got := deref(payment.SnapRedirectURL); got != tt.wantAction {
+				t.Errorf("snap_redirect_url = %q, want %q", got, tt.wantAction)
+			}
+			if got := strings.Join(env.eventTypes(), ","); got != "payment.created" {
+				t.Errorf("published %q, want payment.created", got)
+			}
+
+			var cached models.PaymentResponse
+			if err := env.cache.GetPaymentByOrderID(t.Context(), payment.OrderID, &cached); err != nil || cached.ID != payment.ID {
+				t.Errorf("payment not cached by order ID: %v", err)
+			}
+		})
+	}
+}
+
+func TestCreatePaymentMidtransUnavailable(t *testing.T) {
+	env := newTestEnv(t)
+	env.midtrans.ChargeErr = fmt.Errorf("midtrans API error: 505 Unable to create va_number")
+
+	rec := env.do(http.MethodPost, "/payments", map[string]any{
+		"product_id":     env.product,
+		"amount":         testPrice,
+		"payment_method": models.PaymentMethodBankTransfer,
+		"bank_type":      "bni",
+	})
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("CreatePayment = %d %s, want 503", rec.Code, rec.Body)
+	}
+	// Without Accept-Language the message is in the default locale, Indonesian
+	var answer struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+		Code    string `json:"code"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &answer); err != nil {
+		t.Fatalf("invalid response %s: %v", rec.Body, err)
+	}
+	if answer.Code != "PAYMENT_METHOD_UNAVAILABLE" || answer.Error != "Payment method temporarily unavailable" || !strings.HasPrefix(answer.Message, "Metode pembayaran sedang maintenance") {
+		t.Errorf("CreatePayment = %+v, want code PAYMENT_METHOD_UNAVAILABLE with an English error and an Indonesian message", answer)
+	}
+
+	var count int64
+	env.db.Model(&models.Payment{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d payments stored after a failed charge, want 0", count)
+	}
+	if len(env.events.Events) != 0 {
+		t.Errorf("published %v after a failed charge", env.eventTypes())
+	}
+}
+
+func TestCreatePaymentPriceChanged(t *testing.T) {
+	env := newTestEnv(t)
+	env.midtrans.ChargeResponse = &services.MidtransChargeResponse{TransactionStatus: "pending"}
+
+	rec := env.do(http.MethodPost, "/payments", map[string]any{
+		"product_id":     env.product,
+		"amount":         testPrice - 1000,
+		"payment_method": models.PaymentMethodQRIS,
+	})
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), handlers.ErrCodePriceChanged) {
+		t.Fatalf("CreatePayment = %d %s, want 409 %s", rec.Code, rec.Body, handlers.ErrCodePriceChanged)
+	}
+	if len(env.midtrans.Charges) != 0 {
+		t.Errorf("Midtrans charged %d times, want 0", len(env.midtrans.Charges))
+	}
+}
+
+// BenchmarkCreatePayment charges a bank transfer end to end against the fake Midtrans: the
+// price, stock and pending quantity checks, the database writes, caching and the event. Its
+// allocations are budgeted in loadtest/bench-budgets.txt.
+func BenchmarkCreatePayment(b *testing.B) {
+	discardStdout(b)
+	env := newTestEnv(b)
+	env.midtrans.ChargeResponse = &services.MidtransChargeResponse{
+		TransactionID:     "trx-bench",
+		TransactionStatus: "pending",
+		VANumbers:         []services.VANumber{{Bank: "bca", VANumber: "12345678901"}},
+	}
+	body, _ := json.Marshal(map[string]any{
+		"product_id":     env.product,
+		"amount":         testPrice,
+		"payment_method": models.PaymentMethodBankTransfer,
+		"bank_type":      "bca",
+	})
+
+	b.ReportAllocs()
+	for b.Loop() {
+		req := httptest.NewRequest(http.MethodPost, "/payments", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", env.userID.String())
+		rec := httptest.NewRecorder()
+		env.router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			b.Fatalf("CreatePayment = %d %s, want 200", rec.Code, rec.Body)
+		}
+	}
+}
+
+// callback builds a Midtrans notification for payment
+func callback(payment *models.Payment, status, grossAmount string) models.MidtransCallbackRequest {
+	return models.MidtransCallbackRequest{
+		OrderID:           payment.OrderID,
+		StatusCode:        "200",
+		GrossAmount:       grossAmount,
+		SignatureKey:      "fake-signature",
+		TransactionStatus: status,
+		TransactionID:     deref(payment.MidtransTransactionID),
+		TransactionTime:   midtransTime(time.Now()),
+	}
+}
+
+// midtransTime formats t like Midtrans, Jakarta time without an offset
+func midtransTime(t time.Time) string {
+	return t.In(timeutil.MidtransLocation).Format("2006-01-02 15:04:05")
+}
+
+func TestMidtransCallbackSettlement(t *testing.T) {
+	env := newTestEnv(t)
+	env.midtrans.ChargeResponse = &services.MidtransChargeResponse{TransactionID: "trx-1", TransactionStatus: "pending"}
+	payment := env.createPayment(t, models.PaymentMethodQRIS, nil)
+
+	gross := fmt.Sprintf("%d.00", testPrice)
+	env.midtrans.StatusResponse = &services.MidtransStatusResponse{
+		StatusCode:        "200",
+		TransactionID:     "trx-1",
+		OrderID:           payment.OrderID,
+		GrossAmount:       gross,
+		TransactionStatus: "settlement",
+	}
+	notification := callback(payment, "settlement", gross)
+
+	if rec := env.do(http.MethodPost, "/midtrans/callback", notification); rec.Code != http.StatusOK {
+		t.Fatalf("MidtransCallback = %d %s, want 200", rec.Code, rec.Body)
+	}
+	var stored models.Payment
+	env.db.First(&stored, "id = ?", payment.ID)
+	if stored.Status != models.PaymentStatusSuccess || stored.PaidAt == nil {
+		t.Errorf("payment is %s (paid at %v), want SUCCESS with paid_at", stored.Status, stored.PaidAt)
+	}
+	want := "payment.created,payment.status.updated,payment.success,stock.reduction"
+	if got := strings.Join(env.eventTypes(), ","); got != want {
+		t.Errorf("published %q, want %q", got, want)
+	}
+
+	// Midtrans retrying the same notification changes nothing
+	if rec := env.do(http.MethodPost, "/midtrans/callback", notification); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Duplicate") {
+		t.Errorf("repeated MidtransCallback = %d %s, want 200 duplicate", rec.Code, rec.Body)
+	}
+	if len(env.events.Events) != 4 {
+		t.Errorf("repeated callback published %v", env.eventTypes()[4:])
+	}
+}
+
+// A bank transfer paid days after the charge is accepted, the age counts from settlement_time
+func TestMidtransCallbackLateSettlement(t *testing.T) {
+	t.Setenv("MIDTRANS_CALLBACK_MAX_AGE", "1h")
+	env := newTestEnv(t)
+	env.midtrans.ChargeResponse = &services.MidtransChargeResponse{TransactionID: "trx-1", TransactionStatus: "pending"}
+	payment := env.createPayment(t, models.PaymentMethodQRIS, nil)
+
+	gross := fmt.Sprintf("%d.00", testPrice)
+	env.midtrans.StatusResponse = &services.MidtransStatusResponse{OrderID: payment.OrderID, GrossAmount: gross, TransactionStatus: "settlement"}
+	notification := callback(payment, "settlement", gross)
+	notification.TransactionTime = midtransTime(time.Now().Add(-72 * time.Hour))
+	notification.SettlementTime = midtransTime(time.Now())
+
+	if rec := env.do(http.MethodPost, "/midtrans/callback", notification); rec.Code != http.StatusOK {
+		t.Fatalf("MidtransCallback = %d %s, want 200", rec.Code, rec.Body)
+	}
+	var stored models.Payment
+	env.db.First(&stored, "id = ?", payment.ID)
+	if stored.Status != models.PaymentStatusSuccess {
+		t.Errorf("late settlement left the payment %s", stored.Status)
+	}
+}
+
+func TestMidtransCallbackRejected(t *testing.T) {
+	gross := fmt.Sprintf("%d.00", testPrice)
+	tests := []struct {
+		name      string
+		maxAge    string
+		signature bool
+		gross     string
+		sentAt    time.Time
+		settledAt time.Time
+		wantError string
+	}{
+		{name: "invalid signature", gross: gross, wantError: "Invalid signature"},
+		{name: "gross amount mismatch", signature: true, gross: "1.00", wantError: "Gross amount mismatch"},
+		{name: "malformed gross amount", signature: true, gross: "fifty", wantError: "Gross amount mismatch"},
+		{name: "expired", maxAge: "1h", signature: true, gross: gross, sentAt: time.Now().Add(-2 * time.Hour), wantError: "Callback expired"},
+		{
+			name:      "settlement expired",
+			maxAge:    "1h",
+			signature: true,
+			gross:     gross,
+			sentAt:    time.Now().Add(-72 * time.Hour),
+			settledAt: time.Now().Add(-2 * time.Hour),
+			wantError: "Callback expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MIDTRANS_CALLBACK_MAX_AGE", tt.maxAge)
+			env := newTestEnv(t)
+			env.midtrans.ChargeResponse = &services.MidtransChargeResponse{TransactionID: "trx-1", TransactionStatus: "pending"}
+			payment := env.createPayment(t, models.PaymentMethodQRIS, nil)
+			env.midtrans.ValidSignature = tt.signature
+			env.midtrans.StatusResponse = &services.MidtransStatusResponse{OrderID: payment.OrderID, GrossAmount: gross, TransactionStatus: "settlement"}
+
+			notification := callback(payment, "settlement", tt.gross)
+			if !tt.sentAt.IsZero() {
+				notification.TransactionTime = midtransTime(tt.sentAt)
+			}
+			if !tt.settledAt.IsZero() {
+				notification.SettlementTime = midtransTime(tt.settledAt)
+			}
+			rec := env.do(http.MethodPost, "/midtrans/callback", notification)
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), tt.wantError) {
+				t.Fatalf("MidtransCallback = %d %s, want 400 %q", rec.Code, rec.Body, tt.wantError)
+			}
+
+			var stored models.Payment
+			env.db.First(&stored, "id = ?", payment.ID)
+			if stored.Status != models.PaymentStatusPending {
+				t.Errorf("rejected callback moved the payment to %s", stored.Status)
+			}
+			if got := strings.Join(env.eventTypes(), ","); got != "payment.created" {
+				t.Errorf("rejected callback published %q", got)
+			}
+
+			// A rejected notification isn't remembered, Midtrans' valid retry is processed
+			env.midtrans.ValidSignature = true
+			notification.GrossAmount = gross
+			notification.TransactionTime = midtransTime(time.Now())
+			if rec := env.do(http.MethodPost, "/midtrans/callback", notification); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "Duplicate") {
+				t.Errorf("valid retry = %d %s, want 200 processed", rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+// discardStdout drops what the handler prints while tb runs, in a benchmark it would end up
+// in the middle of the result line
+func discardStdout(tb testing.TB) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		tb.Fatalf("failed to open %s: %v", os.DevNull, err)
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	tb.Cleanup(func() {
+		os.Stdout = stdout
+		devNull.Close()
+	})
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
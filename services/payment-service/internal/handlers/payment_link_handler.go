@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"payment-service/internal/flags"
+	"payment-service/internal/models"
+	"payment-service/internal/money"
+	"payment-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PaymentLinkHandler handles shareable payment links: a purchaser creates a link for an
+// order and anyone holding it can pay through the regular Midtrans charge flow
+type PaymentLinkHandler struct {
+	payments   *PaymentHandler
+	linkRepo   *repository.PaymentLinkRepository
+	flags      *flags.Store
+	baseURL    string        // PAYMENT_LINK_BASE_URL, the frontend page the token is appended to
+	defaultTTL time.Duration // PAYMENT_LINK_TTL
+}
+
+// NewPaymentLinkHandler creates a new payment link handler
+func NewPaymentLinkHandler(payments *PaymentHandler, linkRepo *repository.PaymentLinkRepository, flagStore *flags.Store) *PaymentLinkHandler {
+	defaultTTL := 72 * time.Hour
+	if value := os.Getenv("PAYMENT_LINK_TTL"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			defaultTTL = parsed
+		} else {
+			fmt.Printf("⚠️ Ignoring invalid PAYMENT_LINK_TTL=%q\n", value)
+		}
+	}
+
+	return &PaymentLinkHandler{
+		payments:   payments,
+		linkRepo:   linkRepo,
+		flags:      flagStore,
+		baseURL:    os.Getenv("PAYMENT_LINK_BASE_URL"),
+		defaultTTL: defaultTTL,
+	}
+}
+
+// CreateLink creates a payment link for the authenticated purchaser. The token is only
+// returned here. Creation is gated by the enable_payment_links flag, existing links stay payable.
+func (lh *PaymentLinkHandler) CreateLink(c *gin.Context) {
+	creatorID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return
+	}
+
+	if !lh.flags.Enabled(flags.PaymentLinks, creatorID.String()) {
+		respondError(c, http.StatusNotFound, "PAYMENT_LINKS_UNAVAILABLE", nil)
+		return
+	}
+
+	var req models.CreatePaymentLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	charge, err := lh.payments.charges.Build(money.New(req.Amount, money.IDR), money.New(req.AdminFee, money.IDR))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_AMOUNT", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	product, err := lh.payments.getProductFromService(*req.ProductID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "PRODUCT_NOT_FOUND", nil)
+		return
+	}
+	if !product.IsActive {
+		respondError(c, http.StatusBadRequest, "PRODUCT_INACTIVE", nil)
+		return
+	}
+
+	// A link sells one unit at one price, it has no way to choose a variant
+	if product.HasVariants() {
+		respondError(c, http.StatusBadRequest, "PAYMENT_LINK_VARIANTS_UNSUPPORTED", nil)
+		return
+	}
+
+	// Links are paid by guests, member-only pricing rules don't apply
+	if !lh.payments.checkAmount(c, product, nil, 1, req.Amount, false) {
+		return
+	}
+
+	token, err := generateLinkToken()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "PAYMENT_LINK_GENERATE_FAILED", nil)
+		return
+	}
+
+	ttl := lh.defaultTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	link := &models.PaymentLink{
+		ID:          uuid.New(),
+		TokenHash:   models.HashPaymentLinkToken(token),
+		CreatorID:   creatorID,
+		ProductID:   *req.ProductID,
+		Amount:      req.Amount,
+		AdminFee:    charge.AdminFee.Minor,
+		Discount:    charge.Discount.Minor,
+		Tax:         charge.Tax.Minor,
+		Rounding:    charge.Rounding.Minor,
+		TotalAmount: charge.Total.Minor,
+		Currency:    charge.Total.Currency,
+		Notes:       req.Notes,
+		Status:      models.PaymentLinkActive,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+	if err := lh.linkRepo.Create(link); err != nil {
+		respondError(c, http.StatusInternalServerError, "PAYMENT_LINK_CREATE_FAILED", nil)
+		return
+	}
+
+	fmt.Printf("🔗 Payment link %s created by user %s (expires %s)\n", link.ID, creatorID, link.ExpiresAt.Format(time.RFC3339))
+
+	data := gin.H{
+		"id":           link.ID,
+		"token":        token,
+		"total_amount": link.TotalAmount,
+		"currency":     link.Currency,
+		"expires_at":   link.ExpiresAt,
+	}
+	if lh.baseURL != "" {
+		data["url"] = strings.TrimSuffix(lh.baseURL, "/") + "/" + token
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// ListLinks returns the authenticated purchaser's payment links (without tokens)
+func (lh *PaymentLinkHandler) ListLinks(c *gin.Context) {
+	creatorID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return
+	}
+
+	links, err := lh.linkRepo.ListByCreator(creatorID, 100)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "PAYMENT_LINKS_FETCH_FAILED", nil)
+		return
+	}
+
+	for i := range links {
+		links[i].Status = links[i].EffectiveStatus()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    links,
+	})
+}
+
+// CancelLink cancels one of the purchaser's active links. The route parameter is the link
+// ID; it shares the :token name with the public routes as gin requires.
+func (lh *PaymentLinkHandler) CancelLink(c *gin.Context) {
+	creatorID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return
+	}
+
+	linkID, err := uuid.Parse(c.Param("token"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PAYMENT_LINK_ID", nil)
+		return
+	}
+
+	cancelled, err := lh.linkRepo.Cancel(linkID, creatorID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "PAYMENT_LINK_CANCEL_FAILED", nil)
+		return
+	}
+	if !cancelled {
+		respondError(c, http.StatusNotFound, "NO_ACTIVE_PAYMENT_LINK", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Payment link cancelled",
+	})
+}
+
+// GetLinkPage returns the public data shown on the payment page of a link
+func (lh *PaymentLinkHandler) GetLinkPage(c *gin.Context) {
+	link, ok := lh.loadLink(c)
+	if !ok {
+		return
+	}
+
+	page := models.PaymentLinkPage{
+		Status:      link.EffectiveStatus(),
+		ProductID:   link.ProductID,
+		Amount:      link.Amount,
+		AdminFee:    link.AdminFee,
+		Discount:    link.Discount,
+		Tax:         link.Tax,
+		Rounding:    link.Rounding,
+		TotalAmount: link.TotalAmount,
+		Currency:    link.Currency,
+		Notes:       link.Notes,
+		ExpiresAt:   link.ExpiresAt,
+	}
+
+	// Best effort: the page still renders without product or requester details
+	if product, err := lh.payments.getProductFromService(link.ProductID); err == nil {
+		page.ProductName = product.Name
+	}
+	if creator, err := lh.payments.getUserFromService(link.CreatorID); err == nil {
+		page.RequestedBy = creator.Username
+	}
+	if link.PaymentID != nil {
+		if payment, err := lh.payments.paymentRepo.GetByID(c.Request.Context(), *link.PaymentID); err == nil {
+			page.PaymentStatus = &payment.Status
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    page,
+	})
+}
+
+// PayLink redeems a link: the payer picks a payment method and a Midtrans charge is created
+// for the purchaser's order. A link can be paid once; a failed charge makes it payable again.
+func (lh *PaymentLinkHandler) PayLink(c *gin.Context) {
+	link, ok := lh.loadLink(c)
+	if !ok {
+		return
+	}
+
+	if status := link.EffectiveStatus(); status != models.PaymentLinkActive {
+		respondError(c, http.StatusGone, "PAYMENT_LINK_NOT_PAYABLE", gin.H{
+			"details": string(status),
+		})
+		return
+	}
+
+	var req models.RedeemPaymentLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+	// Links are paid right away through Midtrans, invoices have their own flow
+	if !req.PaymentMethod.IsValid() || req.PaymentMethod == models.PaymentMethodInvoice {
+		respondError(c, http.StatusBadRequest, "INVALID_PAYMENT_METHOD", nil)
+		return
+	}
+
+	creator, err := lh.payments.getUserFromService(link.CreatorID)
+	if err != nil {
+		fmt.Printf("❌ Failed to get creator of payment link %s: %v\n", link.ID, err)
+		respondError(c, http.StatusInternalServerError, "USER_LOOKUP_FAILED", nil)
+		return
+	}
+
+	product, err := lh.payments.getProductForCharge(link.ProductID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "PRODUCT_NOT_FOUND", nil)
+		return
+	}
+	if !product.IsActive || product.HasVariants() {
+		respondError(c, http.StatusBadRequest, "PRODUCT_UNAVAILABLE", nil)
+		return
+	}
+	// The product may be cached, its stock is asked from Product-Service and checked against
+	// the pending payments under the product's checkout lock before charging
+	release, ok := lh.payments.lockCharge(c, link.ProductID, nil)
+	if !ok {
+		return
+	}
+	defer release()
+	availability, err := lh.payments.getProductAvailability(link.ProductID, nil, 1)
+	if err == nil {
+		var pending int
+		if pending, err = lh.payments.paymentRepo.PendingQuantity(c.Request.Context(), link.ProductID, nil); err == nil {
+			availability.InStock = 1 <= availability.MaxQuantity-pending
+		}
+	}
+	if err != nil {
+		fmt.Printf("⚠️ Availability check failed for product %s: %v\n", link.ProductID, err)
+		respondError(c, http.StatusServiceUnavailable, "STOCK_UNAVAILABLE", gin.H{
+			"details": "the current stock could not be verified, try again shortly",
+		})
+		return
+	}
+	if !availability.IsActive || !availability.InStock {
+		respondError(c, http.StatusBadRequest, "PRODUCT_UNAVAILABLE", nil)
+		return
+	}
+
+	// Midtrans addresses the payer, the payment itself belongs to the purchaser
+	customer := *creator
+	if req.PayerName != "" || req.PayerEmail != "" {
+		customer.PhoneNumber = nil // the creator's number doesn't belong to another payer
+	}
+	if req.PayerName != "" {
+		customer.Username = req.PayerName
+	}
+	if req.PayerEmail != "" {
+		customer.Email = req.PayerEmail
+	}
+	if req.PayerPhone != "" {
+		customer.PhoneNumber = &req.PayerPhone
+	}
+
+	productID := link.ProductID
+	payment := &models.Payment{
+		ID:             uuid.New(),
+		OrderID:        fmt.Sprintf("Order_%d", time.Now().UnixNano()),
+		UserID:         link.CreatorID,
+		ProductID:      &productID,
+		Amount:         link.Amount,
+		AdminFee:       link.AdminFee,
+		DiscountAmount: link.Discount,
+		TaxAmount:      link.Tax,
+		RoundingAmount: link.Rounding,
+		TotalAmount:    link.TotalAmount,
+		Currency:       link.Currency,
+		PaymentMethod:  req.PaymentMethod,
+		PaymentType:    "midtrans",
+		Status:         models.PaymentStatusPending,
+		Notes:          link.Notes,
+		BankType:       req.BankType,
+		StoreType:      req.StoreType,
+	}
+
+	redeemed, err := lh.linkRepo.Redeem(link.ID, payment.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "PAYMENT_LINK_REDEEM_FAILED", nil)
+		return
+	}
+	if !redeemed {
+		respondError(c, http.StatusConflict, "PAYMENT_LINK_USED", nil)
+		return
+	}
+
+	updatedPayment, midtransResp, ok := lh.payments.chargePayment(c, payment, &customer, product)
+	if !ok {
+		if err := lh.linkRepo.Release(link.ID, payment.ID); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+		}
+		return
+	}
+
+	fmt.Printf("🔗 Payment link %s redeemed as order %s\n", link.ID, updatedPayment.OrderID)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    chargeResponseData(updatedPayment, midtransResp),
+	})
+}
+
+// loadLink resolves the :token parameter, writing a 404 when it doesn't exist
+func (lh *PaymentLinkHandler) loadLink(c *gin.Context) (*models.PaymentLink, bool) {
+	link, err := lh.linkRepo.GetByToken(c.Param("token"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, "PAYMENT_LINK_NOT_FOUND", nil)
+		return nil, false
+	}
+	return link, true
+}
+
+// generateLinkToken returns a random URL safe token
+func generateLinkToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"payment-service/internal/dbpool"
+	"payment-service/internal/models"
+	"payment-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultStatsWindows are the rolling windows the per method rates are computed over
+var defaultStatsWindows = []time.Duration{15 * time.Minute, time.Hour, 24 * time.Hour}
+
+// PaymentStatsHandler serves payment statistics to admins and to Prometheus
+type PaymentStatsHandler struct {
+	paymentRepo *repository.PaymentRepository
+	windows     []time.Duration
+	cacheTTL    time.Duration
+
+	mu        sync.Mutex
+	snapshot  []models.PaymentMethodWindowStats
+	fetchedAt time.Time
+}
+
+// NewPaymentStatsHandler creates a new payment stats handler. The per method stats are
+// queried at most once per cacheTTL, so frequent scrapes don't hit the database.
+func NewPaymentStatsHandler(paymentRepo *repository.PaymentRepository, windows []time.Duration, cacheTTL time.Duration) *PaymentStatsHandler {
+	if len(windows) == 0 {
+		windows = defaultStatsWindows
+	}
+	return &PaymentStatsHandler{
+		paymentRepo: paymentRepo,
+		windows:     windows,
+		cacheTTL:    cacheTTL,
+	}
+}
+
+// StatsWindowsFromEnv reads PAYMENT_STATS_WINDOWS, a comma separated list of durations
+// (default 15m,1h,24h)
+func StatsWindowsFromEnv() ([]time.Duration, error) {
+	value := os.Getenv("PAYMENT_STATS_WINDOWS")
+	if value == "" {
+		return defaultStatsWindows, nil
+	}
+
+	var windows []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		window, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("invalid PAYMENT_STATS_WINDOWS entry %q", part)
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// GetStats returns the payment counts and amounts per status and the last day per method
+func (sh *PaymentStatsHandler) GetStats(c *gin.Context) {
+	stats, err := sh.paymentRepo.GetPaymentStats(c.Request.Context())
+	if err != nil {
+		fmt.Printf("❌ Failed to get payment stats: %v\n", err)
+		respondError(c, http.StatusInternalServerError, "PAYMENT_STATS_FAILED", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// GetMethodStats returns the success, failure and expiry rates per payment method for every
+// window, or only for ?window= (e.g. 1h)
+func (sh *PaymentStatsHandler) GetMethodStats(c *gin.Context) {
+	snapshot, err := sh.methodStats(c.Request.Context())
+	if err != nil {
+		fmt.Printf("❌ Failed to get payment method stats: %v\n", err)
+		respondError(c, http.StatusInternalServerError, "PAYMENT_METHOD_STATS_FAILED", nil)
+		return
+	}
+
+	if windowStr := c.Query("window"); windowStr != "" {
+		window, err := time.ParseDuration(windowStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_WINDOW", nil)
+			return
+		}
+		var selected []models.PaymentMethodWindowStats
+		for _, stats := range snapshot {
+			if stats.Window == window.String() {
+				selected = append(selected, stats)
+			}
+		}
+		if len(selected) == 0 {
+			respondError(c, http.StatusBadRequest, "UNKNOWN_WINDOW", gin.H{
+				"details": "available: " + sh.windowList(),
+			})
+			return
+		}
+		snapshot = selected
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    snapshot,
+	})
+}
+
+// Metrics writes the per method counts and rates, and the database pool stats, in the
+// Prometheus text format
+func (sh *PaymentStatsHandler) Metrics(c *gin.Context) {
+	snapshot, err := sh.methodStats(c.Request.Context())
+	if err != nil {
+		fmt.Printf("❌ Failed to get payment method stats for metrics: %v\n", err)
+		c.String(http.StatusInternalServerError, "failed to get payment method stats\n")
+		return
+	}
+
+	var b strings.Builder
+	writeGauge := func(name, help string, value func(models.PaymentMethodStats) float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, window := range snapshot {
+			for _, stats := range window.Methods {
+				fmt.Fprintf(&b, "%s{method=%q,bank=%q,window=%q} %g\n",
+					name, stats.PaymentMethod, stats.BankType, window.Window, value(stats))
+			}
+		}
+	}
+
+	writeGauge("payment_method_payments", "Payments created in the window per method and bank.",
+		func(s models.PaymentMethodStats) float64 { return float64(s.Total) })
+	fmt.Fprintf(&b, "# HELP payment_method_outcomes Payments created in the window per method, bank and status.\n# TYPE payment_method_outcomes gauge\n")
+	for _, window := range snapshot {
+		for _, stats := range window.Methods {
+			for _, outcome := range []struct {
+				status string
+				count  int64
+			}{
+				{"success", stats.Success},
+				{"failed", stats.Failed},
+				{"expired", stats.Expired},
+				{"cancelled", stats.Cancelled},
+				{"pending", stats.Pending},
+			} {
+				fmt.Fprintf(&b, "payment_method_outcomes{method=%q,bank=%q,window=%q,status=%q} %d\n",
+					stats.PaymentMethod, stats.BankType, window.Window, outcome.status, outcome.count)
+			}
+		}
+	}
+	writeGauge("payment_method_success_rate", "Share of the decided payments (success, failed, expired) that succeeded.",
+		func(s models.PaymentMethodStats) float64 { return s.SuccessRate })
+	writeGauge("payment_method_failure_rate", "Share of the decided payments (success, failed, expired) that failed.",
+		func(s models.PaymentMethodStats) float64 { return s.FailureRate })
+	writeGauge("payment_method_expiry_rate", "Share of the decided payments (success, failed, expired) that expired.",
+		func(s models.PaymentMethodStats) float64 { return s.ExpiryRate })
+	b.WriteString(dbpool.Prometheus())
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
+
+// methodStats returns the per method stats of every window, cached for cacheTTL
+func (sh *PaymentStatsHandler) methodStats(ctx context.Context) ([]models.PaymentMethodWindowStats, error) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if sh.snapshot != nil && time.Since(sh.fetchedAt) < sh.cacheTTL {
+		return sh.snapshot, nil
+	}
+
+	now := time.Now()
+	snapshot := make([]models.PaymentMethodWindowStats, 0, len(sh.windows))
+	for _, window := range sh.windows {
+		since := now.Add(-window)
+		methods, err := sh.paymentRepo.GetMethodStats(ctx, since)
+		if err != nil {
+			return nil, err
+		}
+		if methods == nil {
+			methods = []models.PaymentMethodStats{}
+		}
+		snapshot = append(snapshot, models.PaymentMethodWindowStats{
+			Window:  window.String(),
+			Since:   since.UTC().Format(time.RFC3339),
+			Methods: methods,
+		})
+	}
+
+	sh.snapshot = snapshot
+	sh.fetchedAt = now
+	return snapshot, nil
+}
+
+// windowList lists the configured windows for error messages
+func (sh *PaymentStatsHandler) windowList() string {
+	names := make([]string, len(sh.windows))
+	for i, window := range sh.windows {
+		names[i] = window.String()
+	}
+	return strings.Join(names, ", ")
+}
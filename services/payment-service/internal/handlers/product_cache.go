@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"payment-service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// cachedProduct is a Product-Service lookup kept in the cache, with the time it was fetched
+// so charges can insist on a recent copy
+type cachedProduct struct {
+	Product   models.Product `json:"product"`
+	FetchedAt time.Time      `json:"fetched_at"`
+}
+
+// productCacheConfigFromEnv reads PRODUCT_CACHE_TTL (default 30s, 0 disables the cache) and
+// PRODUCT_CACHE_CHARGE_MAX_AGE (default 5s), the oldest cached product a payment is charged for
+func productCacheConfigFromEnv() (ttl, chargeMaxAge time.Duration) {
+	ttl = 30 * time.Second
+	if value := os.Getenv("PRODUCT_CACHE_TTL"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			ttl = parsed
+		} else {
+			fmt.Printf("⚠️ Ignoring invalid PRODUCT_CACHE_TTL=%q\n", value)
+		}
+	}
+
+	chargeMaxAge = 5 * time.Second
+	if value := os.Getenv("PRODUCT_CACHE_CHARGE_MAX_AGE"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			chargeMaxAge = parsed
+		} else {
+			fmt.Printf("⚠️ Ignoring invalid PRODUCT_CACHE_CHARGE_MAX_AGE=%q\n", value)
+		}
+	}
+	return ttl, chargeMaxAge
+}
+
+// getProductFromService returns a product, from the cache when it was looked up within
+// PRODUCT_CACHE_TTL. product.updated and product.stock.reduced drop the cached copy.
+func (ph *PaymentHandler) getProductFromService(productID uuid.UUID) (*models.Product, error) {
+	return ph.getProduct(productID, ph.productCacheTTL)
+}
+
+// getProductForCharge returns a product a payment is about to be charged for. A cached copy
+// is only used when fetched within PRODUCT_CACHE_CHARGE_MAX_AGE; stock and price are checked
+// with Product-Service before charging either way.
+func (ph *PaymentHandler) getProductForCharge(productID uuid.UUID) (*models.Product, error) {
+	return ph.getProduct(productID, ph.productChargeMaxAge)
+}
+
+// getProduct returns the cached product when it is at most maxAge old, otherwise it is
+// fetched and cached again. Lookup failures, unknown products included, aren't cached.
+func (ph *PaymentHandler) getProduct(productID uuid.UUID, maxAge time.Duration) (*models.Product, error) {
+	ctx := context.Background()
+
+	if ph.productCacheTTL > 0 && maxAge > 0 {
+		var cached cachedProduct
+		if err := ph.cacheSvc.GetProduct(ctx, productID.String(), &cached); err == nil && time.Since(cached.FetchedAt) <= maxAge {
+			return &cached.Product, nil
+		}
+	}
+
+	product, err := ph.fetchProduct(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ph.productCacheTTL > 0 {
+		entry := cachedProduct{Product: *product, FetchedAt: time.Now()}
+		if err := ph.cacheSvc.SetProduct(ctx, productID.String(), entry, ph.productCacheTTL); err != nil {
+			fmt.Printf("⚠️ Failed to cache product %s: %v\n", productID, err)
+		}
+	}
+	return product, nil
+}
+
+// dropStaleProduct removes a cached product that a fresh Product-Service answer contradicted,
+// e.g. when the product changed before its event arrived
+func (ph *PaymentHandler) dropStaleProduct(productID uuid.UUID, reason string) {
+	if ph.productCacheTTL == 0 {
+		return
+	}
+	if err := ph.cacheSvc.DeleteProduct(context.Background(), productID.String()); err != nil {
+		fmt.Printf("⚠️ Failed to drop stale product %s: %v\n", productID, err)
+		return
+	}
+	fmt.Printf("🧹 Dropped cached product %s, %s\n", productID, reason)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"payment-service/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondError writes a localized error response keyed by an error code. "error" stays in
+// English for logs and existing clients, "message" follows the negotiated locale and "code"
+// is stable for programmatic handling. fields (details, data, ...) are added as they are.
+func respondError(c *gin.Context, status int, code string, fields gin.H) {
+	respondErrorWithMessage(c, status, code, code, fields)
+}
+
+// respondErrorWithMessage is like respondError but uses a separate catalog key for the user-facing message
+func respondErrorWithMessage(c *gin.Context, status int, code, messageKey string, fields gin.H) {
+	body := gin.H{
+		"success": false,
+		"error":   i18n.T(i18n.LocaleEN, code),
+		"message": i18n.T(i18n.FromContext(c), messageKey),
+		"code":    code,
+	}
+	for key, value := range fields {
+		body[key] = value
+	}
+	c.JSON(status, body)
+}
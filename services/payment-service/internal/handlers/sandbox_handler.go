@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"payment-service/internal/cache"
+	"payment-service/internal/models"
+	"payment-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Fixture records for frontend end to end tests: a pending payment of the User-Service
+// fixture buyer for one unit of the Product-Service fixture product
+var (
+	FixtureBuyerID   = uuid.MustParse("00000000-0000-4000-8000-000000000001")
+	FixtureStoreID   = uuid.MustParse("00000000-0000-4000-8000-000000000101")
+	FixtureProductID = uuid.MustParse("00000000-0000-4000-8000-000000000201")
+	FixturePaymentID = uuid.MustParse("00000000-0000-4000-8000-000000000301")
+)
+
+// FixtureOrderID is the order ID of the fixture payment
+const FixtureOrderID = "E2E-ORDER-0001"
+
+// SandboxHandler serves the demo environment tools, registered only with SANDBOX_TOOLS
+type SandboxHandler struct {
+	paymentRepo *repository.PaymentRepository
+	cacheSvc    *cache.CacheService
+}
+
+// NewSandboxHandler creates a new sandbox handler
+func NewSandboxHandler(paymentRepo *repository.PaymentRepository, cacheSvc *cache.CacheService) *SandboxHandler {
+	return &SandboxHandler{
+		paymentRepo: paymentRepo,
+		cacheSvc:    cacheSvc,
+	}
+}
+
+// ResetSandbox handles POST /api/v1/admin/sandbox/reset: every payment, payment link,
+// callback, webhook delivery and logged event is deleted and the payment cache cleared
+func (sh *SandboxHandler) ResetSandbox(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), time.Minute)
+	defer cancel()
+
+	if err := sh.paymentRepo.ResetPaymentData(ctx); err != nil {
+		respondError(c, http.StatusInternalServerError, "SANDBOX_RESET_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+	cleared, err := sh.cacheSvc.ClearPayments(ctx)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "PAYMENT_CACHE_CLEAR_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	log.Printf("🧹 Sandbox payment data reset, %d cache entries cleared", cleared)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"cache_entries_cleared": cleared,
+		},
+	})
+}
+
+// CreateFixtures handles POST /api/v1/dev/fixtures: the fixture payment is created, or
+// restored to PENDING with a fresh expiry. It exists only here, Midtrans never sees it;
+// settle it with the Midtrans callback simulator.
+func (sh *SandboxHandler) CreateFixtures(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	expiry := now.Add(24 * time.Hour)
+	productID := FixtureProductID
+	storeID := FixtureStoreID
+	bank := "bca"
+	vaNumber := "12345000000301"
+	payment := &models.Payment{
+		ID:            FixturePaymentID,
+		OrderID:       FixtureOrderID,
+		OrderRef:      FixtureOrderID,
+		UserID:        FixtureBuyerID,
+		ProductID:     &productID,
+		StoreID:       &storeID,
+		Quantity:      1,
+		Amount:        100000,
+		TotalAmount:   100000,
+		Currency:      "IDR",
+		PaymentMethod: models.PaymentMethodBankTransfer,
+		PaymentType:   string(models.PaymentMethodBankTransfer),
+		Status:        models.PaymentStatusPending,
+		BankType:      &bank,
+		VANumber:      &vaNumber,
+		ExpiryTime:    &expiry,
+		CreatedAt:     now,
+	}
+
+	// Save inserts the payment or overwrites every column of the previous fixture
+	if err := sh.paymentRepo.Update(ctx, payment); err != nil {
+		respondError(c, http.StatusInternalServerError, "FIXTURES_CREATE_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+	if err := sh.cacheSvc.InvalidatePaymentCache(ctx, payment.ID.String(), payment.OrderID, payment.UserID.String()); err != nil {
+		log.Printf("⚠️ Fixture payment %s may be served stale from cache: %v", payment.OrderID, err)
+	}
+
+	log.Printf("🧪 Fixture payment %s ready", payment.OrderID)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"payment": payment.ToResponse(),
+		},
+	})
+}
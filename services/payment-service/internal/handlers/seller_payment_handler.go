@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"payment-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// errProductNotFound is returned by getProductFromService for unknown products
+var errProductNotFound = errors.New("product not found")
+
+// GetSellerPayments handles GET /api/v1/payments/seller?product_id=: the payments for one
+// of the seller's products, newest first, with the filters and pagination of
+// GET /payments/user. Product-Service is asked who owns the product on every request.
+func (ph *PaymentHandler) GetSellerPayments(c *gin.Context) {
+	sellerID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return
+	}
+
+	productID, err := uuid.Parse(c.Query("product_id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PRODUCT_ID", gin.H{
+			"details": "product_id is required",
+		})
+		return
+	}
+
+	product, err := ph.getProductFromService(productID)
+	if err != nil {
+		if errors.Is(err, errProductNotFound) {
+			respondError(c, http.StatusNotFound, "PRODUCT_NOT_FOUND", nil)
+			return
+		}
+		respondError(c, http.StatusBadGateway, "PRODUCT_FETCH_FAILED", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if product.OwnerID != sellerID {
+		respondError(c, http.StatusForbidden, "PRODUCT_NOT_OWNED", nil)
+		return
+	}
+
+	query, err := parseUserPaymentQuery(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_QUERY", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+	query.ProductID = &productID
+
+	payments, total, err := ph.paymentRepo.GetAll(c.Request.Context(), query)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "PAYMENTS_FETCH_FAILED", nil)
+		return
+	}
+
+	responses := make([]models.SellerPaymentResponse, len(payments))
+	for i, payment := range payments {
+		responses[i] = payment.ToSellerResponse()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": models.SellerPaymentListResponse{
+			ProductID: productID,
+			Payments:  responses,
+			Total:     total,
+			Page:      query.Page,
+			Limit:     query.Limit,
+			HasMore:   int64(query.Page*query.Limit) < total,
+		},
+	})
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"payment-service/internal/serviceauth"
+)
+
+// SetServiceAuth signs requests to the other services with a service token
+func (ph *PaymentHandler) SetServiceAuth(issuer *serviceauth.Issuer) {
+	ph.serviceAuth = issuer
+}
+
+// doServiceRequest performs a bodyless request to another internal service following the
+// INTERNAL_SERVICE_* policy: network errors and 5xx responses are retried, anything else
+// is returned to the caller
+func (ph *PaymentHandler) doServiceRequest(req *http.Request) (*http.Response, error) {
+	if ph.serviceAuth != nil {
+		audience := serviceauth.ProductService
+		if strings.HasPrefix(req.URL.String(), ph.userServiceURL) {
+			audience = serviceauth.UserService
+		}
+		if err := ph.serviceAuth.Sign(req, audience); err != nil {
+			return nil, err
+		}
+	}
+	return ph.serviceClient.Retry(req, func(resp *http.Response, err error) bool {
+		return err != nil || resp.StatusCode >= 500
+	})
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"payment-service/internal/models"
+	"payment-service/internal/repository"
+	"payment-service/internal/secrets"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// errStoreNotFound is returned by getStoreFromService for unknown stores
+var errStoreNotFound = errors.New("store not found")
+
+// StoreCredentialsHandler lets sellers configure their store's own Midtrans keys. Payments
+// for the store's products are then charged, verified and checked with those keys.
+type StoreCredentialsHandler struct {
+	payments    *PaymentHandler
+	credentials *repository.StoreCredentialsRepository
+	box         *secrets.Box
+}
+
+// NewStoreCredentialsHandler creates a new store credentials handler
+func NewStoreCredentialsHandler(payments *PaymentHandler, credentials *repository.StoreCredentialsRepository, box *secrets.Box) *StoreCredentialsHandler {
+	return &StoreCredentialsHandler{
+		payments:    payments,
+		credentials: credentials,
+		box:         box,
+	}
+}
+
+// GetCredentials returns whether the store has its own keys, without the server key
+func (sh *StoreCredentialsHandler) GetCredentials(c *gin.Context) {
+	store, ok := sh.authorizeStoreOwner(c)
+	if !ok {
+		return
+	}
+
+	credentials, err := sh.credentials.Get(c.Request.Context(), store.ID)
+	if errors.Is(err, repository.ErrStoreCredentialsNotFound) {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data": gin.H{
+				"store_id":   store.ID,
+				"configured": false,
+			},
+		})
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "STORE_CREDENTIALS_FETCH_FAILED", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    credentialsResponse(credentials),
+	})
+}
+
+// PutCredentials stores the store's Midtrans keys, the server key encrypted
+func (sh *StoreCredentialsHandler) PutCredentials(c *gin.Context) {
+	if sh.box == nil {
+		respondError(c, http.StatusServiceUnavailable, "STORE_CREDENTIALS_UNAVAILABLE", gin.H{
+			"details": "DATA_ENCRYPTION_KEY is not configured",
+		})
+		return
+	}
+
+	store, ok := sh.authorizeStoreOwner(c)
+	if !ok {
+		return
+	}
+
+	var req models.StoreCredentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	serverKey := strings.TrimSpace(req.ServerKey)
+	encrypted, err := sh.box.Encrypt(serverKey)
+	if err != nil {
+		fmt.Printf("❌ Failed to encrypt Midtrans server key for store %s: %v\n", store.ID, err)
+		respondError(c, http.StatusInternalServerError, "STORE_CREDENTIALS_SAVE_FAILED", nil)
+		return
+	}
+
+	credentials := &models.StoreMidtransCredentials{
+		StoreID:            store.ID,
+		OwnerID:            store.OwnerID,
+		ServerKeyEncrypted: encrypted,
+		ServerKeyHint:      keyHint(serverKey),
+		ClientKey:          strings.TrimSpace(req.ClientKey),
+	}
+	if err := sh.credentials.Upsert(c.Request.Context(), credentials); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		respondError(c, http.StatusInternalServerError, "STORE_CREDENTIALS_SAVE_FAILED", nil)
+		return
+	}
+
+	fmt.Printf("🔑 Midtrans credentials configured for store %s\n", store.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    credentialsResponse(credentials),
+	})
+}
+
+// DeleteCredentials removes the store's keys, its payments use the platform keys again
+func (sh *StoreCredentialsHandler) DeleteCredentials(c *gin.Context) {
+	store, ok := sh.authorizeStoreOwner(c)
+	if !ok {
+		return
+	}
+
+	if err := sh.credentials.Delete(c.Request.Context(), store.ID); err != nil {
+		if errors.Is(err, repository.ErrStoreCredentialsNotFound) {
+			respondError(c, http.StatusNotFound, "STORE_CREDENTIALS_NOT_CONFIGURED", nil)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "STORE_CREDENTIALS_DELETE_FAILED", nil)
+		return
+	}
+
+	fmt.Printf("🔑 Midtrans credentials removed for store %s\n", store.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Store credentials removed, payments use the platform Midtrans account",
+	})
+}
+
+// authorizeStoreOwner loads the :id store from Product-Service and checks that the user set
+// by the API Gateway owns it
+func (sh *StoreCredentialsHandler) authorizeStoreOwner(c *gin.Context) (*models.Store, bool) {
+	userID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return nil, false
+	}
+
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_STORE_ID", nil)
+		return nil, false
+	}
+
+	store, err := sh.payments.getStoreFromService(storeID)
+	if err != nil {
+		if errors.Is(err, errStoreNotFound) {
+			respondError(c, http.StatusNotFound, "STORE_NOT_FOUND", nil)
+			return nil, false
+		}
+		respondError(c, http.StatusBadGateway, "STORE_FETCH_FAILED", gin.H{
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	if store.OwnerID != userID {
+		respondError(c, http.StatusForbidden, "STORE_NOT_OWNED", nil)
+		return nil, false
+	}
+
+	return store, true
+}
+
+// credentialsResponse describes configured credentials without the server key
+func credentialsResponse(credentials *models.StoreMidtransCredentials) gin.H {
+	return gin.H{
+		"store_id":        credentials.StoreID,
+		"configured":      true,
+		"client_key":      credentials.ClientKey,
+		"server_key_hint": credentials.ServerKeyHint,
+		"updated_at":      credentials.UpdatedAt,
+	}
+}
+
+// keyHint returns the last four characters of a key
+func keyHint(key string) string {
+	if len(key) <= 4 {
+		return strings.Repeat("*", len(key))
+	}
+	return "..." + key[len(key)-4:]
+}
+
+func (ph *PaymentHandler) getStoreFromService(storeID uuid.UUID) (*models.Store, error) {
+	url := fmt.Sprintf("%s/api/v1/stores/%s", ph.productServiceURL, storeID.String())
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := ph.doServiceRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request to product service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errStoreNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("product service returned status %d", resp.StatusCode)
+	}
+
+	var storeResp struct {
+		Success bool         `json:"success"`
+		Data    models.Store `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&storeResp); err != nil {
+		return nil, fmt.Errorf("failed to decode store response: %w", err)
+	}
+	if !storeResp.Success {
+		return nil, fmt.Errorf("product service returned error")
+	}
+
+	return &storeResp.Data, nil
+}
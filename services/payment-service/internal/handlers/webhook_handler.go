@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"payment-service/internal/models"
+	"payment-service/internal/repository"
+	"payment-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WebhookHandler handles admin management of merchant webhook endpoints
+type WebhookHandler struct {
+	webhookRepo *repository.WebhookRepository
+	webhookSvc  *services.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookRepo *repository.WebhookRepository, webhookSvc *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookRepo: webhookRepo,
+		webhookSvc:  webhookSvc,
+	}
+}
+
+// ListEndpoints returns every registered webhook endpoint
+func (wh *WebhookHandler) ListEndpoints(c *gin.Context) {
+	endpoints, err := wh.webhookRepo.ListEndpoints()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "WEBHOOK_ENDPOINTS_FETCH_FAILED", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    endpoints,
+	})
+}
+
+// GetEndpoint returns a single webhook endpoint
+func (wh *WebhookHandler) GetEndpoint(c *gin.Context) {
+	endpoint, ok := wh.loadEndpoint(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    endpoint,
+	})
+}
+
+// CreateEndpoint registers a new merchant endpoint. The signing secret is only returned here.
+func (wh *WebhookHandler) CreateEndpoint(c *gin.Context) {
+	var req models.CreateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	eventTypes, ok := validateEventTypes(c, req.EventTypes)
+	if !ok {
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := wh.webhookSvc.GenerateSecret()
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "WEBHOOK_SECRET_FAILED", nil)
+			return
+		}
+		secret = generated
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		URL:         req.URL,
+		Secret:      secret,
+		EventTypes:  eventTypes,
+		IsActive:    true,
+		Description: req.Description,
+	}
+
+	if err := wh.webhookRepo.CreateEndpoint(endpoint); err != nil {
+		respondError(c, http.StatusInternalServerError, "WEBHOOK_ENDPOINT_CREATE_FAILED", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    endpoint,
+		"secret":  secret,
+	})
+}
+
+// UpdateEndpoint changes an endpoint's settings and optionally rotates its secret
+func (wh *WebhookHandler) UpdateEndpoint(c *gin.Context) {
+	endpoint, ok := wh.loadEndpoint(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.Name != nil {
+		endpoint.Name = *req.Name
+	}
+	if req.URL != nil {
+		endpoint.URL = *req.URL
+	}
+	if req.EventTypes != nil {
+		eventTypes, ok := validateEventTypes(c, req.EventTypes)
+		if !ok {
+			return
+		}
+		endpoint.EventTypes = eventTypes
+	}
+	if req.IsActive != nil {
+		endpoint.IsActive = *req.IsActive
+	}
+	if req.Description != nil {
+		endpoint.Description = req.Description
+	}
+
+	response := gin.H{"success": true}
+	if req.RotateSecret {
+		secret, err := wh.webhookSvc.GenerateSecret()
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "WEBHOOK_SECRET_FAILED", nil)
+			return
+		}
+		endpoint.Secret = secret
+		response["secret"] = secret
+	}
+
+	if err := wh.webhookRepo.UpdateEndpoint(endpoint); err != nil {
+		respondError(c, http.StatusInternalServerError, "WEBHOOK_ENDPOINT_UPDATE_FAILED", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response["data"] = endpoint
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteEndpoint removes an endpoint and its delivery history
+func (wh *WebhookHandler) DeleteEndpoint(c *gin.Context) {
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_WEBHOOK_ENDPOINT_ID", nil)
+		return
+	}
+
+	if err := wh.webhookRepo.DeleteEndpoint(endpointID); err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		respondError(c, status, "WEBHOOK_ENDPOINT_DELETE_FAILED", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook endpoint deleted",
+	})
+}
+
+// ListDeliveries returns the delivery log of an endpoint, optionally filtered by status
+func (wh *WebhookHandler) ListDeliveries(c *gin.Context) {
+	endpoint, ok := wh.loadEndpoint(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	deliveries, total, err := wh.webhookRepo.GetDeliveriesByEndpoint(endpoint.ID, strings.ToUpper(c.Query("status")), page, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "WEBHOOK_DELIVERIES_FETCH_FAILED", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"deliveries": deliveries,
+			"total":      total,
+			"page":       page,
+			"limit":      limit,
+			"has_more":   int64(page*limit) < total,
+		},
+	})
+}
+
+// Redeliver queues a delivery again with its original payload
+func (wh *WebhookHandler) Redeliver(c *gin.Context) {
+	endpoint, ok := wh.loadEndpoint(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_WEBHOOK_DELIVERY_ID", nil)
+		return
+	}
+
+	delivery, err := wh.webhookRepo.GetDeliveryByID(deliveryID)
+	if err != nil || delivery.EndpointID != endpoint.ID {
+		respondError(c, http.StatusNotFound, "WEBHOOK_DELIVERY_NOT_FOUND", nil)
+		return
+	}
+
+	if !endpoint.IsActive {
+		respondError(c, http.StatusConflict, "WEBHOOK_ENDPOINT_INACTIVE", nil)
+		return
+	}
+
+	redelivery, err := wh.webhookSvc.Redeliver(delivery)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "REDELIVERY_QUEUE_FAILED", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Webhook redelivery queued",
+		"data":    redelivery,
+	})
+}
+
+// loadEndpoint parses the :id param and loads the endpoint, writing the error response on failure
+func (wh *WebhookHandler) loadEndpoint(c *gin.Context) (*models.WebhookEndpoint, bool) {
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_WEBHOOK_ENDPOINT_ID", nil)
+		return nil, false
+	}
+
+	endpoint, err := wh.webhookRepo.GetEndpointByID(endpointID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, "WEBHOOK_ENDPOINT_NOT_FOUND", nil)
+		return nil, false
+	}
+
+	return endpoint, true
+}
+
+// validateEventTypes checks the requested events are supported and joins them for storage
+func validateEventTypes(c *gin.Context, eventTypes []string) (string, bool) {
+	supported := make(map[string]bool, len(models.WebhookEventTypes))
+	for _, eventType := range models.WebhookEventTypes {
+		supported[eventType] = true
+	}
+
+	seen := make(map[string]bool, len(eventTypes))
+	var cleaned []string
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if !supported[eventType] {
+			respondError(c, http.StatusBadRequest, "UNSUPPORTED_EVENT_TYPE", gin.H{
+				"event_type": eventType,
+				"details":    "Supported event types: " + strings.Join(models.WebhookEventTypes, ", "),
+			})
+			return "", false
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			cleaned = append(cleaned, eventType)
+		}
+	}
+
+	if len(cleaned) == 0 {
+		respondError(c, http.StatusBadRequest, "EVENT_TYPE_REQUIRED", nil)
+		return "", false
+	}
+
+	return strings.Join(cleaned, ","), true
+}
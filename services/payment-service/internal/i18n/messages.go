@@ -0,0 +1,318 @@
+package i18n
+
+// catalogs holds the translated messages for every supported locale. Keys are the
+// error codes returned to clients, the English messages are the "error" of the response.
+var catalogs = map[Locale]map[string]string{
+	LocaleEN: {
+		// Generic errors
+		"INVALID_REQUEST":        "Invalid request format",
+		"USER_NOT_AUTHENTICATED": "User not authenticated",
+		"INVALID_USER_ID":        "Invalid user ID",
+		"USER_LOOKUP_FAILED":     "Failed to get user data",
+
+		// Orders
+		"ORDER_ALREADY_PAID":  "Order already paid",
+		"ORDER_NOT_FOUND":     "Order not found",
+		"ORDER_LOOKUP_FAILED": "Failed to get order payments",
+
+		// Checkout
+		"INVALID_QUANTITY":      "Invalid quantity",
+		"INVALID_AMOUNT":        "Invalid amount",
+		"INVALID_DUE_DATE":      "Invalid due date",
+		"PRODUCT_NOT_FOUND":     "Product not found",
+		"VARIANT_REQUIRED":      "Variant required",
+		"VARIANT_NOT_FOUND":     "Variant not found",
+		"CHECKOUT_IN_PROGRESS":  "Checkout already in progress",
+		"PRICE_UNAVAILABLE":     "Product price unavailable",
+		"INVALID_PRODUCT_PRICE": "Invalid product price",
+		"PRICE_CHANGED":         "Amount does not match the product price",
+		"STOCK_UNAVAILABLE":     "Product stock unavailable",
+		"PRODUCT_INACTIVE":      "Product is not active",
+		"INSUFFICIENT_STOCK":    "Insufficient stock",
+		"OUT_OF_STOCK":          "Product is out of stock",
+
+		// Charging
+		"STORE_SETTINGS_FAILED":           "Failed to load store payment settings",
+		"PAYMENT_METHOD_UNAVAILABLE":      "Payment method temporarily unavailable",
+		"PAYMENT_METHOD_UNAVAILABLE_HINT": "The payment method is under maintenance, please choose another method (BNI, BCA, BRI, Mandiri, GoPay, QRIS or Credit Card)",
+		"MIDTRANS_CHARGE_FAILED":          "Failed to create payment with Midtrans",
+		"PAYMENT_CREATE_FAILED":           "Failed to create payment",
+
+		// Payments
+		"INVALID_PAYMENT_ID":           "Invalid payment ID",
+		"PAYMENT_NOT_FOUND":            "Payment not found",
+		"PAYMENT_NOT_PENDING":          "Only pending payments can be cancelled",
+		"PAYMENT_CANCEL_FAILED":        "Failed to cancel payment, please try again",
+		"PAYMENT_UPDATE_FAILED":        "Failed to update payment with Midtrans data",
+		"INVALID_QUERY":                "Invalid query parameters",
+		"PAYMENTS_FETCH_FAILED":        "Failed to get payments",
+		"INVALID_CALLBACK":             "Invalid callback format",
+		"INVALID_SIGNATURE":            "Invalid signature",
+		"CALLBACK_EXPIRED":             "Callback expired",
+		"CALLBACK_RECORD_FAILED":       "Failed to record callback",
+		"GROSS_AMOUNT_MISMATCH":        "Gross amount mismatch",
+		"MIDTRANS_STATUS_FAILED":       "Failed to get payment status from Midtrans",
+		"PAYMENT_STATUS_UPDATE_FAILED": "Failed to update payment status",
+		"INVALID_STORE_ID":             "Invalid store ID",
+		"PAYMENT_REFRESH_FAILED":       "Failed to get updated payment data",
+		"INVALID_PRODUCT_ID":           "Invalid product ID",
+		"PRODUCT_FETCH_FAILED":         "Failed to get product",
+		"PRODUCT_NOT_OWNED":            "You do not own this product",
+
+		// Payment links
+		"PAYMENT_LINKS_UNAVAILABLE":         "Payment links are not available",
+		"PAYMENT_LINK_VARIANTS_UNSUPPORTED": "Products with variants can't be sold through payment links",
+		"PAYMENT_LINK_GENERATE_FAILED":      "Failed to generate payment link",
+		"PAYMENT_LINK_CREATE_FAILED":        "Failed to create payment link",
+		"PAYMENT_LINKS_FETCH_FAILED":        "Failed to get payment links",
+		"INVALID_PAYMENT_LINK_ID":           "Invalid payment link ID",
+		"PAYMENT_LINK_CANCEL_FAILED":        "Failed to cancel payment link",
+		"NO_ACTIVE_PAYMENT_LINK":            "No active payment link found",
+		"PAYMENT_LINK_NOT_PAYABLE":          "Payment link is no longer payable",
+		"INVALID_PAYMENT_METHOD":            "Invalid payment method",
+		"PRODUCT_UNAVAILABLE":               "Product is no longer available",
+		"PAYMENT_LINK_REDEEM_FAILED":        "Failed to redeem payment link",
+		"PAYMENT_LINK_USED":                 "Payment link was already used",
+		"PAYMENT_LINK_NOT_FOUND":            "Payment link not found",
+
+		// Webhooks
+		"WEBHOOK_ENDPOINTS_FETCH_FAILED":  "Failed to get webhook endpoints",
+		"WEBHOOK_SECRET_FAILED":           "Failed to generate webhook secret",
+		"WEBHOOK_ENDPOINT_CREATE_FAILED":  "Failed to create webhook endpoint",
+		"WEBHOOK_ENDPOINT_UPDATE_FAILED":  "Failed to update webhook endpoint",
+		"INVALID_WEBHOOK_ENDPOINT_ID":     "Invalid webhook endpoint ID",
+		"WEBHOOK_ENDPOINT_DELETE_FAILED":  "Failed to delete webhook endpoint",
+		"WEBHOOK_DELIVERIES_FETCH_FAILED": "Failed to get webhook deliveries",
+		"INVALID_WEBHOOK_DELIVERY_ID":     "Invalid webhook delivery ID",
+		"WEBHOOK_DELIVERY_NOT_FOUND":      "Webhook delivery not found",
+		"WEBHOOK_ENDPOINT_INACTIVE":       "Webhook endpoint is inactive",
+		"REDELIVERY_QUEUE_FAILED":         "Failed to queue redelivery",
+		"WEBHOOK_ENDPOINT_NOT_FOUND":      "Webhook endpoint not found",
+		"UNSUPPORTED_EVENT_TYPE":          "Unsupported event type",
+		"EVENT_TYPE_REQUIRED":             "At least one event type is required",
+
+		// Invoices
+		"INVOICE_NOT_FOUND":       "Invoice not found",
+		"INVOICE_GENERATE_FAILED": "Failed to generate invoice",
+		"INVALID_PAID_AT":         "Invalid paid_at",
+		"INVOICE_SETTLE_FAILED":   "Failed to settle invoice",
+		"INVOICE_NOT_OPEN":        "Invoice is not open",
+
+		// Authentication
+		"AUTH_HEADER_REQUIRED":  "Authorization header required",
+		"INVALID_TOKEN":         "Invalid token",
+		"SERVICE_AUTH_REQUIRED": "Service authentication required",
+		"SOURCE_NOT_ALLOWED":    "Source not allowed",
+
+		// Archive
+		"ARCHIVED_PAYMENTS_FETCH_FAILED": "Failed to get archived payments",
+		"ARCHIVED_PAYMENT_NOT_FOUND":     "Archived payment not found",
+		"ARCHIVED_PAYMENT_FETCH_FAILED":  "Failed to get archived payment",
+		"ARCHIVED_PAYMENT_READ_FAILED":   "Failed to read archived payment",
+
+		// Cache
+		"CACHE_STATS_FAILED":      "Failed to get cache stats",
+		"UNKNOWN_CACHE_NAMESPACE": "Unknown cache namespace",
+		"CACHE_PURGE_FAILED":      "Failed to purge cache",
+
+		// Sandbox
+		"INVALID_REQUEST_BODY":           "Invalid request body",
+		"UNSUPPORTED_TRANSACTION_STATUS": "Unsupported transaction_status",
+		"CALLBACK_BUILD_FAILED":          "Failed to build callback",
+		"SANDBOX_RESET_FAILED":           "Failed to reset sandbox data",
+		"PAYMENT_CACHE_CLEAR_FAILED":     "Failed to clear payment cache",
+		"FIXTURES_CREATE_FAILED":         "Failed to create fixtures",
+
+		// Chaos
+		"FAULT_ADD_FAILED": "Failed to add fault",
+		"FAULT_NOT_FOUND":  "Fault not found",
+
+		// Events
+		"INVALID_TIME":             "Invalid time, expected RFC3339",
+		"EVENTS_FETCH_FAILED":      "Failed to get events",
+		"INVALID_EVENT_ID":         "Invalid event ID",
+		"INVALID_TIME_RANGE":       "to must not be before from",
+		"EVENT_SELECTION_REQUIRED": "Either event_ids or both from and to are required",
+		"TOO_MANY_EVENTS":          "Too many events selected, narrow the time range",
+
+		// Exports
+		"INVALID_EXPORT_FORMAT":    "Invalid export format",
+		"EXPORT_LINKS_UNAVAILABLE": "Export links are not available",
+		"PAYMENTS_EXPORT_FAILED":   "Failed to export payments",
+
+		// Feature flags
+		"FEATURE_FLAG_UPDATE_FAILED": "Failed to update feature flag",
+
+		// Stats
+		"PAYMENT_STATS_FAILED":        "Failed to get payment stats",
+		"PAYMENT_METHOD_STATS_FAILED": "Failed to get payment method stats",
+		"INVALID_WINDOW":              "Invalid window, expected a duration such as 1h",
+		"UNKNOWN_WINDOW":              "Unknown window",
+
+		// Store credentials
+		"STORE_CREDENTIALS_FETCH_FAILED":   "Failed to get store credentials",
+		"STORE_CREDENTIALS_UNAVAILABLE":    "Store credentials are not available",
+		"STORE_CREDENTIALS_SAVE_FAILED":    "Failed to save store credentials",
+		"STORE_CREDENTIALS_NOT_CONFIGURED": "Store has no credentials configured",
+		"STORE_CREDENTIALS_DELETE_FAILED":  "Failed to delete store credentials",
+		"STORE_NOT_FOUND":                  "Store not found",
+		"STORE_FETCH_FAILED":               "Failed to get store",
+		"STORE_NOT_OWNED":                  "You do not own this store",
+	},
+	LocaleID: {
+		// Generic errors
+		"INVALID_REQUEST":        "Format permintaan tidak valid",
+		"USER_NOT_AUTHENTICATED": "Pengguna belum terautentikasi",
+		"INVALID_USER_ID":        "ID pengguna tidak valid",
+		"USER_LOOKUP_FAILED":     "Gagal mengambil data pengguna",
+
+		// Orders
+		"ORDER_ALREADY_PAID":  "Pesanan sudah dibayar",
+		"ORDER_NOT_FOUND":     "Pesanan tidak ditemukan",
+		"ORDER_LOOKUP_FAILED": "Gagal mengambil pembayaran pesanan",
+
+		// Checkout
+		"INVALID_QUANTITY":      "Jumlah tidak valid",
+		"INVALID_AMOUNT":        "Nominal tidak valid",
+		"INVALID_DUE_DATE":      "Tanggal jatuh tempo tidak valid",
+		"PRODUCT_NOT_FOUND":     "Produk tidak ditemukan",
+		"VARIANT_REQUIRED":      "Silakan pilih varian produk",
+		"VARIANT_NOT_FOUND":     "Varian tidak ditemukan",
+		"CHECKOUT_IN_PROGRESS":  "Checkout sedang diproses, silakan coba lagi sebentar lagi",
+		"PRICE_UNAVAILABLE":     "Harga produk belum dapat diverifikasi, silakan coba lagi",
+		"INVALID_PRODUCT_PRICE": "Harga produk tidak valid",
+		"PRICE_CHANGED":         "Harga produk telah berubah, silakan konfirmasi harga terbaru",
+		"STOCK_UNAVAILABLE":     "Stok produk belum dapat diverifikasi, silakan coba lagi",
+		"PRODUCT_INACTIVE":      "Produk tidak aktif",
+		"INSUFFICIENT_STOCK":    "Stok tidak mencukupi",
+		"OUT_OF_STOCK":          "Stok produk habis",
+
+		// Charging
+		"STORE_SETTINGS_FAILED":           "Gagal memuat pengaturan pembayaran toko",
+		"PAYMENT_METHOD_UNAVAILABLE":      "Metode pembayaran sementara tidak tersedia",
+		"PAYMENT_METHOD_UNAVAILABLE_HINT": "Metode pembayaran sedang maintenance, silakan pilih metode lain (BNI, BCA, BRI, Mandiri, GoPay, QRIS, atau Credit Card)",
+		"MIDTRANS_CHARGE_FAILED":          "Gagal membuat pembayaran di Midtrans",
+		"PAYMENT_CREATE_FAILED":           "Gagal membuat pembayaran",
+
+		// Payments
+		"INVALID_PAYMENT_ID":           "ID pembayaran tidak valid",
+		"PAYMENT_NOT_FOUND":            "Pembayaran tidak ditemukan",
+		"PAYMENT_NOT_PENDING":          "Hanya pembayaran yang masih pending yang dapat dibatalkan",
+		"PAYMENT_CANCEL_FAILED":        "Gagal membatalkan pembayaran, silakan coba lagi",
+		"PAYMENT_UPDATE_FAILED":        "Gagal memperbarui pembayaran dengan data Midtrans",
+		"INVALID_QUERY":                "Parameter query tidak valid",
+		"PAYMENTS_FETCH_FAILED":        "Gagal mengambil data pembayaran",
+		"INVALID_CALLBACK":             "Format callback tidak valid",
+		"INVALID_SIGNATURE":            "Signature tidak valid",
+		"CALLBACK_EXPIRED":             "Callback sudah kedaluwarsa",
+		"CALLBACK_RECORD_FAILED":       "Gagal mencatat callback",
+		"GROSS_AMOUNT_MISMATCH":        "Jumlah pembayaran tidak sesuai",
+		"MIDTRANS_STATUS_FAILED":       "Gagal mengambil status pembayaran dari Midtrans",
+		"PAYMENT_STATUS_UPDATE_FAILED": "Gagal memperbarui status pembayaran",
+		"INVALID_STORE_ID":             "ID toko tidak valid",
+		"PAYMENT_REFRESH_FAILED":       "Gagal mengambil data pembayaran terbaru",
+		"INVALID_PRODUCT_ID":           "ID produk tidak valid",
+		"PRODUCT_FETCH_FAILED":         "Gagal mengambil data produk",
+		"PRODUCT_NOT_OWNED":            "Anda bukan pemilik produk ini",
+
+		// Payment links
+		"PAYMENT_LINKS_UNAVAILABLE":         "Link pembayaran tidak tersedia",
+		"PAYMENT_LINK_VARIANTS_UNSUPPORTED": "Produk dengan varian tidak dapat dijual melalui link pembayaran",
+		"PAYMENT_LINK_GENERATE_FAILED":      "Gagal membuat link pembayaran",
+		"PAYMENT_LINK_CREATE_FAILED":        "Gagal membuat link pembayaran",
+		"PAYMENT_LINKS_FETCH_FAILED":        "Gagal mengambil link pembayaran",
+		"INVALID_PAYMENT_LINK_ID":           "ID link pembayaran tidak valid",
+		"PAYMENT_LINK_CANCEL_FAILED":        "Gagal membatalkan link pembayaran",
+		"NO_ACTIVE_PAYMENT_LINK":            "Tidak ada link pembayaran yang aktif",
+		"PAYMENT_LINK_NOT_PAYABLE":          "Link pembayaran sudah tidak dapat dibayar",
+		"INVALID_PAYMENT_METHOD":            "Metode pembayaran tidak valid",
+		"PRODUCT_UNAVAILABLE":               "Produk sudah tidak tersedia",
+		"PAYMENT_LINK_REDEEM_FAILED":        "Gagal menggunakan link pembayaran",
+		"PAYMENT_LINK_USED":                 "Link pembayaran sudah digunakan",
+		"PAYMENT_LINK_NOT_FOUND":            "Link pembayaran tidak ditemukan",
+
+		// Webhooks
+		"WEBHOOK_ENDPOINTS_FETCH_FAILED":  "Gagal mengambil endpoint webhook",
+		"WEBHOOK_SECRET_FAILED":           "Gagal membuat secret webhook",
+		"WEBHOOK_ENDPOINT_CREATE_FAILED":  "Gagal membuat endpoint webhook",
+		"WEBHOOK_ENDPOINT_UPDATE_FAILED":  "Gagal memperbarui endpoint webhook",
+		"INVALID_WEBHOOK_ENDPOINT_ID":     "ID endpoint webhook tidak valid",
+		"WEBHOOK_ENDPOINT_DELETE_FAILED":  "Gagal menghapus endpoint webhook",
+		"WEBHOOK_DELIVERIES_FETCH_FAILED": "Gagal mengambil pengiriman webhook",
+		"INVALID_WEBHOOK_DELIVERY_ID":     "ID pengiriman webhook tidak valid",
+		"WEBHOOK_DELIVERY_NOT_FOUND":      "Pengiriman webhook tidak ditemukan",
+		"WEBHOOK_ENDPOINT_INACTIVE":       "Endpoint webhook tidak aktif",
+		"REDELIVERY_QUEUE_FAILED":         "Gagal menjadwalkan pengiriman ulang",
+		"WEBHOOK_ENDPOINT_NOT_FOUND":      "Endpoint webhook tidak ditemukan",
+		"UNSUPPORTED_EVENT_TYPE":          "Jenis event tidak didukung",
+		"EVENT_TYPE_REQUIRED":             "Minimal satu jenis event wajib diisi",
+
+		// Invoices
+		"INVOICE_NOT_FOUND":       "Invoice tidak ditemukan",
+		"INVOICE_GENERATE_FAILED": "Gagal membuat invoice",
+		"INVALID_PAID_AT":         "paid_at tidak valid",
+		"INVOICE_SETTLE_FAILED":   "Gagal melunasi invoice",
+		"INVOICE_NOT_OPEN":        "Invoice tidak dalam status terbuka",
+
+		// Authentication
+		"AUTH_HEADER_REQUIRED":  "Header Authorization wajib diisi",
+		"INVALID_TOKEN":         "Token tidak valid",
+		"SERVICE_AUTH_REQUIRED": "Autentikasi layanan diperlukan",
+		"SOURCE_NOT_ALLOWED":    "Sumber request tidak diizinkan",
+
+		// Archive
+		"ARCHIVED_PAYMENTS_FETCH_FAILED": "Gagal mengambil pembayaran terarsip",
+		"ARCHIVED_PAYMENT_NOT_FOUND":     "Pembayaran terarsip tidak ditemukan",
+		"ARCHIVED_PAYMENT_FETCH_FAILED":  "Gagal mengambil pembayaran terarsip",
+		"ARCHIVED_PAYMENT_READ_FAILED":   "Gagal membaca pembayaran terarsip",
+
+		// Cache
+		"CACHE_STATS_FAILED":      "Gagal mengambil statistik cache",
+		"UNKNOWN_CACHE_NAMESPACE": "Namespace cache tidak dikenal",
+		"CACHE_PURGE_FAILED":      "Gagal membersihkan cache",
+
+		// Sandbox
+		"INVALID_REQUEST_BODY":           "Body request tidak valid",
+		"UNSUPPORTED_TRANSACTION_STATUS": "transaction_status tidak didukung",
+		"CALLBACK_BUILD_FAILED":          "Gagal menyusun callback",
+		"SANDBOX_RESET_FAILED":           "Gagal mereset data sandbox",
+		"PAYMENT_CACHE_CLEAR_FAILED":     "Gagal membersihkan cache pembayaran",
+		"FIXTURES_CREATE_FAILED":         "Gagal membuat data fixture",
+
+		// Chaos
+		"FAULT_ADD_FAILED": "Gagal menambahkan fault",
+		"FAULT_NOT_FOUND":  "Fault tidak ditemukan",
+
+		// Events
+		"INVALID_TIME":             "Waktu tidak valid, gunakan format RFC3339",
+		"EVENTS_FETCH_FAILED":      "Gagal mengambil event",
+		"INVALID_EVENT_ID":         "ID event tidak valid",
+		"INVALID_TIME_RANGE":       "to tidak boleh sebelum from",
+		"EVENT_SELECTION_REQUIRED": "Isi event_ids atau from dan to sekaligus",
+		"TOO_MANY_EVENTS":          "Terlalu banyak event dipilih, persempit rentang waktunya",
+
+		// Exports
+		"INVALID_EXPORT_FORMAT":    "Format ekspor tidak valid",
+		"EXPORT_LINKS_UNAVAILABLE": "Link ekspor tidak tersedia",
+		"PAYMENTS_EXPORT_FAILED":   "Gagal mengekspor pembayaran",
+
+		// Feature flags
+		"FEATURE_FLAG_UPDATE_FAILED": "Gagal memperbarui feature flag",
+
+		// Stats
+		"PAYMENT_STATS_FAILED":        "Gagal mengambil statistik pembayaran",
+		"PAYMENT_METHOD_STATS_FAILED": "Gagal mengambil statistik metode pembayaran",
+		"INVALID_WINDOW":              "Window tidak valid, gunakan durasi seperti 1h",
+		"UNKNOWN_WINDOW":              "Window tidak dikenal",
+
+		// Store credentials
+		"STORE_CREDENTIALS_FETCH_FAILED":   "Gagal mengambil kredensial toko",
+		"STORE_CREDENTIALS_UNAVAILABLE":    "Kredensial toko tidak tersedia",
+		"STORE_CREDENTIALS_SAVE_FAILED":    "Gagal menyimpan kredensial toko",
+		"STORE_CREDENTIALS_NOT_CONFIGURED": "Toko belum memiliki kredensial",
+		"STORE_CREDENTIALS_DELETE_FAILED":  "Gagal menghapus kredensial toko",
+		"STORE_NOT_FOUND":                  "Toko tidak ditemukan",
+		"STORE_FETCH_FAILED":               "Gagal mengambil data toko",
+		"STORE_NOT_OWNED":                  "Anda bukan pemilik toko ini",
+	},
+}
@@ -0,0 +1,167 @@
+// Package invoice renders the PDF invoices of B2B invoice payments. The documents are plain
+// single-page A4 PDFs in the standard Helvetica fonts, written by hand so no PDF library is
+// needed.
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"payment-service/internal/money"
+)
+
+// Invoice is the content of an invoice document
+type Invoice struct {
+	Number    string // the payment's order ID
+	Status    string
+	IssuedAt  time.Time
+	DueDate   time.Time
+	BillTo    []string // customer name, email, ...
+	Items     []Item
+	Total     money.Money
+	PaidAt    *time.Time
+	Reference string // settlement reference, once paid
+}
+
+// Item is one line of an invoice
+type Item struct {
+	Description string
+	Quantity    int // units of the product line, 0 for fees and adjustments
+	Amount      money.Money
+}
+
+// Page geometry in points (A4)
+const (
+	pageWidth   = 595
+	pageHeight  = 842
+	marginLeft  = 56
+	amountRight = pageWidth - 56
+	qtyRight    = amountRight - 140
+	lineHeight  = 16
+)
+
+// dateLayout formats invoice dates, times are rendered in the zone they carry
+const dateLayout = "2 Jan 2006"
+
+// Render writes inv as a PDF document to w
+func Render(w io.Writer, inv Invoice) error {
+	var content bytes.Buffer
+	page := &pageWriter{buf: &content, y: pageHeight - 72}
+
+	page.text("F2", 20, marginLeft, "INVOICE")
+	page.y -= 2 * lineHeight
+	page.text("F1", 10, marginLeft, "Invoice number: "+inv.Number)
+	page.text("F1", 10, marginLeft, "Issued: "+inv.IssuedAt.Format(dateLayout))
+	page.text("F1", 10, marginLeft, "Due: "+inv.DueDate.Format(dateLayout))
+	page.text("F1", 10, marginLeft, "Status: "+inv.Status)
+	page.y -= lineHeight
+
+	if len(inv.BillTo) > 0 {
+		page.text("F2", 11, marginLeft, "Bill to")
+		for _, line := range inv.BillTo {
+			page.text("F1", 10, marginLeft, line)
+		}
+		page.y -= lineHeight
+	}
+
+	page.text("F2", 11, marginLeft, "Description")
+	page.y += lineHeight
+	page.textRight("F2", 11, qtyRight, "Qty")
+	page.y += lineHeight
+	page.textRight("F2", 11, amountRight, "Amount")
+	page.rule()
+	for _, item := range inv.Items {
+		page.text("F1", 10, marginLeft, item.Description)
+		if item.Quantity > 0 {
+			page.y += lineHeight
+			page.textRight("F1", 10, qtyRight, fmt.Sprintf("%d", item.Quantity))
+		}
+		page.y += lineHeight
+		page.textRight("F1", 10, amountRight, item.Amount.String())
+	}
+	page.rule()
+	page.text("F2", 11, marginLeft, "Total")
+	page.y += lineHeight
+	page.textRight("F2", 11, amountRight, inv.Total.String())
+
+	if inv.PaidAt != nil {
+		page.y -= lineHeight
+		page.text("F1", 10, marginLeft, "Paid: "+inv.PaidAt.Format(dateLayout))
+		if inv.Reference != "" {
+			page.text("F1", 10, marginLeft, "Reference: "+inv.Reference)
+		}
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pageWidth, pageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// pageWriter appends text lines to a page content stream, top to bottom
+type pageWriter struct {
+	buf *bytes.Buffer
+	y   int
+}
+
+// text writes s at x on the current line and moves to the next one
+func (p *pageWriter) text(font string, size, x int, s string) {
+	fmt.Fprintf(p.buf, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, x, p.y, escape(s))
+	p.y -= lineHeight
+}
+
+// textRight writes s ending at right, using an approximate Helvetica width so amounts line up
+func (p *pageWriter) textRight(font string, size, right int, s string) {
+	width := len(s) * size * 556 / 1000 // digits are 556/1000 em wide in Helvetica
+	p.text(font, size, right-width, s)
+}
+
+// rule draws a horizontal line under the current line
+func (p *pageWriter) rule() {
+	y := p.y + lineHeight - 4
+	fmt.Fprintf(p.buf, "%d %d m %d %d l S\n", marginLeft, y, amountRight, y)
+	p.y -= 4
+}
+
+// escape makes s safe inside a PDF string literal. Characters outside printable ASCII are
+// replaced, the standard fonts can't be relied on for them.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"payment-service/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTClaims represents the JWT claims structure issued by User-Service
+type JWTClaims struct {
+	UserID     string `json:"user_id"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	IsVerified bool   `json:"is_verified"`
+	jwt.RegisteredClaims
+}
+
+// NewTokenParser returns the parser for user tokens: exp is required, and exp, nbf and iat
+// are checked with a leeway of JWT_CLOCK_SKEW (30s by default), as User-Service does
+func NewTokenParser() *jwt.Parser {
+	clockSkew := 30 * time.Second
+	if skew := os.Getenv("JWT_CLOCK_SKEW"); skew != "" {
+		if parsed, err := time.ParseDuration(skew); err == nil && parsed >= 0 {
+			clockSkew = parsed
+		}
+	}
+
+	return jwt.NewParser(
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+}
+
+// AuthMiddleware validates the bearer token itself instead of trusting the gateway, so
+// requests reaching the service directly can't spoof a user. The identity headers read
+// by the handlers (X-User-ID, X-Username, X-Email) are overwritten from the token claims.
+func AuthMiddleware(keyFunc jwt.Keyfunc) gin.HandlerFunc {
+	parser := NewTokenParser()
+
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, i18n.Error(c, "AUTH_HEADER_REQUIRED", gin.H{"success": false}))
+			return
+		}
+
+		claims := &JWTClaims{}
+		token, err := parser.ParseWithClaims(strings.TrimPrefix(authHeader, "Bearer "), claims, keyFunc)
+		if err != nil || !token.Valid || claims.UserID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, i18n.Error(c, "INVALID_TOKEN", gin.H{"success": false}))
+			return
+		}
+
+		c.Request.Header.Set("X-User-ID", claims.UserID)
+		c.Request.Header.Set("X-Username", claims.Username)
+		c.Request.Header.Set("X-Email", claims.Email)
+
+		c.Set("user_id", claims.UserID)
+		c.Set("username", claims.Username)
+		c.Set("email", claims.Email)
+
+		c.Next()
+	}
+}
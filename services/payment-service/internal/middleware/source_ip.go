@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"payment-service/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SourceAllowlist only lets requests through whose client IP matches one of the
+// comma separated IPs or CIDR ranges in allowed. An empty list allows every source.
+// The client IP honours X-Forwarded-For only from TRUSTED_PROXIES.
+func SourceAllowlist(name, allowed string) gin.HandlerFunc {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(allowed, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("⚠️ Ignoring invalid %s allowlist entry %q: %v", name, entry, err)
+			continue
+		}
+		networks = append(networks, network)
+	}
+
+	if len(networks) == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	log.Printf("🔒 %s restricted to %d source networks", name, len(networks))
+
+	return func(c *gin.Context) {
+		ip := net.ParseIP(c.ClientIP())
+		for _, network := range networks {
+			if ip != nil && network.Contains(ip) {
+				c.Next()
+				return
+			}
+		}
+
+		log.Printf("🚫 Rejected %s request from %s", name, c.ClientIP())
+		c.AbortWithStatusJSON(http.StatusForbidden, i18n.Error(c, "SOURCE_NOT_ALLOWED", gin.H{"success": false}))
+	}
+}
@@ -0,0 +1,10 @@
+// Package mocks holds gomock mocks of the Payment-Service interfaces, generated with
+// `make generate` and not checked in. internal/fakes keeps the hand-written in-memory
+// implementations for tests that need state rather than expectations.
+package mocks
+
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../events/bus.go -destination=bus_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../events/rabbitmq.go -destination=events_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../services/midtrans.go -destination=midtrans_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../cache/redis.go -destination=cache_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../storage/storage.go -destination=storage_mock.go -package=mocks
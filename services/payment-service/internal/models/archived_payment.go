@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchivedStatuses are the final statuses a payment can be archived in. Pending, overdue
+// and other open payments stay in payments whatever their age.
+var ArchivedStatuses = []PaymentStatus{
+	PaymentStatusSuccess,
+	PaymentStatusFailed,
+	PaymentStatusCancelled,
+	PaymentStatusExpired,
+	PaymentStatusRefunded,
+}
+
+// ArchivedPayment is a settled payment moved out of payments by the archival job. The whole
+// row is kept as JSON in Payload, so the archive doesn't have to follow every migration of
+// payments; the columns beside it are the ones archive queries filter on.
+type ArchivedPayment struct {
+	ID            uuid.UUID     `json:"id" gorm:"type:uuid;primary_key"`
+	OrderID       string        `json:"order_id" gorm:"not null;index"`
+	UserID        uuid.UUID     `json:"user_id" gorm:"type:uuid;not null;index:idx_payments_archive_user_created,priority:1"`
+	ProductID     *uuid.UUID    `json:"product_id" gorm:"type:uuid;index"`
+	PaymentMethod PaymentMethod `json:"payment_method" gorm:"not null"`
+	Status        PaymentStatus `json:"status" gorm:"not null"`
+	TotalAmount   int64         `json:"total_amount" gorm:"not null"`
+	CreatedAt     time.Time     `json:"created_at" gorm:"index:idx_payments_archive_user_created,priority:2;index:idx_payments_archive_created_at"`
+	ArchivedAt    time.Time     `json:"archived_at" gorm:"not null"`
+	Payload       string        `json:"-" gorm:"type:text;not null"` // The payments row as JSON
+}
+
+// TableName keeps archived payments next to payments
+func (ArchivedPayment) TableName() string {
+	return "payments_archive"
+}
+
+// NewArchivedPayment copies a payment into its archived form. midtrans_response is copied
+// as stored, encrypted when encryption was enabled.
+func NewArchivedPayment(payment Payment, archivedAt time.Time) (ArchivedPayment, error) {
+	payment.User = nil
+	payment.Product = nil
+	payload, err := json.Marshal(payment)
+	if err != nil {
+		return ArchivedPayment{}, fmt.Errorf("failed to encode payment %s: %w", payment.ID, err)
+	}
+
+	return ArchivedPayment{
+		ID:            payment.ID,
+		OrderID:       payment.OrderID,
+		UserID:        payment.UserID,
+		ProductID:     payment.ProductID,
+		PaymentMethod: payment.PaymentMethod,
+		Status:        payment.Status,
+		TotalAmount:   payment.TotalAmount,
+		CreatedAt:     payment.CreatedAt,
+		ArchivedAt:    archivedAt,
+		Payload:       string(payload),
+	}, nil
+}
+
+// Payment decodes the archived payments row
+func (a ArchivedPayment) Payment() (Payment, error) {
+	var payment Payment
+	if err := json.Unmarshal([]byte(a.Payload), &payment); err != nil {
+		return Payment{}, fmt.Errorf("failed to decode archived payment %s: %w", a.ID, err)
+	}
+	return payment, nil
+}
+
+// ArchivedPaymentResponse is an archived payment as returned by the archive endpoints
+type ArchivedPaymentResponse struct {
+	PaymentResponse
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// ArchivedPaymentListResponse is a page of archived payments
+type ArchivedPaymentListResponse struct {
+	Payments []ArchivedPaymentResponse `json:"payments"`
+	Total    int64                     `json:"total"`
+	Page     int                       `json:"page"`
+	Limit    int                       `json:"limit"`
+	HasMore  bool                      `json:"has_more"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventLog is a copy of an event published to RabbitMQ, kept so it can be replayed
+type EventLog struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"` // Also sent as the AMQP message ID
+	Exchange       string     `json:"exchange" gorm:"not null"`
+	RoutingKey     string     `json:"routing_key" gorm:"not null;index"`
+	EventType      string     `json:"event_type" gorm:"not null"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"`
+	PublishedAt    time.Time  `json:"published_at" gorm:"not null;index"`
+	ReplayCount    int        `json:"replay_count" gorm:"default:0"`
+	LastReplayedAt *time.Time `json:"last_replayed_at"`
+}
+
+// ReplayEventsRequest selects events to publish again, either by ID or by time range
+type ReplayEventsRequest struct {
+	EventIDs   []string   `json:"event_ids,omitempty"`
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
+	RoutingKey string     `json:"routing_key,omitempty"` // Optional filter for time range replays
+	DryRun     bool       `json:"dry_run,omitempty"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MidtransCallback records a Midtrans notification that was accepted for processing.
+// The fingerprint is unique so a replayed (or re-sent) notification for the same
+// transaction state is only processed once.
+type MidtransCallback struct {
+	ID                uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	Fingerprint       string    `json:"fingerprint" gorm:"size:64;uniqueIndex;not null"`
+	OrderID           string    `json:"order_id" gorm:"size:100;index;not null"`
+	TransactionStatus string    `json:"transaction_status" gorm:"size:50"`
+	TransactionTime   string    `json:"transaction_time" gorm:"size:50"`
+	SourceIP          string    `json:"source_ip" gorm:"size:64"`
+	ReceivedAt        time.Time `json:"received_at" gorm:"index"`
+}
+
+// TableName specifies the table name for MidtransCallback
+func (MidtransCallback) TableName() string {
+	return "midtrans_callbacks"
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (mc *MidtransCallback) BeforeCreate(tx *gorm.DB) error {
+	if mc.ID == uuid.Nil {
+		mc.ID = uuid.New()
+	}
+	return nil
+}
+
+// CallbackFingerprint identifies a notification by order, status and transaction time
+func CallbackFingerprint(orderID, transactionStatus, transactionTime string) string {
+	sum := sha256.Sum256([]byte(orderID + "|" + transactionStatus + "|" + transactionTime))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,32 @@
+package models
+
+// Data ownership policy
+//
+// Every table belongs to exactly one service. A service migrates and writes only:
+//   - OwnedModels: the data it is the source of truth for
+//   - ReadModels: local projections of data owned by another service, written only by
+//     event consumers (or refreshed from the owner's API) and never referenced by foreign keys
+//
+// Data owned by other services that isn't projected (e.g. Product) is fetched per request
+// and must not be passed to AutoMigrate.
+
+// OwnedModels returns the tables owned by Payment-Service
+func OwnedModels() []interface{} {
+	return []interface{}{
+		&Payment{},
+		&ArchivedPayment{},
+		&WebhookEndpoint{},
+		&WebhookDelivery{},
+		&EventLog{},
+		&MidtransCallback{},
+		&PaymentLink{},
+		&StoreMidtransCredentials{},
+	}
+}
+
+// ReadModels returns the projections of other services' data kept by Payment-Service
+func ReadModels() []interface{} {
+	return []interface{}{
+		&UserProfile{},
+	}
+}
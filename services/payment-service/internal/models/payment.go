@@ -3,6 +3,9 @@ package models
 import (
 	"time"
 
+	"payment-service/internal/money"
+	"payment-service/internal/timeutil"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -16,6 +19,8 @@ const (
 	PaymentStatusFailed    PaymentStatus = "FAILED"
 	PaymentStatusCancelled PaymentStatus = "CANCELLED"
 	PaymentStatusExpired   PaymentStatus = "EXPIRED"
+	PaymentStatusRefunded  PaymentStatus = "REFUNDED"
+	PaymentStatusOverdue   PaymentStatus = "OVERDUE" // Invoice past its due date, still payable
 )
 
 // PaymentMethod represents the payment method
@@ -30,20 +35,29 @@ const (
 	PaymentMethodEchannel     PaymentMethod = "echannel"
 	PaymentMethodPermata      PaymentMethod = "permata"
 	PaymentMethodCstore       PaymentMethod = "cstore"
+	PaymentMethodInvoice      PaymentMethod = "invoice" // B2B invoice settled manually by an admin, no Midtrans
 )
 
 // Payment represents the payment model in the database
 type Payment struct {
 	ID                    uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	OrderID               string         `json:"order_id" gorm:"uniqueIndex;not null"`
-	UserID                uuid.UUID      `json:"user_id" gorm:"type:uuid;not null"`
-	ProductID             *uuid.UUID     `json:"product_id" gorm:"type:uuid"`
-	Amount                int64          `json:"amount" gorm:"not null"` // Amount in rupiah
+	OrderRef              string         `json:"order_ref" gorm:"size:64;index"` // Groups the attempts to pay one order, the first attempt's order ID
+	UserID                uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index:idx_payments_user_created,priority:1"`
+	ProductID             *uuid.UUID     `json:"product_id" gorm:"type:uuid;index"`
+	VariantID             *uuid.UUID     `json:"variant_id" gorm:"type:uuid"` // Variant bought, nil for products without variants
+	StoreID               *uuid.UUID     `json:"store_id" gorm:"type:uuid;index"` // Store of the product, selects its Midtrans credentials
+	Quantity              int            `json:"quantity" gorm:"not null;default:1"` // Units of the product bought
+	Amount                int64          `json:"amount" gorm:"not null"` // Amount in rupiah, for all units
 	AdminFee              int64          `json:"admin_fee" gorm:"default:0"` // Admin fee in rupiah
+	DiscountAmount        int64          `json:"discount_amount" gorm:"default:0"` // Subtracted from the amount
+	TaxAmount             int64          `json:"tax_amount" gorm:"default:0"` // e.g. PPN
+	RoundingAmount        int64          `json:"rounding_amount" gorm:"default:0"` // Rounding adjustment, may be negative
 	TotalAmount           int64          `json:"total_amount" gorm:"not null"` // Total amount in rupiah
+	Currency              string         `json:"currency" gorm:"size:3;not null;default:'IDR'"` // Amounts are minor units of this currency
 	PaymentMethod         PaymentMethod  `json:"payment_method" gorm:"not null"`
 	PaymentType           string         `json:"payment_type"` // qris, bank_transfer, credit_card, etc
-	Status                PaymentStatus  `json:"status" gorm:"default:'PENDING'"`
+	Status                PaymentStatus  `json:"status" gorm:"default:'PENDING';index"`
 	Notes                 *string        `json:"notes"` // User notes/comments for the order
 	SnapRedirectURL       *string        `json:"snap_redirect_url"`
 	MidtransTransactionID *string        `json:"midtrans_transaction_id"`
@@ -55,54 +69,100 @@ type Payment struct {
 	StoreType             *string        `json:"store_type"`   // alfamart, indomaret, etc
 	ExpiryTime            *time.Time     `json:"expiry_time"`
 	PaidAt                *time.Time     `json:"paid_at"`
+	DueDate               *time.Time     `json:"due_date" gorm:"index"`          // Invoices only, overdue after it
+	InvoiceRemindedAt     *time.Time     `json:"invoice_reminded_at"`            // Due date reminder sent
+	SettlementReference   *string        `json:"settlement_reference" gorm:"size:100"` // Invoices only, e.g. the bank transfer reference
 	MidtransResponse      *string        `json:"midtrans_response"` // JSON response from Midtrans
 	MidtransAction        *string        `json:"midtrans_action"`   // JSON.stringify(result.actions)
-	CreatedAt             time.Time      `json:"created_at"`
+	CreatedAt             time.Time      `json:"created_at" gorm:"index:idx_payments_user_created,priority:2;index:idx_payments_created_at"`
 	UpdatedAt             time.Time      `json:"updated_at"`
 
 	// Relations (no foreign key constraints - just references)
-	User    *User     `json:"user,omitempty" gorm:"-"`
-	Product *Product  `json:"product,omitempty" gorm:"-"`
-}
-
-// User represents a simplified user model for foreign key relationship
-type User struct {
-	ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
-	Username string    `json:"username"`
-	Email    string    `json:"email"`
+	User    *UserProfile `json:"user,omitempty" gorm:"-"`
+	Product *Product     `json:"product,omitempty" gorm:"-"`
 }
 
-// Product represents a simplified product model for foreign key relationship
+// Product is a transient view of a product fetched from Product-Service per request.
+// It is owned by Product-Service and never persisted here (see ownership.go).
 type Product struct {
 	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
-	Price       float64   `json:"price"`
+	Price       money.Major `json:"price"` // Decoded from the JSON number without a float
 	Stock       int       `json:"stock"`
 	IsActive    bool      `json:"is_active"`
+	StoreID     *uuid.UUID `json:"store_id"`
+	OwnerID     uuid.UUID  `json:"owner_id"`
+	Variants    []ProductVariant `json:"variants,omitempty"` // Active variants only
+}
+
+// ProductVariant is a purchasable variant of a product, with its own stock and optionally its own price
+type ProductVariant struct {
+	ID            uuid.UUID         `json:"id"`
+	SKU           string            `json:"sku"`
+	Attributes    map[string]string `json:"attributes"`
+	PriceOverride *money.Major      `json:"price_override,omitempty"`
+	Stock         int               `json:"stock"`
+	IsActive      bool              `json:"is_active"`
+}
+
+// ProductAvailability is Product-Service's stock pre-check for a quantity of a product
+type ProductAvailability struct {
+	InStock     bool `json:"in_stock"`
+	MaxQuantity int  `json:"max_quantity"`
+	IsActive    bool `json:"is_active"`
+}
+
+// PriceQuote is Product-Service's price for a quantity of a product after its pricing rules
+type PriceQuote struct {
+	BasePrice money.Major `json:"base_price"`
+	UnitPrice money.Major `json:"unit_price"`
+	Total     money.Major `json:"total"`
+	Rule      *struct {
+		ID   uuid.UUID `json:"id"`
+		Name string    `json:"name"`
+	} `json:"rule,omitempty"`
 }
 
 // CreatePaymentRequest represents the request payload for creating a payment
 type CreatePaymentRequest struct {
 	ProductID     *uuid.UUID    `json:"product_id" validate:"required"`
+	VariantID     *uuid.UUID    `json:"variant_id,omitempty"` // Required when the product has variants
+	Quantity      int           `json:"quantity,omitempty" validate:"omitempty,min=1"` // Defaults to 1, checked against the available stock
+	OrderRef      *string       `json:"order_ref,omitempty"` // Retry an earlier order with another method, cancelling its pending attempts
 	UserID        *string       `json:"user_id,omitempty"` // Optional, will be overridden by JWT if not provided
 	Amount        int64         `json:"amount" validate:"required,min=1"`
 	AdminFee      int64         `json:"admin_fee" validate:"min=0"`
-	PaymentMethod PaymentMethod `json:"payment_method" validate:"required,oneof=credit_card bank_transfer gopay qris shopeepay echannel permata cstore"`
+	PaymentMethod PaymentMethod `json:"payment_method" validate:"required,oneof=credit_card bank_transfer gopay qris shopeepay echannel permata cstore invoice"`
 	BankType      *string       `json:"bank_type,omitempty"` // For bank transfer
 	StoreType     *string       `json:"store_type,omitempty"` // For cstore (alfamart, indomaret)
 	Notes         *string       `json:"notes,omitempty"`
+	DueDate       *string       `json:"due_date,omitempty"` // Required for invoice, YYYY-MM-DD (end of day) or RFC3339
+}
+
+// SettleInvoiceRequest records how an invoice was paid (admin only)
+type SettleInvoiceRequest struct {
+	Reference string  `json:"reference" binding:"required,max=100"` // e.g. the bank transfer reference
+	PaidAt    *string `json:"paid_at,omitempty"`                    // RFC3339, defaults to now
 }
 
 // PaymentResponse represents the response payload for payment data
 type PaymentResponse struct {
 	ID                    uuid.UUID      `json:"id"`
 	OrderID               string         `json:"order_id"`
+	OrderRef              string         `json:"order_ref"`
 	UserID                uuid.UUID      `json:"user_id"`
 	ProductID             *uuid.UUID     `json:"product_id"`
+	VariantID             *uuid.UUID     `json:"variant_id,omitempty"`
+	StoreID               *uuid.UUID     `json:"store_id"`
+	Quantity              int            `json:"quantity"`
 	Amount                int64          `json:"amount"`
 	AdminFee              int64          `json:"admin_fee"`
+	DiscountAmount        int64          `json:"discount_amount"`
+	TaxAmount             int64          `json:"tax_amount"`
+	RoundingAmount        int64          `json:"rounding_amount"`
 	TotalAmount           int64          `json:"total_amount"`
+	Currency              string         `json:"currency"`
 	PaymentMethod         PaymentMethod  `json:"payment_method"`
 	PaymentType           string         `json:"payment_type"`
 	Status                PaymentStatus  `json:"status"`
@@ -117,9 +177,11 @@ type PaymentResponse struct {
 	StoreType             *string        `json:"store_type"`
 	ExpiryTime            *time.Time     `json:"expiry_time"`
 	PaidAt                *time.Time     `json:"paid_at"`
+	DueDate               *time.Time     `json:"due_date,omitempty"`
+	SettlementReference   *string        `json:"settlement_reference,omitempty"`
 	CreatedAt             time.Time      `json:"created_at"`
 	UpdatedAt             time.Time      `json:"updated_at"`
-	User                  *User          `json:"user,omitempty"`
+	User                  *UserProfile   `json:"user,omitempty"`
 	Product               *Product       `json:"product,omitempty"`
 	Actions               []MidtransAction `json:"actions,omitempty"`
 }
@@ -131,6 +193,60 @@ type MidtransAction struct {
 	URL   string `json:"url"`
 }
 
+// PaymentResponseV2 is the /api/v2 payment shape: amounts as money objects and the
+// Midtrans and payment instruction fields grouped instead of flat
+type PaymentResponseV2 struct {
+	ID            uuid.UUID             `json:"id"`
+	OrderID       string                `json:"order_id"`
+	OrderRef      string                `json:"order_ref"`
+	UserID        uuid.UUID             `json:"user_id"`
+	ProductID     *uuid.UUID            `json:"product_id"`
+	VariantID     *uuid.UUID            `json:"variant_id,omitempty"`
+	StoreID       *uuid.UUID            `json:"store_id"`
+	Quantity      int                   `json:"quantity"`
+	Status        PaymentStatus         `json:"status"`
+	PaymentMethod PaymentMethod         `json:"payment_method"`
+	PaymentType   string                `json:"payment_type"`
+	Amounts       PaymentAmountsV2      `json:"amounts"`
+	Instructions  PaymentInstructionsV2 `json:"instructions"`
+	Midtrans      PaymentMidtransV2     `json:"midtrans"`
+	Notes         *string               `json:"notes"`
+	PaidAt        *time.Time            `json:"paid_at"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+	User          *UserProfile          `json:"user,omitempty"`
+	Product       *Product              `json:"product,omitempty"`
+}
+
+// PaymentAmountsV2 holds the charge lines of a payment in minor units with their currency
+type PaymentAmountsV2 struct {
+	Subtotal money.Money `json:"subtotal"`
+	AdminFee money.Money `json:"admin_fee"`
+	Discount money.Money `json:"discount"`
+	Tax      money.Money `json:"tax"`
+	Rounding money.Money `json:"rounding"`
+	Total    money.Money `json:"total"`
+}
+
+// PaymentInstructionsV2 holds what the payer needs to complete the payment
+type PaymentInstructionsV2 struct {
+	PaymentCode *string    `json:"payment_code"`
+	VANumber    *string    `json:"va_number"`
+	BankType    *string    `json:"bank_type"`
+	StoreType   *string    `json:"store_type"`
+	ExpiryTime  *time.Time `json:"expiry_time"`
+	DueDate     *time.Time `json:"due_date,omitempty"` // Invoices only
+}
+
+// PaymentMidtransV2 holds the Midtrans transaction state
+type PaymentMidtransV2 struct {
+	TransactionID     *string          `json:"transaction_id"`
+	TransactionStatus *string          `json:"transaction_status"`
+	FraudStatus       *string          `json:"fraud_status"`
+	SnapRedirectURL   *string          `json:"snap_redirect_url"`
+	Actions           []MidtransAction `json:"actions,omitempty"`
+}
+
 // PaymentListResponse represents the response payload for paginated payment list
 type PaymentListResponse struct {
 	Payments []PaymentResponse `json:"payments"`
@@ -140,13 +256,77 @@ type PaymentListResponse struct {
 	HasMore  bool              `json:"has_more"`
 }
 
+// SellerPaymentResponse is a payment as the seller of its product sees it: what was bought,
+// for how much and where it stands, without the buyer's notes or payment instructions
+type SellerPaymentResponse struct {
+	ID             uuid.UUID     `json:"id"`
+	OrderID        string        `json:"order_id"`
+	UserID         uuid.UUID     `json:"user_id"`
+	ProductID      *uuid.UUID    `json:"product_id"`
+	VariantID      *uuid.UUID    `json:"variant_id,omitempty"`
+	StoreID        *uuid.UUID    `json:"store_id"`
+	Quantity       int           `json:"quantity"`
+	Amount         int64         `json:"amount"`
+	DiscountAmount int64         `json:"discount_amount"`
+	TotalAmount    int64         `json:"total_amount"`
+	Currency       string        `json:"currency"`
+	PaymentMethod  PaymentMethod `json:"payment_method"`
+	Status         PaymentStatus `json:"status"`
+	PaidAt         *time.Time    `json:"paid_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// SellerPaymentListResponse is a page of the payments of one product
+type SellerPaymentListResponse struct {
+	ProductID uuid.UUID               `json:"product_id"`
+	Payments  []SellerPaymentResponse `json:"payments"`
+	Total     int64                   `json:"total"`
+	Page      int                     `json:"page"`
+	Limit     int                     `json:"limit"`
+	HasMore   bool                    `json:"has_more"`
+}
+
+// OrderAttemptsResponse lists the attempts to pay one order, newest first
+type OrderAttemptsResponse struct {
+	OrderRef string            `json:"order_ref"`
+	Paid     bool              `json:"paid"` // one attempt succeeded
+	Attempts []PaymentResponse `json:"attempts"`
+}
+
 // PaymentQuery represents query parameters for payment listing
 type PaymentQuery struct {
-	Page     int            `form:"page"`
-	Limit    int            `form:"limit"`
-	UserID   *uuid.UUID     `form:"user_id"`
-	Status   *PaymentStatus `form:"status"`
-	OrderID  *string        `form:"order_id"`
+	Page          int            `form:"page"`
+	Limit         int            `form:"limit"`
+	UserID        *uuid.UUID     `form:"user_id"`
+	ProductID     *uuid.UUID     `form:"product_id"`
+	Status        *PaymentStatus `form:"status"`
+	OrderID       *string        `form:"order_id"`
+	PaymentMethod *PaymentMethod `form:"payment_method"`
+	From          *time.Time     `form:"-"` // created_at >= From
+	To            *time.Time     `form:"-"` // created_at < To
+	Search        string         `form:"q"` // matched against order_id, notes and va_number
+}
+
+// IsValid reports whether s is a known payment status
+func (s PaymentStatus) IsValid() bool {
+	switch s {
+	case PaymentStatusPending, PaymentStatusSuccess, PaymentStatusFailed,
+		PaymentStatusCancelled, PaymentStatusExpired, PaymentStatusRefunded, PaymentStatusOverdue:
+		return true
+	}
+	return false
+}
+
+// IsValid reports whether m is a supported payment method
+func (m PaymentMethod) IsValid() bool {
+	switch m {
+	case PaymentMethodCreditCard, PaymentMethodBankTransfer, PaymentMethodGoPay, PaymentMethodQRIS,
+		PaymentMethodShopeepay, PaymentMethodEchannel, PaymentMethodPermata, PaymentMethodCstore,
+		PaymentMethodInvoice:
+		return true
+	}
+	return false
 }
 
 // MidtransCallbackRequest represents the callback request from Midtrans
@@ -159,8 +339,39 @@ type MidtransCallbackRequest struct {
 	FraudStatus   string `json:"fraud_status"`
 	PaymentType   string `json:"payment_type"`
 	TransactionID string `json:"transaction_id"`
+	TransactionTime string `json:"transaction_time"`
+	SettlementTime string `json:"settlement_time"`
 	PaidAt        string `json:"paid_at"`
 	ExpiryTime    string `json:"expiry_time"`
+	Refunds       []MidtransCallbackRefund `json:"refunds"`
+}
+
+// MidtransCallbackRefund is a refund listed in a refund or partial_refund notification
+type MidtransCallbackRefund struct {
+	RefundKey string `json:"refund_key"`
+	CreatedAt string `json:"created_at"`
+}
+
+// EventTime is when the change a notification reports happened: transaction_time is when the
+// charge was created, a bank transfer, cstore or invoice settles hours or days later, a pending
+// payment expires at expiry_time and refunds can follow weeks after the settlement. Times that
+// don't parse are ignored, ok is false when none does.
+func (r *MidtransCallbackRequest) EventTime(now time.Time) (eventTime time.Time, ok bool) {
+	candidates := []string{r.TransactionTime, r.SettlementTime, r.ExpiryTime}
+	for _, refund := range r.Refunds {
+		candidates = append(candidates, refund.CreatedAt)
+	}
+	for _, value := range candidates {
+		parsed, err := timeutil.ParseMidtrans(value)
+		// expiry_time of a payment still pending lies in the future
+		if err != nil || parsed.After(now) {
+			continue
+		}
+		if !ok || parsed.After(eventTime) {
+			eventTime, ok = parsed, true
+		}
+	}
+	return eventTime, ok
 }
 
 // BeforeCreate hook to set UUID if not provided
@@ -176,11 +387,19 @@ func (p *Payment) ToResponse() PaymentResponse {
 	response := PaymentResponse{
 		ID:                    p.ID,
 		OrderID:               p.OrderID,
+		OrderRef:              p.OrderRef,
 		UserID:                p.UserID,
 		ProductID:             p.ProductID,
+		VariantID:             p.VariantID,
+		StoreID:               p.StoreID,
+		Quantity:              p.ItemQuantity(),
 		Amount:                p.Amount,
 		AdminFee:              p.AdminFee,
+		DiscountAmount:        p.DiscountAmount,
+		TaxAmount:             p.TaxAmount,
+		RoundingAmount:        p.RoundingAmount,
 		TotalAmount:           p.TotalAmount,
+		Currency:              p.Currency,
 		PaymentMethod:         p.PaymentMethod,
 		PaymentType:           p.PaymentType,
 		Status:                p.Status,
@@ -195,6 +414,8 @@ func (p *Payment) ToResponse() PaymentResponse {
 		StoreType:             p.StoreType,
 		ExpiryTime:            p.ExpiryTime,
 		PaidAt:                p.PaidAt,
+		DueDate:               p.DueDate,
+		SettlementReference:   p.SettlementReference,
 		CreatedAt:             p.CreatedAt,
 		UpdatedAt:             p.UpdatedAt,
 		User:                  p.User,
@@ -210,6 +431,103 @@ func (p *Payment) ToResponse() PaymentResponse {
 	return response
 }
 
+// ToSellerResponse converts Payment to the seller's view of it
+func (p *Payment) ToSellerResponse() SellerPaymentResponse {
+	return SellerPaymentResponse{
+		ID:             p.ID,
+		OrderID:        p.OrderID,
+		UserID:         p.UserID,
+		ProductID:      p.ProductID,
+		VariantID:      p.VariantID,
+		StoreID:        p.StoreID,
+		Quantity:       p.ItemQuantity(),
+		Amount:         p.Amount,
+		DiscountAmount: p.DiscountAmount,
+		TotalAmount:    p.TotalAmount,
+		Currency:       p.Currency,
+		PaymentMethod:  p.PaymentMethod,
+		Status:         p.Status,
+		PaidAt:         p.PaidAt,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+	}
+}
+
+// V2 converts the response to the /api/v2 shape
+func (r PaymentResponse) V2() PaymentResponseV2 {
+	currency := r.Currency
+	if currency == "" {
+		currency = money.IDR
+	}
+
+	return PaymentResponseV2{
+		ID:            r.ID,
+		OrderID:       r.OrderID,
+		OrderRef:      r.OrderRef,
+		UserID:        r.UserID,
+		ProductID:     r.ProductID,
+		VariantID:     r.VariantID,
+		StoreID:       r.StoreID,
+		Quantity:      r.Quantity,
+		Status:        r.Status,
+		PaymentMethod: r.PaymentMethod,
+		PaymentType:   r.PaymentType,
+		Amounts: PaymentAmountsV2{
+			Subtotal: money.New(r.Amount, currency),
+			AdminFee: money.New(r.AdminFee, currency),
+			Discount: money.New(r.DiscountAmount, currency),
+			Tax:      money.New(r.TaxAmount, currency),
+			Rounding: money.New(r.RoundingAmount, currency),
+			Total:    money.New(r.TotalAmount, currency),
+		},
+		Instructions: PaymentInstructionsV2{
+			PaymentCode: r.PaymentCode,
+			VANumber:    r.VANumber,
+			BankType:    r.BankType,
+			StoreType:   r.StoreType,
+			ExpiryTime:  r.ExpiryTime,
+			DueDate:     r.DueDate,
+		},
+		Midtrans: PaymentMidtransV2{
+			TransactionID:     r.MidtransTransactionID,
+			TransactionStatus: r.TransactionStatus,
+			FraudStatus:       r.FraudStatus,
+			SnapRedirectURL:   r.SnapRedirectURL,
+			Actions:           r.Actions,
+		},
+		Notes:     r.Notes,
+		PaidAt:    r.PaidAt,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+		User:      r.User,
+		Product:   r.Product,
+	}
+}
+
+// Total returns the total amount charged as money
+func (p *Payment) Total() money.Money {
+	currency := p.Currency
+	if currency == "" {
+		currency = money.IDR
+	}
+	return money.New(p.TotalAmount, currency)
+}
+
+// HasVariants reports whether a variant must be chosen to buy the product
+func (p *Product) HasVariants() bool {
+	return len(p.Variants) > 0
+}
+
+// Variant returns the active variant with the given ID, or nil
+func (p *Product) Variant(id uuid.UUID) *ProductVariant {
+	for i := range p.Variants {
+		if p.Variants[i].ID == id {
+			return &p.Variants[i]
+		}
+	}
+	return nil
+}
+
 // IsSuccessful checks if payment is successful
 func (p *Payment) IsSuccessful() bool {
 	return p.Status == PaymentStatusSuccess
@@ -224,3 +542,16 @@ func (p *Payment) IsPending() bool {
 func (p *Payment) IsFailed() bool {
 	return p.Status == PaymentStatusFailed || p.Status == PaymentStatusCancelled || p.Status == PaymentStatusExpired
 }
+
+// ItemQuantity returns the units bought, 1 for payments created before quantities were stored
+func (p *Payment) ItemQuantity() int {
+	if p.Quantity < 1 {
+		return 1
+	}
+	return p.Quantity
+}
+
+// IsInvoice checks if payment is a B2B invoice settled outside Midtrans
+func (p *Payment) IsInvoice() bool {
+	return p.PaymentMethod == PaymentMethodInvoice
+}
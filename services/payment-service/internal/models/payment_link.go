@@ -0,0 +1,98 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentLinkStatus represents the state of a payment link
+type PaymentLinkStatus string
+
+const (
+	PaymentLinkActive    PaymentLinkStatus = "ACTIVE"
+	PaymentLinkRedeemed  PaymentLinkStatus = "REDEEMED" // a payment was created from the link
+	PaymentLinkCancelled PaymentLinkStatus = "CANCELLED"
+	PaymentLinkExpired   PaymentLinkStatus = "EXPIRED" // reported only, derived from ExpiresAt
+)
+
+// PaymentLink lets someone other than the purchaser pay for an order. Only the SHA-256 of
+// the token is stored; the token itself is returned once when the link is created.
+type PaymentLink struct {
+	ID          uuid.UUID         `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TokenHash   string            `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	CreatorID   uuid.UUID         `json:"creator_id" gorm:"type:uuid;not null;index"` // purchaser owning the resulting payment
+	ProductID   uuid.UUID         `json:"product_id" gorm:"type:uuid;not null"`
+	Amount      int64             `json:"amount" gorm:"not null"`     // in rupiah
+	AdminFee    int64             `json:"admin_fee" gorm:"default:0"` // in rupiah
+	Discount    int64             `json:"discount_amount" gorm:"default:0"`
+	Tax         int64             `json:"tax_amount" gorm:"default:0"`
+	Rounding    int64             `json:"rounding_amount" gorm:"default:0"`
+	TotalAmount int64             `json:"total_amount" gorm:"not null"`
+	Currency    string            `json:"currency" gorm:"size:3;not null;default:'IDR'"`
+	Notes       *string           `json:"notes"`
+	Status      PaymentLinkStatus `json:"status" gorm:"size:20;not null;default:'ACTIVE'"`
+	PaymentID   *uuid.UUID        `json:"payment_id" gorm:"type:uuid"`
+	ExpiresAt   time.Time         `json:"expires_at" gorm:"not null"`
+	RedeemedAt  *time.Time        `json:"redeemed_at"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// TableName specifies the table name for PaymentLink
+func (PaymentLink) TableName() string {
+	return "payment_links"
+}
+
+// EffectiveStatus reports EXPIRED for active links past their expiry
+func (pl *PaymentLink) EffectiveStatus() PaymentLinkStatus {
+	if pl.Status == PaymentLinkActive && time.Now().After(pl.ExpiresAt) {
+		return PaymentLinkExpired
+	}
+	return pl.Status
+}
+
+// HashPaymentLinkToken returns the stored form of a link token
+func HashPaymentLinkToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreatePaymentLinkRequest represents the request payload for creating a payment link
+type CreatePaymentLinkRequest struct {
+	ProductID      *uuid.UUID `json:"product_id" binding:"required"`
+	Amount         int64      `json:"amount" binding:"required,min=1"`
+	AdminFee       int64      `json:"admin_fee" binding:"min=0"`
+	Notes          *string    `json:"notes,omitempty"`
+	ExpiresInHours int        `json:"expires_in_hours,omitempty" binding:"min=0,max=720"` // 0 for the default
+}
+
+// RedeemPaymentLinkRequest is sent by the payer to pay a link with a method of their choice
+type RedeemPaymentLinkRequest struct {
+	PaymentMethod PaymentMethod `json:"payment_method" binding:"required"`
+	BankType      *string       `json:"bank_type,omitempty"`
+	StoreType     *string       `json:"store_type,omitempty"`
+	PayerName     string        `json:"payer_name,omitempty" binding:"max=100"`
+	PayerEmail    string        `json:"payer_email,omitempty" binding:"omitempty,email"`
+	PayerPhone    string        `json:"payer_phone,omitempty" binding:"omitempty,e164"`
+}
+
+// PaymentLinkPage is the public view of a link shown to the payer
+type PaymentLinkPage struct {
+	Status        PaymentLinkStatus `json:"status"`
+	ProductID     uuid.UUID         `json:"product_id"`
+	ProductName   string            `json:"product_name,omitempty"`
+	RequestedBy   string            `json:"requested_by,omitempty"`
+	Amount        int64             `json:"amount"`
+	AdminFee      int64             `json:"admin_fee"`
+	Discount      int64             `json:"discount_amount"`
+	Tax           int64             `json:"tax_amount"`
+	Rounding      int64             `json:"rounding_amount"`
+	TotalAmount   int64             `json:"total_amount"`
+	Currency      string            `json:"currency"`
+	Notes         *string           `json:"notes,omitempty"`
+	ExpiresAt     time.Time         `json:"expires_at"`
+	PaymentStatus *PaymentStatus    `json:"payment_status,omitempty"` // once redeemed
+}
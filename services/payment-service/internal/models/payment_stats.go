@@ -0,0 +1,37 @@
+package models
+
+// PaymentMethodStats counts the outcomes of the payments created with one method (and bank,
+// for bank transfers) within a window. Rates are over the decided payments (success, failed
+// and expired): cancelled attempts were superseded by the buyer and pending ones are still
+// open, so neither says anything about the channel.
+type PaymentMethodStats struct {
+	PaymentMethod string  `json:"payment_method"`
+	BankType      string  `json:"bank_type,omitempty"`
+	Total         int64   `json:"total"`
+	Success       int64   `json:"success"` // including refunded
+	Failed        int64   `json:"failed"`
+	Expired       int64   `json:"expired"`
+	Cancelled     int64   `json:"cancelled"`
+	Pending       int64   `json:"pending"`
+	SuccessRate   float64 `json:"success_rate"`
+	FailureRate   float64 `json:"failure_rate"`
+	ExpiryRate    float64 `json:"expiry_rate"`
+}
+
+// ComputeRates fills the rates from the counts; without decided payments they stay 0
+func (s *PaymentMethodStats) ComputeRates() {
+	decided := s.Success + s.Failed + s.Expired
+	if decided == 0 {
+		return
+	}
+	s.SuccessRate = float64(s.Success) / float64(decided)
+	s.FailureRate = float64(s.Failed) / float64(decided)
+	s.ExpiryRate = float64(s.Expired) / float64(decided)
+}
+
+// PaymentMethodWindowStats is the per method breakdown of one rolling window
+type PaymentMethodWindowStats struct {
+	Window  string               `json:"window"` // e.g. 15m0s, 1h0m0s
+	Since   string               `json:"since"`  // RFC3339
+	Methods []PaymentMethodStats `json:"methods"`
+}
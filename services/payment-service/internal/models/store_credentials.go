@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StoreMidtransCredentials are a store's own Midtrans keys, used instead of the platform
+// keys for payments of the store's products. Stores are owned by Product-Service; the
+// credentials are owned here so server keys never leave Payment-Service. The server key
+// is encrypted at rest, the client key is public (it is handed to the frontend).
+type StoreMidtransCredentials struct {
+	StoreID            uuid.UUID `json:"store_id" gorm:"type:uuid;primary_key"`
+	OwnerID            uuid.UUID `json:"owner_id" gorm:"type:uuid;not null;index"`
+	ServerKeyEncrypted string    `json:"-" gorm:"type:text;not null"`
+	ServerKeyHint      string    `json:"server_key_hint" gorm:"size:10"` // last characters, to tell keys apart
+	ClientKey          string    `json:"client_key" gorm:"not null"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for StoreMidtransCredentials
+func (StoreMidtransCredentials) TableName() string {
+	return "store_midtrans_credentials"
+}
+
+// StoreCredentialsRequest represents the request payload for configuring a store's Midtrans keys
+type StoreCredentialsRequest struct {
+	ServerKey string `json:"server_key" binding:"required"`
+	ClientKey string `json:"client_key" binding:"required"`
+}
+
+// Store is a transient view of a store fetched from Product-Service per request
+type Store struct {
+	ID       uuid.UUID `json:"id"`
+	OwnerID  uuid.UUID `json:"owner_id"`
+	Name     string    `json:"name"`
+	IsActive bool      `json:"is_active"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserProfile is Payment-Service's read model of a user owned by User-Service.
+// It is populated from user.* events and refreshed from User-Service on a miss.
+type UserProfile struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	Username    string    `json:"username" gorm:"size:100"`
+	Email       string    `json:"email" gorm:"size:150"`
+	PhoneNumber *string   `json:"phone_number,omitempty" gorm:"size:20"` // verified E.164 number, nil when unknown
+	// AnonymizedAt is set once user.deleted arrived: the contact data is cleared and no
+	// notification is sent to the user again
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// AnonymizedUsername replaces the username of deleted users
+const AnonymizedUsername = "deleted-user"
+
+// Anonymized reports whether the user was deleted in User-Service
+func (p *UserProfile) Anonymized() bool {
+	return p.AnonymizedAt != nil
+}
+
+// TableName specifies the table name for UserProfile
+func (UserProfile) TableName() string {
+	return "user_profiles"
+}
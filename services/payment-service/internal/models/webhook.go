@@ -0,0 +1,91 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook event types delivered to merchant endpoints
+const (
+	WebhookEventPaymentSuccess  = "payment.success"
+	WebhookEventPaymentFailed   = "payment.failed"
+	WebhookEventPaymentRefunded = "payment.refunded"
+)
+
+// WebhookEventTypes lists every event type a merchant endpoint can subscribe to
+var WebhookEventTypes = []string{
+	WebhookEventPaymentSuccess,
+	WebhookEventPaymentFailed,
+	WebhookEventPaymentRefunded,
+}
+
+// WebhookDeliveryStatus represents the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "FAILED"
+)
+
+// WebhookEndpoint is a merchant URL receiving signed payment callbacks
+type WebhookEndpoint struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name        string    `json:"name" gorm:"not null"`
+	URL         string    `json:"url" gorm:"not null"`
+	Secret      string    `json:"-" gorm:"not null"`           // HMAC signing secret, only returned on create
+	EventTypes  string    `json:"event_types" gorm:"not null"` // Comma separated list of subscribed events
+	IsActive    bool      `json:"is_active" gorm:"default:true"`
+	Description *string   `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Subscribes reports whether the endpoint wants the given event type
+func (we *WebhookEndpoint) Subscribes(eventType string) bool {
+	for _, subscribed := range strings.Split(we.EventTypes, ",") {
+		if strings.TrimSpace(subscribed) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records a single event sent (or to be sent) to an endpoint
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	EndpointID     uuid.UUID             `json:"endpoint_id" gorm:"type:uuid;not null;index"`
+	EventID        uuid.UUID             `json:"event_id" gorm:"type:uuid;not null;index"` // Shared by every delivery of the same event
+	EventType      string                `json:"event_type" gorm:"not null"`
+	PaymentID      string                `json:"payment_id" gorm:"index"`
+	Payload        string                `json:"payload" gorm:"type:text;not null"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"default:'PENDING';index"`
+	Attempts       int                   `json:"attempts" gorm:"default:0"`
+	ResponseStatus *int                  `json:"response_status"`
+	LastError      *string               `json:"last_error"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at" gorm:"index"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// CreateWebhookEndpointRequest represents the request to register a merchant endpoint
+type CreateWebhookEndpointRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	URL         string   `json:"url" binding:"required,url"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1"`
+	Secret      string   `json:"secret,omitempty"` // Generated when empty
+	Description *string  `json:"description,omitempty"`
+}
+
+// UpdateWebhookEndpointRequest represents the request to update a merchant endpoint
+type UpdateWebhookEndpointRequest struct {
+	Name         *string  `json:"name,omitempty"`
+	URL          *string  `json:"url,omitempty" binding:"omitempty,url"`
+	EventTypes   []string `json:"event_types,omitempty"`
+	IsActive     *bool    `json:"is_active,omitempty"`
+	Description  *string  `json:"description,omitempty"`
+	RotateSecret bool     `json:"rotate_secret,omitempty"`
+}
@@ -0,0 +1,154 @@
+// Package money represents amounts as integer minor units with their currency, so amounts
+// are never held in floats and values from other services or Midtrans are converted and
+// compared exactly.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IDR is the only currency Midtrans charges in. Rupiah has no minor unit in practice,
+// so one minor unit is one rupiah.
+const IDR = "IDR"
+
+// exponents maps supported currencies to their number of decimal places
+var exponents = map[string]int{
+	IDR: 0,
+}
+
+var (
+	// ErrUnsupportedCurrency is returned for currencies without a known exponent
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	// ErrCurrencyMismatch is returned when combining amounts of different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrOverflow is returned when an amount doesn't fit in int64 minor units
+	ErrOverflow = errors.New("amount overflows int64")
+	// ErrPrecision is returned when an amount has more decimals than its currency allows
+	ErrPrecision = errors.New("amount has more precision than the currency allows")
+)
+
+// Money is an amount in minor units of a currency
+type Money struct {
+	Minor    int64  `json:"minor"`
+	Currency string `json:"currency"`
+}
+
+// New returns minor units of currency
+func New(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: currency}
+}
+
+// ParseDecimal parses a decimal string in major units such as Midtrans' gross_amount
+// ("150000.00") without going through floating point
+func ParseDecimal(value, currency string) (Money, error) {
+	exponent, ok := exponents[currency]
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, currency)
+	}
+
+	value = strings.TrimSpace(value)
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" || strings.HasPrefix(whole, "+") {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+
+	for _, r := range fraction {
+		if r < '0' || r > '9' {
+			return Money{}, fmt.Errorf("invalid amount %q", value)
+		}
+	}
+	// Trailing zeros beyond the currency's precision are fine ("150000.00" for IDR)
+	trimmed := strings.TrimRight(fraction, "0")
+	if len(trimmed) > exponent {
+		return Money{}, fmt.Errorf("%w: %q %s", ErrPrecision, value, currency)
+	}
+	digits := whole + trimmed + strings.Repeat("0", exponent-len(trimmed))
+
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return Money{}, fmt.Errorf("%w: %q", ErrOverflow, value)
+		}
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+// Add returns m + other, failing on mixed currencies or overflow
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	sum := m.Minor + other.Minor
+	if (other.Minor > 0 && sum < m.Minor) || (other.Minor < 0 && sum > m.Minor) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Minor: sum, Currency: m.Currency}, nil
+}
+
+// Equal reports whether both amounts are the same value in the same currency
+func (m Money) Equal(other Money) bool {
+	return m.Minor == other.Minor && m.Currency == other.Currency
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.Minor > 0
+}
+
+// Decimal formats the amount in major units with two decimals, as Midtrans does
+func (m Money) Decimal() string {
+	exponent := exponents[m.Currency]
+	minor := m.Minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	pow := int64(1)
+	for i := 0; i < exponent; i++ {
+		pow *= 10
+	}
+	major, fraction := minor/pow, minor%pow
+
+	decimals := fmt.Sprintf("%0*d", exponent, fraction)
+	for len(decimals) < 2 {
+		decimals += "0"
+	}
+	return fmt.Sprintf("%s%d.%s", sign, major, decimals)
+}
+
+// String formats the amount for logs, e.g. "IDR 150000.00"
+func (m Money) String() string {
+	return m.Currency + " " + m.Decimal()
+}
+
+// Major is an amount other services write in JSON as a plain number of major units of IDR,
+// the way Product-Service sends prices (150000). It is decoded from the number's text with
+// ParseDecimal, never through float64, so a price that isn't whole rupiah fails to decode.
+type Major struct {
+	Money
+}
+
+// UnmarshalJSON decodes a JSON number in major units, null leaves the amount unchanged
+func (m *Major) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	amount, err := ParseDecimal(text, IDR)
+	if err != nil {
+		return err
+	}
+	m.Money = amount
+	return nil
+}
+
+// MarshalJSON encodes the amount as a JSON number in major units
+func (m Major) MarshalJSON() ([]byte, error) {
+	return []byte(m.Decimal()), nil
+}
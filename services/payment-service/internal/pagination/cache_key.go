@@ -0,0 +1,63 @@
+// Package pagination builds cache keys for paginated list queries.
+package pagination
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CacheKey returns prefix:<hash> for a list query. The hash covers every exported field of
+// the query struct, so a filter added to the struct is part of the key without touching the
+// callers. Unset fields (nil pointers, zero values) are left out, so adding a field doesn't
+// change the keys of queries that don't use it.
+//
+// query should be normalized first (defaults applied, values trimmed), otherwise equivalent
+// queries are cached separately.
+func CacheKey(prefix string, query interface{}) string {
+	value := reflect.Indirect(reflect.ValueOf(query))
+	if value.Kind() != reflect.Struct {
+		return prefix + ":" + hashFields([]string{fmt.Sprint(query)})
+	}
+
+	var fields []string
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		if fieldValue.Kind() == reflect.Pointer {
+			if fieldValue.IsNil() {
+				continue
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		if fieldValue.IsZero() {
+			continue
+		}
+		fields = append(fields, field.Name+"="+formatField(fieldValue))
+	}
+	sort.Strings(fields)
+
+	return prefix + ":" + hashFields(fields)
+}
+
+// formatField formats a field value for the key, times in UTC so zones don't split entries
+func formatField(value reflect.Value) string {
+	if t, ok := value.Interface().(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+// hashFields hashes the fields so free text can't produce unbounded or unsafe keys
+func hashFields(fields []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(fields, "&")))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"payment-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ArchivePayments moves up to batchSize payments created before cutoff and in a final
+// status (models.ArchivedStatuses) to payments_archive, oldest first, and returns them.
+// Rows are locked with SKIP LOCKED, so instances archiving at the same time take different
+// batches, and a payment whose status is being updated is left for the next run.
+func (pr *PaymentRepository) ArchivePayments(ctx context.Context, cutoff time.Time, batchSize int) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := pr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("created_at < ? AND status IN ?", cutoff, models.ArchivedStatuses).
+			Order("created_at ASC").
+			Limit(batchSize).
+			Find(&payments).Error; err != nil {
+			return fmt.Errorf("failed to select payments to archive: %w", err)
+		}
+		if len(payments) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		archived := make([]models.ArchivedPayment, len(payments))
+		ids := make([]uuid.UUID, len(payments))
+		for i, payment := range payments {
+			var err error
+			if archived[i], err = models.NewArchivedPayment(payment, now); err != nil {
+				return err
+			}
+			ids[i] = payment.ID
+		}
+
+		// A payment already archived by an interrupted run keeps its first copy
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&archived).Error; err != nil {
+			return fmt.Errorf("failed to archive payments: %w", err)
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&models.Payment{}).Error; err != nil {
+			return fmt.Errorf("failed to delete archived payments: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// PurgeArchivedPayments deletes up to batchSize archived payments created before cutoff,
+// past the archive retention, and returns how many were deleted
+func (pr *PaymentRepository) PurgeArchivedPayments(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	result := pr.db.WithContext(ctx).Exec(
+		`DELETE FROM payments_archive WHERE id IN (SELECT id FROM payments_archive WHERE created_at < ? ORDER BY created_at LIMIT ?)`,
+		cutoff, batchSize,
+	)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge archived payments: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetArchived retrieves archived payments with pagination and the filters of GetAll.
+// Free-text search only matches order IDs, notes and VA numbers aren't indexed in the archive.
+func (pr *PaymentRepository) GetArchived(ctx context.Context, query models.PaymentQuery) ([]models.ArchivedPayment, int64, error) {
+	var archived []models.ArchivedPayment
+	var total int64
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+	offset := (query.Page - 1) * query.Limit
+
+	err := pr.read(ctx, func(db *gorm.DB) error {
+		search := query.Search
+		query.Search = ""
+		db = applyPaymentFilters(db.Model(&models.ArchivedPayment{}), query)
+		if search != "" {
+			db = db.Where("order_id ILIKE ?", "%"+escapeLike(search)+"%")
+		}
+
+		if err := db.Count(&total).Error; err != nil {
+			return fmt.Errorf("failed to count archived payments: %w", err)
+		}
+		if err := db.Order("created_at DESC").
+			Offset(offset).
+			Limit(query.Limit).
+			Find(&archived).Error; err != nil {
+			return fmt.Errorf("failed to get archived payments: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return archived, total, nil
+}
+
+// GetArchivedByID retrieves an archived payment by ID
+func (pr *PaymentRepository) GetArchivedByID(ctx context.Context, id uuid.UUID) (*models.ArchivedPayment, error) {
+	var archived models.ArchivedPayment
+	if err := pr.db.WithContext(ctx).Where("id = ?", id).First(&archived).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get archived payment: %w", err)
+	}
+	return &archived, nil
+}
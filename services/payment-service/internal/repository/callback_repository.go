@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"payment-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CallbackRepository records processed Midtrans notifications for replay protection
+type CallbackRepository struct {
+	db *gorm.DB
+}
+
+// NewCallbackRepository creates a new callback repository
+func NewCallbackRepository(db *gorm.DB) *CallbackRepository {
+	return &CallbackRepository{db: db}
+}
+
+// Claim records a notification and reports whether this call recorded it. False means an
+// identical notification (same fingerprint) was already claimed.
+func (cr *CallbackRepository) Claim(callback *models.MidtransCallback) (bool, error) {
+	callback.ReceivedAt = time.Now()
+	result := cr.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "fingerprint"}},
+		DoNothing: true,
+	}).Create(callback)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to record midtrans callback: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Release removes a claim whose processing failed so Midtrans' retry is processed
+func (cr *CallbackRepository) Release(id uuid.UUID) error {
+	if err := cr.db.Delete(&models.MidtransCallback{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("failed to release midtrans callback: %w", err)
+	}
+	return nil
+}
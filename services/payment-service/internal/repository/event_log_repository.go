@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"payment-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EventLogFilter holds the optional filters for querying the event log
+type EventLogFilter struct {
+	IDs        []uuid.UUID
+	From       *time.Time
+	To         *time.Time
+	RoutingKey string
+	Limit      int
+}
+
+// EventLogRepository handles published event log database operations
+type EventLogRepository struct {
+	db *gorm.DB
+}
+
+// NewEventLogRepository creates a new event log repository
+func NewEventLogRepository(db *gorm.DB) *EventLogRepository {
+	return &EventLogRepository{db: db}
+}
+
+// Create stores a published event
+func (er *EventLogRepository) Create(ctx context.Context, eventLog *models.EventLog) error {
+	if err := er.db.WithContext(ctx).Create(eventLog).Error; err != nil {
+		return fmt.Errorf("failed to create event log: %w", err)
+	}
+	return nil
+}
+
+// List returns logged events matching the filter in publish order
+func (er *EventLogRepository) List(filter EventLogFilter) ([]models.EventLog, error) {
+	query := er.db.Model(&models.EventLog{})
+
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.From != nil {
+		query = query.Where("published_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("published_at <= ?", *filter.To)
+	}
+	if filter.RoutingKey != "" {
+		query = query.Where("routing_key = ?", filter.RoutingKey)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var eventLogs []models.EventLog
+	if err := query.Order("published_at ASC").Find(&eventLogs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list event logs: %w", err)
+	}
+	return eventLogs, nil
+}
+
+// MarkReplayed increments the replay counter of an event
+func (er *EventLogRepository) MarkReplayed(id uuid.UUID, replayedAt time.Time) error {
+	err := er.db.Model(&models.EventLog{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"replay_count":     gorm.Expr("replay_count + 1"),
+			"last_replayed_at": replayedAt,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark event replayed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"payment-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PaymentLinkRepository handles payment link database operations
+type PaymentLinkRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentLinkRepository creates a new payment link repository
+func NewPaymentLinkRepository(db *gorm.DB) *PaymentLinkRepository {
+	return &PaymentLinkRepository{db: db}
+}
+
+// Create creates a new payment link
+func (lr *PaymentLinkRepository) Create(link *models.PaymentLink) error {
+	if err := lr.db.Create(link).Error; err != nil {
+		return fmt.Errorf("failed to create payment link: %w", err)
+	}
+	return nil
+}
+
+// GetByToken retrieves a payment link by its (unhashed) token
+func (lr *PaymentLinkRepository) GetByToken(token string) (*models.PaymentLink, error) {
+	var link models.PaymentLink
+	if err := lr.db.First(&link, "token_hash = ?", models.HashPaymentLinkToken(token)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("payment link not found")
+		}
+		return nil, fmt.Errorf("failed to get payment link: %w", err)
+	}
+	return &link, nil
+}
+
+// ListByCreator returns a purchaser's links, newest first
+func (lr *PaymentLinkRepository) ListByCreator(creatorID uuid.UUID, limit int) ([]models.PaymentLink, error) {
+	var links []models.PaymentLink
+	if err := lr.db.Where("creator_id = ?", creatorID).Order("created_at DESC").Limit(limit).Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to list payment links: %w", err)
+	}
+	return links, nil
+}
+
+// Redeem marks an active, unexpired link as redeemed by paymentID. It reports false when
+// the link was redeemed, cancelled or expired in the meantime, so a link pays at most once.
+func (lr *PaymentLinkRepository) Redeem(id, paymentID uuid.UUID) (bool, error) {
+	now := time.Now()
+	result := lr.db.Model(&models.PaymentLink{}).
+		Where("id = ? AND status = ? AND expires_at > ?", id, models.PaymentLinkActive, now).
+		Updates(map[string]interface{}{
+			"status":      models.PaymentLinkRedeemed,
+			"payment_id":  paymentID,
+			"redeemed_at": now,
+			"updated_at":  now,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to redeem payment link: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Release reactivates a link whose charge failed after it was redeemed by paymentID
+func (lr *PaymentLinkRepository) Release(id, paymentID uuid.UUID) error {
+	err := lr.db.Model(&models.PaymentLink{}).
+		Where("id = ? AND payment_id = ?", id, paymentID).
+		Updates(map[string]interface{}{
+			"status":      models.PaymentLinkActive,
+			"payment_id":  nil,
+			"redeemed_at": nil,
+			"updated_at":  time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release payment link: %w", err)
+	}
+	return nil
+}
+
+// Cancel cancels an active link owned by creatorID
+func (lr *PaymentLinkRepository) Cancel(id, creatorID uuid.UUID) (bool, error) {
+	result := lr.db.Model(&models.PaymentLink{}).
+		Where("id = ? AND creator_id = ? AND status = ?", id, creatorID, models.PaymentLinkActive).
+		Updates(map[string]interface{}{
+			"status":     models.PaymentLinkCancelled,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to cancel payment link: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -1,18 +1,31 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"payment-service/internal/models"
+	"payment-service/internal/redact"
+	"payment-service/internal/secrets"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// PaymentRepository handles payment database operations
+// ErrOrderAlreadyPaid is returned when another attempt of the same order already succeeded
+var ErrOrderAlreadyPaid = errors.New("order already paid by another attempt")
+
+// PaymentRepository handles payment database operations. Every method takes the caller's
+// context so queries are cancelled with the request and honour its deadline.
 type PaymentRepository struct {
-	db *gorm.DB
+	db      *gorm.DB
+	replica *gorm.DB     // serves listings and stats, nil reads everything from db
+	box     *secrets.Box // encrypts midtrans_response at rest, nil stores it in plaintext
 }
 
 // NewPaymentRepository creates a new payment repository
@@ -20,18 +33,59 @@ func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
 	return &PaymentRepository{db: db}
 }
 
+// SetEncryption encrypts the raw Midtrans responses (customer details, VA numbers) written
+// from now on with box
+func (pr *PaymentRepository) SetEncryption(box *secrets.Box) {
+	pr.box = box
+}
+
+// SetReplica serves payment listings, exports and stats from a read replica, falling back to
+// the primary when it fails. Lookups of single payments stay on the primary, they are usually
+// followed by a status transition and must see the latest write.
+func (pr *PaymentRepository) SetReplica(replica *gorm.DB) {
+	pr.replica = replica
+}
+
+// read runs a read only query on the replica, see SetReplica
+func (pr *PaymentRepository) read(ctx context.Context, query func(db *gorm.DB) error) error {
+	return readWithFallback(ctx, pr.db, pr.replica, query)
+}
+
+// sealMidtransResponse encrypts the payment's midtrans_response in place for storage. The
+// returned function restores the plaintext once the write is done.
+func (pr *PaymentRepository) sealMidtransResponse(payment *models.Payment) (func(), error) {
+	plain := payment.MidtransResponse
+	restore := func() { payment.MidtransResponse = plain }
+	if pr.box == nil || plain == nil || secrets.IsEncrypted(*plain) {
+		return restore, nil
+	}
+
+	sealed, err := pr.box.Encrypt(*plain)
+	if err != nil {
+		return restore, fmt.Errorf("failed to encrypt Midtrans response: %w", err)
+	}
+	payment.MidtransResponse = &sealed
+	return restore, nil
+}
+
 // Create creates a new payment
-func (pr *PaymentRepository) Create(payment *models.Payment) error {
-	if err := pr.db.Create(payment).Error; err != nil {
+func (pr *PaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	restore, err := pr.sealMidtransResponse(payment)
+	defer restore()
+	if err != nil {
+		return err
+	}
+
+	if err := pr.db.WithContext(ctx).Create(payment).Error; err != nil {
 		return fmt.Errorf("failed to create payment: %w", err)
 	}
 	return nil
 }
 
 // GetByID retrieves a payment by ID
-func (pr *PaymentRepository) GetByID(id uuid.UUID) (*models.Payment, error) {
+func (pr *PaymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Payment, error) {
 	var payment models.Payment
-	if err := pr.db.First(&payment, "id = ?", id).Error; err != nil {
+	if err := pr.db.WithContext(ctx).First(&payment, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("payment not found")
 		}
@@ -41,9 +95,9 @@ func (pr *PaymentRepository) GetByID(id uuid.UUID) (*models.Payment, error) {
 }
 
 // GetByIDWithoutRelations retrieves a payment by ID without loading relations
-func (pr *PaymentRepository) GetByIDWithoutRelations(id uuid.UUID) (*models.Payment, error) {
+func (pr *PaymentRepository) GetByIDWithoutRelations(ctx context.Context, id uuid.UUID) (*models.Payment, error) {
 	var payment models.Payment
-	if err := pr.db.First(&payment, "id = ?", id).Error; err != nil {
+	if err := pr.db.WithContext(ctx).First(&payment, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("payment not found")
 		}
@@ -53,9 +107,9 @@ func (pr *PaymentRepository) GetByIDWithoutRelations(id uuid.UUID) (*models.Paym
 }
 
 // GetByOrderID retrieves a payment by order ID
-func (pr *PaymentRepository) GetByOrderID(orderID string) (*models.Payment, error) {
+func (pr *PaymentRepository) GetByOrderID(ctx context.Context, orderID string) (*models.Payment, error) {
 	var payment models.Payment
-	if err := pr.db.First(&payment, "order_id = ?", orderID).Error; err != nil {
+	if err := pr.db.WithContext(ctx).First(&payment, "order_id = ?", orderID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("payment not found")
 		}
@@ -64,130 +118,334 @@ func (pr *PaymentRepository) GetByOrderID(orderID string) (*models.Payment, erro
 	return &payment, nil
 }
 
+// GetByOrderRef retrieves every attempt to pay an order, newest first
+func (pr *PaymentRepository) GetByOrderRef(ctx context.Context, orderRef string) ([]models.Payment, error) {
+	var payments []models.Payment
+	if err := pr.db.WithContext(ctx).Where("order_ref = ?", orderRef).
+		Order("created_at DESC").
+		Find(&payments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get payments of order %s: %w", orderRef, err)
+	}
+	return payments, nil
+}
+
 // GetByUserID retrieves payments by user ID with pagination
-func (pr *PaymentRepository) GetByUserID(userID uuid.UUID, page, limit int) ([]models.Payment, int64, error) {
+func (pr *PaymentRepository) GetByUserID(ctx context.Context, userID uuid.UUID, page, limit int) ([]models.Payment, int64, error) {
 	var payments []models.Payment
 	var total int64
 
-	// Count total records
-	if err := pr.db.Model(&models.Payment{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count payments: %w", err)
-	}
-
 	// Calculate offset
 	offset := (page - 1) * limit
 
-	// Get payments with pagination
-	if err := pr.db.Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(limit).
-		Find(&payments).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to get payments: %w", err)
+	err := pr.read(ctx, func(db *gorm.DB) error {
+		// Count total records
+		if err := db.Model(&models.Payment{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+			return fmt.Errorf("failed to count payments: %w", err)
+		}
+
+		// Get payments with pagination
+		if err := db.Where("user_id = ?", userID).
+			Order("created_at DESC").
+			Offset(offset).
+			Limit(limit).
+			Find(&payments).Error; err != nil {
+			return fmt.Errorf("failed to get payments: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
 	}
 
 	return payments, total, nil
 }
 
+// ForEachProductPayment calls fn with the ID, order ID and user ID of every payment of a
+// product, in batches of 500, so their cached responses can be invalidated
+func (pr *PaymentRepository) ForEachProductPayment(ctx context.Context, productID uuid.UUID, fn func([]models.Payment) error) error {
+	var payments []models.Payment
+	err := pr.db.WithContext(ctx).Model(&models.Payment{}).
+		Select("id", "order_id", "user_id").
+		Where("product_id = ?", productID).
+		FindInBatches(&payments, 500, func(tx *gorm.DB, batch int) error {
+			return fn(payments)
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to get payments of product %s: %w", productID, err)
+	}
+	return nil
+}
+
 // GetByStatus retrieves payments by status with pagination
-func (pr *PaymentRepository) GetByStatus(status models.PaymentStatus, page, limit int) ([]models.Payment, int64, error) {
+func (pr *PaymentRepository) GetByStatus(ctx context.Context, status models.PaymentStatus, page, limit int) ([]models.Payment, int64, error) {
 	var payments []models.Payment
 	var total int64
 
-	// Count total records
-	if err := pr.db.Model(&models.Payment{}).Where("status = ?", status).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count payments: %w", err)
-	}
-
 	// Calculate offset
 	offset := (page - 1) * limit
 
-	// Get payments with pagination
-	if err := pr.db.Where("status = ?", status).
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(limit).
-		Find(&payments).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to get payments: %w", err)
+	err := pr.read(ctx, func(db *gorm.DB) error {
+		// Count total records
+		if err := db.Model(&models.Payment{}).Where("status = ?", status).Count(&total).Error; err != nil {
+			return fmt.Errorf("failed to count payments: %w", err)
+		}
+
+		// Get payments with pagination
+		if err := db.Where("status = ?", status).
+			Order("created_at DESC").
+			Offset(offset).
+			Limit(limit).
+			Find(&payments).Error; err != nil {
+			return fmt.Errorf("failed to get payments: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
 	}
 
 	return payments, total, nil
 }
 
 // GetAll retrieves all payments with pagination and filters
-func (pr *PaymentRepository) GetAll(query models.PaymentQuery) ([]models.Payment, int64, error) {
+func (pr *PaymentRepository) GetAll(ctx context.Context, query models.PaymentQuery) ([]models.Payment, int64, error) {
 	var payments []models.Payment
 	var total int64
 
-	// Build query
-	db := pr.db.Model(&models.Payment{})
+	// Set default pagination values
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+
+	// Calculate offset
+	offset := (query.Page - 1) * query.Limit
+
+	err := pr.read(ctx, func(db *gorm.DB) error {
+		// Build query with filters
+		db = applyPaymentFilters(db.Model(&models.Payment{}), query)
+
+		// Count total records
+		if err := db.Count(&total).Error; err != nil {
+			return fmt.Errorf("failed to count payments: %w", err)
+		}
+
+		// Get payments with pagination
+		if err := db.Order("created_at DESC").
+			Offset(offset).
+			Limit(query.Limit).
+			Find(&payments).Error; err != nil {
+			return fmt.Errorf("failed to get payments: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return payments, total, nil
+}
 
-	// Apply filters
+// applyPaymentFilters narrows db to the payments matching query
+func applyPaymentFilters(db *gorm.DB, query models.PaymentQuery) *gorm.DB {
 	if query.UserID != nil {
 		db = db.Where("user_id = ?", *query.UserID)
 	}
+	if query.ProductID != nil {
+		db = db.Where("product_id = ?", *query.ProductID)
+	}
 	if query.Status != nil {
 		db = db.Where("status = ?", *query.Status)
 	}
 	if query.OrderID != nil {
 		db = db.Where("order_id = ?", *query.OrderID)
 	}
+	if query.PaymentMethod != nil {
+		db = db.Where("payment_method = ?", *query.PaymentMethod)
+	}
+	if query.From != nil {
+		db = db.Where("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("created_at < ?", *query.To)
+	}
+	if query.Search != "" {
+		pattern := "%" + escapeLike(query.Search) + "%"
+		db = db.Where("(order_id ILIKE ? OR notes ILIKE ? OR va_number ILIKE ?)", pattern, pattern, pattern)
+	}
+	return db
+}
+
+// StreamPayments calls fn with successive batches of payments matching query, newest first.
+// Batches are read with a (created_at, id) keyset cursor instead of OFFSET so rows created
+// during the export can't shift pages and produce duplicates or gaps.
+func (pr *PaymentRepository) StreamPayments(ctx context.Context, query models.PaymentQuery, batchSize int, fn func([]models.Payment) error) error {
+	var cursorTime *time.Time
+	var cursorID uuid.UUID
+
+	for {
+		var batch []models.Payment
+		err := pr.read(ctx, func(db *gorm.DB) error {
+			db = applyPaymentFilters(db.Model(&models.Payment{}), query)
+			if cursorTime != nil {
+				db = db.Where("(created_at, id) < (?, ?)", *cursorTime, cursorID)
+			}
+			return db.Order("created_at DESC, id DESC").Limit(batchSize).Find(&batch).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get payments: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
 
-	// Count total records
-	if err := db.Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count payments: %w", err)
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+
+		last := batch[len(batch)-1]
+		cursorTime, cursorID = &last.CreatedAt, last.ID
 	}
+}
 
-	// Set default pagination values
-	if query.Page <= 0 {
-		query.Page = 1
+// EnsureOrderGroups puts payments stored before attempts were grouped in an order of their
+// own and creates the partial unique index allowing one successful attempt per order
+func (pr *PaymentRepository) EnsureOrderGroups(ctx context.Context) error {
+	statements := []string{
+		"UPDATE payments SET order_ref = order_id WHERE order_ref IS NULL OR order_ref = ''",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_order_ref_success ON payments (order_ref) WHERE status = 'SUCCESS'",
 	}
-	if query.Limit <= 0 {
-		query.Limit = 10
+	for _, statement := range statements {
+		if err := pr.db.WithContext(ctx).Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to set up payment order groups: %w", err)
+		}
 	}
+	return nil
+}
 
-	// Calculate offset
-	offset := (query.Page - 1) * query.Limit
+// EnsureSearchIndexes creates the trigram indexes backing free-text payment search.
+// pg_trgm may not be installable by the service user, in which case search still works
+// but falls back to scanning the user's payments.
+func (pr *PaymentRepository) EnsureSearchIndexes(ctx context.Context) error {
+	statements := []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+		"CREATE INDEX IF NOT EXISTS idx_payments_order_id_trgm ON payments USING gin (order_id gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_payments_notes_trgm ON payments USING gin (notes gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_payments_va_number_trgm ON payments USING gin (va_number gin_trgm_ops)",
+	}
+	for _, statement := range statements {
+		if err := pr.db.WithContext(ctx).Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to create payment search indexes: %w", err)
+		}
+	}
+	return nil
+}
 
-	// Get payments with pagination
-	if err := db.Order("created_at DESC").
-		Offset(offset).
-		Limit(query.Limit).
-		Find(&payments).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to get payments: %w", err)
+// NormalizeMidtransTimes corrects expiry_time and paid_at of payments stored before Midtrans
+// times were parsed as Asia/Jakarta: both are recomputed from the raw values kept in
+// midtrans_response. Rows already correct are left alone, so it is safe to run on every start.
+// Encrypted responses aren't JSON and are skipped, they were stored with corrected times.
+func (pr *PaymentRepository) NormalizeMidtransTimes(ctx context.Context) (int64, error) {
+	var fixed int64
+	for _, column := range []string{"expiry_time", "paid_at"} {
+		// CASE keeps non JSON values away from the cast whatever order the planner picks
+		raw := fmt.Sprintf("(CASE WHEN midtrans_response LIKE '{%%' THEN midtrans_response::jsonb ->> '%s' END)", column)
+		local := fmt.Sprintf("(replace(%s, 'T', ' ')::timestamp AT TIME ZONE 'Asia/Jakarta')", raw)
+
+		result := pr.db.WithContext(ctx).Exec(fmt.Sprintf(`UPDATE payments SET %[1]s = %[2]s
+			WHERE %[3]s ~ '^\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}:\d{2}$'
+			AND %[1]s IS DISTINCT FROM %[2]s`, column, local, raw))
+		if result.Error != nil {
+			return fixed, fmt.Errorf("failed to normalize %s: %w", column, result.Error)
+		}
+		fixed += result.RowsAffected
 	}
+	return fixed, nil
+}
 
-	return payments, total, nil
+// escapeLike escapes LIKE wildcards so search text is matched literally
+func escapeLike(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
 }
 
 // Update updates a payment
-func (pr *PaymentRepository) Update(payment *models.Payment) error {
-	if err := pr.db.Save(payment).Error; err != nil {
+func (pr *PaymentRepository) Update(ctx context.Context, payment *models.Payment) error {
+	restore, err := pr.sealMidtransResponse(payment)
+	defer restore()
+	if err != nil {
+		return err
+	}
+
+	if err := pr.db.WithContext(ctx).Save(payment).Error; err != nil {
 		return fmt.Errorf("failed to update payment: %w", err)
 	}
 	return nil
 }
 
-// UpdateStatus updates payment status
-func (pr *PaymentRepository) UpdateStatus(id uuid.UUID, status models.PaymentStatus) error {
+// UpdateStatus updates payment status, reporting whether the row changed. A payment already
+// in status is left alone, so when the Midtrans callback and a manual status check see the
+// same transition only one of them gets true and publishes its events. A payment only
+// becomes successful while no other attempt of its order has, otherwise ErrOrderAlreadyPaid
+// is returned.
+func (pr *PaymentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.PaymentStatus) (bool, error) {
 	updates := map[string]interface{}{
 		"status":     status,
 		"updated_at": time.Now(),
 	}
 
+	db := pr.db.WithContext(ctx).Model(&models.Payment{}).Where("id = ? AND status <> ?", id, status)
 	if status == models.PaymentStatusSuccess {
 		updates["paid_at"] = time.Now()
+		db = db.Where("NOT EXISTS (SELECT 1 FROM payments other WHERE other.order_ref = payments.order_ref AND other.id <> payments.id AND other.status = ?)", models.PaymentStatusSuccess)
 	}
 
-	if err := pr.db.Model(&models.Payment{}).Where("id = ?", id).Updates(updates).Error; err != nil {
-		return fmt.Errorf("failed to update payment status: %w", err)
+	result := db.Updates(updates)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update payment status: %w", result.Error)
 	}
-	return nil
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	if status == models.PaymentStatusSuccess {
+		// Nothing changed: either this payment was already successful or another attempt was
+		var count int64
+		if err := pr.db.WithContext(ctx).Model(&models.Payment{}).
+			Where("id = ? AND status = ?", id, status).
+			Count(&count).Error; err != nil {
+			return false, fmt.Errorf("failed to update payment status: %w", err)
+		}
+		if count == 0 {
+			return false, ErrOrderAlreadyPaid
+		}
+	}
+	return false, nil
+}
+
+// ClosePending moves a payment that is still pending to status (CANCELLED or EXPIRED),
+// reporting whether it was pending
+func (pr *PaymentRepository) ClosePending(ctx context.Context, id uuid.UUID, status models.PaymentStatus) (bool, error) {
+	result := pr.db.WithContext(ctx).Model(&models.Payment{}).
+		Where("id = ? AND status = ?", id, models.PaymentStatusPending).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to close pending payment: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
 }
 
-// UpdateMidtransData updates Midtrans-related fields
-func (pr *PaymentRepository) UpdateMidtransData(id uuid.UUID, midtransData map[string]interface{}) error {
-	fmt.Printf("🔍 UpdateMidtransData called with ID: %s, Data: %+v\n", id.String(), midtransData)
+// UpdateMidtransData updates Midtrans-related fields and returns the updated payment
+// (UPDATE ... RETURNING), so callers never need to read their own write back
+func (pr *PaymentRepository) UpdateMidtransData(ctx context.Context, id uuid.UUID, midtransData map[string]interface{}) (*models.Payment, error) {
+	fmt.Printf("🔍 UpdateMidtransData called with ID: %s, fields: %s\n", id.String(), strings.Join(mapKeys(midtransData), ", "))
 	
 	updates := map[string]interface{}{
 		"updated_at": time.Now(),
@@ -205,15 +463,15 @@ func (pr *PaymentRepository) UpdateMidtransData(id uuid.UUID, midtransData map[s
 	}
 	if paymentCode, ok := midtransData["payment_code"].(string); ok {
 		updates["payment_code"] = paymentCode
-		fmt.Printf("🔍 Storing Payment Code in DB: %s\n", paymentCode)
+		fmt.Printf("🔍 Storing Payment Code in DB: %s\n", redact.Tail(paymentCode))
 	} else {
-		fmt.Printf("⚠️ Payment Code not found or not a string: %v\n", midtransData["payment_code"])
+		fmt.Printf("⚠️ Payment Code not found or not a string: %T\n", midtransData["payment_code"])
 	}
 	if vaNumber, ok := midtransData["va_number"].(string); ok {
 		updates["va_number"] = vaNumber
-		fmt.Printf("🔍 Storing VA Number in DB: %s\n", vaNumber)
+		fmt.Printf("🔍 Storing VA Number in DB: %s\n", redact.Tail(vaNumber))
 	} else {
-		fmt.Printf("⚠️ VA Number not found or not a string: %v\n", midtransData["va_number"])
+		fmt.Printf("⚠️ VA Number not found or not a string: %T\n", midtransData["va_number"])
 	}
 	if bankType, ok := midtransData["bank_type"].(string); ok {
 		updates["bank_type"] = bankType
@@ -224,8 +482,16 @@ func (pr *PaymentRepository) UpdateMidtransData(id uuid.UUID, midtransData map[s
 	if paidAt, ok := midtransData["paid_at"].(time.Time); ok {
 		updates["paid_at"] = paidAt
 	}
-	if midtransResponse, ok := midtransData["midtrans_response"].(string); ok {
-		updates["midtrans_response"] = midtransResponse
+	plainResponse, hasResponse := midtransData["midtrans_response"].(string)
+	if hasResponse {
+		updates["midtrans_response"] = plainResponse
+		if pr.box != nil {
+			sealed, err := pr.box.Encrypt(plainResponse)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt Midtrans response: %w", err)
+			}
+			updates["midtrans_response"] = sealed
+		}
 	}
 	if midtransAction, ok := midtransData["midtrans_action"].(string); ok {
 		updates["midtrans_action"] = midtransAction
@@ -234,31 +500,114 @@ func (pr *PaymentRepository) UpdateMidtransData(id uuid.UUID, midtransData map[s
 		updates["snap_redirect_url"] = snapRedirectURL
 	}
 
-	fmt.Printf("🔍 Final updates to save: %+v\n", updates)
+	fmt.Printf("🔍 Final updates to save: %s\n", strings.Join(mapKeys(updates), ", "))
 	
-	if err := pr.db.Model(&models.Payment{}).Where("id = ?", id).Updates(updates).Error; err != nil {
-		fmt.Printf("❌ Failed to update Midtrans data: %v\n", err)
-		return fmt.Errorf("failed to update Midtrans data: %w", err)
+	payment := models.Payment{ID: id}
+	result := pr.db.WithContext(ctx).Model(&payment).Clauses(clause.Returning{}).Updates(updates)
+	if result.Error != nil {
+		fmt.Printf("❌ Failed to update Midtrans data: %v\n", result.Error)
+		return nil, fmt.Errorf("failed to update Midtrans data: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("payment not found")
 	}
 	
+	// RETURNING read back the stored ciphertext, callers get the response they passed in
+	if hasResponse {
+		payment.MidtransResponse = &plainResponse
+	}
+
 	fmt.Printf("✅ Successfully updated Midtrans data in database\n")
-	return nil
+	return &payment, nil
+}
+
+// EncryptMidtransResponses encrypts midtrans_response values stored in plaintext before
+// encryption was enabled, in batches. Run it after NormalizeMidtransTimes, which reads them.
+func (pr *PaymentRepository) EncryptMidtransResponses(ctx context.Context) (int64, error) {
+	if pr.box == nil {
+		return 0, nil
+	}
+
+	var encrypted int64
+	for {
+		var rows []struct {
+			ID               uuid.UUID
+			MidtransResponse string
+		}
+		err := pr.db.WithContext(ctx).Model(&models.Payment{}).
+			Select("id", "midtrans_response").
+			Where("midtrans_response IS NOT NULL AND midtrans_response <> '' AND midtrans_response NOT LIKE ?", secrets.Prefix+"%").
+			Limit(500).Find(&rows).Error
+		if err != nil {
+			return encrypted, fmt.Errorf("failed to load plaintext Midtrans responses: %w", err)
+		}
+		if len(rows) == 0 {
+			return encrypted, nil
+		}
+
+		err = pr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				sealed, err := pr.box.Encrypt(row.MidtransResponse)
+				if err != nil {
+					return fmt.Errorf("failed to encrypt Midtrans response of %s: %w", row.ID, err)
+				}
+				if err := tx.Model(&models.Payment{}).Where("id = ?", row.ID).UpdateColumn("midtrans_response", sealed).Error; err != nil {
+					return fmt.Errorf("failed to store encrypted Midtrans response of %s: %w", row.ID, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return encrypted, err
+		}
+		encrypted += int64(len(rows))
+	}
+}
+
+// mapKeys returns the sorted keys of m, to log which fields changed without their values
+func mapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // Delete deletes a payment
-func (pr *PaymentRepository) Delete(id uuid.UUID) error {
-	if err := pr.db.Delete(&models.Payment{}, "id = ?", id).Error; err != nil {
+func (pr *PaymentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := pr.db.WithContext(ctx).Delete(&models.Payment{}, "id = ?", id).Error; err != nil {
 		return fmt.Errorf("failed to delete payment: %w", err)
 	}
 	return nil
 }
 
+// PendingQuantity sums the units of a product (of its variant, when variantID isn't nil) in
+// pending payments that can still be paid: before their expiry time, or their due date for
+// invoices. Product-Service only takes stock once a payment succeeds, so these units are
+// spoken for.
+func (pr *PaymentRepository) PendingQuantity(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID) (int, error) {
+	now := time.Now()
+	query := pr.db.WithContext(ctx).Model(&models.Payment{}).
+		Where("product_id = ? AND status = ?", productID, models.PaymentStatusPending).
+		Where("expiry_time > ? OR due_date > ?", now, now)
+	if variantID != nil {
+		query = query.Where("variant_id = ?", *variantID)
+	}
+
+	var quantity int64
+	if err := query.Select("COALESCE(SUM(quantity), 0)").Scan(&quantity).Error; err != nil {
+		return 0, fmt.Errorf("failed to sum pending quantity: %w", err)
+	}
+	return int(quantity), nil
+}
+
 // GetPendingPayments retrieves pending payments older than specified duration
-func (pr *PaymentRepository) GetPendingPayments(olderThan time.Duration) ([]models.Payment, error) {
+func (pr *PaymentRepository) GetPendingPayments(ctx context.Context, olderThan time.Duration) ([]models.Payment, error) {
 	var payments []models.Payment
 	cutoffTime := time.Now().Add(-olderThan)
 
-	if err := pr.db.Where("status = ? AND created_at < ?", models.PaymentStatusPending, cutoffTime).
+	if err := pr.db.WithContext(ctx).Where("status = ? AND created_at < ?", models.PaymentStatusPending, cutoffTime).
 		Find(&payments).Error; err != nil {
 		return nil, fmt.Errorf("failed to get pending payments: %w", err)
 	}
@@ -266,12 +615,14 @@ func (pr *PaymentRepository) GetPendingPayments(olderThan time.Duration) ([]mode
 	return payments, nil
 }
 
-// GetExpiredPayments retrieves expired payments
-func (pr *PaymentRepository) GetExpiredPayments() ([]models.Payment, error) {
+// GetExpiredPayments retrieves up to limit pending payments past their expiry time, oldest first
+func (pr *PaymentRepository) GetExpiredPayments(ctx context.Context, limit int) ([]models.Payment, error) {
 	var payments []models.Payment
 	now := time.Now()
 
-	if err := pr.db.Where("status = ? AND expiry_time < ?", models.PaymentStatusPending, now).
+	if err := pr.db.WithContext(ctx).Where("status = ? AND expiry_time < ?", models.PaymentStatusPending, now).
+		Order("expiry_time ASC").
+		Limit(limit).
 		Find(&payments).Error; err != nil {
 		return nil, fmt.Errorf("failed to get expired payments: %w", err)
 	}
@@ -279,8 +630,102 @@ func (pr *PaymentRepository) GetExpiredPayments() ([]models.Payment, error) {
 	return payments, nil
 }
 
+// GetInvoicesToRemind retrieves up to limit pending invoices due before dueBefore that haven't
+// been reminded of yet, soonest due first
+func (pr *PaymentRepository) GetInvoicesToRemind(ctx context.Context, dueBefore time.Time, limit int) ([]models.Payment, error) {
+	var payments []models.Payment
+
+	if err := pr.db.WithContext(ctx).
+		Where("payment_method = ? AND status = ? AND invoice_reminded_at IS NULL AND due_date < ?",
+			models.PaymentMethodInvoice, models.PaymentStatusPending, dueBefore).
+		Order("due_date ASC").
+		Limit(limit).
+		Find(&payments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get invoices to remind: %w", err)
+	}
+
+	return payments, nil
+}
+
+// MarkInvoiceReminded records the due date reminder of an invoice, reporting whether this call
+// did, so concurrent schedulers send it once
+func (pr *PaymentRepository) MarkInvoiceReminded(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := pr.db.WithContext(ctx).Model(&models.Payment{}).
+		Where("id = ? AND invoice_reminded_at IS NULL", id).
+		Update("invoice_reminded_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark invoice reminded: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetOverdueInvoices retrieves up to limit pending invoices past their due date, oldest first
+func (pr *PaymentRepository) GetOverdueInvoices(ctx context.Context, limit int) ([]models.Payment, error) {
+	var payments []models.Payment
+
+	if err := pr.db.WithContext(ctx).
+		Where("payment_method = ? AND status = ? AND due_date < ?",
+			models.PaymentMethodInvoice, models.PaymentStatusPending, time.Now()).
+		Order("due_date ASC").
+		Limit(limit).
+		Find(&payments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get overdue invoices: %w", err)
+	}
+
+	return payments, nil
+}
+
+// MarkOverdue moves an invoice that is still pending to OVERDUE, reporting whether it was pending
+func (pr *PaymentRepository) MarkOverdue(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := pr.db.WithContext(ctx).Model(&models.Payment{}).
+		Where("id = ? AND payment_method = ? AND status = ?", id, models.PaymentMethodInvoice, models.PaymentStatusPending).
+		Updates(map[string]interface{}{
+			"status":     models.PaymentStatusOverdue,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark invoice overdue: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SettleInvoice records the manual settlement of a pending or overdue invoice, reporting
+// whether this call settled it. As with UpdateStatus, it fails with ErrOrderAlreadyPaid when
+// another attempt of the order already succeeded.
+func (pr *PaymentRepository) SettleInvoice(ctx context.Context, id uuid.UUID, reference string, paidAt time.Time) (bool, error) {
+	result := pr.db.WithContext(ctx).Model(&models.Payment{}).
+		Where("id = ? AND payment_method = ? AND status IN ?", id, models.PaymentMethodInvoice,
+			[]models.PaymentStatus{models.PaymentStatusPending, models.PaymentStatusOverdue}).
+		Where("NOT EXISTS (SELECT 1 FROM payments other WHERE other.order_ref = payments.order_ref AND other.id <> payments.id AND other.status = ?)", models.PaymentStatusSuccess).
+		Updates(map[string]interface{}{
+			"status":               models.PaymentStatusSuccess,
+			"paid_at":              paidAt,
+			"settlement_reference": reference,
+			"updated_at":           time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to settle invoice: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	// Nothing changed: either the invoice isn't open or another attempt was paid
+	var count int64
+	if err := pr.db.WithContext(ctx).Model(&models.Payment{}).
+		Where("id = ? AND payment_method = ? AND status IN ?", id, models.PaymentMethodInvoice,
+			[]models.PaymentStatus{models.PaymentStatusPending, models.PaymentStatusOverdue}).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to settle invoice: %w", err)
+	}
+	if count > 0 {
+		return false, ErrOrderAlreadyPaid
+	}
+	return false, nil
+}
+
 // GetPaymentStats retrieves payment statistics
-func (pr *PaymentRepository) GetPaymentStats() (map[string]interface{}, error) {
+func (pr *PaymentRepository) GetPaymentStats(ctx context.Context) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 
 	// Count payments by status
@@ -289,37 +734,80 @@ func (pr *PaymentRepository) GetPaymentStats() (map[string]interface{}, error) {
 		Count  int64  `json:"count"`
 	}
 
-	if err := pr.db.Model(&models.Payment{}).
-		Select("status, count(*) as count").
-		Group("status").
-		Scan(&statusCounts).Error; err != nil {
-		return nil, fmt.Errorf("failed to get status counts: %w", err)
-	}
-
-	stats["status_counts"] = statusCounts
-
 	// Total amount by status
 	var amountByStatus []struct {
 		Status string  `json:"status"`
 		Amount float64 `json:"amount"`
 	}
 
-	if err := pr.db.Model(&models.Payment{}).
-		Select("status, sum(total_amount) as amount").
-		Group("status").
-		Scan(&amountByStatus).Error; err != nil {
-		return nil, fmt.Errorf("failed to get amount by status: %w", err)
+	// Total payments count
+	var totalCount int64
+
+	err := pr.read(ctx, func(db *gorm.DB) error {
+		if err := db.Model(&models.Payment{}).
+			Select("status, count(*) as count").
+			Group("status").
+			Scan(&statusCounts).Error; err != nil {
+			return fmt.Errorf("failed to get status counts: %w", err)
+		}
+
+		if err := db.Model(&models.Payment{}).
+			Select("status, sum(total_amount) as amount").
+			Group("status").
+			Scan(&amountByStatus).Error; err != nil {
+			return fmt.Errorf("failed to get amount by status: %w", err)
+		}
+
+		if err := db.Model(&models.Payment{}).Count(&totalCount).Error; err != nil {
+			return fmt.Errorf("failed to get total count: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	stats["status_counts"] = statusCounts
 	stats["amount_by_status"] = amountByStatus
 
-	// Total payments count
-	var totalCount int64
-	if err := pr.db.Model(&models.Payment{}).Count(&totalCount).Error; err != nil {
-		return nil, fmt.Errorf("failed to get total count: %w", err)
+	stats["total_count"] = totalCount
+
+	// Outcomes per payment method over the last day
+	methods, err := pr.GetMethodStats(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
 	}
+	stats["methods_24h"] = methods
 
-	stats["total_count"] = totalCount
+	return stats, nil
+}
 
+// GetMethodStats counts the outcomes of the payments created since, per payment method and
+// bank, busiest first
+func (pr *PaymentRepository) GetMethodStats(ctx context.Context, since time.Time) ([]models.PaymentMethodStats, error) {
+	var stats []models.PaymentMethodStats
+	err := pr.read(ctx, func(db *gorm.DB) error {
+		return db.Model(&models.Payment{}).
+			Select(`payment_method, COALESCE(bank_type, '') AS bank_type, count(*) AS total,
+				count(*) FILTER (WHERE status IN ?) AS success,
+				count(*) FILTER (WHERE status = ?) AS failed,
+				count(*) FILTER (WHERE status = ?) AS expired,
+				count(*) FILTER (WHERE status = ?) AS cancelled,
+				count(*) FILTER (WHERE status = ?) AS pending`,
+				[]models.PaymentStatus{models.PaymentStatusSuccess, models.PaymentStatusRefunded},
+				models.PaymentStatusFailed, models.PaymentStatusExpired,
+				models.PaymentStatusCancelled, models.PaymentStatusPending).
+			Where("created_at >= ?", since).
+			Group("payment_method, COALESCE(bank_type, '')").
+			Order("total DESC, payment_method, bank_type").
+			Scan(&stats).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment method stats: %w", err)
+	}
+
+	for i := range stats {
+		stats[i].ComputeRates()
+	}
 	return stats, nil
 }
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// paymentDataTables are emptied by the sandbox reset: payments and everything recorded
+// about them. Webhook endpoints, store Midtrans credentials and the user read model are
+// configuration or owned elsewhere, and kept.
+var paymentDataTables = []string{
+	"payments",
+	"payments_archive",
+	"payment_links",
+	"midtrans_callbacks",
+	"webhook_deliveries",
+	"event_logs",
+}
+
+// ResetPaymentData truncates every payment, archived or not, and its links, callbacks, webhook deliveries and
+// logged events. For demo environments only.
+func (pr *PaymentRepository) ResetPaymentData(ctx context.Context) error {
+	if err := pr.db.WithContext(ctx).Exec("TRUNCATE TABLE " + strings.Join(paymentDataTables, ", ")).Error; err != nil {
+		return fmt.Errorf("failed to truncate payment data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"payment-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrStoreCredentialsNotFound is returned when a store uses the platform Midtrans keys
+var ErrStoreCredentialsNotFound = errors.New("store credentials not found")
+
+// StoreCredentialsRepository handles per-store Midtrans credential database operations
+type StoreCredentialsRepository struct {
+	db *gorm.DB
+}
+
+// NewStoreCredentialsRepository creates a new store credentials repository
+func NewStoreCredentialsRepository(db *gorm.DB) *StoreCredentialsRepository {
+	return &StoreCredentialsRepository{db: db}
+}
+
+// Get retrieves a store's credentials
+func (sr *StoreCredentialsRepository) Get(ctx context.Context, storeID uuid.UUID) (*models.StoreMidtransCredentials, error) {
+	var credentials models.StoreMidtransCredentials
+	if err := sr.db.WithContext(ctx).First(&credentials, "store_id = ?", storeID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrStoreCredentialsNotFound
+		}
+		return nil, fmt.Errorf("failed to get store credentials: %w", err)
+	}
+	return &credentials, nil
+}
+
+// Upsert creates or replaces a store's credentials
+func (sr *StoreCredentialsRepository) Upsert(ctx context.Context, credentials *models.StoreMidtransCredentials) error {
+	err := sr.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "store_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"owner_id", "server_key_encrypted", "server_key_hint", "client_key", "updated_at"}),
+	}).Create(credentials).Error
+	if err != nil {
+		return fmt.Errorf("failed to save store credentials: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a store's credentials so it falls back to the platform keys
+func (sr *StoreCredentialsRepository) Delete(ctx context.Context, storeID uuid.UUID) error {
+	result := sr.db.WithContext(ctx).Delete(&models.StoreMidtransCredentials{}, "store_id = ?", storeID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete store credentials: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrStoreCredentialsNotFound
+	}
+	return nil
+}
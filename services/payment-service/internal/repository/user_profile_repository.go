@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"payment-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserProfileRepository handles the user read model owned by User-Service
+type UserProfileRepository struct {
+	db *gorm.DB
+}
+
+// NewUserProfileRepository creates a new user profile repository
+func NewUserProfileRepository(db *gorm.DB) *UserProfileRepository {
+	return &UserProfileRepository{db: db}
+}
+
+// Upsert inserts or refreshes a user profile. The phone number is only overwritten when
+// the profile carries one, events that don't know it keep the stored number. Anonymized
+// profiles are left alone, so a late user.updated can't restore a deleted user's contact data.
+func (ur *UserProfileRepository) Upsert(profile *models.UserProfile) error {
+	profile.UpdatedAt = time.Now()
+	columns := []string{"username", "email", "updated_at"}
+	if profile.PhoneNumber != nil {
+		columns = append(columns, "phone_number")
+	}
+	err := ur.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_profiles.anonymized_at IS NULL"}}},
+	}).Create(profile).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert user profile: %w", err)
+	}
+	return nil
+}
+
+// Anonymize clears the contact data of a deleted user and marks the profile anonymized. The
+// profile is created when it wasn't known yet, so the user isn't fetched from User-Service
+// again. Payments keep referencing it by ID.
+func (ur *UserProfileRepository) Anonymize(id uuid.UUID) error {
+	now := time.Now()
+	profile := &models.UserProfile{
+		ID:           id,
+		Username:     models.AnonymizedUsername,
+		AnonymizedAt: &now,
+		UpdatedAt:    now,
+	}
+	err := ur.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"username", "email", "phone_number", "anonymized_at", "updated_at"}),
+	}).Create(profile).Error
+	if err != nil {
+		return fmt.Errorf("failed to anonymize user profile: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a user profile by user ID
+func (ur *UserProfileRepository) GetByID(id uuid.UUID) (*models.UserProfile, error) {
+	var profile models.UserProfile
+	if err := ur.db.First(&profile, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("user profile not found")
+		}
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+	return &profile, nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"payment-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookRepository handles webhook endpoint and delivery database operations
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// CreateEndpoint creates a new merchant webhook endpoint
+func (wr *WebhookRepository) CreateEndpoint(endpoint *models.WebhookEndpoint) error {
+	if err := wr.db.Create(endpoint).Error; err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// GetEndpointByID retrieves a webhook endpoint by ID
+func (wr *WebhookRepository) GetEndpointByID(id uuid.UUID) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := wr.db.First(&endpoint, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("webhook endpoint not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return &endpoint, nil
+}
+
+// ListEndpoints returns every webhook endpoint, newest first
+func (wr *WebhookRepository) ListEndpoints() ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	if err := wr.db.Order("created_at DESC").Find(&endpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	return endpoints, nil
+}
+
+// GetActiveEndpoints returns every active endpoint
+func (wr *WebhookRepository) GetActiveEndpoints() ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	if err := wr.db.Where("is_active = ?", true).Find(&endpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to get active webhook endpoints: %w", err)
+	}
+	return endpoints, nil
+}
+
+// UpdateEndpoint saves changes to a webhook endpoint
+func (wr *WebhookRepository) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	if err := wr.db.Save(endpoint).Error; err != nil {
+		return fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// DeleteEndpoint removes a webhook endpoint together with its delivery history
+func (wr *WebhookRepository) DeleteEndpoint(id uuid.UUID) error {
+	return wr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("endpoint_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+		}
+		result := tx.Delete(&models.WebhookEndpoint{}, "id = ?", id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete webhook endpoint: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("webhook endpoint not found")
+		}
+		return nil
+	})
+}
+
+// CreateDelivery creates a new webhook delivery
+func (wr *WebhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	if err := wr.db.Create(delivery).Error; err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// GetDeliveryByID retrieves a webhook delivery by ID
+func (wr *WebhookRepository) GetDeliveryByID(id uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := wr.db.First(&delivery, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("webhook delivery not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+// GetDeliveriesByEndpoint retrieves deliveries for an endpoint with pagination, optionally filtered by status
+func (wr *WebhookRepository) GetDeliveriesByEndpoint(endpointID uuid.UUID, status string, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := wr.db.Model(&models.WebhookDelivery{}).Where("endpoint_id = ?", endpointID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+
+	return deliveries, total, nil
+}
+
+// GetDueDeliveries returns pending deliveries whose next attempt is due
+func (wr *WebhookRepository) GetDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := wr.db.
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery saves the outcome of a delivery attempt
+func (wr *WebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	if err := wr.db.Save(delivery).Error; err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package retry defines the retry and timeout policy applied to calls leaving the service
+// (Midtrans, User-Service, Product-Service). Policies are read from the environment so
+// they can be tuned per deployment without recompiling.
+package retry
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Policy describes how often an operation is attempted, how long to wait between
+// attempts and how long a single attempt may take
+type Policy struct {
+	Name        string        `json:"name"`
+	MaxAttempts int           `json:"max_attempts"` // including the first attempt
+	BaseDelay   time.Duration `json:"base_delay"`   // delay before the first retry
+	MaxDelay    time.Duration `json:"max_delay"`    // upper bound for a single delay
+	Multiplier  float64       `json:"multiplier"`   // 1 for a constant delay, 2 for exponential
+	Timeout     time.Duration `json:"timeout"`      // per attempt, 0 for none
+}
+
+// FromEnv overrides defaults with <PREFIX>_MAX_ATTEMPTS, <PREFIX>_BASE_DELAY,
+// <PREFIX>_MAX_DELAY, <PREFIX>_BACKOFF_MULTIPLIER and <PREFIX>_TIMEOUT.
+// Invalid values are ignored with a warning.
+func FromEnv(prefix string, defaults Policy) Policy {
+	policy := defaults
+	policy.Name = prefix
+
+	if value, ok := lookup(prefix + "_MAX_ATTEMPTS"); ok {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			policy.MaxAttempts = parsed
+		} else {
+			warnInvalid(prefix+"_MAX_ATTEMPTS", value)
+		}
+	}
+	if value, ok := lookup(prefix + "_BASE_DELAY"); ok {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			policy.BaseDelay = parsed
+		} else {
+			warnInvalid(prefix+"_BASE_DELAY", value)
+		}
+	}
+	if value, ok := lookup(prefix + "_MAX_DELAY"); ok {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			policy.MaxDelay = parsed
+		} else {
+			warnInvalid(prefix+"_MAX_DELAY", value)
+		}
+	}
+	if value, ok := lookup(prefix + "_BACKOFF_MULTIPLIER"); ok {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed >= 1 {
+			policy.Multiplier = parsed
+		} else {
+			warnInvalid(prefix+"_BACKOFF_MULTIPLIER", value)
+		}
+	}
+	if value, ok := lookup(prefix + "_TIMEOUT"); ok {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			policy.Timeout = parsed
+		} else {
+			warnInvalid(prefix+"_TIMEOUT", value)
+		}
+	}
+
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 1
+	}
+	return policy
+}
+
+// Retries returns the number of retries after the first attempt
+func (p Policy) Retries() int {
+	return p.MaxAttempts - 1
+}
+
+// Delay returns how long to wait after the given failed attempt (0 based)
+func (p Policy) Delay(attempt int) time.Duration {
+	delay := time.Duration(float64(p.BaseDelay) * math.Pow(p.Multiplier, float64(attempt)))
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// String summarises the policy for startup logs
+func (p Policy) String() string {
+	return fmt.Sprintf("%s: %d attempts, delay %s x%.1f (max %s), timeout %s",
+		p.Name, p.MaxAttempts, p.BaseDelay, p.Multiplier, p.MaxDelay, p.Timeout)
+}
+
+func lookup(key string) (string, bool) {
+	value := os.Getenv(key)
+	return value, value != ""
+}
+
+func warnInvalid(key, value string) {
+	fmt.Printf("⚠️ Ignoring invalid %s=%q, using default\n", key, value)
+}
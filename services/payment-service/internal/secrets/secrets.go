@@ -0,0 +1,118 @@
+// Package secrets encrypts sensitive values stored in the database (per-store Midtrans
+// server keys, raw Midtrans responses) with AES-256-GCM, and masks secrets for logging.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Prefix starts every ciphertext and versions its format, so the scheme can change
+// without a migration
+const Prefix = "v1:"
+
+// ErrMalformed is returned when decrypting a value that isn't a ciphertext of this box
+var ErrMalformed = errors.New("malformed encrypted value")
+
+// Box encrypts and decrypts values with a single key
+type Box struct {
+	aead cipher.AEAD
+}
+
+// NewBox creates a box from a 32 byte AES-256 key
+func NewBox(key []byte) (*Box, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return &Box{aead: aead}, nil
+}
+
+// FromEnv creates a box from the data encryption key: base64 of 32 random bytes (e.g.
+// `openssl rand -base64 32`) in DATA_ENCRYPTION_KEY, or in the file named by
+// DATA_ENCRYPTION_KEY_FILE as mounted by a KMS or secret manager. It returns nil without
+// an error when neither is set.
+func FromEnv() (*Box, error) {
+	source := "DATA_ENCRYPTION_KEY"
+	value := os.Getenv(source)
+	if path := os.Getenv("DATA_ENCRYPTION_KEY_FILE"); path != "" {
+		source = "DATA_ENCRYPTION_KEY_FILE"
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		value = string(data)
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", source, err)
+	}
+	box, err := NewBox(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", source, err)
+	}
+	return box, nil
+}
+
+// Encrypt returns the base64 ciphertext of plaintext with a random nonce
+func (b *Box) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return Prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// IsEncrypted reports whether value looks like an Encrypt result
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Decrypt reverses Encrypt. It fails when the value was encrypted with another key.
+func (b *Box) Decrypt(ciphertext string) (string, error) {
+	encoded, ok := strings.CutPrefix(ciphertext, Prefix)
+	if !ok {
+		return "", ErrMalformed
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < b.aead.NonceSize() {
+		return "", ErrMalformed
+	}
+
+	nonce, sealed := sealed[:b.aead.NonceSize()], sealed[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// Mask hides all but the last four characters of a secret for logging
+func Mask(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", 8) + secret[len(secret)-4:]
+}
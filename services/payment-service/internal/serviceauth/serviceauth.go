@@ -0,0 +1,182 @@
+// Package serviceauth authenticates calls between the services. The caller (the gateway or
+// another service) sends a short lived JWT in the X-Service-Token header, signed with the
+// shared SERVICE_AUTH_SECRET, naming itself as issuer and the called service as audience, so
+// a token minted for one service is refused by the others.
+package serviceauth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Header carries the service token, leaving Authorization to the end user's token
+const Header = "X-Service-Token"
+
+// Names of the gateway and services, used as issuer and audience
+const (
+	Gateway        = "api-gateway"
+	UserService    = "user-service"
+	ProductService = "product-service"
+	PaymentService = "payment-service"
+)
+
+// minSecretLength rejects secrets too short for HMAC-SHA256
+const minSecretLength = 32
+
+// ErrMissingToken is returned when a request carries no service token
+var ErrMissingToken = errors.New("service token required")
+
+// IssuerFromEnv creates the issuer for service from SERVICE_AUTH_SECRET and
+// SERVICE_AUTH_TOKEN_TTL (default 5m). It returns nil when no secret is set.
+func IssuerFromEnv(service string) (*Issuer, error) {
+	secret, err := secretFromEnv()
+	if err != nil || secret == nil {
+		return nil, err
+	}
+
+	ttl := 5 * time.Minute
+	if value := os.Getenv("SERVICE_AUTH_TOKEN_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 10*time.Second {
+			return nil, fmt.Errorf("invalid SERVICE_AUTH_TOKEN_TTL %q, expected a duration of at least 10s", value)
+		}
+		ttl = parsed
+	}
+	return NewIssuer(service, secret, ttl), nil
+}
+
+// VerifierFromEnv creates the verifier for audience from SERVICE_AUTH_SECRET. It returns nil
+// when no secret is set.
+func VerifierFromEnv(audience string) (*Verifier, error) {
+	secret, err := secretFromEnv()
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	return NewVerifier(audience, secret), nil
+}
+
+// secretFromEnv reads SERVICE_AUTH_SECRET, nil when unset
+func secretFromEnv() ([]byte, error) {
+	secret := os.Getenv("SERVICE_AUTH_SECRET")
+	if secret == "" {
+		return nil, nil
+	}
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("SERVICE_AUTH_SECRET must be at least %d characters", minSecretLength)
+	}
+	return []byte(secret), nil
+}
+
+// Issuer mints tokens for the calls of one service, reusing each audience's token until
+// half its lifetime has passed
+type Issuer struct {
+	service string
+	secret  []byte
+	ttl     time.Duration
+
+	mu     sync.Mutex
+	tokens map[string]cachedToken
+}
+
+type cachedToken struct {
+	value   string
+	renewAt time.Time
+}
+
+// NewIssuer creates an issuer of tokens valid for ttl, naming service as the caller
+func NewIssuer(service string, secret []byte, ttl time.Duration) *Issuer {
+	return &Issuer{
+		service: service,
+		secret:  secret,
+		ttl:     ttl,
+		tokens:  make(map[string]cachedToken),
+	}
+}
+
+// Token returns a token for calling audience
+func (i *Issuer) Token(audience string) (string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	now := time.Now()
+	if cached, ok := i.tokens[audience]; ok && now.Before(cached.renewAt) {
+		return cached.value, nil
+	}
+
+	claims := jwt.RegisteredClaims{
+		Issuer:    i.service,
+		Subject:   i.service,
+		Audience:  jwt.ClaimStrings{audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now.Add(-5 * time.Second)), // Tolerate clock skew
+		ExpiresAt: jwt.NewNumericDate(now.Add(i.ttl)),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign service token: %w", err)
+	}
+
+	i.tokens[audience] = cachedToken{value: token, renewAt: now.Add(i.ttl / 2)}
+	return token, nil
+}
+
+// Sign sets the service token for audience on req
+func (i *Issuer) Sign(req *http.Request, audience string) error {
+	token, err := i.Token(audience)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(Header, token)
+	return nil
+}
+
+// Verifier checks the tokens of calls to one service
+type Verifier struct {
+	audience string
+	secret   []byte
+	parser   *jwt.Parser
+}
+
+// NewVerifier creates a verifier accepting tokens minted for audience
+func NewVerifier(audience string, secret []byte) *Verifier {
+	return &Verifier{
+		audience: audience,
+		secret:   secret,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+			jwt.WithAudience(audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+			jwt.WithLeeway(5*time.Second),
+		),
+	}
+}
+
+// Audience returns the service the verifier accepts tokens for
+func (v *Verifier) Audience() string {
+	return v.audience
+}
+
+// Verify checks token and returns the calling service
+func (v *Verifier) Verify(token string) (string, error) {
+	if token == "" {
+		return "", ErrMissingToken
+	}
+
+	var claims jwt.RegisteredClaims
+	if _, err := v.parser.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return v.secret, nil
+	}); err != nil {
+		return "", fmt.Errorf("invalid service token: %w", err)
+	}
+	if claims.Issuer == "" {
+		return "", errors.New("invalid service token: missing issuer")
+	}
+	return claims.Issuer, nil
+}
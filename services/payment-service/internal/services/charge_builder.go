@@ -0,0 +1,293 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"payment-service/internal/models"
+	"payment-service/internal/money"
+)
+
+// ChargeConfig configures the lines added to a charge on top of the product price.
+// Rates are in basis points (1100 = 11%).
+type ChargeConfig struct {
+	AdminFee      *int64 // fixed admin fee replacing the one in the request, nil keeps the request's
+	DiscountRate  int64  // discount off the product price
+	TaxName       string
+	TaxRate       int64 // tax on the discounted price
+	TaxOnAdminFee bool  // also tax the admin fee
+	RoundTo       int64 // round the total to the nearest multiple, 0 or 1 disables rounding
+}
+
+// ChargeBreakdown is every amount making up a payment total. Discount is subtracted,
+// Rounding may be negative.
+type ChargeBreakdown struct {
+	Amount   money.Money
+	AdminFee money.Money
+	Discount money.Money
+	Tax      money.Money
+	Rounding money.Money
+	Total    money.Money
+}
+
+// ChargeBuilder computes payment totals from configuration and assembles the Midtrans
+// item_details for them, so gross_amount always equals the sum of the items
+type ChargeBuilder struct {
+	config ChargeConfig
+}
+
+// NewChargeBuilder creates a charge builder
+func NewChargeBuilder(config ChargeConfig) *ChargeBuilder {
+	if config.TaxName == "" {
+		config.TaxName = "Tax"
+	}
+	return &ChargeBuilder{config: config}
+}
+
+// NewChargeBuilderFromEnv reads CHARGE_ADMIN_FEE, CHARGE_DISCOUNT_PERCENT, CHARGE_TAX_NAME,
+// CHARGE_TAX_PERCENT, CHARGE_TAX_ON_ADMIN_FEE and CHARGE_ROUND_TO. Without them charges are
+// the product price plus the requested admin fee, as before.
+func NewChargeBuilderFromEnv() (*ChargeBuilder, error) {
+	var config ChargeConfig
+	var problems []error
+
+	if value := os.Getenv("CHARGE_ADMIN_FEE"); value != "" {
+		fee, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || fee < 0 {
+			problems = append(problems, fmt.Errorf("CHARGE_ADMIN_FEE must be a non-negative whole amount, got %q", value))
+		} else {
+			config.AdminFee = &fee
+		}
+	}
+
+	var err error
+	if config.DiscountRate, err = percentFromEnv("CHARGE_DISCOUNT_PERCENT"); err != nil {
+		problems = append(problems, err)
+	}
+	if config.TaxRate, err = percentFromEnv("CHARGE_TAX_PERCENT"); err != nil {
+		problems = append(problems, err)
+	}
+	config.TaxName = os.Getenv("CHARGE_TAX_NAME")
+	config.TaxOnAdminFee = os.Getenv("CHARGE_TAX_ON_ADMIN_FEE") == "true"
+
+	if value := os.Getenv("CHARGE_ROUND_TO"); value != "" {
+		roundTo, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || roundTo < 0 {
+			problems = append(problems, fmt.Errorf("CHARGE_ROUND_TO must be a non-negative whole amount, got %q", value))
+		} else {
+			config.RoundTo = roundTo
+		}
+	}
+
+	builder := NewChargeBuilder(config)
+	fmt.Printf("🔧 Charge lines: %s\n", builder)
+	return builder, errors.Join(problems...)
+}
+
+// percentFromEnv parses a percentage such as "11" or "2.5" into basis points
+func percentFromEnv(key string) (int64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+	percent, err := strconv.ParseFloat(value, 64)
+	if err != nil || percent < 0 || percent > 100 {
+		return 0, fmt.Errorf("%s must be a percentage between 0 and 100, got %q", key, value)
+	}
+	return int64(math.Round(percent * 100)), nil
+}
+
+// String describes the configuration for startup logs
+func (b *ChargeBuilder) String() string {
+	adminFee := "from request"
+	if b.config.AdminFee != nil {
+		adminFee = strconv.FormatInt(*b.config.AdminFee, 10)
+	}
+	return fmt.Sprintf("admin_fee=%s discount=%s %s=%s (on admin fee: %t) round_to=%d",
+		adminFee, formatRate(b.config.DiscountRate), strings.ToLower(b.config.TaxName),
+		formatRate(b.config.TaxRate), b.config.TaxOnAdminFee, b.config.RoundTo)
+}
+
+// Build computes the breakdown of a charge for the product amount and the admin fee the
+// client asked for
+func (b *ChargeBuilder) Build(amount, requestedAdminFee money.Money) (ChargeBreakdown, error) {
+	currency := amount.Currency
+	adminFee := requestedAdminFee
+	if b.config.AdminFee != nil {
+		adminFee = money.New(*b.config.AdminFee, currency)
+	}
+
+	discount, err := applyRate(amount, b.config.DiscountRate)
+	if err != nil {
+		return ChargeBreakdown{}, err
+	}
+	discounted, err := amount.Add(negate(discount))
+	if err != nil {
+		return ChargeBreakdown{}, err
+	}
+
+	taxBase := discounted
+	if b.config.TaxOnAdminFee {
+		if taxBase, err = taxBase.Add(adminFee); err != nil {
+			return ChargeBreakdown{}, err
+		}
+	}
+	tax, err := applyRate(taxBase, b.config.TaxRate)
+	if err != nil {
+		return ChargeBreakdown{}, err
+	}
+
+	total, err := sum(discounted, adminFee, tax)
+	if err != nil {
+		return ChargeBreakdown{}, err
+	}
+
+	rounding := money.New(0, currency)
+	if b.config.RoundTo > 1 {
+		rounded := (total.Minor + b.config.RoundTo/2) / b.config.RoundTo * b.config.RoundTo
+		rounding = money.New(rounded-total.Minor, currency)
+		total = money.New(rounded, currency)
+	}
+
+	if !total.IsPositive() {
+		return ChargeBreakdown{}, fmt.Errorf("charge total must be positive, got %s", total)
+	}
+
+	return ChargeBreakdown{
+		Amount:   amount,
+		AdminFee: adminFee,
+		Discount: discount,
+		Tax:      tax,
+		Rounding: rounding,
+		Total:    total,
+	}, nil
+}
+
+// Items assembles the Midtrans item_details of a payment from its stored breakdown and
+// checks they add up to the gross amount, Midtrans rejects charges where they don't. The
+// product line carries the quantity at the unit price; when a pricing rule's total for the
+// quantity isn't a whole multiple of it, the remainder is its own line.
+func (b *ChargeBuilder) Items(payment *models.Payment, product *models.Product) ([]ItemDetails, error) {
+	quantity := payment.ItemQuantity()
+	unitPrice := payment.Amount / int64(quantity)
+	items := []ItemDetails{
+		{
+			ID:       product.ID.String(),
+			Price:    unitPrice, // Amount in rupiah (Midtrans expects rupiah, not cents)
+			Quantity: quantity,
+			Name:     product.Name,
+			Category: "product",
+		},
+	}
+
+	if remainder := payment.Amount - unitPrice*int64(quantity); remainder != 0 {
+		items = append(items, ItemDetails{
+			ID:       "price_adjustment",
+			Price:    remainder,
+			Quantity: 1,
+			Name:     "Price Adjustment",
+			Category: "adjustment",
+		})
+	}
+
+	if payment.DiscountAmount > 0 {
+		items = append(items, ItemDetails{
+			ID:       "discount",
+			Price:    -payment.DiscountAmount,
+			Quantity: 1,
+			Name:     "Discount " + formatRate(b.config.DiscountRate),
+			Category: "discount",
+		})
+	}
+
+	if payment.AdminFee > 0 {
+		items = append(items, ItemDetails{
+			ID:       "admin_fee",
+			Price:    payment.AdminFee,
+			Quantity: 1,
+			Name:     "Admin Fee",
+			Category: "fee",
+		})
+	}
+
+	if payment.TaxAmount > 0 {
+		items = append(items, ItemDetails{
+			ID:       "tax",
+			Price:    payment.TaxAmount,
+			Quantity: 1,
+			Name:     b.config.TaxName + " " + formatRate(b.config.TaxRate),
+			Category: "tax",
+		})
+	}
+
+	if payment.RoundingAmount != 0 {
+		items = append(items, ItemDetails{
+			ID:       "rounding",
+			Price:    payment.RoundingAmount,
+			Quantity: 1,
+			Name:     "Rounding",
+			Category: "adjustment",
+		})
+	}
+
+	if err := validateItems(items, payment.TotalAmount); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// validateItems checks that the item lines add up to the gross amount
+func validateItems(items []ItemDetails, grossAmount int64) error {
+	var total int64
+	for _, item := range items {
+		line := item.Price * int64(item.Quantity)
+		if item.Quantity != 0 && line/int64(item.Quantity) != item.Price {
+			return fmt.Errorf("item %s overflows", item.ID)
+		}
+		if (line > 0 && total > math.MaxInt64-line) || (line < 0 && total < math.MinInt64-line) {
+			return fmt.Errorf("item total overflows")
+		}
+		total += line
+	}
+	if total != grossAmount {
+		return fmt.Errorf("item_details add up to %d but gross_amount is %d", total, grossAmount)
+	}
+	return nil
+}
+
+// applyRate returns rate basis points of amount, rounded half up
+func applyRate(amount money.Money, rate int64) (money.Money, error) {
+	if rate == 0 || amount.Minor == 0 {
+		return money.New(0, amount.Currency), nil
+	}
+	if amount.Minor > math.MaxInt64/rate {
+		return money.Money{}, fmt.Errorf("%w: %s", money.ErrOverflow, amount)
+	}
+	return money.New((amount.Minor*rate+5000)/10000, amount.Currency), nil
+}
+
+// negate returns the amount with the opposite sign
+func negate(amount money.Money) money.Money {
+	return money.New(-amount.Minor, amount.Currency)
+}
+
+// sum adds amounts of the same currency
+func sum(first money.Money, rest ...money.Money) (money.Money, error) {
+	total := first
+	for _, amount := range rest {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// formatRate formats basis points as a percentage, e.g. 1100 as "11%" and 250 as "2.5%"
+func formatRate(rate int64) string {
+	return strconv.FormatFloat(float64(rate)/100, 'f', -1, 64) + "%"
+}
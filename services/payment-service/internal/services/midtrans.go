@@ -2,18 +2,30 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha512"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"payment-service/internal/httpclient"
 	"payment-service/internal/models"
+	"payment-service/internal/redact"
+	"payment-service/internal/retry"
+	"payment-service/internal/secrets"
+)
+
+// Shared sandbox keys used when none are configured, only good for local development
+const (
+	defaultSandboxServerKey = "SB-Mid-server-4zIt7djwCeRdMpgF4gXDjciC"
+	defaultSandboxClientKey = "SB-Mid-client-4zIt7djwCeRdMpgF4gXDjciC"
 )
 
 // MidtransService handles Midtrans payment operations
@@ -21,9 +33,14 @@ type MidtransService struct {
 	serverKey      string
 	clientKey      string
 	baseURL        string
-	httpClient     *http.Client
+	chargeClient   *http.Client
+	statusClient   *http.Client
+	chargePolicy   retry.Policy
+	statusPolicy   retry.Policy
 	environment    string
 	authHeader     string // Cached authorization header
+	limiter        *MidtransLimiter
+	charges        *ChargeBuilder // assembles item_details, see SetChargeBuilder
 }
 
 // MidtransChargeRequest represents the charge request to Midtrans
@@ -159,6 +176,33 @@ type MidtransStatusResponse struct {
 	PaidAt            string                 `json:"paid_at,omitempty"`
 }
 
+// Charger creates charges on the payment gateway
+type Charger interface {
+	CreatePayment(payment *models.Payment, user *models.UserProfile, product *models.Product) (*MidtransChargeResponse, error)
+}
+
+// StatusFetcher fetches transaction status from the payment gateway
+type StatusFetcher interface {
+	GetPaymentStatus(orderID string) (*MidtransStatusResponse, error)
+}
+
+// PaymentGateway is everything the payment handler needs from Midtrans
+type PaymentGateway interface {
+	Charger
+	StatusFetcher
+	CancelPayment(orderID string) error
+	ExpirePayment(orderID string) error
+	VerifySignature(orderID, statusCode, grossAmount, signatureKey string) bool
+	SignCallback(orderID, statusCode, grossAmount string) string
+	MapMidtransStatusToPaymentStatus(midtransStatus string) models.PaymentStatus
+	GetClientKey() string
+	GetEnvironment() string
+	WithCredentials(serverKey, clientKey string) PaymentGateway
+}
+
+// Ensure MidtransService implements PaymentGateway
+var _ PaymentGateway = (*MidtransService)(nil)
+
 // NewMidtransService creates a new Midtrans service
 func NewMidtransService() *MidtransService {
 	environment := os.Getenv("MIDTRANS_ENVIRONMENT")
@@ -180,45 +224,88 @@ func NewMidtransService() *MidtransService {
 		clientKey = os.Getenv("MIDTRANS_CLIENT_KEY")
 	}
 
+	// Override for load tests against a local Midtrans mock (cmd/midtrans-mock)
+	if override := os.Getenv("MIDTRANS_BASE_URL"); override != "" {
+		baseURL = strings.TrimSuffix(override, "/")
+	}
+
 	// Default sandbox keys if not provided
 	if serverKey == "" {
-		serverKey = "SB-Mid-server-4zIt7djwCeRdMpgF4gXDjciC"
+		serverKey = defaultSandboxServerKey
 	}
 	if clientKey == "" {
-		clientKey = "SB-Mid-client-4zIt7djwCeRdMpgF4gXDjciC"
+		clientKey = defaultSandboxClientKey
 	}
 
 	// Log configuration for debugging
 	fmt.Printf("🔧 Midtrans Config - Environment: %s, BaseURL: %s\n", environment, baseURL)
-	fmt.Printf("🔧 Server Key: %s...\n", serverKey[:20])
-
-	// Create optimized HTTP client with connection pooling
-	transport := &http.Transport{
-		MaxIdleConns:        100,
-		MaxIdleConnsPerHost: 10,
-		IdleConnTimeout:     90 * time.Second,
-		DisableKeepAlives:   false,
-		DisableCompression:  false,
-	}
+	fmt.Printf("🔧 Server Key: %s\n", secrets.Mask(serverKey))
 
 	// Pre-compute authorization header for better performance
 	authHeader := "Basic " + base64.StdEncoding.EncodeToString([]byte(serverKey+":"))
 
+	// Retry and timeout policies (MIDTRANS_CHARGE_* and MIDTRANS_STATUS_*)
+	defaults := retry.Policy{
+		MaxAttempts: 4,
+		BaseDelay:   1 * time.Second,
+		MaxDelay:    10 * time.Second,
+		Multiplier:  2,
+		Timeout:     60 * time.Second,
+	}
+	chargePolicy := retry.FromEnv("MIDTRANS_CHARGE", defaults)
+	statusPolicy := retry.FromEnv("MIDTRANS_STATUS", defaults)
+	fmt.Printf("🔧 Retry policy %s\n", chargePolicy)
+	fmt.Printf("🔧 Retry policy %s\n", statusPolicy)
+
 	return &MidtransService{
-		serverKey:   serverKey,
-		clientKey:   clientKey,
-		baseURL:     baseURL,
-		environment: environment,
-		authHeader:  authHeader,
-		httpClient: &http.Client{
-			Timeout:   60 * time.Second, // Increased timeout
-			Transport: transport,
-		},
+		serverKey:    serverKey,
+		clientKey:    clientKey,
+		baseURL:      baseURL,
+		environment:  environment,
+		authHeader:   authHeader,
+		limiter:      NewMidtransLimiter(),
+		charges:      NewChargeBuilder(ChargeConfig{}),
+		chargePolicy: chargePolicy,
+		statusPolicy: statusPolicy,
+		chargeClient: httpclient.New("midtrans_charge", chargePolicy).Client,
+		statusClient: httpclient.New("midtrans_status", statusPolicy).Client,
 	}
 }
 
+// Validate reports key configuration that must never serve real payments: the shared
+// sandbox keys in production (APP_ENV or MIDTRANS_ENVIRONMENT), or sandbox keys against
+// the production Midtrans API
+func (ms *MidtransService) Validate(production bool) error {
+	production = production || ms.environment == "production"
+
+	var problems []error
+	if production && (ms.serverKey == defaultSandboxServerKey || ms.clientKey == defaultSandboxClientKey) {
+		problems = append(problems, errors.New("Midtrans keys are the shared sandbox defaults, set MIDTRANS_SERVER_KEY_PROD and MIDTRANS_CLIENT_KEY_PROD"))
+	}
+	if ms.environment == "production" && (strings.HasPrefix(ms.serverKey, "SB-") || strings.HasPrefix(ms.clientKey, "SB-")) {
+		problems = append(problems, errors.New("MIDTRANS_ENVIRONMENT is production but the configured keys are sandbox keys"))
+	}
+	return errors.Join(problems...)
+}
+
+// SetChargeBuilder sets the builder assembling item_details, it must match the one
+// computing payment totals
+func (ms *MidtransService) SetChargeBuilder(charges *ChargeBuilder) {
+	ms.charges = charges
+}
+
+// WithCredentials returns a copy of the service charging with a store's own Midtrans keys.
+// The copy shares the HTTP clients, retry policies and rate limiter with the platform service.
+func (ms *MidtransService) WithCredentials(serverKey, clientKey string) PaymentGateway {
+	store := *ms
+	store.serverKey = serverKey
+	store.clientKey = clientKey
+	store.authHeader = "Basic " + base64.StdEncoding.EncodeToString([]byte(serverKey+":"))
+	return &store
+}
+
 // CreatePayment creates a payment using Midtrans
-func (ms *MidtransService) CreatePayment(payment *models.Payment, user *models.User, product *models.Product) (*MidtransChargeResponse, error) {
+func (ms *MidtransService) CreatePayment(payment *models.Payment, user *models.UserProfile, product *models.Product) (*MidtransChargeResponse, error) {
 	// Map payment method to Midtrans payment type
 	paymentType := string(payment.PaymentMethod)
 	
@@ -236,27 +323,17 @@ func (ms *MidtransService) CreatePayment(payment *models.Payment, user *models.U
 			FirstName: user.Username,
 			Email:     user.Email,
 		},
-		ItemDetails: []ItemDetails{
-			{
-				ID:       product.ID.String(),
-				Price:    payment.Amount, // Amount in rupiah (Midtrans expects rupiah, not cents)
-				Quantity: 1,
-				Name:     product.Name,
-				Category: "product",
-			},
-		},
+	}
+	if user.PhoneNumber != nil {
+		chargeReq.CustomerDetails.Phone = *user.PhoneNumber
 	}
 
-	// Add admin fee if exists
-	if payment.AdminFee > 0 {
-		chargeReq.ItemDetails = append(chargeReq.ItemDetails, ItemDetails{
-			ID:       "admin_fee",
-			Price:    payment.AdminFee, // Admin fee in rupiah (Midtrans expects rupiah, not cents)
-			Quantity: 1,
-			Name:     "Admin Fee",
-			Category: "fee",
-		})
+	// Product, discount, admin fee, tax and rounding lines, checked against gross_amount
+	items, err := ms.charges.Items(payment, product)
+	if err != nil {
+		return nil, fmt.Errorf("invalid charge for order %s: %w", payment.OrderID, err)
 	}
+	chargeReq.ItemDetails = items
 
 	// Add payment method specific details
 	switch payment.PaymentMethod {
@@ -336,9 +413,8 @@ func (ms *MidtransService) CreatePayment(payment *models.Payment, user *models.U
 func (ms *MidtransService) GetPaymentStatus(orderID string) (*MidtransStatusResponse, error) {
 	url := fmt.Sprintf("%s/%s/status", ms.baseURL, orderID)
 
-	// Retry mechanism with exponential backoff
-	maxRetries := 3
-	baseDelay := 1 * time.Second
+	// Retry mechanism with backoff (MIDTRANS_STATUS_* policy)
+	maxRetries := ms.statusPolicy.Retries()
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		req, err := http.NewRequest("GET", url, nil)
@@ -352,14 +428,21 @@ func (ms *MidtransService) GetPaymentStatus(orderID string) (*MidtransStatusResp
 		req.Header.Set("Accept", "application/json")
 		req.Header.Set("User-Agent", "Payment-Service/1.0")
 
-		resp, err := ms.httpClient.Do(req)
+		// Rate limit and circuit breaker: an open breaker fails fast instead of retrying
+		release, err := ms.limiter.Acquire()
+		if err != nil {
+			return nil, fmt.Errorf("Midtrans request not sent: %w", err)
+		}
+
+		resp, err := ms.statusClient.Do(req)
 		if err != nil {
+			release(0, nil)
 			if attempt == maxRetries {
 				return nil, fmt.Errorf("failed to make request after %d attempts: %w", maxRetries+1, err)
 			}
 			
 			// Exponential backoff
-			delay := time.Duration(float64(baseDelay) * math.Pow(2, float64(attempt)))
+			delay := ms.statusPolicy.Delay(attempt)
 			fmt.Printf("⚠️ Status request failed (attempt %d/%d), retrying in %v: %v\n", attempt+1, maxRetries+1, delay, err)
 			time.Sleep(delay)
 			continue
@@ -367,13 +450,14 @@ func (ms *MidtransService) GetPaymentStatus(orderID string) (*MidtransStatusResp
 
 		body, err := io.ReadAll(resp.Body)
 		resp.Body.Close()
+		release(resp.StatusCode, resp.Header)
 		
 		if err != nil {
 			if attempt == maxRetries {
 				return nil, fmt.Errorf("failed to read response: %w", err)
 			}
 			
-			delay := time.Duration(float64(baseDelay) * math.Pow(2, float64(attempt)))
+			delay := ms.statusPolicy.Delay(attempt)
 			fmt.Printf("⚠️ Failed to read status response (attempt %d/%d), retrying in %v: %v\n", attempt+1, maxRetries+1, delay, err)
 			time.Sleep(delay)
 			continue
@@ -394,8 +478,11 @@ func (ms *MidtransService) GetPaymentStatus(orderID string) (*MidtransStatusResp
 				return nil, fmt.Errorf("Midtrans API error (Status %d): %s", resp.StatusCode, string(body))
 			}
 			
-			delay := time.Duration(float64(baseDelay) * math.Pow(2, float64(attempt)))
-			fmt.Printf("⚠️ Status API error %d (attempt %d/%d), retrying in %v: %s\n", resp.StatusCode, attempt+1, maxRetries+1, delay, string(body))
+			delay := ms.statusPolicy.Delay(attempt)
+			if wait := retryAfter(resp.Header); wait > delay {
+				delay = wait // Honour Midtrans' Retry-After on 429
+			}
+			fmt.Printf("⚠️ Status API error %d (attempt %d/%d), retrying in %v: %s\n", resp.StatusCode, attempt+1, maxRetries+1, delay, redact.JSON(body))
 			time.Sleep(delay)
 			continue
 		}
@@ -407,16 +494,114 @@ func (ms *MidtransService) GetPaymentStatus(orderID string) (*MidtransStatusResp
 	return nil, fmt.Errorf("unexpected error: max retries exceeded")
 }
 
+// Ping asks Midtrans for the status of an order that doesn't exist, which checks the API is
+// reachable and accepts the server key without creating or changing anything
+func (ms *MidtransService) Ping(ctx context.Context) error {
+	url := fmt.Sprintf("%s/check-config-%d/status", ms.baseURL, time.Now().UnixNano())
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", ms.authHeader)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Payment-Service/1.0")
+
+	resp, err := ms.statusClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Midtrans: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	// Midtrans answers most errors with HTTP 200 and the real status in the body
+	var status struct {
+		StatusCode string `json:"status_code"`
+	}
+	json.Unmarshal(body, &status)
+	if status.StatusCode == "" {
+		status.StatusCode = strconv.Itoa(resp.StatusCode)
+	}
+
+	switch status.StatusCode {
+	case "200", "404":
+		return nil
+	case "401":
+		return fmt.Errorf("Midtrans rejected the server key (%s environment)", ms.environment)
+	default:
+		return fmt.Errorf("Midtrans API error (Status %s): %s", status.StatusCode, redact.JSON(body))
+	}
+}
+
+// ErrTransactionNotFound is returned when Midtrans has no transaction for the order, e.g.
+// because the charge never reached it
+var ErrTransactionNotFound = errors.New("Midtrans transaction not found")
+
+// CancelPayment cancels a pending transaction so it can no longer be paid
+func (ms *MidtransService) CancelPayment(orderID string) error {
+	return ms.closeTransaction(orderID, "cancel")
+}
+
+// ExpirePayment expires a pending transaction, e.g. one past its expiry time here, so its VA
+// number or payment code stops being payable
+func (ms *MidtransService) ExpirePayment(orderID string) error {
+	return ms.closeTransaction(orderID, "expire")
+}
+
+// closeTransaction calls the /cancel or /expire action of a transaction. It is tried once,
+// Midtrans refuses transactions that were already paid or can't be closed.
+func (ms *MidtransService) closeTransaction(orderID, action string) error {
+	url := fmt.Sprintf("%s/%s/%s", ms.baseURL, orderID, action)
+
+	req, err := http.NewRequest("POST", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", ms.authHeader)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Payment-Service/1.0")
+
+	release, err := ms.limiter.Acquire()
+	if err != nil {
+		return fmt.Errorf("Midtrans request not sent: %w", err)
+	}
+
+	resp, err := ms.statusClient.Do(req)
+	if err != nil {
+		release(0, nil)
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	release(resp.StatusCode, resp.Header)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Midtrans reports refusals in the body's status_code, usually with HTTP 200. 407 means
+	// the transaction already expired, which is just as unpayable.
+	var actionResp MidtransStatusResponse
+	if err := json.Unmarshal(body, &actionResp); err != nil {
+		return fmt.Errorf("Midtrans API error (Status %d): %s", resp.StatusCode, redact.JSON(body))
+	}
+	if resp.StatusCode == http.StatusNotFound || actionResp.StatusCode == "404" {
+		return ErrTransactionNotFound
+	}
+	if resp.StatusCode != http.StatusOK || (actionResp.StatusCode != "200" && actionResp.StatusCode != "407" && actionResp.StatusCode != "") {
+		return fmt.Errorf("Midtrans API error (Status %s): %s", actionResp.StatusCode, actionResp.StatusMessage)
+	}
+	return nil
+}
+
 // VerifySignature verifies Midtrans callback signature
 func (ms *MidtransService) VerifySignature(orderID, statusCode, grossAmount, signatureKey string) bool {
-	// Create signature string
-	signatureString := orderID + statusCode + grossAmount + ms.serverKey
-
-	// Hash with SHA512
-	hash := sha512.Sum512([]byte(signatureString))
-	expectedSignature := fmt.Sprintf("%x", hash)
+	return signatureKey == ms.SignCallback(orderID, statusCode, grossAmount)
+}
 
-	return signatureKey == expectedSignature
+// SignCallback returns the signature_key Midtrans sends with a callback:
+// SHA512(order_id + status_code + gross_amount + server_key)
+func (ms *MidtransService) SignCallback(orderID, statusCode, grossAmount string) string {
+	hash := sha512.Sum512([]byte(orderID + statusCode + grossAmount + ms.serverKey))
+	return fmt.Sprintf("%x", hash)
 }
 
 // MapMidtransStatusToPaymentStatus maps Midtrans status to our payment status
@@ -432,6 +617,8 @@ func (ms *MidtransService) MapMidtransStatusToPaymentStatus(midtransStatus strin
 		return models.PaymentStatusCancelled
 	case "expire":
 		return models.PaymentStatusExpired
+	case "refund", "partial_refund":
+		return models.PaymentStatusRefunded
 	default:
 		return models.PaymentStatusPending
 	}
@@ -446,12 +633,12 @@ func (ms *MidtransService) charge(chargeReq MidtransChargeRequest) (*MidtransCha
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Log the request for debugging
-	fmt.Printf("🔍 Midtrans Request: %s\n", string(jsonData))
+	// Log the request for debugging, without customer details
+	fmt.Printf("🔍 Midtrans Request: order %s, payment_type %s, gross_amount %d\n",
+		chargeReq.TransactionDetails.OrderID, chargeReq.PaymentType, chargeReq.TransactionDetails.GrossAmount)
 
-	// Retry mechanism with exponential backoff
-	maxRetries := 3
-	baseDelay := 1 * time.Second
+	// Retry mechanism with backoff (MIDTRANS_CHARGE_* policy)
+	maxRetries := ms.chargePolicy.Retries()
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
@@ -465,14 +652,21 @@ func (ms *MidtransService) charge(chargeReq MidtransChargeRequest) (*MidtransCha
 		req.Header.Set("Accept", "application/json")
 		req.Header.Set("User-Agent", "Payment-Service/1.0")
 
-		resp, err := ms.httpClient.Do(req)
+		// Rate limit and circuit breaker: an open breaker fails fast instead of retrying
+		release, err := ms.limiter.Acquire()
 		if err != nil {
+			return nil, fmt.Errorf("Midtrans request not sent: %w", err)
+		}
+
+		resp, err := ms.chargeClient.Do(req)
+		if err != nil {
+			release(0, nil)
 			if attempt == maxRetries {
 				return nil, fmt.Errorf("failed to make request after %d attempts: %w", maxRetries+1, err)
 			}
 			
 			// Exponential backoff
-			delay := time.Duration(float64(baseDelay) * math.Pow(2, float64(attempt)))
+			delay := ms.chargePolicy.Delay(attempt)
 			fmt.Printf("⚠️ Request failed (attempt %d/%d), retrying in %v: %v\n", attempt+1, maxRetries+1, delay, err)
 			time.Sleep(delay)
 			continue
@@ -480,20 +674,21 @@ func (ms *MidtransService) charge(chargeReq MidtransChargeRequest) (*MidtransCha
 
 		body, err := io.ReadAll(resp.Body)
 		resp.Body.Close()
+		release(resp.StatusCode, resp.Header)
 		
 		if err != nil {
 			if attempt == maxRetries {
 				return nil, fmt.Errorf("failed to read response: %w", err)
 			}
 			
-			delay := time.Duration(float64(baseDelay) * math.Pow(2, float64(attempt)))
+			delay := ms.chargePolicy.Delay(attempt)
 			fmt.Printf("⚠️ Failed to read response (attempt %d/%d), retrying in %v: %v\n", attempt+1, maxRetries+1, delay, err)
 			time.Sleep(delay)
 			continue
 		}
 
-		// Log the response for debugging
-		fmt.Printf("🔍 Midtrans Response (Status %d): %s\n", resp.StatusCode, string(body))
+		// Log the response for debugging, the body may hold customer details
+		fmt.Printf("🔍 Midtrans Response (Status %d, %d bytes)\n", resp.StatusCode, len(body))
 
 		// Handle different status codes
 		if resp.StatusCode == http.StatusOK {
@@ -503,8 +698,8 @@ func (ms *MidtransService) charge(chargeReq MidtransChargeRequest) (*MidtransCha
 			}
 			
 			// Log parsed response data for debugging
-			fmt.Printf("🔍 Parsed Midtrans Response - PaymentCode: '%s', VANumbers: %+v, PaymentType: '%s'\n", 
-				chargeResp.PaymentCode, chargeResp.VANumbers, chargeResp.PaymentType)
+			fmt.Printf("🔍 Parsed Midtrans Response - PaymentCode: '%s', VANumbers: %d, PaymentType: '%s'\n",
+				redact.Tail(chargeResp.PaymentCode), len(chargeResp.VANumbers), chargeResp.PaymentType)
 			
 			// Check if Midtrans returned an error in the response body
 			if chargeResp.StatusCode == "505" || chargeResp.StatusCode == "500" || chargeResp.StatusCode == "400" || chargeResp.StatusCode == "401" {
@@ -520,8 +715,11 @@ func (ms *MidtransService) charge(chargeReq MidtransChargeRequest) (*MidtransCha
 				return nil, fmt.Errorf("Midtrans API error (Status %d): %s", resp.StatusCode, string(body))
 			}
 			
-			delay := time.Duration(float64(baseDelay) * math.Pow(2, float64(attempt)))
-			fmt.Printf("⚠️ API error %d (attempt %d/%d), retrying in %v: %s\n", resp.StatusCode, attempt+1, maxRetries+1, delay, string(body))
+			delay := ms.chargePolicy.Delay(attempt)
+			if wait := retryAfter(resp.Header); wait > delay {
+				delay = wait // Honour Midtrans' Retry-After on 429
+			}
+			fmt.Printf("⚠️ API error %d (attempt %d/%d), retrying in %v: %s\n", resp.StatusCode, attempt+1, maxRetries+1, delay, redact.JSON(body))
 			time.Sleep(delay)
 			continue
 		}
@@ -533,6 +731,11 @@ func (ms *MidtransService) charge(chargeReq MidtransChargeRequest) (*MidtransCha
 	return nil, fmt.Errorf("unexpected error: max retries exceeded")
 }
 
+// LimiterStats reports the Midtrans rate limiter and circuit breaker state
+func (ms *MidtransService) LimiterStats() map[string]interface{} {
+	return ms.limiter.Stats()
+}
+
 // getCallbackURL returns the callback URL for webhooks
 func (ms *MidtransService) getCallbackURL() string {
 	baseURL := os.Getenv("PAYMENT_SERVICE_URL")
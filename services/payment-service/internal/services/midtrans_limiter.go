@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrMidtransCircuitOpen is returned without calling Midtrans while the breaker is open
+var ErrMidtransCircuitOpen = errors.New("Midtrans circuit breaker is open")
+
+// ErrMidtransQueueTimeout is returned when a call waited too long for a rate limit slot
+var ErrMidtransQueueTimeout = errors.New("timed out waiting for Midtrans rate limit")
+
+// Circuit breaker states
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half_open"
+)
+
+// MidtransLimiter protects the Midtrans API (and us from its rate limiting) with a
+// token bucket, a cap on concurrent calls and a circuit breaker that opens after
+// sustained 429/5xx responses. Every HTTP attempt, including retries, goes through it.
+type MidtransLimiter struct {
+	rate         float64 // tokens per second
+	burst        float64
+	slots        chan struct{}
+	queueTimeout time.Duration
+
+	mu          sync.Mutex
+	tokens      float64
+	lastRefill  time.Time
+	pausedUntil time.Time // set from Retry-After on 429
+
+	threshold   int
+	cooldown    time.Duration
+	state       string
+	failures    int
+	openedAt    time.Time
+	probeActive bool
+
+	// Metrics reported on /api/v1/admin/runtime
+	queued       atomic.Int64
+	requests     atomic.Int64
+	throttled    atomic.Int64
+	serverErrors atomic.Int64
+	rejected     atomic.Int64
+	timeouts     atomic.Int64
+	waitNanos    atomic.Int64
+	breakerOpens atomic.Int64
+}
+
+// NewMidtransLimiter creates the limiter from MIDTRANS_RATE_LIMIT (requests per second),
+// MIDTRANS_RATE_BURST, MIDTRANS_MAX_CONCURRENCY, MIDTRANS_QUEUE_TIMEOUT,
+// MIDTRANS_BREAKER_THRESHOLD and MIDTRANS_BREAKER_COOLDOWN
+func NewMidtransLimiter() *MidtransLimiter {
+	rate := getEnvInt("MIDTRANS_RATE_LIMIT", 10)
+	burst := getEnvInt("MIDTRANS_RATE_BURST", 20)
+	concurrency := getEnvInt("MIDTRANS_MAX_CONCURRENCY", 10)
+	if rate <= 0 {
+		rate = 10
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &MidtransLimiter{
+		rate:         float64(rate),
+		burst:        float64(burst),
+		slots:        make(chan struct{}, concurrency),
+		queueTimeout: getEnvDuration("MIDTRANS_QUEUE_TIMEOUT", 10*time.Second),
+		tokens:       float64(burst),
+		lastRefill:   time.Now(),
+		threshold:    getEnvInt("MIDTRANS_BREAKER_THRESHOLD", 5),
+		cooldown:     getEnvDuration("MIDTRANS_BREAKER_COOLDOWN", 30*time.Second),
+		state:        breakerClosed,
+	}
+}
+
+// Acquire waits for a concurrency slot and a token. The returned release function must be
+// called with the HTTP status (0 on transport errors) once the attempt has finished.
+func (l *MidtransLimiter) Acquire() (func(status int, header http.Header), error) {
+	probe, err := l.allow()
+	if err != nil {
+		l.rejected.Add(1)
+		return nil, err
+	}
+
+	l.queued.Add(1)
+	defer l.queued.Add(-1)
+
+	start := time.Now()
+	deadline := start.Add(l.queueTimeout)
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+	case <-timer.C:
+		l.timeouts.Add(1)
+		l.abortProbe(probe)
+		return nil, ErrMidtransQueueTimeout
+	}
+
+	for {
+		wait := l.reserve()
+		if wait == 0 {
+			break
+		}
+		if time.Now().Add(wait).After(deadline) {
+			<-l.slots
+			l.timeouts.Add(1)
+			l.abortProbe(probe)
+			return nil, ErrMidtransQueueTimeout
+		}
+		time.Sleep(wait)
+	}
+
+	l.waitNanos.Add(int64(time.Since(start)))
+	l.requests.Add(1)
+
+	var once sync.Once
+	return func(status int, header http.Header) {
+		once.Do(func() {
+			<-l.slots
+			l.record(status, header, probe)
+		})
+	}, nil
+}
+
+// allow checks the breaker. After the cooldown a single probe call is let through.
+func (l *MidtransLimiter) allow() (probe bool, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch l.state {
+	case breakerOpen:
+		if time.Since(l.openedAt) < l.cooldown {
+			return false, ErrMidtransCircuitOpen
+		}
+		l.state = breakerHalfOpen
+		l.probeActive = true
+		return true, nil
+	case breakerHalfOpen:
+		if l.probeActive {
+			return false, ErrMidtransCircuitOpen
+		}
+		l.probeActive = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// abortProbe lets another call probe when the probe never reached Midtrans
+func (l *MidtransLimiter) abortProbe(probe bool) {
+	if !probe {
+		return
+	}
+	l.mu.Lock()
+	l.probeActive = false
+	l.mu.Unlock()
+}
+
+// reserve takes a token and returns 0, or returns how long to wait for the next one
+func (l *MidtransLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+
+	l.tokens += now.Sub(l.lastRefill).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.lastRefill = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// record updates the breaker with the outcome of an attempt
+func (l *MidtransLimiter) record(status int, header http.Header, probe bool) {
+	failed := status == 0 || status == http.StatusTooManyRequests || status >= 500
+	if status == http.StatusTooManyRequests {
+		l.throttled.Add(1)
+	} else if status >= 500 {
+		l.serverErrors.Add(1)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if status == http.StatusTooManyRequests {
+		// Everyone backs off, not just the caller that was throttled
+		if pause := retryAfter(header); pause > 0 {
+			l.pausedUntil = time.Now().Add(pause)
+		}
+		l.tokens = 0
+	}
+
+	if probe {
+		l.probeActive = false
+	}
+
+	if !failed {
+		l.failures = 0
+		if l.state != breakerClosed {
+			fmt.Printf("✅ Midtrans circuit breaker closed\n")
+		}
+		l.state = breakerClosed
+		return
+	}
+
+	l.failures++
+	if probe || (l.state == breakerClosed && l.failures >= l.threshold) {
+		l.state = breakerOpen
+		l.openedAt = time.Now()
+		l.breakerOpens.Add(1)
+		fmt.Printf("⚠️ Midtrans circuit breaker opened after %d consecutive failures (cooldown %s)\n", l.failures, l.cooldown)
+	}
+}
+
+// Stats reports limiter configuration, queueing and breaker state
+func (l *MidtransLimiter) Stats() map[string]interface{} {
+	l.mu.Lock()
+	state := l.state
+	failures := l.failures
+	l.mu.Unlock()
+
+	requests := l.requests.Load()
+	avgWait := time.Duration(0)
+	if requests > 0 {
+		avgWait = time.Duration(l.waitNanos.Load() / requests)
+	}
+
+	return map[string]interface{}{
+		"rate_per_second":      l.rate,
+		"burst":                l.burst,
+		"max_concurrency":      cap(l.slots),
+		"in_flight":            len(l.slots),
+		"queued":               l.queued.Load(),
+		"requests":             requests,
+		"avg_wait":             avgWait.String(),
+		"queue_timeouts":       l.timeouts.Load(),
+		"throttled":            l.throttled.Load(),
+		"server_errors":        l.serverErrors.Load(),
+		"breaker_state":        state,
+		"consecutive_failures": failures,
+		"breaker_opens":        l.breakerOpens.Load(),
+		"breaker_rejections":   l.rejected.Load(),
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
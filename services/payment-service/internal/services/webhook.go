@@ -0,0 +1,328 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"payment-service/internal/httpclient"
+	"payment-service/internal/models"
+	"payment-service/internal/repository"
+	"payment-service/internal/retry"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxWebhookBackoff caps the delay between two delivery attempts
+	maxWebhookBackoff = 6 * time.Hour
+	// webhookBatchSize is the number of due deliveries processed per poll
+	webhookBatchSize = 50
+)
+
+// WebhookPayload is the JSON body posted to merchant endpoints
+type WebhookPayload struct {
+	ID        string          `json:"id"` // Event ID, identical across retries and redeliveries
+	Type      string          `json:"type"`
+	CreatedAt string          `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookService delivers signed payment events to merchant endpoints with retry and backoff
+type WebhookService struct {
+	webhookRepo  *repository.WebhookRepository
+	httpClient   *http.Client
+	maxAttempts  int
+	retryDelay   time.Duration
+	pollInterval time.Duration
+	wake         chan struct{}
+	stop         chan struct{}
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(webhookRepo *repository.WebhookRepository) *WebhookService {
+	return &WebhookService{
+		webhookRepo:  webhookRepo,
+		httpClient:   httpclient.New("webhook", retry.Policy{MaxAttempts: 1, Timeout: getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second)}).Client,
+		maxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		retryDelay:   getEnvDuration("WEBHOOK_RETRY_DELAY", 30*time.Second),
+		pollInterval: getEnvDuration("WEBHOOK_POLL_INTERVAL", 15*time.Second),
+		wake:         make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start launches the background worker that sends due deliveries
+func (ws *WebhookService) Start() {
+	go func() {
+		ticker := time.NewTicker(ws.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ws.stop:
+				return
+			case <-ticker.C:
+			case <-ws.wake:
+			}
+			ws.processDueDeliveries()
+		}
+	}()
+
+	fmt.Printf("🚀 Webhook delivery worker started (max attempts: %d, poll interval: %s)\n", ws.maxAttempts, ws.pollInterval)
+}
+
+// Stop stops the background worker
+func (ws *WebhookService) Stop() {
+	close(ws.stop)
+}
+
+// Dispatch queues a delivery of the event for every active endpoint subscribed to it
+func (ws *WebhookService) Dispatch(eventID uuid.UUID, eventType, paymentID string, data json.RawMessage, occurredAt time.Time) error {
+	endpoints, err := ws.Subscribers(eventType)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(WebhookPayload{
+		ID:        eventID.String(),
+		Type:      eventType,
+		CreatedAt: occurredAt.Format(time.RFC3339),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	queued := 0
+	for _, endpoint := range endpoints {
+		if err := ws.queue(endpoint.ID, eventID, eventType, paymentID, string(payload)); err != nil {
+			fmt.Printf("❌ Failed to queue webhook %s for endpoint %s: %v\n", eventType, endpoint.ID, err)
+			continue
+		}
+		queued++
+	}
+
+	if queued > 0 {
+		fmt.Printf("📬 Queued %d webhook deliveries for %s (payment: %s)\n", queued, eventType, paymentID)
+		ws.notify()
+	}
+
+	return nil
+}
+
+// Subscribers returns the active endpoints subscribed to eventType
+func (ws *WebhookService) Subscribers(eventType string) ([]models.WebhookEndpoint, error) {
+	endpoints, err := ws.webhookRepo.GetActiveEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	subscribers := endpoints[:0]
+	for _, endpoint := range endpoints {
+		if endpoint.Subscribes(eventType) {
+			subscribers = append(subscribers, endpoint)
+		}
+	}
+	return subscribers, nil
+}
+
+// Redeliver queues a fresh delivery with the same payload as an earlier one
+func (ws *WebhookService) Redeliver(delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	now := time.Now()
+	redelivery := &models.WebhookDelivery{
+		ID:            uuid.New(),
+		EndpointID:    delivery.EndpointID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		PaymentID:     delivery.PaymentID,
+		Payload:       delivery.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+	}
+
+	if err := ws.webhookRepo.CreateDelivery(redelivery); err != nil {
+		return nil, err
+	}
+
+	ws.notify()
+	return redelivery, nil
+}
+
+// GenerateSecret returns a new random signing secret
+func (ws *WebhookService) GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// Sign computes the signature header value for a payload.
+// Merchants verify it by computing HMAC-SHA256(secret, "<t>.<body>") and comparing with v1.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// queue stores a pending delivery due immediately
+func (ws *WebhookService) queue(endpointID, eventID uuid.UUID, eventType, paymentID, payload string) error {
+	now := time.Now()
+	return ws.webhookRepo.CreateDelivery(&models.WebhookDelivery{
+		ID:            uuid.New(),
+		EndpointID:    endpointID,
+		EventID:       eventID,
+		EventType:     eventType,
+		PaymentID:     paymentID,
+		Payload:       payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+	})
+}
+
+// notify wakes the worker without blocking when a wake up is already pending
+func (ws *WebhookService) notify() {
+	select {
+	case ws.wake <- struct{}{}:
+	default:
+	}
+}
+
+// processDueDeliveries sends every pending delivery whose next attempt is due
+func (ws *WebhookService) processDueDeliveries() {
+	deliveries, err := ws.webhookRepo.GetDueDeliveries(time.Now(), webhookBatchSize)
+	if err != nil {
+		fmt.Printf("❌ Failed to load due webhook deliveries: %v\n", err)
+		return
+	}
+
+	for i := range deliveries {
+		ws.attempt(&deliveries[i])
+	}
+}
+
+// attempt sends a delivery once and schedules the next retry on failure
+func (ws *WebhookService) attempt(delivery *models.WebhookDelivery) {
+	endpoint, err := ws.webhookRepo.GetEndpointByID(delivery.EndpointID)
+	if err != nil || !endpoint.IsActive {
+		// Endpoint was removed or disabled, stop retrying
+		reason := "endpoint not found or inactive"
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = &reason
+		delivery.NextAttemptAt = nil
+		ws.saveDelivery(delivery)
+		return
+	}
+
+	delivery.Attempts++
+	statusCode, sendErr := ws.send(endpoint, delivery)
+	if statusCode != 0 {
+		delivery.ResponseStatus = &statusCode
+	}
+
+	if sendErr == nil {
+		now := time.Now()
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = nil
+		fmt.Printf("✅ Webhook %s delivered to %s (attempt %d)\n", delivery.EventType, endpoint.URL, delivery.Attempts)
+		ws.saveDelivery(delivery)
+		return
+	}
+
+	errMsg := sendErr.Error()
+	delivery.LastError = &errMsg
+
+	if delivery.Attempts >= ws.maxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		fmt.Printf("❌ Webhook %s to %s failed permanently after %d attempts: %v\n", delivery.EventType, endpoint.URL, delivery.Attempts, sendErr)
+	} else {
+		next := time.Now().Add(ws.backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		fmt.Printf("⚠️ Webhook %s to %s failed (attempt %d), retrying at %s: %v\n", delivery.EventType, endpoint.URL, delivery.Attempts, next.Format(time.RFC3339), sendErr)
+	}
+
+	ws.saveDelivery(delivery)
+}
+
+// send posts the signed payload and returns the response status code
+func (ws *WebhookService) send(endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequest("POST", endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "payment-service-webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Event-ID", delivery.EventID.String())
+	req.Header.Set("X-Webhook-Delivery-ID", delivery.ID.String())
+	req.Header.Set("X-Webhook-Signature", Sign(endpoint.Secret, time.Now().Unix(), body))
+
+	resp, err := ws.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff returns the exponential delay before the next attempt
+func (ws *WebhookService) backoff(attempts int) time.Duration {
+	delay := ws.retryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxWebhookBackoff {
+			return maxWebhookBackoff
+		}
+	}
+	return delay
+}
+
+func (ws *WebhookService) saveDelivery(delivery *models.WebhookDelivery) {
+	if err := ws.webhookRepo.UpdateDelivery(delivery); err != nil {
+		fmt.Printf("❌ Failed to update webhook delivery %s: %v\n", delivery.ID, err)
+	}
+}
+
+// getEnvInt reads an integer environment variable with a default
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDuration reads a duration environment variable (e.g. "30s") with a default
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Local stores objects as files under a directory. Signed URLs point at baseURL, where the
+// service serves them with ServeSigned; they carry an expiry and an HMAC of key and expiry.
+type Local struct {
+	dir        string
+	baseURL    string
+	signingKey []byte
+}
+
+// NewLocal creates a store in dir whose signed URLs are baseURL/<key>. Without signingKey a
+// random one is used, signed URLs then stop working on restart.
+func NewLocal(dir, baseURL, signingKey string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	if baseURL == "" {
+		return nil, errors.New("STORAGE_LOCAL_BASE_URL is required for the local storage backend")
+	}
+
+	key := []byte(signingKey)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate storage signing key: %w", err)
+		}
+		log.Println("⚠️ STORAGE_SIGNING_KEY not set, signed storage URLs expire on restart")
+	}
+
+	return &Local{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/"), signingKey: key}, nil
+}
+
+// Put writes the object to a temporary file and renames it into place, so readers never see
+// a partial object. Local files carry no content type, it is detected again when served.
+func (l *Local) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	filename, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0o750); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get opens the file of key
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	filename, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", key, err)
+	}
+	return file, nil
+}
+
+// SignedURL returns baseURL/<key>?expires=<unix>&signature=<hmac>
+func (l *Local) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", l.sign(key, expires))
+	return l.baseURL + "/" + escapeKey(key) + "?" + query.Encode(), nil
+}
+
+// Delete removes the file of key
+func (l *Local) Delete(ctx context.Context, key string) error {
+	filename, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// ServeSigned serves the object of key to a request carrying a valid, unexpired signature
+// from SignedURL. Content type is detected from the extension, then the content.
+func (l *Local) ServeSigned(w http.ResponseWriter, r *http.Request, key string) {
+	expires := r.URL.Query().Get("expires")
+	signature := r.URL.Query().Get("signature")
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || validKey(key) != nil ||
+		!hmac.Equal([]byte(signature), []byte(l.sign(key, expires))) {
+		http.Error(w, "Invalid signature", http.StatusForbidden)
+		return
+	}
+	if time.Now().Unix() > expiresAt {
+		http.Error(w, "Link expired", http.StatusForbidden)
+		return
+	}
+
+	filename, _ := l.path(key)
+	file, err := os.Open(filename)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filepath.Base(filename)))
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, r, filepath.Base(filename), info.ModTime(), file)
+}
+
+func (l *Local) path(key string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+func (l *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, l.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
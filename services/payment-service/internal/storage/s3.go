@@ -0,0 +1,317 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"payment-service/internal/httpclient"
+	"payment-service/internal/retry"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// maxPresignExpiry is the longest validity Signature Version 4 allows
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// S3Config configures an S3 or S3 compatible bucket
+type S3Config struct {
+	Bucket          string
+	Region          string
+	Endpoint        string // e.g. http://minio:9000, empty for AWS
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool // address the bucket in the path, implied by a custom Endpoint
+}
+
+// S3 stores objects in an S3 bucket through its REST API, signing requests with AWS
+// Signature Version 4
+type S3 struct {
+	bucket    string
+	region    string
+	endpoint  *url.URL
+	accessKey string
+	secretKey string
+	pathStyle bool
+	client    *httpclient.Client
+}
+
+// NewS3 creates a store in cfg.Bucket
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("STORAGE_BUCKET, STORAGE_ACCESS_KEY_ID and STORAGE_SECRET_ACCESS_KEY are required for the s3 and gcs storage backends")
+	}
+
+	pathStyle := cfg.PathStyle
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	} else {
+		pathStyle = true
+	}
+	parsed, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid STORAGE_ENDPOINT %q", endpoint)
+	}
+
+	// Only the timeout (STORAGE_TIMEOUT) applies, uploads aren't retried
+	policy := retry.FromEnv("STORAGE", retry.Policy{
+		MaxAttempts: 1,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Multiplier:  2,
+		Timeout:     60 * time.Second,
+	})
+
+	return &S3{
+		bucket:    cfg.Bucket,
+		region:    cfg.Region,
+		endpoint:  parsed,
+		accessKey: cfg.AccessKeyID,
+		secretKey: cfg.SecretAccessKey,
+		pathStyle: pathStyle,
+		client:    httpclient.New("storage", policy),
+	}, nil
+}
+
+// NewGCS creates a store in a Google Cloud Storage bucket through its S3 compatible XML API,
+// authenticated with an HMAC key of a service account
+func NewGCS(bucket, accessID, secret string) (*S3, error) {
+	return NewS3(S3Config{
+		Bucket:          bucket,
+		Region:          "auto",
+		Endpoint:        "https://storage.googleapis.com",
+		AccessKeyID:     accessID,
+		SecretAccessKey: secret,
+	})
+}
+
+// Put uploads the object. The body is read into memory to sign its hash; artifacts are a few
+// megabytes at most.
+func (s *S3) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	contentType, body, err := detectContentType(key, body, contentType)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, data, contentType)
+	if err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to store %s: %w", key, responseError(resp))
+	}
+	return nil
+}
+
+// Get downloads the object
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("failed to get %s: %w", key, responseError(resp))
+	}
+}
+
+// SignedURL presigns a GET of the object, valid for expiry (at most 7 days)
+func (s *S3) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	if expiry > maxPresignExpiry {
+		expiry = maxPresignExpiry
+	}
+
+	target := s.objectURL(key)
+	amzDate, scope := s.credentialScope(time.Now())
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.accessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	canonicalQuery := canonicalQueryString(query)
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		target.EscapedPath(),
+		canonicalQuery,
+		"host:" + target.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	signature := s.signature(amzDate, scope, canonicalRequest)
+	return target.String() + "?" + canonicalQuery + "&X-Amz-Signature=" + signature, nil
+}
+
+// Delete removes the object, S3 reports success for missing keys too
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, "")
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete %s: %w", key, responseError(resp))
+	}
+	return nil
+}
+
+// do sends a signed request for the object of key
+func (s *S3) do(ctx context.Context, method, key string, body []byte, contentType string) (*http.Response, error) {
+	target := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	payloadHash := emptyPayloadHash
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	amzDate, scope := s.credentialScope(time.Now())
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		method,
+		target.EscapedPath(),
+		"",
+		"host:" + target.Host + "\nx-amz-content-sha256:" + payloadHash + "\nx-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, s.signature(amzDate, scope, canonicalRequest)))
+
+	return s.client.Do(req)
+}
+
+// objectURL addresses key in the bucket, virtual hosted or path style
+func (s *S3) objectURL(key string) *url.URL {
+	target := *s.endpoint
+	escaped := "/" + escapeKey(key)
+	if s.pathStyle {
+		escaped = "/" + escapeKey(s.bucket) + escaped
+	} else {
+		target.Host = s.bucket + "." + target.Host
+	}
+	target.RawPath = target.RawPath + escaped
+	target.Path, _ = url.PathUnescape(target.RawPath)
+	return &target
+}
+
+// credentialScope returns the request time and the credential scope of its day
+func (s *S3) credentialScope(now time.Time) (string, string) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	return amzDate, amzDate[:8] + "/" + s.region + "/s3/aws4_request"
+}
+
+// signature signs canonicalRequest with the key derived for the scope's day and region
+func (s *S3) signature(amzDate, scope, canonicalRequest string) string {
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), amzDate[:8])
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escapeKey percent-encodes everything but unreserved characters in each segment of key
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// uriEncode percent-encodes value as Signature Version 4 requires (RFC 3986 unreserved kept)
+func uriEncode(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// canonicalQueryString sorts and encodes query as Signature Version 4 requires
+func canonicalQueryString(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// responseError describes a failed response with the start of its XML error body
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,151 @@
+// Package storage keeps generated artifacts (PDF invoices, payment exports) in a configurable
+// backend: local disk, S3 (or an S3 compatible store such as MinIO) or Google Cloud Storage.
+// Keys are namespaced per service, so services can share a bucket.
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Backends selected with STORAGE_BACKEND
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+	BackendGCS   = "gcs"
+)
+
+// ErrNotFound is returned by Get for keys that aren't stored
+var ErrNotFound = errors.New("object not found")
+
+// Storage stores objects by key
+type Storage interface {
+	// Put stores body under key. An empty contentType is detected from the key's extension,
+	// then from the content.
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	// Get opens the object stored under key, ErrNotFound when there is none
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// SignedURL returns a URL anyone can download the object from until expiry
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+	// Delete removes the object, deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// FromEnv creates the STORAGE_BACKEND storage with keys under namespace (the service name).
+// It returns nil when STORAGE_BACKEND is unset, generated artifacts are then not stored.
+func FromEnv(namespace string) (Storage, error) {
+	var backend Storage
+	var err error
+	switch name := strings.ToLower(os.Getenv("STORAGE_BACKEND")); name {
+	case "":
+		return nil, nil
+	case BackendLocal:
+		backend, err = NewLocal(
+			getEnv("STORAGE_LOCAL_DIR", "./data/storage"),
+			os.Getenv("STORAGE_LOCAL_BASE_URL"),
+			os.Getenv("STORAGE_SIGNING_KEY"),
+		)
+	case BackendS3:
+		backend, err = NewS3(S3Config{
+			Bucket:          os.Getenv("STORAGE_BUCKET"),
+			Region:          getEnv("STORAGE_REGION", "us-east-1"),
+			Endpoint:        os.Getenv("STORAGE_ENDPOINT"),
+			AccessKeyID:     os.Getenv("STORAGE_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("STORAGE_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("STORAGE_PATH_STYLE") == "true",
+		})
+	case BackendGCS:
+		backend, err = NewGCS(os.Getenv("STORAGE_BUCKET"), os.Getenv("STORAGE_ACCESS_KEY_ID"), os.Getenv("STORAGE_SECRET_ACCESS_KEY"))
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q, expected local, s3 or gcs", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Namespaced(backend, namespace), nil
+}
+
+// Namespaced stores the keys of s under namespace/
+func Namespaced(s Storage, namespace string) Storage {
+	return &namespaced{backend: s, prefix: strings.Trim(namespace, "/") + "/"}
+}
+
+type namespaced struct {
+	backend Storage
+	prefix  string
+}
+
+func (n *namespaced) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	return n.backend.Put(ctx, n.prefix+key, body, contentType)
+}
+
+func (n *namespaced) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return n.backend.Get(ctx, n.prefix+key)
+}
+
+func (n *namespaced) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return n.backend.SignedURL(ctx, n.prefix+key, expiry)
+}
+
+func (n *namespaced) Delete(ctx context.Context, key string) error {
+	return n.backend.Delete(ctx, n.prefix+key)
+}
+
+// AsLocal returns the local disk backend of s, nil for other backends. Its signed URLs are
+// served by the service itself, see Local.ServeSigned.
+func AsLocal(s Storage) *Local {
+	if n, ok := s.(*namespaced); ok {
+		s = n.backend
+	}
+	local, _ := s.(*Local)
+	return local
+}
+
+// detectContentType returns contentType, or guesses it from the key's extension and then the
+// start of the content. The returned reader yields the whole content.
+func detectContentType(key string, body io.Reader, contentType string) (string, io.Reader, error) {
+	if contentType != "" {
+		return contentType, body, nil
+	}
+	if byExtension := mime.TypeByExtension(path.Ext(key)); byExtension != "" {
+		return byExtension, body, nil
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", nil, err
+	}
+	head = head[:n]
+	return http.DetectContentType(head), io.MultiReader(bytes.NewReader(head), body), nil
+}
+
+// validKey rejects keys that could escape the namespace or the local directory
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	return nil
+}
+
+// getEnv reads an environment variable with a default
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,78 @@
+// Package timeutil centralizes time handling: Midtrans timestamps are parsed in Asia/Jakarta,
+// times are stored in UTC and API responses are serialized as RFC3339 in DISPLAY_TIMEZONE.
+package timeutil
+
+import (
+	"fmt"
+	"os"
+	"time"
+	_ "time/tzdata" // Zone data for images without /usr/share/zoneinfo
+)
+
+// MidtransLocation is the zone of Midtrans timestamps that carry no offset
+var MidtransLocation = mustLoad("Asia/Jakarta")
+
+// display is the zone responses are rendered in, see Configure
+var display = time.UTC
+
+// midtransLayouts are the layouts Midtrans uses for transaction, expiry and settlement times
+var midtransLayouts = []string{
+	"2006-01-02 15:04:05", // "2025-09-29 20:47:00"
+	"2006-01-02T15:04:05", // "2025-09-29T20:47:00"
+}
+
+// Configure applies DISPLAY_TIMEZONE (an IANA name, default UTC). It becomes the process'
+// local zone, so times read from the database and time.Now() serialize in it; the stored
+// instants are unaffected.
+func Configure() error {
+	name := os.Getenv("DISPLAY_TIMEZONE")
+	if name == "" {
+		name = "UTC"
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid DISPLAY_TIMEZONE %q: %w", name, err)
+	}
+
+	display = location
+	time.Local = location
+	return nil
+}
+
+// Display returns the configured display zone
+func Display() *time.Location {
+	return display
+}
+
+// NowUTC returns the current time in UTC, used as GORM's clock so stored times are UTC
+func NowUTC() time.Time {
+	return time.Now().UTC()
+}
+
+// ParseMidtrans parses a Midtrans timestamp and returns it in UTC. RFC3339 values keep
+// their offset; values without one are read as Asia/Jakarta time.
+func ParseMidtrans(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range midtransLayouts {
+		if t, err := time.ParseInLocation(layout, value, MidtransLocation); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized Midtrans time %q", value)
+}
+
+// ParseDate parses a YYYY-MM-DD date as midnight in the display zone
+func ParseDate(value string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", value, display)
+}
+
+func mustLoad(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("timeutil: failed to load %s: %v", name, err))
+	}
+	return location
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"product-service/internal/cache"
+	"product-service/internal/events"
+	"product-service/internal/redact"
+	"product-service/internal/serviceauth"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// configKind is the type an environment variable must parse as
+type configKind int
+
+const (
+	kindString configKind = iota
+	kindInt
+	kindBool
+	kindDuration
+	kindURL
+	kindEnum
+)
+
+// configVar describes one environment variable of the service
+type configVar struct {
+	name     string
+	kind     configKind
+	required bool
+	secret   bool     // Redacted in the report
+	values   []string // Allowed values of a kindEnum, case-insensitive
+}
+
+// configCheck is a read only check of a dependency or of configuration loaded at startup
+type configCheck struct {
+	name     string
+	optional bool // The service runs without it, a failure is only a warning
+	run      func(ctx context.Context) error
+}
+
+// configSchema lists the environment variables of Product-Service, see env.example
+var configSchema = []configVar{
+	{name: "DB_HOST"},
+	{name: "DB_PORT", kind: kindInt},
+	{name: "DB_USER"},
+	{name: "DB_PASSWORD", secret: true},
+	{name: "DB_NAME"},
+	{name: "DB_MAX_OPEN_CONNS", kind: kindInt},
+	{name: "DB_MAX_IDLE_CONNS", kind: kindInt},
+	{name: "DB_CONN_MAX_LIFETIME", kind: kindDuration},
+	{name: "DB_CONN_MAX_IDLE_TIME", kind: kindDuration},
+	{name: "DB_SLOW_ACQUIRE_THRESHOLD", kind: kindDuration},
+	{name: "DB_REPLICA_HOST"},
+	{name: "DB_REPLICA_PORT", kind: kindInt},
+	{name: "DB_REPLICA_USER"},
+	{name: "DB_REPLICA_PASSWORD", secret: true},
+	{name: "DB_REPLICA_NAME"},
+	{name: "REDIS_HOST"},
+	{name: "REDIS_PORT", kind: kindInt},
+	{name: "REDIS_PASSWORD", secret: true},
+	{name: "REDIS_DB", kind: kindInt},
+	{name: "REDIS_MODE", kind: kindEnum, values: []string{cache.RedisStandalone, cache.RedisSentinel, cache.RedisCluster}},
+	{name: "REDIS_ADDRS"},
+	{name: "REDIS_MASTER_NAME"},
+	{name: "REDIS_SENTINEL_PASSWORD", secret: true},
+	{name: "CACHE_L1_SIZE", kind: kindInt},
+	{name: "CACHE_L1_TTL", kind: kindDuration},
+	{name: "CACHE_REDIS_RETRY_INTERVAL", kind: kindDuration},
+	{name: "CACHE_TTL_PRODUCT_LIST", kind: kindDuration},
+	{name: "CACHE_TTL_PRODUCT", kind: kindDuration},
+	{name: "CACHE_TTL_AVAILABILITY", kind: kindDuration},
+	{name: "CACHE_TTL_PRICING_RULES", kind: kindDuration},
+	{name: "CACHE_TTL_JITTER"},
+	{name: "WORKER_COUNT", kind: kindInt},
+	{name: "EVENT_BUS", kind: kindEnum, values: []string{events.TransportRabbitMQ, events.TransportKafka}},
+	{name: "CONSUMER_MODE", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_MODE_CHECKOUT", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_MODE_STOCK", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_MODE_STOCK_RELEASE", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_MODE_FUNNEL", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_MODE_USER_PROFILE", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_CONCURRENCY", kind: kindInt},
+	{name: "CONSUMER_PREFETCH", kind: kindInt},
+	{name: "RABBITMQ_HOST"},
+	{name: "RABBITMQ_PORT", kind: kindInt},
+	{name: "RABBITMQ_USERNAME"},
+	{name: "RABBITMQ_PASSWORD", secret: true},
+	{name: "RABBITMQ_DECLARE_TOPOLOGY", kind: kindBool},
+	{name: "RABBITMQ_MANAGEMENT_URL", kind: kindURL},
+	{name: "KAFKA_BROKERS"},
+	{name: "KAFKA_TOPIC_PREFIX"},
+	{name: "KAFKA_TOPIC_PARTITIONS", kind: kindInt},
+	{name: "KAFKA_REPLICATION_FACTOR", kind: kindInt},
+	{name: "KAFKA_MAX_ATTEMPTS", kind: kindInt},
+	{name: "PAYMENT_SERVICE_URL", kind: kindURL},
+	{name: "USER_SERVICE_URL", kind: kindURL},
+	{name: "PRODUCT_SERVICE_URL", kind: kindURL},
+	{name: "SERVICE_AUTH_SECRET", secret: true},
+	{name: "SERVICE_AUTH_TOKEN_TTL", kind: kindDuration},
+	{name: "PORT", kind: kindInt},
+	{name: "BIND_ADDR"},
+	{name: "STARTUP_WAIT_TIMEOUT", kind: kindDuration},
+	{name: "STARTUP_RETRY_INTERVAL", kind: kindDuration},
+	{name: "PUBLISH_SCHEDULER_INTERVAL", kind: kindDuration},
+	{name: "PRODUCT_VIEW_FLUSH_INTERVAL", kind: kindDuration},
+	{name: "DEFAULT_LOCALE", kind: kindEnum, values: []string{"id", "en"}},
+	{name: "APP_ENV", kind: kindEnum, values: []string{"development", "staging", "production"}},
+	{name: "GIN_MODE", kind: kindEnum, values: []string{"debug", "release", "test"}},
+	{name: "TRUSTED_PROXIES"},
+	{name: "ENABLE_PPROF", kind: kindBool},
+	{name: "ADMIN_TOKEN", secret: true},
+	{name: "METRICS_TOKEN", secret: true},
+	{name: "SANDBOX_TOOLS", kind: kindBool},
+	{name: "LOG_REDACT_FIELDS"},
+	{name: "LOG_REQUEST_BODIES", kind: kindBool},
+	{name: "CURSOR_SECRET", secret: true},
+	{name: "API_V1_DEPRECATED_AT"},
+	{name: "API_V1_SUNSET"},
+	{name: "MODERATION_BANNED_WORDS"},
+	{name: "MODERATION_IMAGE_API_URL", kind: kindURL},
+	{name: "MODERATION_IMAGE_API_KEY", secret: true},
+	{name: "MODERATION_IMAGE_API_TIMEOUT", kind: kindDuration},
+	{name: "MODERATION_AUTO_APPROVE", kind: kindBool},
+}
+
+// configChecks are the dependencies of Product-Service. They only connect and authenticate:
+// nothing is migrated or declared.
+func configChecks() []configCheck {
+	checks := []configCheck{
+		{name: "postgres", run: func(ctx context.Context) error {
+			dbHost, dbPort, dbUser, dbPass, dbName := databaseSettings()
+			return pingPostgres(ctx, postgresDSN(dbHost, dbUser, dbPass, dbName, dbPort))
+		}},
+	}
+
+	// Listings fall back to the primary while the replica is down, so it is optional
+	if os.Getenv("DB_REPLICA_HOST") != "" {
+		checks = append(checks, configCheck{name: "postgres replica", optional: true, run: func(ctx context.Context) error {
+			_, dbPort, dbUser, dbPass, dbName := databaseSettings()
+			return pingPostgres(ctx, replicaDSN(dbUser, dbPass, dbName, dbPort))
+		}})
+	}
+
+	return append(checks,
+		// The service runs without its cache until Redis is reachable
+		configCheck{name: "redis", optional: true, run: func(ctx context.Context) error {
+			redisTopology, err := cache.RedisTopologyFromEnv(redisAddr())
+			if err != nil {
+				return err
+			}
+			redisClient := cache.NewRedisClient(redisTopology)
+			defer redisClient.Close()
+			return redisClient.Ping(ctx)
+		}},
+		configCheck{name: "event bus", run: func(ctx context.Context) error {
+			return events.Ping()
+		}},
+		configCheck{name: "service auth", run: func(ctx context.Context) error {
+			_, err := serviceauth.VerifierFromEnv(serviceauth.ProductService)
+			return err
+		}},
+	)
+}
+
+// runCheckConfig validates the environment against configSchema, runs configChecks and
+// prints a report with secrets redacted. It reports whether everything required passed, so
+// it can gate a deploy or run as an init container.
+func runCheckConfig(service string) bool {
+	ok := true
+
+	fmt.Printf("🔎 %s configuration\n", service)
+	for _, v := range configSchema {
+		value, set := os.LookupEnv(v.name)
+		if err := v.validate(value); err != nil {
+			fmt.Printf("  ❌ %s=%s: %v\n", v.name, v.display(value), err)
+			ok = false
+			continue
+		}
+		if !set || value == "" {
+			fmt.Printf("  ·  %s (default)\n", v.name)
+			continue
+		}
+		fmt.Printf("  ✅ %s=%s\n", v.name, v.display(value))
+	}
+
+	fmt.Println("🔌 Dependencies")
+	for _, check := range configChecks() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		start := time.Now()
+		err := check.run(ctx)
+		cancel()
+
+		switch {
+		case err == nil:
+			fmt.Printf("  ✅ %s (%s)\n", check.name, time.Since(start).Round(time.Millisecond))
+		case check.optional:
+			fmt.Printf("  ⚠️ %s (optional): %v\n", check.name, err)
+		default:
+			fmt.Printf("  ❌ %s: %v\n", check.name, err)
+			ok = false
+		}
+	}
+
+	if !ok {
+		fmt.Println("❌ Configuration check failed")
+		return false
+	}
+	fmt.Println("✅ Configuration check passed")
+	return true
+}
+
+// validate checks value against the variable's kind, an empty value means the default
+func (v configVar) validate(value string) error {
+	if value == "" {
+		if v.required {
+			return fmt.Errorf("required")
+		}
+		return nil
+	}
+
+	switch v.kind {
+	case kindInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("not an integer")
+		}
+	case kindBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("not a boolean")
+		}
+	case kindDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("not a duration (e.g. 30s, 15m, 24h)")
+		}
+	case kindURL:
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("not an absolute URL")
+		}
+	case kindEnum:
+		for _, allowed := range v.values {
+			if strings.EqualFold(value, allowed) {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %s", strings.Join(v.values, ", "))
+	}
+	return nil
+}
+
+// display returns value as printed in the report: secrets are redacted and URLs lose their
+// password
+func (v configVar) display(value string) string {
+	if value == "" {
+		return value
+	}
+	if v.secret {
+		return redact.Redacted
+	}
+	if v.kind == kindURL {
+		if parsed, err := url.Parse(value); err == nil {
+			return parsed.Redacted()
+		}
+	}
+	return value
+}
+
+// pingPostgres opens a short lived connection to dsn and pings it
+func pingPostgres(ctx context.Context, dsn string) error {
+	db, err := gorm.Open(postgres.Open(dsn+" connect_timeout=5"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	return sqlDB.PingContext(ctx)
+}
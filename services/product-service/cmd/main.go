@@ -1,18 +1,26 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"strconv"
 	"time"
 
+	"product-service/internal/apiversion"
 	"product-service/internal/cache"
 	"product-service/internal/consumers"
+	"product-service/internal/dbpool"
 	"product-service/internal/events"
 	"product-service/internal/handlers"
+	"product-service/internal/middleware"
 	"product-service/internal/models"
 	"product-service/internal/repository"
+	"product-service/internal/serviceauth"
+	"product-service/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -21,52 +29,56 @@ import (
 )
 
 var (
-	DB *gorm.DB
+	DB        *gorm.DB
+	ReplicaDB *gorm.DB // optional read replica for product listings, nil when not configured
 )
 
+// databaseSettings returns the primary's DB_* settings with their defaults
+func databaseSettings() (dbHost, dbPort, dbUser, dbPass, dbName string) {
+	return getEnv("DB_HOST", "localhost"),
+		getEnv("DB_PORT", "5432"),
+		getEnv("DB_USER", "postgres"),
+		getEnv("DB_PASSWORD", "password"),
+		getEnv("DB_NAME", "microservice_db")
+}
+
+// postgresDSN builds a PostgreSQL connection string
+func postgresDSN(host, user, pass, name, port string) string {
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+		host, user, pass, name, port,
+	)
+}
+
+// dbPoolDefaults size the primary and replica pools unless DB_MAX_OPEN_CONNS and the other
+// pool settings are set
+var dbPoolDefaults = dbpool.Config{
+	MaxOpen:     50,
+	MaxIdle:     25,
+	MaxLifetime: time.Hour,
+	MaxIdleTime: 10 * time.Minute,
+	SlowAcquire: 100 * time.Millisecond,
+}
+
 func initDB() {
 	// Load .env for main application configuration
 	if err := godotenv.Load(); err != nil {
 		log.Println("⚠️ .env file not found in main, using system env")
 	}
 
-	// Get database configuration from environment
-	dbHost := os.Getenv("DB_HOST")
-	if dbHost == "" {
-		dbHost = "localhost"
-	}
-
-	dbPort := os.Getenv("DB_PORT")
-	if dbPort == "" {
-		dbPort = "5432"
-	}
-
-	dbUser := os.Getenv("DB_USER")
-	if dbUser == "" {
-		dbUser = "postgres"
-	}
-
-	dbPass := os.Getenv("DB_PASSWORD")
-	if dbPass == "" {
-		dbPass = "password"
-	}
-
-	dbName := os.Getenv("DB_NAME")
-	if dbName == "" {
-		dbName = "microservice_db"
-	}
+	dbHost, dbPort, dbUser, dbPass, dbName := databaseSettings()
 
 	// Connection string
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		dbHost, dbUser, dbPass, dbName, dbPort,
-	)
+	dsn := postgresDSN(dbHost, dbUser, dbPass, dbName, dbPort)
 
 	// Connect to database using GORM
 	log.Printf("🔗 Connecting to database: %s@%s:%s/%s", dbUser, dbHost, dbPort, dbName)
 	
-	var errDB error
-	DB, errDB = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	errDB := waitFor("Database", func() error {
+		var err error
+		DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		return err
+	})
 	if errDB != nil {
 		log.Fatalf("❌ Failed to connect to database: %v", errDB)
 	}
@@ -82,39 +94,236 @@ func initDB() {
 
 	log.Println("✅ Database connection established successfully!")
 
+	// Size the connection pool (DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS, DB_CONN_MAX_*) below
+	// WORKER_COUNT, workers beyond it queue for a connection
+	poolConfig := dbpool.ConfigFromEnv(dbPoolDefaults)
+	dbpool.Register("primary", sqlDB, poolConfig)
+	log.Printf("✅ Database pool configured: %s", poolConfig)
+
 	// Auto migrate the models
 	log.Println("🔄 Running database migrations...")
-	if err := DB.AutoMigrate(&models.Product{}, &models.ProductImage{}, &models.User{}); err != nil {
+	if err := DB.AutoMigrate(append(models.OwnedModels(), models.ReadModels()...)...); err != nil {
 		log.Fatalf("❌ Failed to migrate database: %v", err)
 	}
 
+	migrateLegacyUsers()
+	migrateProductStores()
+	migrateImagePositions()
+
 	log.Println("✅ Database migrations completed successfully!")
+
+	initReplica(dbUser, dbPass, dbName, dbPort)
+}
+
+// initReplica opens the read replica at DB_REPLICA_HOST, the other DB_REPLICA_* settings
+// default to the primary's. It is not pinged at startup: while the replica is down reads fall
+// back to the primary, and use it again once it is back.
+func initReplica(dbUser, dbPass, dbName, dbPort string) {
+	dsn := replicaDSN(dbUser, dbPass, dbName, dbPort)
+	if dsn == "" {
+		log.Println("ℹ️ DB_REPLICA_HOST not set, all reads go to the primary database")
+		return
+	}
+
+	replica, err := gorm.Open(postgres.Open(dsn), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		log.Printf("⚠️ Invalid read replica configuration, all reads go to the primary database: %v", err)
+		return
+	}
+
+	if sqlDB, err := replica.DB(); err == nil {
+		dbpool.Register("replica", sqlDB, dbpool.ConfigFromEnv(dbPoolDefaults))
+	}
+
+	ReplicaDB = replica
+	log.Printf("✅ Read replica configured at %s:%s, product listings fall back to the primary while it is unreachable",
+		os.Getenv("DB_REPLICA_HOST"), getEnv("DB_REPLICA_PORT", dbPort))
+}
+
+// replicaDSN builds the read replica's connection string from the DB_REPLICA_* settings
+// over the primary's, or returns "" when DB_REPLICA_HOST is not set
+func replicaDSN(dbUser, dbPass, dbName, dbPort string) string {
+	host := os.Getenv("DB_REPLICA_HOST")
+	if host == "" {
+		return ""
+	}
+
+	return postgresDSN(host,
+		getEnv("DB_REPLICA_USER", dbUser),
+		getEnv("DB_REPLICA_PASSWORD", dbPass),
+		getEnv("DB_REPLICA_NAME", dbName),
+		getEnv("DB_REPLICA_PORT", dbPort),
+	)
+}
+
+// migrateLegacyUsers moves off the users table earlier versions migrated here:
+// the foreign key to it is dropped and its rows seed the user_profiles read model.
+// The legacy table itself is left in place for the operator to drop.
+func migrateLegacyUsers() {
+	if DB.Migrator().HasConstraint(&models.Product{}, "fk_products_user") {
+		if err := DB.Migrator().DropConstraint(&models.Product{}, "fk_products_user"); err != nil {
+			log.Printf("⚠️ Failed to drop legacy products -> users foreign key: %v", err)
+		} else {
+			log.Println("✅ Dropped legacy products -> users foreign key")
+		}
+	}
+
+	if !DB.Migrator().HasTable("users") {
+		return
+	}
+
+	result := DB.Exec(`INSERT INTO user_profiles (id, username, email, updated_at)
+		SELECT id, username, email, now() FROM users
+		ON CONFLICT (id) DO NOTHING`)
+	if result.Error != nil {
+		log.Printf("⚠️ Failed to backfill user profiles from legacy users table: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("✅ Backfilled %d user profiles from legacy users table", result.RowsAffected)
+	}
+}
+
+// migrateProductStores gives every seller with products created before stores existed a
+// default store and moves those products into it
+func migrateProductStores() {
+	result := DB.Exec(`INSERT INTO stores (id, owner_id, name, slug, description, is_active, created_at, updated_at)
+		SELECT gen_random_uuid(), owners.user_id,
+			COALESCE(NULLIF(up.username, ''), 'Store') || ' Store',
+			'store-' || replace(owners.user_id::text, '-', ''), '', true, now(), now()
+		FROM (SELECT DISTINCT user_id FROM products WHERE store_id IS NULL) owners
+		LEFT JOIN user_profiles up ON up.id = owners.user_id
+		WHERE NOT EXISTS (SELECT 1 FROM stores s WHERE s.owner_id = owners.user_id)`)
+	if result.Error != nil {
+		log.Printf("⚠️ Failed to create default stores for existing sellers: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("✅ Created %d default stores for existing sellers", result.RowsAffected)
+	}
+
+	result = DB.Exec(`UPDATE products SET store_id = (
+			SELECT s.id FROM stores s WHERE s.owner_id = products.user_id ORDER BY s.created_at LIMIT 1
+		) WHERE store_id IS NULL`)
+	if result.Error != nil {
+		log.Printf("⚠️ Failed to assign existing products to stores: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("✅ Assigned %d existing products to their seller's store", result.RowsAffected)
+	}
+}
+
+// migrateImagePositions numbers the images of products created before image ordering by age
+// and makes the oldest one primary, then allows only one primary image per product
+func migrateImagePositions() {
+	result := DB.Exec(`UPDATE product_images SET position = ranked.position
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY created_at, id) - 1 AS position
+			FROM product_images
+			WHERE product_id IN (SELECT product_id FROM product_images GROUP BY product_id HAVING COUNT(*) > 1 AND MAX(position) = 0)
+		) ranked
+		WHERE product_images.id = ranked.id`)
+	if result.Error != nil {
+		log.Printf("⚠️ Failed to number existing product images: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("✅ Numbered %d existing product images by age", result.RowsAffected)
+	}
+
+	result = DB.Exec(`UPDATE product_images SET is_primary = true WHERE id IN (
+			SELECT DISTINCT ON (product_id) id FROM product_images
+			WHERE product_id NOT IN (SELECT product_id FROM product_images WHERE is_primary)
+			ORDER BY product_id, position, created_at
+		)`)
+	if result.Error != nil {
+		log.Printf("⚠️ Failed to set primary images of existing products: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("✅ Set the primary image of %d existing products", result.RowsAffected)
+	}
+
+	if err := DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_product_images_primary
+		ON product_images (product_id) WHERE is_primary`).Error; err != nil {
+		log.Printf("⚠️ Failed to create primary image index: %v", err)
+	}
 }
 
 func main() {
+	// -healthcheck probes the running service, for the compose healthcheck
+	healthcheck := flag.Bool("healthcheck", false, "check /health of the running service and exit")
+	flag.Parse()
+	if *healthcheck {
+		godotenv.Load()
+		if !runHealthcheck(getEnv("PORT", "8082")) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// check-config validates the environment and dependencies, for an init container or a
+	// pre-deploy gate
+	if flag.Arg(0) == "check-config" {
+		godotenv.Load()
+		if !runCheckConfig("Product Service") {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// verify-topology compares the broker with the exchanges, queues and bindings the
+	// services expect
+	if flag.Arg(0) == "verify-topology" {
+		godotenv.Load()
+		if !runVerifyTopology("Product Service") {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize database
 	initDB()
 
-	// Get Redis configuration from environment
-	redisHost := getEnv("REDIS_HOST", "localhost:6379")
-	redisPassword := getEnv("REDIS_PASSWORD", "")
-	redisDB := getEnvAsInt("REDIS_DB", 0)
+	// Warn when queries wait for a pool connection (DB_SLOW_ACQUIRE_THRESHOLD)
+	poolMonitor := dbpool.NewMonitor(10 * time.Second)
+	poolMonitor.Start()
+	defer poolMonitor.Stop()
+
+	// Get Redis configuration from environment (REDIS_MODE standalone, sentinel or cluster)
+	redisTopology, err := cache.RedisTopologyFromEnv(redisAddr())
+	if err != nil {
+		log.Fatalf("❌ Invalid Redis configuration: %v", err)
+	}
 	
 	// Get worker pool configuration
 	workerCount := getEnvAsInt("WORKER_COUNT", 100)
 	port := getEnv("PORT", "8082")
 
 	// Connect to Redis
-	log.Printf("🔗 Connecting to Redis: %s (DB: %d)", redisHost, redisDB)
-	redisClient := cache.NewRedisClient(redisHost, redisPassword, redisDB)
+	log.Printf("🔗 Connecting to Redis: %s (DB: %d)", redisTopology, getEnvAsInt("REDIS_DB", 0))
+	redisClient := cache.NewRedisClient(redisTopology)
 	defer redisClient.Close()
-	log.Println("✅ Redis connection established successfully!")
+	if err := waitFor("Redis", func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return redisClient.Ping(ctx)
+	}); err != nil {
+		log.Printf("⚠️ %v, continuing without cache until it is reachable", err)
+	} else {
+		log.Println("✅ Redis connection established successfully!")
+	}
+
+	// Local LRU in front of Redis; invalidations reach every replica over Postgres
+	// LISTEN/NOTIFY, so they still propagate while Redis is down
+	productCache := cache.NewTieredCacheFromEnv(redisClient)
+	dbHost, dbPort, dbUser, dbPass, dbName := databaseSettings()
+	productCache.SetInvalidationBus(context.Background(), cache.NewInvalidationBus(DB, postgresDSN(dbHost, dbUser, dbPass, dbName, dbPort)))
 
 	// Create repository
 	log.Println("🏗️ Initializing product repository...")
-	productRepo := repository.NewProductRepository(DB, redisClient)
+	productRepo := repository.NewProductRepository(DB, productCache)
+	productRepo.SetReplica(ReplicaDB)
 	log.Println("✅ Product repository initialized successfully!")
 
+	// New and edited listings wait for review unless the automated checks decide (MODERATION_*)
+	moderator := services.NewModeratorFromEnv()
+	productRepo.SetModerator(moderator)
+	log.Printf("🛡️ Product moderation checks: %v", moderator.Checkers())
+
 	// Create worker pool
 	log.Printf("👥 Creating worker pool with %d workers...", workerCount)
 	workerPool := handlers.NewWorkerPool(workerCount)
@@ -124,13 +333,25 @@ func main() {
 
 	// Create handlers
 	log.Println("🎯 Initializing product handlers...")
-	productHandler := handlers.NewProductHandler(productRepo, workerPool)
+	pricingEngine := services.NewPricingEngine(productRepo)
+	productHandler := handlers.NewProductHandler(productRepo, workerPool, pricingEngine)
 	productHandler.UpdateWorkerPoolHandlers()
+
+	// Product detail views feed the conversion funnel
+	viewCounter := services.NewViewCounter(productRepo)
+	viewCounter.Start()
+	defer viewCounter.Stop()
+	productHandler.SetViewCounter(viewCounter)
 	log.Println("✅ Product handlers initialized successfully!")
 
 	// Initialize RabbitMQ Event Service
 	log.Println("🐰 Initializing RabbitMQ event service...")
-	eventSvc, err := events.NewEventService()
+	var eventSvc *events.EventService
+	err = waitFor("RabbitMQ", func() error {
+		var err error
+		eventSvc, err = events.NewEventService()
+		return err
+	})
 	if err != nil {
 		log.Fatalf("❌ Failed to initialize RabbitMQ event service: %v", err)
 	}
@@ -145,15 +366,63 @@ func main() {
 	}
 	log.Println("✅ Checkout consumer started successfully!")
 
+	// Initialize stock consumer
+	log.Println("📦 Initializing stock consumer...")
+	stockConsumer := consumers.NewStockConsumer(eventSvc, productRepo)
+	if err := stockConsumer.Start(); err != nil {
+		log.Fatalf("❌ Failed to start stock consumer: %v", err)
+	}
+	log.Println("✅ Stock consumer started successfully!")
+
+	// Initialize stock release consumer
+	log.Println("♻️ Initializing stock release consumer...")
+	stockReleaseConsumer := consumers.NewStockReleaseConsumer(eventSvc, productRepo)
+	if err := stockReleaseConsumer.Start(); err != nil {
+		log.Fatalf("❌ Failed to start stock release consumer: %v", err)
+	}
+	log.Println("✅ Stock release consumer started successfully!")
+
+	// Initialize user profile consumer
+	log.Println("👤 Initializing user profile consumer...")
+	userProfileConsumer := consumers.NewUserProfileConsumer(eventSvc, repository.NewUserProfileRepository(DB))
+	if err := userProfileConsumer.Start(); err != nil {
+		log.Fatalf("❌ Failed to start user profile consumer: %v", err)
+	}
+	log.Println("✅ User profile consumer started successfully!")
+
+	// Initialize funnel consumer
+	log.Println("📊 Initializing funnel consumer...")
+	funnelConsumer := consumers.NewFunnelConsumer(eventSvc, productRepo)
+	if err := funnelConsumer.Start(); err != nil {
+		log.Fatalf("❌ Failed to start funnel consumer: %v", err)
+	}
+	log.Println("✅ Funnel consumer started successfully!")
+
+	stockHandler := handlers.NewStockHandler(productRepo, eventSvc)
+	bulkHandler := handlers.NewBulkHandler(productRepo, eventSvc)
+	storeHandler := handlers.NewStoreHandler(productRepo)
+	imageHandler := handlers.NewImageHandler(productRepo)
+	variantHandler := handlers.NewVariantHandler(productRepo)
+	sandboxHandler := handlers.NewSandboxHandler(productRepo)
+	pricingRuleHandler := handlers.NewPricingRuleHandler(productRepo)
+	moderationHandler := handlers.NewModerationHandler(productRepo, eventSvc)
+	analyticsHandler := handlers.NewAnalyticsHandler(productRepo)
+	cacheHandler := handlers.NewCacheHandler(productCache)
+
+	// Start publish scheduler
+	publishScheduler := services.NewPublishScheduler(productRepo, eventSvc)
+	publishScheduler.Start()
+	defer publishScheduler.Stop()
+
 	// Setup Gin router
 	log.Println("🌐 Setting up HTTP server...")
-	r := gin.Default()
+	r := newRouter()
 
 	// CORS middleware
 	log.Println("🔧 Configuring CORS middleware...")
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
 
 		if c.Request.Method == "OPTIONS" {
@@ -164,21 +433,28 @@ func main() {
 		c.Next()
 	})
 
-	// Request logging middleware
-	log.Println("📝 Configuring request logging middleware...")
-	r.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s \"%s\" %s\"\n",
-			param.ClientIP,
-			param.TimeStamp.Format(time.RFC1123),
-			param.Method,
-			param.Path,
-			param.Request.Proto,
-			param.StatusCode,
-			param.Latency,
-			param.Request.UserAgent(),
-			param.ErrorMessage,
-		)
-	}))
+	// Service to service authentication (SERVICE_AUTH_SECRET): everything but health checks
+	// and admin token routes must come through the gateway or another service
+	serviceVerifier, err := serviceauth.VerifierFromEnv(serviceauth.ProductService)
+	if err != nil {
+		log.Fatalf("❌ Invalid service authentication configuration: %v", err)
+	}
+	if serviceVerifier != nil {
+		log.Println("🔐 Service authentication enabled")
+	} else if appEnv() == "production" {
+		log.Fatalf("❌ SERVICE_AUTH_SECRET is required in production")
+	} else {
+		log.Println("⚠️ SERVICE_AUTH_SECRET not set, accepting calls from anywhere")
+	}
+	r.Use(middleware.ServiceAuth(serviceVerifier,
+		"/health",
+		"/api/v1/admin/",
+		"/debug/",
+		"/metrics",
+	))
+
+	// ?nocache=true skips and refreshes the product cache (ADMIN_TOKEN in production)
+	r.Use(cacheBypass())
 
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
@@ -198,12 +474,10 @@ func main() {
 			health["database"] = "ok"
 		}
 
-		// Check Redis
-		if redisClient != nil {
-			health["redis"] = "ok"
-		} else {
-			health["redis"] = "not_configured"
-		}
+		// Check Redis and the local cache
+		cacheStatus := productCache.Status()
+		health["redis"] = cacheStatus["redis"]
+		health["cache"] = cacheStatus
 
 		// Check worker pool
 		health["worker_pool"] = gin.H{
@@ -214,31 +488,171 @@ func main() {
 		c.JSON(200, health)
 	})
 
-	// API routes
-	api := r.Group("/api/v1")
+	// Redis topology health: the sentinel master or cluster state, 503 when Redis is down
+	r.GET("/health/redis", func(c *gin.Context) {
+		state := redisClient.State(c.Request.Context())
+		status := 200
+		if state.Status == "down" {
+			status = 503
+		}
+		c.JSON(status, gin.H{
+			"service": "product-service",
+			"redis":   state,
+		})
+	})
+
+	// API routes, served under /api/v1 and /api/v2. Routes overridden in v2 are deprecated
+	// in v1 (API_V1_DEPRECATED_AT, API_V1_SUNSET).
+	api := apiversion.New(r, "v1", "v2")
+	api.Deprecate("v1", apiversion.DeprecationFromEnv("v1"))
 	{
 		// Product routes
 		products := api.Group("/products")
 		{
 			products.GET("", productHandler.GetProducts)
 			products.GET("/:id", productHandler.GetProductByID)
+			products.GET("/:id/availability", stockHandler.GetAvailability)
+			products.GET("/:id/price", productHandler.GetProductPrice)
+		}
+
+		// Store routes
+		stores := api.Group("/stores")
+		{
+			stores.GET("", storeHandler.GetStores)
+			stores.GET("/:id", storeHandler.GetStoreByID)
+		}
+
+		// Seller routes (X-User-ID set by the API Gateway)
+		seller := api.Group("/seller/products")
+		{
+			seller.GET("/:id/stock-movements", stockHandler.GetStockMovements)
+			seller.POST("/:id/stock", stockHandler.AdjustStock)
+			seller.PATCH("/bulk", bulkHandler.BulkUpdateProducts)
+			seller.PUT("/:id/store", storeHandler.AssignProductStore)
+			seller.PUT("/:id/images/order", imageHandler.ReorderImages)
+			seller.PUT("/:id/images/:image_id/primary", imageHandler.SetPrimaryImage)
+			seller.GET("/:id/variants", variantHandler.GetVariants)
+			seller.POST("/:id/variants", variantHandler.CreateVariant)
+			seller.PUT("/:id/variants/:variant_id", variantHandler.UpdateVariant)
+		}
+
+		// Seller store management (X-User-ID set by the API Gateway)
+		sellerStores := api.Group("/seller/stores")
+		{
+			sellerStores.GET("", storeHandler.GetMyStores)
+			sellerStores.POST("", storeHandler.CreateStore)
+			sellerStores.PUT("/:id", storeHandler.UpdateStore)
+			sellerStores.DELETE("/:id", storeHandler.DeleteStore)
+		}
+	}
+	api.Mount()
+
+	// Demo environment tools (SANDBOX_TOOLS, never in production)
+	sandboxEnabled := sandboxToolsEnabled()
+	if sandboxEnabled {
+		r.POST("/api/v1/dev/fixtures", sandboxHandler.CreateFixtures)
+		log.Printf("⚠️ Sandbox tools enabled, POST /api/v1/dev/fixtures creates the fixture catalog (%s)", appEnv())
+	}
+
+	// Prometheus metrics, scraped with METRICS_TOKEN as bearer token
+	if metricsToken := os.Getenv("METRICS_TOKEN"); metricsToken != "" {
+		r.GET("/metrics", metricsAuthMiddleware(metricsToken), func(c *gin.Context) {
+			c.Data(200, "text/plain; version=0.0.4; charset=utf-8", []byte(dbpool.Prometheus()))
+		})
+	} else {
+		log.Println("⚠️ METRICS_TOKEN not set, /metrics disabled")
+	}
+
+	// Debug and runtime diagnostics endpoints (admin token required)
+	registerDebugRoutes(r)
+	admin := registerAdminRoutes(r, func() gin.H {
+		return gin.H{
+			"worker_pool": gin.H{
+				"workers":        workerCount,
+				"active_jobs":    workerPool.GetActiveJobs(),
+				"queue_depth":    workerPool.GetQueueDepth(),
+				"queue_capacity": workerPool.GetQueueCapacity(),
+			},
+			"rabbitmq": gin.H{
+				"connected": eventSvc.IsConnected(),
+			},
+			"publish_scheduler": publishScheduler.Stats(),
+			"view_counter":      viewCounter.Stats(),
+		}
+	})
+	if admin != nil {
+		pricingRules := admin.Group("/pricing-rules")
+		{
+			pricingRules.GET("", pricingRuleHandler.GetPricingRules)
+			pricingRules.POST("", pricingRuleHandler.CreatePricingRule)
+			pricingRules.GET("/:id", pricingRuleHandler.GetPricingRule)
+			pricingRules.PUT("/:id", pricingRuleHandler.UpdatePricingRule)
+			pricingRules.DELETE("/:id", pricingRuleHandler.DeletePricingRule)
+		}
+
+		moderation := admin.Group("/moderation/products")
+		{
+			moderation.GET("", moderationHandler.GetModerationQueue)
+			moderation.POST("/:id/approve", moderationHandler.ApproveProduct)
+			moderation.POST("/:id/reject", moderationHandler.RejectProduct)
+		}
+
+		admin.GET("/products/:id/funnel", analyticsHandler.GetProductFunnel)
+		admin.GET("/cache", cacheHandler.GetCacheStats)
+		admin.DELETE("/cache/:namespace", cacheHandler.PurgeCache)
+
+		if sandboxEnabled {
+			admin.POST("/sandbox/reset", sandboxHandler.ResetSandbox)
 		}
 	}
 
-	log.Printf("🚀 Product Service running on http://localhost:%s", port)
+	addr := listenAddr(port)
+	log.Printf("🚀 Product Service running on http://localhost:%s (listening on %s)", port, addr)
 	log.Println("📚 API Documentation:")
 	log.Println("  GET /api/v1/products        - Get all products (with pagination)")
 	log.Println("  GET /api/v1/products/:id    - Get product by ID")
+	log.Println("  GET /api/v1/products/:id/availability?quantity=N&variant_id= - Stock pre-check before checkout")
+	log.Println("  GET /api/v1/products/:id/price?quantity=N&member=true&variant_id= - Price after pricing rules")
+	log.Println("  GET /api/v1/stores          - List active stores")
+	log.Println("  GET /api/v1/stores/:id      - Get store by ID")
+	log.Println("  GET /api/v1/seller/products/:id/stock-movements - Stock audit trail (seller)")
+	log.Println("  POST /api/v1/seller/products/:id/stock          - Restock or adjust stock (seller)")
+	log.Println("  PATCH /api/v1/seller/products/bulk              - Bulk status, price and schedule update (seller)")
+	log.Println("  PUT /api/v1/seller/products/:id/store           - Move a product to one of the seller's stores")
+	log.Println("  PUT /api/v1/seller/products/:id/images/order    - Reorder a product's images (seller)")
+	log.Println("  PUT /api/v1/seller/products/:id/images/:image_id/primary - Set the primary image (seller)")
+	log.Println("  GET|POST /api/v1/seller/products/:id/variants   - List or add variants (seller)")
+	log.Println("  PUT /api/v1/seller/products/:id/variants/:variant_id - Update or deactivate a variant (seller)")
+	log.Println("  GET|POST /api/v1/seller/stores                  - List or create the seller's stores")
+	log.Println("  PUT|DELETE /api/v1/seller/stores/:id            - Update or delete a store (seller)")
+	log.Println("  GET|POST|PUT|DELETE /api/v1/admin/pricing-rules - Manage pricing rules (admin token)")
+	log.Println("  GET /api/v1/admin/moderation/products           - Products awaiting review (admin token)")
+	log.Println("  POST /api/v1/admin/moderation/products/:id/{approve,reject} - Moderate a product (admin token)")
+	log.Println("  GET /api/v1/admin/cache                        - Cached keys and hit rates per namespace (admin token)")
+	log.Println("  DELETE /api/v1/admin/cache/:namespace?id=      - Purge a cache namespace or entry (admin token)")
+	log.Println("  POST /api/v1/admin/sandbox/reset               - Reseed the catalog (admin token, SANDBOX_TOOLS)")
+	log.Println("  POST /api/v1/dev/fixtures                       - Create the end to end test catalog (SANDBOX_TOOLS)")
 	log.Println("  GET /health                 - Health check")
+	log.Println("  GET /metrics                - Database pool metrics (METRICS_TOKEN)")
 	log.Printf("🔧 Worker pool: %d workers", workerCount)
 
 	// Start server
-	if err := r.Run(":" + port); err != nil {
+	if err := r.Run(addr); err != nil {
 		log.Fatalf("❌ Failed to start server: %v", err)
 	}
 }
 
 // Helper functions
+// redisAddr returns the Redis address: REDIS_HOST may include the port, otherwise REDIS_PORT
+// is used
+func redisAddr() string {
+	redisHost := getEnv("REDIS_HOST", "localhost:6379")
+	if _, _, err := net.SplitHostPort(redisHost); err != nil {
+		redisHost = net.JoinHostPort(redisHost, getEnv("REDIS_PORT", "6379"))
+	}
+	return redisHost
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
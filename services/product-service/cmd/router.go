@@ -0,0 +1,208 @@
+package main
+
+import (
+	"crypto/subtle"
+	"expvar"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"product-service/internal/cache"
+	"product-service/internal/i18n"
+	"product-service/internal/middleware"
+	"product-service/internal/serviceauth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// appEnv returns the current environment (development, staging or production)
+func appEnv() string {
+	env := strings.ToLower(os.Getenv("APP_ENV"))
+	if env == "" {
+		return "development"
+	}
+	return env
+}
+
+// sandboxToolsEnabled reports whether SANDBOX_TOOLS=true turns on the demo environment
+// endpoints (sandbox data reset, end to end test fixtures). Production never gets them.
+func sandboxToolsEnabled() bool {
+	if os.Getenv("SANDBOX_TOOLS") != "true" {
+		return false
+	}
+	if appEnv() == "production" {
+		log.Println("⚠️ SANDBOX_TOOLS is ignored in production")
+		return false
+	}
+	return true
+}
+
+// newRouter creates the gin engine configured for the current APP_ENV:
+// release mode and no request logging in production, debug mode (or GIN_MODE) otherwise
+func newRouter() *gin.Engine {
+	env := appEnv()
+	if env == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	} else if mode := os.Getenv("GIN_MODE"); mode != "" {
+		gin.SetMode(mode)
+	} else {
+		gin.SetMode(gin.DebugMode)
+	}
+
+	r := gin.New()
+	r.Use(gin.Recovery())
+	if env != "production" {
+		r.Use(middleware.RequestLogger())
+	}
+	r.Use(i18n.Middleware())
+
+	// Only trust X-Forwarded-For from configured proxies (e.g. the API gateway)
+	var trustedProxies []string
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		for _, proxy := range strings.Split(proxies, ",") {
+			trustedProxies = append(trustedProxies, strings.TrimSpace(proxy))
+		}
+	}
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		log.Printf("⚠️ Invalid TRUSTED_PROXIES, trusting no proxies: %v", err)
+		r.SetTrustedProxies(nil)
+	}
+
+	log.Printf("✅ Router configured for %s environment (gin mode: %s)", env, gin.Mode())
+	return r
+}
+
+// adminAuthMiddleware guards debug endpoints with the ADMIN_TOKEN shared secret
+func adminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader("X-Admin-Token")
+		if provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Admin token required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// metricsAuthMiddleware guards /metrics with the METRICS_TOKEN bearer token Prometheus scrapes with
+func metricsAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Metrics token required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// cacheBypass marks requests flagged with ?nocache=true (or 1) or Cache-Control: no-cache so
+// they skip the cached copies and refresh them, to debug stale data. In production the flag
+// is only honored with the ADMIN_TOKEN (X-Admin-Token), so it can't be used to load the
+// database, or for Payment-Service, which verifies price and stock at charge time.
+func cacheBypass() gin.HandlerFunc {
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	production := appEnv() == "production"
+
+	return func(c *gin.Context) {
+		nocache := c.Query("nocache")
+		if nocache != "true" && nocache != "1" && !strings.Contains(c.GetHeader("Cache-Control"), "no-cache") {
+			c.Next()
+			return
+		}
+		if caller, _ := c.Get("service_caller"); production && caller != serviceauth.PaymentService {
+			provided := c.GetHeader("X-Admin-Token")
+			if adminToken == "" || provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(adminToken)) != 1 {
+				c.Next()
+				return
+			}
+		}
+
+		c.Request = c.Request.WithContext(cache.WithBypass(c.Request.Context()))
+		c.Header("X-Cache-Bypass", "true")
+		c.Next()
+	}
+}
+
+// registerDebugRoutes exposes /debug/pprof and /debug/vars when ENABLE_PPROF=true.
+// The endpoints are opt-in in every environment and always require ADMIN_TOKEN.
+func registerDebugRoutes(r *gin.Engine) {
+	if os.Getenv("ENABLE_PPROF") != "true" {
+		return
+	}
+
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		log.Println("⚠️ ENABLE_PPROF is set but ADMIN_TOKEN is empty, pprof endpoints disabled")
+		return
+	}
+
+	debug := r.Group("/debug")
+	debug.Use(adminAuthMiddleware(adminToken))
+	{
+		debug.GET("/vars", gin.WrapH(expvar.Handler()))
+		debug.GET("/pprof/", gin.WrapF(pprof.Index))
+		debug.GET("/pprof/cmdline", gin.WrapF(pprof.Cmdline))
+		debug.GET("/pprof/profile", gin.WrapF(pprof.Profile))
+		debug.POST("/pprof/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/pprof/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/pprof/trace", gin.WrapF(pprof.Trace))
+		debug.GET("/pprof/:profile", func(c *gin.Context) {
+			pprof.Handler(c.Param("profile")).ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	log.Printf("⚠️ pprof endpoints enabled at /debug/pprof and /debug/vars (%s, admin token required)", appEnv())
+}
+
+// startedAt is used to report process uptime
+var startedAt = time.Now()
+
+// registerAdminRoutes exposes GET /api/v1/admin/runtime with process diagnostics.
+// serviceStats adds service specific state (worker pools, RabbitMQ connections, ...).
+// It returns the admin group for service specific admin routes, or nil when ADMIN_TOKEN is unset.
+func registerAdminRoutes(r *gin.Engine, serviceStats func() gin.H) *gin.RouterGroup {
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		return nil
+	}
+
+	admin := r.Group("/api/v1/admin")
+	admin.Use(adminAuthMiddleware(adminToken))
+	admin.GET("/runtime", func(c *gin.Context) {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		stats := gin.H{
+			"environment": appEnv(),
+			"uptime":      time.Since(startedAt).String(),
+			"go_version":  runtime.Version(),
+			"num_cpu":     runtime.NumCPU(),
+			"goroutines":  runtime.NumGoroutine(),
+			"heap": gin.H{
+				"alloc_bytes":    mem.HeapAlloc,
+				"sys_bytes":      mem.HeapSys,
+				"idle_bytes":     mem.HeapIdle,
+				"inuse_bytes":    mem.HeapInuse,
+				"objects":        mem.HeapObjects,
+				"total_alloc":    mem.TotalAlloc,
+				"num_gc":         mem.NumGC,
+				"pause_total_ns": mem.PauseTotalNs,
+			},
+		}
+
+		if serviceStats != nil {
+			for key, value := range serviceStats() {
+				stats[key] = value
+			}
+		}
+
+		c.JSON(http.StatusOK, stats)
+	})
+
+	return admin
+}
@@ -0,0 +1,132 @@
+package apiversion
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// anyMethod registers a route for every HTTP method, like gin's Any
+const anyMethod = "ANY"
+
+// Group mirrors gin.RouterGroup: middleware added with Use applies to routes added after it
+type Group struct {
+	router     *Router
+	path       string
+	middleware []gin.HandlerFunc
+}
+
+// Group returns a sub-group inheriting the group's middleware
+func (g *Group) Group(path string, handlers ...gin.HandlerFunc) *Group {
+	middleware := append(append([]gin.HandlerFunc{}, g.middleware...), handlers...)
+	return &Group{router: g.router, path: joinPath(g.path, path), middleware: middleware}
+}
+
+// Use adds middleware to the routes added to the group afterwards
+func (g *Group) Use(middleware ...gin.HandlerFunc) *Group {
+	g.middleware = append(g.middleware, middleware...)
+	return g
+}
+
+// Handle adds a route served by every version with the given handlers
+func (g *Group) Handle(method, path string, handlers ...gin.HandlerFunc) *Route {
+	route := &Route{
+		method:       method,
+		path:         joinPath(g.path, path),
+		middleware:   append([]gin.HandlerFunc{}, g.middleware...),
+		handlers:     handlers,
+		overrides:    make(map[string][]gin.HandlerFunc),
+		deprecations: make(map[string]Deprecation),
+	}
+	g.router.routes = append(g.router.routes, route)
+	return route
+}
+
+// GET is a shortcut for Handle("GET", path, handlers...)
+func (g *Group) GET(path string, handlers ...gin.HandlerFunc) *Route {
+	return g.Handle(http.MethodGet, path, handlers...)
+}
+
+// POST is a shortcut for Handle("POST", path, handlers...)
+func (g *Group) POST(path string, handlers ...gin.HandlerFunc) *Route {
+	return g.Handle(http.MethodPost, path, handlers...)
+}
+
+// PUT is a shortcut for Handle("PUT", path, handlers...)
+func (g *Group) PUT(path string, handlers ...gin.HandlerFunc) *Route {
+	return g.Handle(http.MethodPut, path, handlers...)
+}
+
+// PATCH is a shortcut for Handle("PATCH", path, handlers...)
+func (g *Group) PATCH(path string, handlers ...gin.HandlerFunc) *Route {
+	return g.Handle(http.MethodPatch, path, handlers...)
+}
+
+// DELETE is a shortcut for Handle("DELETE", path, handlers...)
+func (g *Group) DELETE(path string, handlers ...gin.HandlerFunc) *Route {
+	return g.Handle(http.MethodDelete, path, handlers...)
+}
+
+// Any adds a route matching every HTTP method
+func (g *Group) Any(path string, handlers ...gin.HandlerFunc) *Route {
+	return g.Handle(anyMethod, path, handlers...)
+}
+
+// Route is one endpoint across versions
+type Route struct {
+	method       string
+	path         string
+	middleware   []gin.HandlerFunc
+	handlers     []gin.HandlerFunc
+	overrides    map[string][]gin.HandlerFunc
+	only         []string
+	deprecations map[string]Deprecation
+}
+
+// Version replaces the route's handlers in one version. Group middleware still runs first.
+func (r *Route) Version(version string, handlers ...gin.HandlerFunc) *Route {
+	r.overrides[version] = handlers
+	return r
+}
+
+// Only limits the route to the given versions
+func (r *Route) Only(versions ...string) *Route {
+	r.only = versions
+	return r
+}
+
+// Deprecate marks the route as deprecated in one version, whether or not it is overridden
+func (r *Route) Deprecate(version string, deprecation Deprecation) *Route {
+	r.deprecations[version] = deprecation
+	return r
+}
+
+// handlersFor returns the handlers serving a version, nil when the version doesn't serve the route
+func (r *Route) handlersFor(version string) []gin.HandlerFunc {
+	if len(r.only) > 0 {
+		served := false
+		for _, v := range r.only {
+			if v == version {
+				served = true
+				break
+			}
+		}
+		if !served {
+			return nil
+		}
+	}
+	if handlers, ok := r.overrides[version]; ok {
+		return handlers
+	}
+	return r.handlers
+}
+
+func joinPath(base, path string) string {
+	if path == "" {
+		return base
+	}
+	if base == "" {
+		return path
+	}
+	return base + path
+}
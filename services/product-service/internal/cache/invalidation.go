@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// invalidationChannel is the Postgres NOTIFY channel shared by all product-service replicas
+const invalidationChannel = "product_cache_invalidation"
+
+// Invalidation names a key or a glob pattern every replica drops from its local cache
+type Invalidation struct {
+	Origin  string `json:"origin"`
+	Key     string `json:"key,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// InvalidationBus broadcasts cache invalidations over Postgres LISTEN/NOTIFY, so they reach
+// every replica even when Redis is down
+type InvalidationBus struct {
+	db        *gorm.DB
+	dsn       string
+	origin    string // identifies this replica, its own notifications are ignored
+	listening atomic.Bool
+}
+
+// NewInvalidationBus creates a bus notifying through db and listening on a dedicated
+// connection to dsn
+func NewInvalidationBus(db *gorm.DB, dsn string) *InvalidationBus {
+	return &InvalidationBus{
+		db:     db,
+		dsn:    dsn,
+		origin: uuid.New().String(),
+	}
+}
+
+// Publish notifies the other replicas of an invalidation
+func (b *InvalidationBus) Publish(ctx context.Context, inv Invalidation) error {
+	inv.Origin = b.origin
+	payload, err := json.Marshal(inv)
+	if err != nil {
+		return fmt.Errorf("failed to marshal invalidation: %w", err)
+	}
+	return b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", invalidationChannel, string(payload)).Error
+}
+
+// Listen delivers invalidations of the other replicas to apply until ctx is done, reconnecting
+// after errors. onConnect runs on every (re)connect, as notifications may have been missed.
+func (b *InvalidationBus) Listen(ctx context.Context, apply func(Invalidation), onConnect func()) {
+	interval := time.Second
+	for ctx.Err() == nil {
+		err := b.listen(ctx, apply, onConnect)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("⚠️ Cache invalidation listener disconnected, retrying in %s: %v", interval, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		if interval < 30*time.Second {
+			interval *= 2
+		}
+	}
+}
+
+// Listening reports whether the listener is connected
+func (b *InvalidationBus) Listening() bool {
+	return b.listening.Load()
+}
+
+func (b *InvalidationBus) listen(ctx context.Context, apply func(Invalidation), onConnect func()) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+invalidationChannel); err != nil {
+		return err
+	}
+	b.listening.Store(true)
+	defer b.listening.Store(false)
+	log.Printf("✅ Listening for cache invalidations on %s", invalidationChannel)
+	onConnect()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var inv Invalidation
+		if err := json.Unmarshal([]byte(notification.Payload), &inv); err != nil {
+			log.Printf("⚠️ Ignoring malformed cache invalidation %q: %v", notification.Payload, err)
+			continue
+		}
+		if inv.Origin != b.origin {
+			apply(inv)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"container/list"
+	"path"
+	"sync"
+	"time"
+)
+
+// LocalCache is a size-bounded in-memory LRU with per-entry expiry, used as the L1 in front
+// of Redis. Values are kept as JSON so callers never share decoded objects.
+type LocalCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // front is most recently used
+}
+
+type localEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLocalCache creates an LRU holding up to capacity entries; 0 or less disables it
+func NewLocalCache(capacity int) *LocalCache {
+	return &LocalCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the value of an unexpired entry
+func (l *LocalCache) Get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*localEntry)
+	if time.Now().After(entry.expiresAt) {
+		l.removeElement(elem)
+		return nil, false
+	}
+	l.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// Set stores value for ttl, evicting the least recently used entry when full
+func (l *LocalCache) Set(key string, value []byte, ttl time.Duration) {
+	if l.capacity <= 0 || ttl <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if elem, ok := l.entries[key]; ok {
+		entry := elem.Value.(*localEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&localEntry{key: key, value: value, expiresAt: expiresAt})
+	for l.order.Len() > l.capacity {
+		l.removeElement(l.order.Back())
+	}
+}
+
+// Delete removes key
+func (l *LocalCache) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[key]; ok {
+		l.removeElement(elem)
+	}
+}
+
+// DeletePattern removes every key matching the glob pattern (as in Redis KEYS)
+func (l *LocalCache) DeletePattern(pattern string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, elem := range l.entries {
+		if matched, _ := path.Match(pattern, key); matched {
+			l.removeElement(elem)
+		}
+	}
+}
+
+// Clear removes every entry
+func (l *LocalCache) Clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = make(map[string]*list.Element)
+	l.order.Init()
+}
+
+// Len returns the number of entries, expired ones included until they are touched
+func (l *LocalCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+func (l *LocalCache) removeElement(elem *list.Element) {
+	l.order.Remove(elem)
+	delete(l.entries, elem.Value.(*localEntry).key)
+}
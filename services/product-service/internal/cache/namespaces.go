@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Namespaces are the key prefixes of the catalog cache, each entry is stored as
+// <namespace>:<id or query hash>
+var Namespaces = []string{"product", "products", "availability", "pricing_rules"}
+
+// ErrUnknownNamespace is returned when purging a namespace that isn't in Namespaces
+var ErrUnknownNamespace = errors.New("unknown cache namespace")
+
+// namespaceCounters count the reads of one namespace
+type namespaceCounters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NamespaceStats describes one namespace. Keys counts its entries in Redis, hits and misses
+// are this replica's reads since it started (L1 and Redis hits together).
+type NamespaceStats struct {
+	Name    string  `json:"name"`
+	Pattern string  `json:"pattern"`
+	Keys    int     `json:"keys"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// Stats is the admin view of the cache
+type Stats struct {
+	Namespaces []NamespaceStats `json:"namespaces"`
+	Hits       int64            `json:"hits"`
+	Misses     int64            `json:"misses"`
+	HitRate    float64          `json:"hit_rate"`
+	Since      time.Time        `json:"since"`
+}
+
+// namespaceOf returns the namespace of key, the part before the first colon
+func namespaceOf(key string) string {
+	namespace, _, _ := strings.Cut(key, ":")
+	return namespace
+}
+
+// countRead records a hit or miss of key in its namespace
+func (t *TieredCache) countRead(key string, hit bool) {
+	counters, ok := t.namespaces[namespaceOf(key)]
+	if !ok {
+		return
+	}
+	if hit {
+		counters.hits.Add(1)
+	} else {
+		counters.misses.Add(1)
+	}
+}
+
+// Stats counts the keys of every namespace in Redis and reports the read counters. Keys
+// are left at 0 while Redis is degraded.
+func (t *TieredCache) Stats(ctx context.Context) (Stats, error) {
+	stats := Stats{Namespaces: []NamespaceStats{}, Since: t.started}
+	usable := t.redisUsable(ctx)
+	for _, name := range Namespaces {
+		counters := t.namespaces[name]
+		ns := NamespaceStats{
+			Name:    name,
+			Pattern: name + ":*",
+			Hits:    counters.hits.Load(),
+			Misses:  counters.misses.Load(),
+		}
+		ns.HitRate = hitRate(ns.Hits, ns.Misses)
+		if usable {
+			count, err := t.countKeys(ctx, ns.Pattern)
+			if err != nil {
+				return stats, fmt.Errorf("failed to count cached keys %s: %w", ns.Pattern, err)
+			}
+			ns.Keys = count
+		}
+
+		stats.Hits += ns.Hits
+		stats.Misses += ns.Misses
+		stats.Namespaces = append(stats.Namespaces, ns)
+	}
+	stats.HitRate = hitRate(stats.Hits, stats.Misses)
+	return stats, nil
+}
+
+// Purge removes a namespace from Redis and every replica's L1. With an id only
+// <namespace>:<id> and its variants (<namespace>:<id>:*) are removed. It returns how many
+// entries matched.
+func (t *TieredCache) Purge(ctx context.Context, namespace, id string) (int, error) {
+	if _, ok := t.namespaces[namespace]; !ok {
+		return 0, fmt.Errorf("%w %q", ErrUnknownNamespace, namespace)
+	}
+
+	pattern := namespace + ":*"
+	count := 0
+	if id != "" {
+		key := namespace + ":" + id
+		pattern = key + ":*"
+		if exists, _ := t.Exists(ctx, key); exists {
+			count++
+		}
+		if err := t.Delete(ctx, key); err != nil {
+			return 0, fmt.Errorf("failed to purge %s: %w", key, err)
+		}
+	}
+
+	if t.redisUsable(ctx) {
+		variants, _ := t.countKeys(ctx, pattern)
+		count += variants
+	}
+	if err := t.DeletePattern(ctx, pattern); err != nil {
+		return count, fmt.Errorf("failed to purge %s: %w", pattern, err)
+	}
+	log.Printf("🗑️ Purged %d %s cache entries", count, namespace)
+	return count, nil
+}
+
+// countKeys counts the Redis keys matching pattern
+func (t *TieredCache) countKeys(ctx context.Context, pattern string) (int, error) {
+	count := 0
+	err := scanKeys(ctx, t.redis.client, pattern, func(keys []string) error {
+		count += len(keys)
+		return nil
+	})
+	return count, err
+}
+
+// hitRate is hits over reads, 0 before the first read
+func hitRate(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
@@ -10,18 +10,14 @@ import (
 )
 
 type RedisClient struct {
-	client *redis.Client
+	client   redis.UniversalClient
+	topology RedisTopology
 }
 
-func NewRedisClient(addr, password string, db int) *RedisClient {
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: password,
-		DB:       db,
-	})
-
+func NewRedisClient(topology RedisTopology) *RedisClient {
 	return &RedisClient{
-		client: rdb,
+		client:   topology.NewClient(),
+		topology: topology,
 	}
 }
 
@@ -47,17 +43,12 @@ func (r *RedisClient) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
 }
 
+// DeletePattern deletes the keys matching pattern with SCAN rather than KEYS, on every
+// master of a cluster
 func (r *RedisClient) DeletePattern(ctx context.Context, pattern string) error {
-	keys, err := r.client.Keys(ctx, pattern).Result()
-	if err != nil {
-		return err
-	}
-
-	if len(keys) > 0 {
-		return r.client.Del(ctx, keys...).Err()
-	}
-
-	return nil
+	return scanKeys(ctx, r.client, pattern, func(keys []string) error {
+		return deleteKeys(ctx, r.client, keys)
+	})
 }
 
 func (r *RedisClient) Exists(ctx context.Context, key string) (bool, error) {
@@ -65,6 +56,10 @@ func (r *RedisClient) Exists(ctx context.Context, key string) (bool, error) {
 	return result > 0, err
 }
 
+func (r *RedisClient) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
 func (r *RedisClient) Close() error {
 	return r.client.Close()
 }
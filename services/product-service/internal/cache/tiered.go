@@ -0,0 +1,357 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TieredCache serves reads from a local LRU (L1) in front of Redis (L2). Invalidations are
+// applied to both and broadcast over Postgres so every replica drops its L1 copy.
+//
+// Any Redis error other than a miss marks Redis degraded for retryAfter: reads are then
+// served from L1 or fall through to the database, writes only fill L1, and deletions are
+// queued and replayed on Redis before it is read again, so it never serves entries that
+// were invalidated while it was unreachable.
+type TieredCache struct {
+	redis      *RedisClient
+	local      *LocalCache
+	localTTL   time.Duration // upper bound for L1 entries, bounds staleness after a missed notification
+	retryAfter time.Duration
+	jitter     float64          // fraction Redis expirations are randomly shortened by, see withJitter
+	bus        *InvalidationBus // nil to invalidate this replica only
+
+	mu        sync.Mutex
+	downUntil time.Time
+	pending   map[Invalidation]bool // deletions Redis missed while degraded
+
+	loadMu sync.Mutex
+	loads  map[string]*inflightLoad // misses being loaded, joined by concurrent callers
+
+	localHits atomic.Int64
+	redisHits atomic.Int64
+	misses    atomic.Int64
+	coalesced atomic.Int64
+	bypasses  atomic.Int64
+
+	namespaces map[string]*namespaceCounters // reads per namespace, see Stats
+	started    time.Time
+}
+
+// inflightLoad is a Load in progress, its result is shared with the callers that join it
+type inflightLoad struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// NewTieredCache creates a cache with local in front of redis, shortening expirations by up
+// to CACHE_TTL_JITTER (default 0.1)
+func NewTieredCache(redis *RedisClient, local *LocalCache, localTTL, retryAfter time.Duration) *TieredCache {
+	t := &TieredCache{
+		redis:      redis,
+		local:      local,
+		localTTL:   localTTL,
+		retryAfter: retryAfter,
+		jitter:     jitterFromEnv(),
+		pending:    make(map[Invalidation]bool),
+		loads:      make(map[string]*inflightLoad),
+		namespaces: make(map[string]*namespaceCounters),
+		started:    time.Now(),
+	}
+	for _, namespace := range Namespaces {
+		t.namespaces[namespace] = &namespaceCounters{}
+	}
+	return t
+}
+
+// NewTieredCacheFromEnv creates a cache in front of redis with an L1 of CACHE_L1_SIZE entries
+// (default 1000, 0 disables it) kept at most CACHE_L1_TTL (default 30s), retrying a degraded
+// Redis after CACHE_REDIS_RETRY_INTERVAL (default 5s)
+func NewTieredCacheFromEnv(redis *RedisClient) *TieredCache {
+	size := 1000
+	if value := os.Getenv("CACHE_L1_SIZE"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			size = parsed
+		}
+	}
+	return NewTieredCache(redis, NewLocalCache(size),
+		getEnvDuration("CACHE_L1_TTL", 30*time.Second),
+		getEnvDuration("CACHE_REDIS_RETRY_INTERVAL", 5*time.Second))
+}
+
+// SetInvalidationBus broadcasts invalidations on bus and applies the ones of other replicas
+// until ctx is done
+func (t *TieredCache) SetInvalidationBus(ctx context.Context, bus *InvalidationBus) {
+	t.bus = bus
+	go bus.Listen(ctx, t.applyInvalidation, t.local.Clear)
+}
+
+// Set stores value in both tiers
+func (t *TieredCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+	return t.setRaw(ctx, key, jsonData, expiration)
+}
+
+func (t *TieredCache) setRaw(ctx context.Context, key string, jsonData []byte, expiration time.Duration) error {
+	expiration = withJitter(expiration, t.jitter)
+	t.local.Set(key, jsonData, t.localExpiry(expiration))
+
+	if !t.redisUsable(ctx) {
+		return nil
+	}
+	if err := t.redis.client.Set(ctx, key, jsonData, expiration).Err(); err != nil {
+		t.markDown(err)
+		return err
+	}
+	return nil
+}
+
+// Get decodes the cached value of key into dest, filling L1 on a Redis hit. It returns
+// redis.Nil on a miss, and for every key when ctx is marked with WithBypass.
+func (t *TieredCache) Get(ctx context.Context, key string, dest interface{}) error {
+	if bypassed(ctx) {
+		t.bypasses.Add(1)
+		return redis.Nil
+	}
+
+	if data, ok := t.local.Get(key); ok {
+		t.localHits.Add(1)
+		t.countRead(key, true)
+		return json.Unmarshal(data, dest)
+	}
+
+	if !t.redisUsable(ctx) {
+		t.misses.Add(1)
+		t.countRead(key, false)
+		return redis.Nil
+	}
+	data, err := t.redis.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			t.markDown(err)
+		}
+		t.misses.Add(1)
+		t.countRead(key, false)
+		return err
+	}
+
+	t.redisHits.Add(1)
+	t.countRead(key, true)
+	t.local.Set(key, data, t.localTTL)
+	return json.Unmarshal(data, dest)
+}
+
+// Load decodes the cached value of key into dest, or caches and decodes the result of load on
+// a miss. Concurrent misses of the same key share one load, so a hot product that just got
+// invalidated reaches the database once per replica instead of once per request.
+func (t *TieredCache) Load(ctx context.Context, key string, dest interface{}, expiration time.Duration, load func() (interface{}, error)) error {
+	if err := t.Get(ctx, key, dest); err == nil {
+		return nil
+	}
+
+	t.loadMu.Lock()
+	if call, ok := t.loads[key]; ok {
+		t.loadMu.Unlock()
+		t.coalesced.Add(1)
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if call.err != nil {
+			return call.err
+		}
+		return json.Unmarshal(call.data, dest)
+	}
+	call := &inflightLoad{done: make(chan struct{})}
+	t.loads[key] = call
+	t.loadMu.Unlock()
+
+	defer func() {
+		t.loadMu.Lock()
+		delete(t.loads, key)
+		t.loadMu.Unlock()
+		close(call.done)
+	}()
+
+	value, err := load()
+	if err != nil {
+		call.err = err
+		return err
+	}
+	if call.data, call.err = json.Marshal(value); call.err != nil {
+		return fmt.Errorf("failed to marshal data: %w", call.err)
+	}
+
+	if err := t.setRaw(ctx, key, call.data, expiration); err != nil {
+		// The value is still returned, only caching it failed
+		log.Printf("⚠️ Failed to cache %s: %v", key, err)
+	}
+	return json.Unmarshal(call.data, dest)
+}
+
+// Exists reports whether key is cached in either tier
+func (t *TieredCache) Exists(ctx context.Context, key string) (bool, error) {
+	if _, ok := t.local.Get(key); ok {
+		return true, nil
+	}
+
+	if !t.redisUsable(ctx) {
+		return false, nil
+	}
+	exists, err := t.redis.Exists(ctx, key)
+	if err != nil {
+		t.markDown(err)
+	}
+	return exists, err
+}
+
+// Delete removes key from every replica and Redis
+func (t *TieredCache) Delete(ctx context.Context, key string) error {
+	return t.invalidate(ctx, Invalidation{Key: key})
+}
+
+// DeletePattern removes the keys matching pattern from every replica and Redis
+func (t *TieredCache) DeletePattern(ctx context.Context, pattern string) error {
+	return t.invalidate(ctx, Invalidation{Pattern: pattern})
+}
+
+// Status describes both tiers for the health check
+func (t *TieredCache) Status() map[string]interface{} {
+	t.mu.Lock()
+	degraded := time.Now().Before(t.downUntil) || len(t.pending) > 0
+	pending := len(t.pending)
+	t.mu.Unlock()
+
+	redisStatus := "ok"
+	if degraded {
+		redisStatus = "degraded"
+	}
+	invalidation := "local_only"
+	if t.bus != nil {
+		invalidation = "disconnected"
+		if t.bus.Listening() {
+			invalidation = "listening"
+		}
+	}
+
+	return map[string]interface{}{
+		"redis":                 redisStatus,
+		"pending_invalidations": pending,
+		"local_entries":         t.local.Len(),
+		"local_hits":            t.localHits.Load(),
+		"redis_hits":            t.redisHits.Load(),
+		"misses":                t.misses.Load(),
+		"coalesced_loads":       t.coalesced.Load(),
+		"bypassed_reads":        t.bypasses.Load(),
+		"invalidation_channel":  invalidation,
+	}
+}
+
+// invalidate applies inv locally, deletes it from Redis (queueing it for replay when Redis is
+// degraded) and then broadcasts it, so other replicas don't refill L1 from stale Redis entries
+func (t *TieredCache) invalidate(ctx context.Context, inv Invalidation) error {
+	t.applyInvalidation(inv)
+
+	var redisErr error
+	if !t.redisUsable(ctx) {
+		t.queue(inv)
+	} else if redisErr = t.deleteFromRedis(ctx, inv); redisErr != nil {
+		t.markDown(redisErr)
+		t.queue(inv)
+	}
+
+	if t.bus != nil {
+		if err := t.bus.Publish(ctx, inv); err != nil {
+			log.Printf("⚠️ Failed to broadcast cache invalidation %+v: %v", inv, err)
+			return err
+		}
+	}
+	return redisErr
+}
+
+// applyInvalidation drops inv from the local cache
+func (t *TieredCache) applyInvalidation(inv Invalidation) {
+	if inv.Pattern != "" {
+		t.local.DeletePattern(inv.Pattern)
+	} else {
+		t.local.Delete(inv.Key)
+	}
+}
+
+func (t *TieredCache) deleteFromRedis(ctx context.Context, inv Invalidation) error {
+	if inv.Pattern != "" {
+		return t.redis.DeletePattern(ctx, inv.Pattern)
+	}
+	return t.redis.Delete(ctx, inv.Key)
+}
+
+func (t *TieredCache) queue(inv Invalidation) {
+	inv.Origin = ""
+	t.mu.Lock()
+	t.pending[inv] = true
+	t.mu.Unlock()
+}
+
+// redisUsable reports whether Redis may be used, replaying queued deletions once the
+// degraded period is over
+func (t *TieredCache) redisUsable(ctx context.Context) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if time.Now().Before(t.downUntil) {
+		return false
+	}
+	for inv := range t.pending {
+		if err := t.deleteFromRedis(ctx, inv); err != nil {
+			t.markDownLocked(err)
+			return false
+		}
+		delete(t.pending, inv)
+	}
+	return true
+}
+
+func (t *TieredCache) markDown(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.markDownLocked(err)
+}
+
+func (t *TieredCache) markDownLocked(err error) {
+	if time.Now().After(t.downUntil) {
+		log.Printf("⚠️ Redis unavailable, serving from the local cache and database for %s: %v", t.retryAfter, err)
+	}
+	t.downUntil = time.Now().Add(t.retryAfter)
+}
+
+// localExpiry caps expiration at the L1 TTL
+func (t *TieredCache) localExpiry(expiration time.Duration) time.Duration {
+	if expiration <= 0 || expiration > t.localTTL {
+		return t.localTTL
+	}
+	return expiration
+}
+
+// getEnvDuration reads a duration environment variable with a default
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,239 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis topologies selected with REDIS_MODE
+const (
+	RedisStandalone = "standalone"
+	RedisSentinel   = "sentinel"
+	RedisCluster    = "cluster"
+)
+
+// RedisTopology is the Redis deployment behind the shared cache tier: a single node, a
+// master followed through Sentinel failovers, or a Cluster
+type RedisTopology struct {
+	Mode       string   `json:"mode"`
+	Addrs      []string `json:"addrs"`                 // the node, the sentinels or the cluster seed nodes
+	MasterName string   `json:"master_name,omitempty"` // sentinel only
+
+	password         string
+	sentinelPassword string
+	db               int
+}
+
+// RedisTopologyFromEnv reads REDIS_MODE (standalone by default), REDIS_ADDRS (comma
+// separated sentinel or cluster node addresses), REDIS_MASTER_NAME, REDIS_SENTINEL_PASSWORD,
+// REDIS_PASSWORD and REDIS_DB. Standalone connects to addr.
+func RedisTopologyFromEnv(addr string) (RedisTopology, error) {
+	db, _ := strconv.Atoi(os.Getenv("REDIS_DB"))
+
+	t := RedisTopology{
+		Mode:             strings.ToLower(os.Getenv("REDIS_MODE")),
+		MasterName:       os.Getenv("REDIS_MASTER_NAME"),
+		password:         os.Getenv("REDIS_PASSWORD"),
+		sentinelPassword: os.Getenv("REDIS_SENTINEL_PASSWORD"),
+		db:               db,
+	}
+	for _, addr := range strings.Split(os.Getenv("REDIS_ADDRS"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			t.Addrs = append(t.Addrs, addr)
+		}
+	}
+
+	switch t.Mode {
+	case "", RedisStandalone:
+		t.Mode = RedisStandalone
+		t.Addrs = []string{addr}
+	case RedisSentinel:
+		if len(t.Addrs) == 0 || t.MasterName == "" {
+			return t, errors.New("REDIS_MODE=sentinel needs REDIS_ADDRS and REDIS_MASTER_NAME")
+		}
+	case RedisCluster:
+		if len(t.Addrs) == 0 {
+			return t, errors.New("REDIS_MODE=cluster needs REDIS_ADDRS")
+		}
+		if t.db != 0 {
+			return t, errors.New("REDIS_DB must be 0 with REDIS_MODE=cluster")
+		}
+	default:
+		return t, fmt.Errorf("unknown REDIS_MODE %q, expected standalone, sentinel or cluster", t.Mode)
+	}
+	return t, nil
+}
+
+// String describes the topology for logs
+func (t RedisTopology) String() string {
+	switch t.Mode {
+	case RedisSentinel:
+		return fmt.Sprintf("sentinel master %s via %s", t.MasterName, strings.Join(t.Addrs, ","))
+	case RedisCluster:
+		return "cluster " + strings.Join(t.Addrs, ",")
+	default:
+		return t.Addrs[0]
+	}
+}
+
+// NewClient creates a client for the topology. Sentinel clients follow the master the
+// sentinels elect and cluster clients follow slot migrations, so failovers need no restart.
+func (t RedisTopology) NewClient() redis.UniversalClient {
+	switch t.Mode {
+	case RedisSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       t.MasterName,
+			SentinelAddrs:    t.Addrs,
+			SentinelPassword: t.sentinelPassword,
+			Password:         t.password,
+			DB:               t.db,
+		})
+	case RedisCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    t.Addrs,
+			Password: t.password,
+		})
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:     t.Addrs[0],
+			Password: t.password,
+			DB:       t.db,
+		})
+	}
+}
+
+// scanKeys calls fn with the keys matching pattern, in batches, without blocking Redis the
+// way KEYS does. A cluster is scanned on every master; fn calls are serialized.
+func scanKeys(ctx context.Context, client redis.UniversalClient, pattern string, fn func(keys []string) error) error {
+	cluster, ok := client.(*redis.ClusterClient)
+	if !ok {
+		return scanNode(ctx, client, pattern, fn)
+	}
+
+	var mu sync.Mutex
+	locked := func(keys []string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return fn(keys)
+	}
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		return scanNode(ctx, node, pattern, locked)
+	})
+}
+
+// scanNode scans the keys of one node
+func scanNode(ctx context.Context, node redis.UniversalClient, pattern string, fn func(keys []string) error) error {
+	iter := node.Scan(ctx, 0, pattern, 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == 100 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+			keys = nil
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return fn(keys)
+	}
+	return nil
+}
+
+// deleteKeys deletes keys in one round trip. Each key gets its own DEL, so keys living in
+// different cluster slots don't fail the whole command with CROSSSLOT.
+func deleteKeys(ctx context.Context, client redis.UniversalClient, keys []string) error {
+	_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	return err
+}
+
+// RedisState is the health of the topology as seen from this instance
+type RedisState struct {
+	Mode         string `json:"mode"`
+	Status       string `json:"status"` // ok, degraded (answering, but a sentinel or the cluster is unhealthy) or down
+	Error        string `json:"error,omitempty"`
+	Master       string `json:"master,omitempty"`        // standalone node or current sentinel master
+	Sentinels    string `json:"sentinels,omitempty"`     // reachable/configured
+	ClusterState string `json:"cluster_state,omitempty"` // CLUSTER INFO cluster_state
+	ClusterSize  int    `json:"cluster_size,omitempty"`  // masters serving slots
+	KnownNodes   int    `json:"known_nodes,omitempty"`
+}
+
+// State pings Redis and inspects the topology: the master the sentinels currently agree
+// on, or the cluster's state and size
+func (r *RedisClient) State(ctx context.Context) RedisState {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	t := r.topology
+	state := RedisState{Mode: t.Mode, Status: "ok"}
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		state.Status = "down"
+		state.Error = err.Error()
+	}
+
+	switch t.Mode {
+	case RedisSentinel:
+		reachable := 0
+		for _, addr := range t.Addrs {
+			sentinel := redis.NewSentinelClient(&redis.Options{Addr: addr, Password: t.sentinelPassword})
+			master, err := sentinel.GetMasterAddrByName(ctx, t.MasterName).Result()
+			sentinel.Close()
+			if err != nil || len(master) != 2 {
+				continue
+			}
+			reachable++
+			if state.Master == "" {
+				state.Master = master[0] + ":" + master[1]
+			}
+		}
+		state.Sentinels = fmt.Sprintf("%d/%d", reachable, len(t.Addrs))
+		if reachable < len(t.Addrs) && state.Status == "ok" {
+			state.Status = "degraded"
+		}
+	case RedisCluster:
+		info, err := r.client.ClusterInfo(ctx).Result()
+		if err != nil {
+			if state.Error == "" {
+				state.Error = err.Error()
+			}
+			break
+		}
+		for _, line := range strings.Split(info, "\n") {
+			key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+			if !ok {
+				continue
+			}
+			switch key {
+			case "cluster_state":
+				state.ClusterState = value
+			case "cluster_size":
+				state.ClusterSize, _ = strconv.Atoi(value)
+			case "cluster_known_nodes":
+				state.KnownNodes, _ = strconv.Atoi(value)
+			}
+		}
+		if state.ClusterState != "ok" && state.Status == "ok" {
+			state.Status = "degraded"
+		}
+	default:
+		state.Master = t.Addrs[0]
+	}
+	return state
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"time"
+)
+
+// TTLs are the Redis expirations of the cached entities
+type TTLs struct {
+	ProductList  time.Duration // products:<query>, CACHE_TTL_PRODUCT_LIST
+	Product      time.Duration // product:<id>, CACHE_TTL_PRODUCT
+	Availability time.Duration // availability:<id>, CACHE_TTL_AVAILABILITY
+	PricingRules time.Duration // pricing_rules:active, CACHE_TTL_PRICING_RULES
+}
+
+// TTLsFromEnv reads the CACHE_TTL_* durations, defaulting to 5m for product lists, 10m for
+// products, 30s for availability and 5m for pricing rules
+func TTLsFromEnv() TTLs {
+	return TTLs{
+		ProductList:  getEnvDuration("CACHE_TTL_PRODUCT_LIST", 5*time.Minute),
+		Product:      getEnvDuration("CACHE_TTL_PRODUCT", 10*time.Minute),
+		Availability: getEnvDuration("CACHE_TTL_AVAILABILITY", 30*time.Second),
+		PricingRules: getEnvDuration("CACHE_TTL_PRICING_RULES", 5*time.Minute),
+	}
+}
+
+// jitterFromEnv reads CACHE_TTL_JITTER, the fraction (0-1, default 0.1) expirations are
+// shortened by at most
+func jitterFromEnv() float64 {
+	value := os.Getenv("CACHE_TTL_JITTER")
+	if value == "" {
+		return 0.1
+	}
+	jitter, err := strconv.ParseFloat(value, 64)
+	if err != nil || jitter < 0 || jitter > 1 {
+		log.Printf("⚠️ Ignoring invalid CACHE_TTL_JITTER=%q", value)
+		return 0.1
+	}
+	return jitter
+}
+
+// withJitter shortens ttl by a random fraction up to jitter, so entries cached at the same
+// time (e.g. after a deploy or a catalog-wide invalidation) don't all expire together. The
+// configured TTL stays the upper bound of how stale an entry can get.
+func withJitter(ttl time.Duration, jitter float64) time.Duration {
+	if ttl <= 0 || jitter <= 0 {
+		return ttl
+	}
+	return ttl - time.Duration(rand.Float64()*jitter*float64(ttl))
+}
+
+type bypassKey struct{}
+
+// WithBypass marks ctx so reads miss the cache, loading fresh values that replace the cached
+// ones. It serves the nocache debugging flag.
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// bypassed reports whether ctx was marked with WithBypass
+func bypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassKey{}).(bool)
+	return bypass
+}
@@ -10,7 +10,6 @@ import (
 	"product-service/internal/repository"
 
 	"github.com/google/uuid"
-	"github.com/streadway/amqp"
 	"gorm.io/gorm"
 )
 
@@ -18,6 +17,7 @@ import (
 type CheckoutConsumer struct {
 	eventSvc *events.EventService
 	repo     *repository.ProductRepository
+	mode     events.ConsumerMode // CONSUMER_MODE_CHECKOUT
 }
 
 // NewCheckoutConsumer creates a new checkout consumer
@@ -25,104 +25,59 @@ func NewCheckoutConsumer(eventSvc *events.EventService, repo *repository.Product
 	return &CheckoutConsumer{
 		eventSvc: eventSvc,
 		repo:     repo,
+		mode:     events.ConsumerModeFromEnv("checkout"),
 	}
 }
 
 // Start starts consuming checkout events
 func (cc *CheckoutConsumer) Start() error {
-	channel := cc.eventSvc.GetChannel()
-	
-	// Declare queue for checkout events
-	queueName := "product.checkout.queue"
-	_, err := channel.QueueDeclare(
-		queueName, // name
-		true,      // durable
-		false,     // delete when unused
-		false,     // exclusive
-		false,     // no-wait
-		nil,       // arguments
-	)
+	// Consume checkout.init events from Payment-Service
+	err := cc.eventSvc.Subscribe(cc.mode.Queue("product.checkout.queue"), []events.Binding{
+		{Exchange: "payment.events", RoutingKey: "checkout.init"},
+	}, cc.processMessage)
 	if err != nil {
-		return fmt.Errorf("failed to declare queue: %w", err)
+		return fmt.Errorf("failed to subscribe to checkout events: %w", err)
 	}
 
-	// Bind queue to payment.events exchange with checkout.init routing key
-	err = channel.QueueBind(
-		queueName,           // queue name
-		"checkout.init",     // routing key
-		"payment.events",    // exchange
-		false,               // no-wait
-		nil,                 // arguments
-	)
-	if err != nil {
-		return fmt.Errorf("failed to bind queue: %w", err)
-	}
-
-	// Set QoS to process one message at a time
-	err = channel.Qos(1, 0, false)
-	if err != nil {
-		return fmt.Errorf("failed to set QoS: %w", err)
-	}
-
-	// Start consuming messages
-	msgs, err := channel.Consume(
-		queueName, // queue
-		"",        // consumer
-		false,     // auto-ack
-		false,     // exclusive
-		false,     // no-local
-		false,     // no-wait
-		nil,       // args
-	)
-	if err != nil {
-		return fmt.Errorf("failed to register consumer: %w", err)
-	}
-
-	log.Println("🚀 Product-Service checkout consumer started")
-
-	// Process messages in a goroutine
-	go func() {
-		for msg := range msgs {
-			cc.processMessage(msg)
-		}
-	}()
+	log.Printf("🚀 Product-Service checkout consumer started (%s)", cc.mode)
 
 	return nil
 }
 
 // processMessage processes a single message
-func (cc *CheckoutConsumer) processMessage(msg amqp.Delivery) {
+func (cc *CheckoutConsumer) processMessage(msg events.Message) error {
 	log.Printf("📨 Received checkout event: %s", msg.RoutingKey)
 
 	// Parse the event
 	var event events.Event
 	if err := json.Unmarshal(msg.Body, &event); err != nil {
 		log.Printf("❌ Failed to unmarshal event: %v", err)
-		msg.Nack(false, false) // Reject message without requeue
-		return
+		return fmt.Errorf("%w: %v", events.ErrReject, err) // Reject message without requeue
 	}
 
+	effects := events.NewEffects("checkout", cc.mode, msg)
+	defer effects.Log()
+
 	// Handle different event types
 	switch event.Type {
 	case "checkout.init":
-		cc.handleCheckoutInit(event)
+		cc.handleCheckoutInit(event, effects)
 	default:
 		log.Printf("⚠️ Unknown event type: %s", event.Type)
 	}
 
-	// Acknowledge message
-	msg.Ack(false)
+	return nil
 }
 
 // handleCheckoutInit handles checkout initialization event
-func (cc *CheckoutConsumer) handleCheckoutInit(event events.Event) {
+func (cc *CheckoutConsumer) handleCheckoutInit(event events.Event, effects *events.Effects) {
 	log.Printf("🛒 Processing checkout init event")
 
 	// Parse checkout data
 	checkoutData, ok := event.Data.(map[string]interface{})
 	if !ok {
 		log.Printf("❌ Invalid checkout data format")
-		cc.sendValidationResponse("", "", "", "OUT_OF_STOCK", "Invalid checkout data format", 0)
+		cc.sendValidationResponse(effects, "", "", "", "OUT_OF_STOCK", "Invalid checkout data format", 0)
 		return
 	}
 
@@ -134,7 +89,7 @@ func (cc *CheckoutConsumer) handleCheckoutInit(event events.Event) {
 
 	if paymentID == "" || orderID == "" || productIDStr == "" {
 		log.Printf("❌ Missing required fields in checkout data")
-		cc.sendValidationResponse(paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Missing required fields", 0)
+		cc.sendValidationResponse(effects, paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Missing required fields", 0)
 		return
 	}
 
@@ -142,19 +97,19 @@ func (cc *CheckoutConsumer) handleCheckoutInit(event events.Event) {
 	productID, err := uuid.Parse(productIDStr)
 	if err != nil {
 		log.Printf("❌ Invalid product ID: %v", err)
-		cc.sendValidationResponse(paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Invalid product ID", 0)
+		cc.sendValidationResponse(effects, paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Invalid product ID", 0)
 		return
 	}
 
 	// Get product from database directly (bypassing cache to avoid Redis issues)
 	var product models.Product
-	if err := cc.repo.GetDB().Preload("User").Preload("Images").First(&product, "id = ?", productID).Error; err != nil {
+	if err := cc.repo.GetDB().Preload("User").Preload("Images").Preload("Variants").First(&product, "id = ?", productID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			log.Printf("❌ Product not found: %s", productIDStr)
-			cc.sendValidationResponse(paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Product not found", 0)
+			cc.sendValidationResponse(effects, paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Product not found", 0)
 		} else {
 			log.Printf("❌ Failed to get product: %v", err)
-			cc.sendValidationResponse(paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Database error", 0)
+			cc.sendValidationResponse(effects, paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Database error", 0)
 		}
 		return
 	}
@@ -162,7 +117,14 @@ func (cc *CheckoutConsumer) handleCheckoutInit(event events.Event) {
 	// Check if product is active
 	if !product.IsActive {
 		log.Printf("❌ Product is not active: %s", productIDStr)
-		cc.sendValidationResponse(paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Product is not active", product.Stock)
+		cc.sendValidationResponse(effects, paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Product is not active", product.Stock)
+		return
+	}
+
+	// Check the listing passed moderation
+	if product.ModerationStatus != models.ModerationApproved {
+		log.Printf("❌ Product is not approved (%s): %s", product.ModerationStatus, productIDStr)
+		cc.sendValidationResponse(effects, paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Product is not approved", product.Stock)
 		return
 	}
 
@@ -172,19 +134,42 @@ func (cc *CheckoutConsumer) handleCheckoutInit(event events.Event) {
 		requiredQuantity = 1 // Default to 1 if not specified
 	}
 
-	if product.Stock < requiredQuantity {
-		log.Printf("❌ Insufficient stock: required %d, available %d", requiredQuantity, product.Stock)
-		cc.sendValidationResponse(paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Insufficient stock", product.Stock)
+	// Products with variants are bought per variant, from the variant's stock
+	stock := product.Stock
+	variantIDStr, _ := checkoutData["variant_id"].(string)
+	if variantIDStr != "" {
+		variantID, err := uuid.Parse(variantIDStr)
+		if err != nil {
+			log.Printf("❌ Invalid variant ID: %v", err)
+			cc.sendValidationResponse(effects, paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Invalid variant ID", 0)
+			return
+		}
+		variant := models.FindVariant(product.Variants, variantID)
+		if variant == nil || !variant.IsActive {
+			log.Printf("❌ Variant %s of product %s not found or inactive", variantIDStr, productIDStr)
+			cc.sendValidationResponse(effects, paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Variant is not available", 0)
+			return
+		}
+		stock = variant.Stock
+	} else if len(models.ActiveVariants(product.Variants)) > 0 {
+		log.Printf("❌ No variant chosen for product %s", productIDStr)
+		cc.sendValidationResponse(effects, paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Variant required", 0)
+		return
+	}
+
+	if stock < requiredQuantity {
+		log.Printf("❌ Insufficient stock: required %d, available %d", requiredQuantity, stock)
+		cc.sendValidationResponse(effects, paymentID, orderID, productIDStr, "OUT_OF_STOCK", "Insufficient stock", stock)
 		return
 	}
 
 	// Product validation successful
-	log.Printf("✅ Product validation successful: %s (stock: %d)", productIDStr, product.Stock)
-	cc.sendValidationResponse(paymentID, orderID, productIDStr, "PRODUCT_OK", "Product validation successful", product.Stock)
+	log.Printf("✅ Product validation successful: %s (stock: %d)", productIDStr, stock)
+	cc.sendValidationResponse(effects, paymentID, orderID, productIDStr, "PRODUCT_OK", "Product validation successful", stock)
 }
 
 // sendValidationResponse sends validation response back to payment service
-func (cc *CheckoutConsumer) sendValidationResponse(paymentID, orderID, productID, status, message string, stock int) {
+func (cc *CheckoutConsumer) sendValidationResponse(effects *events.Effects, paymentID, orderID, productID, status, message string, stock int) {
 	response := events.ProductValidationResponse{
 		PaymentID: paymentID,
 		OrderID:   orderID,
@@ -194,9 +179,12 @@ func (cc *CheckoutConsumer) sendValidationResponse(paymentID, orderID, productID
 		Stock:     stock,
 	}
 
-	if err := cc.eventSvc.PublishProductValidationResponse(response); err != nil {
+	err := effects.Apply(events.Change{Action: "publish", Target: "product.validation.response/" + paymentID, After: response}, func() error {
+		return cc.eventSvc.PublishProductValidationResponse(response)
+	})
+	if err != nil {
 		log.Printf("❌ Failed to publish validation response: %v", err)
-	} else {
+	} else if !effects.Shadow() {
 		log.Printf("📤 Published validation response: %s for product %s", status, productID)
 	}
 }
@@ -0,0 +1,129 @@
+package consumers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"product-service/internal/events"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// FunnelConsumer counts checkouts and paid orders per product for the conversion funnel
+type FunnelConsumer struct {
+	eventSvc *events.EventService
+	repo     *repository.ProductRepository
+	mode     events.ConsumerMode // CONSUMER_MODE_FUNNEL
+}
+
+// NewFunnelConsumer creates a new funnel consumer
+func NewFunnelConsumer(eventSvc *events.EventService, repo *repository.ProductRepository) *FunnelConsumer {
+	return &FunnelConsumer{
+		eventSvc: eventSvc,
+		repo:     repo,
+		mode:     events.ConsumerModeFromEnv("funnel"),
+	}
+}
+
+// Start starts consuming checkout and payment events published by Payment-Service, on a queue
+// of its own so counting never delays checkout validation
+func (fc *FunnelConsumer) Start() error {
+	err := fc.eventSvc.Subscribe(fc.mode.Queue("product.funnel.queue"), []events.Binding{
+		{Exchange: "payment.events", RoutingKey: "checkout.init"},
+		{Exchange: "payment.events", RoutingKey: "payment.success"},
+	}, fc.processMessage)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to funnel events: %w", err)
+	}
+
+	log.Printf("🚀 Product-Service funnel consumer started (%s)", fc.mode)
+
+	return nil
+}
+
+// processMessage processes a single message
+func (fc *FunnelConsumer) processMessage(msg events.Message) error {
+	var event events.Event
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Printf("❌ Failed to unmarshal event: %v", err)
+		return fmt.Errorf("%w: %v", events.ErrReject, err)
+	}
+
+	data, ok := event.Data.(map[string]interface{})
+	if !ok {
+		log.Printf("❌ Invalid funnel event data format")
+		return fmt.Errorf("%w: invalid event data format", events.ErrReject)
+	}
+
+	productIDStr, _ := data["product_id"].(string)
+	if productIDStr == "" {
+		// Payments without a product aren't part of any product's funnel
+		return nil
+	}
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		log.Printf("❌ Invalid product ID in %s: %q", event.Type, productIDStr)
+		return fmt.Errorf("%w: invalid product ID", events.ErrReject)
+	}
+
+	at := time.Now()
+	if event.Timestamp > 0 {
+		at = time.Unix(event.Timestamp, 0)
+	}
+
+	var step, ref string
+	var revenue int64
+	switch event.Type {
+	case "checkout.init":
+		step = models.FunnelStepCheckout
+		ref, _ = data["payment_id"].(string)
+	case "payment.success":
+		// Retried attempts of one order count as one paid order
+		step = models.FunnelStepPayment
+		ref, _ = data["order_id"].(string)
+		if total, ok := data["total_amount"].(float64); ok {
+			revenue = int64(total)
+		}
+		if paidAt, _ := data["paid_at"].(string); paidAt != "" {
+			if parsed, err := time.Parse(time.RFC3339, paidAt); err == nil {
+				at = parsed
+			}
+		}
+	default:
+		log.Printf("⚠️ Unknown event type: %s", event.Type)
+		return nil
+	}
+
+	if ref == "" {
+		log.Printf("❌ Missing reference in %s for product %s", event.Type, productIDStr)
+		return fmt.Errorf("%w: missing reference", events.ErrReject)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	effects := events.NewEffects("funnel", fc.mode, msg)
+	defer effects.Log()
+	if effects.Shadow() {
+		ctx = repository.DryRun(ctx)
+	}
+
+	recorded, err := fc.repo.RecordFunnelStep(ctx, step, ref, productID, at, revenue)
+	if err != nil {
+		log.Printf("❌ Failed to count %s %s of product %s: %v", step, ref, productIDStr, err)
+		return err
+	}
+	if recorded {
+		effects.Record(events.Change{
+			Action: "increment",
+			Target: fmt.Sprintf("product_funnel/%s/%s.%s", productIDStr, at.UTC().Format("2006-01-02"), step),
+			After:  map[string]interface{}{"ref": ref, "revenue": revenue},
+		})
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package consumers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"product-service/internal/events"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// StockConsumer applies stock changes from completed orders
+type StockConsumer struct {
+	eventSvc *events.EventService
+	repo     *repository.ProductRepository
+	mode     events.ConsumerMode // CONSUMER_MODE_STOCK
+}
+
+// NewStockConsumer creates a new stock consumer
+func NewStockConsumer(eventSvc *events.EventService, repo *repository.ProductRepository) *StockConsumer {
+	return &StockConsumer{
+		eventSvc: eventSvc,
+		repo:     repo,
+		mode:     events.ConsumerModeFromEnv("stock"),
+	}
+}
+
+// Start starts consuming stock reduction events published by Payment-Service
+func (sc *StockConsumer) Start() error {
+	err := sc.eventSvc.Subscribe(sc.mode.Queue("product.stock.queue"), []events.Binding{
+		{Exchange: "product.events", RoutingKey: "product.stock.reduced"},
+	}, sc.processMessage, events.OrderedByDataField("product_id"))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to stock events: %w", err)
+	}
+
+	log.Printf("🚀 Product-Service stock consumer started (%s)", sc.mode)
+
+	return nil
+}
+
+// processMessage processes a single message
+func (sc *StockConsumer) processMessage(msg events.Message) error {
+	log.Printf("📨 Received stock event: %s", msg.RoutingKey)
+
+	// Parse the event
+	var event events.Event
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Printf("❌ Failed to unmarshal event: %v", err)
+		return fmt.Errorf("%w: %v", events.ErrReject, err) // Reject message without requeue
+	}
+
+	stockData, ok := event.Data.(map[string]interface{})
+	if !ok {
+		log.Printf("❌ Invalid stock data format")
+		return fmt.Errorf("%w: invalid stock data format", events.ErrReject)
+	}
+
+	productIDStr, _ := stockData["product_id"].(string)
+	orderID, _ := stockData["order_id"].(string)
+	quantity, _ := stockData["quantity"].(float64)
+
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil || quantity <= 0 {
+		log.Printf("❌ Invalid stock reduction: product %q, quantity %v", productIDStr, quantity)
+		return fmt.Errorf("%w: invalid stock reduction", events.ErrReject)
+	}
+
+	movement := &models.StockMovement{
+		Delta:     -int(quantity),
+		Reason:    models.StockReasonSale,
+		ActorType: models.StockActorSystem,
+	}
+	if orderID != "" {
+		movement.OrderID = &orderID
+	}
+	if variantIDStr, _ := stockData["variant_id"].(string); variantIDStr != "" {
+		variantID, err := uuid.Parse(variantIDStr)
+		if err != nil {
+			log.Printf("❌ Invalid stock reduction: variant %q", variantIDStr)
+			return fmt.Errorf("%w: invalid stock reduction", events.ErrReject)
+		}
+		movement.VariantID = &variantID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	effects := events.NewEffects("stock", sc.mode, msg)
+	defer effects.Log()
+	if effects.Shadow() {
+		ctx = repository.DryRun(ctx)
+	}
+
+	if err := sc.repo.AdjustStock(ctx, productID, movement); err != nil {
+		if errors.Is(err, repository.ErrSaleAlreadyRecorded) {
+			// Redelivered or published twice for the same order, the stock is already reduced
+			log.Printf("ℹ️ Stock of product %s already reduced for order %s, skipping", productIDStr, orderID)
+			return nil
+		}
+		if errors.Is(err, repository.ErrInsufficientStock) || errors.Is(err, repository.ErrVariantNotFound) || err.Error() == "product not found" {
+			// The sale already happened, retrying cannot fix it; keep it visible in the logs
+			log.Printf("⚠️ Could not record sale of product %s for order %s: %v", productIDStr, orderID, err)
+			return fmt.Errorf("%w: %v", events.ErrReject, err)
+		}
+		log.Printf("❌ Failed to reduce stock for product %s: %v", productIDStr, err)
+		return err
+	}
+	effects.Record(stockChange(movement))
+
+	err = effects.Apply(events.Change{Action: "publish", Target: "product.updated/" + productIDStr, After: movement.StockAfter}, func() error {
+		return sc.eventSvc.PublishStockUpdated(productIDStr, movement.VariantID, movement.StockAfter, movement.Reason)
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to publish product.updated for %s: %v", productIDStr, err)
+	}
+	if effects.Shadow() {
+		return nil
+	}
+
+	log.Printf("📦 Stock reduced for product %s by %d (order: %s, stock: %d -> %d)", productIDStr, int(quantity), orderID, movement.StockBefore, movement.StockAfter)
+	return nil
+}
+
+// stockChange describes a stock movement as the change of the product's stock
+func stockChange(movement *models.StockMovement) events.Change {
+	return events.Change{
+		Action: "update",
+		Target: "product/" + movement.ProductID.String() + ".stock",
+		Before: movement.StockBefore,
+		After:  movement.StockAfter,
+	}
+}
@@ -0,0 +1,119 @@
+package consumers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"product-service/internal/events"
+	"product-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// StockReleaseConsumer restores stock held by orders whose payment failed or expired
+type StockReleaseConsumer struct {
+	eventSvc *events.EventService
+	repo     *repository.ProductRepository
+	mode     events.ConsumerMode // CONSUMER_MODE_STOCK_RELEASE
+}
+
+// NewStockReleaseConsumer creates a new stock release consumer
+func NewStockReleaseConsumer(eventSvc *events.EventService, repo *repository.ProductRepository) *StockReleaseConsumer {
+	return &StockReleaseConsumer{
+		eventSvc: eventSvc,
+		repo:     repo,
+		mode:     events.ConsumerModeFromEnv("stock_release"),
+	}
+}
+
+// Start starts consuming failed payment events published by Payment-Service. Cancelled and
+// expired payments are published as payment.failed with the status as failure_reason;
+// payment.expired is bound as well for publishers that send it separately.
+func (sc *StockReleaseConsumer) Start() error {
+	err := sc.eventSvc.Subscribe(sc.mode.Queue("product.stock_release.queue"), []events.Binding{
+		{Exchange: "payment.events", RoutingKey: "payment.failed"},
+		{Exchange: "payment.events", RoutingKey: "payment.expired"},
+	}, sc.processMessage, events.OrderedByDataField("product_id"))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to payment failure events: %w", err)
+	}
+
+	log.Printf("🚀 Product-Service stock release consumer started (%s)", sc.mode)
+
+	return nil
+}
+
+// processMessage processes a single message
+func (sc *StockReleaseConsumer) processMessage(msg events.Message) error {
+	var event events.Event
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Printf("❌ Failed to unmarshal event: %v", err)
+		return fmt.Errorf("%w: %v", events.ErrReject, err)
+	}
+
+	paymentData, ok := event.Data.(map[string]interface{})
+	if !ok {
+		log.Printf("❌ Invalid payment data format")
+		return fmt.Errorf("%w: invalid payment data format", events.ErrReject)
+	}
+
+	productIDStr, _ := paymentData["product_id"].(string)
+	orderID, _ := paymentData["order_id"].(string)
+	reason, _ := paymentData["failure_reason"].(string)
+	if productIDStr == "" {
+		// Payments without a product never held stock
+		return nil
+	}
+
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil || orderID == "" {
+		log.Printf("❌ Invalid payment failure: product %q, order %q", productIDStr, orderID)
+		return fmt.Errorf("%w: invalid payment failure", events.ErrReject)
+	}
+
+	note := fmt.Sprintf("%s: payment %s", msg.RoutingKey, reason)
+	if reason == "" {
+		note = msg.RoutingKey
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	effects := events.NewEffects("stock_release", sc.mode, msg)
+	defer effects.Log()
+	if effects.Shadow() {
+		ctx = repository.DryRun(ctx)
+	}
+
+	movement, err := sc.repo.RestoreOrderStock(ctx, productID, orderID, note)
+	if err != nil {
+		if err.Error() == "product not found" || errors.Is(err, repository.ErrVariantNotFound) {
+			log.Printf("⚠️ Could not restore stock of deleted product %s for order %s: %v", productIDStr, orderID, err)
+			return fmt.Errorf("%w: %v", events.ErrReject, err)
+		}
+		log.Printf("❌ Failed to restore stock for order %s: %v", orderID, err)
+		return err
+	}
+	if movement == nil {
+		// Nothing was reserved or sold for the order, or it was restored already
+		return nil
+	}
+	effects.Record(stockChange(movement))
+
+	err = effects.Apply(events.Change{Action: "publish", Target: "product.updated/" + productIDStr, After: movement.StockAfter}, func() error {
+		return sc.eventSvc.PublishStockUpdated(productIDStr, movement.VariantID, movement.StockAfter, movement.Reason)
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to publish product.updated for %s: %v", productIDStr, err)
+	}
+	if effects.Shadow() {
+		return nil
+	}
+
+	log.Printf("♻️ Stock restored for product %s by %d (order: %s, %s, stock: %d -> %d)", productIDStr, movement.Delta, orderID, note, movement.StockBefore, movement.StockAfter)
+	return nil
+}
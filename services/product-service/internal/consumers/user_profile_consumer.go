@@ -0,0 +1,99 @@
+package consumers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"product-service/internal/events"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// UserProfileConsumer keeps the user read model in sync with User-Service events
+type UserProfileConsumer struct {
+	eventSvc *events.EventService
+	repo     *repository.UserProfileRepository
+	mode     events.ConsumerMode // CONSUMER_MODE_USER_PROFILE
+}
+
+// NewUserProfileConsumer creates a new user profile consumer
+func NewUserProfileConsumer(eventSvc *events.EventService, repo *repository.UserProfileRepository) *UserProfileConsumer {
+	return &UserProfileConsumer{
+		eventSvc: eventSvc,
+		repo:     repo,
+		mode:     events.ConsumerModeFromEnv("user_profile"),
+	}
+}
+
+// Start starts consuming user events
+func (uc *UserProfileConsumer) Start() error {
+	err := uc.eventSvc.Subscribe(uc.mode.Queue("product.user_profile.queue"), []events.Binding{
+		{Exchange: "user.events", RoutingKey: "user.registered"},
+		{Exchange: "user.events", RoutingKey: "user.verified"},
+		{Exchange: "user.events", RoutingKey: "user.updated"},
+	}, uc.processMessage, events.OrderedByDataField("user_id"))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to user events: %w", err)
+	}
+
+	log.Printf("🚀 Product-Service user profile consumer started (%s)", uc.mode)
+	return nil
+}
+
+// processMessage upserts the profile carried by a user event
+func (uc *UserProfileConsumer) processMessage(msg events.Message) error {
+	var event events.Event
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Printf("❌ Failed to unmarshal user event: %v", err)
+		return fmt.Errorf("%w: %v", events.ErrReject, err)
+	}
+
+	userData, ok := event.Data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: invalid user data format", events.ErrReject)
+	}
+
+	userIDStr, _ := userData["user_id"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return fmt.Errorf("%w: invalid user_id %q", events.ErrReject, userIDStr)
+	}
+
+	username, _ := userData["username"].(string)
+	email, _ := userData["email"].(string)
+
+	effects := events.NewEffects("user_profile", uc.mode, msg)
+	defer effects.Log()
+
+	profile := &models.UserProfile{ID: userID, Username: username, Email: email}
+	err = effects.Apply(profileChange(uc.repo, profile), func() error {
+		return uc.repo.Upsert(profile)
+	})
+	if err != nil {
+		log.Printf("❌ Failed to update user profile %s: %v", userIDStr, err)
+		return err
+	}
+	if effects.Shadow() {
+		return nil
+	}
+
+	log.Printf("👤 User profile %s updated from %s", userIDStr, msg.RoutingKey)
+	return nil
+}
+
+// profileChange describes the upsert of profile against the stored profile
+func profileChange(repo *repository.UserProfileRepository, profile *models.UserProfile) events.Change {
+	change := events.Change{
+		Action: "insert",
+		Target: "user_profile/" + profile.ID.String(),
+		After:  map[string]string{"username": profile.Username, "email": profile.Email},
+	}
+	if stored, err := repo.GetByID(profile.ID); err == nil {
+		change.Action = "update"
+		change.Before = map[string]string{"username": stored.Username, "email": stored.Email}
+	}
+	return change
+}
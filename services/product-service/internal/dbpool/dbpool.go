@@ -0,0 +1,201 @@
+// Package dbpool sizes the Postgres connection pools from the environment and reports how
+// they are used: Prometheus gauges and counters for /metrics, and a warning log when queries
+// wait long for a connection, the sign of a pool smaller than the work sent to it.
+package dbpool
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config sizes a pool. Zero values keep the database/sql defaults (unlimited open
+// connections, 2 idle, no lifetime limits).
+type Config struct {
+	MaxOpen     int
+	MaxIdle     int
+	MaxLifetime time.Duration
+	MaxIdleTime time.Duration
+	SlowAcquire time.Duration // average connection wait logged as slow, 0 disables the warning
+}
+
+// ConfigFromEnv reads DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS, DB_CONN_MAX_LIFETIME,
+// DB_CONN_MAX_IDLE_TIME and DB_SLOW_ACQUIRE_THRESHOLD over defaults. Invalid values are
+// logged and the default kept.
+func ConfigFromEnv(defaults Config) Config {
+	cfg := defaults
+	envInt("DB_MAX_OPEN_CONNS", &cfg.MaxOpen)
+	envInt("DB_MAX_IDLE_CONNS", &cfg.MaxIdle)
+	envDuration("DB_CONN_MAX_LIFETIME", &cfg.MaxLifetime)
+	envDuration("DB_CONN_MAX_IDLE_TIME", &cfg.MaxIdleTime)
+	envDuration("DB_SLOW_ACQUIRE_THRESHOLD", &cfg.SlowAcquire)
+	if cfg.MaxOpen > 0 && cfg.MaxIdle > cfg.MaxOpen {
+		cfg.MaxIdle = cfg.MaxOpen
+	}
+	return cfg
+}
+
+func envInt(key string, dest *int) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		log.Printf("⚠️ Ignoring invalid %s=%q", key, value)
+		return
+	}
+	*dest = parsed
+}
+
+func envDuration(key string, dest *time.Duration) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		log.Printf("⚠️ Ignoring invalid %s=%q", key, value)
+		return
+	}
+	*dest = parsed
+}
+
+// String describes the configuration for logs
+func (c Config) String() string {
+	maxOpen := "unlimited"
+	if c.MaxOpen > 0 {
+		maxOpen = strconv.Itoa(c.MaxOpen)
+	}
+	return fmt.Sprintf("max open %s, max idle %d, lifetime %s, idle time %s", maxOpen, c.MaxIdle, c.MaxLifetime, c.MaxIdleTime)
+}
+
+// pool is a registered connection pool
+type pool struct {
+	name string
+	db   *sql.DB
+	cfg  Config
+
+	// Wait totals at the previous check, to average the waits of the last interval
+	lastWaitCount    int64
+	lastWaitDuration time.Duration
+}
+
+var (
+	mu    sync.Mutex
+	pools []*pool
+)
+
+// Register applies cfg to db and reports the pool under name (primary, replica) on
+// Prometheus and to the Monitor
+func Register(name string, db *sql.DB, cfg Config) {
+	db.SetMaxOpenConns(cfg.MaxOpen)
+	db.SetMaxIdleConns(cfg.MaxIdle)
+	db.SetConnMaxLifetime(cfg.MaxLifetime)
+	db.SetConnMaxIdleTime(cfg.MaxIdleTime)
+
+	mu.Lock()
+	defer mu.Unlock()
+	pools = append(pools, &pool{name: name, db: db, cfg: cfg})
+}
+
+// Prometheus writes the stats of the registered pools in the Prometheus text format
+func Prometheus() string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	stats := make([]sql.DBStats, len(pools))
+	for i, p := range pools {
+		stats[i] = p.db.Stats()
+	}
+
+	var b strings.Builder
+	write := func(name, kind, help string, value func(sql.DBStats) float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for i, p := range pools {
+			fmt.Fprintf(&b, "%s{pool=%q} %g\n", name, p.name, value(stats[i]))
+		}
+	}
+
+	write("db_pool_max_open_connections", "gauge", "Maximum open connections, 0 for unlimited.",
+		func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	write("db_pool_open_connections", "gauge", "Open connections, in use and idle.",
+		func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	write("db_pool_in_use_connections", "gauge", "Connections running a query or transaction.",
+		func(s sql.DBStats) float64 { return float64(s.InUse) })
+	write("db_pool_idle_connections", "gauge", "Idle connections.",
+		func(s sql.DBStats) float64 { return float64(s.Idle) })
+	write("db_pool_wait_count_total", "counter", "Connection requests that waited for a free connection.",
+		func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+	write("db_pool_wait_duration_seconds_total", "counter", "Time spent waiting for a free connection.",
+		func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+	write("db_pool_closed_max_idle_total", "counter", "Connections closed by the max idle limit.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) })
+	write("db_pool_closed_max_idle_time_total", "counter", "Connections closed by the max idle time.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) })
+	write("db_pool_closed_max_lifetime_total", "counter", "Connections closed by the max lifetime.",
+		func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) })
+	return b.String()
+}
+
+// Monitor checks the registered pools every interval and logs a warning when the
+// connection requests that had to wait since the last check waited longer than the pool's
+// SlowAcquire on average
+type Monitor struct {
+	interval time.Duration
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMonitor creates a monitor checking every interval
+func NewMonitor(interval time.Duration) *Monitor {
+	return &Monitor{interval: interval, stopCh: make(chan struct{})}
+}
+
+// Start checks the pools in the background until Stop
+func (m *Monitor) Start() {
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.check()
+			case <-m.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the monitor
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+	})
+}
+
+func (m *Monitor) check() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, p := range pools {
+		stats := p.db.Stats()
+		waits := stats.WaitCount - p.lastWaitCount
+		waited := stats.WaitDuration - p.lastWaitDuration
+		p.lastWaitCount, p.lastWaitDuration = stats.WaitCount, stats.WaitDuration
+
+		if p.cfg.SlowAcquire <= 0 || waits <= 0 {
+			continue
+		}
+		if average := waited / time.Duration(waits); average > p.cfg.SlowAcquire {
+			log.Printf("⚠️ Slow %s database connection acquisition: %d requests waited %s on average in the last %s (%d/%d in use), consider raising DB_MAX_OPEN_CONNS",
+				p.name, waits, average.Round(time.Millisecond), m.interval, stats.InUse, stats.MaxOpenConnections)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package events
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// SubscribeOption configures how a subscription processes its messages
+type SubscribeOption func(*subscribeConfig)
+
+// subscribeConfig is the resolved processing configuration of a subscription
+type subscribeConfig struct {
+	concurrency int
+	prefetch    int
+	orderKey    func(msg Message) string
+}
+
+// WithConcurrency processes up to n messages of the queue at once, unless the environment
+// configures the queue otherwise. The handler must be safe for concurrent use.
+func WithConcurrency(n int) SubscribeOption {
+	return func(cfg *subscribeConfig) {
+		if n > 0 {
+			cfg.concurrency = n
+		}
+	}
+}
+
+// OrderedBy processes messages with the same key one at a time, in delivery order, when the
+// queue is processed concurrently. Messages with an empty key go to any worker. Kafka keeps
+// the order of a partition anyway and ignores it.
+func OrderedBy(key func(msg Message) string) SubscribeOption {
+	return func(cfg *subscribeConfig) {
+		cfg.orderKey = key
+	}
+}
+
+// OrderedByDataField orders messages by a string field of their event data, e.g. the
+// payment_id of payment events
+func OrderedByDataField(field string) SubscribeOption {
+	return OrderedBy(func(msg Message) string {
+		return dataField(msg.Body, field)
+	})
+}
+
+// dataField returns a string field of an event's data, empty when the body isn't an event
+// or the field isn't a string
+func dataField(body []byte, field string) string {
+	var event struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return ""
+	}
+	value, _ := event.Data[field].(string)
+	return value
+}
+
+// newSubscribeConfig resolves the configuration of a queue. Concurrency comes from
+// CONSUMER_CONCURRENCY_<QUEUE>, then WithConcurrency, then CONSUMER_CONCURRENCY (default 1).
+// The RabbitMQ prefetch count comes from CONSUMER_PREFETCH_<QUEUE>, then CONSUMER_PREFETCH,
+// and defaults to the concurrency. <QUEUE> is the queue name in upper case with dots and
+// dashes as underscores, e.g. CONSUMER_CONCURRENCY_EMAIL_QUEUE; shadow queues share it.
+func newSubscribeConfig(queue string, opts []SubscribeOption) subscribeConfig {
+	cfg := subscribeConfig{concurrency: getEnvInt("CONSUMER_CONCURRENCY", 1)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	suffix := queueEnvSuffix(queue)
+	cfg.concurrency = getEnvInt("CONSUMER_CONCURRENCY_"+suffix, cfg.concurrency)
+	cfg.prefetch = getEnvInt("CONSUMER_PREFETCH_"+suffix, getEnvInt("CONSUMER_PREFETCH", cfg.concurrency))
+	return cfg
+}
+
+// queueEnvSuffix turns a queue name into an environment variable suffix
+func queueEnvSuffix(queue string) string {
+	queue = strings.TrimSuffix(queue, ShadowQueueSuffix)
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(queue))
+}
+
+// dispatcher runs the work of a subscription on a fixed set of workers. Work with an order
+// key always goes to the same worker, the rest is spread round robin.
+type dispatcher struct {
+	workers  []chan func()
+	orderKey func(msg Message) string
+	next     atomic.Uint32
+	wg       sync.WaitGroup
+}
+
+// newDispatcher starts concurrency workers
+func newDispatcher(concurrency int, orderKey func(msg Message) string) *dispatcher {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	d := &dispatcher{
+		workers:  make([]chan func(), concurrency),
+		orderKey: orderKey,
+	}
+	for i := range d.workers {
+		work := make(chan func())
+		d.workers[i] = work
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for process := range work {
+				process()
+			}
+		}()
+	}
+	return d
+}
+
+// dispatch hands process for msg to a worker, waiting while that worker is busy
+func (d *dispatcher) dispatch(msg Message, process func()) {
+	if len(d.workers) == 1 {
+		d.workers[0] <- process
+		return
+	}
+
+	var index int
+	if key := d.key(msg); key != "" {
+		hash := fnv.New32a()
+		hash.Write([]byte(key))
+		index = int(hash.Sum32() % uint32(len(d.workers)))
+	} else {
+		index = int(d.next.Add(1) % uint32(len(d.workers)))
+	}
+	d.workers[index] <- process
+}
+
+func (d *dispatcher) key(msg Message) string {
+	if d.orderKey == nil {
+		return ""
+	}
+	return d.orderKey(msg)
+}
+
+// close waits for the workers to finish the work handed to them
+func (d *dispatcher) close() {
+	for _, work := range d.workers {
+		close(work)
+	}
+	d.wg.Wait()
+}
@@ -4,17 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
-	"github.com/streadway/amqp"
 )
 
-// EventService handles RabbitMQ event publishing and consuming
+// EventService handles event publishing and consuming on the configured bus
 type EventService struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
+	bus Bus
 }
 
 // Event represents a generic event structure
@@ -80,66 +78,14 @@ func NewEventService() (*EventService, error) {
 		log.Println("⚠️ .env file not found in events package, using system env")
 	}
 
-	// Get RabbitMQ configuration from environment
-	host := os.Getenv("RABBITMQ_HOST")
-	if host == "" {
-		host = "localhost"
-	}
-
-	port := os.Getenv("RABBITMQ_PORT")
-	if port == "" {
-		port = "5672"
-	}
-
-	username := os.Getenv("RABBITMQ_USERNAME")
-	if username == "" {
-		username = "admin"
-	}
-
-	password := os.Getenv("RABBITMQ_PASSWORD")
-	if password == "" {
-		password = "secret123"
-	}
-
-	// Create connection URL
-	url := fmt.Sprintf("amqp://%s:%s@%s:%s/", username, password, host, port)
-
-	// Connect to RabbitMQ
-	conn, err := amqp.Dial(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
-	}
-
-	// Create channel
-	ch, err := conn.Channel()
+	// Connect to the configured transport and declare exchanges
+	bus, err := NewBus([]string{"payment.events", "product.events", "user.events"})
 	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to open channel: %w", err)
+		return nil, err
 	}
 
-	// Declare exchanges
-	exchanges := []string{"payment.events", "product.events", "user.events"}
-	for _, exchange := range exchanges {
-		if err := ch.ExchangeDeclare(
-			exchange, // name
-			"topic",  // type
-			true,     // durable
-			false,    // auto-deleted
-			false,    // internal
-			false,    // no-wait
-			nil,      // arguments
-		); err != nil {
-			ch.Close()
-			conn.Close()
-			return nil, fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
-		}
-	}
-
-	log.Println("✅ Product-Service connected to RabbitMQ successfully")
-
 	return &EventService{
-		conn:    conn,
-		channel: ch,
+		bus: bus,
 	}, nil
 }
 
@@ -172,6 +118,46 @@ func (es *EventService) PublishStockReduction(productID string, quantity int, or
 	return es.publishEvent("product.events", "product.stock.reduced", event)
 }
 
+// PublishProductUpdated publishes a product change so caches (e.g. the API Gateway) can invalidate it
+func (es *EventService) PublishProductUpdated(productID string, stock int, reason string) error {
+	event := Event{
+		Type: "product.updated",
+		Data: map[string]interface{}{
+			"product_id": productID,
+			"stock":      stock,
+			"reason":     reason,
+		},
+		Timestamp: time.Now().Unix(),
+	}
+
+	return es.publishEvent("product.events", "product.updated", event)
+}
+
+// PublishStockUpdated publishes the stock left after a stock movement as product.updated.
+// When a variant's stock moved the event carries its variant_id and stock is the variant's.
+func (es *EventService) PublishStockUpdated(productID string, variantID *uuid.UUID, stock int, reason string) error {
+	data := map[string]interface{}{
+		"product_id": productID,
+		"stock":      stock,
+		"reason":     reason,
+	}
+	if variantID != nil {
+		data["variant_id"] = variantID.String()
+	}
+
+	event := Event{
+		Type:      "product.updated",
+		Data:      data,
+		Timestamp: time.Now().Unix(),
+	}
+
+	return es.publishEvent("product.events", "product.updated", event)
+}
+
+// aggregateKeyField is the event data field product events are keyed by on the bus, the
+// events of one product stay in order on Kafka
+const aggregateKeyField = "product_id"
+
 // publishEvent publishes a generic event
 func (es *EventService) publishEvent(exchange, routingKey string, event Event) error {
 	// Marshal event to JSON
@@ -181,17 +167,14 @@ func (es *EventService) publishEvent(exchange, routingKey string, event Event) e
 	}
 
 	// Publish message
-	err = es.channel.Publish(
-		exchange,   // exchange
-		routingKey, // routing key
-		false,      // mandatory
-		false,      // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-			Timestamp:   time.Now(),
-		},
-	)
+	err = es.bus.Publish(Message{
+		ID:         uuid.New().String(),
+		Exchange:   exchange,
+		RoutingKey: routingKey,
+		Key:        dataField(body, aggregateKeyField),
+		Body:       body,
+		Timestamp:  time.Now(),
+	})
 
 	if err != nil {
 		return fmt.Errorf("failed to publish event: %w", err)
@@ -201,44 +184,26 @@ func (es *EventService) publishEvent(exchange, routingKey string, event Event) e
 	return nil
 }
 
-// Close closes the RabbitMQ connection
-func (es *EventService) Close() error {
-	if es.channel != nil {
-		es.channel.Close()
-	}
-	if es.conn != nil {
-		return es.conn.Close()
+// Subscribe consumes the given bindings from a durable queue (a consumer group on Kafka),
+// warning when they drifted from Topology
+func (es *EventService) Subscribe(queue string, bindings []Binding, handler Handler, opts ...SubscribeOption) error {
+	for _, drift := range Topology.CheckSubscription(queue, bindings) {
+		log.Printf("⚠️ Topology drift: %s", drift)
 	}
-	return nil
+	return es.bus.Subscribe(queue, bindings, handler, opts...)
 }
 
-// GetChannel returns the RabbitMQ channel for consumers
-func (es *EventService) GetChannel() *amqp.Channel {
-	return es.channel
+// Close closes the bus connection
+func (es *EventService) Close() error {
+	return es.bus.Close()
 }
 
-// HealthCheck checks if RabbitMQ connection is healthy
-func (es *EventService) HealthCheck() error {
-	if es.conn == nil || es.channel == nil {
-		return fmt.Errorf("RabbitMQ connection not initialized")
-	}
-
-	// Try to declare a temporary queue to test connection
-	_, err := es.channel.QueueDeclare(
-		"health_check", // name
-		false,          // durable
-		true,           // delete when unused
-		true,           // exclusive
-		false,          // no-wait
-		nil,            // arguments
-	)
-
-	if err != nil {
-		return fmt.Errorf("RabbitMQ health check failed: %w", err)
-	}
-
-	// Clean up the temporary queue
-	es.channel.QueueDelete("health_check", false, false, false)
+// IsConnected reports whether the bus connection is open
+func (es *EventService) IsConnected() bool {
+	return es.bus.IsConnected()
+}
 
-	return nil
+// HealthCheck checks if the bus connection is healthy
+func (es *EventService) HealthCheck() error {
+	return es.bus.HealthCheck()
 }
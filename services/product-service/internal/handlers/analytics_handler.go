@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"product-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxFunnelDays bounds the date range of a funnel request
+const maxFunnelDays = 366
+
+type AnalyticsHandler struct {
+	repo *repository.ProductRepository
+}
+
+func NewAnalyticsHandler(repo *repository.ProductRepository) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		repo: repo,
+	}
+}
+
+// GetProductFunnel handles GET /api/v1/admin/products/:id/funnel?from=YYYY-MM-DD&to=YYYY-MM-DD,
+// the product's views, checkouts and paid orders per day (UTC) with the conversion between
+// them. Both dates are inclusive, the range defaults to the last 30 days.
+func (h *AnalyticsHandler) GetProductFunnel(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PRODUCT_ID", nil)
+		return
+	}
+
+	to := time.Now().UTC()
+	if raw := c.Query("to"); raw != "" {
+		to, err = time.Parse("2006-01-02", raw)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_TO_DATE", gin.H{"details": "use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	from := to.AddDate(0, 0, -29)
+	if raw := c.Query("from"); raw != "" {
+		from, err = time.Parse("2006-01-02", raw)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_FROM_DATE", gin.H{"details": "use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	if from.After(to) {
+		respondError(c, http.StatusBadRequest, "INVALID_DATE_RANGE", gin.H{"details": "from must not be after to"})
+		return
+	}
+	if to.Sub(from) >= maxFunnelDays*24*time.Hour {
+		respondError(c, http.StatusBadRequest, "INVALID_DATE_RANGE", gin.H{"details": "at most 366 days"})
+		return
+	}
+
+	funnel, err := h.repo.GetProductFunnel(ctx, productID, from, to)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "PRODUCT_FUNNEL_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    funnel,
+	})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"product-service/internal/events"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BulkHandler struct {
+	repo     *repository.ProductRepository
+	eventSvc *events.EventService
+}
+
+func NewBulkHandler(repo *repository.ProductRepository, eventSvc *events.EventService) *BulkHandler {
+	return &BulkHandler{
+		repo:     repo,
+		eventSvc: eventSvc,
+	}
+}
+
+// BulkUpdateProducts handles PATCH /api/v1/seller/products/bulk
+func (h *BulkHandler) BulkUpdateProducts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	sellerID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return
+	}
+
+	var req models.BulkProductUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{"details": err.Error()})
+		return
+	}
+
+	if req.IsActive == nil && req.Price == nil && req.PublishAt == nil && req.UnpublishAt == nil && !req.ClearSchedule {
+		respondError(c, http.StatusBadRequest, "NO_CHANGES", nil)
+		return
+	}
+	if req.PublishAt != nil && req.UnpublishAt != nil && !req.UnpublishAt.After(*req.PublishAt) {
+		respondError(c, http.StatusBadRequest, "INVALID_PUBLISH_WINDOW", nil)
+		return
+	}
+
+	products, err := h.repo.BulkUpdateProducts(ctx, sellerID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrProductsNotOwned):
+			respondError(c, http.StatusForbidden, "PRODUCTS_NOT_OWNED", gin.H{"details": err.Error()})
+		case errors.Is(err, repository.ErrInvalidPrice):
+			respondError(c, http.StatusBadRequest, "INVALID_PRICE_ADJUSTMENT", gin.H{"details": err.Error()})
+		default:
+			respondError(c, http.StatusInternalServerError, "PRODUCTS_UPDATE_FAILED", gin.H{"details": err.Error()})
+		}
+		return
+	}
+
+	responses := make([]models.ProductResponse, len(products))
+	for i := range products {
+		responses[i] = products[i].ToResponse()
+		if err := h.eventSvc.PublishProductUpdated(products[i].ID.String(), products[i].Stock, models.ProductReasonBulkUpdate); err != nil {
+			log.Printf("⚠️ Failed to publish product.updated for %s: %v", products[i].ID, err)
+		}
+	}
+
+	log.Printf("📝 Seller %s bulk updated %d products", sellerID, len(products))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": models.BulkProductUpdateResponse{
+			Updated:  len(responses),
+			Products: responses,
+		},
+	})
+}
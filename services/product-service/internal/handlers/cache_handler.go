@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"product-service/internal/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheHandler serves the cache admin API, so a stale entry can be cleared without
+// connecting to Redis
+type CacheHandler struct {
+	cache *cache.TieredCache
+}
+
+func NewCacheHandler(productCache *cache.TieredCache) *CacheHandler {
+	return &CacheHandler{
+		cache: productCache,
+	}
+}
+
+// GetCacheStats handles GET /api/v1/admin/cache: key counts and hit rates per namespace
+func (h *CacheHandler) GetCacheStats(c *gin.Context) {
+	stats, err := h.cache.Stats(c.Request.Context())
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "CACHE_STATS_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// PurgeCache handles DELETE /api/v1/admin/cache/:namespace. ?id= only purges that entry,
+// e.g. DELETE /api/v1/admin/cache/product?id=<product id>.
+func (h *CacheHandler) PurgeCache(c *gin.Context) {
+	namespace := c.Param("namespace")
+	id := c.Query("id")
+
+	deleted, err := h.cache.Purge(c.Request.Context(), namespace, id)
+	if errors.Is(err, cache.ErrUnknownNamespace) {
+		respondError(c, http.StatusNotFound, "UNKNOWN_CACHE_NAMESPACE", gin.H{"details": err.Error(), "namespaces": cache.Namespaces})
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "CACHE_PURGE_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"namespace": namespace,
+			"id":        id,
+			"deleted":   deleted,
+		},
+	})
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ImageHandler struct {
+	repo *repository.ProductRepository
+}
+
+func NewImageHandler(repo *repository.ProductRepository) *ImageHandler {
+	return &ImageHandler{
+		repo: repo,
+	}
+}
+
+// ReorderImages handles PUT /api/v1/seller/products/:id/images/order, body
+// {"image_ids": [...]} listing every image of the product in its new order
+func (h *ImageHandler) ReorderImages(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	productID, ok := authorizeProductSeller(ctx, c, h.repo)
+	if !ok {
+		return
+	}
+
+	var req models.ReorderImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{"details": err.Error()})
+		return
+	}
+
+	images, err := h.repo.ReorderProductImages(ctx, productID, req.ImageIDs)
+	if err != nil {
+		if errors.Is(err, repository.ErrImageOrderMismatch) {
+			respondError(c, http.StatusBadRequest, "INVALID_IMAGE_ORDER", gin.H{"details": err.Error()})
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "IMAGE_REORDER_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    images,
+	})
+}
+
+// SetPrimaryImage handles PUT /api/v1/seller/products/:id/images/:image_id/primary
+func (h *ImageHandler) SetPrimaryImage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	productID, ok := authorizeProductSeller(ctx, c, h.repo)
+	if !ok {
+		return
+	}
+
+	imageID, err := uuid.Parse(c.Param("image_id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_IMAGE_ID", nil)
+		return
+	}
+
+	images, err := h.repo.SetPrimaryProductImage(ctx, productID, imageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrImageNotFound) {
+			respondError(c, http.StatusNotFound, "IMAGE_NOT_FOUND", nil)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "PRIMARY_IMAGE_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    images,
+	})
+}
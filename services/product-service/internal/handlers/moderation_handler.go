@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"product-service/internal/events"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ModerationHandler struct {
+	repo     *repository.ProductRepository
+	eventSvc *events.EventService
+}
+
+func NewModerationHandler(repo *repository.ProductRepository, eventSvc *events.EventService) *ModerationHandler {
+	return &ModerationHandler{
+		repo:     repo,
+		eventSvc: eventSvc,
+	}
+}
+
+// GetModerationQueue handles GET /api/v1/admin/moderation/products, listing products in
+// ?status= (pending_review by default), oldest first
+func (h *ModerationHandler) GetModerationQueue(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	status := models.ModerationStatus(c.DefaultQuery("status", string(models.ModerationPendingReview)))
+	if !status.IsValid() {
+		respondError(c, http.StatusBadRequest, "INVALID_MODERATION_STATUS", gin.H{"details": "use pending_review, approved or rejected"})
+		return
+	}
+
+	page, limit := storePagination(c)
+	products, err := h.repo.ListProductsForModeration(ctx, status, page, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "PRODUCTS_FETCH_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    products,
+	})
+}
+
+// ApproveProduct handles POST /api/v1/admin/moderation/products/:id/approve
+func (h *ModerationHandler) ApproveProduct(c *gin.Context) {
+	h.decide(c, models.ModerationApproved, nil)
+}
+
+// RejectProduct handles POST /api/v1/admin/moderation/products/:id/reject
+func (h *ModerationHandler) RejectProduct(c *gin.Context) {
+	var req models.RejectProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{"details": err.Error()})
+		return
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{"details": "reason must not be blank"})
+		return
+	}
+	h.decide(c, models.ModerationRejected, &reason)
+}
+
+// decide records the decision and announces it with product.updated, so services caching
+// the product drop it
+func (h *ModerationHandler) decide(c *gin.Context, status models.ModerationStatus, reason *string) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PRODUCT_ID", nil)
+		return
+	}
+
+	product, err := h.repo.SetModerationStatus(ctx, productID, status, reason)
+	if err != nil {
+		if err.Error() == "product not found" {
+			respondError(c, http.StatusNotFound, "PRODUCT_NOT_FOUND", nil)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "MODERATION_UPDATE_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+	log.Printf("🛡️ Product %s %s by admin", productID, status)
+
+	eventReason := models.ProductReasonModerationApproved
+	if status == models.ModerationRejected {
+		eventReason = models.ProductReasonModerationRejected
+	}
+	if err := h.eventSvc.PublishProductUpdated(productID.String(), product.Stock, eventReason); err != nil {
+		log.Printf("⚠️ Failed to publish product.updated for %s: %v", productID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    product,
+	})
+}
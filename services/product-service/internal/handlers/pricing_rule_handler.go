@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PricingRuleHandler struct {
+	repo *repository.ProductRepository
+}
+
+func NewPricingRuleHandler(repo *repository.ProductRepository) *PricingRuleHandler {
+	return &PricingRuleHandler{
+		repo: repo,
+	}
+}
+
+// GetPricingRules handles GET /api/v1/admin/pricing-rules, optionally ?product_id=
+func (h *PricingRuleHandler) GetPricingRules(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var productID *uuid.UUID
+	if raw := c.Query("product_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_PRODUCT_ID", nil)
+			return
+		}
+		productID = &parsed
+	}
+
+	page, limit := storePagination(c)
+	rules, err := h.repo.ListPricingRules(ctx, productID, page, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "PRICING_RULES_FETCH_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+	})
+}
+
+// GetPricingRule handles GET /api/v1/admin/pricing-rules/:id
+func (h *PricingRuleHandler) GetPricingRule(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	rule, ok := h.loadRule(ctx, c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// CreatePricingRule handles POST /api/v1/admin/pricing-rules
+func (h *PricingRuleHandler) CreatePricingRule(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req models.CreatePricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{"details": err.Error()})
+		return
+	}
+	if req.ProductID != nil && req.StoreID != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PRICING_RULE", gin.H{"details": "target a product or a store, not both"})
+		return
+	}
+
+	rule := &models.PricingRule{
+		Name:        strings.TrimSpace(req.Name),
+		Type:        req.Type,
+		Value:       req.Value,
+		ProductID:   req.ProductID,
+		StoreID:     req.StoreID,
+		MinQuantity: req.MinQuantity,
+		MemberOnly:  req.MemberOnly,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		IsActive:    true,
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	if err := validatePricingRule(rule); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PRICING_RULE", gin.H{"details": err.Error()})
+		return
+	}
+
+	if !h.targetExists(ctx, c, rule) {
+		return
+	}
+
+	if err := h.repo.CreatePricingRule(ctx, rule); err != nil {
+		respondError(c, http.StatusInternalServerError, "PRICING_RULE_CREATE_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// UpdatePricingRule handles PUT /api/v1/admin/pricing-rules/:id
+func (h *PricingRuleHandler) UpdatePricingRule(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	rule, ok := h.loadRule(ctx, c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdatePricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{"details": err.Error()})
+		return
+	}
+
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Type != nil {
+		rule.Type = *req.Type
+	}
+	if req.Value != nil {
+		rule.Value = *req.Value
+	}
+	if req.MinQuantity != nil {
+		rule.MinQuantity = *req.MinQuantity
+	}
+	if req.MemberOnly != nil {
+		rule.MemberOnly = *req.MemberOnly
+	}
+	if req.StartsAt != nil {
+		rule.StartsAt = req.StartsAt
+	}
+	if req.EndsAt != nil {
+		rule.EndsAt = req.EndsAt
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	if err := validatePricingRule(rule); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PRICING_RULE", gin.H{"details": err.Error()})
+		return
+	}
+
+	if err := h.repo.UpdatePricingRule(ctx, rule); err != nil {
+		respondError(c, http.StatusInternalServerError, "PRICING_RULE_UPDATE_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// DeletePricingRule handles DELETE /api/v1/admin/pricing-rules/:id
+func (h *PricingRuleHandler) DeletePricingRule(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PRICING_RULE_ID", nil)
+		return
+	}
+
+	if err := h.repo.DeletePricingRule(ctx, ruleID); err != nil {
+		if errors.Is(err, repository.ErrPricingRuleNotFound) {
+			respondError(c, http.StatusNotFound, "PRICING_RULE_NOT_FOUND", nil)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "PRICING_RULE_DELETE_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Pricing rule deleted",
+	})
+}
+
+// loadRule parses the rule ID and loads the rule
+func (h *PricingRuleHandler) loadRule(ctx context.Context, c *gin.Context) (*models.PricingRule, bool) {
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PRICING_RULE_ID", nil)
+		return nil, false
+	}
+
+	rule, err := h.repo.GetPricingRule(ctx, ruleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrPricingRuleNotFound) {
+			respondError(c, http.StatusNotFound, "PRICING_RULE_NOT_FOUND", nil)
+			return nil, false
+		}
+		respondError(c, http.StatusInternalServerError, "PRICING_RULE_FETCH_FAILED", gin.H{"details": err.Error()})
+		return nil, false
+	}
+	return rule, true
+}
+
+// targetExists checks that the product or store a new rule targets exists
+func (h *PricingRuleHandler) targetExists(ctx context.Context, c *gin.Context, rule *models.PricingRule) bool {
+	if rule.ProductID != nil {
+		if _, err := h.repo.GetProductOwner(ctx, *rule.ProductID); err != nil {
+			if err.Error() == "product not found" {
+				respondError(c, http.StatusNotFound, "PRODUCT_NOT_FOUND", nil)
+				return false
+			}
+			respondError(c, http.StatusInternalServerError, "PRODUCT_FETCH_FAILED", gin.H{"details": err.Error()})
+			return false
+		}
+	}
+	if rule.StoreID != nil {
+		if _, err := h.repo.GetStore(ctx, *rule.StoreID); err != nil {
+			if errors.Is(err, repository.ErrStoreNotFound) {
+				respondError(c, http.StatusNotFound, "STORE_NOT_FOUND", nil)
+				return false
+			}
+			respondError(c, http.StatusInternalServerError, "STORE_FETCH_FAILED", gin.H{"details": err.Error()})
+			return false
+		}
+	}
+	return true
+}
+
+// validatePricingRule checks the parts of a rule binding tags can't express
+func validatePricingRule(rule *models.PricingRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("name must not be blank")
+	}
+	if rule.Type == models.PricingRulePercentage && rule.Value > 100 {
+		return fmt.Errorf("a percentage discount must be at most 100")
+	}
+	if rule.MinQuantity < 1 {
+		rule.MinQuantity = 1
+	}
+	if rule.StartsAt != nil && rule.EndsAt != nil && !rule.EndsAt.After(*rule.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	return nil
+}
@@ -2,12 +2,17 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"product-service/internal/models"
+	"product-service/internal/pagination"
 	"product-service/internal/repository"
+	"product-service/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -16,15 +21,23 @@ import (
 type ProductHandler struct {
 	repo       *repository.ProductRepository
 	workerPool *WorkerPool
+	pricing    *services.PricingEngine
+	views      *services.ViewCounter // nil counts no views
 }
 
-func NewProductHandler(repo *repository.ProductRepository, workerPool *WorkerPool) *ProductHandler {
+func NewProductHandler(repo *repository.ProductRepository, workerPool *WorkerPool, pricing *services.PricingEngine) *ProductHandler {
 	return &ProductHandler{
 		repo:       repo,
 		workerPool: workerPool,
+		pricing:    pricing,
 	}
 }
 
+// SetViewCounter counts the product detail views served for the conversion funnel
+func (h *ProductHandler) SetViewCounter(views *services.ViewCounter) {
+	h.views = views
+}
+
 // GetProducts handles GET /api/v1/products
 func (h *ProductHandler) GetProducts(c *gin.Context) {
 	// Create context with timeout
@@ -34,7 +47,7 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	// Parse query parameters
 	var query models.ProductQuery
 	if err := c.ShouldBindQuery(&query); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		respondError(c, http.StatusBadRequest, "INVALID_QUERY", gin.H{"details": err.Error()})
 		return
 	}
 	
@@ -48,6 +61,29 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	if query.Limit > 100 {
 		query.Limit = 100
 	}
+	if query.StoreID != "" {
+		if _, err := uuid.Parse(query.StoreID); err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_STORE_ID", nil)
+			return
+		}
+	}
+	if query.IDs != "" {
+		ids := strings.Split(query.IDs, ",")
+		if len(ids) > 100 {
+			respondError(c, http.StatusBadRequest, "TOO_MANY_PRODUCT_IDS", gin.H{"details": "ids takes at most 100 IDs"})
+			return
+		}
+		for _, id := range ids {
+			if _, err := uuid.Parse(id); err != nil {
+				respondError(c, http.StatusBadRequest, "INVALID_PRODUCT_ID", gin.H{"details": id})
+				return
+			}
+		}
+	}
+	if _, err := pagination.LookupSort(query.Sort); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_SORT", gin.H{"details": "sort must be one of newest, oldest, price_asc, price_desc, name_asc, name_desc"})
+		return
+	}
 	
 	// Create request for worker pool
 	req := Request{
@@ -61,7 +97,7 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	
 	// Submit request to worker pool
 	if err := h.workerPool.SubmitRequest(req); err != nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Service temporarily unavailable", "details": err.Error()})
+		respondError(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", gin.H{"details": err.Error()})
 		return
 	}
 	
@@ -69,14 +105,18 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	select {
 	case response := <-req.Response:
 		if response.Error != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get products", "details": response.Error.Error()})
+			if errors.Is(response.Error, pagination.ErrInvalidCursor) {
+				respondError(c, http.StatusBadRequest, "INVALID_CURSOR", gin.H{"details": "use next_cursor from a previous page with the same sort"})
+				return
+			}
+			respondError(c, http.StatusInternalServerError, "PRODUCTS_FETCH_FAILED", gin.H{"details": response.Error.Error()})
 			return
 		}
 		
 		// Type assert the response data
 		products, ok := response.Data.(*models.ProductListResponse)
 		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid response format"})
+			respondError(c, http.StatusInternalServerError, "INVALID_RESPONSE", nil)
 			return
 		}
 		
@@ -90,7 +130,7 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 		})
 		
 	case <-ctx.Done():
-		c.JSON(http.StatusRequestTimeout, gin.H{"error": "Request timeout"})
+		respondError(c, http.StatusRequestTimeout, "REQUEST_TIMEOUT", nil)
 		return
 	}
 }
@@ -105,7 +145,7 @@ func (h *ProductHandler) GetProductByID(c *gin.Context) {
 	productIDStr := c.Param("id")
 	productID, err := uuid.Parse(productIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		respondError(c, http.StatusBadRequest, "INVALID_PRODUCT_ID", nil)
 		return
 	}
 	
@@ -121,7 +161,7 @@ func (h *ProductHandler) GetProductByID(c *gin.Context) {
 	
 	// Submit request to worker pool
 	if err := h.workerPool.SubmitRequest(req); err != nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Service temporarily unavailable", "details": err.Error()})
+		respondError(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", gin.H{"details": err.Error()})
 		return
 	}
 	
@@ -130,20 +170,24 @@ func (h *ProductHandler) GetProductByID(c *gin.Context) {
 	case response := <-req.Response:
 		if response.Error != nil {
 			if response.Error.Error() == "product not found" {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+				respondError(c, http.StatusNotFound, "PRODUCT_NOT_FOUND", nil)
 				return
 			}
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get product", "details": response.Error.Error()})
+			respondError(c, http.StatusInternalServerError, "PRODUCT_FETCH_FAILED", gin.H{"details": response.Error.Error()})
 			return
 		}
 		
 		// Type assert the response data
 		product, ok := response.Data.(*models.ProductResponse)
 		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid response format"})
+			respondError(c, http.StatusInternalServerError, "INVALID_RESPONSE", nil)
 			return
 		}
 		
+		if h.views != nil {
+			h.views.Record(productID)
+		}
+		
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"data":    product,
@@ -154,9 +198,73 @@ func (h *ProductHandler) GetProductByID(c *gin.Context) {
 		})
 		
 	case <-ctx.Done():
-		c.JSON(http.StatusRequestTimeout, gin.H{"error": "Request timeout"})
+		respondError(c, http.StatusRequestTimeout, "REQUEST_TIMEOUT", nil)
+		return
+	}
+}
+
+// GetProductPrice handles GET /api/v1/products/:id/price?quantity=N&member=true&variant_id=,
+// quoting the price after pricing rules. Requests from signed-in customers (X-User-ID) get
+// member prices. A variant with its own price is quoted from that price.
+func (h *ProductHandler) GetProductPrice(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PRODUCT_ID", nil)
+		return
+	}
+
+	quantity, err := strconv.Atoi(c.DefaultQuery("quantity", "1"))
+	if err != nil || quantity < 1 {
+		respondError(c, http.StatusBadRequest, "INVALID_QUANTITY", gin.H{"details": "quantity must be a positive integer"})
 		return
 	}
+	member := c.Query("member") == "true" || c.GetHeader("X-User-ID") != ""
+
+	product, err := h.repo.GetProductByID(ctx, productID)
+	if err != nil {
+		if err.Error() == "product not found" {
+			respondError(c, http.StatusNotFound, "PRODUCT_NOT_FOUND", nil)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "PRODUCT_FETCH_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	var variantID *uuid.UUID
+	if value := c.Query("variant_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_VARIANT_ID", nil)
+			return
+		}
+		variantID = &parsed
+
+		variant := models.FindVariant(product.Variants, parsed)
+		if variant == nil {
+			respondError(c, http.StatusNotFound, "VARIANT_NOT_FOUND", nil)
+			return
+		}
+
+		// Rules are evaluated against the variant's price, the cached product is left untouched
+		priced := *product
+		priced.Price = variant.Price(product.Price)
+		product = &priced
+	}
+
+	quote, err := h.pricing.Quote(ctx, product, quantity, member)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "PRICING_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+	quote.VariantID = variantID
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    quote,
+	})
 }
 
 // Health handles GET /health
@@ -201,6 +309,13 @@ func (h *ProductHandler) handleGetProducts(req Request) Response {
 			Duration: time.Since(start),
 		}
 	}
+
+	// Prices depend on the time, so rules are applied after the product cache
+	priced := make([]*models.ProductResponse, len(products.Products))
+	for i := range products.Products {
+		priced[i] = &products.Products[i]
+	}
+	h.pricing.Apply(req.Context, priced...)
 	
 	return Response{
 		ID:       req.ID,
@@ -233,6 +348,7 @@ func (h *ProductHandler) handleGetProductByID(req Request) Response {
 			Duration: time.Since(start),
 		}
 	}
+	h.pricing.Apply(req.Context, product)
 	
 	return Response{
 		ID:       req.ID,
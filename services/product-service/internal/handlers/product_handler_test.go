@@ -0,0 +1,157 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"product-service/internal/cache"
+	"product-service/internal/handlers"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/internal/services"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// benchProducts is how many approved products the benchmark database holds
+const benchProducts = 50
+
+// newBenchRouter serves GET /api/v1/products from an in-memory database holding
+// benchProducts products with two images each, through the worker pool and the tiered
+// cache in front of an in-memory Redis
+func newBenchRouter(b *testing.B) *gin.Engine {
+	b.Helper()
+	gin.SetMode(gin.TestMode)
+	output := log.Writer()
+	log.SetOutput(io.Discard) // the worker pool logs every request
+	b.Cleanup(func() { log.SetOutput(output) })
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		b.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		b.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1) // every connection would get its own in-memory database
+	b.Cleanup(func() { sqlDB.Close() })
+
+	// SQLite has no gen_random_uuid(), the seed sets the IDs
+	tables := append(models.OwnedModels(), models.ReadModels()...)
+	for _, table := range tables {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(table); err != nil {
+			b.Fatalf("failed to parse %T: %v", table, err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DefaultValue == "gen_random_uuid()" {
+				field.HasDefaultValue = false
+				field.DefaultValue = ""
+			}
+		}
+	}
+	if err := db.AutoMigrate(tables...); err != nil {
+		b.Fatalf("failed to migrate database: %v", err)
+	}
+
+	seller := models.UserProfile{ID: uuid.New(), Username: "seller", Email: "seller@example.com"}
+	if err := db.Create(&seller).Error; err != nil {
+		b.Fatalf("failed to seed seller: %v", err)
+	}
+	created := time.Now().Add(-time.Hour)
+	for i := range benchProducts {
+		product := models.Product{
+			ID:               uuid.New(),
+			UserID:           seller.ID,
+			Name:             fmt.Sprintf("Product %02d", i),
+			Description:      "Benchmark product",
+			Price:            float64(10000 + i*500),
+			Stock:            100,
+			IsActive:         true,
+			ModerationStatus: models.ModerationApproved,
+			CreatedAt:        created.Add(time.Duration(i) * time.Second),
+		}
+		for position := range 2 {
+			product.Images = append(product.Images, models.ProductImage{
+				ID:        uuid.New(),
+				ImageUrl:  fmt.Sprintf("https://cdn.example.com/%d-%d.jpg", i, position),
+				Position:  position,
+				IsPrimary: position == 0,
+			})
+		}
+		if err := db.Create(&product).Error; err != nil {
+			b.Fatalf("failed to seed product: %v", err)
+		}
+	}
+
+	redisServer := miniredis.RunT(b)
+	topology, err := cache.RedisTopologyFromEnv(redisServer.Addr())
+	if err != nil {
+		b.Fatalf("invalid Redis topology: %v", err)
+	}
+	productCache := cache.NewTieredCache(cache.NewRedisClient(topology), cache.NewLocalCache(1000), 30*time.Second, 5*time.Second)
+
+	repo := repository.NewProductRepository(db, productCache)
+	workerPool := handlers.NewWorkerPool(4)
+	workerPool.Start()
+	b.Cleanup(workerPool.Stop)
+	handler := handlers.NewProductHandler(repo, workerPool, services.NewPricingEngine(repo))
+	handler.UpdateWorkerPoolHandlers()
+
+	router := gin.New()
+	router.GET("/api/v1/products", func(c *gin.Context) {
+		if c.Query("nocache") == "true" {
+			c.Request = c.Request.WithContext(cache.WithBypass(c.Request.Context()))
+		}
+		handler.GetProducts(c)
+	})
+	return router
+}
+
+// BenchmarkGetProducts lists the first page of 20 products, from the local cache tier and
+// from the database. Its allocations are budgeted in loadtest/bench-budgets.txt.
+func BenchmarkGetProducts(b *testing.B) {
+	router := newBenchRouter(b)
+
+	// The page must hold products, an empty listing would be cheap for the wrong reason
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products?limit=20&nocache=true", nil))
+	var answer struct {
+		Data models.ProductListResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &answer); err != nil || len(answer.Data.Products) != 20 || len(answer.Data.Products[0].Images) != 2 {
+		b.Fatalf("GetProducts = %d %s, want 20 products with their images", rec.Code, rec.Body)
+	}
+
+	for _, bench := range []struct {
+		name  string
+		query string
+	}{
+		{name: "cached", query: "limit=20"},
+		{name: "database", query: "limit=20&nocache=true"},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/products?"+bench.query, nil)
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					b.Fatalf("GetProducts = %d %s, want 200", rec.Code, rec.Body)
+				}
+			}
+		})
+	}
+}
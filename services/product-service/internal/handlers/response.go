@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"product-service/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondError writes a localized error response keyed by an error code. "error" stays in
+// English for logs and existing clients, "message" follows the negotiated locale and "code"
+// is stable for programmatic handling. fields (details, ...) are added as they are.
+func respondError(c *gin.Context, status int, code string, fields gin.H) {
+	body := gin.H{
+		"error":   i18n.T(i18n.LocaleEN, code),
+		"message": i18n.T(i18n.FromContext(c), code),
+		"code":    code,
+	}
+	for key, value := range fields {
+		body[key] = value
+	}
+	c.JSON(status, body)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Fixture records for frontend end to end tests. The seller is the fixture seller of
+// User-Service, the payment fixture of Payment-Service buys the product.
+var (
+	FixtureSellerID  = uuid.MustParse("00000000-0000-4000-8000-000000000002")
+	FixtureStoreID   = uuid.MustParse("00000000-0000-4000-8000-000000000101")
+	FixtureProductID = uuid.MustParse("00000000-0000-4000-8000-000000000201")
+)
+
+// SandboxHandler serves the demo environment tools, registered only with SANDBOX_TOOLS
+type SandboxHandler struct {
+	repo *repository.ProductRepository
+}
+
+func NewSandboxHandler(repo *repository.ProductRepository) *SandboxHandler {
+	return &SandboxHandler{
+		repo: repo,
+	}
+}
+
+// ResetSandbox handles POST /api/v1/admin/sandbox/reset: every store and product is deleted
+// and the sample catalog is seeded again
+func (h *SandboxHandler) ResetSandbox(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	result, err := h.repo.ResetCatalog(ctx)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "SANDBOX_RESET_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	log.Printf("🧹 Sandbox catalog reset, seeded %d products in %d stores", result.Products, result.Stores)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// CreateFixtures handles POST /api/v1/dev/fixtures: the fixture seller's store and product
+// are created, or restored to their initial state and stock
+func (h *SandboxHandler) CreateFixtures(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	storeID := FixtureStoreID
+	store := &models.Store{
+		ID:          FixtureStoreID,
+		OwnerID:     FixtureSellerID,
+		Name:        "E2E Store",
+		Slug:        "e2e-store",
+		Description: "Store of the end to end test seller",
+		IsActive:    true,
+	}
+	product := &models.Product{
+		ID:               FixtureProductID,
+		UserID:           FixtureSellerID,
+		StoreID:          &storeID,
+		Name:             "E2E Test Product",
+		Description:      "Product with a fixed price and stock for end to end tests",
+		Price:            100000,
+		Stock:            100,
+		IsActive:         true,
+		ModerationStatus: models.ModerationApproved,
+		Images: []models.ProductImage{
+			{
+				ID:        uuid.MustParse("00000000-0000-4000-8000-000000000211"),
+				ImageUrl:  "https://images.unsplash.com/photo-1521572163474-6864f9cf17ab?w=500",
+				Position:  0,
+				IsPrimary: true,
+			},
+		},
+	}
+
+	if err := h.repo.UpsertFixture(ctx, store, product); err != nil {
+		respondError(c, http.StatusInternalServerError, "FIXTURES_CREATE_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	log.Printf("🧪 Fixture store %s and product %s ready", store.Slug, product.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"store":   store,
+			"product": product.ToResponse(),
+		},
+	})
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"product-service/internal/events"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type StockHandler struct {
+	repo     *repository.ProductRepository
+	eventSvc *events.EventService
+}
+
+func NewStockHandler(repo *repository.ProductRepository, eventSvc *events.EventService) *StockHandler {
+	return &StockHandler{
+		repo:     repo,
+		eventSvc: eventSvc,
+	}
+}
+
+// GetStockMovements handles GET /api/v1/seller/products/:id/stock-movements
+func (h *StockHandler) GetStockMovements(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	productID, ok := h.authorizeSeller(ctx, c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	movements, err := h.repo.GetStockMovements(ctx, productID, page, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "STOCK_MOVEMENTS_FETCH_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    movements,
+	})
+}
+
+// AdjustStock handles POST /api/v1/seller/products/:id/stock for restocks and manual adjustments
+func (h *StockHandler) AdjustStock(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	productID, ok := h.authorizeSeller(ctx, c)
+	if !ok {
+		return
+	}
+
+	var req models.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{"details": err.Error()})
+		return
+	}
+
+	if req.Reason == models.StockReasonRestock && req.Delta < 0 {
+		respondError(c, http.StatusBadRequest, "INVALID_RESTOCK_DELTA", nil)
+		return
+	}
+
+	sellerID, _ := uuid.Parse(c.GetHeader("X-User-ID"))
+	movement := &models.StockMovement{
+		VariantID: req.VariantID,
+		Delta:     req.Delta,
+		Reason:    req.Reason,
+		ActorType: models.StockActorSeller,
+		ActorID:   &sellerID,
+		Note:      req.Note,
+	}
+
+	if err := h.repo.AdjustStock(ctx, productID, movement); err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			respondError(c, http.StatusConflict, "INSUFFICIENT_STOCK", gin.H{"details": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrVariantNotFound) {
+			respondError(c, http.StatusNotFound, "VARIANT_NOT_FOUND", nil)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "STOCK_ADJUST_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	if err := h.eventSvc.PublishStockUpdated(productID.String(), movement.VariantID, movement.StockAfter, movement.Reason); err != nil {
+		log.Printf("⚠️ Failed to publish product.updated for %s: %v", productID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    movement,
+	})
+}
+
+// GetAvailability handles GET /api/v1/products/:id/availability?quantity=N&variant_id=, a
+// cheap stock pre-check for the frontend and Payment-Service before a charge is created.
+// Products with variants are checked per variant, variant_id is required for them.
+func (h *StockHandler) GetAvailability(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PRODUCT_ID", nil)
+		return
+	}
+
+	quantity, err := strconv.Atoi(c.DefaultQuery("quantity", "1"))
+	if err != nil || quantity < 1 {
+		respondError(c, http.StatusBadRequest, "INVALID_QUANTITY", gin.H{"details": "quantity must be a positive integer"})
+		return
+	}
+
+	var variantID *uuid.UUID
+	if value := c.Query("variant_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_VARIANT_ID", nil)
+			return
+		}
+		variantID = &parsed
+	}
+
+	var availability *models.ProductAvailability
+	if variantID != nil {
+		availability, err = h.repo.GetVariantAvailability(ctx, productID, *variantID)
+	} else {
+		availability, err = h.repo.GetProductAvailability(ctx, productID)
+	}
+	if err != nil {
+		if err.Error() == "product not found" {
+			respondError(c, http.StatusNotFound, "PRODUCT_NOT_FOUND", nil)
+			return
+		}
+		if errors.Is(err, repository.ErrVariantNotFound) {
+			respondError(c, http.StatusNotFound, "VARIANT_NOT_FOUND", nil)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "AVAILABILITY_FETCH_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+	if variantID == nil && availability.HasVariants {
+		respondError(c, http.StatusBadRequest, "VARIANT_REQUIRED", gin.H{"details": "variant_id is required for products with variants"})
+		return
+	}
+
+	maxQuantity := 0
+	if availability.IsActive && availability.Stock > 0 {
+		maxQuantity = availability.Stock
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": models.AvailabilityResponse{
+			ProductID:   productID,
+			VariantID:   variantID,
+			Quantity:    quantity,
+			InStock:     quantity <= maxQuantity,
+			MaxQuantity: maxQuantity,
+			IsActive:    availability.IsActive,
+		},
+	})
+}
+
+// authorizeSeller parses the product ID and checks that the user set by the API Gateway owns it
+func (h *StockHandler) authorizeSeller(ctx context.Context, c *gin.Context) (uuid.UUID, bool) {
+	return authorizeProductSeller(ctx, c, h.repo)
+}
+
+// authorizeProductSeller parses the :id product ID and checks that the user set by the API
+// Gateway owns it, writing the error response otherwise
+func authorizeProductSeller(ctx context.Context, c *gin.Context, repo *repository.ProductRepository) (uuid.UUID, bool) {
+	sellerID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return uuid.Nil, false
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PRODUCT_ID", nil)
+		return uuid.Nil, false
+	}
+
+	ownerID, err := repo.GetProductOwner(ctx, productID)
+	if err != nil {
+		if err.Error() == "product not found" {
+			respondError(c, http.StatusNotFound, "PRODUCT_NOT_FOUND", nil)
+			return uuid.Nil, false
+		}
+		respondError(c, http.StatusInternalServerError, "PRODUCT_FETCH_FAILED", gin.H{"details": err.Error()})
+		return uuid.Nil, false
+	}
+
+	if ownerID != sellerID {
+		respondError(c, http.StatusForbidden, "PRODUCT_NOT_OWNED", nil)
+		return uuid.Nil, false
+	}
+
+	return productID, true
+}
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// slugPattern accepts lowercase letters, digits and single hyphens between them
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type StoreHandler struct {
+	repo *repository.ProductRepository
+}
+
+func NewStoreHandler(repo *repository.ProductRepository) *StoreHandler {
+	return &StoreHandler{
+		repo: repo,
+	}
+}
+
+// GetStores handles GET /api/v1/stores, listing active stores
+func (h *StoreHandler) GetStores(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	page, limit := storePagination(c)
+	stores, err := h.repo.ListStores(ctx, nil, page, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "STORES_FETCH_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stores,
+	})
+}
+
+// GetStoreByID handles GET /api/v1/stores/:id
+func (h *StoreHandler) GetStoreByID(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_STORE_ID", nil)
+		return
+	}
+
+	store, err := h.repo.GetStore(ctx, storeID)
+	if err != nil {
+		h.writeStoreError(c, "STORE_FETCH_FAILED", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    store,
+	})
+}
+
+// GetMyStores handles GET /api/v1/seller/stores, listing the seller's stores
+func (h *StoreHandler) GetMyStores(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	sellerID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return
+	}
+
+	page, limit := storePagination(c)
+	stores, err := h.repo.ListStores(ctx, &sellerID, page, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "STORES_FETCH_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stores,
+	})
+}
+
+// CreateStore handles POST /api/v1/seller/stores
+func (h *StoreHandler) CreateStore(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	sellerID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return
+	}
+
+	var req models.CreateStoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{"details": err.Error()})
+		return
+	}
+
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if !slugPattern.MatchString(slug) {
+		respondError(c, http.StatusBadRequest, "INVALID_SLUG", gin.H{"details": "use lowercase letters, digits and hyphens"})
+		return
+	}
+
+	store := &models.Store{
+		OwnerID:     sellerID,
+		Name:        strings.TrimSpace(req.Name),
+		Slug:        slug,
+		Description: req.Description,
+		IsActive:    true,
+	}
+	if err := h.repo.CreateStore(ctx, store); err != nil {
+		h.writeStoreError(c, "STORE_CREATE_FAILED", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    store,
+	})
+}
+
+// UpdateStore handles PUT /api/v1/seller/stores/:id
+func (h *StoreHandler) UpdateStore(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	store, ok := h.authorizeStoreOwner(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req models.UpdateStoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{"details": err.Error()})
+		return
+	}
+
+	if req.Name != nil {
+		store.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		store.Description = *req.Description
+	}
+	if req.IsActive != nil {
+		store.IsActive = *req.IsActive
+	}
+
+	if err := h.repo.UpdateStore(ctx, store); err != nil {
+		h.writeStoreError(c, "STORE_UPDATE_FAILED", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    store,
+	})
+}
+
+// DeleteStore handles DELETE /api/v1/seller/stores/:id. Stores with products can't be deleted.
+func (h *StoreHandler) DeleteStore(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	store, ok := h.authorizeStoreOwner(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteStore(ctx, store.ID); err != nil {
+		h.writeStoreError(c, "STORE_DELETE_FAILED", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Store deleted",
+	})
+}
+
+// AssignProductStore handles PUT /api/v1/seller/products/:id/store. The seller must own
+// both the product and the store.
+func (h *StoreHandler) AssignProductStore(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var req models.AssignStoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{"details": err.Error()})
+		return
+	}
+
+	store, ok := h.authorizeStoreOwner(ctx, c, req.StoreID.String())
+	if !ok {
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PRODUCT_ID", nil)
+		return
+	}
+
+	ownerID, err := h.repo.GetProductOwner(ctx, productID)
+	if err != nil {
+		if err.Error() == "product not found" {
+			respondError(c, http.StatusNotFound, "PRODUCT_NOT_FOUND", nil)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "PRODUCT_FETCH_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+	if ownerID != store.OwnerID {
+		respondError(c, http.StatusForbidden, "PRODUCT_NOT_OWNED", nil)
+		return
+	}
+
+	if err := h.repo.AssignProductStore(ctx, productID, store.ID); err != nil {
+		respondError(c, http.StatusInternalServerError, "PRODUCT_ASSIGN_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"product_id": productID,
+			"store_id":   store.ID,
+		},
+	})
+}
+
+// authorizeStoreOwner loads a store and checks that the user set by the API Gateway owns it
+func (h *StoreHandler) authorizeStoreOwner(ctx context.Context, c *gin.Context, rawStoreID string) (*models.Store, bool) {
+	sellerID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED", nil)
+		return nil, false
+	}
+
+	storeID, err := uuid.Parse(rawStoreID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_STORE_ID", nil)
+		return nil, false
+	}
+
+	store, err := h.repo.GetStore(ctx, storeID)
+	if err != nil {
+		h.writeStoreError(c, "STORE_FETCH_FAILED", err)
+		return nil, false
+	}
+
+	if store.OwnerID != sellerID {
+		respondError(c, http.StatusForbidden, "STORE_NOT_OWNED", nil)
+		return nil, false
+	}
+
+	return store, true
+}
+
+// writeStoreError maps store repository errors to responses, code is the error code of
+// unexpected errors
+func (h *StoreHandler) writeStoreError(c *gin.Context, code string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrStoreNotFound):
+		respondError(c, http.StatusNotFound, "STORE_NOT_FOUND", nil)
+	case errors.Is(err, repository.ErrStoreSlugTaken):
+		respondError(c, http.StatusConflict, "STORE_SLUG_TAKEN", nil)
+	case errors.Is(err, repository.ErrStoreHasProducts):
+		respondError(c, http.StatusConflict, "STORE_HAS_PRODUCTS", gin.H{"details": err.Error()})
+	default:
+		respondError(c, http.StatusInternalServerError, code, gin.H{"details": err.Error()})
+	}
+}
+
+// storePagination reads page and limit query parameters
+func storePagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type VariantHandler struct {
+	repo *repository.ProductRepository
+}
+
+func NewVariantHandler(repo *repository.ProductRepository) *VariantHandler {
+	return &VariantHandler{
+		repo: repo,
+	}
+}
+
+// GetVariants handles GET /api/v1/seller/products/:id/variants, inactive variants included
+func (h *VariantHandler) GetVariants(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	productID, ok := authorizeProductSeller(ctx, c, h.repo)
+	if !ok {
+		return
+	}
+
+	variants, err := h.repo.GetProductVariants(ctx, productID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "VARIANTS_FETCH_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    variants,
+	})
+}
+
+// CreateVariant handles POST /api/v1/seller/products/:id/variants. Once a product has an
+// active variant, customers must choose one to buy it.
+func (h *VariantHandler) CreateVariant(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	productID, ok := authorizeProductSeller(ctx, c, h.repo)
+	if !ok {
+		return
+	}
+
+	var req models.CreateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{"details": err.Error()})
+		return
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+	variant := &models.ProductVariant{
+		ProductID:     productID,
+		SKU:           strings.TrimSpace(req.SKU),
+		Attributes:    req.Attributes,
+		PriceOverride: req.PriceOverride,
+		Stock:         req.Stock,
+		IsActive:      isActive,
+	}
+
+	sellerID, _ := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err := h.repo.CreateVariant(ctx, variant, sellerID); err != nil {
+		if errors.Is(err, repository.ErrVariantSKUTaken) {
+			respondError(c, http.StatusConflict, "SKU_TAKEN", nil)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "VARIANT_CREATE_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    variant,
+	})
+}
+
+// UpdateVariant handles PUT /api/v1/seller/products/:id/variants/:variant_id. Variants are
+// deactivated rather than deleted, orders may still hold their stock.
+func (h *VariantHandler) UpdateVariant(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	productID, ok := authorizeProductSeller(ctx, c, h.repo)
+	if !ok {
+		return
+	}
+
+	variantID, err := uuid.Parse(c.Param("variant_id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_VARIANT_ID", nil)
+		return
+	}
+
+	var req models.UpdateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", gin.H{"details": err.Error()})
+		return
+	}
+	req.SKU = strings.TrimSpace(req.SKU)
+
+	variant, err := h.repo.UpdateVariant(ctx, productID, variantID, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrVariantNotFound) {
+			respondError(c, http.StatusNotFound, "VARIANT_NOT_FOUND", nil)
+			return
+		}
+		if errors.Is(err, repository.ErrVariantSKUTaken) {
+			respondError(c, http.StatusConflict, "SKU_TAKEN", nil)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "VARIANT_UPDATE_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    variant,
+	})
+}
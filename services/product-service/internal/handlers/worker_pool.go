@@ -96,6 +96,16 @@ func (wp *WorkerPool) SubmitRequest(req Request) error {
 	}
 }
 
+// GetQueueDepth returns the number of requests waiting for a worker
+func (wp *WorkerPool) GetQueueDepth() int {
+	return len(wp.requestCh)
+}
+
+// GetQueueCapacity returns the size of the request buffer
+func (wp *WorkerPool) GetQueueCapacity() int {
+	return cap(wp.requestCh)
+}
+
 // GetActiveJobs returns the number of active jobs
 func (wp *WorkerPool) GetActiveJobs() int64 {
 	wp.mu.RLock()
@@ -0,0 +1,204 @@
+package i18n
+
+// catalogs holds the translated messages for every supported locale. Keys are the
+// error codes returned to clients, the English messages are the "error" of the response.
+var catalogs = map[Locale]map[string]string{
+	LocaleEN: {
+		// Generic errors
+		"INVALID_QUERY":       "Invalid query parameters",
+		"SERVICE_UNAVAILABLE": "Service temporarily unavailable",
+		"REQUEST_TIMEOUT":     "Request timeout",
+		"INVALID_RESPONSE":    "Invalid response format",
+
+		// Catalog
+		"INVALID_STORE_ID":      "Invalid store ID",
+		"TOO_MANY_PRODUCT_IDS":  "Too many product IDs",
+		"INVALID_PRODUCT_ID":    "Invalid product ID",
+		"INVALID_SORT":          "Invalid sort",
+		"INVALID_CURSOR":        "Invalid cursor",
+		"PRODUCTS_FETCH_FAILED": "Failed to get products",
+		"PRODUCT_NOT_FOUND":     "Product not found",
+		"PRODUCT_FETCH_FAILED":  "Failed to get product",
+
+		// Price and stock
+		"INVALID_QUANTITY":          "Invalid quantity",
+		"INVALID_VARIANT_ID":        "Invalid variant ID",
+		"VARIANT_NOT_FOUND":         "Variant not found",
+		"VARIANT_REQUIRED":          "Variant required",
+		"PRICING_FAILED":            "Failed to price product",
+		"AVAILABILITY_FETCH_FAILED": "Failed to get product availability",
+
+		// Stock
+		"STOCK_MOVEMENTS_FETCH_FAILED": "Failed to get stock movements",
+		"INVALID_REQUEST":              "Invalid request format",
+		"INVALID_RESTOCK_DELTA":        "Restock delta must be positive",
+		"INSUFFICIENT_STOCK":           "Insufficient stock",
+		"STOCK_ADJUST_FAILED":          "Failed to adjust stock",
+		"USER_NOT_AUTHENTICATED":       "User not authenticated",
+		"PRODUCT_NOT_OWNED":            "You do not own this product",
+
+		// Variants
+		"VARIANTS_FETCH_FAILED": "Failed to get variants",
+		"SKU_TAKEN":             "SKU already taken",
+		"VARIANT_CREATE_FAILED": "Failed to create variant",
+		"VARIANT_UPDATE_FAILED": "Failed to update variant",
+
+		// Images
+		"INVALID_IMAGE_ORDER":  "Invalid image order",
+		"IMAGE_REORDER_FAILED": "Failed to reorder images",
+		"INVALID_IMAGE_ID":     "Invalid image ID",
+		"IMAGE_NOT_FOUND":      "Image not found",
+		"PRIMARY_IMAGE_FAILED": "Failed to set primary image",
+
+		// Stores
+		"STORES_FETCH_FAILED":   "Failed to get stores",
+		"INVALID_SLUG":          "Invalid slug",
+		"PRODUCT_ASSIGN_FAILED": "Failed to assign product to store",
+		"STORE_NOT_OWNED":       "You do not own this store",
+		"STORE_NOT_FOUND":       "Store not found",
+		"STORE_SLUG_TAKEN":      "Store slug already taken",
+		"STORE_HAS_PRODUCTS":    "Store still has products",
+
+		// Pricing rules
+		"PRICING_RULES_FETCH_FAILED": "Failed to get pricing rules",
+		"INVALID_PRICING_RULE":       "Invalid pricing rule",
+		"PRICING_RULE_CREATE_FAILED": "Failed to create pricing rule",
+		"PRICING_RULE_UPDATE_FAILED": "Failed to update pricing rule",
+		"INVALID_PRICING_RULE_ID":    "Invalid pricing rule ID",
+		"PRICING_RULE_NOT_FOUND":     "Pricing rule not found",
+		"PRICING_RULE_DELETE_FAILED": "Failed to delete pricing rule",
+		"PRICING_RULE_FETCH_FAILED":  "Failed to get pricing rule",
+		"STORE_FETCH_FAILED":         "Failed to get store",
+		"STORE_CREATE_FAILED":        "Failed to create store",
+		"STORE_UPDATE_FAILED":        "Failed to update store",
+		"STORE_DELETE_FAILED":        "Failed to delete store",
+
+		// Bulk updates
+		"NO_CHANGES":               "No changes requested",
+		"INVALID_PUBLISH_WINDOW":   "unpublish_at must be after publish_at",
+		"PRODUCTS_NOT_OWNED":       "Some products were not found or are not yours",
+		"INVALID_PRICE_ADJUSTMENT": "Price adjustment results in an invalid price",
+		"PRODUCTS_UPDATE_FAILED":   "Failed to update products",
+
+		// Moderation
+		"INVALID_MODERATION_STATUS": "Invalid moderation status",
+		"MODERATION_UPDATE_FAILED":  "Failed to update moderation status",
+
+		// Analytics
+		"INVALID_TO_DATE":       "Invalid to date",
+		"INVALID_FROM_DATE":     "Invalid from date",
+		"INVALID_DATE_RANGE":    "Invalid date range",
+		"PRODUCT_FUNNEL_FAILED": "Failed to get product funnel",
+
+		// Cache
+		"CACHE_STATS_FAILED":      "Failed to get cache stats",
+		"UNKNOWN_CACHE_NAMESPACE": "Unknown cache namespace",
+		"CACHE_PURGE_FAILED":      "Failed to purge cache",
+
+		// Sandbox
+		"SANDBOX_RESET_FAILED":   "Failed to reset sandbox data",
+		"FIXTURES_CREATE_FAILED": "Failed to create fixtures",
+
+		// Authentication
+		"SERVICE_AUTH_REQUIRED": "Service authentication required",
+	},
+	LocaleID: {
+		// Generic errors
+		"INVALID_QUERY":       "Parameter query tidak valid",
+		"SERVICE_UNAVAILABLE": "Layanan sementara tidak tersedia",
+		"REQUEST_TIMEOUT":     "Waktu permintaan habis",
+		"INVALID_RESPONSE":    "Format respons tidak valid",
+
+		// Catalog
+		"INVALID_STORE_ID":      "ID toko tidak valid",
+		"TOO_MANY_PRODUCT_IDS":  "Terlalu banyak ID produk",
+		"INVALID_PRODUCT_ID":    "ID produk tidak valid",
+		"INVALID_SORT":          "Urutan tidak valid",
+		"INVALID_CURSOR":        "Cursor tidak valid",
+		"PRODUCTS_FETCH_FAILED": "Gagal mengambil daftar produk",
+		"PRODUCT_NOT_FOUND":     "Produk tidak ditemukan",
+		"PRODUCT_FETCH_FAILED":  "Gagal mengambil produk",
+
+		// Price and stock
+		"INVALID_QUANTITY":          "Jumlah tidak valid",
+		"INVALID_VARIANT_ID":        "ID varian tidak valid",
+		"VARIANT_NOT_FOUND":         "Varian tidak ditemukan",
+		"VARIANT_REQUIRED":          "Silakan pilih varian produk",
+		"PRICING_FAILED":            "Gagal menghitung harga produk",
+		"AVAILABILITY_FETCH_FAILED": "Gagal mengambil ketersediaan produk",
+
+		// Stock
+		"STOCK_MOVEMENTS_FETCH_FAILED": "Gagal mengambil riwayat stok",
+		"INVALID_REQUEST":              "Format request tidak valid",
+		"INVALID_RESTOCK_DELTA":        "Jumlah restock harus positif",
+		"INSUFFICIENT_STOCK":           "Stok tidak mencukupi",
+		"STOCK_ADJUST_FAILED":          "Gagal menyesuaikan stok",
+		"USER_NOT_AUTHENTICATED":       "Pengguna belum terautentikasi",
+		"PRODUCT_NOT_OWNED":            "Anda bukan pemilik produk ini",
+
+		// Variants
+		"VARIANTS_FETCH_FAILED": "Gagal mengambil varian",
+		"SKU_TAKEN":             "SKU sudah digunakan",
+		"VARIANT_CREATE_FAILED": "Gagal membuat varian",
+		"VARIANT_UPDATE_FAILED": "Gagal memperbarui varian",
+
+		// Images
+		"INVALID_IMAGE_ORDER":  "Urutan gambar tidak valid",
+		"IMAGE_REORDER_FAILED": "Gagal mengurutkan ulang gambar",
+		"INVALID_IMAGE_ID":     "ID gambar tidak valid",
+		"IMAGE_NOT_FOUND":      "Gambar tidak ditemukan",
+		"PRIMARY_IMAGE_FAILED": "Gagal mengatur gambar utama",
+
+		// Stores
+		"STORES_FETCH_FAILED":   "Gagal mengambil data toko",
+		"INVALID_SLUG":          "Slug tidak valid",
+		"PRODUCT_ASSIGN_FAILED": "Gagal memasukkan produk ke toko",
+		"STORE_NOT_OWNED":       "Anda bukan pemilik toko ini",
+		"STORE_NOT_FOUND":       "Toko tidak ditemukan",
+		"STORE_SLUG_TAKEN":      "Slug toko sudah digunakan",
+		"STORE_HAS_PRODUCTS":    "Toko masih memiliki produk",
+
+		// Pricing rules
+		"PRICING_RULES_FETCH_FAILED": "Gagal mengambil aturan harga",
+		"INVALID_PRICING_RULE":       "Aturan harga tidak valid",
+		"PRICING_RULE_CREATE_FAILED": "Gagal membuat aturan harga",
+		"PRICING_RULE_UPDATE_FAILED": "Gagal memperbarui aturan harga",
+		"INVALID_PRICING_RULE_ID":    "ID aturan harga tidak valid",
+		"PRICING_RULE_NOT_FOUND":     "Aturan harga tidak ditemukan",
+		"PRICING_RULE_DELETE_FAILED": "Gagal menghapus aturan harga",
+		"PRICING_RULE_FETCH_FAILED":  "Gagal mengambil aturan harga",
+		"STORE_FETCH_FAILED":         "Gagal mengambil data toko",
+		"STORE_CREATE_FAILED":        "Gagal membuat toko",
+		"STORE_UPDATE_FAILED":        "Gagal memperbarui toko",
+		"STORE_DELETE_FAILED":        "Gagal menghapus toko",
+
+		// Bulk updates
+		"NO_CHANGES":               "Tidak ada perubahan yang diminta",
+		"INVALID_PUBLISH_WINDOW":   "unpublish_at harus setelah publish_at",
+		"PRODUCTS_NOT_OWNED":       "Sebagian produk tidak ditemukan atau bukan milik Anda",
+		"INVALID_PRICE_ADJUSTMENT": "Penyesuaian harga menghasilkan harga yang tidak valid",
+		"PRODUCTS_UPDATE_FAILED":   "Gagal memperbarui produk",
+
+		// Moderation
+		"INVALID_MODERATION_STATUS": "Status moderasi tidak valid",
+		"MODERATION_UPDATE_FAILED":  "Gagal memperbarui status moderasi",
+
+		// Analytics
+		"INVALID_TO_DATE":       "Tanggal to tidak valid",
+		"INVALID_FROM_DATE":     "Tanggal from tidak valid",
+		"INVALID_DATE_RANGE":    "Rentang tanggal tidak valid",
+		"PRODUCT_FUNNEL_FAILED": "Gagal mengambil funnel produk",
+
+		// Cache
+		"CACHE_STATS_FAILED":      "Gagal mengambil statistik cache",
+		"UNKNOWN_CACHE_NAMESPACE": "Namespace cache tidak dikenal",
+		"CACHE_PURGE_FAILED":      "Gagal membersihkan cache",
+
+		// Sandbox
+		"SANDBOX_RESET_FAILED":   "Gagal mereset data sandbox",
+		"FIXTURES_CREATE_FAILED": "Gagal membuat data fixture",
+
+		// Authentication
+		"SERVICE_AUTH_REQUIRED": "Autentikasi layanan diperlukan",
+	},
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"product-service/internal/redact"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger logs every request with sensitive query parameters redacted. With
+// LOG_REQUEST_BODIES=true it also logs request headers and JSON bodies, sensitive fields
+// redacted, to debug integrations without leaking tokens, OTPs or customer data.
+func RequestLogger() gin.HandlerFunc {
+	logBodies := os.Getenv("LOG_REQUEST_BODIES") == "true"
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		var body []byte
+		if logBodies && c.Request.Body != nil {
+			// Read a bounded prefix and hand the handler the full body back
+			body, _ = io.ReadAll(io.LimitReader(c.Request.Body, 64<<10))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+		}
+
+		c.Next()
+
+		log.Printf("%s \"%s %s %s\" %d %s \"%s\" %s",
+			c.ClientIP(),
+			c.Request.Method,
+			redact.URL(c.Request.URL),
+			c.Request.Proto,
+			c.Writer.Status(),
+			time.Since(start),
+			c.Request.UserAgent(),
+			c.Errors.ByType(gin.ErrorTypePrivate).String(),
+		)
+		if logBodies {
+			log.Printf("   headers: %s", redact.Headers(c.Request.Header))
+			if len(body) > 0 {
+				log.Printf("   body: %s", redact.JSON(body))
+			}
+		}
+	}
+}
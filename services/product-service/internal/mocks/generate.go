@@ -0,0 +1,7 @@
+// Package mocks holds gomock mocks of the Product-Service interfaces, generated with
+// `make generate` and not checked in
+package mocks
+
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../events/bus.go -destination=bus_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../repository/product_repository.go -destination=repository_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../services/moderation.go -destination=moderation_mock.go -package=mocks
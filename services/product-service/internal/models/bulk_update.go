@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Price adjustment types for bulk updates
+const (
+	PriceAdjustSet     = "set"     // price = value
+	PriceAdjustAmount  = "amount"  // price += value
+	PriceAdjustPercent = "percent" // price += price * value / 100
+)
+
+// Reasons reported on product.updated for status and schedule changes
+const (
+	ProductReasonBulkUpdate         = "bulk_update"
+	ProductReasonScheduledPublish   = "scheduled_publish"
+	ProductReasonScheduledUnpublish = "scheduled_unpublish"
+)
+
+// PriceAdjustment describes a price change applied to every product of a bulk update
+type PriceAdjustment struct {
+	Type  string  `json:"type" binding:"required,oneof=set amount percent"`
+	Value float64 `json:"value"`
+}
+
+// BulkProductUpdateRequest changes several of a seller's products at once. Every field is
+// optional; only the ones provided are applied, all products or none.
+type BulkProductUpdateRequest struct {
+	ProductIDs    []uuid.UUID      `json:"product_ids" binding:"required,min=1,max=500"` // bounds a single transaction
+	IsActive      *bool            `json:"is_active,omitempty"`
+	Price         *PriceAdjustment `json:"price,omitempty"`
+	PublishAt     *time.Time       `json:"publish_at,omitempty"`
+	UnpublishAt   *time.Time       `json:"unpublish_at,omitempty"`
+	ClearSchedule bool             `json:"clear_schedule,omitempty"` // removes pending publish_at/unpublish_at
+}
+
+// BulkProductUpdateResponse lists the products after a bulk update
+type BulkProductUpdateResponse struct {
+	Updated  int               `json:"updated"`
+	Products []ProductResponse `json:"products"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Conversion funnel steps counted from events
+const (
+	FunnelStepCheckout = "checkout" // checkout.init from Payment-Service, one per payment attempt
+	FunnelStepPayment  = "payment"  // payment.success from Payment-Service, one per order
+)
+
+// ProductFunnelDay counts the conversion funnel of a product on one day (UTC): product
+// detail views, checkouts started and orders paid. Revenue is the paid total in rupiah.
+type ProductFunnelDay struct {
+	ProductID uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	Day       time.Time `json:"day" gorm:"type:date;primaryKey"`
+	Views     int64     `json:"views" gorm:"not null;default:0"`
+	Checkouts int64     `json:"checkouts" gorm:"not null;default:0"`
+	Payments  int64     `json:"payments" gorm:"not null;default:0"`
+	Revenue   int64     `json:"revenue" gorm:"not null;default:0"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+// ProductFunnelEvent remembers a checkout or payment already counted, so redelivered events
+// don't count twice
+type ProductFunnelEvent struct {
+	Step      string    `gorm:"type:varchar(20);primaryKey"`
+	Ref       string    `gorm:"type:varchar(100);primaryKey"` // Payment ID of checkouts, order ID of payments
+	ProductID uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time
+}
+
+// ProductFunnel is a product's conversion funnel over a date range. Rates are step to step
+// conversions; without entries into a step they stay 0.
+type ProductFunnel struct {
+	ProductID         uuid.UUID          `json:"product_id"`
+	From              string             `json:"from"` // YYYY-MM-DD, inclusive
+	To                string             `json:"to"`   // YYYY-MM-DD, inclusive
+	Views             int64              `json:"views"`
+	Checkouts         int64              `json:"checkouts"`
+	Payments          int64              `json:"payments"`
+	Revenue           int64              `json:"revenue"`
+	ViewToCheckout    float64            `json:"view_to_checkout"`
+	CheckoutToPayment float64            `json:"checkout_to_payment"`
+	ViewToPayment     float64            `json:"view_to_payment"`
+	Days              []ProductFunnelDay `json:"days"`
+}
+
+// ComputeRates fills the totals and rates from the days
+func (f *ProductFunnel) ComputeRates() {
+	f.Views, f.Checkouts, f.Payments, f.Revenue = 0, 0, 0, 0
+	for _, day := range f.Days {
+		f.Views += day.Views
+		f.Checkouts += day.Checkouts
+		f.Payments += day.Payments
+		f.Revenue += day.Revenue
+	}
+
+	if f.Views > 0 {
+		f.ViewToCheckout = float64(f.Checkouts) / float64(f.Views)
+		f.ViewToPayment = float64(f.Payments) / float64(f.Views)
+	}
+	if f.Checkouts > 0 {
+		f.CheckoutToPayment = float64(f.Payments) / float64(f.Checkouts)
+	}
+}
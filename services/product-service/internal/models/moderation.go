@@ -0,0 +1,30 @@
+package models
+
+// ModerationStatus is the review state of a product listing
+type ModerationStatus string
+
+const (
+	ModerationPendingReview ModerationStatus = "pending_review"
+	ModerationApproved      ModerationStatus = "approved"
+	ModerationRejected      ModerationStatus = "rejected"
+)
+
+// Reasons reported on product.updated for moderation decisions
+const (
+	ProductReasonModerationApproved = "moderation_approved"
+	ProductReasonModerationRejected = "moderation_rejected"
+)
+
+// IsValid reports whether s is a known moderation status
+func (s ModerationStatus) IsValid() bool {
+	switch s {
+	case ModerationPendingReview, ModerationApproved, ModerationRejected:
+		return true
+	}
+	return false
+}
+
+// RejectProductRequest is the body of an admin rejecting a product
+type RejectProductRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// Data ownership policy
+//
+// Every table belongs to exactly one service. A service migrates and writes only:
+//   - OwnedModels: the data it is the source of truth for
+//   - ReadModels: local projections of data owned by another service, written only by
+//     event consumers and never the target of a foreign key constraint
+//
+// Relations to read models are tagged -:migration so AutoMigrate doesn't create constraints.
+
+// OwnedModels returns the tables owned by Product-Service
+func OwnedModels() []interface{} {
+	return []interface{}{
+		&Store{},
+		&Product{},
+		&ProductImage{},
+		&ProductVariant{},
+		&StockMovement{},
+		&PricingRule{},
+		&ProductFunnelDay{},
+		&ProductFunnelEvent{},
+	}
+}
+
+// ReadModels returns the projections of other services' data kept by Product-Service
+func ReadModels() []interface{} {
+	return []interface{}{
+		&UserProfile{},
+	}
+}
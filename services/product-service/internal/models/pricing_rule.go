@@ -0,0 +1,131 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PricingRuleType is how a pricing rule lowers the price
+type PricingRuleType string
+
+const (
+	PricingRulePercentage PricingRuleType = "percentage" // Value percent off the unit price
+	PricingRuleFixed      PricingRuleType = "fixed"      // Value rupiah off the unit price
+)
+
+// PricingRule discounts products while it is active. A rule targets one product, every
+// product of a store, or the whole catalogue when neither is set. Member-only rules apply to
+// signed-in customers, MinQuantity to orders of at least that many units.
+type PricingRule struct {
+	ID          uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name        string          `json:"name" gorm:"type:varchar(150);not null"`
+	Type        PricingRuleType `json:"type" gorm:"type:varchar(20);not null"`
+	Value       float64         `json:"value" gorm:"not null"`
+	ProductID   *uuid.UUID      `json:"product_id,omitempty" gorm:"type:uuid;index"`
+	StoreID     *uuid.UUID      `json:"store_id,omitempty" gorm:"type:uuid;index"`
+	MinQuantity int             `json:"min_quantity" gorm:"not null;default:1"`
+	MemberOnly  bool            `json:"member_only" gorm:"default:false"`
+	StartsAt    *time.Time      `json:"starts_at,omitempty"`
+	EndsAt      *time.Time      `json:"ends_at,omitempty" gorm:"index"`
+	IsActive    bool            `json:"is_active" gorm:"default:true"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// CreatePricingRuleRequest represents the request payload for creating a pricing rule
+type CreatePricingRuleRequest struct {
+	Name        string          `json:"name" binding:"required,max=150"`
+	Type        PricingRuleType `json:"type" binding:"required,oneof=percentage fixed"`
+	Value       float64         `json:"value" binding:"required,gt=0"`
+	ProductID   *uuid.UUID      `json:"product_id,omitempty"`
+	StoreID     *uuid.UUID      `json:"store_id,omitempty"`
+	MinQuantity int             `json:"min_quantity" binding:"min=0"`
+	MemberOnly  bool            `json:"member_only"`
+	StartsAt    *time.Time      `json:"starts_at,omitempty"`
+	EndsAt      *time.Time      `json:"ends_at,omitempty"`
+	IsActive    *bool           `json:"is_active,omitempty"`
+}
+
+// UpdatePricingRuleRequest represents the request payload for updating a pricing rule,
+// omitted fields are kept. The target (product or store) can't be changed.
+type UpdatePricingRuleRequest struct {
+	Name        *string          `json:"name,omitempty" binding:"omitempty,max=150"`
+	Type        *PricingRuleType `json:"type,omitempty" binding:"omitempty,oneof=percentage fixed"`
+	Value       *float64         `json:"value,omitempty" binding:"omitempty,gt=0"`
+	MinQuantity *int             `json:"min_quantity,omitempty" binding:"omitempty,min=0"`
+	MemberOnly  *bool            `json:"member_only,omitempty"`
+	StartsAt    *time.Time       `json:"starts_at,omitempty"`
+	EndsAt      *time.Time       `json:"ends_at,omitempty"`
+	IsActive    *bool            `json:"is_active,omitempty"`
+}
+
+// PricingRuleListResponse represents a paginated list of pricing rules
+type PricingRuleListResponse struct {
+	Rules   []PricingRule `json:"rules"`
+	Total   int64         `json:"total"`
+	Page    int           `json:"page"`
+	Limit   int           `json:"limit"`
+	HasMore bool          `json:"has_more"`
+}
+
+// AppliedPricingRule is the rule that set a price, as shown to customers
+type AppliedPricingRule struct {
+	ID         uuid.UUID       `json:"id"`
+	Name       string          `json:"name"`
+	Type       PricingRuleType `json:"type"`
+	Value      float64         `json:"value"`
+	MemberOnly bool            `json:"member_only"`
+	EndsAt     *time.Time      `json:"ends_at,omitempty"`
+}
+
+// PriceQuote is the price of a quantity of a product after pricing rules
+type PriceQuote struct {
+	ProductID uuid.UUID           `json:"product_id"`
+	VariantID *uuid.UUID          `json:"variant_id,omitempty"` // base_price is then the variant's price
+	Quantity  int                 `json:"quantity"`
+	Member    bool                `json:"member"`
+	BasePrice float64             `json:"base_price"`
+	UnitPrice float64             `json:"unit_price"`
+	Discount  float64             `json:"discount"` // off each unit
+	Total     float64             `json:"total"`
+	Rule      *AppliedPricingRule `json:"rule,omitempty"`
+}
+
+// Applies reports whether the rule discounts product at time now for an order of quantity
+func (r *PricingRule) Applies(product *ProductResponse, quantity int, member bool, now time.Time) bool {
+	if !r.IsActive || (r.MemberOnly && !member) || quantity < r.MinQuantity {
+		return false
+	}
+	if (r.StartsAt != nil && now.Before(*r.StartsAt)) || (r.EndsAt != nil && !now.Before(*r.EndsAt)) {
+		return false
+	}
+	if r.ProductID != nil {
+		return *r.ProductID == product.ID
+	}
+	if r.StoreID != nil {
+		return product.StoreID != nil && *r.StoreID == *product.StoreID
+	}
+	return true
+}
+
+// ToApplied converts a rule to the form shown to customers
+func (r *PricingRule) ToApplied() *AppliedPricingRule {
+	return &AppliedPricingRule{
+		ID:         r.ID,
+		Name:       r.Name,
+		Type:       r.Type,
+		Value:      r.Value,
+		MemberOnly: r.MemberOnly,
+		EndsAt:     r.EndsAt,
+	}
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (r *PricingRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
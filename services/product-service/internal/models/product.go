@@ -1,6 +1,7 @@
 package models
 
 import (
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,17 +10,26 @@ import (
 
 // Product represents the product model in the database
 type Product struct {
-	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	UserID      uuid.UUID      `json:"user_id" gorm:"type:uuid;not null"`
-	User        User           `json:"user" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Name        string         `json:"name" gorm:"type:varchar(200);not null"`
-	Description string         `json:"description" gorm:"type:text"`
-	Price       float64        `json:"price" gorm:"not null"`
-	Stock       int            `json:"stock" gorm:"not null;default:0"`
-	IsActive    bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	Images      []ProductImage `json:"images" gorm:"foreignKey:ProductID"`
+	ID          uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      uuid.UUID   `json:"user_id" gorm:"type:uuid;not null"`
+	User        UserProfile `json:"user" gorm:"foreignKey:UserID;-:migration"`
+	StoreID     *uuid.UUID  `json:"store_id" gorm:"type:uuid;index"` // nil only for products created before stores
+	Store       *Store      `json:"store,omitempty" gorm:"foreignKey:StoreID;constraint:OnDelete:RESTRICT;"`
+	Name        string      `json:"name" gorm:"type:varchar(200);not null"`
+	Description string      `json:"description" gorm:"type:text"`
+	Price       float64     `json:"price" gorm:"not null"`
+	Stock       int         `json:"stock" gorm:"not null;default:0"`
+	IsActive    bool        `json:"is_active" gorm:"default:true"`
+	PublishAt   *time.Time  `json:"publish_at" gorm:"index"`   // activated by the publish scheduler
+	UnpublishAt *time.Time  `json:"unpublish_at" gorm:"index"` // deactivated by the publish scheduler
+	// Only approved products are listed and sold; ones created before moderation are approved
+	ModerationStatus ModerationStatus `json:"moderation_status" gorm:"type:varchar(20);not null;default:'approved';index"`
+	ModerationReason *string          `json:"moderation_reason"`
+	ModeratedAt      *time.Time       `json:"moderated_at"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	Images           []ProductImage   `json:"images" gorm:"foreignKey:ProductID"`
+	Variants         []ProductVariant `json:"variants" gorm:"foreignKey:ProductID"`
 }
 
 // ProductImage represents the product image model in the database
@@ -28,35 +38,45 @@ type ProductImage struct {
 	ProductID uuid.UUID `json:"product_id" gorm:"type:uuid;not null"`
 	Product   Product   `json:"-" gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	ImageUrl  string    `json:"image_url" gorm:"type:varchar(500);not null"`
+	Position  int       `json:"position" gorm:"not null;default:0"`       // gallery order, lowest first
+	IsPrimary bool      `json:"is_primary" gorm:"not null;default:false"` // thumbnail, at most one per product
 	CreatedAt time.Time `json:"created_at"`
 }
 
-// User represents a simplified user model for foreign key relationship
-type User struct {
-	ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
-	Username string    `json:"username"`
-	Email    string    `json:"email"`
+// ReorderImagesRequest lists every image of a product in its new gallery order
+type ReorderImagesRequest struct {
+	ImageIDs []uuid.UUID `json:"image_ids" binding:"required,min=1"`
 }
 
 // ProductResponse represents the response payload for product data
 type ProductResponse struct {
-	ID          uuid.UUID           `json:"id"`
-	UserID      uuid.UUID           `json:"user_id"`
-	User        User                `json:"user"`
-	Name        string              `json:"name"`
-	Description string              `json:"description"`
-	Price       float64             `json:"price"`
-	Stock       int                 `json:"stock"`
-	IsActive    bool                `json:"is_active"`
-	CreatedAt   time.Time           `json:"created_at"`
-	UpdatedAt   time.Time           `json:"updated_at"`
-	Images      []ProductImage      `json:"images"`
+	ID               uuid.UUID           `json:"id"`
+	UserID           uuid.UUID           `json:"user_id"`
+	User             UserProfile         `json:"user"`
+	StoreID          *uuid.UUID          `json:"store_id"`
+	Store            *Store              `json:"store,omitempty"`
+	Name             string              `json:"name"`
+	Description      string              `json:"description"`
+	Price            float64             `json:"price"`
+	FinalPrice       float64             `json:"final_price"`            // price after pricing rules
+	MemberPrice      float64             `json:"member_price"`           // final price for signed-in customers
+	PricingRule      *AppliedPricingRule `json:"pricing_rule,omitempty"` // rule setting final_price
+	Stock            int                 `json:"stock"` // sum of the active variants' stock for products with variants
+	IsActive         bool                `json:"is_active"`
+	PublishAt        *time.Time          `json:"publish_at,omitempty"`
+	UnpublishAt      *time.Time          `json:"unpublish_at,omitempty"`
+	ModerationStatus ModerationStatus    `json:"moderation_status"`
+	ModerationReason *string             `json:"moderation_reason,omitempty"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+	Images           []ProductImage      `json:"images"`
+	Variants         []ProductVariant    `json:"variants,omitempty"` // active variants, one must be chosen to buy
 }
 
 // ProductListResponse represents the response payload for paginated product list
 type ProductListResponse struct {
 	Products   []ProductResponse `json:"products"`
-	Total      int64             `json:"total"`
+	Total      int64             `json:"total"` // -1 when the query skipped counting
 	Page       int               `json:"page"`
 	Limit      int               `json:"limit"`
 	HasMore    bool              `json:"has_more"`
@@ -67,11 +87,15 @@ type ProductListResponse struct {
 type ProductQuery struct {
 	Page     int     `form:"page"`
 	Limit    int     `form:"limit"`
-	Cursor   string  `form:"cursor"`
+	Cursor   string  `form:"cursor"` // opaque signed token from next_cursor
+	Sort     string  `form:"sort"`   // newest, oldest, price_asc, price_desc, name_asc, name_desc; ID order by default
 	Search   string  `form:"search"`
 	MinPrice *float64 `form:"min_price"`
 	MaxPrice *float64 `form:"max_price"`
 	IsActive *bool   `form:"is_active"`
+	StoreID  string  `form:"store_id"`
+	IDs      string  `form:"ids"`      // comma separated product IDs, at most 100, e.g. to batch lookups
+	SkipTotal bool   `form:"skip_total"` // skip counting matches, total is returned as -1
 }
 
 // BeforeCreate hook to set UUID if not provided
@@ -92,17 +116,46 @@ func (pi *ProductImage) BeforeCreate(tx *gorm.DB) error {
 
 // ToResponse converts Product to ProductResponse
 func (p *Product) ToResponse() ProductResponse {
+	stock := p.Stock
+	variants := ActiveVariants(p.Variants)
+	if len(variants) > 0 {
+		stock = 0
+		for _, variant := range variants {
+			stock += variant.Stock
+		}
+	}
+
 	return ProductResponse{
-		ID:          p.ID,
-		UserID:      p.UserID,
-		User:        p.User,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-		Stock:       p.Stock,
-		IsActive:    p.IsActive,
-		CreatedAt:   p.CreatedAt,
-		UpdatedAt:   p.UpdatedAt,
-		Images:      p.Images,
+		ID:               p.ID,
+		UserID:           p.UserID,
+		User:             p.User,
+		StoreID:          p.StoreID,
+		Store:            p.Store,
+		Name:             p.Name,
+		Description:      p.Description,
+		Price:            p.Price,
+		FinalPrice:       p.Price,
+		MemberPrice:      p.Price,
+		Stock:            stock,
+		IsActive:         p.IsActive,
+		PublishAt:        p.PublishAt,
+		UnpublishAt:      p.UnpublishAt,
+		ModerationStatus: p.ModerationStatus,
+		ModerationReason: p.ModerationReason,
+		CreatedAt:        p.CreatedAt,
+		UpdatedAt:        p.UpdatedAt,
+		Images:           SortImages(p.Images),
+		Variants:         variants,
 	}
 }
+
+// SortImages orders images by position, images created before positions existed by age
+func SortImages(images []ProductImage) []ProductImage {
+	sort.SliceStable(images, func(i, j int) bool {
+		if images[i].Position != images[j].Position {
+			return images[i].Position < images[j].Position
+		}
+		return images[i].CreatedAt.Before(images[j].CreatedAt)
+	})
+	return images
+}
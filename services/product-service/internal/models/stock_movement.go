@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stock movement reasons
+const (
+	StockReasonSale               = "sale"
+	StockReasonRestock            = "restock"
+	StockReasonAdjustment         = "adjustment"
+	StockReasonReservationRelease = "reservation_release"
+)
+
+// Stock movement actor types
+const (
+	StockActorSeller = "seller"
+	StockActorSystem = "system"
+)
+
+// StockMovement records a single change to a product's stock
+type StockMovement struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ProductID   uuid.UUID  `json:"product_id" gorm:"type:uuid;not null;index"`
+	VariantID   *uuid.UUID `json:"variant_id,omitempty" gorm:"type:uuid;index"` // set when a variant's stock changed
+	Delta       int        `json:"delta" gorm:"not null"`
+	StockBefore int        `json:"stock_before" gorm:"not null"`
+	StockAfter  int        `json:"stock_after" gorm:"not null"`
+	Reason      string     `json:"reason" gorm:"type:varchar(50);not null"`
+	ActorType   string     `json:"actor_type" gorm:"type:varchar(20);not null"`
+	ActorID     *uuid.UUID `json:"actor_id" gorm:"type:uuid"`
+	OrderID     *string    `json:"order_id" gorm:"type:varchar(100);index"`
+	Note        *string    `json:"note" gorm:"type:text"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"index"`
+}
+
+// StockAdjustmentRequest represents a seller restock or manual adjustment
+type StockAdjustmentRequest struct {
+	VariantID *uuid.UUID `json:"variant_id,omitempty"` // adjusts the variant's stock instead of the product's
+	Delta     int        `json:"delta" binding:"required"`
+	Reason    string     `json:"reason" binding:"required,oneof=restock adjustment"`
+	Note      *string    `json:"note,omitempty"`
+}
+
+// StockMovementListResponse represents a paginated list of stock movements
+type StockMovementListResponse struct {
+	Movements []StockMovement `json:"movements"`
+	Total     int64           `json:"total"`
+	Page      int             `json:"page"`
+	Limit     int             `json:"limit"`
+	HasMore   bool            `json:"has_more"`
+}
+
+// ProductAvailability is the cached stock snapshot behind the checkout pre-check
+type ProductAvailability struct {
+	ProductID   uuid.UUID `json:"product_id"`
+	Stock       int       `json:"stock"`
+	IsActive    bool      `json:"is_active"`
+	HasVariants bool      `json:"has_variants"` // stock is then checked per variant
+}
+
+// AvailabilityResponse answers whether a quantity of a product can be bought right now
+type AvailabilityResponse struct {
+	ProductID   uuid.UUID  `json:"product_id"`
+	VariantID   *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity    int        `json:"quantity"`
+	InStock     bool       `json:"in_stock"`
+	MaxQuantity int        `json:"max_quantity"`
+	IsActive    bool       `json:"is_active"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Store is a merchant storefront owned by a seller. Products belong to a store and
+// payments for them are charged with the store's Midtrans credentials when configured.
+type Store struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	OwnerID     uuid.UUID `json:"owner_id" gorm:"type:uuid;not null;index"`
+	Name        string    `json:"name" gorm:"type:varchar(150);not null"`
+	Slug        string    `json:"slug" gorm:"type:varchar(150);uniqueIndex;not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	IsActive    bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateStoreRequest represents the request payload for creating a store
+type CreateStoreRequest struct {
+	Name        string `json:"name" binding:"required,max=150"`
+	Slug        string `json:"slug" binding:"required,max=150"`
+	Description string `json:"description"`
+}
+
+// UpdateStoreRequest represents the request payload for updating a store, omitted fields are kept
+type UpdateStoreRequest struct {
+	Name        *string `json:"name,omitempty" binding:"omitempty,max=150"`
+	Description *string `json:"description,omitempty"`
+	IsActive    *bool   `json:"is_active,omitempty"`
+}
+
+// AssignStoreRequest moves a product to one of the seller's stores
+type AssignStoreRequest struct {
+	StoreID uuid.UUID `json:"store_id" binding:"required"`
+}
+
+// StoreListResponse represents a paginated list of stores
+type StoreListResponse struct {
+	Stores  []Store `json:"stores"`
+	Total   int64   `json:"total"`
+	Page    int     `json:"page"`
+	Limit   int     `json:"limit"`
+	HasMore bool    `json:"has_more"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (s *Store) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserProfile is Product-Service's read model of a user owned by User-Service.
+// It is populated from user.* events and only used to show the seller of a product.
+type UserProfile struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	Username  string    `json:"username" gorm:"size:100"`
+	Email     string    `json:"email" gorm:"size:150"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+// TableName specifies the table name for UserProfile
+func (UserProfile) TableName() string {
+	return "user_profiles"
+}
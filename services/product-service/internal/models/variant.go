@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProductVariant is one purchasable version of a product, e.g. a size and color. A product
+// with active variants is bought per variant, each with its own stock and optionally its own
+// price; products without variants are sold as before.
+type ProductVariant struct {
+	ID            uuid.UUID         `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ProductID     uuid.UUID         `json:"product_id" gorm:"type:uuid;not null;index"`
+	Product       Product           `json:"-" gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SKU           string            `json:"sku" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Attributes    map[string]string `json:"attributes" gorm:"type:jsonb;serializer:json;not null"` // e.g. {"size": "L", "color": "Hitam"}
+	PriceOverride *float64          `json:"price_override"`                                        // nil sells at the product price
+	Stock         int               `json:"stock" gorm:"not null;default:0"`
+	IsActive      bool              `json:"is_active" gorm:"not null"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// CreateVariantRequest adds a variant to a product, its initial stock is recorded as a restock
+type CreateVariantRequest struct {
+	SKU           string            `json:"sku" binding:"required,max=64"`
+	Attributes    map[string]string `json:"attributes" binding:"required,min=1"`
+	PriceOverride *float64          `json:"price_override,omitempty" binding:"omitempty,gt=0"`
+	Stock         int               `json:"stock" binding:"min=0"`
+	IsActive      *bool             `json:"is_active,omitempty"` // defaults to true
+}
+
+// UpdateVariantRequest changes a variant's details; stock is changed through the stock
+// endpoint so every change is in the audit trail
+type UpdateVariantRequest struct {
+	SKU           string            `json:"sku" binding:"required,max=64"`
+	Attributes    map[string]string `json:"attributes" binding:"required,min=1"`
+	PriceOverride *float64          `json:"price_override" binding:"omitempty,gt=0"` // null removes the override
+	IsActive      bool              `json:"is_active"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (v *ProductVariant) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+// Price returns the variant's unit price, the product's base price unless overridden
+func (v *ProductVariant) Price(basePrice float64) float64 {
+	if v.PriceOverride != nil {
+		return *v.PriceOverride
+	}
+	return basePrice
+}
+
+// ActiveVariants returns the variants that can be bought
+func ActiveVariants(variants []ProductVariant) []ProductVariant {
+	var active []ProductVariant
+	for _, variant := range variants {
+		if variant.IsActive {
+			active = append(active, variant)
+		}
+	}
+	return active
+}
+
+// FindVariant returns the variant with the given ID, nil when the product has no such variant
+func FindVariant(variants []ProductVariant, variantID uuid.UUID) *ProductVariant {
+	for i := range variants {
+		if variants[i].ID == variantID {
+			return &variants[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+// Package pagination encodes keyset pagination cursors as opaque signed tokens.
+//
+// A cursor is base64url(JSON payload) + "." + base64url(HMAC-SHA256 of the payload). The
+// payload carries the sort order, the sort key of the last row and its ID, so a cursor
+// only continues the listing it came from and can't be fabricated or edited by clients.
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned for cursors that are malformed, tampered with or belong to another sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidSort is returned for sort orders that aren't supported
+var ErrInvalidSort = errors.New("invalid sort")
+
+// SortOrder describes how a listing is ordered. Rows are ordered by Column then ID, both in
+// the same direction, so (column, id) is a unique keyset.
+type SortOrder struct {
+	Name       string
+	Column     string // empty when ordering by ID only
+	Descending bool
+}
+
+// SortDefault keeps the original ordering by ID
+const SortDefault = "default"
+
+var sortOrders = map[string]SortOrder{
+	SortDefault:  {Name: SortDefault},
+	"newest":     {Name: "newest", Column: "created_at", Descending: true},
+	"oldest":     {Name: "oldest", Column: "created_at"},
+	"price_asc":  {Name: "price_asc", Column: "price"},
+	"price_desc": {Name: "price_desc", Column: "price", Descending: true},
+	"name_asc":   {Name: "name_asc", Column: "name"},
+	"name_desc":  {Name: "name_desc", Column: "name", Descending: true},
+}
+
+// LookupSort returns the sort order for a name, the default order for an empty name
+func LookupSort(name string) (SortOrder, error) {
+	if name == "" {
+		name = SortDefault
+	}
+	order, ok := sortOrders[name]
+	if !ok {
+		return SortOrder{}, ErrInvalidSort
+	}
+	return order, nil
+}
+
+// OrderClause returns the ORDER BY clause of the sort order
+func (s SortOrder) OrderClause() string {
+	direction := "ASC"
+	if s.Descending {
+		direction = "DESC"
+	}
+	if s.Column == "" {
+		return "id " + direction
+	}
+	return s.Column + " " + direction + ", id " + direction
+}
+
+// AfterClause returns the WHERE clause and arguments selecting rows after the cursor
+func (s SortOrder) AfterClause(cursor Cursor) (string, []interface{}, error) {
+	operator := ">"
+	if s.Descending {
+		operator = "<"
+	}
+	if s.Column == "" {
+		return "id " + operator + " ?", []interface{}{cursor.ID}, nil
+	}
+
+	key, err := s.parseKey(cursor.Key)
+	if err != nil {
+		return "", nil, err
+	}
+	return "(" + s.Column + ", id) " + operator + " (?, ?)", []interface{}{key, cursor.ID}, nil
+}
+
+// FormatKey converts a sort key value into its cursor form
+func (s SortOrder) FormatKey(createdAt time.Time, price float64, name string) string {
+	switch s.Column {
+	case "created_at":
+		return createdAt.UTC().Format(time.RFC3339Nano)
+	case "price":
+		return strconv.FormatFloat(price, 'g', -1, 64)
+	case "name":
+		return name
+	}
+	return ""
+}
+
+// parseKey converts a cursor sort key back into a query argument
+func (s SortOrder) parseKey(key string) (interface{}, error) {
+	switch s.Column {
+	case "created_at":
+		value, err := time.Parse(time.RFC3339Nano, key)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return value, nil
+	case "price":
+		value, err := strconv.ParseFloat(key, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return value, nil
+	}
+	return key, nil
+}
+
+// Cursor is the position after which the next page starts
+type Cursor struct {
+	Sort string    `json:"s"`
+	Key  string    `json:"k,omitempty"`
+	ID   uuid.UUID `json:"id"`
+}
+
+// Codec signs and verifies cursors
+type Codec struct {
+	secret []byte
+}
+
+// NewCodec creates a codec signing cursors with secret
+func NewCodec(secret []byte) *Codec {
+	return &Codec{secret: secret}
+}
+
+// NewCodecFromEnv creates a codec from CURSOR_SECRET. Without it a random secret is
+// generated, so cursors stop working after a restart and across instances.
+func NewCodecFromEnv() *Codec {
+	if secret := os.Getenv("CURSOR_SECRET"); secret != "" {
+		return NewCodec([]byte(secret))
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Failed to generate cursor secret: %v", err)
+	}
+	log.Printf("⚠️ CURSOR_SECRET not set, pagination cursors are only valid for this instance until restart")
+	return NewCodec(secret)
+}
+
+// Encode returns the opaque token of a cursor
+func (c *Codec) Encode(cursor Cursor) string {
+	payload, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload))
+}
+
+// Decode verifies a token and returns its cursor. The cursor must have been issued for the
+// given sort order.
+func (c *Codec) Decode(token string, order SortOrder) (Cursor, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, c.sign(payload)) {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if cursor.Sort != order.Name {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// sign returns the HMAC-SHA256 of payload
+func (c *Codec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
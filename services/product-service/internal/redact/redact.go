@@ -0,0 +1,186 @@
+// Package redact hides sensitive header, query and body fields before they are logged.
+// Passwords, tokens, OTPs and keys are replaced entirely; VA numbers, payment codes and phone
+// numbers keep their last four characters and emails their first character and domain, so
+// log lines can still be matched to a payment or user. LOG_REDACT_FIELDS adds field names
+// (comma separated, case-insensitive) to the fully redacted set.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the value of a sensitive field
+const Redacted = "[REDACTED]"
+
+// MaxBodyLength caps how much of a body is logged
+const MaxBodyLength = 2048
+
+var defaultFields = []string{
+	// Headers
+	"authorization", "cookie", "set-cookie", "x-admin-token", "x-api-key", "x-internal-token",
+	// Credentials and one-time codes
+	"password", "current_password", "new_password", "confirm_password",
+	"token", "access_token", "refresh_token", "id_token", "otp", "otp_code", "code", "secret",
+	// Payment secrets
+	"server_key", "client_secret", "signature_key", "card_number", "card_cvv", "cvv", "token_id",
+}
+
+// tailFields keep their last four characters to tell payments apart
+var tailFields = map[string]bool{
+	"va_number":         true,
+	"permata_va_number": true,
+	"bill_key":          true,
+	"payment_code":      true,
+	"masked_card":       true,
+	"phone":             true,
+}
+
+var (
+	fieldsOnce sync.Once
+	fields     map[string]bool
+)
+
+// sensitive reports whether the named field is redacted entirely
+func sensitive(name string) bool {
+	fieldsOnce.Do(func() {
+		fields = make(map[string]bool)
+		for _, field := range defaultFields {
+			fields[field] = true
+		}
+		for _, field := range strings.Split(os.Getenv("LOG_REDACT_FIELDS"), ",") {
+			if field = strings.ToLower(strings.TrimSpace(field)); field != "" {
+				fields[field] = true
+			}
+		}
+	})
+	return fields[strings.ToLower(name)]
+}
+
+// Value redacts value according to the field it belongs to
+func Value(name, value string) string {
+	key := strings.ToLower(name)
+	switch {
+	case value == "":
+		return value
+	case sensitive(key):
+		return Redacted
+	case tailFields[key]:
+		return Tail(value)
+	case key == "email" || strings.HasSuffix(key, "_email"):
+		return Email(value)
+	}
+	return value
+}
+
+// Tail hides all but the last four characters of value
+func Tail(value string) string {
+	if len(value) <= 4 {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", len(value)-4) + value[len(value)-4:]
+}
+
+// Email keeps the first character of the local part and the domain
+func Email(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return Redacted
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// Headers formats headers as sorted name=value pairs with sensitive values redacted
+func Headers(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", name, Value(name, strings.Join(header[name], ","))))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// Query redacts the sensitive parameters of a raw query string
+func Query(rawQuery string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return Redacted
+	}
+	for name, list := range values {
+		for i := range list {
+			list[i] = Value(name, list[i])
+		}
+	}
+	// Encode escapes the brackets of [REDACTED], keep it readable
+	return strings.ReplaceAll(values.Encode(), url.QueryEscape(Redacted), Redacted)
+}
+
+// URL returns path and query of u with sensitive parameters redacted
+func URL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+	return u.Path + "?" + Query(u.RawQuery)
+}
+
+// JSON returns body with sensitive fields redacted at any depth, truncated to MaxBodyLength.
+// Bodies that aren't JSON are summarised by their size, they may hold anything.
+func JSON(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Sprintf("[%d bytes, not JSON]", len(body))
+	}
+
+	redacted, err := json.Marshal(walk("", data))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes]", len(body))
+	}
+	if len(redacted) > MaxBodyLength {
+		return fmt.Sprintf("%s... [%d bytes]", redacted[:MaxBodyLength], len(redacted))
+	}
+	return string(redacted)
+}
+
+// walk redacts the string values of sensitive keys in decoded JSON
+func walk(key string, data interface{}) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			value[k] = walk(k, v)
+		}
+		return value
+	case []interface{}:
+		for i, v := range value {
+			value[i] = walk(key, v)
+		}
+		return value
+	case string:
+		return Value(key, value)
+	case nil:
+		return nil
+	default:
+		// Numbers and booleans under a sensitive key (e.g. a numeric OTP) are hidden too
+		if sensitive(key) {
+			return Redacted
+		}
+		return value
+	}
+}
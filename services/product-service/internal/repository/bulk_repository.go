@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"product-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrProductsNotOwned is returned when a bulk update names products that don't exist
+// or belong to another seller
+var ErrProductsNotOwned = errors.New("products not found or not owned by seller")
+
+// ErrInvalidPrice is returned when a price adjustment would make a price zero or negative
+var ErrInvalidPrice = errors.New("price must be positive")
+
+// BulkUpdateProducts applies req to every listed product of sellerID in one transaction.
+// Nothing is changed unless all products belong to the seller and every new price is valid.
+func (r *ProductRepository) BulkUpdateProducts(ctx context.Context, sellerID uuid.UUID, req *models.BulkProductUpdateRequest) ([]models.Product, error) {
+	ids := uniqueIDs(req.ProductIDs)
+
+	var products []models.Product
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND user_id = ?", ids, sellerID).
+			Order("id").
+			Find(&products).Error; err != nil {
+			return fmt.Errorf("failed to get products: %w", err)
+		}
+		if len(products) != len(ids) {
+			return fmt.Errorf("%w: %d of %d", ErrProductsNotOwned, len(ids)-len(products), len(ids))
+		}
+
+		now := time.Now()
+		for i := range products {
+			product := &products[i]
+			updates := map[string]interface{}{"updated_at": now}
+
+			if req.Price != nil {
+				price, err := adjustPrice(product.Price, req.Price)
+				if err != nil {
+					return fmt.Errorf("product %s: %w", product.ID, err)
+				}
+				updates["price"] = price
+				product.Price = price
+			}
+
+			if req.ClearSchedule {
+				updates["publish_at"] = nil
+				updates["unpublish_at"] = nil
+				product.PublishAt, product.UnpublishAt = nil, nil
+			}
+			if req.PublishAt != nil {
+				updates["publish_at"] = *req.PublishAt
+				product.PublishAt = req.PublishAt
+			}
+			if req.UnpublishAt != nil {
+				updates["unpublish_at"] = *req.UnpublishAt
+				product.UnpublishAt = req.UnpublishAt
+			}
+
+			if req.IsActive != nil {
+				updates["is_active"] = *req.IsActive
+				product.IsActive = *req.IsActive
+			} else if req.PublishAt != nil && req.PublishAt.After(now) {
+				// A scheduled launch stays hidden until the scheduler publishes it
+				updates["is_active"] = false
+				product.IsActive = false
+			}
+
+			if err := tx.Model(&models.Product{}).Where("id = ?", product.ID).Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to update product %s: %w", product.ID, err)
+			}
+			product.UpdatedAt = now
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		r.InvalidateProductCache(ctx, id)
+	}
+	r.InvalidateProductsCache(ctx)
+
+	return products, nil
+}
+
+// ApplyPublishSchedule activates products whose publish_at and deactivates products whose
+// unpublish_at has passed, clearing the applied schedule. It returns the changed products.
+func (r *ProductRepository) ApplyPublishSchedule(ctx context.Context, now time.Time) (published, unpublished []models.Product, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		returning := clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "stock"}}}
+
+		if err := tx.Model(&published).Clauses(returning).
+			Where("publish_at <= ?", now).
+			Updates(map[string]interface{}{"is_active": true, "publish_at": nil, "updated_at": now}).Error; err != nil {
+			return fmt.Errorf("failed to publish scheduled products: %w", err)
+		}
+
+		// Runs second so a product whose whole window has passed ends up inactive
+		if err := tx.Model(&unpublished).Clauses(returning).
+			Where("unpublish_at <= ?", now).
+			Updates(map[string]interface{}{"is_active": false, "unpublish_at": nil, "updated_at": now}).Error; err != nil {
+			return fmt.Errorf("failed to unpublish scheduled products: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(published) > 0 || len(unpublished) > 0 {
+		for _, product := range published {
+			r.InvalidateProductCache(ctx, product.ID)
+		}
+		for _, product := range unpublished {
+			r.InvalidateProductCache(ctx, product.ID)
+		}
+		r.InvalidateProductsCache(ctx)
+	}
+
+	return published, unpublished, nil
+}
+
+// adjustPrice applies a bulk price adjustment, rounding to two decimals
+func adjustPrice(price float64, adjustment *models.PriceAdjustment) (float64, error) {
+	switch adjustment.Type {
+	case models.PriceAdjustSet:
+		price = adjustment.Value
+	case models.PriceAdjustAmount:
+		price += adjustment.Value
+	case models.PriceAdjustPercent:
+		price += price * adjustment.Value / 100
+	default:
+		return 0, fmt.Errorf("unknown price adjustment %q", adjustment.Type)
+	}
+
+	price = math.Round(price*100) / 100
+	if price <= 0 {
+		return 0, ErrInvalidPrice
+	}
+	return price, nil
+}
+
+// uniqueIDs drops duplicate IDs, keeping the first occurrence
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
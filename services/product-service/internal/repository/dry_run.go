@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type dryRunKey struct{}
+
+// errDryRun rolls back the transaction of a dry run
+var errDryRun = errors.New("dry run")
+
+// DryRun marks ctx so writes made with it run their transaction to the end, reporting what
+// they changed, and are then rolled back. Shadow consumers use it to compute the effect of
+// a message with the same queries and locks as the active consumer.
+func DryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx is marked with DryRun
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// transaction runs fn in a transaction, rolled back instead of committed for dry runs
+func (r *ProductRepository) transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if IsDryRun(ctx) {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"product-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// funnelDay truncates t to its day in UTC, the granularity of the funnel table
+func funnelDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// addFunnelCounts adds counts to the product's funnel row of day, creating it if needed
+func addFunnelCounts(tx *gorm.DB, row models.ProductFunnelDay) error {
+	row.Day = funnelDay(row.Day)
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "product_id"}, {Name: "day"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "views"}, Value: gorm.Expr("product_funnel_days.views + EXCLUDED.views")},
+			{Column: clause.Column{Name: "checkouts"}, Value: gorm.Expr("product_funnel_days.checkouts + EXCLUDED.checkouts")},
+			{Column: clause.Column{Name: "payments"}, Value: gorm.Expr("product_funnel_days.payments + EXCLUDED.payments")},
+			{Column: clause.Column{Name: "revenue"}, Value: gorm.Expr("product_funnel_days.revenue + EXCLUDED.revenue")},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("EXCLUDED.updated_at")},
+		},
+	}).Create(&row).Error
+}
+
+// AddProductViews adds buffered product detail views to the funnel of day
+func (r *ProductRepository) AddProductViews(ctx context.Context, day time.Time, views map[uuid.UUID]int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for productID, count := range views {
+			if err := addFunnelCounts(tx, models.ProductFunnelDay{ProductID: productID, Day: day, Views: count}); err != nil {
+				return fmt.Errorf("failed to add views of product %s: %w", productID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// RecordFunnelStep counts a checkout or payment of a product at the given time, once per ref.
+// It reports false when ref was already counted. Dry runs (see DryRun) count nothing.
+func (r *ProductRepository) RecordFunnelStep(ctx context.Context, step, ref string, productID uuid.UUID, at time.Time, revenue int64) (bool, error) {
+	row := models.ProductFunnelDay{ProductID: productID, Day: at}
+	switch step {
+	case models.FunnelStepCheckout:
+		row.Checkouts = 1
+	case models.FunnelStepPayment:
+		row.Payments = 1
+		row.Revenue = revenue
+	default:
+		return false, fmt.Errorf("unknown funnel step %q", step)
+	}
+
+	recorded := false
+	err := r.transaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.ProductFunnelEvent{Step: step, Ref: ref, ProductID: productID})
+		if result.Error != nil {
+			return fmt.Errorf("failed to record %s %s: %w", step, ref, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := addFunnelCounts(tx, row); err != nil {
+			return fmt.Errorf("failed to count %s of product %s: %w", step, productID, err)
+		}
+		recorded = true
+		return nil
+	})
+	return recorded, err
+}
+
+// GetProductFunnel returns a product's funnel for the days from to to, both inclusive. Days
+// without activity are left out of Days.
+func (r *ProductRepository) GetProductFunnel(ctx context.Context, productID uuid.UUID, from, to time.Time) (*models.ProductFunnel, error) {
+	from, to = funnelDay(from), funnelDay(to)
+
+	funnel := &models.ProductFunnel{
+		ProductID: productID,
+		From:      from.Format("2006-01-02"),
+		To:        to.Format("2006-01-02"),
+		Days:      []models.ProductFunnelDay{},
+	}
+
+	err := r.read(ctx, func(db *gorm.DB) error {
+		return db.Where("product_id = ? AND day >= ? AND day <= ?", productID, from, to).
+			Order("day").
+			Find(&funnel.Days).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product funnel: %w", err)
+	}
+
+	funnel.ComputeRates()
+	return funnel, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"product-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrImageNotFound is returned when an image doesn't exist or belongs to another product
+	ErrImageNotFound = errors.New("image not found")
+	// ErrImageOrderMismatch is returned when a new order doesn't list every image of the product once
+	ErrImageOrderMismatch = errors.New("image order must list every image of the product once")
+)
+
+// GetProductImages returns the images of a product in gallery order
+func (r *ProductRepository) GetProductImages(ctx context.Context, productID uuid.UUID) ([]models.ProductImage, error) {
+	var images []models.ProductImage
+	if err := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("position, created_at").Find(&images).Error; err != nil {
+		return nil, fmt.Errorf("failed to get product images: %w", err)
+	}
+	return images, nil
+}
+
+// ReorderProductImages sets the gallery order of a product's images to imageIDs, which must
+// list each of them exactly once
+func (r *ProductRepository) ReorderProductImages(ctx context.Context, productID uuid.UUID, imageIDs []uuid.UUID) ([]models.ProductImage, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []uuid.UUID
+		if err := tx.Model(&models.ProductImage{}).Where("product_id = ?", productID).Pluck("id", &existing).Error; err != nil {
+			return fmt.Errorf("failed to get product images: %w", err)
+		}
+		if !sameImageSet(existing, imageIDs) {
+			return ErrImageOrderMismatch
+		}
+
+		for position, imageID := range imageIDs {
+			if err := tx.Model(&models.ProductImage{}).Where("id = ?", imageID).Update("position", position).Error; err != nil {
+				return fmt.Errorf("failed to reorder product images: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidateProductImages(ctx, productID)
+	return r.GetProductImages(ctx, productID)
+}
+
+// SetPrimaryProductImage makes an image the product's primary image, unmarking the previous one
+func (r *ProductRepository) SetPrimaryProductImage(ctx context.Context, productID, imageID uuid.UUID) ([]models.ProductImage, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.ProductImage{}).Where("id = ? AND product_id = ?", imageID, productID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to get product image: %w", err)
+		}
+		if count == 0 {
+			return ErrImageNotFound
+		}
+
+		// Unmark first, the unique index allows one primary image per product
+		if err := tx.Model(&models.ProductImage{}).Where("product_id = ? AND is_primary AND id <> ?", productID, imageID).Update("is_primary", false).Error; err != nil {
+			return fmt.Errorf("failed to unset primary image: %w", err)
+		}
+		if err := tx.Model(&models.ProductImage{}).Where("id = ?", imageID).Update("is_primary", true).Error; err != nil {
+			return fmt.Errorf("failed to set primary image: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidateProductImages(ctx, productID)
+	return r.GetProductImages(ctx, productID)
+}
+
+// invalidateProductImages evicts the cached product and the product lists embedding its images
+func (r *ProductRepository) invalidateProductImages(ctx context.Context, productID uuid.UUID) {
+	if err := r.InvalidateProductCache(ctx, productID); err != nil {
+		fmt.Printf("Failed to invalidate cache of product %s after an image change: %v\n", productID, err)
+	}
+	if err := r.InvalidateProductsCache(ctx); err != nil {
+		fmt.Printf("Failed to invalidate product lists after an image change of %s: %v\n", productID, err)
+	}
+}
+
+// sameImageSet reports whether ordered lists every existing image exactly once
+func sameImageSet(existing, ordered []uuid.UUID) bool {
+	if len(existing) != len(ordered) {
+		return false
+	}
+	remaining := make(map[uuid.UUID]bool, len(existing))
+	for _, id := range existing {
+		remaining[id] = true
+	}
+	for _, id := range ordered {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+	return true
+}
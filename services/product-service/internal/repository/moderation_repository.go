@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"product-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ListProductsForModeration retrieves products in a moderation status with pagination,
+// oldest first so the review queue is worked in order
+func (r *ProductRepository) ListProductsForModeration(ctx context.Context, status models.ModerationStatus, page, limit int) (*models.ProductListResponse, error) {
+	dbQuery := r.db.WithContext(ctx).Model(&models.Product{}).Where("moderation_status = ?", status)
+
+	var total int64
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count products: %w", err)
+	}
+
+	var products []models.Product
+	offset := (page - 1) * limit
+	if err := dbQuery.Preload("User").Preload("Store").Preload("Images").Preload("Variants").
+		Order("updated_at ASC").Offset(offset).Limit(limit).Find(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+
+	productResponses := make([]models.ProductResponse, len(products))
+	for i, product := range products {
+		productResponses[i] = product.ToResponse()
+	}
+
+	return &models.ProductListResponse{
+		Products: productResponses,
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+		HasMore:  int64(offset+len(products)) < total,
+	}, nil
+}
+
+// SetModerationStatus records an admin's decision on a product and drops its cached copies,
+// returning the updated product
+func (r *ProductRepository) SetModerationStatus(ctx context.Context, id uuid.UUID, status models.ModerationStatus, reason *string) (*models.Product, error) {
+	var product models.Product
+	if err := r.db.WithContext(ctx).First(&product, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("product not found")
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	now := time.Now()
+	err := r.db.WithContext(ctx).Model(&product).Updates(map[string]interface{}{
+		"moderation_status": status,
+		"moderation_reason": reason,
+		"moderated_at":      now,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update moderation status: %w", err)
+	}
+	product.ModerationStatus = status
+	product.ModerationReason = reason
+	product.ModeratedAt = &now
+
+	r.InvalidateProductCache(ctx, id)
+	r.InvalidateProductsCache(ctx)
+	return &product, nil
+}
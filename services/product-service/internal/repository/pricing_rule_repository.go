@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"product-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrPricingRuleNotFound is returned when a pricing rule doesn't exist
+var ErrPricingRuleNotFound = errors.New("pricing rule not found")
+
+// activePricingRulesKey caches the rules that are active now or scheduled, shared by all
+// instances and dropped on every rule change
+const activePricingRulesKey = "pricing_rules:active"
+
+// CreatePricingRule creates a pricing rule
+func (r *ProductRepository) CreatePricingRule(ctx context.Context, rule *models.PricingRule) error {
+	if err := r.db.WithContext(ctx).Create(rule).Error; err != nil {
+		return fmt.Errorf("failed to create pricing rule: %w", err)
+	}
+	r.invalidatePricingRules(ctx)
+	return nil
+}
+
+// GetPricingRule retrieves a pricing rule by ID
+func (r *ProductRepository) GetPricingRule(ctx context.Context, id uuid.UUID) (*models.PricingRule, error) {
+	var rule models.PricingRule
+	if err := r.db.WithContext(ctx).First(&rule, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrPricingRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to get pricing rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// ListPricingRules retrieves pricing rules with pagination, newest first, optionally only
+// the ones targeting a product
+func (r *ProductRepository) ListPricingRules(ctx context.Context, productID *uuid.UUID, page, limit int) (*models.PricingRuleListResponse, error) {
+	dbQuery := r.db.WithContext(ctx).Model(&models.PricingRule{})
+	if productID != nil {
+		dbQuery = dbQuery.Where("product_id = ?", *productID)
+	}
+
+	var total int64
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count pricing rules: %w", err)
+	}
+
+	rules := []models.PricingRule{}
+	offset := (page - 1) * limit
+	if err := dbQuery.Order("created_at DESC").Offset(offset).Limit(limit).Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pricing rules: %w", err)
+	}
+
+	return &models.PricingRuleListResponse{
+		Rules:   rules,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		HasMore: int64(offset+len(rules)) < total,
+	}, nil
+}
+
+// UpdatePricingRule saves a pricing rule
+func (r *ProductRepository) UpdatePricingRule(ctx context.Context, rule *models.PricingRule) error {
+	if err := r.db.WithContext(ctx).Save(rule).Error; err != nil {
+		return fmt.Errorf("failed to update pricing rule: %w", err)
+	}
+	r.invalidatePricingRules(ctx)
+	return nil
+}
+
+// DeletePricingRule deletes a pricing rule
+func (r *ProductRepository) DeletePricingRule(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&models.PricingRule{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete pricing rule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPricingRuleNotFound
+	}
+	r.invalidatePricingRules(ctx)
+	return nil
+}
+
+// ActivePricingRules returns the enabled rules that haven't ended, oldest first. Rules that
+// start later are included so the cached set stays valid until it expires; callers check
+// the time window when evaluating.
+func (r *ProductRepository) ActivePricingRules(ctx context.Context) ([]models.PricingRule, error) {
+	var cached []models.PricingRule
+	if err := r.cache.Get(ctx, activePricingRulesKey, &cached); err == nil {
+		return cached, nil
+	}
+
+	rules := []models.PricingRule{}
+	err := r.db.WithContext(ctx).
+		Where("is_active = ? AND (ends_at IS NULL OR ends_at > ?)", true, time.Now()).
+		Order("created_at ASC").
+		Find(&rules).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active pricing rules: %w", err)
+	}
+
+	// Cache the rules for CACHE_TTL_PRICING_RULES
+	if err := r.cache.Set(ctx, activePricingRulesKey, rules, r.ttls.PricingRules); err != nil {
+		fmt.Printf("Failed to cache pricing rules: %v\n", err)
+	}
+	return rules, nil
+}
+
+// invalidatePricingRules drops the cached active rules after a change
+func (r *ProductRepository) invalidatePricingRules(ctx context.Context) {
+	if err := r.cache.Delete(ctx, activePricingRulesKey); err != nil {
+		fmt.Printf("Failed to invalidate pricing rules cache: %v\n", err)
+	}
+}
@@ -3,28 +3,55 @@ package repository
 import (
 	"context"
 	"fmt"
-	"strconv"
-	"time"
+	"strings"
 
 	"product-service/internal/cache"
 	"product-service/internal/models"
+	"product-service/internal/pagination"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type ProductRepository struct {
-	db    *gorm.DB
-	cache *cache.RedisClient
+	db        *gorm.DB
+	replica   *gorm.DB // serves product listings and details, nil reads everything from db
+	cache     *cache.TieredCache
+	ttls      cache.TTLs // CACHE_TTL_*
+	cursors   *pagination.Codec
+	moderator ProductModerator // nil leaves new products pending review
 }
 
-func NewProductRepository(db *gorm.DB, cache *cache.RedisClient) *ProductRepository {
+// ProductModerator sets the moderation status of a product whose content is new or changed
+type ProductModerator interface {
+	Moderate(ctx context.Context, product *models.Product)
+}
+
+func NewProductRepository(db *gorm.DB, productCache *cache.TieredCache) *ProductRepository {
 	return &ProductRepository{
-		db:    db,
-		cache: cache,
+		db:      db,
+		cache:   productCache,
+		ttls:    cache.TTLsFromEnv(),
+		cursors: pagination.NewCodecFromEnv(),
 	}
 }
 
+// SetModerator runs moderator on products created or whose name or description changes
+func (r *ProductRepository) SetModerator(moderator ProductModerator) {
+	r.moderator = moderator
+}
+
+// SetReplica serves product listings and details from a read replica, falling back to the
+// primary when it fails. Everything else, including reads before a write, stays on the primary.
+func (r *ProductRepository) SetReplica(replica *gorm.DB) {
+	r.replica = replica
+}
+
+// read runs a read only query on the replica, see SetReplica
+func (r *ProductRepository) read(ctx context.Context, query func(db *gorm.DB) error) error {
+	return readWithFallback(ctx, r.db, r.replica, query)
+}
+
 // GetDB returns the database instance for direct access
 func (r *ProductRepository) GetDB() *gorm.DB {
 	return r.db
@@ -32,17 +59,21 @@ func (r *ProductRepository) GetDB() *gorm.DB {
 
 // GetProducts retrieves products with pagination and caching
 func (r *ProductRepository) GetProducts(ctx context.Context, query models.ProductQuery) (*models.ProductListResponse, error) {
-	// Create cache key
-	cacheKey := r.generateCacheKey("products", query)
-	
-	// Try to get from cache first
-	var cachedResponse models.ProductListResponse
-	if exists, _ := r.cache.Exists(ctx, cacheKey); exists {
-		if err := r.cache.Get(ctx, cacheKey, &cachedResponse); err == nil {
-			return &cachedResponse, nil
+	sortOrder, err := pagination.LookupSort(query.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the cursor before anything is served for it, cached or not
+	var cursor *pagination.Cursor
+	if query.Cursor != "" {
+		decoded, err := r.cursors.Decode(query.Cursor, sortOrder)
+		if err != nil {
+			return nil, err
 		}
+		cursor = &decoded
 	}
-	
+
 	// Set default values
 	if query.Page <= 0 {
 		query.Page = 1
@@ -54,60 +85,94 @@ func (r *ProductRepository) GetProducts(ctx context.Context, query models.Produc
 		query.Limit = 100
 	}
 	
-	// Build query
-	dbQuery := r.db.WithContext(ctx).Model(&models.Product{}).Preload("User").Preload("Images")
-	
-	// Apply filters
-	if query.Search != "" {
-		dbQuery = dbQuery.Where("name ILIKE ? OR description ILIKE ?", "%"+query.Search+"%", "%"+query.Search+"%")
-	}
+	// Create cache key from the normalized query, every filter and skip_total included
+	cacheKey := pagination.CacheKey("products", query)
 	
-	if query.MinPrice != nil {
-		dbQuery = dbQuery.Where("price >= ?", *query.MinPrice)
-	}
-	
-	if query.MaxPrice != nil {
-		dbQuery = dbQuery.Where("price <= ?", *query.MaxPrice)
+	// Try to get from cache first
+	var cachedResponse models.ProductListResponse
+	if err := r.cache.Get(ctx, cacheKey, &cachedResponse); err == nil {
+		return &cachedResponse, nil
 	}
 	
-	if query.IsActive != nil {
-		dbQuery = dbQuery.Where("is_active = ?", *query.IsActive)
+	// Keyset pagination: rows after the cursor's (sort key, id)
+	var afterClause string
+	var afterArgs []interface{}
+	if cursor != nil {
+		afterClause, afterArgs, err = sortOrder.AfterClause(*cursor)
+		if err != nil {
+			return nil, err
+		}
 	}
 	
-	// Get total count
 	var total int64
-	if err := dbQuery.Count(&total).Error; err != nil {
-		return nil, fmt.Errorf("failed to count products: %w", err)
-	}
-	
-	// Apply pagination using keyset pagination for better performance
 	var products []models.Product
 	var hasMore bool
 	var nextCursor string
 	
-	if query.Cursor != "" {
-		// Keyset pagination: WHERE id > cursor
-		cursorID, err := uuid.Parse(query.Cursor)
-		if err != nil {
-			return nil, fmt.Errorf("invalid cursor: %w", err)
+	err = r.read(ctx, func(db *gorm.DB) error {
+		// Build query, listings only show approved products
+		dbQuery := db.Model(&models.Product{}).Preload("User").Preload("Store").Preload("Images").Preload("Variants").
+			Where("moderation_status = ?", models.ModerationApproved)
+		
+		// Apply filters
+		if query.Search != "" {
+			dbQuery = dbQuery.Where("name ILIKE ? OR description ILIKE ?", "%"+query.Search+"%", "%"+query.Search+"%")
 		}
-		dbQuery = dbQuery.Where("id > ?", cursorID)
-	}
-	
-	// Order by ID for consistent pagination
-	dbQuery = dbQuery.Order("id ASC")
-	
-	// Get one extra record to check if there are more
-	limit := query.Limit + 1
-	if err := dbQuery.Limit(limit).Find(&products).Error; err != nil {
-		return nil, fmt.Errorf("failed to get products: %w", err)
+		
+		if query.MinPrice != nil {
+			dbQuery = dbQuery.Where("price >= ?", *query.MinPrice)
+		}
+		
+		if query.MaxPrice != nil {
+			dbQuery = dbQuery.Where("price <= ?", *query.MaxPrice)
+		}
+		
+		if query.IsActive != nil {
+			dbQuery = dbQuery.Where("is_active = ?", *query.IsActive)
+		}
+		
+		if query.StoreID != "" {
+			dbQuery = dbQuery.Where("store_id = ?", query.StoreID)
+		}
+
+		if query.IDs != "" {
+			dbQuery = dbQuery.Where("id IN ?", strings.Split(query.IDs, ","))
+		}
+		
+		// Get total count, unless the client doesn't need it
+		if query.SkipTotal {
+			total = -1
+		} else if err := dbQuery.Count(&total).Error; err != nil {
+			return fmt.Errorf("failed to count products: %w", err)
+		}
+		
+		if cursor != nil {
+			dbQuery = dbQuery.Where(afterClause, afterArgs...)
+		}
+		
+		// Order by the sort key, then ID for consistent pagination
+		dbQuery = dbQuery.Order(sortOrder.OrderClause())
+		
+		// Get one extra record to check if there are more
+		if err := dbQuery.Limit(query.Limit + 1).Find(&products).Error; err != nil {
+			return fmt.Errorf("failed to get products: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	
 	// Check if there are more records
 	if len(products) > query.Limit {
 		hasMore = true
 		products = products[:query.Limit] // Remove the extra record
-		nextCursor = products[len(products)-1].ID.String()
+		last := products[len(products)-1]
+		nextCursor = r.cursors.Encode(pagination.Cursor{
+			Sort: sortOrder.Name,
+			Key:  sortOrder.FormatKey(last.CreatedAt, last.Price, last.Name),
+			ID:   last.ID,
+		})
 	}
 	
 	// Convert to response format
@@ -125,8 +190,8 @@ func (r *ProductRepository) GetProducts(ctx context.Context, query models.Produc
 		NextCursor: nextCursor,
 	}
 	
-	// Cache the response for 5 minutes
-	if err := r.cache.Set(ctx, cacheKey, response, 5*time.Minute); err != nil {
+	// Cache the response for CACHE_TTL_PRODUCT_LIST
+	if err := r.cache.Set(ctx, cacheKey, response, r.ttls.ProductList); err != nil {
 		// Log error but don't fail the request
 		fmt.Printf("Failed to cache products: %v\n", err)
 	}
@@ -134,43 +199,41 @@ func (r *ProductRepository) GetProducts(ctx context.Context, query models.Produc
 	return response, nil
 }
 
-// GetProductByID retrieves a single product by ID with caching
+// GetProductByID retrieves a single approved product by ID with caching. It is served from
+// the local cache, then Redis, and concurrent misses of the same product share one query.
 func (r *ProductRepository) GetProductByID(ctx context.Context, id uuid.UUID) (*models.ProductResponse, error) {
-	// Create cache key
 	cacheKey := fmt.Sprintf("product:%s", id.String())
-	
-	// Try to get from cache first
-	var cachedProduct models.ProductResponse
-	if exists, _ := r.cache.Exists(ctx, cacheKey); exists {
-		if err := r.cache.Get(ctx, cacheKey, &cachedProduct); err == nil {
-			return &cachedProduct, nil
-		}
-	}
-	
-	// Get from database
-	var product models.Product
-	if err := r.db.WithContext(ctx).Preload("User").Preload("Images").First(&product, "id = ?", id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("product not found")
+
+	// Cached for CACHE_TTL_PRODUCT in Redis, CACHE_L1_TTL locally
+	var response models.ProductResponse
+	err := r.cache.Load(ctx, cacheKey, &response, r.ttls.Product, func() (interface{}, error) {
+		var product models.Product
+		err := r.read(ctx, func(db *gorm.DB) error {
+			return db.Preload("User").Preload("Store").Preload("Images").Preload("Variants").First(&product, "id = ? AND moderation_status = ?", id, models.ModerationApproved).Error
+		})
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, fmt.Errorf("product not found")
+			}
+			return nil, fmt.Errorf("failed to get product: %w", err)
 		}
-		return nil, fmt.Errorf("failed to get product: %w", err)
-	}
-	
-	response := product.ToResponse()
-	
-	// Cache the response for 10 minutes
-	if err := r.cache.Set(ctx, cacheKey, response, 10*time.Minute); err != nil {
-		// Log error but don't fail the request
-		fmt.Printf("Failed to cache product: %v\n", err)
+		return product.ToResponse(), nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	
+
 	return &response, nil
 }
 
-// InvalidateProductCache invalidates cache for a specific product
+// InvalidateProductCache invalidates cache for a specific product and its availability
 func (r *ProductRepository) InvalidateProductCache(ctx context.Context, productID uuid.UUID) error {
 	cacheKey := fmt.Sprintf("product:%s", productID.String())
-	return r.cache.Delete(ctx, cacheKey)
+	err := r.cache.Delete(ctx, cacheKey)
+	if availErr := r.cache.Delete(ctx, fmt.Sprintf("availability:%s", productID.String())); err == nil {
+		err = availErr
+	}
+	return err
 }
 
 // InvalidateProductsCache invalidates the products list cache
@@ -178,43 +241,19 @@ func (r *ProductRepository) InvalidateProductsCache(ctx context.Context) error {
 	return r.cache.DeletePattern(ctx, "products:*")
 }
 
-// generateCacheKey generates a cache key for products list
-func (r *ProductRepository) generateCacheKey(prefix string, query models.ProductQuery) string {
-	key := prefix
-	
-	if query.Page > 0 {
-		key += fmt.Sprintf(":page:%d", query.Page)
-	}
-	
-	if query.Limit > 0 {
-		key += fmt.Sprintf(":limit:%d", query.Limit)
-	}
-	
-	if query.Cursor != "" {
-		key += fmt.Sprintf(":cursor:%s", query.Cursor)
-	}
-	
-	if query.Search != "" {
-		key += fmt.Sprintf(":search:%s", query.Search)
-	}
-	
-	if query.MinPrice != nil {
-		key += fmt.Sprintf(":min_price:%s", strconv.FormatFloat(*query.MinPrice, 'f', 2, 64))
+// CreateProduct creates a new product (for future use). It is held for review unless the
+// moderator decides otherwise.
+func (r *ProductRepository) CreateProduct(ctx context.Context, product *models.Product) error {
+	if product.ID == uuid.Nil {
+		product.ID = uuid.New() // identifies it in moderation logs
 	}
-	
-	if query.MaxPrice != nil {
-		key += fmt.Sprintf(":max_price:%s", strconv.FormatFloat(*query.MaxPrice, 'f', 2, 64))
+	if product.ModerationStatus == "" {
+		product.ModerationStatus = models.ModerationPendingReview
 	}
-	
-	if query.IsActive != nil {
-		key += fmt.Sprintf(":is_active:%t", *query.IsActive)
+	if r.moderator != nil {
+		r.moderator.Moderate(ctx, product)
 	}
-	
-	return key
-}
 
-// CreateProduct creates a new product (for future use)
-func (r *ProductRepository) CreateProduct(ctx context.Context, product *models.Product) error {
 	if err := r.db.WithContext(ctx).Create(product).Error; err != nil {
 		return fmt.Errorf("failed to create product: %w", err)
 	}
@@ -225,8 +264,18 @@ func (r *ProductRepository) CreateProduct(ctx context.Context, product *models.P
 	return nil
 }
 
-// UpdateProduct updates an existing product (for future use)
+// UpdateProduct updates an existing product (for future use). A changed name or description
+// is moderated again.
 func (r *ProductRepository) UpdateProduct(ctx context.Context, product *models.Product) error {
+	var current models.Product
+	if err := r.db.WithContext(ctx).Select("name", "description").First(&current, "id = ?", product.ID).Error; err != nil {
+		return fmt.Errorf("failed to get product: %w", err)
+	}
+	if r.moderator != nil && (current.Name != product.Name || current.Description != product.Description) {
+		product.ModerationStatus = models.ModerationPendingReview
+		r.moderator.Moderate(ctx, product)
+	}
+
 	if err := r.db.WithContext(ctx).Save(product).Error; err != nil {
 		return fmt.Errorf("failed to update product: %w", err)
 	}
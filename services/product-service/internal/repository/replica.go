@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// readWithFallback runs the read only query on replica, and again on primary when the replica
+// fails (down, lagging schema, connection refused...). Not found and cancelled requests are
+// returned as is. A nil replica reads from primary.
+func readWithFallback(ctx context.Context, primary, replica *gorm.DB, query func(db *gorm.DB) error) error {
+	if replica == nil {
+		return query(primary.WithContext(ctx))
+	}
+
+	err := query(replica.WithContext(ctx))
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) || ctx.Err() != nil {
+		return err
+	}
+
+	log.Printf("⚠️ Read replica query failed, retrying on primary: %v", err)
+	return query(primary.WithContext(ctx))
+}
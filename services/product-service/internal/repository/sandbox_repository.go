@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"product-service/internal/models"
+	"product-service/internal/seed"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// catalogTables are emptied by the sandbox reset. Global pricing rules are configuration and
+// the user read model belongs to User-Service, both are kept.
+var catalogTables = []string{
+	"product_funnel_events",
+	"product_funnel_days",
+	"stock_movements",
+	"product_images",
+	"product_variants",
+	"products",
+	"stores",
+}
+
+// catalogCachePatterns match every cached product, product list, availability and the
+// active pricing rules
+var catalogCachePatterns = []string{"product:*", "products:*", "availability:*", activePricingRulesKey}
+
+// ResetCatalog empties the catalog, drops its cache entries and seeds the sample catalog
+// again. For demo environments only: every store and product is lost.
+func (r *ProductRepository) ResetCatalog(ctx context.Context) (seed.Result, error) {
+	db := r.db.WithContext(ctx)
+	if err := db.Exec("TRUNCATE TABLE " + strings.Join(catalogTables, ", ")).Error; err != nil {
+		return seed.Result{}, fmt.Errorf("failed to truncate the catalog: %w", err)
+	}
+	// Rules scoped to a product or store would point at nothing
+	if err := db.Where("product_id IS NOT NULL OR store_id IS NOT NULL").Delete(&models.PricingRule{}).Error; err != nil {
+		return seed.Result{}, fmt.Errorf("failed to remove scoped pricing rules: %w", err)
+	}
+
+	if err := r.clearCatalogCache(ctx); err != nil {
+		return seed.Result{}, err
+	}
+	return seed.Run(db)
+}
+
+// UpsertFixture creates store and product with their fixed IDs, or restores them to the
+// given state, replacing the product's images
+func (r *ProductRepository) UpsertFixture(ctx context.Context, store *models.Store, product *models.Product) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(store).Error; err != nil {
+			return fmt.Errorf("failed to save fixture store: %w", err)
+		}
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductImage{}).Error; err != nil {
+			return fmt.Errorf("failed to remove fixture product images: %w", err)
+		}
+		if err := tx.Omit(clause.Associations).Save(product).Error; err != nil {
+			return fmt.Errorf("failed to save fixture product: %w", err)
+		}
+		if len(product.Images) == 0 {
+			return nil
+		}
+		for i := range product.Images {
+			product.Images[i].ProductID = product.ID
+		}
+		if err := tx.Create(&product.Images).Error; err != nil {
+			return fmt.Errorf("failed to create fixture product images: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.invalidateProductImages(ctx, product.ID)
+	return nil
+}
+
+// clearCatalogCache removes the catalog's entries from Redis and every replica's local cache
+func (r *ProductRepository) clearCatalogCache(ctx context.Context) error {
+	for _, pattern := range catalogCachePatterns {
+		if err := r.cache.DeletePattern(ctx, pattern); err != nil {
+			return fmt.Errorf("failed to clear cache %s: %w", pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"product-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientStock is returned when an adjustment would make stock negative
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// ErrSaleAlreadyRecorded is returned when the sale of an order was already applied to a
+// product's stock
+var ErrSaleAlreadyRecorded = errors.New("sale already recorded for order")
+
+// AdjustStock applies a stock delta and records the movement in the same transaction.
+// The product row is locked so concurrent adjustments see each other's results. A sale is
+// applied once per order, repeating it returns ErrSaleAlreadyRecorded. With a VariantID the
+// variant's stock changes instead of the product's. Dry runs (see DryRun) fill in the
+// movement without applying it.
+func (r *ProductRepository) AdjustStock(ctx context.Context, productID uuid.UUID, movement *models.StockMovement) error {
+	err := r.transaction(ctx, func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, "id = ?", productID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("product not found")
+			}
+			return fmt.Errorf("failed to get product: %w", err)
+		}
+
+		// The product lock serializes this check with any other sale of the product
+		if movement.Reason == models.StockReasonSale && movement.OrderID != nil {
+			var sold int64
+			if err := tx.Model(&models.StockMovement{}).
+				Where("product_id = ? AND order_id = ? AND reason = ?", productID, *movement.OrderID, models.StockReasonSale).
+				Count(&sold).Error; err != nil {
+				return fmt.Errorf("failed to check order sale: %w", err)
+			}
+			if sold > 0 {
+				return ErrSaleAlreadyRecorded
+			}
+		}
+
+		stockBefore, err := r.applyStockDelta(tx, &product, movement.VariantID, movement.Delta)
+		if err != nil {
+			return err
+		}
+
+		movement.ProductID = productID
+		movement.StockBefore = stockBefore
+		movement.StockAfter = stockBefore + movement.Delta
+		if err := tx.Create(movement).Error; err != nil {
+			return fmt.Errorf("failed to record stock movement: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil || IsDryRun(ctx) {
+		return err
+	}
+
+	// Invalidate caches
+	r.InvalidateProductCache(ctx, productID)
+	r.InvalidateProductsCache(ctx)
+
+	return nil
+}
+
+// RestoreOrderStock gives back the stock an order still holds on a product, e.g. after its
+// payment failed or expired. What the order holds is the negative net delta of its movements
+// (reservations and sales), so once restored the net is zero and redeliveries change nothing.
+// It returns nil when there is nothing to restore. Dry runs (see DryRun) return the movement
+// without applying it.
+func (r *ProductRepository) RestoreOrderStock(ctx context.Context, productID uuid.UUID, orderID, note string) (*models.StockMovement, error) {
+	var movement *models.StockMovement
+	err := r.transaction(ctx, func(tx *gorm.DB) error {
+		// Lock the product first, it serializes every adjustment of its stock
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, "id = ?", productID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("product not found")
+			}
+			return fmt.Errorf("failed to get product: %w", err)
+		}
+
+		// An order buys one variant, or the product itself when it has none
+		var holding struct {
+			VariantID *uuid.UUID
+			Held      int
+		}
+		if err := tx.Model(&models.StockMovement{}).
+			Select("variant_id, COALESCE(-SUM(delta), 0) AS held").
+			Where("product_id = ? AND order_id = ?", productID, orderID).
+			Group("variant_id").
+			Having("-SUM(delta) > 0").
+			Limit(1).
+			Scan(&holding).Error; err != nil {
+			return fmt.Errorf("failed to sum order stock movements: %w", err)
+		}
+		held := holding.Held
+		if held <= 0 {
+			return nil
+		}
+
+		stockBefore, err := r.applyStockDelta(tx, &product, holding.VariantID, held)
+		if err != nil {
+			return err
+		}
+
+		movement = &models.StockMovement{
+			ProductID:   productID,
+			VariantID:   holding.VariantID,
+			Delta:       held,
+			StockBefore: stockBefore,
+			StockAfter:  stockBefore + held,
+			Reason:      models.StockReasonReservationRelease,
+			ActorType:   models.StockActorSystem,
+			OrderID:     &orderID,
+		}
+		if note != "" {
+			movement.Note = &note
+		}
+		if err := tx.Create(movement).Error; err != nil {
+			return fmt.Errorf("failed to record stock movement: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if movement != nil && !IsDryRun(ctx) {
+		r.InvalidateProductCache(ctx, productID)
+		r.InvalidateProductsCache(ctx)
+	}
+
+	return movement, nil
+}
+
+// applyStockDelta changes the stock of the locked product, or of its variant when variantID is
+// set, and returns the stock before the change. Stock never goes negative.
+func (r *ProductRepository) applyStockDelta(tx *gorm.DB, product *models.Product, variantID *uuid.UUID, delta int) (int, error) {
+	if variantID == nil {
+		newStock := product.Stock + delta
+		if newStock < 0 {
+			return 0, fmt.Errorf("%w: available %d, requested %d", ErrInsufficientStock, product.Stock, -delta)
+		}
+		if err := tx.Model(product).Update("stock", newStock).Error; err != nil {
+			return 0, fmt.Errorf("failed to update stock: %w", err)
+		}
+		return product.Stock, nil
+	}
+
+	var variant models.ProductVariant
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&variant, "id = ? AND product_id = ?", *variantID, product.ID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, ErrVariantNotFound
+		}
+		return 0, fmt.Errorf("failed to get variant: %w", err)
+	}
+
+	newStock := variant.Stock + delta
+	if newStock < 0 {
+		return 0, fmt.Errorf("%w: available %d, requested %d", ErrInsufficientStock, variant.Stock, -delta)
+	}
+	if err := tx.Model(&variant).Update("stock", newStock).Error; err != nil {
+		return 0, fmt.Errorf("failed to update variant stock: %w", err)
+	}
+	return variant.Stock, nil
+}
+
+// GetProductAvailability returns a product's stock and active flag, products that aren't
+// approved by moderation count as inactive. It only reads a few columns and is cached
+// briefly, so checkouts can call it before every charge. For products with variants the
+// stock is checked per variant, see GetVariantAvailability.
+func (r *ProductRepository) GetProductAvailability(ctx context.Context, productID uuid.UUID) (*models.ProductAvailability, error) {
+	cacheKey := fmt.Sprintf("availability:%s", productID.String())
+
+	var cached models.ProductAvailability
+	if err := r.cache.Get(ctx, cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	var availability models.ProductAvailability
+	err := r.db.WithContext(ctx).Model(&models.Product{}).
+		Select("id AS product_id, stock, is_active AND moderation_status = ? AS is_active, "+
+			"EXISTS (SELECT 1 FROM product_variants WHERE product_id = products.id AND is_active) AS has_variants", models.ModerationApproved).
+		Where("id = ?", productID).
+		Take(&availability).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("product not found")
+		}
+		return nil, fmt.Errorf("failed to get product availability: %w", err)
+	}
+
+	// Cache for CACHE_TTL_AVAILABILITY, stock changes also invalidate it
+	if err := r.cache.Set(ctx, cacheKey, availability, r.ttls.Availability); err != nil {
+		fmt.Printf("Failed to cache product availability: %v\n", err)
+	}
+
+	return &availability, nil
+}
+
+// GetVariantAvailability returns the stock of a product's variant; it is active when both the
+// variant and the product are. Variant stock is read by primary key and not cached.
+func (r *ProductRepository) GetVariantAvailability(ctx context.Context, productID, variantID uuid.UUID) (*models.ProductAvailability, error) {
+	var availability models.ProductAvailability
+	err := r.db.WithContext(ctx).Table("product_variants").
+		Select("products.id AS product_id, product_variants.stock, "+
+			"products.is_active AND products.moderation_status = ? AND product_variants.is_active AS is_active, "+
+			"TRUE AS has_variants", models.ModerationApproved).
+		Joins("JOIN products ON products.id = product_variants.product_id").
+		Where("product_variants.id = ? AND product_variants.product_id = ?", variantID, productID).
+		Take(&availability).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrVariantNotFound
+		}
+		return nil, fmt.Errorf("failed to get variant availability: %w", err)
+	}
+	return &availability, nil
+}
+
+// GetStockMovements retrieves a product's stock movements with pagination, newest first
+func (r *ProductRepository) GetStockMovements(ctx context.Context, productID uuid.UUID, page, limit int) (*models.StockMovementListResponse, error) {
+	var movements []models.StockMovement
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.StockMovement{}).Where("product_id = ?", productID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count stock movements: %w", err)
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&movements).Error; err != nil {
+		return nil, fmt.Errorf("failed to get stock movements: %w", err)
+	}
+
+	return &models.StockMovementListResponse{
+		Movements: movements,
+		Total:     total,
+		Page:      page,
+		Limit:     limit,
+		HasMore:   int64(page*limit) < total,
+	}, nil
+}
+
+// GetProductOwner returns the seller (user) ID owning a product
+func (r *ProductRepository) GetProductOwner(ctx context.Context, productID uuid.UUID) (uuid.UUID, error) {
+	var product models.Product
+	if err := r.db.WithContext(ctx).Select("id", "user_id").First(&product, "id = ?", productID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return uuid.Nil, fmt.Errorf("product not found")
+		}
+		return uuid.Nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	return product.UserID, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"product-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrStoreNotFound is returned when a store doesn't exist
+	ErrStoreNotFound = errors.New("store not found")
+	// ErrStoreSlugTaken is returned when another store already uses the slug
+	ErrStoreSlugTaken = errors.New("store slug already taken")
+	// ErrStoreHasProducts is returned when deleting a store that still has products
+	ErrStoreHasProducts = errors.New("store still has products")
+)
+
+// CreateStore creates a store, slugs are unique across sellers
+func (r *ProductRepository) CreateStore(ctx context.Context, store *models.Store) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Store{}).Where("slug = ?", store.Slug).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check store slug: %w", err)
+		}
+		if count > 0 {
+			return ErrStoreSlugTaken
+		}
+
+		if err := tx.Create(store).Error; err != nil {
+			return fmt.Errorf("failed to create store: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetStore retrieves a store by ID
+func (r *ProductRepository) GetStore(ctx context.Context, id uuid.UUID) (*models.Store, error) {
+	var store models.Store
+	if err := r.db.WithContext(ctx).First(&store, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrStoreNotFound
+		}
+		return nil, fmt.Errorf("failed to get store: %w", err)
+	}
+	return &store, nil
+}
+
+// ListStores retrieves stores with pagination, newest first. A non-nil ownerID lists a
+// seller's own stores (active or not), otherwise only active stores are listed.
+func (r *ProductRepository) ListStores(ctx context.Context, ownerID *uuid.UUID, page, limit int) (*models.StoreListResponse, error) {
+	dbQuery := r.db.WithContext(ctx).Model(&models.Store{})
+	if ownerID != nil {
+		dbQuery = dbQuery.Where("owner_id = ?", *ownerID)
+	} else {
+		dbQuery = dbQuery.Where("is_active = ?", true)
+	}
+
+	var total int64
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count stores: %w", err)
+	}
+
+	stores := []models.Store{}
+	offset := (page - 1) * limit
+	if err := dbQuery.Order("created_at DESC").Offset(offset).Limit(limit).Find(&stores).Error; err != nil {
+		return nil, fmt.Errorf("failed to get stores: %w", err)
+	}
+
+	return &models.StoreListResponse{
+		Stores:  stores,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		HasMore: int64(offset+len(stores)) < total,
+	}, nil
+}
+
+// UpdateStore saves a store. Product responses embed their store, so the store's
+// products are evicted from the cache.
+func (r *ProductRepository) UpdateStore(ctx context.Context, store *models.Store) error {
+	if err := r.db.WithContext(ctx).Save(store).Error; err != nil {
+		return fmt.Errorf("failed to update store: %w", err)
+	}
+
+	r.invalidateStoreProducts(ctx, store.ID)
+	return nil
+}
+
+// DeleteStore deletes a store without products
+func (r *ProductRepository) DeleteStore(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Product{}).Where("store_id = ?", id).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count store products: %w", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: %d products", ErrStoreHasProducts, count)
+		}
+
+		result := tx.Delete(&models.Store{}, "id = ?", id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete store: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrStoreNotFound
+		}
+		return nil
+	})
+}
+
+// AssignProductStore moves a product to a store
+func (r *ProductRepository) AssignProductStore(ctx context.Context, productID, storeID uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Model(&models.Product{}).Where("id = ?", productID).Update("store_id", storeID).Error; err != nil {
+		return fmt.Errorf("failed to assign product to store: %w", err)
+	}
+
+	// Invalidate caches
+	r.InvalidateProductCache(ctx, productID)
+	r.InvalidateProductsCache(ctx)
+
+	return nil
+}
+
+// invalidateStoreProducts evicts the cached products of a store and the product lists
+func (r *ProductRepository) invalidateStoreProducts(ctx context.Context, storeID uuid.UUID) {
+	var productIDs []uuid.UUID
+	if err := r.db.WithContext(ctx).Model(&models.Product{}).Where("store_id = ?", storeID).Pluck("id", &productIDs).Error; err != nil {
+		fmt.Printf("Failed to list products of store %s for cache invalidation: %v\n", storeID, err)
+	}
+	for _, productID := range productIDs {
+		r.InvalidateProductCache(ctx, productID)
+	}
+	r.InvalidateProductsCache(ctx)
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"product-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserProfileRepository handles the user read model owned by User-Service
+type UserProfileRepository struct {
+	db *gorm.DB
+}
+
+// NewUserProfileRepository creates a new user profile repository
+func NewUserProfileRepository(db *gorm.DB) *UserProfileRepository {
+	return &UserProfileRepository{db: db}
+}
+
+// Upsert inserts or refreshes a user profile
+func (ur *UserProfileRepository) Upsert(profile *models.UserProfile) error {
+	profile.UpdatedAt = time.Now()
+	err := ur.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"username", "email", "updated_at"}),
+	}).Create(profile).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert user profile: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a user profile by user ID
+func (ur *UserProfileRepository) GetByID(id uuid.UUID) (*models.UserProfile, error) {
+	var profile models.UserProfile
+	if err := ur.db.First(&profile, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("user profile not found")
+		}
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+	return &profile, nil
+}
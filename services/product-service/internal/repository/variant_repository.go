@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"product-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrVariantNotFound is returned when a variant doesn't exist or belongs to another product
+	ErrVariantNotFound = errors.New("variant not found")
+	// ErrVariantSKUTaken is returned when another variant already uses the SKU
+	ErrVariantSKUTaken = errors.New("variant SKU already taken")
+)
+
+// GetProductVariants returns every variant of a product, active or not, oldest first
+func (r *ProductRepository) GetProductVariants(ctx context.Context, productID uuid.UUID) ([]models.ProductVariant, error) {
+	var variants []models.ProductVariant
+	if err := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("created_at").Find(&variants).Error; err != nil {
+		return nil, fmt.Errorf("failed to get product variants: %w", err)
+	}
+	return variants, nil
+}
+
+// CreateVariant adds a variant to a product. Its initial stock is recorded as a restock by
+// actorID, so the variant's stock history starts in the audit trail.
+func (r *ProductRepository) CreateVariant(ctx context.Context, variant *models.ProductVariant, actorID uuid.UUID) error {
+	err := r.transaction(ctx, func(tx *gorm.DB) error {
+		// Lock the product, it serializes every adjustment of its stock
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&product, "id = ?", variant.ProductID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("product not found")
+			}
+			return fmt.Errorf("failed to get product: %w", err)
+		}
+
+		if err := checkVariantSKU(tx, variant.SKU, uuid.Nil); err != nil {
+			return err
+		}
+		if err := tx.Create(variant).Error; err != nil {
+			return fmt.Errorf("failed to create variant: %w", err)
+		}
+
+		if variant.Stock > 0 {
+			movement := &models.StockMovement{
+				ProductID:   variant.ProductID,
+				VariantID:   &variant.ID,
+				Delta:       variant.Stock,
+				StockBefore: 0,
+				StockAfter:  variant.Stock,
+				Reason:      models.StockReasonRestock,
+				ActorType:   models.StockActorSeller,
+				ActorID:     &actorID,
+			}
+			if err := tx.Create(movement).Error; err != nil {
+				return fmt.Errorf("failed to record stock movement: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.invalidateProductVariants(ctx, variant.ProductID)
+	return nil
+}
+
+// UpdateVariant changes a variant's SKU, attributes, price override and active flag. Stock is
+// left alone, it only changes through AdjustStock.
+func (r *ProductRepository) UpdateVariant(ctx context.Context, productID, variantID uuid.UUID, req models.UpdateVariantRequest) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&variant, "id = ? AND product_id = ?", variantID, productID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrVariantNotFound
+			}
+			return fmt.Errorf("failed to get variant: %w", err)
+		}
+
+		if err := checkVariantSKU(tx, req.SKU, variantID); err != nil {
+			return err
+		}
+
+		variant.SKU = req.SKU
+		variant.Attributes = req.Attributes
+		variant.PriceOverride = req.PriceOverride
+		variant.IsActive = req.IsActive
+		if err := tx.Select("sku", "attributes", "price_override", "is_active", "updated_at").Save(&variant).Error; err != nil {
+			return fmt.Errorf("failed to update variant: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidateProductVariants(ctx, productID)
+	return &variant, nil
+}
+
+// checkVariantSKU returns ErrVariantSKUTaken when a variant other than exceptID uses sku
+func checkVariantSKU(tx *gorm.DB, sku string, exceptID uuid.UUID) error {
+	var count int64
+	if err := tx.Model(&models.ProductVariant{}).Where("sku = ? AND id <> ?", sku, exceptID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check variant SKU: %w", err)
+	}
+	if count > 0 {
+		return ErrVariantSKUTaken
+	}
+	return nil
+}
+
+// invalidateProductVariants evicts the cached product, its availability and the product lists
+// embedding its variants
+func (r *ProductRepository) invalidateProductVariants(ctx context.Context, productID uuid.UUID) {
+	if err := r.InvalidateProductCache(ctx, productID); err != nil {
+		fmt.Printf("Failed to invalidate cache of product %s after a variant change: %v\n", productID, err)
+	}
+	if err := r.InvalidateProductsCache(ctx); err != nil {
+		fmt.Printf("Failed to invalidate product lists after a variant change of %s: %v\n", productID, err)
+	}
+}
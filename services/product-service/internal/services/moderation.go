@@ -0,0 +1,226 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// Decision is a checker's verdict on a product
+type Decision string
+
+const (
+	DecisionApprove Decision = "approve"
+	DecisionReview  Decision = "review" // leave it to an admin
+	DecisionReject  Decision = "reject"
+)
+
+// Verdict is the outcome of one automated check
+type Verdict struct {
+	Decision Decision
+	Reason   string
+}
+
+// ContentChecker is an automated moderation check of a product listing
+type ContentChecker interface {
+	Name() string
+	Check(ctx context.Context, product *models.Product) (Verdict, error)
+}
+
+// Moderator runs the automated checks on new and edited products. A rejection by any check
+// rejects the product; otherwise it waits for an admin, unless autoApprove is set and every
+// check approved it. Failing checks leave the product for an admin.
+type Moderator struct {
+	checkers    []ContentChecker
+	autoApprove bool
+}
+
+// Ensure Moderator can be set on the repository
+var _ repository.ProductModerator = (*Moderator)(nil)
+
+// NewModerator creates a moderator running checkers in order
+func NewModerator(autoApprove bool, checkers ...ContentChecker) *Moderator {
+	return &Moderator{
+		checkers:    checkers,
+		autoApprove: autoApprove,
+	}
+}
+
+// NewModeratorFromEnv builds the moderator from MODERATION_AUTO_APPROVE,
+// MODERATION_BANNED_WORDS (comma separated) and MODERATION_IMAGE_API_URL, each check being
+// optional
+func NewModeratorFromEnv() *Moderator {
+	var checkers []ContentChecker
+	if words := os.Getenv("MODERATION_BANNED_WORDS"); words != "" {
+		checkers = append(checkers, NewWordListChecker(strings.Split(words, ",")))
+	}
+	if apiURL := os.Getenv("MODERATION_IMAGE_API_URL"); apiURL != "" {
+		checkers = append(checkers, NewImageModerationChecker(apiURL, os.Getenv("MODERATION_IMAGE_API_KEY"), getEnvDuration("MODERATION_IMAGE_API_TIMEOUT", 10*time.Second)))
+	}
+	return NewModerator(os.Getenv("MODERATION_AUTO_APPROVE") == "true", checkers...)
+}
+
+// Checkers returns the names of the configured checks
+func (m *Moderator) Checkers() []string {
+	names := make([]string, len(m.checkers))
+	for i, checker := range m.checkers {
+		names[i] = checker.Name()
+	}
+	return names
+}
+
+// Moderate sets the product's moderation status and reason from the checks
+func (m *Moderator) Moderate(ctx context.Context, product *models.Product) {
+	product.ModerationStatus = models.ModerationPendingReview
+	product.ModerationReason = nil
+
+	approved := m.autoApprove
+	for _, checker := range m.checkers {
+		verdict, err := checker.Check(ctx, product)
+		if err != nil {
+			log.Printf("⚠️ Moderation check %s failed for product %s, leaving it for review: %v", checker.Name(), product.ID, err)
+			approved = false
+			continue
+		}
+
+		switch verdict.Decision {
+		case DecisionReject:
+			reason := checker.Name() + ": " + verdict.Reason
+			product.ModerationStatus = models.ModerationRejected
+			product.ModerationReason = &reason
+			now := time.Now()
+			product.ModeratedAt = &now
+			log.Printf("🚫 Product %s rejected by %s", product.ID, reason)
+			return
+		case DecisionReview:
+			reason := checker.Name() + ": " + verdict.Reason
+			product.ModerationReason = &reason
+			approved = false
+		}
+	}
+
+	if approved {
+		product.ModerationStatus = models.ModerationApproved
+		now := time.Now()
+		product.ModeratedAt = &now
+	}
+}
+
+// WordListChecker rejects products whose name or description contains a banned word
+type WordListChecker struct {
+	pattern *regexp.Regexp
+}
+
+// NewWordListChecker creates a checker matching words case-insensitively as whole words
+func NewWordListChecker(words []string) *WordListChecker {
+	var quoted []string
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	if len(quoted) == 0 {
+		return &WordListChecker{}
+	}
+	return &WordListChecker{
+		pattern: regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`),
+	}
+}
+
+// Name identifies the check in moderation reasons
+func (c *WordListChecker) Name() string {
+	return "banned_words"
+}
+
+// Check looks for banned words
+func (c *WordListChecker) Check(ctx context.Context, product *models.Product) (Verdict, error) {
+	if c.pattern == nil {
+		return Verdict{Decision: DecisionApprove}, nil
+	}
+	for _, text := range []string{product.Name, product.Description} {
+		if match := c.pattern.FindString(text); match != "" {
+			return Verdict{Decision: DecisionReject, Reason: fmt.Sprintf("contains banned word %q", strings.ToLower(match))}, nil
+		}
+	}
+	return Verdict{Decision: DecisionApprove}, nil
+}
+
+// ImageModerationChecker sends the product's image URLs to an image moderation API.
+// The API receives {"image_urls": [...]} and answers {"flagged": bool, "reason": "..."};
+// flagged products are left for an admin since image classifiers misfire.
+type ImageModerationChecker struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewImageModerationChecker creates a checker calling url, authenticated with apiKey as a
+// bearer token when set
+func NewImageModerationChecker(url, apiKey string, timeout time.Duration) *ImageModerationChecker {
+	return &ImageModerationChecker{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name identifies the check in moderation reasons
+func (c *ImageModerationChecker) Name() string {
+	return "image_moderation"
+}
+
+// Check asks the API about the product's images
+func (c *ImageModerationChecker) Check(ctx context.Context, product *models.Product) (Verdict, error) {
+	if len(product.Images) == 0 {
+		return Verdict{Decision: DecisionApprove}, nil
+	}
+
+	imageURLs := make([]string, len(product.Images))
+	for i, image := range product.Images {
+		imageURLs[i] = image.ImageUrl
+	}
+	body, err := json.Marshal(map[string][]string{"image_urls": imageURLs})
+	if err != nil {
+		return Verdict{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return Verdict{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("image moderation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Verdict{}, fmt.Errorf("image moderation API returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Flagged bool   `json:"flagged"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Verdict{}, fmt.Errorf("failed to decode image moderation response: %w", err)
+	}
+	if result.Flagged {
+		return Verdict{Decision: DecisionReview, Reason: result.Reason}, nil
+	}
+	return Verdict{Decision: DecisionApprove}, nil
+}
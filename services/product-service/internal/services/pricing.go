@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// PricingEngine evaluates pricing rules. The rule that gives the lowest unit price wins,
+// rules don't stack; ties go to the oldest rule. Prices are whole rupiah.
+type PricingEngine struct {
+	repo *repository.ProductRepository
+}
+
+// NewPricingEngine creates a pricing engine reading the cached active rules from repo
+func NewPricingEngine(repo *repository.ProductRepository) *PricingEngine {
+	return &PricingEngine{repo: repo}
+}
+
+// Quote prices quantity units of a product for a customer
+func (e *PricingEngine) Quote(ctx context.Context, product *models.ProductResponse, quantity int, member bool) (*models.PriceQuote, error) {
+	if quantity < 1 {
+		return nil, fmt.Errorf("quantity must be at least 1")
+	}
+
+	rules, err := e.repo.ActivePricingRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unitPrice, rule := bestPrice(rules, product, quantity, member, time.Now())
+	quote := &models.PriceQuote{
+		ProductID: product.ID,
+		Quantity:  quantity,
+		Member:    member,
+		BasePrice: product.Price,
+		UnitPrice: unitPrice,
+		Discount:  product.Price - unitPrice,
+		Total:     unitPrice * float64(quantity),
+	}
+	if rule != nil {
+		quote.Rule = rule.ToApplied()
+	}
+	return quote, nil
+}
+
+// Apply sets the single unit final and member prices of products. When the rules can't be
+// loaded products keep their base price rather than failing the request.
+func (e *PricingEngine) Apply(ctx context.Context, products ...*models.ProductResponse) {
+	rules, err := e.repo.ActivePricingRules(ctx)
+	if err != nil {
+		log.Printf("⚠️ Pricing rules unavailable, serving base prices: %v", err)
+		rules = nil
+	}
+
+	now := time.Now()
+	for _, product := range products {
+		finalPrice, rule := bestPrice(rules, product, 1, false, now)
+		product.FinalPrice = finalPrice
+		product.MemberPrice, _ = bestPrice(rules, product, 1, true, now)
+		product.PricingRule = nil
+		if rule != nil {
+			product.PricingRule = rule.ToApplied()
+		}
+	}
+}
+
+// bestPrice returns the lowest unit price the rules give a product and the rule giving it,
+// nil when no rule lowers the base price
+func bestPrice(rules []models.PricingRule, product *models.ProductResponse, quantity int, member bool, now time.Time) (float64, *models.PricingRule) {
+	best := product.Price
+	var bestRule *models.PricingRule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Applies(product, quantity, member, now) {
+			continue
+		}
+		if price := discountedPrice(product.Price, rule); price < best {
+			best = price
+			bestRule = rule
+		}
+	}
+	return best, bestRule
+}
+
+// discountedPrice applies one rule to a unit price, rounded to whole rupiah and never below zero
+func discountedPrice(price float64, rule *models.PricingRule) float64 {
+	var discounted float64
+	switch rule.Type {
+	case models.PricingRulePercentage:
+		discounted = price * (100 - rule.Value) / 100
+	case models.PricingRuleFixed:
+		discounted = price - rule.Value
+	default:
+		return price
+	}
+	return math.Max(0, math.Round(discounted))
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"product-service/internal/events"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// PublishScheduler activates and deactivates products when their publish_at or
+// unpublish_at time passes, every PUBLISH_SCHEDULER_INTERVAL
+type PublishScheduler struct {
+	repo     *repository.ProductRepository
+	eventSvc *events.EventService
+
+	interval time.Duration
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	// Totals since start, reported on /api/v1/admin/runtime
+	runs        atomic.Int64
+	published   atomic.Int64
+	unpublished atomic.Int64
+	errors      atomic.Int64
+	lastRun     atomic.Pointer[time.Time]
+}
+
+// NewPublishScheduler creates the scheduler from environment configuration
+func NewPublishScheduler(repo *repository.ProductRepository, eventSvc *events.EventService) *PublishScheduler {
+	return &PublishScheduler{
+		repo:     repo,
+		eventSvc: eventSvc,
+		interval: getEnvDuration("PUBLISH_SCHEDULER_INTERVAL", time.Minute),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler immediately and then every interval
+func (s *PublishScheduler) Start() {
+	log.Printf("⏰ Publish scheduler started (interval: %s)", s.interval)
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.Run()
+		for {
+			select {
+			case <-ticker.C:
+				s.Run()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background worker
+func (s *PublishScheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+}
+
+// Run applies every schedule that is due
+func (s *PublishScheduler) Run() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	s.runs.Add(1)
+	s.lastRun.Store(&now)
+
+	published, unpublished, err := s.repo.ApplyPublishSchedule(ctx, now)
+	if err != nil {
+		log.Printf("❌ Failed to apply publish schedule: %v", err)
+		s.errors.Add(1)
+		return
+	}
+
+	for _, product := range published {
+		if err := s.eventSvc.PublishProductUpdated(product.ID.String(), product.Stock, models.ProductReasonScheduledPublish); err != nil {
+			log.Printf("⚠️ Failed to publish product.updated for %s: %v", product.ID, err)
+		}
+	}
+	for _, product := range unpublished {
+		if err := s.eventSvc.PublishProductUpdated(product.ID.String(), product.Stock, models.ProductReasonScheduledUnpublish); err != nil {
+			log.Printf("⚠️ Failed to publish product.updated for %s: %v", product.ID, err)
+		}
+	}
+
+	s.published.Add(int64(len(published)))
+	s.unpublished.Add(int64(len(unpublished)))
+	if len(published) > 0 || len(unpublished) > 0 {
+		log.Printf("⏰ Publish schedule applied: %d published, %d unpublished", len(published), len(unpublished))
+	}
+}
+
+// Stats reports the scheduler configuration and totals
+func (s *PublishScheduler) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"interval":    s.interval.String(),
+		"runs":        s.runs.Load(),
+		"published":   s.published.Load(),
+		"unpublished": s.unpublished.Load(),
+		"errors":      s.errors.Load(),
+		"last_run":    s.lastRun.Load(),
+	}
+}
+
+// getEnvDuration reads a duration environment variable with a default
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
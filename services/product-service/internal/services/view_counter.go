@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"product-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ViewCounter counts product detail views for the conversion funnel. Views are buffered in
+// memory and added to the funnel table every PRODUCT_VIEW_FLUSH_INTERVAL (default 30s), so
+// the hot GET /products/:id path never waits on a write.
+type ViewCounter struct {
+	repo *repository.ProductRepository
+
+	interval time.Duration
+	mu       sync.Mutex
+	pending  map[uuid.UUID]int64
+	day      time.Time // UTC day the pending views belong to
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+
+	// Totals since start, reported on /api/v1/admin/runtime
+	recorded atomic.Int64
+	flushed  atomic.Int64
+	dropped  atomic.Int64
+	errors   atomic.Int64
+}
+
+// maxPendingViews bounds the buffered products, views of further products are dropped until
+// the next flush
+const maxPendingViews = 100000
+
+// NewViewCounter creates the view counter from environment configuration
+func NewViewCounter(repo *repository.ProductRepository) *ViewCounter {
+	return &ViewCounter{
+		repo:     repo,
+		interval: getEnvDuration("PRODUCT_VIEW_FLUSH_INTERVAL", 30*time.Second),
+		pending:  make(map[uuid.UUID]int64),
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Record counts one view of a product
+func (vc *ViewCounter) Record(productID uuid.UUID) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	// Keep the views of the previous day apart, they are written to its row
+	if !vc.day.Equal(today) {
+		if len(vc.pending) > 0 {
+			go vc.write(vc.day, vc.pending)
+			vc.pending = make(map[uuid.UUID]int64)
+		}
+		vc.day = today
+	}
+
+	if _, ok := vc.pending[productID]; !ok && len(vc.pending) >= maxPendingViews {
+		vc.dropped.Add(1)
+		return
+	}
+	vc.pending[productID]++
+	vc.recorded.Add(1)
+}
+
+// Start flushes the buffered views every interval until stopped
+func (vc *ViewCounter) Start() {
+	log.Printf("👀 Product view counter started (flush interval: %s)", vc.interval)
+
+	go func() {
+		defer close(vc.done)
+
+		ticker := time.NewTicker(vc.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				vc.Flush()
+			case <-vc.stopCh:
+				vc.Flush()
+				return
+			}
+		}
+	}()
+}
+
+// Stop flushes the remaining views and stops the background worker
+func (vc *ViewCounter) Stop() {
+	vc.stopOnce.Do(func() {
+		close(vc.stopCh)
+		<-vc.done
+	})
+}
+
+// Flush adds the buffered views to the funnel table
+func (vc *ViewCounter) Flush() {
+	vc.mu.Lock()
+	pending, day := vc.pending, vc.day
+	vc.pending = make(map[uuid.UUID]int64)
+	vc.mu.Unlock()
+
+	vc.write(day, pending)
+}
+
+// write adds views to the funnel of day. When that fails they are put back for the next
+// flush, unless the day is over by then.
+func (vc *ViewCounter) write(day time.Time, views map[uuid.UUID]int64) {
+	if len(views) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var total int64
+	for _, count := range views {
+		total += count
+	}
+
+	if err := vc.repo.AddProductViews(ctx, day, views); err != nil {
+		log.Printf("❌ Failed to flush %d product views: %v", total, err)
+		vc.errors.Add(1)
+
+		vc.mu.Lock()
+		defer vc.mu.Unlock()
+		if !vc.day.Equal(day) {
+			vc.dropped.Add(total)
+			return
+		}
+		for productID, count := range views {
+			vc.pending[productID] += count
+		}
+		return
+	}
+
+	vc.flushed.Add(total)
+}
+
+// Stats reports the counter configuration and totals
+func (vc *ViewCounter) Stats() map[string]interface{} {
+	vc.mu.Lock()
+	pending := len(vc.pending)
+	vc.mu.Unlock()
+
+	return map[string]interface{}{
+		"flush_interval":   vc.interval.String(),
+		"pending_products": pending,
+		"recorded":         vc.recorded.Load(),
+		"flushed":          vc.flushed.Load(),
+		"dropped":          vc.dropped.Load(),
+		"errors":           vc.errors.Load(),
+	}
+}
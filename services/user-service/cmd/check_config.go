@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"user-service/internal/cache"
+	"user-service/internal/events"
+	"user-service/internal/redact"
+	"user-service/internal/serviceauth"
+	"user-service/internal/services"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// configKind is the type an environment variable must parse as
+type configKind int
+
+const (
+	kindString configKind = iota
+	kindInt
+	kindBool
+	kindDuration
+	kindURL
+	kindEnum
+)
+
+// configVar describes one environment variable of the service
+type configVar struct {
+	name     string
+	kind     configKind
+	required bool
+	secret   bool     // Redacted in the report
+	values   []string // Allowed values of a kindEnum, case-insensitive
+}
+
+// configCheck is a read only check of a dependency or of configuration loaded at startup
+type configCheck struct {
+	name     string
+	optional bool // The service runs without it, a failure is only a warning
+	run      func(ctx context.Context) error
+}
+
+// configSchema lists the environment variables of User-Service, see env.example
+var configSchema = []configVar{
+	{name: "DB_HOST"},
+	{name: "DB_PORT", kind: kindInt},
+	{name: "DB_USER"},
+	{name: "DB_PASSWORD", secret: true},
+	{name: "DB_NAME"},
+	{name: "DB_MAX_OPEN_CONNS", kind: kindInt},
+	{name: "DB_MAX_IDLE_CONNS", kind: kindInt},
+	{name: "DB_CONN_MAX_LIFETIME", kind: kindDuration},
+	{name: "DB_CONN_MAX_IDLE_TIME", kind: kindDuration},
+	{name: "DB_SLOW_ACQUIRE_THRESHOLD", kind: kindDuration},
+	{name: "JWT_SECRET", secret: true},
+	{name: "JWT_ACCESS_EXPIRY", kind: kindDuration},
+	{name: "JWT_REFRESH_EXPIRY", kind: kindDuration},
+	{name: "JWT_CLOCK_SKEW", kind: kindDuration},
+	{name: "JWT_KEYS_DIR"},
+	{name: "JWT_ACTIVE_KID"},
+	{name: "REDIS_HOST"},
+	{name: "REDIS_PORT", kind: kindInt},
+	{name: "REDIS_PASSWORD", secret: true},
+	{name: "REDIS_DB", kind: kindInt},
+	{name: "REDIS_MODE", kind: kindEnum, values: []string{cache.RedisStandalone, cache.RedisSentinel, cache.RedisCluster}},
+	{name: "REDIS_ADDRS"},
+	{name: "REDIS_MASTER_NAME"},
+	{name: "REDIS_SENTINEL_PASSWORD", secret: true},
+	{name: "EVENT_BUS", kind: kindEnum, values: []string{events.TransportRabbitMQ, events.TransportKafka}},
+	{name: "CONSUMER_MODE", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_MODE_CHECKOUT", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_MODE_EMAIL", kind: kindEnum, values: []string{string(events.ConsumerModeActive), string(events.ConsumerModeShadow)}},
+	{name: "CONSUMER_CONCURRENCY", kind: kindInt},
+	{name: "CONSUMER_PREFETCH", kind: kindInt},
+	{name: "CONSUMER_CONCURRENCY_EMAIL_QUEUE", kind: kindInt},
+	{name: "RABBITMQ_HOST"},
+	{name: "RABBITMQ_PORT", kind: kindInt},
+	{name: "RABBITMQ_USERNAME"},
+	{name: "RABBITMQ_PASSWORD", secret: true},
+	{name: "RABBITMQ_DECLARE_TOPOLOGY", kind: kindBool},
+	{name: "RABBITMQ_MANAGEMENT_URL", kind: kindURL},
+	{name: "EVENT_BUS_STARTUP_WAIT", kind: kindDuration},
+	{name: "EVENT_BUS_RETRY_INTERVAL", kind: kindDuration},
+	{name: "EVENT_OUTBOX_RELAY_INTERVAL", kind: kindDuration},
+	{name: "KAFKA_BROKERS"},
+	{name: "KAFKA_TOPIC_PREFIX"},
+	{name: "KAFKA_TOPIC_PARTITIONS", kind: kindInt},
+	{name: "KAFKA_REPLICATION_FACTOR", kind: kindInt},
+	{name: "KAFKA_MAX_ATTEMPTS", kind: kindInt},
+	{name: "SERVICE_AUTH_SECRET", secret: true},
+	{name: "SERVICE_AUTH_TOKEN_TTL", kind: kindDuration},
+	{name: "PORT", kind: kindInt},
+	{name: "BIND_ADDR"},
+	{name: "STARTUP_WAIT_TIMEOUT", kind: kindDuration},
+	{name: "STARTUP_RETRY_INTERVAL", kind: kindDuration},
+	{name: "APP_ENV", kind: kindEnum, values: []string{"development", "staging", "production"}},
+	{name: "GIN_MODE", kind: kindEnum, values: []string{"debug", "release", "test"}},
+	{name: "TRUSTED_PROXIES"},
+	{name: "ENABLE_PPROF", kind: kindBool},
+	{name: "ADMIN_TOKEN", secret: true},
+	{name: "SANDBOX_TOOLS", kind: kindBool},
+	{name: "METRICS_TOKEN", secret: true},
+	{name: "LOG_REDACT_FIELDS"},
+	{name: "LOG_REQUEST_BODIES", kind: kindBool},
+	{name: "SMTP_HOST"},
+	{name: "SMTP_PORT", kind: kindInt},
+	{name: "SMTP_USERNAME", required: true},
+	{name: "SMTP_PASSWORD", required: true, secret: true},
+	{name: "FROM_EMAIL"},
+	{name: "FROM_NAME"},
+	{name: "EMAIL_WEBHOOK_SECRET", secret: true},
+	{name: "DEFAULT_LOCALE", kind: kindEnum, values: []string{"id", "en"}},
+	{name: "PASSWORD_MIN_LENGTH", kind: kindInt},
+	{name: "PASSWORD_REQUIRE_UPPERCASE", kind: kindBool},
+	{name: "PASSWORD_REQUIRE_LOWERCASE", kind: kindBool},
+	{name: "PASSWORD_REQUIRE_DIGIT", kind: kindBool},
+	{name: "PASSWORD_REQUIRE_SYMBOL", kind: kindBool},
+	{name: "PASSWORD_CHECK_BREACHED", kind: kindBool},
+	{name: "PWNED_PASSWORDS_API_URL", kind: kindURL},
+	{name: "USERNAME_MIN_LENGTH", kind: kindInt},
+	{name: "USERNAME_MAX_LENGTH", kind: kindInt},
+	{name: "USERNAME_RESERVED"},
+	{name: "AVAILABILITY_RATE_LIMIT", kind: kindInt},
+	{name: "SEND_LIMIT_PER_EMAIL", kind: kindInt},
+	{name: "SEND_LIMIT_PER_IP", kind: kindInt},
+	{name: "SEND_LIMIT_WINDOW", kind: kindDuration},
+	{name: "OTP_LOCKOUT_PER_EMAIL", kind: kindInt},
+	{name: "OTP_LOCKOUT_PER_IP", kind: kindInt},
+	{name: "OTP_LOCKOUT_WINDOW", kind: kindDuration},
+	{name: "OTP_LOCKOUT_DURATION", kind: kindDuration},
+	{name: "OTP_LOCKOUT_MAX", kind: kindDuration},
+	{name: "CAPTCHA_PROVIDER", kind: kindEnum, values: []string{"turnstile", "recaptcha"}},
+	{name: "CAPTCHA_SECRET", secret: true},
+	{name: "CAPTCHA_MIN_SCORE"},
+	{name: "CAPTCHA_AFTER_FAILURES", kind: kindInt},
+	{name: "SMS_PROVIDER", kind: kindEnum, values: []string{"log", "twilio"}},
+	{name: "TWILIO_ACCOUNT_SID"},
+	{name: "TWILIO_AUTH_TOKEN", secret: true},
+	{name: "TWILIO_SMS_FROM"},
+	{name: "TWILIO_WHATSAPP_FROM"},
+	{name: "PHONE_DEFAULT_COUNTRY_CODE", kind: kindInt},
+	{name: "PHONE_OTP_TTL", kind: kindDuration},
+	{name: "PHONE_OTP_MAX_ATTEMPTS", kind: kindInt},
+	{name: "EMAIL_VERIFICATION_URL", kind: kindURL},
+	{name: "EMAIL_VERIFICATION_TTL", kind: kindDuration},
+	{name: "FRONTEND_URL", kind: kindURL},
+	{name: "ACCOUNT_CLEANUP_ENABLED", kind: kindBool},
+	{name: "ACCOUNT_CLEANUP_INTERVAL", kind: kindDuration},
+	{name: "ACCOUNT_CLEANUP_DRY_RUN", kind: kindBool},
+	{name: "OTP_MAX_AGE", kind: kindDuration},
+	{name: "UNVERIFIED_ACCOUNT_MAX_DAYS", kind: kindInt},
+	{name: "UNVERIFIED_ACCOUNT_GRACE_DAYS", kind: kindInt},
+	{name: "UNVERIFIED_ACCOUNT_ACTION", kind: kindEnum, values: []string{"delete", "flag"}},
+	{name: "SESSION_REVOKE_URL", kind: kindURL},
+	{name: "SESSION_REVOKE_TTL", kind: kindDuration},
+	{name: "CAMPAIGN_INTERVAL", kind: kindDuration},
+	{name: "CAMPAIGN_BATCH_SIZE", kind: kindInt},
+	{name: "API_V1_DEPRECATED_AT"},
+	{name: "API_V1_SUNSET"},
+}
+
+// configChecks are the dependencies of User-Service. They only connect and authenticate:
+// nothing is migrated, declared or sent.
+func configChecks() []configCheck {
+	return []configCheck{
+		{name: "postgres", run: func(ctx context.Context) error {
+			return pingPostgres(ctx, databaseDSN())
+		}},
+		{name: "redis", optional: true, run: func(ctx context.Context) error {
+			redisService, err := cache.NewRedisService()
+			if err != nil {
+				return err
+			}
+			return redisService.Client.Close()
+		}},
+		{name: "event bus", run: func(ctx context.Context) error {
+			return events.Ping()
+		}},
+		{name: "smtp", run: func(ctx context.Context) error {
+			emailService, err := services.NewEmailService()
+			if err != nil {
+				return err
+			}
+			return emailService.Ping()
+		}},
+		{name: "sms provider", run: func(ctx context.Context) error {
+			_, err := services.NewSMSProviderFromEnv()
+			return err
+		}},
+		{name: "captcha", run: func(ctx context.Context) error {
+			_, err := services.NewCaptchaVerifierFromEnv()
+			return err
+		}},
+		{name: "service auth", run: func(ctx context.Context) error {
+			_, err := serviceauth.VerifierFromEnv(serviceauth.UserService)
+			return err
+		}},
+	}
+}
+
+// runCheckConfig validates the environment against configSchema, runs configChecks and
+// prints a report with secrets redacted. It reports whether everything required passed, so
+// it can gate a deploy or run as an init container.
+func runCheckConfig(service string) bool {
+	ok := true
+
+	fmt.Printf("🔎 %s configuration\n", service)
+	for _, v := range configSchema {
+		value, set := os.LookupEnv(v.name)
+		if err := v.validate(value); err != nil {
+			fmt.Printf("  ❌ %s=%s: %v\n", v.name, v.display(value), err)
+			ok = false
+			continue
+		}
+		if !set || value == "" {
+			fmt.Printf("  ·  %s (default)\n", v.name)
+			continue
+		}
+		fmt.Printf("  ✅ %s=%s\n", v.name, v.display(value))
+	}
+
+	fmt.Println("🔌 Dependencies")
+	for _, check := range configChecks() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		start := time.Now()
+		err := check.run(ctx)
+		cancel()
+
+		switch {
+		case err == nil:
+			fmt.Printf("  ✅ %s (%s)\n", check.name, time.Since(start).Round(time.Millisecond))
+		case check.optional:
+			fmt.Printf("  ⚠️ %s (optional): %v\n", check.name, err)
+		default:
+			fmt.Printf("  ❌ %s: %v\n", check.name, err)
+			ok = false
+		}
+	}
+
+	if !ok {
+		fmt.Println("❌ Configuration check failed")
+		return false
+	}
+	fmt.Println("✅ Configuration check passed")
+	return true
+}
+
+// validate checks value against the variable's kind, an empty value means the default
+func (v configVar) validate(value string) error {
+	if value == "" {
+		if v.required {
+			return fmt.Errorf("required")
+		}
+		return nil
+	}
+
+	switch v.kind {
+	case kindInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("not an integer")
+		}
+	case kindBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("not a boolean")
+		}
+	case kindDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("not a duration (e.g. 30s, 15m, 24h)")
+		}
+	case kindURL:
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("not an absolute URL")
+		}
+	case kindEnum:
+		for _, allowed := range v.values {
+			if strings.EqualFold(value, allowed) {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %s", strings.Join(v.values, ", "))
+	}
+	return nil
+}
+
+// display returns value as printed in the report: secrets are redacted and URLs lose their
+// password
+func (v configVar) display(value string) string {
+	if value == "" {
+		return value
+	}
+	if v.secret {
+		return redact.Redacted
+	}
+	if v.kind == kindURL {
+		if parsed, err := url.Parse(value); err == nil {
+			return parsed.Redacted()
+		}
+	}
+	return value
+}
+
+// pingPostgres opens a short lived connection to dsn and pings it
+func pingPostgres(ctx context.Context, dsn string) error {
+	db, err := gorm.Open(postgres.Open(dsn+" connect_timeout=5"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	return sqlDB.PingContext(ctx)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -11,27 +13,33 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
+	"user-service/internal/apiversion"
+	"user-service/internal/cache"
 	"user-service/internal/consumers"
+	"user-service/internal/dbpool"
 	"user-service/internal/events"
 	"user-service/internal/handlers"
+	"user-service/internal/middleware"
+	"user-service/internal/i18n"
 	"user-service/internal/models"
 	"user-service/internal/repository"
+	"user-service/internal/serviceauth"
+	"user-service/internal/services"
 )
 
 var (
 	DB                *gorm.DB
+	Redis             *cache.RedisService // optional, shares send throttling across instances
 	EventService      *events.EventService
 	EmailConsumer     *consumers.EmailConsumer
 	CheckoutConsumer  *consumers.CheckoutConsumer
+	AccountCleanup    *services.AccountCleanupService
+	Campaigns         *services.CampaignService
+	AuthMetrics       = services.NewAuthMetrics() // registration → verification funnel
 )
 
-func initDB() {
-	// Load .env for main application configuration
-	// Note: Each internal package also loads .env independently for modularity
-	if err := godotenv.Load(); err != nil {
-		log.Println("⚠️ .env file not found in main, using system env")
-	}
-
+// databaseDSN builds the PostgreSQL connection string from the DB_* settings
+func databaseDSN() string {
 	// Get database configuration from environment
 	dbHost := os.Getenv("DB_HOST")
 	if dbHost == "" {
@@ -59,16 +67,29 @@ func initDB() {
 	}
 
 	// Connection string
-	dsn := fmt.Sprintf(
+	return fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
 		dbHost, dbUser, dbPass, dbName, dbPort,
 	)
+}
 
-	// Connect to database using GORM
-	var errDB error
-	DB, errDB = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if errDB != nil {
-		log.Fatalf("❌ Failed to connect to database: %v", errDB)
+func initDB() {
+	// Load .env for main application configuration
+	// Note: Each internal package also loads .env independently for modularity
+	if err := godotenv.Load(); err != nil {
+		log.Println("⚠️ .env file not found in main, using system env")
+	}
+
+	dsn := databaseDSN()
+
+	// Connect to database using GORM, waiting for it to accept connections
+	err := waitFor("Database", func() error {
+		var errDB error
+		DB, errDB = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		return errDB
+	})
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to database: %v", err)
 	}
 
 	sqlDB, err := DB.DB()
@@ -80,11 +101,32 @@ func initDB() {
 		log.Fatalf("❌ Database not responding: %v", err)
 	}
 
+	// Size the connection pool (DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS, DB_CONN_MAX_*)
+	poolConfig := dbpool.ConfigFromEnv(dbpool.Config{
+		MaxOpen:     25,
+		MaxIdle:     10,
+		MaxLifetime: time.Hour,
+		MaxIdleTime: 10 * time.Minute,
+		SlowAcquire: 100 * time.Millisecond,
+	})
+	dbpool.Register("primary", sqlDB, poolConfig)
+	log.Printf("✅ Database pool configured: %s", poolConfig)
+
 	// Auto migrate the User model
-	if err := DB.AutoMigrate(&models.User{}); err != nil {
+	if err := DB.AutoMigrate(&models.User{}, &models.EmailLog{}, &models.EmailVerificationToken{}, &models.LoginDevice{}, &models.SessionRevokeToken{}, &models.RefreshToken{}, &models.OutboxEvent{}, &models.EmailCampaign{}, &models.UserPurchase{}, &models.EmailSuppression{}); err != nil {
 		log.Fatalf("❌ Failed to migrate database: %v", err)
 	}
 
+	// Logins look users up by email or username ignoring case, keep both unique that way
+	for _, stmt := range []string{
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email))",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (LOWER(username))",
+	} {
+		if err := DB.Exec(stmt).Error; err != nil {
+			log.Printf("⚠️ Could not create case-insensitive index, accounts differing only in case must be merged first: %v", err)
+		}
+	}
+
 	// Force update OTP field size if needed
 	if err := DB.Exec("ALTER TABLE users ALTER COLUMN otp_code TYPE VARCHAR(6)").Error; err != nil {
 		log.Printf("⚠️ Could not alter otp_code column (might already be correct): %v", err)
@@ -94,26 +136,61 @@ func initDB() {
 }
 
 
+func initRedis() {
+	var err error
+	Redis, err = cache.NewRedisService()
+	if err != nil {
+		log.Printf("⚠️ Redis unavailable, OTP and reset code throttling is per instance: %v", err)
+		Redis = nil
+		return
+	}
+	log.Println("✅ Redis connected, OTP and reset code throttling shared across instances")
+}
+
 func initRabbitMQ() {
+	// Waits up to EVENT_BUS_STARTUP_WAIT for the broker, then keeps connecting in the background
 	var err error
 	EventService, err = events.NewEventService()
 	if err != nil {
-		log.Printf("⚠️ Failed to connect to RabbitMQ: %v", err)
-		log.Println("⚠️ Continuing without RabbitMQ (events will not be published)")
+		log.Printf("⚠️ Failed to initialize event bus: %v", err)
+		log.Println("⚠️ Continuing without events (nothing will be published or consumed)")
 		EventService = nil
-	} else {
+		return
+	}
+
+	// Events that can't be published wait in the database until the broker is back
+	EventService.SetOutbox(repository.NewEventOutboxRepository(DB))
+	relayInterval := 10 * time.Second
+	if value := os.Getenv("EVENT_OUTBOX_RELAY_INTERVAL"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			relayInterval = parsed
+		} else {
+			log.Printf("⚠️ Invalid EVENT_OUTBOX_RELAY_INTERVAL %q, using %v", value, relayInterval)
+		}
+	}
+	EventService.StartOutboxRelay(relayInterval)
+
+	if EventService.IsConnected() {
 		log.Println("✅ RabbitMQ connected successfully!")
+	} else {
+		log.Println("⚠️ RabbitMQ unavailable, events are queued in the database and consumers start once it is connected")
 	}
 }
 
 func initEmailConsumer() {
+	if EventService == nil {
+		log.Println("⚠️ Event bus not available, skipping email consumer initialization")
+		return
+	}
+
 	var err error
-	EmailConsumer, err = consumers.NewEmailConsumer()
+	EmailConsumer, err = consumers.NewEmailConsumer(EventService)
 	if err != nil {
 		log.Printf("⚠️ Failed to initialize email consumer: %v", err)
 		log.Println("⚠️ Continuing without email consumer...")
 	} else {
 		log.Println("✅ Email consumer initialized successfully")
+		EmailConsumer.SetAuthMetrics(AuthMetrics)
 		
 		// Start the email consumer
 		if err := EmailConsumer.Start(); err != nil {
@@ -142,18 +219,61 @@ func initCheckoutConsumer() {
 	}
 }
 
+func initAccountCleanup() {
+	// Always available to admins (dry runs included), scheduled only when enabled
+	AccountCleanup = services.NewAccountCleanupService(repository.NewUserRepository(DB), EventService)
+	AccountCleanup.SetAuthMetrics(AuthMetrics)
+	if os.Getenv("ACCOUNT_CLEANUP_ENABLED") != "true" {
+		log.Println("ℹ️ Scheduled account cleanup disabled (set ACCOUNT_CLEANUP_ENABLED=true)")
+		return
+	}
+
+	AccountCleanup.Start()
+}
+
+func initCampaigns() {
+	// Queues the emails of running announcement campaigns in throttled batches
+	Campaigns = services.NewCampaignService(repository.NewCampaignRepository(DB), EventService)
+	if EventService == nil {
+		log.Println("⚠️ Event bus not available, campaign emails are not sent")
+		return
+	}
+
+	Campaigns.Start()
+}
+
 func setupRoutes() *gin.Engine {
 	// Initialize handlers
-	userHandler := handlers.NewUserHandler(DB)
+	userHandler := handlers.NewUserHandler(DB, EventService)
+	if Redis != nil {
+		userHandler.SetSendThrottle(services.NewSendThrottle(Redis))
+	}
+	smsProvider, err := services.NewSMSProviderFromEnv()
+	if err != nil {
+		log.Fatalf("❌ Invalid SMS configuration: %v", err)
+	}
+	userHandler.SetPhoneVerification(services.NewPhoneVerificationService(smsProvider))
+	userHandler.SetAuthMetrics(AuthMetrics)
+
+	// OTP lockouts and CAPTCHA flags, shared across instances through Redis when available
+	captcha, err := services.NewCaptchaVerifierFromEnv()
+	if err != nil {
+		log.Fatalf("❌ Invalid CAPTCHA configuration: %v", err)
+	}
+	var guardStore services.RateLimitStore = services.NewMemoryRateLimitStore()
+	if Redis != nil {
+		guardStore = Redis
+	}
+	userHandler.SetVerificationGuard(services.NewVerificationGuard(guardStore, captcha))
 
 	// Setup Gin with middleware
-	r := gin.Default()
+	r := newRouter()
 
 	// CORS middleware
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Accept-Language, Authorization")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -163,20 +283,29 @@ func setupRoutes() *gin.Engine {
 		c.Next()
 	})
 
-	// Request logging middleware
-	r.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s \"%s\" %s\"\n",
-			param.ClientIP,
-			param.TimeStamp.Format(time.RFC1123),
-			param.Method,
-			param.Path,
-			param.Request.Proto,
-			param.StatusCode,
-			param.Latency,
-			param.Request.UserAgent(),
-			param.ErrorMessage,
-		)
-	}))
+	// Locale negotiation middleware (Accept-Language)
+	r.Use(i18n.Middleware())
+
+	// Service to service authentication (SERVICE_AUTH_SECRET): everything but health checks,
+	// the JWKS and admin token routes must come through the gateway or another service
+	serviceVerifier, err := serviceauth.VerifierFromEnv(serviceauth.UserService)
+	if err != nil {
+		log.Fatalf("❌ Invalid service authentication configuration: %v", err)
+	}
+	if serviceVerifier != nil {
+		log.Println("🔐 Service authentication enabled")
+	} else if appEnv() == "production" {
+		log.Fatalf("❌ SERVICE_AUTH_SECRET is required in production")
+	} else {
+		log.Println("⚠️ SERVICE_AUTH_SECRET not set, accepting calls from anywhere")
+	}
+	r.Use(middleware.ServiceAuth(serviceVerifier,
+		"/health",
+		"/.well-known/jwks.json",
+		"/api/v1/admin/",
+		"/debug/",
+		"/metrics",
+	))
 
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
@@ -196,12 +325,20 @@ func setupRoutes() *gin.Engine {
 			health["database"] = "ok"
 		}
 
-		// Redis removed - using database-only OTP storage
-		health["redis"] = "not_used"
+		// OTPs live in the database, Redis only holds send throttling counters
+		if Redis == nil {
+			health["redis"] = "not_configured"
+		} else if err := Redis.Client.Ping(c.Request.Context()).Err(); err != nil {
+			health["redis"] = "error"
+		} else {
+			health["redis"] = "ok"
+		}
 
 		// Check RabbitMQ
 		if EventService != nil {
-			if err := EventService.HealthCheck(); err != nil {
+			if err := EventService.HealthCheck(); errors.Is(err, events.ErrNotConnected) {
+				health["rabbitmq"] = "connecting" // events are queued in the outbox meanwhile
+			} else if err != nil {
 				health["rabbitmq"] = "error"
 			} else {
 				health["rabbitmq"] = "ok"
@@ -213,8 +350,34 @@ func setupRoutes() *gin.Engine {
 		c.JSON(200, health)
 	})
 
-	// API routes
-	api := r.Group("/api/v1")
+	// Redis topology health: the sentinel master or cluster state, 503 when Redis is down
+	r.GET("/health/redis", func(c *gin.Context) {
+		if Redis == nil {
+			c.JSON(503, gin.H{
+				"service": "user-service",
+				"redis":   cache.RedisState{Status: "down", Error: "not connected"},
+			})
+			return
+		}
+
+		state := Redis.RedisState(c.Request.Context())
+		status := 200
+		if state.Status == "down" {
+			status = 503
+		}
+		c.JSON(status, gin.H{
+			"service": "user-service",
+			"redis":   state,
+		})
+	})
+
+	// JSON Web Key Set for validating RS256/ES256 tokens
+	r.GET("/.well-known/jwks.json", userHandler.JWTService.JWKSHandler)
+
+	// API routes, served under /api/v1 and /api/v2. Routes overridden in v2 are deprecated
+	// in v1 (API_V1_DEPRECATED_AT, API_V1_SUNSET).
+	api := apiversion.New(r, "v1", "v2")
+	api.Deprecate("v1", apiversion.DeprecationFromEnv("v1"))
 	{
 		// Public routes (no authentication required)
 		public := api.Group("/auth")
@@ -222,11 +385,18 @@ func setupRoutes() *gin.Engine {
 			public.POST("/register", userHandler.Register)
 			public.POST("/login", userHandler.Login)
 			public.POST("/verify-otp", userHandler.VerifyOTP)
+			public.GET("/verify-email", userHandler.VerifyEmail)
+			public.GET("/revoke-sessions", userHandler.RevokeSessions)
 			public.POST("/resend-otp", userHandler.ResendOTP)
 			public.POST("/refresh-token", userHandler.RefreshToken)
 			public.POST("/google-oauth", userHandler.GoogleOAuth)
 			public.POST("/request-reset-password", userHandler.RequestResetPassword)
 			public.POST("/verify-reset-password", userHandler.VerifyResetPassword)
+
+			// Both checks share one per-IP budget
+			availabilityLimit := handlers.AvailabilityRateLimit()
+			public.GET("/check-username", availabilityLimit, userHandler.CheckUsername)
+			public.GET("/check-email", availabilityLimit, userHandler.CheckEmail)
 		}
 
 		// Protected routes (authentication required)
@@ -235,6 +405,10 @@ func setupRoutes() *gin.Engine {
 		{
 			protected.GET("/profile", userHandler.GetProfile)
 			protected.PUT("/profile", userHandler.UpdateProfile)
+			protected.POST("/change-password", userHandler.ChangePassword)
+			protected.POST("/phone", userHandler.RequestPhoneVerification)
+			protected.POST("/phone/verify", userHandler.VerifyPhone)
+			protected.DELETE("/phone", userHandler.RemovePhone)
 		}
 
 		// Public routes for other services (no authentication required)
@@ -243,17 +417,109 @@ func setupRoutes() *gin.Engine {
 			users.GET("/:id", userHandler.GetUserByID)
 		}
 	}
+	// Bounce and complaint webhooks of the email provider, authenticated with EMAIL_WEBHOOK_SECRET
+	webhookSecret := os.Getenv("EMAIL_WEBHOOK_SECRET")
+	emailSuppressionHandler := handlers.NewEmailSuppressionHandler(repository.NewEmailSuppressionRepository(DB), repository.NewUserRepository(DB), webhookSecret)
+	if webhookSecret != "" {
+		webhooks := api.Group("/webhooks")
+		webhooks.POST("/email/:provider", emailSuppressionHandler.ReceiveFeedback)
+	} else {
+		log.Println("⚠️ EMAIL_WEBHOOK_SECRET not set, bounce and complaint webhooks disabled")
+	}
+	api.Mount()
+
+	// Fixture accounts for frontend end to end tests (SANDBOX_TOOLS, never in production)
+	if sandboxToolsEnabled() {
+		r.POST("/api/v1/dev/fixtures", userHandler.CreateFixtures)
+		log.Printf("⚠️ Sandbox tools enabled, POST /api/v1/dev/fixtures creates fixture users (%s)", appEnv())
+	}
+
+	// Prometheus metrics, scraped with METRICS_TOKEN as bearer token
+	if metricsToken := os.Getenv("METRICS_TOKEN"); metricsToken != "" {
+		r.GET("/metrics", metricsAuthMiddleware(metricsToken), handlers.AuthMetricsPrometheus(AuthMetrics))
+	} else {
+		log.Println("⚠️ METRICS_TOKEN not set, /metrics disabled")
+	}
+
+	// Debug and runtime diagnostics endpoints (admin token required)
+	registerDebugRoutes(r)
+	admin := registerAdminRoutes(r, func() gin.H {
+		return gin.H{
+			"rabbitmq": gin.H{
+				"event_service_connected":  EventService != nil && EventService.IsConnected(),
+				"email_consumer_connected": EmailConsumer != nil && EmailConsumer.IsConnected(),
+			},
+			"account_cleanup":    AccountCleanup.Stats(),
+			"send_throttle":      userHandler.SendThrottleStats(),
+			"verification_guard": userHandler.VerificationGuardStats(),
+			"campaigns":          Campaigns.Stats(),
+		}
+	})
+	if admin != nil {
+		admin.GET("/users/:id", userHandler.GetUserAdmin)
+		admin.GET("/emails", userHandler.ListEmailLogs)
+		admin.POST("/emails/:id/resend", userHandler.ResendEmail)
+		admin.GET("/email-suppressions", emailSuppressionHandler.ListSuppressions)
+		admin.DELETE("/email-suppressions/:email", emailSuppressionHandler.RemoveSuppression)
+		admin.POST("/maintenance/account-cleanup", handlers.RunAccountCleanup(AccountCleanup))
+		admin.GET("/auth/metrics", handlers.AuthMetricsSummary(AuthMetrics))
+
+		campaignHandler := handlers.NewCampaignHandler(repository.NewCampaignRepository(DB))
+		admin.POST("/campaigns", campaignHandler.CreateCampaign)
+		admin.GET("/campaigns", campaignHandler.ListCampaigns)
+		admin.GET("/campaigns/:id", campaignHandler.GetCampaign)
+		admin.POST("/campaigns/:id/cancel", campaignHandler.CancelCampaign)
+	}
 
 	return r
 }
 
 func main() {
+	// -healthcheck probes the running service, for the compose healthcheck
+	healthcheck := flag.Bool("healthcheck", false, "check /health of the running service and exit")
+	flag.Parse()
+	if *healthcheck {
+		godotenv.Load()
+		if !runHealthcheck(servicePort()) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// check-config validates the environment and dependencies, for an init container or a
+	// pre-deploy gate
+	if flag.Arg(0) == "check-config" {
+		godotenv.Load()
+		if !runCheckConfig("User Service") {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// verify-topology compares the broker with the exchanges, queues and bindings the
+	// services expect
+	if flag.Arg(0) == "verify-topology" {
+		godotenv.Load()
+		if !runVerifyTopology("User Service") {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize all services
 	log.Println("🚀 Starting User Service...")
 
 	// Initialize database
 	initDB()
 
+	// Warn when queries wait for a pool connection (DB_SLOW_ACQUIRE_THRESHOLD)
+	poolMonitor := dbpool.NewMonitor(10 * time.Second)
+	poolMonitor.Start()
+	defer poolMonitor.Stop()
+
+	// Initialize Redis (optional)
+	initRedis()
+
 	// Initialize RabbitMQ
 	initRabbitMQ()
 
@@ -263,16 +529,20 @@ func main() {
 	// Initialize Checkout Consumer
 	initCheckoutConsumer()
 
+	// Initialize stale OTP / unverified account cleanup
+	initAccountCleanup()
+
+	// Initialize announcement campaign worker
+	initCampaigns()
+
 	// Setup routes
 	r := setupRoutes()
 
-	// Get port from environment
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8081"
-	}
+	// Get port and bind address from environment
+	port := servicePort()
+	addr := listenAddr(port)
 
-	log.Printf("🚀 User Service running on http://localhost:%s", port)
+	log.Printf("🚀 User Service running on http://localhost:%s (listening on %s)", port, addr)
 	log.Println("📚 API Documentation:")
 	log.Println("  POST /api/v1/auth/register     - Register new user")
 	log.Println("  POST /api/v1/auth/login        - Login user")
@@ -282,12 +552,25 @@ func main() {
 	log.Println("  POST /api/v1/auth/google-oauth - Google OAuth login")
 	log.Println("  POST /api/v1/auth/request-reset-password - Request password reset")
 	log.Println("  POST /api/v1/auth/verify-reset-password - Verify reset password")
+	log.Println("  GET  /api/v1/auth/check-username?u= - Check username availability")
+	log.Println("  GET  /api/v1/auth/check-email?e= - Check email availability")
 	log.Println("  GET  /api/v1/user/profile      - Get user profile (protected)")
 	log.Println("  PUT  /api/v1/user/profile      - Update user profile (protected)")
 	log.Println("  GET  /health                   - Health check")
+	log.Println("  GET  /health/redis             - Redis topology health (standalone, sentinel or cluster)")
+	log.Println("  GET  /metrics                  - Auth funnel and database pool metrics (METRICS_TOKEN)")
 
 	// Start server
-	if err := r.Run(":" + port); err != nil {
+	if err := r.Run(addr); err != nil {
 		log.Fatalf("❌ Failed to start server: %v", err)
 	}
 }
+
+// servicePort returns PORT, 8081 by default
+func servicePort() string {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8081"
+	}
+	return port
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// waitFor calls check until it succeeds or STARTUP_WAIT_TIMEOUT (default 60s) has passed,
+// STARTUP_RETRY_INTERVAL (default 2s) apart, so the service can start before its
+// dependencies are ready (docker compose, restarts of the whole stack). The last error is
+// returned once the wait is over.
+func waitFor(name string, check func() error) error {
+	timeout := startupDuration("STARTUP_WAIT_TIMEOUT", 60*time.Second)
+	interval := startupDuration("STARTUP_RETRY_INTERVAL", 2*time.Second)
+	deadline := time.Now().Add(timeout)
+
+	for attempt := 1; ; attempt++ {
+		err := check()
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("✅ %s ready after %d attempts", name, attempt)
+			}
+			return nil
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("%s not ready after %d attempts (%v): %w", name, attempt, timeout, err)
+		}
+
+		wait := interval
+		if wait > remaining {
+			wait = remaining
+		}
+		log.Printf("⏳ %s not ready (attempt %d), retrying in %v: %v", name, attempt, wait, err)
+		time.Sleep(wait)
+	}
+}
+
+// startupDuration reads a startup duration, exiting on invalid values
+func startupDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		log.Fatalf("❌ Invalid %s %q", key, value)
+	}
+	return parsed
+}
+
+// listenAddr is the address the server binds to: BIND_ADDR (all interfaces by default)
+// and port
+func listenAddr(port string) string {
+	return net.JoinHostPort(os.Getenv("BIND_ADDR"), port)
+}
+
+// runHealthcheck requests /health from the running server and reports whether it answered
+// 2xx. The images are distroless, without a shell or curl, so the compose healthcheck runs
+// the binary itself with -healthcheck.
+func runHealthcheck(port string) bool {
+	host := os.Getenv("BIND_ADDR")
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get("http://" + net.JoinHostPort(host, port) + "/health")
+	if err != nil {
+		log.Printf("❌ Health check failed: %v", err)
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Printf("❌ Health check returned status %d", resp.StatusCode)
+		return false
+	}
+	return true
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,7 +13,8 @@ import (
 
 // RedisService handles Redis operations
 type RedisService struct {
-	Client *redis.Client
+	Client   redis.UniversalClient
+	topology RedisTopology
 }
 
 // NewRedisService creates a new Redis service
@@ -24,39 +24,23 @@ func NewRedisService() (*RedisService, error) {
 		log.Println("⚠️ .env file not found in cache package, using system env")
 	}
 
-	// Get Redis configuration from environment
-	host := os.Getenv("REDIS_HOST")
-	if host == "" {
-		host = "localhost"
-	}
-
-	port := os.Getenv("REDIS_PORT")
-	if port == "" {
-		port = "6379"
-	}
-
-	password := os.Getenv("REDIS_PASSWORD")
-	db := 0
-	if dbStr := os.Getenv("REDIS_DB"); dbStr != "" {
-		if parsed, err := fmt.Sscanf(dbStr, "%d", &db); err != nil || parsed != 1 {
-			db = 0
-		}
+	// Get the Redis topology from environment (REDIS_MODE standalone, sentinel or cluster)
+	topology, err := RedisTopologyFromEnv()
+	if err != nil {
+		return nil, err
 	}
 
 	// Create Redis client
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", host, port),
-		Password: password,
-		DB:       db,
-	})
+	rdb := topology.NewClient()
 
 	// Test connection
 	ctx := context.Background()
 	if err := rdb.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		rdb.Close()
+		return nil, fmt.Errorf("failed to connect to Redis (%s): %w", topology, err)
 	}
 
-	return &RedisService{Client: rdb}, nil
+	return &RedisService{Client: rdb, topology: topology}, nil
 }
 
 // Set stores a key-value pair with expiration
@@ -149,12 +133,13 @@ func (rs *RedisService) GetRateLimit(ctx context.Context, key string) (int, erro
 	return count, nil
 }
 
-// IncrementRateLimit increments rate limit counter
+// IncrementRateLimit increments rate limit counter. The window starts with the first
+// increment and isn't extended by later ones.
 func (rs *RedisService) IncrementRateLimit(ctx context.Context, key string, window time.Duration) (int, error) {
 	pipe := rs.Client.Pipeline()
 	
 	incr := pipe.Incr(ctx, key)
-	pipe.Expire(ctx, key, window)
+	pipe.ExpireNX(ctx, key, window)
 	
 	_, err := pipe.Exec(ctx)
 	if err != nil {
@@ -164,6 +149,18 @@ func (rs *RedisService) IncrementRateLimit(ctx context.Context, key string, wind
 	return int(incr.Val()), nil
 }
 
+// RateLimitTTL returns how long until a rate limit counter resets
+func (rs *RedisService) RateLimitTTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := rs.Client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rate limit ttl: %w", err)
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
 // Close closes the Redis connection
 func (rs *RedisService) Close() error {
 	return rs.Client.Close()
@@ -9,119 +9,74 @@ import (
 	"user-service/internal/repository"
 
 	"github.com/google/uuid"
-	"github.com/streadway/amqp"
 )
 
 // CheckoutConsumer handles checkout-related events from RabbitMQ
 type CheckoutConsumer struct {
 	eventSvc *events.EventService
-	userRepo *repository.UserRepository
+	userRepo repository.UserStore
+	mode     events.ConsumerMode // CONSUMER_MODE_CHECKOUT
 }
 
 
 // NewCheckoutConsumer creates a new checkout consumer
-func NewCheckoutConsumer(eventSvc *events.EventService, userRepo *repository.UserRepository) *CheckoutConsumer {
+func NewCheckoutConsumer(eventSvc *events.EventService, userRepo repository.UserStore) *CheckoutConsumer {
 	return &CheckoutConsumer{
 		eventSvc: eventSvc,
 		userRepo: userRepo,
+		mode:     events.ConsumerModeFromEnv("checkout"),
 	}
 }
 
 // Start starts consuming checkout events
 func (cc *CheckoutConsumer) Start() error {
-	channel := cc.eventSvc.GetChannel()
-	
-	// Declare queue for checkout events
-	queueName := "user.checkout.queue"
-	_, err := channel.QueueDeclare(
-		queueName, // name
-		true,      // durable
-		false,     // delete when unused
-		false,     // exclusive
-		false,     // no-wait
-		nil,       // arguments
-	)
+	// Consume checkout.init events from Payment-Service
+	err := cc.eventSvc.Subscribe(cc.mode.Queue("user.checkout.queue"), []events.Binding{
+		{Exchange: "payment.events", RoutingKey: "checkout.init"},
+	}, cc.processMessage)
 	if err != nil {
-		return fmt.Errorf("failed to declare queue: %w", err)
+		return fmt.Errorf("failed to subscribe to checkout events: %w", err)
 	}
 
-	// Bind queue to payment.events exchange with checkout.init routing key
-	err = channel.QueueBind(
-		queueName,           // queue name
-		"checkout.init",     // routing key
-		"payment.events",    // exchange
-		false,               // no-wait
-		nil,                 // arguments
-	)
-	if err != nil {
-		return fmt.Errorf("failed to bind queue: %w", err)
-	}
-
-	// Set QoS to process one message at a time
-	err = channel.Qos(1, 0, false)
-	if err != nil {
-		return fmt.Errorf("failed to set QoS: %w", err)
-	}
-
-	// Start consuming messages
-	msgs, err := channel.Consume(
-		queueName, // queue
-		"",        // consumer
-		false,     // auto-ack
-		false,     // exclusive
-		false,     // no-local
-		false,     // no-wait
-		nil,       // args
-	)
-	if err != nil {
-		return fmt.Errorf("failed to register consumer: %w", err)
-	}
-
-	log.Println("🚀 User-Service checkout consumer started")
-
-	// Process messages in a goroutine
-	go func() {
-		for msg := range msgs {
-			cc.processMessage(msg)
-		}
-	}()
+	log.Printf("🚀 User-Service checkout consumer started (%s)", cc.mode)
 
 	return nil
 }
 
 // processMessage processes a single message
-func (cc *CheckoutConsumer) processMessage(msg amqp.Delivery) {
+func (cc *CheckoutConsumer) processMessage(msg events.Message) error {
 	log.Printf("📨 Received checkout event: %s", msg.RoutingKey)
 
 	// Parse the event
 	var event events.Event
 	if err := json.Unmarshal(msg.Body, &event); err != nil {
 		log.Printf("❌ Failed to unmarshal event: %v", err)
-		msg.Nack(false, false) // Reject message without requeue
-		return
+		return fmt.Errorf("%w: %v", events.ErrReject, err) // Reject message without requeue
 	}
 
+	effects := events.NewEffects("checkout", cc.mode, msg)
+	defer effects.Log()
+
 	// Handle different event types
 	switch event.Type {
 	case "checkout.init":
-		cc.handleCheckoutInit(event)
+		cc.handleCheckoutInit(event, effects)
 	default:
 		log.Printf("⚠️ Unknown event type: %s", event.Type)
 	}
 
-	// Acknowledge message
-	msg.Ack(false)
+	return nil
 }
 
 // handleCheckoutInit handles checkout initialization event
-func (cc *CheckoutConsumer) handleCheckoutInit(event events.Event) {
+func (cc *CheckoutConsumer) handleCheckoutInit(event events.Event, effects *events.Effects) {
 	log.Printf("🛒 Processing checkout init event for user validation")
 
 	// Parse checkout data
 	checkoutData, ok := event.Data.(map[string]interface{})
 	if !ok {
 		log.Printf("❌ Invalid checkout data format")
-		cc.sendValidationResponse("", "", "", "USER_INVALID", "Invalid checkout data format")
+		cc.sendValidationResponse(effects, "", "", "", "USER_INVALID", "Invalid checkout data format")
 		return
 	}
 
@@ -132,7 +87,7 @@ func (cc *CheckoutConsumer) handleCheckoutInit(event events.Event) {
 
 	if paymentID == "" || orderID == "" || userIDStr == "" {
 		log.Printf("❌ Missing required fields in checkout data")
-		cc.sendValidationResponse(paymentID, orderID, userIDStr, "USER_INVALID", "Missing required fields")
+		cc.sendValidationResponse(effects, paymentID, orderID, userIDStr, "USER_INVALID", "Missing required fields")
 		return
 	}
 
@@ -140,7 +95,7 @@ func (cc *CheckoutConsumer) handleCheckoutInit(event events.Event) {
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
 		log.Printf("❌ Invalid user ID: %v", err)
-		cc.sendValidationResponse(paymentID, orderID, userIDStr, "USER_INVALID", "Invalid user ID")
+		cc.sendValidationResponse(effects, paymentID, orderID, userIDStr, "USER_INVALID", "Invalid user ID")
 		return
 	}
 
@@ -148,7 +103,7 @@ func (cc *CheckoutConsumer) handleCheckoutInit(event events.Event) {
 	user, err := cc.userRepo.GetByID(userID)
 	if err != nil {
 		log.Printf("❌ Failed to get user: %v", err)
-		cc.sendValidationResponse(paymentID, orderID, userIDStr, "USER_INVALID", "User not found")
+		cc.sendValidationResponse(effects, paymentID, orderID, userIDStr, "USER_INVALID", "User not found")
 		return
 	}
 
@@ -157,17 +112,17 @@ func (cc *CheckoutConsumer) handleCheckoutInit(event events.Event) {
 	// For now, we'll assume all users in the database are valid
 	if user.ID == uuid.Nil {
 		log.Printf("❌ User is not valid: %s", userIDStr)
-		cc.sendValidationResponse(paymentID, orderID, userIDStr, "USER_INVALID", "User is not valid")
+		cc.sendValidationResponse(effects, paymentID, orderID, userIDStr, "USER_INVALID", "User is not valid")
 		return
 	}
 
 	// User validation successful
 	log.Printf("✅ User validation successful: %s", userIDStr)
-	cc.sendValidationResponse(paymentID, orderID, userIDStr, "USER_OK", "User validation successful")
+	cc.sendValidationResponse(effects, paymentID, orderID, userIDStr, "USER_OK", "User validation successful")
 }
 
 // sendValidationResponse sends validation response back to payment service
-func (cc *CheckoutConsumer) sendValidationResponse(paymentID, orderID, userID, status, message string) {
+func (cc *CheckoutConsumer) sendValidationResponse(effects *events.Effects, paymentID, orderID, userID, status, message string) {
 	response := events.UserValidationResponse{
 		PaymentID: paymentID,
 		OrderID:   orderID,
@@ -176,9 +131,12 @@ func (cc *CheckoutConsumer) sendValidationResponse(paymentID, orderID, userID, s
 		Message:   message,
 	}
 
-	if err := cc.eventSvc.PublishUserValidationResponse(response); err != nil {
+	err := effects.Apply(events.Change{Action: "publish", Target: "user.validation.response/" + paymentID, After: response}, func() error {
+		return cc.eventSvc.PublishUserValidationResponse(response)
+	})
+	if err != nil {
 		log.Printf("❌ Failed to publish validation response: %v", err)
-	} else {
+	} else if !effects.Shadow() {
 		log.Printf("📤 Published validation response: %s for user %s", status, userID)
 	}
 }
@@ -0,0 +1,148 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported EVENT_BUS transports
+const (
+	TransportRabbitMQ = "rabbitmq"
+	TransportKafka    = "kafka"
+)
+
+// ErrReject tells the bus to drop a message instead of redelivering it.
+// Handlers wrap it for messages that can never be processed (e.g. malformed JSON).
+var ErrReject = errors.New("message rejected")
+
+// Message is a transport independent event message.
+// Exchange maps to a RabbitMQ exchange or a Kafka topic.
+type Message struct {
+	ID         string
+	Exchange   string
+	RoutingKey string
+	// Key is the aggregate the message belongs to, e.g. an order, product or user ID. Kafka
+	// partitions by it so one aggregate's messages are consumed in publish order.
+	Key       string
+	Body      []byte
+	Headers   map[string]interface{}
+	Timestamp time.Time
+}
+
+// Binding subscribes a queue to a routing key pattern on an exchange.
+// Patterns follow RabbitMQ topic rules: "*" matches one word, "#" zero or more.
+type Binding struct {
+	Exchange   string
+	RoutingKey string
+}
+
+// Handler processes a consumed message. Returning nil acknowledges it,
+// an error wrapping ErrReject drops it and any other error redelivers it.
+type Handler func(msg Message) error
+
+// Publisher publishes messages to the bus
+type Publisher interface {
+	Publish(msg Message) error
+}
+
+// Consumer delivers messages bound to a named queue (a consumer group on Kafka), to
+// several handler calls at once when the subscription is configured so
+type Consumer interface {
+	Subscribe(queue string, bindings []Binding, handler Handler, opts ...SubscribeOption) error
+}
+
+// Bus is a message bus transport
+type Bus interface {
+	Publisher
+	Consumer
+	IsConnected() bool
+	HealthCheck() error
+	Close() error
+}
+
+// NewBus creates the transport selected by EVENT_BUS (rabbitmq by default) and declares the given exchanges
+func NewBus(exchanges []string) (Bus, error) {
+	transport, err := busTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	if transport == TransportKafka {
+		return newKafkaBus(exchanges)
+	}
+	return newRabbitMQBus(exchanges)
+}
+
+// Ping connects to the transport selected by EVENT_BUS and disconnects again, without
+// declaring exchanges, topics or queues
+func Ping() error {
+	transport, err := busTransport()
+	if err != nil {
+		return err
+	}
+
+	if transport == TransportKafka {
+		return pingKafka()
+	}
+	return pingRabbitMQ()
+}
+
+// busTransport returns the transport selected by EVENT_BUS
+func busTransport() (string, error) {
+	transport := strings.ToLower(os.Getenv("EVENT_BUS"))
+	switch transport {
+	case "", TransportRabbitMQ:
+		return TransportRabbitMQ, nil
+	case TransportKafka:
+		return TransportKafka, nil
+	default:
+		return "", fmt.Errorf("unsupported EVENT_BUS %q, expected %s or %s", transport, TransportRabbitMQ, TransportKafka)
+	}
+}
+
+// matchRoutingKey reports whether a routing key matches a topic binding pattern
+func matchRoutingKey(pattern, routingKey string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(routingKey, "."))
+}
+
+func matchWords(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(words); i++ {
+			if matchWords(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(words) > 0 && matchWords(pattern[1:], words[1:])
+	default:
+		return len(words) > 0 && pattern[0] == words[0] && matchWords(pattern[1:], words[1:])
+	}
+}
+
+// getEnvInt reads an integer environment variable with a default
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvBool reads a boolean environment variable with a default
+func getEnvBool(key string, defaultValue bool) bool {
+	if parsed, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return parsed
+	}
+	return defaultValue
+}
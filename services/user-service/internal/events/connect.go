@@ -0,0 +1,201 @@
+package events
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrNotConnected is returned when publishing while the bus is unavailable
+var ErrNotConnected = errors.New("event bus not connected")
+
+// maxRetryInterval caps the delay between connection attempts
+const maxRetryInterval = 30 * time.Second
+
+// subscription is a Subscribe call kept to be replayed after (re)connecting
+type subscription struct {
+	queue    string
+	bindings []Binding
+	handler  Handler
+	opts     []SubscribeOption
+}
+
+// ConnectBus connects to the bus selected by EVENT_BUS, retrying until the broker is ready
+// or EVENT_BUS_STARTUP_WAIT (default 30s) has passed, EVENT_BUS_RETRY_INTERVAL (default 2s)
+// apart and doubling. When the broker is still unavailable the returned bus is degraded: it
+// keeps connecting in the background, Publish returns ErrNotConnected and subscriptions
+// start once it is connected. Only an invalid EVENT_BUS is returned as an error.
+func ConnectBus(exchanges []string) (Bus, error) {
+	if _, err := busTransport(); err != nil {
+		return nil, err
+	}
+
+	maxWait := getEnvDuration("EVENT_BUS_STARTUP_WAIT", 30*time.Second)
+	interval := getEnvDuration("EVENT_BUS_RETRY_INTERVAL", 2*time.Second)
+	deadline := time.Now().Add(maxWait)
+
+	for attempt := 1; ; attempt++ {
+		bus, err := NewBus(exchanges)
+		if err == nil {
+			return bus, nil
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			log.Printf("⚠️ Event bus unavailable after %d attempts (%v), starting degraded and retrying in the background: %v", attempt, maxWait, err)
+			break
+		}
+
+		wait := interval
+		if wait > remaining {
+			wait = remaining
+		}
+		log.Printf("⏳ Event bus not ready (attempt %d), retrying in %v: %v", attempt, wait, err)
+		time.Sleep(wait)
+		interval = nextInterval(interval)
+	}
+
+	degraded := &degradedBus{
+		exchanges: exchanges,
+		interval:  interval,
+		done:      make(chan struct{}),
+	}
+	go degraded.connect()
+	return degraded, nil
+}
+
+// degradedBus stands in for a bus whose broker was unavailable at startup
+type degradedBus struct {
+	exchanges []string
+	interval  time.Duration
+	done      chan struct{}
+
+	mu            sync.RWMutex
+	bus           Bus
+	subscriptions []subscription
+}
+
+// connect retries until the broker is reachable, then starts the subscriptions made meanwhile
+func (db *degradedBus) connect() {
+	interval := db.interval
+	for {
+		select {
+		case <-db.done:
+			return
+		case <-time.After(interval):
+		}
+
+		bus, err := NewBus(db.exchanges)
+		if err != nil {
+			log.Printf("⏳ Event bus still unavailable, retrying in %v: %v", interval, err)
+			interval = nextInterval(interval)
+			continue
+		}
+
+		db.mu.Lock()
+		select {
+		case <-db.done:
+			db.mu.Unlock()
+			bus.Close() // closed while connecting
+			return
+		default:
+		}
+		db.bus = bus
+		subscriptions := db.subscriptions
+		db.subscriptions = nil
+		db.mu.Unlock()
+
+		log.Println("✅ Event bus connected, leaving degraded mode")
+		for _, sub := range subscriptions {
+			if err := bus.Subscribe(sub.queue, sub.bindings, sub.handler, sub.opts...); err != nil {
+				log.Printf("❌ Failed to start subscription %s after connecting: %v", sub.queue, err)
+			} else {
+				log.Printf("✅ Subscription %s started", sub.queue)
+			}
+		}
+		return
+	}
+}
+
+// current returns the connected bus, nil while degraded
+func (db *degradedBus) current() Bus {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.bus
+}
+
+// Publish publishes through the connected bus or returns ErrNotConnected
+func (db *degradedBus) Publish(msg Message) error {
+	bus := db.current()
+	if bus == nil {
+		return ErrNotConnected
+	}
+	return bus.Publish(msg)
+}
+
+// Subscribe subscribes on the connected bus or keeps the subscription until connected
+func (db *degradedBus) Subscribe(queue string, bindings []Binding, handler Handler, opts ...SubscribeOption) error {
+	db.mu.Lock()
+	if db.bus == nil {
+		db.subscriptions = append(db.subscriptions, subscription{queue: queue, bindings: bindings, handler: handler, opts: opts})
+		db.mu.Unlock()
+		log.Printf("⏳ Subscription %s will start once the event bus is connected", queue)
+		return nil
+	}
+	bus := db.bus
+	db.mu.Unlock()
+
+	return bus.Subscribe(queue, bindings, handler, opts...)
+}
+
+// IsConnected reports whether the bus has connected since startup and is still open
+func (db *degradedBus) IsConnected() bool {
+	bus := db.current()
+	return bus != nil && bus.IsConnected()
+}
+
+// HealthCheck checks the connected bus
+func (db *degradedBus) HealthCheck() error {
+	bus := db.current()
+	if bus == nil {
+		return ErrNotConnected
+	}
+	return bus.HealthCheck()
+}
+
+// Close stops connecting and closes the bus if it connected
+func (db *degradedBus) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	select {
+	case <-db.done:
+	default:
+		close(db.done)
+	}
+	if db.bus != nil {
+		return db.bus.Close()
+	}
+	return nil
+}
+
+// nextInterval doubles a retry interval up to maxRetryInterval
+func nextInterval(interval time.Duration) time.Duration {
+	interval *= 2
+	if interval > maxRetryInterval {
+		return maxRetryInterval
+	}
+	return interval
+}
+
+// getEnvDuration reads a duration environment variable with a default
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			return parsed
+		}
+		log.Printf("⚠️ Invalid %s %q, using %v", key, value, defaultValue)
+	}
+	return defaultValue
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"log"
+	"time"
+)
+
+// OutboxStore keeps events that couldn't be published until the bus is available again
+type OutboxStore interface {
+	Save(msg Message) error
+	Pending(limit int) ([]Message, error)
+	Delete(id string) error
+	Failed(id string, publishErr error) error
+}
+
+// outboxBatchSize is how many queued events a relay run publishes at most
+const outboxBatchSize = 100
+
+// SetOutbox queues events that fail to publish in store instead of dropping them
+func (es *EventService) SetOutbox(store OutboxStore) {
+	es.outbox = store
+}
+
+// StartOutboxRelay publishes queued events every interval while the bus is connected,
+// oldest first
+func (es *EventService) StartOutboxRelay(interval time.Duration) {
+	if es.outbox == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if es.bus.IsConnected() {
+				es.relayOutbox()
+			}
+		}
+	}()
+	log.Printf("✅ Event outbox relay started (every %v)", interval)
+}
+
+// relayOutbox publishes one batch of queued events, stopping at the first failure
+func (es *EventService) relayOutbox() {
+	pending, err := es.outbox.Pending(outboxBatchSize)
+	if err != nil {
+		log.Printf("❌ Failed to read event outbox: %v", err)
+		return
+	}
+
+	relayed := 0
+	for _, msg := range pending {
+		// The outbox doesn't keep the key, it comes from the event as when it was published
+		msg.Key = dataField(msg.Body, aggregateKeyField)
+		if err := es.bus.Publish(msg); err != nil {
+			if markErr := es.outbox.Failed(msg.ID, err); markErr != nil {
+				log.Printf("❌ Failed to record outbox attempt for %s: %v", msg.ID, markErr)
+			}
+			log.Printf("⚠️ Event outbox relay stopped after %d events: %v", relayed, err)
+			return
+		}
+		if err := es.outbox.Delete(msg.ID); err != nil {
+			log.Printf("❌ Failed to remove relayed event %s from outbox: %v", msg.ID, err)
+			return
+		}
+		relayed++
+	}
+
+	if relayed > 0 {
+		log.Printf("📤 Relayed %d queued events from the outbox", relayed)
+	}
+}
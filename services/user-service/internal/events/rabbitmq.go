@@ -4,16 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"os"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
-	"github.com/streadway/amqp"
 )
 
-// EventService handles RabbitMQ event publishing
+// EventService handles event publishing and consuming on the configured bus
 type EventService struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
+	bus    Bus
+	outbox OutboxStore // Events that failed to publish, nil to return the error instead
 }
 
 // Event represents a generic event structure
@@ -29,6 +29,7 @@ type UserRegisteredEvent struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Locale   string `json:"locale,omitempty"`
 }
 
 // UserVerifiedEvent represents user verification event
@@ -36,13 +37,32 @@ type UserVerifiedEvent struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Locale   string `json:"locale,omitempty"`
+}
+
+// UserUpdatedEvent represents a change to a user's public profile
+type UserUpdatedEvent struct {
+	UserID      string `json:"user_id"`
+	Username    string `json:"username"`
+	Email       string `json:"email"`
+	Locale      string `json:"locale,omitempty"`
+	PhoneNumber string `json:"phone_number"` // Verified phone number, empty when removed
+}
+
+// UserDeletedEvent tells other services to forget a deleted user's contact data
+type UserDeletedEvent struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"` // e.g. "unverified" for accounts removed by the cleanup worker
 }
 
 // UserLoginEvent represents user login event
 type UserLoginEvent struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Method    string `json:"method"` // credential or google
+	IPAddress string `json:"ip_address"`
+	LoginAt   string `json:"login_at"` // RFC3339
 }
 
 // PasswordResetEvent represents password reset event
@@ -50,6 +70,7 @@ type PasswordResetEvent struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Locale   string `json:"locale,omitempty"`
 }
 
 // PasswordResetSuccessEvent represents password reset success event
@@ -57,81 +78,38 @@ type PasswordResetSuccessEvent struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Locale   string `json:"locale,omitempty"`
 }
 
-// NewEventService creates a new event service
+// NewEventService creates a new event service. It waits for the broker as described in
+// ConnectBus and runs degraded when it isn't ready, so it only fails on an invalid EVENT_BUS.
 func NewEventService() (*EventService, error) {
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
 		log.Println("⚠️ .env file not found in events package, using system env")
 	}
 
-	// Get RabbitMQ configuration from environment
-	host := os.Getenv("RABBITMQ_HOST")
-	if host == "" {
-		host = "localhost"
-	}
-
-	port := os.Getenv("RABBITMQ_PORT")
-	if port == "" {
-		port = "5672"
-	}
-
-	username := os.Getenv("RABBITMQ_USERNAME")
-	if username == "" {
-		username = "admin"
-	}
-
-	password := os.Getenv("RABBITMQ_PASSWORD")
-	if password == "" {
-		password = "secret123"
-	}
-
-	// Create connection URL
-	url := fmt.Sprintf("amqp://%s:%s@%s:%s/", username, password, host, port)
-
-	// Connect to RabbitMQ
-	conn, err := amqp.Dial(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
-	}
-
-	// Create channel
-	ch, err := conn.Channel()
+	// Connect to the configured transport and declare exchanges, including the ones
+	// consumed from Payment-Service
+	bus, err := ConnectBus([]string{"user.events", "payment.events"})
 	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to open channel: %w", err)
-	}
-
-	// Declare exchanges
-	if err := ch.ExchangeDeclare(
-		"user.events", // name
-		"topic",       // type
-		true,          // durable
-		false,         // auto-deleted
-		false,         // internal
-		false,         // no-wait
-		nil,           // arguments
-	); err != nil {
-		ch.Close()
-		conn.Close()
-		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+		return nil, err
 	}
 
 	return &EventService{
-		conn:    conn,
-		channel: ch,
+		bus: bus,
 	}, nil
 }
 
 // PublishUserRegistered publishes user registration event
-func (es *EventService) PublishUserRegistered(userID, username, email string) error {
+func (es *EventService) PublishUserRegistered(userID, username, email, locale string) error {
 	event := Event{
 		Type: "user.registered",
 		Data: UserRegisteredEvent{
 			UserID:   userID,
 			Username: username,
 			Email:    email,
+			Locale:   locale,
 		},
 	}
 
@@ -139,27 +117,60 @@ func (es *EventService) PublishUserRegistered(userID, username, email string) er
 }
 
 // PublishUserVerified publishes user verification event
-func (es *EventService) PublishUserVerified(userID, username, email string) error {
+func (es *EventService) PublishUserVerified(userID, username, email, locale string) error {
 	event := Event{
 		Type: "user.verified",
 		Data: UserVerifiedEvent{
 			UserID:   userID,
 			Username: username,
 			Email:    email,
+			Locale:   locale,
 		},
 	}
 
 	return es.publishEvent("user.verified", event)
 }
 
-// PublishUserLogin publishes user login event
-func (es *EventService) PublishUserLogin(userID, username, email string) error {
+// PublishUserUpdated publishes user updated event so other services can refresh their read models
+func (es *EventService) PublishUserUpdated(userID, username, email, locale, phoneNumber string) error {
+	event := Event{
+		Type: "user.updated",
+		Data: UserUpdatedEvent{
+			UserID:      userID,
+			Username:    username,
+			Email:       email,
+			Locale:      locale,
+			PhoneNumber: phoneNumber,
+		},
+	}
+
+	return es.publishEvent("user.updated", event)
+}
+
+// PublishUserDeleted publishes user deleted event so other services anonymize their read models
+func (es *EventService) PublishUserDeleted(userID, reason string) error {
+	event := Event{
+		Type: "user.deleted",
+		Data: UserDeletedEvent{
+			UserID: userID,
+			Reason: reason,
+		},
+	}
+
+	return es.publishEvent("user.deleted", event)
+}
+
+// PublishUserLogin publishes user login event for every successful login
+func (es *EventService) PublishUserLogin(userID, username, email, method, ipAddress string, loginAt time.Time) error {
 	event := Event{
 		Type: "user.login",
 		Data: UserLoginEvent{
-			UserID:   userID,
-			Username: username,
-			Email:    email,
+			UserID:    userID,
+			Username:  username,
+			Email:     email,
+			Method:    method,
+			IPAddress: ipAddress,
+			LoginAt:   loginAt.Format(time.RFC3339),
 		},
 	}
 
@@ -167,13 +178,14 @@ func (es *EventService) PublishUserLogin(userID, username, email string) error {
 }
 
 // PublishPasswordReset publishes password reset event
-func (es *EventService) PublishPasswordReset(userID, username, email string) error {
+func (es *EventService) PublishPasswordReset(userID, username, email, locale string) error {
 	event := Event{
 		Type: "password.reset",
 		Data: PasswordResetEvent{
 			UserID:   userID,
 			Username: username,
 			Email:    email,
+			Locale:   locale,
 		},
 	}
 
@@ -181,19 +193,128 @@ func (es *EventService) PublishPasswordReset(userID, username, email string) err
 }
 
 // PublishPasswordResetSuccess publishes password reset success event
-func (es *EventService) PublishPasswordResetSuccess(userID, username, email string) error {
+func (es *EventService) PublishPasswordResetSuccess(userID, username, email, locale string) error {
 	event := Event{
 		Type: "password.reset.success",
 		Data: PasswordResetSuccessEvent{
 			UserID:   userID,
 			Username: username,
 			Email:    email,
+			Locale:   locale,
 		},
 	}
 
 	return es.publishEvent("password.reset.success", event)
 }
 
+// VerificationReminderEvent asks for the final reminder to an unverified account
+type VerificationReminderEvent struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Locale   string `json:"locale,omitempty"`
+	Deadline string `json:"deadline"` // RFC3339 time after which the account is removed
+}
+
+// PublishVerificationReminder publishes the final verification reminder event
+func (es *EventService) PublishVerificationReminder(userID, username, email, locale string, deadline time.Time) error {
+	event := Event{
+		Type: "user.verification.reminder",
+		Data: VerificationReminderEvent{
+			UserID:   userID,
+			Username: username,
+			Email:    email,
+			Locale:   locale,
+			Deadline: deadline.Format(time.RFC3339),
+		},
+	}
+
+	return es.publishEvent("user.verification.reminder", event)
+}
+
+// LoginNewDeviceEvent represents a login from a device/network the user hasn't used before
+type LoginNewDeviceEvent struct {
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Locale    string `json:"locale,omitempty"`
+	IPAddress string `json:"ip_address"`
+	UserAgent string `json:"user_agent"`
+	LoginAt   string `json:"login_at"` // RFC3339
+}
+
+// PublishLoginNewDevice publishes a new device login event for the security alert email
+func (es *EventService) PublishLoginNewDevice(userID, username, email, locale, ipAddress, userAgent string, loginAt time.Time) error {
+	event := Event{
+		Type:   "user.login.new_device",
+		UserID: userID,
+		Data: LoginNewDeviceEvent{
+			UserID:    userID,
+			Username:  username,
+			Email:     email,
+			Locale:    locale,
+			IPAddress: ipAddress,
+			UserAgent: userAgent,
+			LoginAt:   loginAt.Format(time.RFC3339),
+		},
+	}
+
+	return es.publishEvent("user.login.new_device", event)
+}
+
+// RefreshTokenMismatchEvent is a security event for a refresh token presented by another client
+// than the one it was issued to, which usually means the token was stolen
+type RefreshTokenMismatchEvent struct {
+	UserID     string `json:"user_id"`
+	Email      string `json:"email"`
+	IPAddress  string `json:"ip_address"`
+	UserAgent  string `json:"user_agent"`
+	RejectedAt string `json:"rejected_at"` // RFC3339
+}
+
+// PublishRefreshTokenMismatch publishes a security event for a rejected refresh attempt
+func (es *EventService) PublishRefreshTokenMismatch(userID, email, ipAddress, userAgent string, rejectedAt time.Time) error {
+	event := Event{
+		Type:   "user.security.refresh_token_mismatch",
+		UserID: userID,
+		Data: RefreshTokenMismatchEvent{
+			UserID:     userID,
+			Email:      email,
+			IPAddress:  ipAddress,
+			UserAgent:  userAgent,
+			RejectedAt: rejectedAt.Format(time.RFC3339),
+		},
+	}
+
+	return es.publishEvent("user.security.refresh_token_mismatch", event)
+}
+
+// CampaignEmailEvent asks the email consumer to send one recipient a campaign email
+type CampaignEmailEvent struct {
+	CampaignID string `json:"campaign_id"`
+	UserID     string `json:"user_id"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	Locale     string `json:"locale,omitempty"`
+}
+
+// PublishCampaignEmail queues a campaign email for one recipient
+func (es *EventService) PublishCampaignEmail(campaignID, userID, username, email, locale string) error {
+	event := Event{
+		Type:   "user.campaign.email",
+		UserID: userID,
+		Data: CampaignEmailEvent{
+			CampaignID: campaignID,
+			UserID:     userID,
+			Username:   username,
+			Email:      email,
+			Locale:     locale,
+		},
+	}
+
+	return es.publishEvent("user.campaign.email", event)
+}
+
 // UserValidationResponse represents user validation response
 type UserValidationResponse struct {
 	PaymentID string `json:"payment_id"`
@@ -214,6 +335,10 @@ func (es *EventService) PublishUserValidationResponse(response UserValidationRes
 	return es.publishEvent("user.validation.response", event)
 }
 
+// aggregateKeyField is the event data field user events are keyed by on the bus, the
+// events of one user stay in order on Kafka
+const aggregateKeyField = "user_id"
+
 // publishEvent publishes a generic event
 func (es *EventService) publishEvent(routingKey string, event Event) error {
 	// Marshal event to JSON
@@ -223,62 +348,52 @@ func (es *EventService) publishEvent(routingKey string, event Event) error {
 	}
 
 	// Publish message
-	err = es.channel.Publish(
-		"user.events", // exchange
-		routingKey,    // routing key
-		false,         // mandatory
-		false,         // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		},
-	)
+	msg := Message{
+		ID:         uuid.New().String(),
+		Exchange:   "user.events",
+		RoutingKey: routingKey,
+		Key:        dataField(body, aggregateKeyField),
+		Body:       body,
+		Timestamp:  time.Now(),
+	}
+	err = es.bus.Publish(msg)
 
 	if err != nil {
+		// Keep the event for the outbox relay while the bus is unavailable
+		if es.outbox != nil {
+			saveErr := es.outbox.Save(msg)
+			if saveErr == nil {
+				log.Printf("📥 Event %s queued in outbox: %v", routingKey, err)
+				return nil
+			}
+			log.Printf("❌ Failed to queue event %s in outbox: %v", routingKey, saveErr)
+		}
 		return fmt.Errorf("failed to publish event: %w", err)
 	}
 
 	return nil
 }
 
-// Close closes the RabbitMQ connection
-func (es *EventService) Close() error {
-	if es.channel != nil {
-		es.channel.Close()
-	}
-	if es.conn != nil {
-		return es.conn.Close()
+// Subscribe consumes the given bindings from a durable queue (a consumer group on Kafka),
+// warning when they drifted from Topology
+func (es *EventService) Subscribe(queue string, bindings []Binding, handler Handler, opts ...SubscribeOption) error {
+	for _, drift := range Topology.CheckSubscription(queue, bindings) {
+		log.Printf("⚠️ Topology drift: %s", drift)
 	}
-	return nil
+	return es.bus.Subscribe(queue, bindings, handler, opts...)
 }
 
-// GetChannel returns the RabbitMQ channel for consumers
-func (es *EventService) GetChannel() *amqp.Channel {
-	return es.channel
+// Close closes the bus connection
+func (es *EventService) Close() error {
+	return es.bus.Close()
 }
 
-// HealthCheck checks if RabbitMQ connection is healthy
-func (es *EventService) HealthCheck() error {
-	if es.conn == nil || es.channel == nil {
-		return fmt.Errorf("RabbitMQ connection not initialized")
-	}
-
-	// Try to declare a temporary queue to test connection
-	_, err := es.channel.QueueDeclare(
-		"health_check", // name
-		false,          // durable
-		true,           // delete when unused
-		true,           // exclusive
-		false,          // no-wait
-		nil,            // arguments
-	)
-
-	if err != nil {
-		return fmt.Errorf("RabbitMQ health check failed: %w", err)
-	}
-
-	// Clean up the temporary queue
-	es.channel.QueueDelete("health_check", false, false, false)
+// IsConnected reports whether the bus connection is open
+func (es *EventService) IsConnected() bool {
+	return es.bus.IsConnected()
+}
 
-	return nil
+// HealthCheck checks if the bus connection is healthy
+func (es *EventService) HealthCheck() error {
+	return es.bus.HealthCheck()
 }
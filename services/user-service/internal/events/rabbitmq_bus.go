@@ -0,0 +1,360 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// rabbitMQBus implements Bus on RabbitMQ topic exchanges. A dropped connection is
+// re-established in the background and its subscriptions are started again.
+type rabbitMQBus struct {
+	url       string
+	exchanges []string
+
+	mu            sync.RWMutex
+	conn          *amqp.Connection
+	channel       *amqp.Channel
+	subscriptions []subscription
+	closed        bool
+}
+
+// newRabbitMQBus connects to RabbitMQ and declares the topic exchanges
+func newRabbitMQBus(exchanges []string) (*rabbitMQBus, error) {
+	rb := &rabbitMQBus{
+		url:       rabbitMQURL(),
+		exchanges: exchanges,
+	}
+
+	conn, ch, err := rb.dial()
+	if err != nil {
+		return nil, err
+	}
+	rb.conn = conn
+	rb.channel = ch
+	go rb.watch(conn)
+
+	log.Println("✅ Connected to RabbitMQ successfully")
+
+	return rb, nil
+}
+
+// rabbitMQURL builds the broker URL from the RABBITMQ_* settings
+func rabbitMQURL() string {
+	// Get RabbitMQ configuration from environment
+	host := os.Getenv("RABBITMQ_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+
+	port := os.Getenv("RABBITMQ_PORT")
+	if port == "" {
+		port = "5672"
+	}
+
+	username := os.Getenv("RABBITMQ_USERNAME")
+	if username == "" {
+		username = "admin"
+	}
+
+	password := os.Getenv("RABBITMQ_PASSWORD")
+	if password == "" {
+		password = "secret123"
+	}
+	return fmt.Sprintf("amqp://%s:%s@%s:%s/", username, password, host, port)
+}
+
+// pingRabbitMQ connects to the broker and disconnects again, declaring nothing
+func pingRabbitMQ() error {
+	conn, err := amqp.Dial(rabbitMQURL())
+	if err != nil {
+		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+	return conn.Close()
+}
+
+// dial connects to RabbitMQ, opens the publishing channel and declares the exchanges and
+// the topology
+func (rb *rabbitMQBus) dial() (*amqp.Connection, *amqp.Channel, error) {
+	// Connect to RabbitMQ
+	conn, err := amqp.Dial(rb.url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	// Create channel
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	// Declare exchanges
+	for _, exchange := range rb.exchanges {
+		if err := ch.ExchangeDeclare(
+			exchange, // name
+			"topic",  // type
+			true,     // durable
+			false,    // auto-deleted
+			false,    // internal
+			false,    // no-wait
+			nil,      // arguments
+		); err != nil {
+			ch.Close()
+			conn.Close()
+			return nil, nil, fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
+		}
+	}
+
+	// Declare the queues of every service, so nothing published is lost before they start
+	if err := declareTopology(ch); err != nil {
+		ch.Close()
+		conn.Close()
+		return nil, nil, err
+	}
+
+	return conn, ch, nil
+}
+
+// watch waits for the connection to drop and reconnects until Close is called
+func (rb *rabbitMQBus) watch(conn *amqp.Connection) {
+	reason, ok := <-conn.NotifyClose(make(chan *amqp.Error, 1))
+	if !ok || reason == nil {
+		return // closed by Close
+	}
+	log.Printf("⚠️ RabbitMQ connection lost, reconnecting: %v", reason)
+
+	interval := getEnvDuration("EVENT_BUS_RETRY_INTERVAL", 2*time.Second)
+	for {
+		time.Sleep(interval)
+
+		if rb.isClosed() {
+			return
+		}
+		conn, ch, err := rb.dial()
+		if err != nil {
+			log.Printf("⏳ RabbitMQ still unavailable, retrying in %v: %v", interval, err)
+			interval = nextInterval(interval)
+			continue
+		}
+
+		rb.mu.Lock()
+		if rb.closed {
+			rb.mu.Unlock()
+			conn.Close()
+			return
+		}
+		rb.conn = conn
+		rb.channel = ch
+		subscriptions := rb.subscriptions
+		rb.mu.Unlock()
+
+		log.Println("✅ Reconnected to RabbitMQ")
+		for _, sub := range subscriptions {
+			if err := rb.consume(conn, sub); err != nil {
+				log.Printf("❌ Failed to restart subscription %s after reconnecting: %v", sub.queue, err)
+			}
+		}
+
+		go rb.watch(conn)
+		return
+	}
+}
+
+// isClosed reports whether Close was called
+func (rb *rabbitMQBus) isClosed() bool {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+	return rb.closed
+}
+
+// Publish publishes a message to its exchange with its routing key
+func (rb *rabbitMQBus) Publish(msg Message) error {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+
+	if rb.conn == nil || rb.conn.IsClosed() {
+		return ErrNotConnected
+	}
+
+	return rb.channel.Publish(
+		msg.Exchange,   // exchange
+		msg.RoutingKey, // routing key
+		false,          // mandatory
+		false,          // immediate
+		amqp.Publishing{
+			ContentType: "application/json",
+			MessageId:   msg.ID,
+			Body:        msg.Body,
+			Timestamp:   msg.Timestamp,
+			Headers:     amqp.Table(msg.Headers),
+		},
+	)
+}
+
+// Subscribe declares a durable queue (auto-deleted for shadow consumers), binds it and
+// consumes it on a dedicated channel. The subscription is started again after a reconnect.
+func (rb *rabbitMQBus) Subscribe(queue string, bindings []Binding, handler Handler, opts ...SubscribeOption) error {
+	sub := subscription{queue: queue, bindings: bindings, handler: handler, opts: opts}
+
+	rb.mu.Lock()
+	conn := rb.conn
+	rb.subscriptions = append(rb.subscriptions, sub)
+	rb.mu.Unlock()
+
+	if conn.IsClosed() {
+		log.Printf("⏳ Subscription %s will start once RabbitMQ is reconnected", queue)
+		return nil
+	}
+	return rb.consume(conn, sub)
+}
+
+// consume declares, binds and consumes a subscription's queue on the given connection
+func (rb *rabbitMQBus) consume(conn *amqp.Connection, sub subscription) error {
+	queue, bindings, handler := sub.queue, sub.bindings, sub.handler
+	cfg := newSubscribeConfig(queue, sub.opts)
+
+	// A channel per consumer keeps QoS settings independent
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	// Shadow queues only matter while a shadow consumer reads them
+	shadow := strings.HasSuffix(queue, ShadowQueueSuffix)
+	_, err = ch.QueueDeclare(
+		queue,   // name
+		!shadow, // durable
+		shadow,  // delete when unused
+		false,   // exclusive
+		false,   // no-wait
+		nil,     // arguments
+	)
+	if err != nil {
+		ch.Close()
+		return fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	for _, binding := range bindings {
+		err = ch.QueueBind(
+			queue,              // queue name
+			binding.RoutingKey, // routing key
+			binding.Exchange,   // exchange
+			false,              // no-wait
+			nil,                // arguments
+		)
+		if err != nil {
+			ch.Close()
+			return fmt.Errorf("failed to bind queue to %s on %s: %w", binding.RoutingKey, binding.Exchange, err)
+		}
+	}
+
+	// Prefetch bounds the unacknowledged deliveries in flight, one by default
+	if err := ch.Qos(cfg.prefetch, 0, false); err != nil {
+		ch.Close()
+		return fmt.Errorf("failed to set QoS: %w", err)
+	}
+
+	msgs, err := ch.Consume(
+		queue, // queue
+		"",    // consumer
+		false, // auto-ack
+		false, // exclusive
+		false, // no-local
+		false, // no-wait
+		nil,   // args
+	)
+	if err != nil {
+		ch.Close()
+		return fmt.Errorf("failed to register consumer: %w", err)
+	}
+
+	// Deliveries are handled by cfg.concurrency workers, acknowledged by the worker handling
+	// them; the channel stops delivering once cfg.prefetch of them are unacknowledged
+	go func() {
+		workers := newDispatcher(cfg.concurrency, cfg.orderKey)
+		defer workers.close()
+
+		for delivery := range msgs {
+			msg := Message{
+				ID:         delivery.MessageId,
+				Exchange:   delivery.Exchange,
+				RoutingKey: delivery.RoutingKey,
+				Body:       delivery.Body,
+				Headers:    delivery.Headers,
+				Timestamp:  delivery.Timestamp,
+			}
+
+			workers.dispatch(msg, func() {
+				err := handler(msg)
+				switch {
+				case err == nil:
+					delivery.Ack(false)
+				case errors.Is(err, ErrReject):
+					delivery.Nack(false, false) // Reject message without requeue
+				default:
+					delivery.Nack(false, true) // Requeue for another attempt
+				}
+			})
+		}
+	}()
+
+	return nil
+}
+
+// IsConnected reports whether the RabbitMQ connection is open
+func (rb *rabbitMQBus) IsConnected() bool {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+	return rb.conn != nil && !rb.conn.IsClosed()
+}
+
+// HealthCheck checks if RabbitMQ connection is healthy
+func (rb *rabbitMQBus) HealthCheck() error {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+
+	if rb.conn == nil || rb.channel == nil {
+		return fmt.Errorf("RabbitMQ connection not initialized")
+	}
+
+	// Try to declare a temporary queue to test connection
+	_, err := rb.channel.QueueDeclare(
+		"health_check", // name
+		false,          // durable
+		true,           // delete when unused
+		true,           // exclusive
+		false,          // no-wait
+		nil,            // arguments
+	)
+
+	if err != nil {
+		return fmt.Errorf("RabbitMQ health check failed: %w", err)
+	}
+
+	// Clean up the temporary queue
+	rb.channel.QueueDelete("health_check", false, false, false)
+
+	return nil
+}
+
+// Close closes the RabbitMQ connection and stops reconnecting
+func (rb *rabbitMQBus) Close() error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	rb.closed = true
+	if rb.channel != nil {
+		rb.channel.Close()
+	}
+	if rb.conn != nil {
+		return rb.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// TopologyReport lists where the broker differs from Topology. Problems lose or misroute
+// messages, warnings are worth a look.
+type TopologyReport struct {
+	Problems []string
+	Warnings []string
+}
+
+func (r *TopologyReport) problem(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+func (r *TopologyReport) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// declareTopology declares the exchanges and durable queues of Topology with their
+// bindings, the same way Subscribe declares them, unless RABBITMQ_DECLARE_TOPOLOGY is false.
+// Declaring is idempotent, but fails when a queue exists with other arguments.
+func declareTopology(ch *amqp.Channel) error {
+	if !getEnvBool("RABBITMQ_DECLARE_TOPOLOGY", true) {
+		return nil
+	}
+
+	for _, exchange := range Topology.Exchanges {
+		if err := ch.ExchangeDeclare(exchange, "topic", true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
+		}
+	}
+	for _, queue := range Topology.Queues {
+		if _, err := ch.QueueDeclare(queue.Name, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare queue %s: %w", queue.Name, err)
+		}
+		for _, binding := range queue.Bindings {
+			if err := ch.QueueBind(queue.Name, binding.RoutingKey, binding.Exchange, false, nil); err != nil {
+				return fmt.Errorf("failed to bind queue %s to %s on %s: %w", queue.Name, binding.RoutingKey, binding.Exchange, err)
+			}
+		}
+	}
+	return nil
+}
+
+// VerifyTopology compares the broker selected by EVENT_BUS with Topology without declaring
+// anything. Exchanges and queues are checked passively over AMQP; bindings can't be read
+// over AMQP, so they are compared through the management API at RABBITMQ_MANAGEMENT_URL
+// (default http://RABBITMQ_HOST:15672). Kafka has no bindings, only Topology is linted.
+func VerifyTopology() (*TopologyReport, error) {
+	report := &TopologyReport{Warnings: Topology.Lint()}
+
+	transport := strings.ToLower(os.Getenv("EVENT_BUS"))
+	switch transport {
+	case "", TransportRabbitMQ:
+	case TransportKafka:
+		report.warn("EVENT_BUS is kafka, topics and consumer groups are not verified")
+		return report, nil
+	default:
+		return nil, fmt.Errorf("unsupported EVENT_BUS %q, expected %s or %s", transport, TransportRabbitMQ, TransportKafka)
+	}
+
+	conn, err := amqp.Dial(rabbitMQURL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+	defer conn.Close()
+
+	// A failed passive declare closes the channel, so each check gets a fresh one
+	passive := func(check func(ch *amqp.Channel) error) error {
+		ch, err := conn.Channel()
+		if err != nil {
+			return err
+		}
+		defer ch.Close()
+		return check(ch)
+	}
+
+	for _, exchange := range Topology.Exchanges {
+		err := passive(func(ch *amqp.Channel) error {
+			return ch.ExchangeDeclarePassive(exchange, "topic", true, false, false, false, nil)
+		})
+		if err != nil {
+			report.problem("exchange %s: %v", exchange, err)
+		}
+	}
+
+	management := newManagementClient()
+	for _, queue := range Topology.Queues {
+		var state amqp.Queue
+		err := passive(func(ch *amqp.Channel) error {
+			var err error
+			state, err = ch.QueueDeclarePassive(queue.Name, true, false, false, false, nil)
+			return err
+		})
+		if err != nil {
+			report.problem("queue %s (consumed by %s): %v", queue.Name, queue.Consumer, err)
+			continue
+		}
+		if state.Consumers == 0 {
+			report.warn("queue %s has no consumers, %s is not running (%d messages waiting)", queue.Name, queue.Consumer, state.Messages)
+		}
+
+		bindings, err := management.queueBindings(queue.Name)
+		if err != nil {
+			report.warn("bindings of queue %s not verified: %v", queue.Name, err)
+			continue
+		}
+		for _, binding := range queue.Bindings {
+			if !containsBinding(bindings, binding) {
+				report.problem("queue %s is not bound to %s on %s", queue.Name, binding.RoutingKey, binding.Exchange)
+			}
+		}
+		for _, binding := range bindings {
+			if !containsBinding(queue.Bindings, binding) {
+				report.problem("queue %s is bound to %s on %s, which the topology doesn't list", queue.Name, binding.RoutingKey, binding.Exchange)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// managementClient reads bindings from the RabbitMQ management API
+type managementClient struct {
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+}
+
+func newManagementClient() *managementClient {
+	mc := &managementClient{
+		baseURL: strings.TrimSuffix(os.Getenv("RABBITMQ_MANAGEMENT_URL"), "/"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+	if broker, err := url.Parse(rabbitMQURL()); err == nil {
+		if mc.baseURL == "" {
+			mc.baseURL = "http://" + broker.Hostname() + ":15672"
+		}
+		mc.username = broker.User.Username()
+		mc.password, _ = broker.User.Password()
+	}
+	return mc
+}
+
+// queueBindings returns the exchange bindings of a queue on the default vhost, leaving out
+// the implicit binding to the default exchange
+func (mc *managementClient) queueBindings(queue string) ([]Binding, error) {
+	req, err := http.NewRequest(http.MethodGet, mc.baseURL+"/api/queues/%2F/"+url.PathEscape(queue)+"/bindings", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(mc.username, mc.password)
+
+	resp, err := mc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("management API unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("management API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var rows []struct {
+		Source     string `json:"source"`
+		RoutingKey string `json:"routing_key"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("failed to decode bindings: %w", err)
+	}
+
+	var bindings []Binding
+	for _, row := range rows {
+		if row.Source == "" {
+			continue
+		}
+		bindings = append(bindings, Binding{Exchange: row.Source, RoutingKey: row.RoutingKey})
+	}
+	return bindings, nil
+}
@@ -0,0 +1,197 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QueueTopology is a durable queue with the service consuming it and its bindings
+type QueueTopology struct {
+	Name     string
+	Consumer string
+	Bindings []Binding
+}
+
+// Route is a routing key a service publishes on an exchange
+type Route struct {
+	Exchange   string
+	RoutingKey string
+	Publisher  string
+}
+
+// TopologySpec describes the exchanges, the routes published on them and the queues
+// consuming them
+type TopologySpec struct {
+	Exchanges []string
+	Routes    []Route
+	Queues    []QueueTopology
+}
+
+// Topology is the message topology of all services. This file is identical in every
+// service: each declares the whole topology when it connects, so events published before
+// their consumer first starts are queued instead of dropped, and verify-topology compares
+// it with the broker. Change it in every service at once.
+var Topology = TopologySpec{
+	Exchanges: []string{"payment.events", "product.events", "user.events", "notification.events"},
+	Routes: []Route{
+		{Exchange: "payment.events", RoutingKey: "payment.created", Publisher: "payment-service"},
+		{Exchange: "payment.events", RoutingKey: "payment.status.updated", Publisher: "payment-service"},
+		{Exchange: "payment.events", RoutingKey: "payment.success", Publisher: "payment-service"},
+		{Exchange: "payment.events", RoutingKey: "payment.failed", Publisher: "payment-service"},
+		{Exchange: "payment.events", RoutingKey: "payment.refunded", Publisher: "payment-service"},
+		{Exchange: "payment.events", RoutingKey: "payment.invoice.reminder", Publisher: "payment-service"},
+		{Exchange: "payment.events", RoutingKey: "checkout.init", Publisher: "payment-service"},
+		{Exchange: "payment.events", RoutingKey: "order.completed", Publisher: "payment-service"},
+		{Exchange: "payment.events", RoutingKey: "order.failed", Publisher: "payment-service"},
+		{Exchange: "product.events", RoutingKey: "product.stock.reduced", Publisher: "payment-service"},
+		{Exchange: "product.events", RoutingKey: "product.validation.response", Publisher: "product-service"},
+		{Exchange: "product.events", RoutingKey: "product.stock.reduced", Publisher: "product-service"},
+		{Exchange: "product.events", RoutingKey: "product.updated", Publisher: "product-service"},
+		{Exchange: "user.events", RoutingKey: "user.registered", Publisher: "user-service"},
+		{Exchange: "user.events", RoutingKey: "user.verified", Publisher: "user-service"},
+		{Exchange: "user.events", RoutingKey: "user.updated", Publisher: "user-service"},
+		{Exchange: "user.events", RoutingKey: "user.deleted", Publisher: "user-service"},
+		{Exchange: "user.events", RoutingKey: "user.login", Publisher: "user-service"},
+		{Exchange: "user.events", RoutingKey: "password.reset", Publisher: "user-service"},
+		{Exchange: "user.events", RoutingKey: "password.reset.success", Publisher: "user-service"},
+		{Exchange: "user.events", RoutingKey: "user.verification.reminder", Publisher: "user-service"},
+		{Exchange: "user.events", RoutingKey: "user.login.new_device", Publisher: "user-service"},
+		{Exchange: "user.events", RoutingKey: "user.security.refresh_token_mismatch", Publisher: "user-service"},
+		{Exchange: "user.events", RoutingKey: "user.campaign.email", Publisher: "user-service"},
+		{Exchange: "user.events", RoutingKey: "user.validation.response", Publisher: "user-service"},
+	},
+	Queues: []QueueTopology{
+		{Name: "payment.validation.queue", Consumer: "payment-service", Bindings: []Binding{
+			{Exchange: "product.events", RoutingKey: "product.validation.response"},
+			{Exchange: "user.events", RoutingKey: "user.validation.response"},
+		}},
+		{Name: "payment.user_profile.queue", Consumer: "payment-service", Bindings: []Binding{
+			{Exchange: "user.events", RoutingKey: "user.registered"},
+			{Exchange: "user.events", RoutingKey: "user.verified"},
+			{Exchange: "user.events", RoutingKey: "user.updated"},
+			{Exchange: "user.events", RoutingKey: "user.deleted"},
+		}},
+		{Name: "payment.product_cache.queue", Consumer: "payment-service", Bindings: []Binding{
+			{Exchange: "product.events", RoutingKey: "product.updated"},
+			{Exchange: "product.events", RoutingKey: "product.stock.reduced"},
+		}},
+		{Name: "payment.webhook.queue", Consumer: "payment-service", Bindings: []Binding{
+			{Exchange: "payment.events", RoutingKey: "payment.success"},
+			{Exchange: "payment.events", RoutingKey: "payment.failed"},
+			{Exchange: "payment.events", RoutingKey: "payment.refunded"},
+		}},
+		{Name: "product.checkout.queue", Consumer: "product-service", Bindings: []Binding{
+			{Exchange: "payment.events", RoutingKey: "checkout.init"},
+		}},
+		{Name: "product.stock.queue", Consumer: "product-service", Bindings: []Binding{
+			{Exchange: "product.events", RoutingKey: "product.stock.reduced"},
+		}},
+		{Name: "product.stock_release.queue", Consumer: "product-service", Bindings: []Binding{
+			{Exchange: "payment.events", RoutingKey: "payment.failed"},
+			{Exchange: "payment.events", RoutingKey: "payment.expired"},
+		}},
+		{Name: "product.funnel.queue", Consumer: "product-service", Bindings: []Binding{
+			{Exchange: "payment.events", RoutingKey: "checkout.init"},
+			{Exchange: "payment.events", RoutingKey: "payment.success"},
+		}},
+		{Name: "product.user_profile.queue", Consumer: "product-service", Bindings: []Binding{
+			{Exchange: "user.events", RoutingKey: "user.registered"},
+			{Exchange: "user.events", RoutingKey: "user.verified"},
+			{Exchange: "user.events", RoutingKey: "user.updated"},
+		}},
+		{Name: "user.checkout.queue", Consumer: "user-service", Bindings: []Binding{
+			{Exchange: "payment.events", RoutingKey: "checkout.init"},
+		}},
+		{Name: "email_queue", Consumer: "user-service", Bindings: []Binding{
+			{Exchange: "user.events", RoutingKey: "user.registered"},
+			{Exchange: "user.events", RoutingKey: "user.verified"},
+			{Exchange: "user.events", RoutingKey: "password.reset"},
+			{Exchange: "user.events", RoutingKey: "password.reset.success"},
+			{Exchange: "user.events", RoutingKey: "user.verification.reminder"},
+			{Exchange: "user.events", RoutingKey: "user.login.new_device"},
+			{Exchange: "user.events", RoutingKey: "user.campaign.email"},
+			{Exchange: "payment.events", RoutingKey: "payment.success"},
+			{Exchange: "payment.events", RoutingKey: "payment.invoice.reminder"},
+		}},
+	},
+}
+
+// Queue returns the queue named name; shadow queues share the topology of their queue
+func (t TopologySpec) Queue(name string) (QueueTopology, bool) {
+	name = strings.TrimSuffix(name, ShadowQueueSuffix)
+	for _, queue := range t.Queues {
+		if queue.Name == name {
+			return queue, true
+		}
+	}
+	return QueueTopology{}, false
+}
+
+// CheckSubscription compares the bindings a consumer subscribes queue with against the
+// topology, describing each difference
+func (t TopologySpec) CheckSubscription(queue string, bindings []Binding) []string {
+	expected, ok := t.Queue(queue)
+	if !ok {
+		return []string{fmt.Sprintf("queue %s is not in the topology", queue)}
+	}
+
+	var problems []string
+	for _, binding := range bindings {
+		if !containsBinding(expected.Bindings, binding) {
+			problems = append(problems, fmt.Sprintf("queue %s binds %s on %s, which the topology doesn't", queue, binding.RoutingKey, binding.Exchange))
+		}
+	}
+	for _, binding := range expected.Bindings {
+		if !containsBinding(bindings, binding) {
+			problems = append(problems, fmt.Sprintf("queue %s doesn't bind %s on %s as the topology does", queue, binding.RoutingKey, binding.Exchange))
+		}
+	}
+	return problems
+}
+
+// Lint reports bindings that can't receive anything: on an exchange outside the topology or
+// matching no route published on it. That is how a consumer drifting from its publisher
+// looks, e.g. a queue bound on user.events to a response published on another exchange.
+func (t TopologySpec) Lint() []string {
+	var problems []string
+	for _, queue := range t.Queues {
+		for _, binding := range queue.Bindings {
+			if !t.hasExchange(binding.Exchange) {
+				problems = append(problems, fmt.Sprintf("queue %s binds to exchange %s, which is not declared", queue.Name, binding.Exchange))
+				continue
+			}
+			if !t.published(binding) {
+				problems = append(problems, fmt.Sprintf("queue %s binds %s on %s, which no service publishes", queue.Name, binding.RoutingKey, binding.Exchange))
+			}
+		}
+	}
+	return problems
+}
+
+func (t TopologySpec) hasExchange(name string) bool {
+	for _, exchange := range t.Exchanges {
+		if exchange == name {
+			return true
+		}
+	}
+	return false
+}
+
+// published reports whether any route on the binding's exchange matches its pattern
+func (t TopologySpec) published(binding Binding) bool {
+	for _, route := range t.Routes {
+		if route.Exchange == binding.Exchange && matchRoutingKey(binding.RoutingKey, route.RoutingKey) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsBinding(bindings []Binding, binding Binding) bool {
+	for _, b := range bindings {
+		if b == binding {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetUserAdmin returns a user's profile and account state, including the last login and whether
+// their address is on the email suppression list (admin only)
+func (uh *UserHandler) GetUserAdmin(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_USER_ID")
+		return
+	}
+
+	user, err := uh.userRepo.GetByID(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "USER_NOT_FOUND")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	response := user.ToAdminResponse()
+	response.EmailSuppression = emailSuppression(uh.suppressionRepo, user.Email)
+	response.EmailUndeliverable = response.EmailSuppression != nil
+
+	c.JSON(http.StatusOK, gin.H{"user": response})
+}
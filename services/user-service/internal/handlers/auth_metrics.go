@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"user-service/internal/dbpool"
+	"user-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthMetricsSummary returns the registration → verification funnel counters and rates since
+// start (admin only)
+func AuthMetricsSummary(metrics *services.AuthMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"metrics": metrics.Summary(),
+		})
+	}
+}
+
+// AuthMetricsPrometheus writes the funnel counters and the database pool stats in the
+// Prometheus text format
+func AuthMetricsPrometheus(metrics *services.AuthMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(metrics.Prometheus()+dbpool.Prometheus()))
+	}
+}
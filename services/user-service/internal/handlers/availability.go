@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CheckUsername handles GET /api/v1/auth/check-username?u= so registration forms can
+// validate a username before submitting. Policy violations are reported as unavailable.
+func (uh *UserHandler) CheckUsername(c *gin.Context) {
+	username := uh.usernamePolicy.Normalize(c.Query("u"))
+	if username == "" {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST")
+		return
+	}
+
+	if violations := uh.usernamePolicy.Validate(username); len(violations) > 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"username":  username,
+			"available": false,
+			"code":      "INVALID_USERNAME",
+			"message":   localize(c, "INVALID_USERNAME"),
+			"details":   usernameViolationDetails(c, violations),
+		})
+		return
+	}
+
+	taken, err := uh.userRepo.IsUsernameTaken(username, uuid.Nil)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	code := "USERNAME_AVAILABLE"
+	if taken {
+		code = "USERNAME_TAKEN"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"username":  username,
+		"available": !taken,
+		"code":      code,
+		"message":   localize(c, code),
+	})
+}
+
+// CheckEmail handles GET /api/v1/auth/check-email?e=, reporting whether an email can still
+// be registered
+func (uh *UserHandler) CheckEmail(c *gin.Context) {
+	email := strings.ToLower(strings.TrimSpace(c.Query("e")))
+	if err := uh.validator.Var(email, "required,email,max=150"); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_EMAIL")
+		return
+	}
+
+	registered, err := uh.userRepo.IsEmailRegistered(email)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	code := "EMAIL_AVAILABLE"
+	if registered {
+		code = "EMAIL_TAKEN"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"email":     email,
+		"available": !registered,
+		"code":      code,
+		"message":   localize(c, code),
+	})
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"user-service/internal/models"
+	"user-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CampaignHandler handles the admin announcement campaign endpoints. Creating a campaign only
+// stores it, the campaign worker queues its emails in throttled batches.
+type CampaignHandler struct {
+	campaignRepo repository.CampaignStore
+	validator    *validator.Validate
+}
+
+// NewCampaignHandler creates a new campaign handler
+func NewCampaignHandler(campaignRepo repository.CampaignStore) *CampaignHandler {
+	return &CampaignHandler{
+		campaignRepo: campaignRepo,
+		validator:    validator.New(),
+	}
+}
+
+// CreateCampaign starts an announcement campaign to a user segment, ?dry_run=true only counts
+// the recipients (admin only)
+func (h *CampaignHandler) CreateCampaign(c *gin.Context) {
+	var req models.CreateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondErrorWithFields(c, http.StatusBadRequest, "INVALID_REQUEST_BODY", gin.H{"details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.BuyersFrom != nil && req.BuyersTo != nil && !req.BuyersTo.After(*req.BuyersFrom) {
+		respondError(c, http.StatusBadRequest, "INVALID_BUYERS_RANGE")
+		return
+	}
+
+	campaign := &models.EmailCampaign{
+		Subject: req.Subject,
+		Body:    req.Body,
+		Segment: req.Segment,
+		Status:  models.CampaignStatusSending,
+	}
+	if req.Segment == models.CampaignSegmentBuyers {
+		campaign.BuyersFrom = req.BuyersFrom
+		campaign.BuyersTo = req.BuyersTo
+	}
+
+	recipients, err := h.campaignRepo.CountRecipients(campaign)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	if dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false")); dryRun {
+		c.JSON(http.StatusOK, gin.H{
+			"message":    "Dry run, campaign not created",
+			"recipients": recipients,
+		})
+		return
+	}
+
+	if err := h.campaignRepo.Create(campaign); err != nil {
+		respondError(c, http.StatusInternalServerError, "CAMPAIGN_CREATE_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":    "Campaign created, emails are queued in batches",
+		"campaign":   campaign,
+		"recipients": recipients,
+	})
+}
+
+// ListCampaigns returns the latest campaigns, newest first (admin only)
+func (h *CampaignHandler) ListCampaigns(c *gin.Context) {
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= 200 {
+			limit = parsed
+		}
+	}
+
+	campaigns, err := h.campaignRepo.List(limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"campaigns": campaigns,
+		"count":     len(campaigns),
+	})
+}
+
+// GetCampaign returns a campaign with its delivery counts (admin only)
+func (h *CampaignHandler) GetCampaign(c *gin.Context) {
+	campaignID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_CAMPAIGN_ID")
+		return
+	}
+
+	campaign, err := h.campaignRepo.GetByID(campaignID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "CAMPAIGN_NOT_FOUND")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	deliveries, err := h.campaignRepo.DeliveryCounts(campaignID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"campaign":   campaign,
+		"deliveries": deliveries,
+	})
+}
+
+// CancelCampaign stops a campaign that is still sending, queued emails are skipped by the
+// email consumer (admin only)
+func (h *CampaignHandler) CancelCampaign(c *gin.Context) {
+	campaignID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_CAMPAIGN_ID")
+		return
+	}
+
+	cancelled, err := h.campaignRepo.Cancel(campaignID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+	if !cancelled {
+		respondError(c, http.StatusConflict, "CAMPAIGN_NOT_SENDING")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Campaign cancelled"})
+}
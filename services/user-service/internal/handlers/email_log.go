@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"user-service/internal/models"
+	"user-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ListEmailLogs returns the email delivery history, optionally filtered by user_id or recipient (admin only)
+func (uh *UserHandler) ListEmailLogs(c *gin.Context) {
+	var filter repository.EmailLogFilter
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_USER_ID")
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	filter.Recipient = c.Query("recipient")
+	filter.Status = c.Query("status")
+
+	filter.Limit = 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= 200 {
+			filter.Limit = parsed
+		}
+	}
+
+	logs, err := uh.emailLogRepo.List(filter)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"emails": logs,
+		"count":  len(logs),
+	})
+}
+
+// ResendEmail re-publishes the event behind a logged email so the consumer sends it again (admin only)
+func (uh *UserHandler) ResendEmail(c *gin.Context) {
+	logID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_EMAIL_LOG_ID")
+		return
+	}
+
+	emailLog, err := uh.emailLogRepo.GetByID(logID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "EMAIL_LOG_NOT_FOUND")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	if emailLog.UserID == nil {
+		respondError(c, http.StatusUnprocessableEntity, "EMAIL_LOG_WITHOUT_USER")
+		return
+	}
+
+	user, err := uh.userRepo.GetByID(*emailLog.UserID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "USER_NOT_FOUND")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	if suppression := emailSuppression(uh.suppressionRepo, user.Email); suppression != nil {
+		respondErrorWithFields(c, http.StatusConflict, "EMAIL_SUPPRESSED", gin.H{"reason": suppression.Reason})
+		return
+	}
+
+	if uh.eventService == nil {
+		respondError(c, http.StatusServiceUnavailable, "EVENT_SERVICE_UNAVAILABLE")
+		return
+	}
+
+	// OTP based emails read the current code from the database, make sure one still exists
+	switch emailLog.Type {
+	case models.EmailTypeOTP:
+		if user.IsVerified {
+			respondError(c, http.StatusConflict, "USER_ALREADY_VERIFIED")
+			return
+		}
+		fallthrough
+	case models.EmailTypePasswordReset:
+		if user.OTPCode == nil {
+			respondError(c, http.StatusConflict, "NO_ACTIVE_CODE")
+			return
+		}
+	}
+
+	userID := user.ID.String()
+	switch emailLog.Type {
+	case models.EmailTypeOTP:
+		err = uh.eventService.PublishUserRegistered(userID, user.Username, user.Email, user.Locale)
+	case models.EmailTypeWelcome:
+		err = uh.eventService.PublishUserVerified(userID, user.Username, user.Email, user.Locale)
+	case models.EmailTypePasswordReset:
+		err = uh.eventService.PublishPasswordReset(userID, user.Username, user.Email, user.Locale)
+	case models.EmailTypePasswordResetSuccess:
+		err = uh.eventService.PublishPasswordResetSuccess(userID, user.Username, user.Email, user.Locale)
+	default:
+		respondErrorWithFields(c, http.StatusUnprocessableEntity, "UNSUPPORTED_EMAIL_TYPE", gin.H{"email_type": emailLog.Type})
+		return
+	}
+
+	if err != nil {
+		respondErrorWithFields(c, http.StatusInternalServerError, "EMAIL_QUEUE_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Email queued for resend",
+		"type":    emailLog.Type,
+		"to":      user.Email,
+	})
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"user-service/internal/models"
+	"user-service/internal/repository"
+	"user-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxEmailWebhookBody caps a provider post; SendGrid batches events but stays far below it
+const maxEmailWebhookBody = 1 << 20
+
+// EmailSuppressionHandler receives the email provider's bounce and complaint webhooks and
+// serves the suppression list to support
+type EmailSuppressionHandler struct {
+	suppressionRepo repository.EmailSuppressionStore
+	userRepo        repository.UserStore
+	webhookSecret   string
+}
+
+// NewEmailSuppressionHandler creates a new email suppression handler. Webhook posts must
+// carry webhookSecret as the HTTP basic auth password or the token query parameter.
+func NewEmailSuppressionHandler(suppressionRepo repository.EmailSuppressionStore, userRepo repository.UserStore, webhookSecret string) *EmailSuppressionHandler {
+	return &EmailSuppressionHandler{
+		suppressionRepo: suppressionRepo,
+		userRepo:        userRepo,
+		webhookSecret:   webhookSecret,
+	}
+}
+
+// ReceiveFeedback handles POST /api/v1/webhooks/email/:provider (ses or sendgrid): every
+// permanently bouncing or complaining recipient is added to the suppression list
+func (h *EmailSuppressionHandler) ReceiveFeedback(c *gin.Context) {
+	provided := c.Query("token")
+	if _, password, ok := c.Request.BasicAuth(); ok {
+		provided = password
+	}
+	if provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(h.webhookSecret)) != 1 {
+		respondError(c, http.StatusUnauthorized, "INVALID_WEBHOOK_CREDENTIALS")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxEmailWebhookBody))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "REQUEST_BODY_READ_FAILED")
+		return
+	}
+
+	provider := c.Param("provider")
+	var feedback []services.EmailFeedback
+	switch provider {
+	case services.EmailProviderSES:
+		var subscribeURL string
+		feedback, subscribeURL, err = services.ParseSESNotification(body)
+		if err == nil && subscribeURL != "" {
+			if err := services.ConfirmSNSSubscription(c.Request.Context(), subscribeURL); err != nil {
+				log.Printf("❌ SNS subscription not confirmed: %v", err)
+				respondErrorWithFields(c, http.StatusBadRequest, "SUBSCRIPTION_CONFIRM_FAILED", gin.H{"details": err.Error()})
+				return
+			}
+			log.Println("✅ SNS subscription for SES notifications confirmed")
+			c.JSON(http.StatusOK, gin.H{"message": "Subscription confirmed"})
+			return
+		}
+	case services.EmailProviderSendGrid:
+		feedback, err = services.ParseSendGridEvents(body)
+	default:
+		respondErrorWithFields(c, http.StatusNotFound, "UNSUPPORTED_EMAIL_PROVIDER", gin.H{"provider": provider})
+		return
+	}
+	if err != nil {
+		respondErrorWithFields(c, http.StatusBadRequest, "INVALID_WEBHOOK_PAYLOAD", gin.H{"details": err.Error()})
+		return
+	}
+
+	suppressed := 0
+	for _, entry := range feedback {
+		if entry.Email == "" {
+			continue
+		}
+		suppression := &models.EmailSuppression{
+			Email:    entry.Email,
+			Reason:   entry.Reason,
+			Provider: provider,
+		}
+		if entry.Detail != "" {
+			suppression.Detail = &entry.Detail
+		}
+		if entry.MessageID != "" {
+			suppression.ProviderMessageID = &entry.MessageID
+		}
+		if user, err := h.userRepo.GetByEmail(entry.Email); err == nil {
+			suppression.UserID = &user.ID
+		}
+
+		// The provider retries failed posts, so a failure fails the whole post
+		if err := h.suppressionRepo.Suppress(suppression); err != nil {
+			respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+			return
+		}
+		log.Printf("🚫 %s suppressed after a %s reported by %s", suppression.Email, suppression.Reason, provider)
+		suppressed++
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suppressed": suppressed})
+}
+
+// ListSuppressions returns the suppression list, optionally filtered by reason (admin only)
+func (h *EmailSuppressionHandler) ListSuppressions(c *gin.Context) {
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= 200 {
+			limit = parsed
+		}
+	}
+
+	suppressions, err := h.suppressionRepo.List(c.Query("reason"), limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"suppressions": suppressions,
+		"count":        len(suppressions),
+	})
+}
+
+// RemoveSuppression takes an address off the suppression list once support confirmed it can
+// receive email again (admin only)
+func (h *EmailSuppressionHandler) RemoveSuppression(c *gin.Context) {
+	email := c.Param("email")
+	removed, err := h.suppressionRepo.Remove(email)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+	if !removed {
+		respondError(c, http.StatusNotFound, "EMAIL_NOT_SUPPRESSED")
+		return
+	}
+
+	log.Printf("✅ %s removed from the email suppression list", models.NormalizeSuppressedEmail(email))
+	c.JSON(http.StatusOK, gin.H{"message": "Email removed from the suppression list"})
+}
+
+// emailSuppression looks up the suppression of a user's address for admin views, nil when
+// it isn't suppressed or the lookup failed
+func emailSuppression(store repository.EmailSuppressionStore, email string) *models.EmailSuppression {
+	suppression, err := store.GetByEmail(email)
+	if err != nil {
+		log.Printf("⚠️ Failed to check the email suppression of %s: %v", email, err)
+		return nil
+	}
+	return suppression
+}
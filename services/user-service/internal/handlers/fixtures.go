@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"user-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Fixture accounts for frontend end to end tests. Their IDs are fixed so the fixtures of the
+// other services (the seller's store, the buyer's payment) can refer to them.
+var (
+	FixtureBuyerID  = uuid.MustParse("00000000-0000-4000-8000-000000000001")
+	FixtureSellerID = uuid.MustParse("00000000-0000-4000-8000-000000000002")
+)
+
+// FixturePassword is the password of both fixture accounts
+const FixturePassword = "Fixture123!"
+
+// CreateFixtures handles POST /api/v1/dev/fixtures (sandbox only). It creates a verified
+// buyer and seller, or resets them to their initial state when they already exist, and
+// publishes user.updated so the other services' read models know them.
+func (uh *UserHandler) CreateFixtures(c *gin.Context) {
+	passwordHash, err := uh.passwordService.HashPassword(FixturePassword)
+	if err != nil {
+		respondErrorWithFields(c, http.StatusInternalServerError, "FIXTURES_CREATE_FAILED", gin.H{"details": err.Error()})
+		return
+	}
+
+	fixtures := []models.User{
+		{ID: FixtureBuyerID, Username: "e2e_buyer", Email: "e2e-buyer@example.com"},
+		{ID: FixtureSellerID, Username: "e2e_seller", Email: "e2e-seller@example.com"},
+	}
+	for i := range fixtures {
+		user := &fixtures[i]
+		user.PasswordHash = passwordHash
+		user.Type = "credential"
+		user.IsVerified = true
+		user.Locale = "id"
+		user.LoginAlerts = true
+		user.MarketingEmails = true
+
+		existing, err := uh.userRepo.GetByID(user.ID)
+		switch {
+		case err == nil:
+			// Save overwrites every column, clearing OTPs, phone numbers and revocations
+			user.CreatedAt = existing.CreatedAt
+			err = uh.userRepo.Update(user)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			err = uh.userRepo.Create(user)
+		}
+		if err != nil {
+			respondErrorWithFields(c, http.StatusInternalServerError, "FIXTURES_CREATE_FAILED", gin.H{"details": err.Error()})
+			return
+		}
+
+		if uh.eventService != nil {
+			if err := uh.eventService.PublishUserUpdated(user.ID.String(), user.Username, user.Email, user.Locale, ""); err != nil {
+				log.Printf("⚠️ Failed to publish user updated event for fixture %s: %v", user.Username, err)
+			}
+		}
+	}
+
+	log.Printf("🧪 Fixture users %s and %s ready", fixtures[0].Username, fixtures[1].Username)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"password": FixturePassword,
+			"buyer":    fixtures[0].ToResponse(),
+			"seller":   fixtures[1].ToResponse(),
+		},
+	})
+}
@@ -1,16 +1,20 @@
 package handlers
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"user-service/internal/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 )
 
@@ -19,6 +23,9 @@ type JWTService struct {
 	secretKey          string
 	accessTokenExpiry  time.Duration
 	refreshTokenExpiry time.Duration
+	keys               map[string]*signingKey // Asymmetric keys by kid (RS256/ES256 mode)
+	activeKey          *signingKey            // Key used to sign new tokens, nil in HS256 mode
+	parser             *jwt.Parser
 }
 
 // NewJWTService creates a new JWT service
@@ -28,11 +35,6 @@ func NewJWTService() *JWTService {
 		log.Println("⚠️ .env file not found in handlers package, using system env")
 	}
 
-	secretKey := os.Getenv("JWT_SECRET")
-	if secretKey == "" {
-		secretKey = "your-secret-key" // Default for development
-	}
-
 	accessExpiry := 15 * time.Minute
 	if exp := os.Getenv("JWT_ACCESS_EXPIRY"); exp != "" {
 		if parsed, err := time.ParseDuration(exp); err == nil {
@@ -47,11 +49,129 @@ func NewJWTService() *JWTService {
 		}
 	}
 
-	return &JWTService{
-		secretKey:          secretKey,
+	// Leeway for exp, nbf and iat, so a validating host whose clock runs slightly ahead or
+	// behind doesn't reject fresh tokens
+	clockSkew := 30 * time.Second
+	if skew := os.Getenv("JWT_CLOCK_SKEW"); skew != "" {
+		if parsed, err := time.ParseDuration(skew); err == nil && parsed >= 0 {
+			clockSkew = parsed
+		}
+	}
+
+	js := &JWTService{
 		accessTokenExpiry:  accessExpiry,
 		refreshTokenExpiry: refreshExpiry,
+		parser: jwt.NewParser(
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+			jwt.WithLeeway(clockSkew),
+		),
+	}
+
+	if !js.useSigningKeys() {
+		js.secretKey = hs256Secret()
+	}
+	return js
+}
+
+// useSigningKeys enables asymmetric signing (RS256/ES256) when a key directory is configured,
+// otherwise HS256 with JWT_SECRET stays as the development fallback. A configured but unusable
+// key directory only falls back in development, elsewhere tokens would silently be signed with
+// the shared secret the deployment meant to retire
+func (js *JWTService) useSigningKeys() bool {
+	keysDir := os.Getenv("JWT_KEYS_DIR")
+	if keysDir == "" {
+		log.Println("⚠️ JWT_KEYS_DIR not set, signing tokens with HS256 shared secret")
+		return false
+	}
+
+	keys, err := loadSigningKeys(keysDir)
+	if err != nil {
+		if appEnv() != "development" {
+			log.Fatalf("❌ Failed to load JWT signing keys from %s: %v", keysDir, err)
+		}
+		log.Printf("❌ Failed to load JWT signing keys, falling back to HS256: %v", err)
+		return false
 	}
+
+	activeKid := os.Getenv("JWT_ACTIVE_KID")
+	activeKey, ok := keys[activeKid]
+	if !ok {
+		if appEnv() != "development" {
+			log.Fatalf("❌ Active JWT key %q not found in %s, set JWT_ACTIVE_KID to one of its keys", activeKid, keysDir)
+		}
+		log.Printf("❌ Active JWT key %q not found in %s, falling back to HS256", activeKid, keysDir)
+		return false
+	}
+
+	js.keys = keys
+	js.activeKey = activeKey
+	log.Printf("✅ JWT signing with %s (kid=%s, %d keys published)", activeKey.method.Alg(), activeKid, len(keys))
+	return true
+}
+
+// exampleJWTSecret is the JWT_SECRET of env.example, only accepted in development
+const exampleJWTSecret = "your-super-secret-jwt-key-change-this-in-production"
+
+// hs256Secret returns the JWT_SECRET that signs HS256 tokens. A default secret would be public
+// and let anyone sign tokens for any user, so outside development an unset or example secret
+// is fatal; development without JWT_SECRET signs with a random secret until the next restart
+func hs256Secret() string {
+	secret := os.Getenv("JWT_SECRET")
+	switch {
+	case secret == "" && appEnv() != "development":
+		log.Fatalf("❌ JWT_SECRET not set, set a secret of your own or JWT_KEYS_DIR")
+	case secret == "":
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			log.Fatalf("❌ Failed to generate a JWT secret: %v", err)
+		}
+		log.Println("⚠️ JWT_SECRET not set, signing with a random secret, tokens stop validating on restart")
+		return hex.EncodeToString(random)
+	case secret == exampleJWTSecret && appEnv() != "development":
+		log.Fatalf("❌ JWT_SECRET is the example secret from env.example, set a secret of your own or JWT_KEYS_DIR")
+	}
+	return secret
+}
+
+// appEnv returns the current environment (development, staging or production)
+func appEnv() string {
+	env := strings.ToLower(os.Getenv("APP_ENV"))
+	if env == "" {
+		return "development"
+	}
+	return env
+}
+
+// signToken signs claims with the active asymmetric key, or with the shared secret in HS256 mode
+func (js *JWTService) signToken(claims jwt.Claims) (string, error) {
+	if js.activeKey == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(js.secretKey))
+	}
+
+	token := jwt.NewWithClaims(js.activeKey.method, claims)
+	token.Header["kid"] = js.activeKey.kid
+	return token.SignedString(js.activeKey.privateKey)
+}
+
+// verificationKey resolves the key used to verify a token based on its alg and kid headers
+func (js *JWTService) verificationKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if js.activeKey != nil {
+			return nil, fmt.Errorf("HS256 tokens are not accepted when asymmetric signing is enabled")
+		}
+		return []byte(js.secretKey), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := js.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %q", kid)
+	}
+	if key.method.Alg() != token.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.privateKey.Public(), nil
 }
 
 // GenerateTokens generates both access and refresh tokens
@@ -64,30 +184,37 @@ func (js *JWTService) GenerateTokens(user *models.User) (*models.AuthResponse, e
 		Username:   user.Username,
 		Email:      user.Email,
 		IsVerified: user.IsVerified,
-		ExpiresAt:  now.Add(js.accessTokenExpiry).Unix(),
-		IssuedAt:   now.Unix(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID.String(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(js.accessTokenExpiry)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
 	}
 
-	// Refresh token claims
+	// Refresh token claims, the jti makes each stored hash distinct
 	refreshClaims := &models.JWTClaims{
 		UserID:     user.ID.String(),
 		Username:   user.Username,
 		Email:      user.Email,
 		IsVerified: user.IsVerified,
-		ExpiresAt:  now.Add(js.refreshTokenExpiry).Unix(),
-		IssuedAt:   now.Unix(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID.String(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(js.refreshTokenExpiry)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.New().String(),
+		},
 	}
 
 	// Create access token
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessTokenString, err := accessToken.SignedString([]byte(js.secretKey))
+	accessTokenString, err := js.signToken(accessClaims)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create access token: %w", err)
 	}
 
 	// Create refresh token
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString([]byte(js.secretKey))
+	refreshTokenString, err := js.signToken(refreshClaims)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create refresh token: %w", err)
 	}
@@ -100,14 +227,15 @@ func (js *JWTService) GenerateTokens(user *models.User) (*models.AuthResponse, e
 	}, nil
 }
 
-// ValidateToken validates a JWT token and returns the claims
+// RefreshTokenExpiry returns how long issued refresh tokens stay valid
+func (js *JWTService) RefreshTokenExpiry() time.Duration {
+	return js.refreshTokenExpiry
+}
+
+// ValidateToken validates a JWT token and returns the claims. exp is required; exp, nbf
+// and iat are checked with the JWT_CLOCK_SKEW leeway.
 func (js *JWTService) ValidateToken(tokenString string) (*models.JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(js.secretKey), nil
-	})
+	token, err := js.parser.ParseWithClaims(tokenString, &models.JWTClaims{}, js.verificationKey)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -125,7 +253,7 @@ func (js *JWTService) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			respondError(c, http.StatusUnauthorized, "AUTH_HEADER_REQUIRED")
 			c.Abort()
 			return
 		}
@@ -138,7 +266,7 @@ func (js *JWTService) AuthMiddleware() gin.HandlerFunc {
 
 		claims, err := js.ValidateToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			respondError(c, http.StatusUnauthorized, "INVALID_TOKEN")
 			c.Abort()
 			return
 		}
@@ -218,3 +346,17 @@ func GetUserFromContext(c *gin.Context) (userID string, username string, email s
 
 	return userID, username, email, isVerified, true
 }
+
+// currentUserID returns the authenticated user's ID parsed as UUID
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, _, _, _, ok := GetUserFromContext(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"user-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// recordLogin records a successful credential or Google login: it stores last_login_at and
+// last_login_ip, publishes user.login, remembers the device/network and publishes
+// user.login.new_device when it hasn't been seen before and the user wants alerts
+func (uh *UserHandler) recordLogin(c *gin.Context, user *models.User) {
+	ipAddress := c.ClientIP()
+	userAgent := c.Request.UserAgent()
+	loginAt := time.Now()
+
+	if err := uh.userRepo.RecordLastLogin(user, ipAddress, loginAt); err != nil {
+		log.Printf("⚠️ Failed to record last login for %s: %v", user.Email, err)
+	}
+
+	if uh.eventService != nil {
+		if err := uh.eventService.PublishUserLogin(user.ID.String(), user.Username, user.Email, user.Type, ipAddress, loginAt); err != nil {
+			log.Printf("⚠️ Failed to publish user login event: %v", err)
+		}
+	}
+
+	isNew, err := uh.deviceRepo.RecordLogin(user.ID, ipAddress, userAgent)
+	if err != nil {
+		log.Printf("⚠️ Failed to record login device for %s: %v", user.Email, err)
+		return
+	}
+
+	if !isNew || !user.LoginAlerts {
+		return
+	}
+
+	if uh.eventService == nil {
+		log.Printf("⚠️ Event service not available, skipping login alert for %s", user.Email)
+		return
+	}
+
+	if err := uh.eventService.PublishLoginNewDevice(user.ID.String(), user.Username, user.Email, user.Locale, ipAddress, userAgent, loginAt); err != nil {
+		log.Printf("⚠️ Failed to publish new device login event: %v", err)
+	} else {
+		log.Printf("🔔 New device login event published for: %s", user.Email)
+	}
+}
+
+// RevokeSessions handles the one-click link from the login alert email: every refresh token
+// issued so far is rejected and the browser is redirected to the frontend with the result
+func (uh *UserHandler) RevokeSessions(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		redirectRevokeSessions(c, "invalid")
+		return
+	}
+
+	revokeToken, err := uh.deviceRepo.GetRevokeToken(token)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			redirectRevokeSessions(c, "invalid")
+			return
+		}
+		redirectRevokeSessions(c, "error")
+		return
+	}
+
+	if !revokeToken.IsUsable() {
+		redirectRevokeSessions(c, "expired")
+		return
+	}
+
+	user, err := uh.userRepo.GetByID(revokeToken.UserID)
+	if err != nil {
+		redirectRevokeSessions(c, "invalid")
+		return
+	}
+
+	if err := uh.deviceRepo.MarkRevokeTokenUsed(revokeToken); err != nil {
+		redirectRevokeSessions(c, "expired")
+		return
+	}
+
+	if err := uh.userRepo.RevokeSessions(user); err != nil {
+		redirectRevokeSessions(c, "error")
+		return
+	}
+
+	log.Printf("🔒 All sessions revoked for: %s", user.Email)
+	redirectRevokeSessions(c, "success")
+}
+
+// redirectRevokeSessions redirects to the frontend session revoke page (FRONTEND_URL) with a status
+func redirectRevokeSessions(c *gin.Context, status string) {
+	frontendURL := os.Getenv("FRONTEND_URL")
+	if frontendURL == "" {
+		frontendURL = "http://localhost:3000"
+	}
+	c.Redirect(http.StatusFound, strings.TrimRight(frontendURL, "/")+"/auth/revoke-sessions?status="+status)
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"user-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RunAccountCleanup triggers an account cleanup pass, ?dry_run=true reports without changing anything,
+// dry_run defaults to ACCOUNT_CLEANUP_DRY_RUN (admin only)
+func RunAccountCleanup(cleanup *services.AccountCleanupService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", strconv.FormatBool(cleanup.DryRun())))
+
+		result := cleanup.Run(dryRun)
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Account cleanup completed",
+			"result":  result,
+		})
+	}
+}
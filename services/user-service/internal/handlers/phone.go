@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"user-service/internal/models"
+	"user-service/internal/redact"
+	"user-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RequestPhoneVerification sends a verification code to a new phone number of the
+// authenticated user. The number replaces the current one once verified.
+func (uh *UserHandler) RequestPhoneVerification(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED")
+		return
+	}
+
+	var req models.PhoneNumberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST")
+		return
+	}
+	if err := uh.validator.Struct(req); err != nil {
+		respondValidationError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	phoneNumber, err := uh.phoneVerification.Normalize(req.PhoneNumber)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_PHONE_NUMBER")
+		return
+	}
+	channel := req.Channel
+	if channel == "" {
+		channel = services.SMSChannelSMS
+	}
+
+	user, err := uh.userRepo.GetByID(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "USER_NOT_FOUND")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	if user.VerifiedPhoneNumber() == phoneNumber {
+		respondError(c, http.StatusConflict, "PHONE_ALREADY_VERIFIED")
+		return
+	}
+
+	taken, err := uh.userRepo.IsPhoneNumberTaken(phoneNumber, user.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+	if taken {
+		respondError(c, http.StatusConflict, "PHONE_NUMBER_TAKEN")
+		return
+	}
+
+	// Throttle per phone number and IP, texts cost money and can bother the number's owner
+	throttle := uh.sendThrottle.Allow(c.Request.Context(), services.ThrottlePhoneOTP, phoneNumber, c.ClientIP())
+	if !throttle.Allowed {
+		respondSendThrottled(c, throttle)
+		return
+	}
+
+	code, err := uh.otpService.GenerateOTP()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "OTP_GENERATION_FAILED")
+		return
+	}
+
+	now := time.Now()
+	user.PendingPhoneNumber = &phoneNumber
+	user.PhoneOTPCode = &code
+	user.PhoneOTPIssuedAt = &now
+	user.PhoneOTPAttempts = 0
+	if err := uh.userRepo.Update(user); err != nil {
+		respondError(c, http.StatusInternalServerError, "OTP_UPDATE_FAILED")
+		return
+	}
+
+	if err := uh.phoneVerification.SendCode(c.Request.Context(), channel, phoneNumber, code, user.Locale); err != nil {
+		log.Printf("❌ Failed to send phone verification code to %s: %v", redact.Tail(phoneNumber), err)
+		respondError(c, http.StatusBadGateway, "SMS_SEND_FAILED")
+		return
+	}
+	log.Printf("📱 Phone verification code sent to %s via %s for user %s", redact.Tail(phoneNumber), channel, user.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      localize(c, "PHONE_OTP_SENT"),
+		"code":         "PHONE_OTP_SENT",
+		"phone_number": phoneNumber,
+		"channel":      channel,
+		"expires_in":   int(uh.phoneVerification.CodeTTL().Seconds()),
+		"cooldown":     uh.sendCooldown(throttle),
+	})
+}
+
+// VerifyPhone confirms the pending phone number of the authenticated user with its code
+func (uh *UserHandler) VerifyPhone(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED")
+		return
+	}
+
+	var req models.PhoneVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST")
+		return
+	}
+	if err := uh.validator.Struct(req); err != nil {
+		respondValidationError(c, http.StatusBadRequest, err)
+		return
+	}
+	if !uh.otpService.ValidateOTP(req.OTPCode) {
+		respondError(c, http.StatusBadRequest, "INVALID_OTP_FORMAT")
+		return
+	}
+
+	user, err := uh.userRepo.GetByID(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "USER_NOT_FOUND")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	if user.PendingPhoneNumber == nil || user.PhoneOTPCode == nil {
+		respondError(c, http.StatusBadRequest, "NO_PENDING_PHONE")
+		return
+	}
+
+	if uh.phoneVerification.IsCodeExpired(user.PhoneOTPIssuedAt) {
+		user.ClearPhoneVerification()
+		if err := uh.userRepo.Update(user); err != nil {
+			log.Printf("⚠️ Failed to clear expired phone code for user %s: %v", user.ID, err)
+		}
+		respondError(c, http.StatusBadRequest, "PHONE_OTP_EXPIRED")
+		return
+	}
+
+	if *user.PhoneOTPCode != req.OTPCode {
+		// Too many wrong guesses discard the code, a new one has to be requested
+		user.PhoneOTPAttempts++
+		code := "INVALID_OTP"
+		if user.PhoneOTPAttempts >= uh.phoneVerification.MaxAttempts() {
+			user.ClearPhoneVerification()
+			code = "PHONE_OTP_ATTEMPTS_EXCEEDED"
+		}
+		if err := uh.userRepo.Update(user); err != nil {
+			respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+			return
+		}
+		respondError(c, http.StatusBadRequest, code)
+		return
+	}
+
+	// Another account may have verified the number since the code was sent
+	taken, err := uh.userRepo.IsPhoneNumberTaken(*user.PendingPhoneNumber, user.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+	if taken {
+		respondError(c, http.StatusConflict, "PHONE_NUMBER_TAKEN")
+		return
+	}
+
+	now := time.Now()
+	phoneNumber := *user.PendingPhoneNumber
+	user.PhoneNumber = &phoneNumber
+	user.PhoneVerifiedAt = &now
+	user.ClearPhoneVerification()
+	if err := uh.userRepo.Update(user); err != nil {
+		respondError(c, http.StatusInternalServerError, "PROFILE_UPDATE_FAILED")
+		return
+	}
+
+	uh.publishUserUpdated(user)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": localize(c, "PHONE_VERIFIED"),
+		"code":    "PHONE_VERIFIED",
+		"user":    user.ToResponse(),
+	})
+}
+
+// RemovePhone removes the phone number of the authenticated user, including a pending one
+func (uh *UserHandler) RemovePhone(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED")
+		return
+	}
+
+	user, err := uh.userRepo.GetByID(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "USER_NOT_FOUND")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	hadVerified := user.VerifiedPhoneNumber() != ""
+	user.PhoneNumber = nil
+	user.PhoneVerifiedAt = nil
+	user.ClearPhoneVerification()
+	if err := uh.userRepo.Update(user); err != nil {
+		respondError(c, http.StatusInternalServerError, "PROFILE_UPDATE_FAILED")
+		return
+	}
+
+	if hadVerified {
+		uh.publishUserUpdated(user)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": localize(c, "PHONE_REMOVED"),
+		"code":    "PHONE_REMOVED",
+		"user":    user.ToResponse(),
+	})
+}
+
+// publishUserUpdated tells other services' read models about a profile change
+func (uh *UserHandler) publishUserUpdated(user *models.User) {
+	if uh.eventService == nil {
+		return
+	}
+	if err := uh.eventService.PublishUserUpdated(user.ID.String(), user.Username, user.Email, user.Locale, user.VerifiedPhoneNumber()); err != nil {
+		log.Printf("⚠️ Failed to publish user updated event: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateWindow counts the requests of one client in the current window
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimit allows each client IP limit requests per window, answering 429 with Retry-After
+// beyond that. Counters are kept in memory, so the limit applies per instance.
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	var (
+		mu        sync.Mutex
+		clients   = make(map[string]*rateWindow)
+		lastSweep = time.Now()
+	)
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		// Drop expired windows now and then so idle clients don't accumulate
+		if now.Sub(lastSweep) > window {
+			for key, w := range clients {
+				if now.Sub(w.start) >= window {
+					delete(clients, key)
+				}
+			}
+			lastSweep = now
+		}
+
+		w, ok := clients[ip]
+		if !ok || now.Sub(w.start) >= window {
+			w = &rateWindow{start: now}
+			clients[ip] = w
+		}
+		w.count++
+		count, retryAfter := w.count, w.start.Add(window).Sub(now)
+		mu.Unlock()
+
+		if count > limit {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			respondError(c, http.StatusTooManyRequests, "TOO_MANY_REQUESTS")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// AvailabilityRateLimit limits the username and email availability checks, which would
+// otherwise let anyone enumerate registered accounts. AVAILABILITY_RATE_LIMIT sets the
+// requests allowed per IP per minute (default 20).
+func AvailabilityRateLimit() gin.HandlerFunc {
+	limit := 20
+	if value, err := strconv.Atoi(os.Getenv("AVAILABILITY_RATE_LIMIT")); err == nil && value > 0 {
+		limit = value
+	}
+	return RateLimit(limit, time.Minute)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"log"
+	"time"
+
+	"user-service/internal/models"
+	"user-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// issueTokens generates an access/refresh token pair and records the refresh token, bound to
+// the requesting client, so RefreshToken only accepts it from that client
+func (uh *UserHandler) issueTokens(c *gin.Context, user *models.User) (*models.AuthResponse, error) {
+	authResponse, err := uh.JWTService.GenerateTokens(user)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(uh.JWTService.RefreshTokenExpiry())
+	if err := uh.refreshTokenRepo.Store(user.ID, authResponse.RefreshToken, c.Request.UserAgent(), expiresAt); err != nil {
+		return nil, err
+	}
+
+	return authResponse, nil
+}
+
+// clientMatches reports whether the refresh token is presented by the client it was issued to.
+// On a mismatch the token is revoked, as it is most likely stolen, and a security event is published.
+func (uh *UserHandler) clientMatches(c *gin.Context, user *models.User, refreshToken *models.RefreshToken) bool {
+	userAgent := c.Request.UserAgent()
+	if refreshToken.ClientFingerprint == repository.ClientFingerprint(userAgent) {
+		return true
+	}
+
+	log.Printf("🚨 Refresh token of %s presented by another client (ip=%s)", user.Email, c.ClientIP())
+
+	if err := uh.refreshTokenRepo.Revoke(refreshToken); err != nil {
+		log.Printf("⚠️ Failed to revoke mismatched refresh token of %s: %v", user.Email, err)
+	}
+
+	if uh.eventService != nil {
+		if err := uh.eventService.PublishRefreshTokenMismatch(user.ID.String(), user.Email, c.ClientIP(), userAgent, time.Now()); err != nil {
+			log.Printf("⚠️ Failed to publish refresh token mismatch event: %v", err)
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"user-service/internal/i18n"
+	"user-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondError writes a localized error response keyed by an error code.
+// "error" stays in English for logs and existing clients, "message" follows
+// the negotiated locale and "code" is stable for programmatic handling.
+func respondError(c *gin.Context, status int, code string) {
+	respondErrorWithMessage(c, status, code, code)
+}
+
+// respondErrorWithMessage is like respondError but uses a separate catalog key for the user-facing message
+func respondErrorWithMessage(c *gin.Context, status int, code, messageKey string) {
+	c.JSON(status, gin.H{
+		"error":   i18n.T(i18n.LocaleEN, code),
+		"message": i18n.T(i18n.FromContext(c), messageKey),
+		"code":    code,
+	})
+}
+
+// respondErrorWithFields is like respondError but adds fields (details, ...) to the response
+func respondErrorWithFields(c *gin.Context, status int, code string, fields gin.H) {
+	body := gin.H{
+		"error":   i18n.T(i18n.LocaleEN, code),
+		"message": i18n.T(i18n.FromContext(c), code),
+		"code":    code,
+	}
+	for key, value := range fields {
+		body[key] = value
+	}
+	c.JSON(status, body)
+}
+
+// respondValidationError writes a localized validation error including the validator details
+func respondValidationError(c *gin.Context, status int, err error) {
+	c.JSON(status, gin.H{
+		"error":   i18n.T(i18n.LocaleEN, "VALIDATION_FAILED"),
+		"message": i18n.T(i18n.FromContext(c), "VALIDATION_FAILED"),
+		"code":    "VALIDATION_FAILED",
+		"details": err.Error(),
+	})
+}
+
+// respondPasswordPolicyError writes a localized weak password error listing every failed rule
+func respondPasswordPolicyError(c *gin.Context, violations []services.PasswordViolation) {
+	locale := i18n.FromContext(c)
+
+	details := make([]gin.H, 0, len(violations))
+	for _, violation := range violations {
+		key := "password.rule." + violation.Rule
+		message := i18n.T(locale, key)
+		if violation.Param > 0 {
+			message = i18n.T(locale, key, violation.Param)
+		}
+		details = append(details, gin.H{
+			"rule":    violation.Rule,
+			"message": message,
+		})
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":   i18n.T(i18n.LocaleEN, "WEAK_PASSWORD"),
+		"message": i18n.T(locale, "WEAK_PASSWORD"),
+		"code":    "WEAK_PASSWORD",
+		"details": details,
+	})
+}
+
+// respondUsernamePolicyError writes a localized invalid username error listing every failed rule
+func respondUsernamePolicyError(c *gin.Context, violations []services.UsernameViolation) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":   i18n.T(i18n.LocaleEN, "INVALID_USERNAME"),
+		"message": i18n.T(i18n.FromContext(c), "INVALID_USERNAME"),
+		"code":    "INVALID_USERNAME",
+		"details": usernameViolationDetails(c, violations),
+	})
+}
+
+// usernameViolationDetails localizes username policy violations
+func usernameViolationDetails(c *gin.Context, violations []services.UsernameViolation) []gin.H {
+	locale := i18n.FromContext(c)
+
+	details := make([]gin.H, 0, len(violations))
+	for _, violation := range violations {
+		key := "username.rule." + violation.Rule
+		message := i18n.T(locale, key)
+		if violation.Param > 0 {
+			message = i18n.T(locale, key, violation.Param)
+		}
+		details = append(details, gin.H{
+			"rule":    violation.Rule,
+			"message": message,
+		})
+	}
+	return details
+}
+
+// respondSendThrottled writes a localized 429 for throttled OTP or reset code requests
+func respondSendThrottled(c *gin.Context, result services.ThrottleResult) {
+	retryAfter := result.RetryAfterSeconds()
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       i18n.T(i18n.LocaleEN, "SEND_LIMIT_REACHED", retryAfter),
+		"message":     i18n.T(i18n.FromContext(c), "SEND_LIMIT_REACHED", retryAfter),
+		"code":        "SEND_LIMIT_REACHED",
+		"retry_after": retryAfter,
+	})
+}
+
+// respondVerificationLocked writes a localized 429 for OTP verification locked by the guard
+func respondVerificationLocked(c *gin.Context, result services.ThrottleResult) {
+	retryAfter := result.RetryAfterSeconds()
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       i18n.T(i18n.LocaleEN, "VERIFICATION_LOCKED", retryAfter),
+		"message":     i18n.T(i18n.FromContext(c), "VERIFICATION_LOCKED", retryAfter),
+		"code":        "VERIFICATION_LOCKED",
+		"retry_after": retryAfter,
+	})
+}
+
+// respondInvalidCode writes a wrong OTP or reset code error with the attempts left, or the
+// lockout when this code used up the last one
+func respondInvalidCode(c *gin.Context, code string, result services.ThrottleResult) {
+	if !result.Allowed {
+		respondVerificationLocked(c, result)
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":              i18n.T(i18n.LocaleEN, code),
+		"message":            i18n.T(i18n.FromContext(c), code),
+		"code":               code,
+		"attempts_remaining": result.Remaining,
+	})
+}
+
+// respondCaptchaError writes a localized 403 asking for a (new) CAPTCHA token from provider
+func respondCaptchaError(c *gin.Context, err error, provider string) {
+	code := "CAPTCHA_INVALID"
+	if errors.Is(err, services.ErrCaptchaRequired) {
+		code = "CAPTCHA_REQUIRED"
+	}
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":            i18n.T(i18n.LocaleEN, code),
+		"message":          i18n.T(i18n.FromContext(c), code),
+		"code":             code,
+		"captcha_provider": provider,
+	})
+}
+
+// sendCooldown describes how many more codes the email can request in the current window
+func (uh *UserHandler) sendCooldown(result services.ThrottleResult) gin.H {
+	return gin.H{
+		"remaining":      result.Remaining,
+		"window_seconds": int(uh.sendThrottle.Window().Seconds()),
+	}
+}
+
+// localize translates a message key into the request locale
+func localize(c *gin.Context, key string) string {
+	return i18n.T(i18n.FromContext(c), key)
+}
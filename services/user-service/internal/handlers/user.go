@@ -3,10 +3,15 @@ package handlers
 import (
 	"log"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"user-service/internal/events"
+	"user-service/internal/i18n"
 	"user-service/internal/models"
+	"user-service/internal/repository"
+	"user-service/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -17,31 +22,45 @@ import (
 
 // UserHandler handles user-related HTTP requests
 type UserHandler struct {
-	db             *gorm.DB
+	userRepo        repository.UserStore
+	emailLogRepo    repository.EmailLogStore
+	suppressionRepo repository.EmailSuppressionStore
+	tokenRepo       repository.VerificationTokenStore
+	deviceRepo      repository.LoginDeviceStore
+	refreshTokenRepo repository.RefreshTokenStore
 	passwordService *models.PasswordService
+	passwordPolicy  *services.PasswordPolicyService
+	usernamePolicy  *services.UsernamePolicyService
+	sendThrottle    *services.SendThrottle
+	verificationGuard *services.VerificationGuard
+	phoneVerification *services.PhoneVerificationService
+	authMetrics     *services.AuthMetrics
 	otpService     *models.OTPService
 	JWTService     *JWTService
 	validator      *validator.Validate
 	eventService   *events.EventService
 }
 
-// NewUserHandler creates a new user handler
-func NewUserHandler(db *gorm.DB) *UserHandler {
+// NewUserHandler creates a new user handler publishing on eventService (nil to publish nothing)
+func NewUserHandler(db *gorm.DB, eventService *events.EventService) *UserHandler {
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
 		log.Println("⚠️ .env file not found in user handlers package, using system env")
 	}
 
-	// Initialize event service
-	eventService, err := events.NewEventService()
-	if err != nil {
-		log.Printf("⚠️ Failed to initialize event service: %v", err)
-		// Continue without event service for now
-	}
-
 	return &UserHandler{
-		db:              db,
+		userRepo:        repository.NewUserRepository(db),
+		emailLogRepo:    repository.NewEmailLogRepository(db),
+		suppressionRepo: repository.NewEmailSuppressionRepository(db),
+		tokenRepo:       repository.NewVerificationTokenRepository(db),
+		deviceRepo:      repository.NewLoginDeviceRepository(db),
+		refreshTokenRepo: repository.NewRefreshTokenRepository(db),
 		passwordService: models.NewPasswordService(),
+		passwordPolicy:  services.NewPasswordPolicyService(),
+		usernamePolicy:  services.NewUsernamePolicyService(),
+		sendThrottle:    services.NewSendThrottle(services.NewMemoryRateLimitStore()),
+		verificationGuard: services.NewVerificationGuard(services.NewMemoryRateLimitStore(), nil),
+		phoneVerification: services.NewPhoneVerificationService(services.LogSMSProvider{}),
 		otpService:      models.NewOTPService(),
 		JWTService:      NewJWTService(),
 		validator:       validator.New(),
@@ -49,60 +68,119 @@ func NewUserHandler(db *gorm.DB) *UserHandler {
 	}
 }
 
+// SetSendThrottle replaces the per-instance send throttle, e.g. with one backed by Redis
+func (uh *UserHandler) SetSendThrottle(throttle *services.SendThrottle) {
+	uh.sendThrottle = throttle
+}
+
+// SetVerificationGuard replaces the per-instance OTP lockout and CAPTCHA guard, e.g. with one
+// backed by Redis and a CAPTCHA provider
+func (uh *UserHandler) SetVerificationGuard(guard *services.VerificationGuard) {
+	uh.verificationGuard = guard
+}
+
+// SetPhoneVerification replaces the phone verification service, e.g. with one sending real SMS
+func (uh *UserHandler) SetPhoneVerification(phoneVerification *services.PhoneVerificationService) {
+	uh.phoneVerification = phoneVerification
+}
+
+// SetAuthMetrics counts registrations, resends and verifications in metrics
+func (uh *UserHandler) SetAuthMetrics(metrics *services.AuthMetrics) {
+	uh.authMetrics = metrics
+}
+
+// SendThrottleStats returns the OTP resend and reset code throttling counters
+func (uh *UserHandler) SendThrottleStats() map[string]interface{} {
+	return uh.sendThrottle.Stats()
+}
+
+// VerificationGuardStats returns the OTP lockout and CAPTCHA counters
+func (uh *UserHandler) VerificationGuardStats() map[string]interface{} {
+	return uh.verificationGuard.Stats()
+}
+
 // Register handles user registration
 func (uh *UserHandler) Register(c *gin.Context) {
 	var req models.UserRegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST")
 		return
 	}
 
 	// Validate request
 	if err := uh.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondValidationError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// Clients flagged by failed logins or verifications must solve a CAPTCHA
+	if err := uh.verificationGuard.CheckCaptcha(c.Request.Context(), services.GuardRegister, req.Email, c.ClientIP(), req.CaptchaToken); err != nil {
+		respondCaptchaError(c, err, uh.verificationGuard.CaptchaProvider())
+		return
+	}
+
+	// Enforce username policy on the normalized username
+	req.Username = uh.usernamePolicy.Normalize(req.Username)
+	if violations := uh.usernamePolicy.Validate(req.Username); len(violations) > 0 {
+		respondUsernamePolicyError(c, violations)
+		return
+	}
+
+	// Enforce password policy
+	if violations := uh.passwordPolicy.Validate(req.Password, req.Username, req.Email); len(violations) > 0 {
+		respondPasswordPolicyError(c, violations)
 		return
 	}
 
 	// Check if user already exists
-	var existingUser models.User
-	if err := uh.db.Where("email = ? OR username = ?", req.Email, req.Username).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "User with this email or username already exists"})
+	exists, err := uh.userRepo.ExistsByEmailOrUsername(req.Email, req.Username)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+	if exists {
+		respondError(c, http.StatusConflict, "USER_ALREADY_EXISTS")
 		return
 	}
 
 	// Hash password
 	hashedPassword, err := uh.passwordService.HashPassword(req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
+		respondError(c, http.StatusInternalServerError, "PASSWORD_PROCESSING_FAILED")
 		return
 	}
 
 	// Generate OTP
 	otp, err := uh.otpService.GenerateOTP()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate OTP"})
+		respondError(c, http.StatusInternalServerError, "OTP_GENERATION_FAILED")
 		return
 	}
 
 	// Create user
+	otpIssuedAt := time.Now()
 	user := models.User{
 		Username:     req.Username,
 		Email:        req.Email,
 		PasswordHash: hashedPassword,
 		OTPCode:      &otp,
+		OTPIssuedAt:  &otpIssuedAt,
 		Type:         "credential",
 		IsVerified:   false,
+		Locale:       string(i18n.FromContext(c)),
 	}
 
 	// Save user to database
-	if err := uh.db.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+	if err := uh.userRepo.Create(&user); err != nil {
+		respondError(c, http.StatusInternalServerError, "USER_CREATE_FAILED")
 		return
 	}
 
+	uh.authMetrics.Registered()
+
 	// Publish user registered event to message broker
 	if uh.eventService != nil {
-		if err := uh.eventService.PublishUserRegistered(user.ID.String(), user.Username, user.Email); err != nil {
+		if err := uh.eventService.PublishUserRegistered(user.ID.String(), user.Username, user.Email, user.Locale); err != nil {
 			log.Printf("⚠️ Failed to publish user registered event: %v", err)
 			// Don't fail the registration if event publishing fails
 		} else {
@@ -114,7 +192,8 @@ func (uh *UserHandler) Register(c *gin.Context) {
 
 	// Return success response (OTP will be sent via email through message broker)
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "User registered successfully. Please check your email for verification code.",
+		"message": localize(c, "REGISTER_SUCCESS"),
+		"code":    "REGISTER_SUCCESS",
 		"user":    user.ToResponse(),
 	})
 }
@@ -123,58 +202,61 @@ func (uh *UserHandler) Register(c *gin.Context) {
 func (uh *UserHandler) Login(c *gin.Context) {
 	var req models.UserLoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST")
 		return
 	}
 
 	// Validate request
 	if err := uh.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondValidationError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	// Find user by email
-	var user models.User
-	if err := uh.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
+	identifier := req.LoginIdentifier()
+	if err := uh.verificationGuard.CheckCaptcha(c.Request.Context(), services.GuardLogin, identifier, c.ClientIP(), req.CaptchaToken); err != nil {
+		respondCaptchaError(c, err, uh.verificationGuard.CaptchaProvider())
+		return
+	}
+
+	// Find user by email or username
+	user, err := uh.userRepo.GetByLoginIdentifier(identifier)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "User not found",
-				"message": "Email tidak terdaftar. Silakan periksa kembali email Anda atau daftar akun baru.",
-				"code": "USER_NOT_FOUND",
-			})
+			uh.verificationGuard.LoginFailed(c.Request.Context(), identifier, c.ClientIP())
+			if models.IsEmailIdentifier(identifier) {
+				respondErrorWithMessage(c, http.StatusUnauthorized, "USER_NOT_FOUND", "EMAIL_NOT_REGISTERED")
+			} else {
+				respondErrorWithMessage(c, http.StatusUnauthorized, "USER_NOT_FOUND", "USERNAME_NOT_REGISTERED")
+			}
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
 		return
 	}
 
 	// Check if user type is credential (not Google OAuth user)
 	if user.Type != "credential" {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Account type mismatch",
-			"message": "Akun ini dibuat dengan Google. Silakan gunakan tombol 'Masuk dengan Google' untuk login.",
-			"code": "ACCOUNT_TYPE_MISMATCH",
-		})
+		respondErrorWithMessage(c, http.StatusUnauthorized, "ACCOUNT_TYPE_MISMATCH", "ACCOUNT_TYPE_MISMATCH_HINT")
 		return
 	}
 
 	// Verify password
 	if err := uh.passwordService.VerifyPassword(user.PasswordHash, req.Password); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid password",
-			"message": "Password yang Anda masukkan salah. Silakan coba lagi.",
-			"code": "INVALID_PASSWORD",
-		})
+		uh.verificationGuard.LoginFailed(c.Request.Context(), identifier, c.ClientIP())
+		respondErrorWithMessage(c, http.StatusUnauthorized, "INVALID_PASSWORD", "INVALID_PASSWORD_HINT")
 		return
 	}
 
 	// Generate tokens
-	authResponse, err := uh.JWTService.GenerateTokens(&user)
+	authResponse, err := uh.issueTokens(c, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		respondError(c, http.StatusInternalServerError, "TOKEN_GENERATION_FAILED")
 		return
 	}
 
+	// Alert the user about logins from new devices
+	uh.recordLogin(c, user)
+
 	c.JSON(http.StatusOK, authResponse)
 }
 
@@ -182,65 +264,76 @@ func (uh *UserHandler) Login(c *gin.Context) {
 func (uh *UserHandler) VerifyOTP(c *gin.Context) {
 	var req models.OTPVerifyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST")
 		return
 	}
 
 	// Validate request
 	if err := uh.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondValidationError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	// Validate OTP format
 	if !uh.otpService.ValidateOTP(req.OTPCode) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid OTP format"})
+		respondError(c, http.StatusBadRequest, "INVALID_OTP_FORMAT")
+		return
+	}
+
+	// Locked out emails and IPs are refused before the code is looked at
+	ctx, ip := c.Request.Context(), c.ClientIP()
+	if lock := uh.verificationGuard.Locked(ctx, services.GuardVerifyOTP, req.Email, ip); !lock.Allowed {
+		respondVerificationLocked(c, lock)
 		return
 	}
 
 	// Find user by email
-	var user models.User
-	if err := uh.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
+	user, err := uh.userRepo.GetByEmail(req.Email)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			uh.verificationGuard.Failed(ctx, services.GuardVerifyOTP, req.Email, ip)
+			respondError(c, http.StatusNotFound, "USER_NOT_FOUND")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
 		return
 	}
 
 	// Check if user is already verified
 	if user.IsVerified {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User is already verified"})
+		respondError(c, http.StatusBadRequest, "USER_ALREADY_VERIFIED")
 		return
 	}
 
-	// Verify OTP
+	// Verify OTP, a missing code was cleared by the account cleanup (OTP_MAX_AGE)
 	if user.OTPCode == nil || *user.OTPCode != req.OTPCode {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid OTP"})
+		if user.OTPCode == nil {
+			uh.authMetrics.ExpiredOTP()
+		} else {
+			uh.authMetrics.InvalidOTP()
+		}
+		respondInvalidCode(c, "INVALID_OTP", uh.verificationGuard.Failed(ctx, services.GuardVerifyOTP, req.Email, ip))
 		return
 	}
 
 	// Update user as verified and clear OTP
-	user.IsVerified = true
-	user.OTPCode = nil
-	user.UpdatedAt = time.Now()
-
-	if err := uh.db.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify user"})
+	if err := uh.userRepo.MarkVerified(user); err != nil {
+		respondError(c, http.StatusInternalServerError, "VERIFICATION_FAILED")
 		return
 	}
 
+	uh.authMetrics.VerifiedByOTP()
+
 	// Generate tokens after successful verification
-	authResponse, err := uh.JWTService.GenerateTokens(&user)
+	authResponse, err := uh.issueTokens(c, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		respondError(c, http.StatusInternalServerError, "TOKEN_GENERATION_FAILED")
 		return
 	}
 
 	// Publish user verified event to message broker
 	if uh.eventService != nil {
-		if err := uh.eventService.PublishUserVerified(user.ID.String(), user.Username, user.Email); err != nil {
+		if err := uh.eventService.PublishUserVerified(user.ID.String(), user.Username, user.Email, user.Locale); err != nil {
 			log.Printf("⚠️ Failed to publish user verified event: %v", err)
 			// Don't fail the verification if event publishing fails
 		} else {
@@ -251,6 +344,74 @@ func (uh *UserHandler) VerifyOTP(c *gin.Context) {
 	c.JSON(http.StatusOK, authResponse)
 }
 
+// VerifyEmail handles the one-click verification link from the OTP email
+// and redirects the browser to the frontend with the result
+func (uh *UserHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		redirectVerification(c, "invalid")
+		return
+	}
+
+	verificationToken, err := uh.tokenRepo.GetByToken(token)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			redirectVerification(c, "invalid")
+			return
+		}
+		redirectVerification(c, "error")
+		return
+	}
+
+	if !verificationToken.IsUsable() {
+		uh.authMetrics.ExpiredLink()
+		redirectVerification(c, "expired")
+		return
+	}
+
+	user, err := uh.userRepo.GetByID(verificationToken.UserID)
+	if err != nil {
+		redirectVerification(c, "invalid")
+		return
+	}
+
+	if user.IsVerified {
+		redirectVerification(c, "already_verified")
+		return
+	}
+
+	if err := uh.tokenRepo.MarkUsed(verificationToken); err != nil {
+		redirectVerification(c, "expired")
+		return
+	}
+
+	if err := uh.userRepo.MarkVerified(user); err != nil {
+		redirectVerification(c, "error")
+		return
+	}
+	uh.authMetrics.VerifiedByLink()
+
+	// Publish user verified event to message broker
+	if uh.eventService != nil {
+		if err := uh.eventService.PublishUserVerified(user.ID.String(), user.Username, user.Email, user.Locale); err != nil {
+			log.Printf("⚠️ Failed to publish user verified event: %v", err)
+		} else {
+			log.Printf("✅ User verified event published for: %s", user.Email)
+		}
+	}
+
+	redirectVerification(c, "success")
+}
+
+// redirectVerification redirects to the frontend verification page (FRONTEND_URL) with a status
+func redirectVerification(c *gin.Context, status string) {
+	frontendURL := os.Getenv("FRONTEND_URL")
+	if frontendURL == "" {
+		frontendURL = "http://localhost:3000"
+	}
+	c.Redirect(http.StatusFound, strings.TrimRight(frontendURL, "/")+"/auth/verify-email?status="+status)
+}
+
 // ResendOTP handles OTP resending
 func (uh *UserHandler) ResendOTP(c *gin.Context) {
 	var req struct {
@@ -258,52 +419,57 @@ func (uh *UserHandler) ResendOTP(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST")
 		return
 	}
 
 	// Validate request
 	if err := uh.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondValidationError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// Throttle per email and IP before anything is looked up or sent
+	throttle := uh.sendThrottle.Allow(c.Request.Context(), services.ThrottleResendOTP, req.Email, c.ClientIP())
+	uh.authMetrics.ResendRequested(!throttle.Allowed)
+	if !throttle.Allowed {
+		respondSendThrottled(c, throttle)
 		return
 	}
 
 	// Find user by email
-	var user models.User
-	if err := uh.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
+	user, err := uh.userRepo.GetByEmail(req.Email)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			respondError(c, http.StatusNotFound, "USER_NOT_FOUND")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
 		return
 	}
 
 	// Check if user is already verified
 	if user.IsVerified {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User is already verified"})
+		respondError(c, http.StatusBadRequest, "USER_ALREADY_VERIFIED")
 		return
 	}
 
 	// Generate new OTP
 	otp, err := uh.otpService.GenerateOTP()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate OTP"})
+		respondError(c, http.StatusInternalServerError, "OTP_GENERATION_FAILED")
 		return
 	}
 
 	// Update user with new OTP
-	user.OTPCode = &otp
-	user.UpdatedAt = time.Now()
-
-	if err := uh.db.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update OTP"})
+	if err := uh.userRepo.UpdateOTP(user, &otp); err != nil {
+		respondError(c, http.StatusInternalServerError, "OTP_UPDATE_FAILED")
 		return
 	}
 
 	// Publish user registered event again to resend OTP
 	if uh.eventService != nil {
-		if err := uh.eventService.PublishUserRegistered(user.ID.String(), user.Username, user.Email); err != nil {
+		if err := uh.eventService.PublishUserRegistered(user.ID.String(), user.Username, user.Email, user.Locale); err != nil {
 			log.Printf("⚠️ Failed to publish resend OTP event: %v", err)
 			// Don't fail the resend if event publishing fails
 		} else {
@@ -312,25 +478,27 @@ func (uh *UserHandler) ResendOTP(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "OTP sent successfully. Please check your email.",
+		"message":  localize(c, "OTP_SENT"),
+		"code":     "OTP_SENT",
+		"cooldown": uh.sendCooldown(throttle),
 	})
 }
 
 // GetProfile handles getting user profile
 func (uh *UserHandler) GetProfile(c *gin.Context) {
-	userID, _, _, _, ok := GetUserFromContext(c)
+	userID, ok := currentUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED")
 		return
 	}
 
-	var user models.User
-	if err := uh.db.Where("id = ?", userID).First(&user).Error; err != nil {
+	user, err := uh.userRepo.GetByID(userID)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			respondError(c, http.StatusNotFound, "USER_NOT_FOUND")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
 		return
 	}
 
@@ -344,26 +512,17 @@ func (uh *UserHandler) GetUserByID(c *gin.Context) {
 	// Parse UUID
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid user ID format",
-		})
+		respondErrorWithFields(c, http.StatusBadRequest, "INVALID_USER_ID", gin.H{"success": false})
 		return
 	}
 
-	var user models.User
-	if err := uh.db.Where("id = ?", userID).First(&user).Error; err != nil {
+	user, err := uh.userRepo.GetByID(userID)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"success": false,
-				"error":   "User not found",
-			})
+			respondErrorWithFields(c, http.StatusNotFound, "USER_NOT_FOUND", gin.H{"success": false})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Database error",
-		})
+		respondErrorWithFields(c, http.StatusInternalServerError, "DATABASE_ERROR", gin.H{"success": false})
 		return
 	}
 
@@ -371,69 +530,165 @@ func (uh *UserHandler) GetUserByID(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
-			"id":       user.ID.String(),
-			"username": user.Username,
-			"email":    user.Email,
+			"id":           user.ID.String(),
+			"username":     user.Username,
+			"email":        user.Email,
+			"phone_number": user.VerifiedPhoneNumber(),
 		},
 	})
 }
 
 // UpdateProfile handles updating user profile
 func (uh *UserHandler) UpdateProfile(c *gin.Context) {
-	userID, _, _, _, ok := GetUserFromContext(c)
+	userID, ok := currentUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED")
 		return
 	}
 
 	var req struct {
-		Username string `json:"username" validate:"omitempty,min=3,max=100"`
+		Username    string `json:"username" validate:"omitempty,min=3,max=100"`
+		Locale      string `json:"locale" validate:"omitempty,oneof=id en"`
+		LoginAlerts *bool  `json:"login_alerts"`
+		MarketingEmails *bool `json:"marketing_emails"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST")
 		return
 	}
 
 	// Validate request
 	if err := uh.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondValidationError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	var user models.User
-	if err := uh.db.Where("id = ?", userID).First(&user).Error; err != nil {
+	user, err := uh.userRepo.GetByID(userID)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			respondError(c, http.StatusNotFound, "USER_NOT_FOUND")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
 		return
 	}
 
 	// Check if username is already taken by another user
+	req.Username = uh.usernamePolicy.Normalize(req.Username)
 	if req.Username != "" && req.Username != user.Username {
-		var existingUser models.User
-		if err := uh.db.Where("username = ? AND id != ?", req.Username, userID).First(&existingUser).Error; err == nil {
-			c.JSON(http.StatusConflict, gin.H{"error": "Username already taken"})
+		if violations := uh.usernamePolicy.Validate(req.Username); len(violations) > 0 {
+			respondUsernamePolicyError(c, violations)
+			return
+		}
+
+		taken, err := uh.userRepo.IsUsernameTaken(req.Username, userID)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+			return
+		}
+		if taken {
+			respondError(c, http.StatusConflict, "USERNAME_TAKEN")
 			return
 		}
 		user.Username = req.Username
 	}
 
-	user.UpdatedAt = time.Now()
+	if req.Locale != "" {
+		user.Locale = req.Locale
+	}
+
+	if req.LoginAlerts != nil {
+		user.LoginAlerts = *req.LoginAlerts
+	}
+
+	if req.MarketingEmails != nil {
+		user.MarketingEmails = *req.MarketingEmails
+	}
 
-	if err := uh.db.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+	if err := uh.userRepo.Update(user); err != nil {
+		respondError(c, http.StatusInternalServerError, "PROFILE_UPDATE_FAILED")
 		return
 	}
 
+	// Publish user updated event so read models in other services stay current
+	if uh.eventService != nil {
+		if err := uh.eventService.PublishUserUpdated(user.ID.String(), user.Username, user.Email, user.Locale, user.VerifiedPhoneNumber()); err != nil {
+			log.Printf("⚠️ Failed to publish user updated event: %v", err)
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Profile updated successfully",
+		"message": localize(c, "PROFILE_UPDATED"),
+		"code":    "PROFILE_UPDATED",
 		"user":    user.ToResponse(),
 	})
 }
 
+// ChangePassword handles changing the password of the authenticated user
+func (uh *UserHandler) ChangePassword(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "USER_NOT_AUTHENTICATED")
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST")
+		return
+	}
+
+	// Validate request
+	if err := uh.validator.Struct(req); err != nil {
+		respondValidationError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := uh.userRepo.GetByID(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "USER_NOT_FOUND")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	// Google accounts have no password to change
+	if user.Type != "credential" {
+		respondErrorWithMessage(c, http.StatusBadRequest, "ACCOUNT_TYPE_MISMATCH", "ACCOUNT_TYPE_MISMATCH_HINT")
+		return
+	}
+
+	if err := uh.passwordService.VerifyPassword(user.PasswordHash, req.CurrentPassword); err != nil {
+		respondError(c, http.StatusUnauthorized, "CURRENT_PASSWORD_INVALID")
+		return
+	}
+
+	// Enforce password policy
+	if violations := uh.passwordPolicy.Validate(req.NewPassword, user.Username, user.Email); len(violations) > 0 {
+		respondPasswordPolicyError(c, violations)
+		return
+	}
+
+	hashedPassword, err := uh.passwordService.HashPassword(req.NewPassword)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "NEW_PASSWORD_PROCESSING_FAILED")
+		return
+	}
+
+	if err := uh.userRepo.UpdatePassword(user, hashedPassword); err != nil {
+		respondError(c, http.StatusInternalServerError, "PASSWORD_UPDATE_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": localize(c, "PASSWORD_CHANGED"),
+		"code":    "PASSWORD_CHANGED",
+	})
+}
+
 // RefreshToken handles token refresh
 func (uh *UserHandler) RefreshToken(c *gin.Context) {
 	var req struct {
@@ -441,32 +696,69 @@ func (uh *UserHandler) RefreshToken(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST")
 		return
 	}
 
 	// Validate refresh token
 	claims, err := uh.JWTService.ValidateToken(req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		respondError(c, http.StatusUnauthorized, "INVALID_REFRESH_TOKEN")
 		return
 	}
 
 	// Find user
-	var user models.User
-	if err := uh.db.Where("id = ?", claims.UserID).First(&user).Error; err != nil {
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "INVALID_REFRESH_TOKEN")
+		return
+	}
+
+	user, err := uh.userRepo.GetByID(userID)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			respondError(c, http.StatusNotFound, "USER_NOT_FOUND")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+
+	// Reject refresh tokens issued before the user signed out everywhere
+	if user.SessionsRevokedAt != nil && (claims.IssuedAt == nil || claims.IssuedAt.Unix() < user.SessionsRevokedAt.Unix()) {
+		respondError(c, http.StatusUnauthorized, "SESSIONS_REVOKED")
+		return
+	}
+
+	// Only tokens on record are accepted, and only from the client they were issued to
+	storedToken, err := uh.refreshTokenRepo.Get(req.RefreshToken)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusUnauthorized, "INVALID_REFRESH_TOKEN")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
+		return
+	}
+	if !storedToken.IsUsable() || storedToken.UserID != user.ID {
+		respondError(c, http.StatusUnauthorized, "INVALID_REFRESH_TOKEN")
+		return
+	}
+	if !uh.clientMatches(c, user, storedToken) {
+		respondError(c, http.StatusUnauthorized, "REFRESH_TOKEN_CLIENT_MISMATCH")
+		return
+	}
+
+	// Rotate: the presented token is spent, failing if it was used concurrently
+	if err := uh.refreshTokenRepo.Revoke(storedToken); err != nil {
+		respondError(c, http.StatusUnauthorized, "INVALID_REFRESH_TOKEN")
 		return
 	}
 
 	// Generate new tokens
-	authResponse, err := uh.JWTService.GenerateTokens(&user)
+	authResponse, err := uh.issueTokens(c, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		respondError(c, http.StatusInternalServerError, "TOKEN_GENERATION_FAILED")
 		return
 	}
 
@@ -477,55 +769,61 @@ func (uh *UserHandler) RefreshToken(c *gin.Context) {
 func (uh *UserHandler) RequestResetPassword(c *gin.Context) {
 	var req models.ResetPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST")
 		return
 	}
 
 	// Validate request
 	if err := uh.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondValidationError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// Throttle per email and IP before the lookup, so limits don't reveal registered emails
+	throttle := uh.sendThrottle.Allow(c.Request.Context(), services.ThrottleResetPassword, req.Email, c.ClientIP())
+	if !throttle.Allowed {
+		respondSendThrottled(c, throttle)
 		return
 	}
 
 	// Find user by email
-	var user models.User
-	if err := uh.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
+	user, err := uh.userRepo.GetByEmail(req.Email)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			// Don't reveal if email exists or not for security
 			c.JSON(http.StatusOK, gin.H{
-				"message": "If the email exists, a reset code has been sent.",
+				"message":  localize(c, "RESET_CODE_SENT"),
+				"code":     "RESET_CODE_SENT",
+				"cooldown": uh.sendCooldown(throttle),
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
 		return
 	}
 
 	// Check if user is verified
 	if !user.IsVerified {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Account not verified. Please verify your email first."})
+		respondError(c, http.StatusBadRequest, "ACCOUNT_NOT_VERIFIED")
 		return
 	}
 
 	// Generate OTP for password reset
 	otp, err := uh.otpService.GenerateOTP()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate reset code"})
+		respondError(c, http.StatusInternalServerError, "RESET_CODE_GENERATION_FAILED")
 		return
 	}
 
 	// Update user with reset OTP
-	user.OTPCode = &otp
-	user.UpdatedAt = time.Now()
-
-	if err := uh.db.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate reset code"})
+	if err := uh.userRepo.UpdateOTP(user, &otp); err != nil {
+		respondError(c, http.StatusInternalServerError, "RESET_CODE_GENERATION_FAILED")
 		return
 	}
 
 	// Publish password reset event to message broker
 	if uh.eventService != nil {
-		if err := uh.eventService.PublishPasswordReset(user.ID.String(), user.Username, user.Email); err != nil {
+		if err := uh.eventService.PublishPasswordReset(user.ID.String(), user.Username, user.Email, user.Locale); err != nil {
 			log.Printf("⚠️ Failed to publish password reset event: %v", err)
 			// Don't fail the request if event publishing fails
 		} else {
@@ -534,7 +832,9 @@ func (uh *UserHandler) RequestResetPassword(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "If the email exists, a reset code has been sent.",
+		"message":  localize(c, "RESET_CODE_SENT"),
+		"code":     "RESET_CODE_SENT",
+		"cooldown": uh.sendCooldown(throttle),
 	})
 }
 
@@ -542,72 +842,82 @@ func (uh *UserHandler) RequestResetPassword(c *gin.Context) {
 func (uh *UserHandler) VerifyResetPassword(c *gin.Context) {
 	var req models.VerifyResetPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST")
 		return
 	}
 
 	// Validate request
 	if err := uh.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondValidationError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	// Validate OTP format
 	if !uh.otpService.ValidateOTP(req.OTPCode) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reset code format"})
+		respondError(c, http.StatusBadRequest, "INVALID_RESET_CODE_FORMAT")
+		return
+	}
+
+	// Reset codes share the OTP lockout, one lock covers both ways of guessing
+	ctx, ip := c.Request.Context(), c.ClientIP()
+	if lock := uh.verificationGuard.Locked(ctx, services.GuardVerifyResetCode, req.Email, ip); !lock.Allowed {
+		respondVerificationLocked(c, lock)
 		return
 	}
 
 	// Find user by email
-	var user models.User
-	if err := uh.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
+	user, err := uh.userRepo.GetByEmail(req.Email)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			uh.verificationGuard.Failed(ctx, services.GuardVerifyResetCode, req.Email, ip)
+			respondError(c, http.StatusNotFound, "USER_NOT_FOUND")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
 		return
 	}
 
 	// Check if user is verified
 	if !user.IsVerified {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Account not verified. Please verify your email first."})
+		respondError(c, http.StatusBadRequest, "ACCOUNT_NOT_VERIFIED")
 		return
 	}
 
 	// Verify OTP
 	if user.OTPCode == nil || *user.OTPCode != req.OTPCode {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reset code"})
+		respondInvalidCode(c, "INVALID_RESET_CODE", uh.verificationGuard.Failed(ctx, services.GuardVerifyResetCode, req.Email, ip))
+		return
+	}
+
+	// Enforce password policy
+	if violations := uh.passwordPolicy.Validate(req.NewPassword, user.Username, user.Email); len(violations) > 0 {
+		respondPasswordPolicyError(c, violations)
 		return
 	}
 
 	// Hash new password
 	hashedPassword, err := uh.passwordService.HashPassword(req.NewPassword)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process new password"})
+		respondError(c, http.StatusInternalServerError, "NEW_PASSWORD_PROCESSING_FAILED")
 		return
 	}
 
 	// Update user password and clear OTP
-	user.PasswordHash = hashedPassword
-	user.OTPCode = nil
-	user.UpdatedAt = time.Now()
-
-	if err := uh.db.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+	if err := uh.userRepo.UpdatePassword(user, hashedPassword); err != nil {
+		respondError(c, http.StatusInternalServerError, "PASSWORD_UPDATE_FAILED")
 		return
 	}
 
 	// Generate new tokens after successful password reset
-	authResponse, err := uh.JWTService.GenerateTokens(&user)
+	authResponse, err := uh.issueTokens(c, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		respondError(c, http.StatusInternalServerError, "TOKEN_GENERATION_FAILED")
 		return
 	}
 
 	// Publish password reset success event
 	if uh.eventService != nil {
-		if err := uh.eventService.PublishPasswordResetSuccess(user.ID.String(), user.Username, user.Email); err != nil {
+		if err := uh.eventService.PublishPasswordResetSuccess(user.ID.String(), user.Username, user.Email, user.Locale); err != nil {
 			log.Printf("⚠️ Failed to publish password reset success event: %v", err)
 			// Don't fail the request if event publishing fails
 		} else {
@@ -616,7 +926,8 @@ func (uh *UserHandler) VerifyResetPassword(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Password reset successfully",
+		"message": localize(c, "PASSWORD_RESET_SUCCESS"),
+		"code":    "PASSWORD_RESET_SUCCESS",
 		"user":    user.ToResponse(),
 		"access_token": authResponse.AccessToken,
 		"refresh_token": authResponse.RefreshToken,
@@ -634,61 +945,63 @@ func (uh *UserHandler) GoogleOAuth(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST")
 		return
 	}
 
 	// Validate request
 	if err := uh.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondValidationError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	// Check if user already exists by email
-	var user models.User
-	err := uh.db.Where("email = ?", req.Email).First(&user).Error
-	
+	user, err := uh.userRepo.GetByEmail(req.Email)
+
 	if err == gorm.ErrRecordNotFound {
 		// Create new user
-		user = models.User{
+		user = &models.User{
 			Username:   req.Username,
 			Email:      req.Email,
 			ImageUrl:   &req.ImageUrl,
 			Type:       "google",
 			IsVerified: true, // Google users are automatically verified
+			Locale:     string(i18n.FromContext(c)),
 		}
 		
-		if err := uh.db.Create(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		if err := uh.userRepo.Create(user); err != nil {
+			respondError(c, http.StatusInternalServerError, "USER_CREATE_FAILED")
 			return
 		}
 	} else if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		respondError(c, http.StatusInternalServerError, "DATABASE_ERROR")
 		return
 	} else {
 		// Check if existing user is credential type
 		if user.Type == "credential" {
-			c.JSON(http.StatusConflict, gin.H{"error": "This email is already registered with credentials. Please use email/password login instead."})
+			respondError(c, http.StatusConflict, "CREDENTIAL_ACCOUNT_EXISTS")
 			return
 		}
 		
 		// Update existing Google user with new info
 		user.ImageUrl = &req.ImageUrl
 		user.IsVerified = true // Ensure Google users are verified
-		user.UpdatedAt = time.Now()
-		
-		if err := uh.db.Save(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+
+		if err := uh.userRepo.Update(user); err != nil {
+			respondError(c, http.StatusInternalServerError, "USER_UPDATE_FAILED")
 			return
 		}
 	}
 
 	// Generate tokens
-	authResponse, err := uh.JWTService.GenerateTokens(&user)
+	authResponse, err := uh.issueTokens(c, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		respondError(c, http.StatusInternalServerError, "TOKEN_GENERATION_FAILED")
 		return
 	}
 
+	// Alert the user about logins from new devices
+	uh.recordLogin(c, user)
+
 	c.JSON(http.StatusOK, authResponse)
 }
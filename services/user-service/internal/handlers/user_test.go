@@ -0,0 +1,158 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"user-service/internal/handlers"
+	"user-service/internal/i18n"
+	"user-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testPassword = "s3cret-Password"
+
+// newLoginRouter serves POST /login from an in-memory database holding the credential user
+// Alice (alice / Alice@Example.com) and returns the router with the user
+func newLoginRouter(t *testing.T) (*gin.Engine, *models.User) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	output := log.Writer()
+	log.SetOutput(io.Discard) // the handler logs .env lookups and login alerts
+	t.Cleanup(func() { log.SetOutput(output) })
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1) // every connection would get its own in-memory database
+	t.Cleanup(func() { sqlDB.Close() })
+
+	// SQLite has no gen_random_uuid(); User's BeforeCreate sets its ID, the one login of a
+	// test stores its device and refresh token with the zero ID
+	tables := []interface{}{&models.User{}, &models.LoginDevice{}, &models.RefreshToken{}}
+	for _, table := range tables {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(table); err != nil {
+			t.Fatalf("failed to parse %T: %v", table, err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DefaultValue == "gen_random_uuid()" {
+				field.HasDefaultValue = false
+				field.DefaultValue = ""
+			}
+		}
+	}
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	hash, err := models.NewPasswordService().HashPassword(testPassword)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	user := &models.User{Username: "alice", Email: "Alice@Example.com", PasswordHash: hash, Type: "credential", IsVerified: true}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+
+	handler := handlers.NewUserHandler(db, nil)
+	router := gin.New()
+	router.Use(i18n.Middleware())
+	router.POST("/login", handler.Login)
+	return router, user
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       map[string]string
+		language   string
+		wantStatus int
+		wantCode   string // of failed logins
+		wantKey    string // message catalog key of failed logins
+	}{
+		{name: "email", body: map[string]string{"identifier": "Alice@Example.com", "password": testPassword}, wantStatus: http.StatusOK},
+		{name: "email in another case", body: map[string]string{"identifier": "ALICE@example.COM", "password": testPassword}, wantStatus: http.StatusOK},
+		{name: "username", body: map[string]string{"identifier": "alice", "password": testPassword}, wantStatus: http.StatusOK},
+		{name: "username in another case", body: map[string]string{"identifier": " Alice ", "password": testPassword}, wantStatus: http.StatusOK},
+		{name: "email field of older clients", body: map[string]string{"email": "alice@example.com", "password": testPassword}, wantStatus: http.StatusOK},
+		{
+			name:       "unknown email",
+			body:       map[string]string{"identifier": "bob@example.com", "password": testPassword},
+			language:   "en",
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "USER_NOT_FOUND",
+			wantKey:    "EMAIL_NOT_REGISTERED",
+		},
+		{
+			name:       "unknown username",
+			body:       map[string]string{"identifier": "bob", "password": testPassword},
+			language:   "id",
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "USER_NOT_FOUND",
+			wantKey:    "USERNAME_NOT_REGISTERED",
+		},
+		{
+			name:       "wrong password",
+			body:       map[string]string{"identifier": "alice", "password": "wrong-password"},
+			language:   "en",
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "INVALID_PASSWORD",
+			wantKey:    "INVALID_PASSWORD_HINT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, user := newLoginRouter(t)
+
+			payload, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(payload))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.language != "" {
+				req.Header.Set("Accept-Language", tt.language)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Login = %d %s, want %d", rec.Code, rec.Body, tt.wantStatus)
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				var answer models.AuthResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &answer); err != nil {
+					t.Fatalf("invalid response %s: %v", rec.Body, err)
+				}
+				if answer.User.ID != user.ID || answer.AccessToken == "" || answer.RefreshToken == "" {
+					t.Errorf("Login = %s, want tokens of %s", rec.Body, user.ID)
+				}
+				return
+			}
+
+			var answer struct {
+				Message string `json:"message"`
+				Code    string `json:"code"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &answer); err != nil {
+				t.Fatalf("invalid response %s: %v", rec.Body, err)
+			}
+			wantMessage := i18n.T(i18n.Locale(tt.language), tt.wantKey)
+			if answer.Code != tt.wantCode || answer.Message != wantMessage {
+				t.Errorf("Login = %+v, want code %s with message %q", answer, tt.wantCode, wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,447 @@
+package i18n
+
+// catalogs holds the translated messages for every supported locale.
+// API message keys are the error/response codes returned to clients,
+// email keys are namespaced with "email.".
+var catalogs = map[Locale]map[string]string{
+	LocaleEN: {
+		// Generic errors
+		"INVALID_REQUEST":        "Invalid request format",
+		"VALIDATION_FAILED":      "Validation failed",
+		"DATABASE_ERROR":         "Database error",
+		"USER_NOT_AUTHENTICATED": "User not authenticated",
+		"AUTH_HEADER_REQUIRED":   "Authorization header required",
+		"INVALID_TOKEN":          "Invalid token",
+
+		// Registration
+		"USER_ALREADY_EXISTS":        "User with this email or username already exists",
+		"PASSWORD_PROCESSING_FAILED": "Failed to process password",
+		"OTP_GENERATION_FAILED":      "Failed to generate OTP",
+		"USER_CREATE_FAILED":         "Failed to create user",
+		"USER_UPDATE_FAILED":         "Failed to update user",
+		"REGISTER_SUCCESS":           "User registered successfully. Please check your email for verification code.",
+
+		// Login
+		"USER_NOT_FOUND":                "User not found",
+		"EMAIL_NOT_REGISTERED":          "Email is not registered. Please check your email or create a new account.",
+		"USERNAME_NOT_REGISTERED":       "No account uses this username. Please check it, log in with your email or create a new account.",
+		"ACCOUNT_TYPE_MISMATCH":         "Account type mismatch",
+		"ACCOUNT_TYPE_MISMATCH_HINT":    "This account was created with Google. Please use the 'Sign in with Google' button to log in.",
+		"INVALID_PASSWORD":              "Invalid password",
+		"INVALID_PASSWORD_HINT":         "The password you entered is incorrect. Please try again.",
+		"TOKEN_GENERATION_FAILED":       "Failed to generate tokens",
+		"INVALID_REFRESH_TOKEN":         "Invalid refresh token",
+		"SESSIONS_REVOKED":              "Your sessions were signed out, please log in again",
+		"REFRESH_TOKEN_CLIENT_MISMATCH": "This session was started on another device, please log in again",
+		"CREDENTIAL_ACCOUNT_EXISTS":     "This email is already registered with credentials. Please use email/password login instead.",
+
+		// OTP verification
+		"INVALID_OTP_FORMAT":    "Invalid OTP format",
+		"INVALID_OTP":           "Invalid OTP",
+		"USER_ALREADY_VERIFIED": "User is already verified",
+		"VERIFICATION_FAILED":   "Failed to verify user",
+		"OTP_UPDATE_FAILED":     "Failed to update OTP",
+		"OTP_SENT":              "OTP sent successfully. Please check your email.",
+
+		// Profile
+		"USERNAME_TAKEN":        "Username already taken",
+		"PROFILE_UPDATE_FAILED": "Failed to update profile",
+		"PROFILE_UPDATED":       "Profile updated successfully",
+
+		// Password reset
+		"ACCOUNT_NOT_VERIFIED":           "Account not verified. Please verify your email first.",
+		"RESET_CODE_GENERATION_FAILED":   "Failed to generate reset code",
+		"RESET_CODE_SENT":                "If the email exists, a reset code has been sent.",
+		"INVALID_RESET_CODE_FORMAT":      "Invalid reset code format",
+		"INVALID_RESET_CODE":             "Invalid reset code",
+		"NEW_PASSWORD_PROCESSING_FAILED": "Failed to process new password",
+		"PASSWORD_UPDATE_FAILED":         "Failed to update password",
+		"PASSWORD_RESET_SUCCESS":         "Password reset successfully",
+
+		// Username policy and availability
+		"INVALID_USERNAME":         "Username does not meet the requirements",
+		"USERNAME_AVAILABLE":       "Username is available",
+		"INVALID_EMAIL":            "Invalid email address",
+		"EMAIL_AVAILABLE":          "Email is available",
+		"EMAIL_TAKEN":              "Email is already registered",
+		"TOO_MANY_REQUESTS":        "Too many requests, please try again later",
+		"SEND_LIMIT_REACHED":       "Too many codes requested, please try again in %d seconds",
+		"VERIFICATION_LOCKED":      "Too many incorrect codes, please try again in %d seconds",
+		"CAPTCHA_REQUIRED":         "Please complete the CAPTCHA to continue",
+		"CAPTCHA_INVALID":          "CAPTCHA verification failed, please try again",
+		"username.rule.min_length": "Username must be at least %d characters long",
+		"username.rule.max_length": "Username must be at most %d characters long",
+		"username.rule.characters": "Username may only contain letters, numbers, dots, underscores and hyphens, and must start and end with a letter or number",
+		"username.rule.reserved":   "This username is reserved",
+
+		// Password policy
+		"WEAK_PASSWORD":                 "Password does not meet the security requirements",
+		"CURRENT_PASSWORD_INVALID":      "Current password is incorrect",
+		"PASSWORD_CHANGED":              "Password changed successfully",
+		"password.rule.min_length":      "Password must be at least %d characters long",
+		"password.rule.uppercase":       "Password must contain an uppercase letter",
+		"password.rule.lowercase":       "Password must contain a lowercase letter",
+		"password.rule.digit":           "Password must contain a number",
+		"password.rule.symbol":          "Password must contain a symbol",
+		"password.rule.common_password": "Password is too common",
+		"password.rule.personal_info":   "Password must not contain your username or email",
+		"password.rule.breached":        "Password has appeared in a data breach, please choose another one",
+
+		// Phone number
+		"INVALID_PHONE_NUMBER":        "Invalid phone number",
+		"PHONE_NUMBER_TAKEN":          "Phone number is already used by another account",
+		"PHONE_ALREADY_VERIFIED":      "This phone number is already verified on your account",
+		"PHONE_OTP_SENT":              "Verification code sent to your phone",
+		"SMS_SEND_FAILED":             "Failed to send verification code, please try again later",
+		"NO_PENDING_PHONE":            "No phone number is awaiting verification",
+		"PHONE_OTP_EXPIRED":           "Verification code has expired, please request a new one",
+		"PHONE_OTP_ATTEMPTS_EXCEEDED": "Too many incorrect codes, please request a new one",
+		"PHONE_VERIFIED":              "Phone number verified successfully",
+		"PHONE_REMOVED":               "Phone number removed",
+		"sms.phone_otp":               "Your ZACloth verification code is %s. It expires in %d minutes. Do not share this code with anyone.",
+
+		// Emails
+		"email.signoff":         "Thank you,<br>The ZACloth Team",
+		"email.footer":          "This email was sent automatically, please do not reply.",
+		"email.greeting":        "Hi %s!",
+		"email.datetime_layout": "January 02, 2006 15:04 MST",
+
+		"email.otp.subject":     "Email Verification - ZACloth",
+		"email.otp.heading":     "🎉 Welcome to ZACloth!",
+		"email.otp.intro":       "Thank you for signing up at ZACloth. To complete your registration, please verify your email with the following OTP code:",
+		"email.otp.validity":    "This code is valid for 10 minutes.",
+		"email.otp.ignore":      "If you did not sign up at ZACloth, please ignore this email.",
+		"email.otp.link_intro":  "Or verify your email with one click:",
+		"email.otp.link_button": "Verify Email",
+
+		"email.welcome.subject":       "Congratulations! Your Account Has Been Verified - ZACloth",
+		"email.welcome.heading":       "🎉 Welcome to ZACloth!",
+		"email.welcome.intro":         "Congratulations! Your email has been verified. Your ZACloth account is now active and ready to use.",
+		"email.welcome.features":      "You can now:",
+		"email.welcome.feature1":      "✅ Log in to your account",
+		"email.welcome.feature2":      "🛍️ Shop the latest products",
+		"email.welcome.feature3":      "💳 Manage your profile and preferences",
+		"email.welcome.feature4":      "📱 Access all ZACloth features",
+		"email.welcome.closing":       "Thank you for joining ZACloth!",
+		"email.reset.subject":         "Reset Password - ZACloth",
+		"email.reset.heading":         "🔐 Reset Password - ZACloth",
+		"email.reset.intro":           "We received a request to reset your ZACloth account password. Use the following verification code to continue:",
+		"email.reset.important":       "⚠️ Important:",
+		"email.reset.note1":           "This code is valid for 10 minutes",
+		"email.reset.note2":           "Do not share this code with anyone",
+		"email.reset.note3":           "If you did not request a password reset, ignore this email",
+		"email.reset.ignore":          "If you did not request a password reset, please ignore this email and your password will remain safe.",
+		"email.reset_success.subject": "Password Reset Successfully - ZACloth",
+		"email.reset_success.heading": "✅ Password Reset Successfully!",
+		"email.reset_success.intro":   "Your ZACloth account password was reset on %s.",
+		"email.reset_success.confirm": "✅ Confirmation:",
+		"email.reset_success.note1":   "Your new password is now active",
+		"email.reset_success.note2":   "You have been logged in automatically",
+		"email.reset_success.note3":   "All previous sessions have been ended",
+		"email.reset_success.support": "If you did not perform this password reset, contact our support team immediately.",
+
+		"email.reminder.subject":  "Verify Your Email to Keep Your Account - ZACloth",
+		"email.reminder.heading":  "⏰ Your Account Is Waiting for Verification",
+		"email.reminder.intro":    "You signed up at ZACloth but have not verified your email yet.",
+		"email.reminder.deadline": "Unverified accounts are removed automatically. Verify before %s to keep yours.",
+		"email.reminder.button":   "Verify Email",
+		"email.reminder.ignore":   "If you did not sign up at ZACloth, ignore this email and the account will be removed.",
+
+		"email.login_alert.subject":   "New Login to Your Account - ZACloth",
+		"email.login_alert.heading":   "🔔 New Login Detected",
+		"email.login_alert.intro":     "Your ZACloth account was just signed in to from a new device or location:",
+		"email.login_alert.time":      "Time: %s",
+		"email.login_alert.ip":        "IP address: %s",
+		"email.login_alert.device":    "Device: %s",
+		"email.login_alert.if_you":    "If this was you, you don't need to do anything.",
+		"email.login_alert.if_not":    "If this wasn't you, sign out of every session and change your password right away:",
+		"email.login_alert.button":    "Sign Out Everywhere",
+		"email.login_alert.configure": "You can turn off these alerts in your profile settings.",
+
+		"email.order.id":       "Order ID: %s",
+		"email.order.product":  "Product: %s",
+		"email.order.quantity": "Quantity: %d",
+		"email.order.amount":   "Total: %s",
+		"email.order.method":   "Payment method: %s",
+		"email.order.va":       "Virtual account: %s",
+		"email.order.paid_at":  "Paid at: %s",
+		"email.order.due_date": "Due date: %s",
+
+		"email.receipt.subject": "Payment Received for Order %s - ZACloth",
+		"email.receipt.heading": "🧾 Payment Received",
+		"email.receipt.intro":   "Thank you for your purchase! We have received your payment, here is your order summary:",
+		"email.receipt.closing": "Keep this email as your proof of payment.",
+
+		"email.sale.subject": "You Made a Sale! Order %s - ZACloth",
+		"email.sale.heading": "🎉 You Made a Sale!",
+		"email.sale.intro":   "Good news, a buyer has paid for your product:",
+		"email.sale.closing": "Please prepare the order for shipping as soon as possible.",
+
+		"email.invoice_due.subject": "Invoice %s Is Due Soon - ZACloth",
+		"email.invoice_due.heading": "🧾 Invoice Due Soon",
+		"email.invoice_due.intro":   "This is a reminder that the following invoice is due soon:",
+		"email.invoice_due.closing": "Please pay before the due date. You can download the invoice from your payment history.",
+
+		"email.invoice_overdue.subject": "Invoice %s Is Overdue - ZACloth",
+		"email.invoice_overdue.heading": "⏰ Invoice Overdue",
+		"email.invoice_overdue.intro":   "The following invoice is past its due date and has not been paid yet:",
+		"email.invoice_overdue.closing": "Please pay it as soon as possible. If you have already paid, you can ignore this email.",
+
+		"email.campaign.preferences": "You receive ZACloth announcements because they are enabled in your profile settings, where you can turn them off.",
+
+		// Admin
+		"INVALID_USER_ID": "Invalid user ID format",
+
+		// Campaigns
+		"INVALID_REQUEST_BODY":   "Invalid request body",
+		"INVALID_BUYERS_RANGE":   "buyers_to must be after buyers_from",
+		"CAMPAIGN_CREATE_FAILED": "Failed to create campaign",
+		"INVALID_CAMPAIGN_ID":    "Invalid campaign ID format",
+		"CAMPAIGN_NOT_FOUND":     "Campaign not found",
+		"CAMPAIGN_NOT_SENDING":   "Campaign not found or no longer sending",
+
+		// Email logs
+		"INVALID_EMAIL_LOG_ID":      "Invalid email log ID format",
+		"EMAIL_LOG_NOT_FOUND":       "Email log not found",
+		"EMAIL_LOG_WITHOUT_USER":    "Email log is not linked to a user",
+		"EMAIL_SUPPRESSED":          "Email is on the suppression list",
+		"EVENT_SERVICE_UNAVAILABLE": "Event service not available",
+		"NO_ACTIVE_CODE":            "No active code for this user, ask the user to request a new one",
+		"UNSUPPORTED_EMAIL_TYPE":    "Unsupported email type",
+		"EMAIL_QUEUE_FAILED":        "Failed to queue email",
+
+		// Email suppressions
+		"INVALID_WEBHOOK_CREDENTIALS": "Invalid webhook credentials",
+		"REQUEST_BODY_READ_FAILED":    "Failed to read request body",
+		"SUBSCRIPTION_CONFIRM_FAILED": "Failed to confirm subscription",
+		"UNSUPPORTED_EMAIL_PROVIDER":  "Unsupported email provider",
+		"INVALID_WEBHOOK_PAYLOAD":     "Invalid webhook payload",
+		"EMAIL_NOT_SUPPRESSED":        "Email is not suppressed",
+
+		// Sandbox
+		"FIXTURES_CREATE_FAILED": "Failed to create fixtures",
+
+		// Authentication
+		"SERVICE_AUTH_REQUIRED": "Service authentication required",
+	},
+	LocaleID: {
+		// Generic errors
+		"INVALID_REQUEST":        "Format permintaan tidak valid",
+		"VALIDATION_FAILED":      "Validasi gagal",
+		"DATABASE_ERROR":         "Terjadi kesalahan pada database",
+		"USER_NOT_AUTHENTICATED": "Pengguna belum terautentikasi",
+		"AUTH_HEADER_REQUIRED":   "Header Authorization wajib diisi",
+		"INVALID_TOKEN":          "Token tidak valid",
+
+		// Registration
+		"USER_ALREADY_EXISTS":        "Pengguna dengan email atau username ini sudah terdaftar",
+		"PASSWORD_PROCESSING_FAILED": "Gagal memproses password",
+		"OTP_GENERATION_FAILED":      "Gagal membuat kode OTP",
+		"USER_CREATE_FAILED":         "Gagal membuat pengguna",
+		"USER_UPDATE_FAILED":         "Gagal memperbarui pengguna",
+		"REGISTER_SUCCESS":           "Registrasi berhasil. Silakan cek email Anda untuk kode verifikasi.",
+
+		// Login
+		"USER_NOT_FOUND":                "Pengguna tidak ditemukan",
+		"EMAIL_NOT_REGISTERED":          "Email tidak terdaftar. Silakan periksa kembali email Anda atau daftar akun baru.",
+		"USERNAME_NOT_REGISTERED":       "Tidak ada akun dengan username ini. Silakan periksa kembali, masuk dengan email Anda atau daftar akun baru.",
+		"ACCOUNT_TYPE_MISMATCH":         "Tipe akun tidak sesuai",
+		"ACCOUNT_TYPE_MISMATCH_HINT":    "Akun ini dibuat dengan Google. Silakan gunakan tombol 'Masuk dengan Google' untuk login.",
+		"INVALID_PASSWORD":              "Password salah",
+		"INVALID_PASSWORD_HINT":         "Password yang Anda masukkan salah. Silakan coba lagi.",
+		"TOKEN_GENERATION_FAILED":       "Gagal membuat token",
+		"INVALID_REFRESH_TOKEN":         "Refresh token tidak valid",
+		"SESSIONS_REVOKED":              "Sesi Anda telah dikeluarkan, silakan login kembali",
+		"REFRESH_TOKEN_CLIENT_MISMATCH": "Sesi ini dimulai di perangkat lain, silakan login kembali",
+		"CREDENTIAL_ACCOUNT_EXISTS":     "Email ini sudah terdaftar dengan password. Silakan login menggunakan email dan password.",
+
+		// OTP verification
+		"INVALID_OTP_FORMAT":    "Format OTP tidak valid",
+		"INVALID_OTP":           "Kode OTP salah",
+		"USER_ALREADY_VERIFIED": "Pengguna sudah terverifikasi",
+		"VERIFICATION_FAILED":   "Gagal memverifikasi pengguna",
+		"OTP_UPDATE_FAILED":     "Gagal memperbarui kode OTP",
+		"OTP_SENT":              "Kode OTP berhasil dikirim. Silakan cek email Anda.",
+
+		// Profile
+		"USERNAME_TAKEN":        "Username sudah digunakan",
+		"PROFILE_UPDATE_FAILED": "Gagal memperbarui profil",
+		"PROFILE_UPDATED":       "Profil berhasil diperbarui",
+
+		// Password reset
+		"ACCOUNT_NOT_VERIFIED":           "Akun belum terverifikasi. Silakan verifikasi email Anda terlebih dahulu.",
+		"RESET_CODE_GENERATION_FAILED":   "Gagal membuat kode reset",
+		"RESET_CODE_SENT":                "Jika email terdaftar, kode reset telah dikirim.",
+		"INVALID_RESET_CODE_FORMAT":      "Format kode reset tidak valid",
+		"INVALID_RESET_CODE":             "Kode reset salah",
+		"NEW_PASSWORD_PROCESSING_FAILED": "Gagal memproses password baru",
+		"PASSWORD_UPDATE_FAILED":         "Gagal memperbarui password",
+		"PASSWORD_RESET_SUCCESS":         "Password berhasil direset",
+
+		// Username policy and availability
+		"INVALID_USERNAME":         "Username tidak memenuhi persyaratan",
+		"USERNAME_AVAILABLE":       "Username tersedia",
+		"INVALID_EMAIL":            "Alamat email tidak valid",
+		"EMAIL_AVAILABLE":          "Email tersedia",
+		"EMAIL_TAKEN":              "Email sudah terdaftar",
+		"TOO_MANY_REQUESTS":        "Terlalu banyak permintaan, silakan coba lagi nanti",
+		"SEND_LIMIT_REACHED":       "Terlalu banyak permintaan kode, silakan coba lagi dalam %d detik",
+		"VERIFICATION_LOCKED":      "Terlalu banyak kode yang salah, silakan coba lagi dalam %d detik",
+		"CAPTCHA_REQUIRED":         "Silakan selesaikan CAPTCHA untuk melanjutkan",
+		"CAPTCHA_INVALID":          "Verifikasi CAPTCHA gagal, silakan coba lagi",
+		"username.rule.min_length": "Username minimal %d karakter",
+		"username.rule.max_length": "Username maksimal %d karakter",
+		"username.rule.characters": "Username hanya boleh berisi huruf, angka, titik, garis bawah dan tanda hubung, serta diawali dan diakhiri huruf atau angka",
+		"username.rule.reserved":   "Username ini tidak dapat digunakan",
+
+		// Password policy
+		"WEAK_PASSWORD":                 "Password tidak memenuhi persyaratan keamanan",
+		"CURRENT_PASSWORD_INVALID":      "Password saat ini salah",
+		"PASSWORD_CHANGED":              "Password berhasil diubah",
+		"password.rule.min_length":      "Password minimal %d karakter",
+		"password.rule.uppercase":       "Password harus mengandung huruf besar",
+		"password.rule.lowercase":       "Password harus mengandung huruf kecil",
+		"password.rule.digit":           "Password harus mengandung angka",
+		"password.rule.symbol":          "Password harus mengandung simbol",
+		"password.rule.common_password": "Password terlalu umum",
+		"password.rule.personal_info":   "Password tidak boleh mengandung username atau email Anda",
+		"password.rule.breached":        "Password ini pernah bocor dalam insiden keamanan, silakan pilih password lain",
+
+		// Phone number
+		"INVALID_PHONE_NUMBER":        "Nomor telepon tidak valid",
+		"PHONE_NUMBER_TAKEN":          "Nomor telepon sudah digunakan akun lain",
+		"PHONE_ALREADY_VERIFIED":      "Nomor telepon ini sudah terverifikasi di akun Anda",
+		"PHONE_OTP_SENT":              "Kode verifikasi telah dikirim ke nomor telepon Anda",
+		"SMS_SEND_FAILED":             "Gagal mengirim kode verifikasi, silakan coba lagi nanti",
+		"NO_PENDING_PHONE":            "Tidak ada nomor telepon yang menunggu verifikasi",
+		"PHONE_OTP_EXPIRED":           "Kode verifikasi sudah kedaluwarsa, silakan minta kode baru",
+		"PHONE_OTP_ATTEMPTS_EXCEEDED": "Terlalu banyak kode yang salah, silakan minta kode baru",
+		"PHONE_VERIFIED":              "Nomor telepon berhasil diverifikasi",
+		"PHONE_REMOVED":               "Nomor telepon dihapus",
+		"sms.phone_otp":               "Kode verifikasi ZACloth Anda adalah %s. Berlaku selama %d menit. Jangan berikan kode ini kepada siapa pun.",
+
+		// Emails
+		"email.signoff":         "Terima kasih,<br>Tim ZACloth",
+		"email.footer":          "Email ini dikirim secara otomatis, mohon tidak membalas email ini.",
+		"email.greeting":        "Halo %s!",
+		"email.datetime_layout": "02-01-2006 15:04 MST",
+
+		"email.otp.subject":     "Verifikasi Email - ZACloth",
+		"email.otp.heading":     "🎉 Selamat Datang di ZACloth!",
+		"email.otp.intro":       "Terima kasih telah mendaftar di ZACloth. Untuk melengkapi proses pendaftaran, silakan verifikasi email Anda dengan kode OTP berikut:",
+		"email.otp.validity":    "Kode ini berlaku selama 10 menit.",
+		"email.otp.ignore":      "Jika Anda tidak mendaftar di ZACloth, silakan abaikan email ini.",
+		"email.otp.link_intro":  "Atau verifikasi email Anda dengan satu klik:",
+		"email.otp.link_button": "Verifikasi Email",
+
+		"email.welcome.subject":       "Selamat! Akun Anda Telah Terverifikasi - ZACloth",
+		"email.welcome.heading":       "🎉 Selamat Datang di ZACloth!",
+		"email.welcome.intro":         "Selamat! Email Anda telah berhasil diverifikasi. Akun ZACloth Anda sekarang sudah aktif dan siap digunakan.",
+		"email.welcome.features":      "Anda sekarang dapat:",
+		"email.welcome.feature1":      "✅ Login ke akun Anda",
+		"email.welcome.feature2":      "🛍️ Berbelanja produk terbaru",
+		"email.welcome.feature3":      "💳 Mengelola profil dan preferensi",
+		"email.welcome.feature4":      "📱 Mengakses semua fitur ZACloth",
+		"email.welcome.closing":       "Terima kasih telah bergabung dengan ZACloth!",
+		"email.reset.subject":         "Reset Password - ZACloth",
+		"email.reset.heading":         "🔐 Reset Password - ZACloth",
+		"email.reset.intro":           "Kami menerima permintaan untuk mereset password akun ZACloth Anda. Gunakan kode verifikasi berikut untuk melanjutkan:",
+		"email.reset.important":       "⚠️ Penting:",
+		"email.reset.note1":           "Kode ini berlaku selama 10 menit",
+		"email.reset.note2":           "Jangan bagikan kode ini kepada siapa pun",
+		"email.reset.note3":           "Jika Anda tidak meminta reset password, abaikan email ini",
+		"email.reset.ignore":          "Jika Anda tidak meminta reset password, silakan abaikan email ini dan password Anda akan tetap aman.",
+		"email.reset_success.subject": "Password Berhasil Direset - ZACloth",
+		"email.reset_success.heading": "✅ Password Berhasil Direset!",
+		"email.reset_success.intro":   "Password akun ZACloth Anda telah berhasil direset pada %s.",
+		"email.reset_success.confirm": "✅ Konfirmasi:",
+		"email.reset_success.note1":   "Password baru Anda telah aktif",
+		"email.reset_success.note2":   "Anda telah otomatis login ke akun",
+		"email.reset_success.note3":   "Semua sesi sebelumnya telah diakhiri",
+		"email.reset_success.support": "Jika Anda tidak melakukan reset password ini, segera hubungi tim support kami.",
+
+		"email.reminder.subject":  "Verifikasi Email Anda agar Akun Tidak Dihapus - ZACloth",
+		"email.reminder.heading":  "⏰ Akun Anda Menunggu Verifikasi",
+		"email.reminder.intro":    "Anda telah mendaftar di ZACloth tetapi belum memverifikasi email Anda.",
+		"email.reminder.deadline": "Akun yang belum diverifikasi akan dihapus otomatis. Verifikasi sebelum %s agar akun Anda tetap aktif.",
+		"email.reminder.button":   "Verifikasi Email",
+		"email.reminder.ignore":   "Jika Anda tidak mendaftar di ZACloth, abaikan email ini dan akun tersebut akan dihapus.",
+
+		"email.login_alert.subject":   "Login Baru ke Akun Anda - ZACloth",
+		"email.login_alert.heading":   "🔔 Login Baru Terdeteksi",
+		"email.login_alert.intro":     "Akun ZACloth Anda baru saja digunakan untuk login dari perangkat atau lokasi baru:",
+		"email.login_alert.time":      "Waktu: %s",
+		"email.login_alert.ip":        "Alamat IP: %s",
+		"email.login_alert.device":    "Perangkat: %s",
+		"email.login_alert.if_you":    "Jika ini Anda, tidak ada yang perlu dilakukan.",
+		"email.login_alert.if_not":    "Jika ini bukan Anda, keluarkan semua sesi dan segera ganti password Anda:",
+		"email.login_alert.button":    "Keluar dari Semua Sesi",
+		"email.login_alert.configure": "Anda dapat menonaktifkan peringatan ini di pengaturan profil.",
+
+		"email.order.id":       "ID Pesanan: %s",
+		"email.order.product":  "Produk: %s",
+		"email.order.quantity": "Jumlah: %d",
+		"email.order.amount":   "Total: %s",
+		"email.order.method":   "Metode pembayaran: %s",
+		"email.order.va":       "Virtual account: %s",
+		"email.order.paid_at":  "Dibayar pada: %s",
+		"email.order.due_date": "Jatuh tempo: %s",
+
+		"email.receipt.subject": "Pembayaran Diterima untuk Pesanan %s - ZACloth",
+		"email.receipt.heading": "🧾 Pembayaran Diterima",
+		"email.receipt.intro":   "Terima kasih atas pembelian Anda! Pembayaran Anda telah kami terima, berikut ringkasan pesanan Anda:",
+		"email.receipt.closing": "Simpan email ini sebagai bukti pembayaran Anda.",
+
+		"email.sale.subject": "Produk Anda Terjual! Pesanan %s - ZACloth",
+		"email.sale.heading": "🎉 Produk Anda Terjual!",
+		"email.sale.intro":   "Kabar baik, pembeli telah membayar produk Anda:",
+		"email.sale.closing": "Segera siapkan pesanan untuk dikirim.",
+
+		"email.invoice_due.subject": "Tagihan %s Segera Jatuh Tempo - ZACloth",
+		"email.invoice_due.heading": "🧾 Tagihan Segera Jatuh Tempo",
+		"email.invoice_due.intro":   "Ini adalah pengingat bahwa tagihan berikut akan segera jatuh tempo:",
+		"email.invoice_due.closing": "Mohon lakukan pembayaran sebelum tanggal jatuh tempo. Tagihan dapat diunduh dari riwayat pembayaran Anda.",
+
+		"email.invoice_overdue.subject": "Tagihan %s Telah Jatuh Tempo - ZACloth",
+		"email.invoice_overdue.heading": "⏰ Tagihan Telah Jatuh Tempo",
+		"email.invoice_overdue.intro":   "Tagihan berikut telah melewati tanggal jatuh tempo dan belum dibayar:",
+		"email.invoice_overdue.closing": "Mohon segera lakukan pembayaran. Jika Anda sudah membayar, abaikan email ini.",
+
+		"email.campaign.preferences": "Anda menerima pengumuman ZACloth karena fitur ini aktif di pengaturan profil Anda, di sana Anda dapat menonaktifkannya.",
+
+		// Admin
+		"INVALID_USER_ID": "Format ID pengguna tidak valid",
+
+		// Campaigns
+		"INVALID_REQUEST_BODY":   "Body request tidak valid",
+		"INVALID_BUYERS_RANGE":   "buyers_to harus setelah buyers_from",
+		"CAMPAIGN_CREATE_FAILED": "Gagal membuat kampanye",
+		"INVALID_CAMPAIGN_ID":    "Format ID kampanye tidak valid",
+		"CAMPAIGN_NOT_FOUND":     "Kampanye tidak ditemukan",
+		"CAMPAIGN_NOT_SENDING":   "Kampanye tidak ditemukan atau sudah tidak mengirim",
+
+		// Email logs
+		"INVALID_EMAIL_LOG_ID":      "Format ID log email tidak valid",
+		"EMAIL_LOG_NOT_FOUND":       "Log email tidak ditemukan",
+		"EMAIL_LOG_WITHOUT_USER":    "Log email tidak terhubung ke pengguna",
+		"EMAIL_SUPPRESSED":          "Email ada di daftar suppression",
+		"EVENT_SERVICE_UNAVAILABLE": "Layanan event tidak tersedia",
+		"NO_ACTIVE_CODE":            "Pengguna ini tidak memiliki kode aktif, minta pengguna meminta kode baru",
+		"UNSUPPORTED_EMAIL_TYPE":    "Jenis email tidak didukung",
+		"EMAIL_QUEUE_FAILED":        "Gagal mengantrekan email",
+
+		// Email suppressions
+		"INVALID_WEBHOOK_CREDENTIALS": "Kredensial webhook tidak valid",
+		"REQUEST_BODY_READ_FAILED":    "Gagal membaca body request",
+		"SUBSCRIPTION_CONFIRM_FAILED": "Gagal mengonfirmasi langganan",
+		"UNSUPPORTED_EMAIL_PROVIDER":  "Penyedia email tidak didukung",
+		"INVALID_WEBHOOK_PAYLOAD":     "Payload webhook tidak valid",
+		"EMAIL_NOT_SUPPRESSED":        "Email tidak ada di daftar suppression",
+
+		// Sandbox
+		"FIXTURES_CREATE_FAILED": "Gagal membuat data fixture",
+
+		// Authentication
+		"SERVICE_AUTH_REQUIRED": "Autentikasi layanan diperlukan",
+	},
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"user-service/internal/i18n"
+	"user-service/internal/serviceauth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAuth only lets through requests carrying a valid X-Service-Token minted for this
+// service, so the service can't be called around the gateway. Paths starting with one of
+// exempt are let through: public ones (health checks, the JWKS) and those checking their
+// own credentials (admin token). A nil verifier disables the check. The calling service is
+// stored as "service_caller".
+func ServiceAuth(verifier *serviceauth.Verifier, exempt ...string) gin.HandlerFunc {
+	if verifier == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		caller, err := verifier.Verify(c.GetHeader(serviceauth.Header))
+		if err != nil {
+			log.Printf("🚫 Rejected %s %s from %s: %v", c.Request.Method, c.Request.URL.Path, c.ClientIP(), err)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, i18n.Error(c, "SERVICE_AUTH_REQUIRED", nil))
+			return
+		}
+		c.Set("service_caller", caller)
+		c.Next()
+	}
+}
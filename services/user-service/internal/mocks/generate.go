@@ -0,0 +1,16 @@
+// Package mocks holds gomock mocks of the User-Service stores and providers, generated with
+// `make generate` and not checked in
+package mocks
+
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../events/bus.go -destination=bus_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../events/outbox.go -destination=outbox_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../repository/user_repository.go -destination=user_repository_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../repository/refresh_token_repository.go -destination=refresh_token_repository_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../repository/verification_token_repository.go -destination=verification_token_repository_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../repository/login_device_repository.go -destination=login_device_repository_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../repository/email_log_repository.go -destination=email_log_repository_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../repository/campaign_repository.go -destination=campaign_repository_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../repository/email_suppression_repository.go -destination=email_suppression_repository_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../services/send_throttle.go -destination=rate_limit_store_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../services/sms.go -destination=sms_mock.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -source=../services/captcha.go -destination=captcha_mock.go -package=mocks
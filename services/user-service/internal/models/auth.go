@@ -65,56 +65,20 @@ func (os *OTPService) ValidateOTP(otp string) bool {
 	return true
 }
 
-// JWTClaims represents the JWT claims structure
+// JWTClaims represents the JWT claims structure. exp, nbf, iat, sub and jti are the
+// registered claims, so the API Gateway and Payment-Service validate them the same way.
 type JWTClaims struct {
 	UserID     string `json:"user_id"`
 	Username   string `json:"username"`
 	Email      string `json:"email"`
 	IsVerified bool   `json:"is_verified"`
-	ExpiresAt  int64  `json:"exp"`
-	IssuedAt   int64  `json:"iat"`
-}
-
-// Valid implements jwt.Claims interface
-func (c JWTClaims) Valid() error {
-	return nil
-}
-
-// GetAudience implements jwt.Claims interface
-func (c JWTClaims) GetAudience() (jwt.ClaimStrings, error) {
-	return nil, nil
-}
-
-// GetExpirationTime implements jwt.Claims interface
-func (c JWTClaims) GetExpirationTime() (*jwt.NumericDate, error) {
-	return jwt.NewNumericDate(time.Unix(c.ExpiresAt, 0)), nil
-}
-
-// GetIssuedAt implements jwt.Claims interface
-func (c JWTClaims) GetIssuedAt() (*jwt.NumericDate, error) {
-	return jwt.NewNumericDate(time.Unix(c.IssuedAt, 0)), nil
-}
-
-// GetIssuer implements jwt.Claims interface
-func (c JWTClaims) GetIssuer() (string, error) {
-	return "", nil
-}
-
-// GetNotBefore implements jwt.Claims interface
-func (c JWTClaims) GetNotBefore() (*jwt.NumericDate, error) {
-	return nil, nil
-}
-
-// GetSubject implements jwt.Claims interface
-func (c JWTClaims) GetSubject() (string, error) {
-	return c.UserID, nil
+	jwt.RegisteredClaims
 }
 
 // TokenConfig holds JWT configuration
 type TokenConfig struct {
 	AccessTokenExpiry  time.Duration
 	RefreshTokenExpiry time.Duration
-	SecretKey          string
 }
 
 // DefaultTokenConfig returns default JWT configuration
@@ -122,6 +86,5 @@ func DefaultTokenConfig() *TokenConfig {
 	return &TokenConfig{
 		AccessTokenExpiry:  15 * time.Minute,  // 15 minutes
 		RefreshTokenExpiry: 7 * 24 * time.Hour, // 7 days
-		SecretKey:          "your-secret-key", // Should be from env
 	}
 }
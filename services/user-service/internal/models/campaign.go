@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailTypeCampaign is the email log type of announcement campaign emails
+const EmailTypeCampaign = "campaign"
+
+// Campaign recipient segments
+const (
+	CampaignSegmentVerified = "verified" // Every verified user
+	CampaignSegmentBuyers   = "buyers"   // Verified users who paid for an order in a date range
+)
+
+// Campaign statuses
+const (
+	CampaignStatusSending   = "sending"
+	CampaignStatusCompleted = "completed"
+	CampaignStatusCancelled = "cancelled"
+)
+
+// EmailCampaign is an announcement emailed to a segment of users. The campaign worker walks
+// the segment in user ID order and queues one email per recipient, LastUserID is the last
+// user queued so far.
+type EmailCampaign struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Subject     string     `json:"subject" gorm:"not null;size:200"`
+	Body        string     `json:"body" gorm:"type:text;not null"` // Plain text, {{username}} is replaced per recipient
+	Segment     string     `json:"segment" gorm:"not null;size:20"`
+	BuyersFrom  *time.Time `json:"buyers_from,omitempty"`
+	BuyersTo    *time.Time `json:"buyers_to,omitempty"`
+	Status      string     `json:"status" gorm:"not null;size:20;index"`
+	LastUserID  *uuid.UUID `json:"-" gorm:"type:uuid"`
+	Queued      int        `json:"queued" gorm:"not null;default:0"` // Emails handed to the email consumer
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// CreateCampaignRequest represents the admin request to start a campaign
+type CreateCampaignRequest struct {
+	Subject    string     `json:"subject" validate:"required,max=200"`
+	Body       string     `json:"body" validate:"required,max=20000"`
+	Segment    string     `json:"segment" validate:"required,oneof=verified buyers"`
+	BuyersFrom *time.Time `json:"buyers_from" validate:"required_if=Segment buyers"`
+	BuyersTo   *time.Time `json:"buyers_to" validate:"required_if=Segment buyers"`
+}
+
+// UserPurchase records a paid order of a user, read from payment.success, so campaigns can
+// target buyers without asking Payment-Service
+type UserPurchase struct {
+	PaymentID string    `json:"payment_id" gorm:"primaryKey;size:100"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	PaidAt    time.Time `json:"paid_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CampaignEventKeyPrefix prefixes the email log event keys of a campaign's emails
+func CampaignEventKeyPrefix(campaignID uuid.UUID) string {
+	return "campaign:" + campaignID.String() + ":"
+}
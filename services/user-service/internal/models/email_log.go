@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Email types
+const (
+	EmailTypeOTP                  = "otp"
+	EmailTypeWelcome              = "welcome"
+	EmailTypePasswordReset        = "password_reset"
+	EmailTypePasswordResetSuccess = "password_reset_success"
+	EmailTypeVerificationReminder = "verification_reminder"
+	EmailTypeLoginAlert           = "login_alert"
+	EmailTypeOrderReceipt         = "order_receipt"
+	EmailTypeSaleNotification     = "sale_notification"
+	EmailTypeInvoiceReminder      = "invoice_reminder"
+)
+
+// Email delivery statuses
+const (
+	EmailStatusSent       = "sent"
+	EmailStatusFailed     = "failed"
+	EmailStatusSuppressed = "suppressed" // Not sent, the recipient is on the suppression list
+)
+
+// EmailLog records every email delivery attempt made by the email consumer
+type EmailLog struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID            *uuid.UUID `json:"user_id" gorm:"type:uuid;index"`
+	Recipient         string     `json:"recipient" gorm:"not null;size:150;index"`
+	Type              string     `json:"type" gorm:"not null;size:50"`
+	Status            string     `json:"status" gorm:"not null;size:20"`
+	ProviderMessageID *string    `json:"provider_message_id" gorm:"size:255"`
+	Error             *string    `json:"error" gorm:"type:text"`
+	Locale            string     `json:"locale" gorm:"size:5"`
+	EventKey          *string    `json:"event_key,omitempty" gorm:"size:150;index"` // Event and email it was sent for, to skip redeliveries
+	CreatedAt         time.Time  `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for EmailLog
+func (EmailLog) TableName() string {
+	return "email_logs"
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Email suppression reasons
+const (
+	SuppressionReasonBounce    = "bounce"    // permanent bounce, the mailbox doesn't exist
+	SuppressionReasonComplaint = "complaint" // the recipient marked our email as spam
+)
+
+// EmailSuppression is an address the email provider reported as undeliverable. The email
+// consumer sends nothing to it until support removes the entry.
+type EmailSuppression struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Email             string     `json:"email" gorm:"not null;size:150;uniqueIndex"` // Lowercased
+	UserID            *uuid.UUID `json:"user_id" gorm:"type:uuid;index"`             // Account using the address when it was reported
+	Reason            string     `json:"reason" gorm:"not null;size:20"`
+	Provider          string     `json:"provider" gorm:"not null;size:20"` // ses or sendgrid
+	Detail            *string    `json:"detail" gorm:"type:text"`          // Provider diagnostic, e.g. the SMTP response
+	ProviderMessageID *string    `json:"provider_message_id" gorm:"size:255"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"` // Last report
+}
+
+// TableName specifies the table name for EmailSuppression
+func (EmailSuppression) TableName() string {
+	return "email_suppressions"
+}
+
+// NormalizeSuppressedEmail is the form addresses are stored and looked up in
+func NormalizeSuppressedEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
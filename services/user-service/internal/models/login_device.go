@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginDevice is a device/network combination a user has logged in from.
+// Logins from an unknown combination trigger a "was this you?" alert email.
+type LoginDevice struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_login_devices_user_fingerprint"`
+	Fingerprint string    `json:"-" gorm:"not null;size:64;uniqueIndex:idx_login_devices_user_fingerprint"` // SHA-256 of user agent and IP network
+	IPAddress   string    `json:"ip_address" gorm:"size:45"`
+	UserAgent   string    `json:"user_agent" gorm:"size:500"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+// TableName specifies the table name for LoginDevice
+func (LoginDevice) TableName() string {
+	return "login_devices"
+}
+
+// SessionRevokeToken is a single-use token sent in login alert emails to sign out every session.
+// Only the SHA-256 hash of the token is stored.
+type SessionRevokeToken struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	TokenHash string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for SessionRevokeToken
+func (SessionRevokeToken) TableName() string {
+	return "session_revoke_tokens"
+}
+
+// IsUsable reports whether the token is unused and not expired
+func (t *SessionRevokeToken) IsUsable() bool {
+	return t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// OutboxEvent is an event that couldn't be published while the event bus was unavailable.
+// The outbox relay publishes and removes it once the bus is connected again.
+type OutboxEvent struct {
+	ID         string    `json:"id" gorm:"primaryKey;size:64"` // Message ID, kept when relayed
+	Exchange   string    `json:"exchange" gorm:"not null;size:100"`
+	RoutingKey string    `json:"routing_key" gorm:"not null;size:100"`
+	Body       string    `json:"body" gorm:"type:text;not null"`
+	Attempts   int       `json:"attempts" gorm:"not null;default:0"`
+	LastError  *string   `json:"last_error" gorm:"type:text"`
+	OccurredAt time.Time `json:"occurred_at" gorm:"not null"` // When the event was first published
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for OutboxEvent
+func (OutboxEvent) TableName() string {
+	return "event_outbox"
+}
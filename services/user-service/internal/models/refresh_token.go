@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is an issued refresh token bound to the client it was issued to.
+// Only the SHA-256 hashes of the token and of the client's user agent are stored.
+type RefreshToken struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID            uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	TokenHash         string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	ClientFingerprint string     `json:"-" gorm:"not null;size:64"` // SHA-256 of the user agent
+	ExpiresAt         time.Time  `json:"expires_at" gorm:"not null;index"`
+	RevokedAt         *time.Time `json:"revoked_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for RefreshToken
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// IsUsable reports whether the token is not revoked and not expired
+func (t *RefreshToken) IsUsable() bool {
+	return t.RevokedAt == nil && time.Now().Before(t.ExpiresAt)
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,21 +18,54 @@ type User struct {
 	ImageUrl     *string   `json:"image_url" gorm:"size:500"` // Profile image URL from OAuth providers
 	Type         string    `json:"type" gorm:"not null;default:'credential'" validate:"required,oneof=credential google"` // Login type: credential or google
 	IsVerified   bool      `json:"is_verified" gorm:"default:false"`
+	Locale       string    `json:"locale" gorm:"size:5;not null;default:'id'"` // Preferred language for emails and messages
+	OTPIssuedAt  *time.Time `json:"-"` // When the current OTP was generated, used to expire stale codes
+	RemindedAt   *time.Time `json:"-"` // Final verification reminder sent before an unverified account is removed
+	FlaggedAt    *time.Time `json:"-"` // Marked stale when UNVERIFIED_ACCOUNT_ACTION=flag instead of deleted
+	LoginAlerts  bool       `json:"login_alerts" gorm:"not null;default:true"` // Email a security alert on logins from new devices
+	MarketingEmails bool    `json:"marketing_emails" gorm:"not null;default:true"` // Receive announcement campaign emails
+	SessionsRevokedAt *time.Time `json:"-"` // Refresh tokens issued before this are rejected
+	PhoneNumber        *string    `json:"phone_number" gorm:"size:20;uniqueIndex"` // Verified phone number in E.164, sent to payment providers
+	PhoneVerifiedAt    *time.Time `json:"phone_verified_at"`
+	PendingPhoneNumber *string    `json:"-" gorm:"size:20"` // Number waiting for its verification code
+	PhoneOTPCode       *string    `json:"-" gorm:"size:6"`
+	PhoneOTPIssuedAt   *time.Time `json:"-"`
+	PhoneOTPAttempts   int        `json:"-" gorm:"not null;default:0"` // Wrong codes entered for the pending number
+	LastLoginAt        *time.Time `json:"last_login_at"`
+	LastLoginIP        *string    `json:"last_login_ip" gorm:"size:45"` // Client address of the last login (IPv6 fits)
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 // UserRegisterRequest represents the request payload for user registration
 type UserRegisterRequest struct {
-	Username string `json:"username" validate:"required,min=3,max=100"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Username     string `json:"username" validate:"required,min=3,max=100"`
+	Email        string `json:"email" validate:"required,email"`
+	Password     string `json:"password" validate:"required"` // length and strength checked by the password policy
+	CaptchaToken string `json:"captcha_token,omitempty"`      // required after suspicious activity
 }
 
-// UserLoginRequest represents the request payload for user login
+// UserLoginRequest represents the request payload for user login. Identifier is an email or
+// a username; email is still accepted from clients that predate it.
 type UserLoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Identifier   string `json:"identifier" validate:"required_without=Email,max=150"`
+	Email        string `json:"email" validate:"omitempty,email"`
+	Password     string `json:"password" validate:"required"`
+	CaptchaToken string `json:"captcha_token,omitempty"` // required after suspicious activity
+}
+
+// LoginIdentifier returns the email or username to log in with
+func (r UserLoginRequest) LoginIdentifier() string {
+	if identifier := strings.TrimSpace(r.Identifier); identifier != "" {
+		return identifier
+	}
+	return strings.TrimSpace(r.Email)
+}
+
+// IsEmailIdentifier reports whether a login identifier is an email, usernames can't
+// contain "@"
+func IsEmailIdentifier(identifier string) bool {
+	return strings.Contains(identifier, "@")
 }
 
 // OTPVerifyRequest represents the request payload for OTP verification
@@ -49,18 +83,58 @@ type ResetPasswordRequest struct {
 type VerifyResetPasswordRequest struct {
 	Email       string `json:"email" validate:"required,email"`
 	OTPCode     string `json:"otp_code" validate:"required,len=6"`
-	NewPassword string `json:"new_password" validate:"required,min=6"`
+	NewPassword string `json:"new_password" validate:"required"` // length and strength checked by the password policy
+}
+
+// PhoneNumberRequest represents the request payload for adding or changing the phone number
+type PhoneNumberRequest struct {
+	PhoneNumber string `json:"phone_number" validate:"required,max=32"`
+	Channel     string `json:"channel" validate:"omitempty,oneof=sms whatsapp"` // Delivery of the code, sms by default
+}
+
+// PhoneVerifyRequest represents the request payload for verifying the phone number
+type PhoneVerifyRequest struct {
+	OTPCode string `json:"otp_code" validate:"required,len=6"`
+}
+
+// ChangePasswordRequest represents the request payload for changing the password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"` // length and strength checked by the password policy
 }
 
 // UserResponse represents the response payload for user data
 type UserResponse struct {
-	ID         uuid.UUID `json:"id"`
-	Username   string    `json:"username"`
-	Email      string    `json:"email"`
-	ImageUrl   *string   `json:"image_url"`
-	Type       string    `json:"type"`
-	IsVerified bool      `json:"is_verified"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID                 uuid.UUID  `json:"id"`
+	Username           string     `json:"username"`
+	Email              string     `json:"email"`
+	ImageUrl           *string    `json:"image_url"`
+	Type               string     `json:"type"`
+	IsVerified         bool       `json:"is_verified"`
+	Locale             string     `json:"locale"`
+	LoginAlerts        bool       `json:"login_alerts"`
+	MarketingEmails    bool       `json:"marketing_emails"`
+	PhoneNumber        *string    `json:"phone_number"`
+	PhoneVerified      bool       `json:"phone_verified"`
+	PendingPhoneNumber *string    `json:"pending_phone_number,omitempty"`
+	LastLoginAt        *time.Time `json:"last_login_at"`
+	LastLoginIP        *string    `json:"last_login_ip"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// AdminUserResponse is the admin view of a user: the profile plus account state support
+// needs when following up on a user
+type AdminUserResponse struct {
+	UserResponse
+	PhoneVerifiedAt   *time.Time `json:"phone_verified_at"`
+	SessionsRevokedAt *time.Time `json:"sessions_revoked_at"`
+	FlaggedAt         *time.Time `json:"flagged_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// Set when the email provider reported the address as bouncing or complaining, nothing
+	// is emailed to the user until support removes the suppression
+	EmailUndeliverable bool              `json:"email_undeliverable"`
+	EmailSuppression   *EmailSuppression `json:"email_suppression,omitempty"`
 }
 
 // AuthResponse represents the response payload for authentication
@@ -79,15 +153,50 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// VerifiedPhoneNumber returns the verified phone number, empty when there is none
+func (u *User) VerifiedPhoneNumber() string {
+	if u.PhoneNumber == nil || u.PhoneVerifiedAt == nil {
+		return ""
+	}
+	return *u.PhoneNumber
+}
+
+// ClearPhoneVerification discards the pending phone number and its code
+func (u *User) ClearPhoneVerification() {
+	u.PendingPhoneNumber = nil
+	u.PhoneOTPCode = nil
+	u.PhoneOTPIssuedAt = nil
+	u.PhoneOTPAttempts = 0
+}
+
+// ToAdminResponse converts User to AdminUserResponse
+func (u *User) ToAdminResponse() AdminUserResponse {
+	return AdminUserResponse{
+		UserResponse:      u.ToResponse(),
+		PhoneVerifiedAt:   u.PhoneVerifiedAt,
+		SessionsRevokedAt: u.SessionsRevokedAt,
+		FlaggedAt:         u.FlaggedAt,
+		UpdatedAt:         u.UpdatedAt,
+	}
+}
+
 // ToResponse converts User to UserResponse
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:         u.ID,
-		Username:   u.Username,
-		Email:      u.Email,
-		ImageUrl:   u.ImageUrl,
-		Type:       u.Type,
-		IsVerified: u.IsVerified,
-		CreatedAt:  u.CreatedAt,
+		ID:                 u.ID,
+		Username:           u.Username,
+		Email:              u.Email,
+		ImageUrl:           u.ImageUrl,
+		Type:               u.Type,
+		IsVerified:         u.IsVerified,
+		Locale:             u.Locale,
+		LoginAlerts:        u.LoginAlerts,
+		MarketingEmails:    u.MarketingEmails,
+		PhoneNumber:        u.PhoneNumber,
+		PhoneVerified:      u.PhoneNumber != nil && u.PhoneVerifiedAt != nil,
+		PendingPhoneNumber: u.PendingPhoneNumber,
+		LastLoginAt:        u.LastLoginAt,
+		LastLoginIP:        u.LastLoginIP,
+		CreatedAt:          u.CreatedAt,
 	}
 }
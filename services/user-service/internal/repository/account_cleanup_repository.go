@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"time"
+
+	"user-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// staleOTPQuery selects users holding an OTP issued before cutoff.
+// Rows created before otp_issued_at existed fall back to updated_at.
+func (r *UserRepository) staleOTPQuery(cutoff time.Time) *gorm.DB {
+	return r.db.Model(&models.User{}).
+		Where("otp_code IS NOT NULL AND COALESCE(otp_issued_at, updated_at) < ?", cutoff)
+}
+
+// CountStaleOTPs counts OTP codes issued before cutoff
+func (r *UserRepository) CountStaleOTPs(cutoff time.Time) (int64, error) {
+	var count int64
+	err := r.staleOTPQuery(cutoff).Count(&count).Error
+	return count, err
+}
+
+// ExpireOTPs clears OTP codes issued before cutoff and returns how many were cleared
+func (r *UserRepository) ExpireOTPs(cutoff time.Time) (int64, error) {
+	result := r.staleOTPQuery(cutoff).Updates(map[string]interface{}{
+		"otp_code":      nil,
+		"otp_issued_at": nil,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// GetUnverifiedForReminder returns credential accounts registered before cutoff that are
+// still unverified and haven't received the final verification reminder
+func (r *UserRepository) GetUnverifiedForReminder(cutoff time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.
+		Where("is_verified = ? AND type = ? AND reminded_at IS NULL AND flagged_at IS NULL AND created_at < ?", false, "credential", cutoff).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// GetStaleUnverified returns unverified accounts whose final reminder was sent before cutoff
+func (r *UserRepository) GetStaleUnverified(cutoff time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.
+		Where("is_verified = ? AND flagged_at IS NULL AND reminded_at < ?", false, cutoff).
+		Order("reminded_at ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// MarkReminded records that the final verification reminder was sent
+func (r *UserRepository) MarkReminded(user *models.User) error {
+	now := time.Now()
+	user.RemindedAt = &now
+	return r.db.Model(user).Update("reminded_at", now).Error
+}
+
+// FlagStale marks an unverified account as stale without deleting it
+func (r *UserRepository) FlagStale(user *models.User) error {
+	now := time.Now()
+	user.FlaggedAt = &now
+	return r.db.Model(user).Update("flagged_at", now).Error
+}
+
+// DeleteUnverified removes an unverified account and its verification tokens,
+// releasing its email and username. Verified accounts are never touched.
+func (r *UserRepository) DeleteUnverified(user *models.User) (bool, error) {
+	deleted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND is_verified = ?", user.ID, false).Delete(&models.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil // Verified in the meantime
+		}
+		deleted = true
+
+		return tx.Where("user_id = ?", user.ID).Delete(&models.EmailVerificationToken{}).Error
+	})
+	return deleted, err
+}
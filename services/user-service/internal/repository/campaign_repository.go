@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"time"
+
+	"user-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CampaignStore abstracts email campaign and purchase persistence
+type CampaignStore interface {
+	Create(campaign *models.EmailCampaign) error
+	GetByID(id uuid.UUID) (*models.EmailCampaign, error)
+	List(limit int) ([]models.EmailCampaign, error)
+	ListSending() ([]models.EmailCampaign, error)
+	CountRecipients(campaign *models.EmailCampaign) (int64, error)
+	NextRecipients(campaign *models.EmailCampaign, limit int) ([]models.User, error)
+	Advance(campaign *models.EmailCampaign, lastUserID uuid.UUID, queued int) (bool, error)
+	Complete(campaign *models.EmailCampaign) error
+	Cancel(id uuid.UUID) (bool, error)
+	DeliveryCounts(id uuid.UUID) (map[string]int64, error)
+	RecordPurchase(purchase *models.UserPurchase) error
+}
+
+// CampaignRepository handles email campaign database operations
+type CampaignRepository struct {
+	db *gorm.DB
+}
+
+// Ensure CampaignRepository implements CampaignStore
+var _ CampaignStore = (*CampaignRepository)(nil)
+
+// NewCampaignRepository creates a new campaign repository
+func NewCampaignRepository(db *gorm.DB) *CampaignRepository {
+	return &CampaignRepository{
+		db: db,
+	}
+}
+
+// Create stores a new campaign
+func (r *CampaignRepository) Create(campaign *models.EmailCampaign) error {
+	return r.db.Create(campaign).Error
+}
+
+// GetByID retrieves a campaign by ID
+func (r *CampaignRepository) GetByID(id uuid.UUID) (*models.EmailCampaign, error) {
+	var campaign models.EmailCampaign
+	err := r.db.Where("id = ?", id).First(&campaign).Error
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// List returns the latest campaigns, newest first
+func (r *CampaignRepository) List(limit int) ([]models.EmailCampaign, error) {
+	var campaigns []models.EmailCampaign
+	err := r.db.Order("created_at DESC").Limit(limit).Find(&campaigns).Error
+	return campaigns, err
+}
+
+// ListSending returns the campaigns still queueing emails, oldest first
+func (r *CampaignRepository) ListSending() ([]models.EmailCampaign, error) {
+	var campaigns []models.EmailCampaign
+	err := r.db.Where("status = ?", models.CampaignStatusSending).Order("created_at").Find(&campaigns).Error
+	return campaigns, err
+}
+
+// recipients selects the verified users of the campaign's segment who accept announcements
+func (r *CampaignRepository) recipients(campaign *models.EmailCampaign) *gorm.DB {
+	query := r.db.Model(&models.User{}).Where("is_verified = ? AND marketing_emails = ?", true, true)
+	if campaign.Segment == models.CampaignSegmentBuyers {
+		buyers := r.db.Model(&models.UserPurchase{}).Select("user_id")
+		if campaign.BuyersFrom != nil {
+			buyers = buyers.Where("paid_at >= ?", *campaign.BuyersFrom)
+		}
+		if campaign.BuyersTo != nil {
+			buyers = buyers.Where("paid_at < ?", *campaign.BuyersTo)
+		}
+		query = query.Where("id IN (?)", buyers)
+	}
+	return query
+}
+
+// CountRecipients counts the users the campaign would be sent to
+func (r *CampaignRepository) CountRecipients(campaign *models.EmailCampaign) (int64, error) {
+	var count int64
+	err := r.recipients(campaign).Count(&count).Error
+	return count, err
+}
+
+// NextRecipients returns the next recipients after the last one queued, in ID order
+func (r *CampaignRepository) NextRecipients(campaign *models.EmailCampaign, limit int) ([]models.User, error) {
+	query := r.recipients(campaign).Select("id", "username", "email", "locale")
+	if campaign.LastUserID != nil {
+		query = query.Where("id > ?", *campaign.LastUserID)
+	}
+
+	var users []models.User
+	err := query.Order("id").Limit(limit).Find(&users).Error
+	return users, err
+}
+
+// Advance records lastUserID as the last queued recipient and counts the queued emails. It
+// only succeeds while the campaign is still where it was read, so two workers (replicas)
+// never queue the same batch.
+func (r *CampaignRepository) Advance(campaign *models.EmailCampaign, lastUserID uuid.UUID, queued int) (bool, error) {
+	query := r.db.Model(&models.EmailCampaign{}).Where("id = ? AND status = ?", campaign.ID, models.CampaignStatusSending)
+	if campaign.LastUserID == nil {
+		query = query.Where("last_user_id IS NULL")
+	} else {
+		query = query.Where("last_user_id = ?", *campaign.LastUserID)
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"last_user_id": lastUserID,
+		"queued":       gorm.Expr("queued + ?", queued),
+		"updated_at":   time.Now(),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	campaign.LastUserID = &lastUserID
+	campaign.Queued += queued
+	return true, nil
+}
+
+// Complete marks a campaign whose segment was fully queued as completed
+func (r *CampaignRepository) Complete(campaign *models.EmailCampaign) error {
+	now := time.Now()
+	return r.db.Model(&models.EmailCampaign{}).
+		Where("id = ? AND status = ?", campaign.ID, models.CampaignStatusSending).
+		Updates(map[string]interface{}{
+			"status":       models.CampaignStatusCompleted,
+			"completed_at": now,
+			"updated_at":   now,
+		}).Error
+}
+
+// Cancel stops a campaign that is still sending, reporting whether it was
+func (r *CampaignRepository) Cancel(id uuid.UUID) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.EmailCampaign{}).
+		Where("id = ? AND status = ?", id, models.CampaignStatusSending).
+		Updates(map[string]interface{}{
+			"status":       models.CampaignStatusCancelled,
+			"completed_at": now,
+			"updated_at":   now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeliveryCounts counts the campaign's logged delivery attempts by status (sent, failed)
+func (r *CampaignRepository) DeliveryCounts(id uuid.UUID) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.Model(&models.EmailLog{}).
+		Select("status, count(*) AS count").
+		Where("event_key LIKE ?", models.CampaignEventKeyPrefix(id)+"%").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int64{models.EmailStatusSent: 0, models.EmailStatusFailed: 0}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// RecordPurchase stores a paid order, redelivered events are ignored
+func (r *CampaignRepository) RecordPurchase(purchase *models.UserPurchase) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(purchase).Error
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"user-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EmailLogFilter holds the optional filters for listing email logs
+type EmailLogFilter struct {
+	UserID    *uuid.UUID
+	Recipient string
+	Status    string
+	Limit     int
+}
+
+// EmailLogStore abstracts email log persistence
+type EmailLogStore interface {
+	Create(emailLog *models.EmailLog) error
+	GetByID(id uuid.UUID) (*models.EmailLog, error)
+	List(filter EmailLogFilter) ([]models.EmailLog, error)
+	WasSent(eventKey string) (bool, error)
+}
+
+// EmailLogRepository handles email log database operations
+type EmailLogRepository struct {
+	db *gorm.DB
+}
+
+// Ensure EmailLogRepository implements EmailLogStore
+var _ EmailLogStore = (*EmailLogRepository)(nil)
+
+// NewEmailLogRepository creates a new email log repository
+func NewEmailLogRepository(db *gorm.DB) *EmailLogRepository {
+	return &EmailLogRepository{
+		db: db,
+	}
+}
+
+// Create stores a new email log entry
+func (r *EmailLogRepository) Create(emailLog *models.EmailLog) error {
+	return r.db.Create(emailLog).Error
+}
+
+// GetByID retrieves an email log by ID
+func (r *EmailLogRepository) GetByID(id uuid.UUID) (*models.EmailLog, error) {
+	var emailLog models.EmailLog
+	err := r.db.Where("id = ?", id).First(&emailLog).Error
+	if err != nil {
+		return nil, err
+	}
+	return &emailLog, nil
+}
+
+// List returns email logs matching the filter, newest first
+func (r *EmailLogRepository) List(filter EmailLogFilter) ([]models.EmailLog, error) {
+	query := r.db.Model(&models.EmailLog{})
+
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Recipient != "" {
+		query = query.Where("recipient = ?", filter.Recipient)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var logs []models.EmailLog
+	if err := query.Order("created_at DESC").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// WasSent reports whether an email was already sent successfully for the event key
+func (r *EmailLogRepository) WasSent(eventKey string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.EmailLog{}).
+		Where("event_key = ? AND status = ?", eventKey, models.EmailStatusSent).
+		Count(&count).Error
+	return count > 0, err
+}
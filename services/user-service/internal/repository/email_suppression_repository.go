@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+
+	"user-service/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailSuppressionStore abstracts the email suppression list
+type EmailSuppressionStore interface {
+	Suppress(suppression *models.EmailSuppression) error
+	GetByEmail(email string) (*models.EmailSuppression, error)
+	IsSuppressed(email string) (bool, error)
+	List(reason string, limit int) ([]models.EmailSuppression, error)
+	Remove(email string) (bool, error)
+}
+
+// EmailSuppressionRepository handles email suppression database operations
+type EmailSuppressionRepository struct {
+	db *gorm.DB
+}
+
+// Ensure EmailSuppressionRepository implements EmailSuppressionStore
+var _ EmailSuppressionStore = (*EmailSuppressionRepository)(nil)
+
+// NewEmailSuppressionRepository creates a new email suppression repository
+func NewEmailSuppressionRepository(db *gorm.DB) *EmailSuppressionRepository {
+	return &EmailSuppressionRepository{
+		db: db,
+	}
+}
+
+// Suppress adds an address to the list, or records the latest report for one already on it
+func (r *EmailSuppressionRepository) Suppress(suppression *models.EmailSuppression) error {
+	suppression.Email = models.NormalizeSuppressedEmail(suppression.Email)
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "reason", "provider", "detail", "provider_message_id", "updated_at"}),
+	}).Create(suppression).Error
+}
+
+// GetByEmail returns the suppression of an address, nil when it isn't suppressed
+func (r *EmailSuppressionRepository) GetByEmail(email string) (*models.EmailSuppression, error) {
+	var suppression models.EmailSuppression
+	err := r.db.Where("email = ?", models.NormalizeSuppressedEmail(email)).First(&suppression).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &suppression, nil
+}
+
+// IsSuppressed reports whether nothing may be sent to an address
+func (r *EmailSuppressionRepository) IsSuppressed(email string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.EmailSuppression{}).
+		Where("email = ?", models.NormalizeSuppressedEmail(email)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// List returns suppressed addresses, optionally of one reason, most recently reported first
+func (r *EmailSuppressionRepository) List(reason string, limit int) ([]models.EmailSuppression, error) {
+	query := r.db.Model(&models.EmailSuppression{})
+	if reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var suppressions []models.EmailSuppression
+	if err := query.Order("updated_at DESC").Find(&suppressions).Error; err != nil {
+		return nil, err
+	}
+	return suppressions, nil
+}
+
+// Remove takes an address off the list, reporting whether it was on it
+func (r *EmailSuppressionRepository) Remove(email string) (bool, error) {
+	result := r.db.Where("email = ?", models.NormalizeSuppressedEmail(email)).Delete(&models.EmailSuppression{})
+	return result.RowsAffected > 0, result.Error
+}
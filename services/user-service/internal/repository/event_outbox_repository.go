@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"user-service/internal/events"
+	"user-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// EventOutboxRepository stores events waiting for the event bus
+type EventOutboxRepository struct {
+	db *gorm.DB
+}
+
+// Ensure EventOutboxRepository implements events.OutboxStore
+var _ events.OutboxStore = (*EventOutboxRepository)(nil)
+
+// NewEventOutboxRepository creates a new event outbox repository
+func NewEventOutboxRepository(db *gorm.DB) *EventOutboxRepository {
+	return &EventOutboxRepository{
+		db: db,
+	}
+}
+
+// Save queues an event. Headers are not kept, user-service events don't use them.
+func (r *EventOutboxRepository) Save(msg events.Message) error {
+	return r.db.Create(&models.OutboxEvent{
+		ID:         msg.ID,
+		Exchange:   msg.Exchange,
+		RoutingKey: msg.RoutingKey,
+		Body:       string(msg.Body),
+		OccurredAt: msg.Timestamp,
+	}).Error
+}
+
+// Pending returns queued events, oldest first
+func (r *EventOutboxRepository) Pending(limit int) ([]events.Message, error) {
+	var queued []models.OutboxEvent
+	if err := r.db.Order("created_at ASC").Limit(limit).Find(&queued).Error; err != nil {
+		return nil, err
+	}
+
+	messages := make([]events.Message, 0, len(queued))
+	for _, event := range queued {
+		messages = append(messages, events.Message{
+			ID:         event.ID,
+			Exchange:   event.Exchange,
+			RoutingKey: event.RoutingKey,
+			Body:       []byte(event.Body),
+			Timestamp:  event.OccurredAt,
+		})
+	}
+	return messages, nil
+}
+
+// Delete removes a relayed event
+func (r *EventOutboxRepository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&models.OutboxEvent{}).Error
+}
+
+// Failed records a failed relay attempt
+func (r *EventOutboxRepository) Failed(id string, publishErr error) error {
+	message := publishErr.Error()
+	return r.db.Model(&models.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": message,
+	}).Error
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"time"
+
+	"user-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginDeviceStore abstracts login device history and session revocation tokens
+type LoginDeviceStore interface {
+	RecordLogin(userID uuid.UUID, ipAddress, userAgent string) (bool, error)
+	IssueRevokeToken(userID uuid.UUID, ttl time.Duration) (string, error)
+	GetRevokeToken(token string) (*models.SessionRevokeToken, error)
+	MarkRevokeTokenUsed(revokeToken *models.SessionRevokeToken) error
+}
+
+// LoginDeviceRepository handles login device and session revoke token database operations
+type LoginDeviceRepository struct {
+	db *gorm.DB
+}
+
+// Ensure LoginDeviceRepository implements LoginDeviceStore
+var _ LoginDeviceStore = (*LoginDeviceRepository)(nil)
+
+// NewLoginDeviceRepository creates a new login device repository
+func NewLoginDeviceRepository(db *gorm.DB) *LoginDeviceRepository {
+	return &LoginDeviceRepository{
+		db: db,
+	}
+}
+
+// RecordLogin stores the device/network of a login and reports whether it is new for the user.
+// The very first login of a user is not reported as new.
+func (r *LoginDeviceRepository) RecordLogin(userID uuid.UUID, ipAddress, userAgent string) (bool, error) {
+	now := time.Now()
+	fingerprint := deviceFingerprint(ipAddress, userAgent)
+
+	var device models.LoginDevice
+	err := r.db.Where("user_id = ? AND fingerprint = ?", userID, fingerprint).First(&device).Error
+	if err == nil {
+		return false, r.db.Model(&device).Updates(map[string]interface{}{
+			"ip_address":   ipAddress,
+			"last_seen_at": now,
+		}).Error
+	}
+	if err != gorm.ErrRecordNotFound {
+		return false, err
+	}
+
+	var known int64
+	if err := r.db.Model(&models.LoginDevice{}).Where("user_id = ?", userID).Count(&known).Error; err != nil {
+		return false, err
+	}
+
+	device = models.LoginDevice{
+		UserID:      userID,
+		Fingerprint: fingerprint,
+		IPAddress:   ipAddress,
+		UserAgent:   truncate(userAgent, 500),
+		FirstSeenAt: now,
+		LastSeenAt:  now,
+	}
+	if err := r.db.Create(&device).Error; err != nil {
+		return false, err
+	}
+
+	return known > 0, nil
+}
+
+// IssueRevokeToken creates a new random session revoke token and returns its plain value
+func (r *LoginDeviceRepository) IssueRevokeToken(userID uuid.UUID, ttl time.Duration) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	revokeToken := models.SessionRevokeToken{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := r.db.Create(&revokeToken).Error; err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// GetRevokeToken retrieves a session revoke token by its plain value
+func (r *LoginDeviceRepository) GetRevokeToken(token string) (*models.SessionRevokeToken, error) {
+	var revokeToken models.SessionRevokeToken
+	err := r.db.Where("token_hash = ?", hashToken(token)).First(&revokeToken).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revokeToken, nil
+}
+
+// MarkRevokeTokenUsed marks the token as consumed, failing if it was already used concurrently
+func (r *LoginDeviceRepository) MarkRevokeTokenUsed(revokeToken *models.SessionRevokeToken) error {
+	now := time.Now()
+	result := r.db.Model(&models.SessionRevokeToken{}).
+		Where("id = ? AND used_at IS NULL", revokeToken.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("token already used")
+	}
+
+	revokeToken.UsedAt = &now
+	return nil
+}
+
+// deviceFingerprint identifies a device by its user agent and the network it connects from
+// (IPv4 /24, IPv6 /48), so address changes within the same network don't look like a new location
+func deviceFingerprint(ipAddress, userAgent string) string {
+	network := ipAddress
+	if ip := net.ParseIP(ipAddress); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			network = ip4.Mask(net.CIDRMask(24, 32)).String()
+		} else {
+			network = ip.Mask(net.CIDRMask(48, 128)).String()
+		}
+	}
+
+	sum := sha256.Sum256([]byte(userAgent + "|" + network))
+	return hex.EncodeToString(sum[:])
+}
+
+// truncate limits s to max bytes
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
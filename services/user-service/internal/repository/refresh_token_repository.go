@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"user-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshTokenStore abstracts issued refresh token storage
+type RefreshTokenStore interface {
+	Store(userID uuid.UUID, token, userAgent string, expiresAt time.Time) error
+	Get(token string) (*models.RefreshToken, error)
+	Revoke(refreshToken *models.RefreshToken) error
+}
+
+// RefreshTokenRepository handles refresh token database operations
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// Ensure RefreshTokenRepository implements RefreshTokenStore
+var _ RefreshTokenStore = (*RefreshTokenRepository)(nil)
+
+// NewRefreshTokenRepository creates a new refresh token repository
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		db: db,
+	}
+}
+
+// Store records an issued refresh token with the fingerprint of the client it was issued to
+func (r *RefreshTokenRepository) Store(userID uuid.UUID, token, userAgent string, expiresAt time.Time) error {
+	refreshToken := models.RefreshToken{
+		UserID:            userID,
+		TokenHash:         hashToken(token),
+		ClientFingerprint: ClientFingerprint(userAgent),
+		ExpiresAt:         expiresAt,
+	}
+	return r.db.Create(&refreshToken).Error
+}
+
+// Get retrieves a refresh token by its plain value
+func (r *RefreshTokenRepository) Get(token string) (*models.RefreshToken, error) {
+	var refreshToken models.RefreshToken
+	err := r.db.Where("token_hash = ?", hashToken(token)).First(&refreshToken).Error
+	if err != nil {
+		return nil, err
+	}
+	return &refreshToken, nil
+}
+
+// Revoke marks the token as revoked, failing if it was already revoked concurrently
+func (r *RefreshTokenRepository) Revoke(refreshToken *models.RefreshToken) error {
+	now := time.Now()
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", refreshToken.ID).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("token already revoked")
+	}
+
+	refreshToken.RevokedAt = &now
+	return nil
+}
+
+// ClientFingerprint identifies the client a refresh token was issued to by its user agent
+func ClientFingerprint(userAgent string) string {
+	sum := sha256.Sum256([]byte(userAgent))
+	return hex.EncodeToString(sum[:])
+}
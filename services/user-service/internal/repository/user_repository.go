@@ -1,17 +1,40 @@
 package repository
 
 import (
+	"time"
+
 	"user-service/internal/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// UserStore abstracts user persistence so handlers and consumers can be tested with mocks
+type UserStore interface {
+	GetByID(id uuid.UUID) (*models.User, error)
+	GetByEmail(email string) (*models.User, error)
+	GetByLoginIdentifier(identifier string) (*models.User, error)
+	ExistsByEmailOrUsername(email, username string) (bool, error)
+	IsUsernameTaken(username string, excludeID uuid.UUID) (bool, error)
+	IsEmailRegistered(email string) (bool, error)
+	IsPhoneNumberTaken(phoneNumber string, excludeID uuid.UUID) (bool, error)
+	Create(user *models.User) error
+	Update(user *models.User) error
+	UpdateOTP(user *models.User, otp *string) error
+	MarkVerified(user *models.User) error
+	UpdatePassword(user *models.User, passwordHash string) error
+	RevokeSessions(user *models.User) error
+	RecordLastLogin(user *models.User, ipAddress string, at time.Time) error
+}
+
 // UserRepository handles user database operations
 type UserRepository struct {
 	db *gorm.DB
 }
 
+// Ensure UserRepository implements UserStore
+var _ UserStore = (*UserRepository)(nil)
+
 // NewUserRepository creates a new user repository
 func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{
@@ -39,6 +62,61 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+// GetByLoginIdentifier retrieves a user by email or username, ignoring case
+func (r *UserRepository) GetByLoginIdentifier(identifier string) (*models.User, error) {
+	column := "username"
+	if models.IsEmailIdentifier(identifier) {
+		column = "email"
+	}
+
+	var user models.User
+	err := r.db.Where("LOWER("+column+") = LOWER(?)", identifier).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ExistsByEmailOrUsername checks whether a user with the email or username already exists
+func (r *UserRepository) ExistsByEmailOrUsername(email, username string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("LOWER(email) = LOWER(?) OR LOWER(username) = LOWER(?)", email, username).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// IsUsernameTaken checks whether another user already uses the username
+func (r *UserRepository) IsUsernameTaken(username string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("LOWER(username) = LOWER(?) AND id != ?", username, excludeID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// IsEmailRegistered checks whether any user is registered with the email, ignoring case
+func (r *UserRepository) IsEmailRegistered(email string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("LOWER(email) = LOWER(?)", email).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// IsPhoneNumberTaken checks whether another user has verified the phone number
+func (r *UserRepository) IsPhoneNumberTaken(phoneNumber string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("phone_number = ? AND id != ?", phoneNumber, excludeID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 // Create creates a new user
 func (r *UserRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
@@ -46,5 +124,55 @@ func (r *UserRepository) Create(user *models.User) error {
 
 // Update updates an existing user
 func (r *UserRepository) Update(user *models.User) error {
+	user.UpdatedAt = time.Now()
 	return r.db.Save(user).Error
 }
+
+// UpdateOTP sets (or clears, when otp is nil) the user's OTP code
+func (r *UserRepository) UpdateOTP(user *models.User, otp *string) error {
+	now := time.Now()
+	user.OTPCode = otp
+	user.OTPIssuedAt = nil
+	if otp != nil {
+		user.OTPIssuedAt = &now
+	}
+	user.UpdatedAt = now
+	return r.db.Model(user).Select("otp_code", "otp_issued_at", "updated_at").Updates(user).Error
+}
+
+// MarkVerified marks the user as verified and clears the OTP code
+func (r *UserRepository) MarkVerified(user *models.User) error {
+	user.IsVerified = true
+	user.OTPCode = nil
+	user.OTPIssuedAt = nil
+	user.UpdatedAt = time.Now()
+	return r.db.Model(user).Select("is_verified", "otp_code", "otp_issued_at", "updated_at").Updates(user).Error
+}
+
+// UpdatePassword sets a new password hash and clears the reset code
+func (r *UserRepository) UpdatePassword(user *models.User, passwordHash string) error {
+	user.PasswordHash = passwordHash
+	user.OTPCode = nil
+	user.OTPIssuedAt = nil
+	user.UpdatedAt = time.Now()
+	return r.db.Model(user).Select("password_hash", "otp_code", "otp_issued_at", "updated_at").Updates(user).Error
+}
+
+// RecordLastLogin stores when and from where the user last logged in. updated_at is left
+// alone, a login doesn't change the profile.
+func (r *UserRepository) RecordLastLogin(user *models.User, ipAddress string, at time.Time) error {
+	user.LastLoginAt = &at
+	user.LastLoginIP = &ipAddress
+	return r.db.Model(user).UpdateColumns(map[string]interface{}{
+		"last_login_at": at,
+		"last_login_ip": ipAddress,
+	}).Error
+}
+
+// RevokeSessions invalidates every refresh token issued to the user so far
+func (r *UserRepository) RevokeSessions(user *models.User) error {
+	now := time.Now()
+	user.SessionsRevokedAt = &now
+	user.UpdatedAt = now
+	return r.db.Model(user).Select("sessions_revoked_at", "updated_at").Updates(user).Error
+}
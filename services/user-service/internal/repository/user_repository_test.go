@@ -0,0 +1,85 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+
+	"user-service/internal/models"
+	"user-service/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens an in-memory database holding the users table
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1) // every connection would get its own in-memory database
+	t.Cleanup(func() { sqlDB.Close() })
+
+	// SQLite has no gen_random_uuid(), User's BeforeCreate sets the ID
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&models.User{}); err != nil {
+		t.Fatalf("failed to parse User: %v", err)
+	}
+	for _, field := range stmt.Schema.Fields {
+		if field.DefaultValue == "gen_random_uuid()" {
+			field.HasDefaultValue = false
+			field.DefaultValue = ""
+		}
+	}
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return db
+}
+
+func TestGetByLoginIdentifier(t *testing.T) {
+	repo := repository.NewUserRepository(newTestDB(t))
+	alice := &models.User{Username: "Alice", Email: "Alice@Example.com", PasswordHash: "hash", Type: "credential"}
+	bob := &models.User{Username: "bob", Email: "bob@example.com", PasswordHash: "hash", Type: "credential"}
+	for _, user := range []*models.User{alice, bob} {
+		if err := repo.Create(user); err != nil {
+			t.Fatalf("failed to create %s: %v", user.Username, err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		identifier string
+		want       *models.User // nil for not found
+	}{
+		{name: "email", identifier: "bob@example.com", want: bob},
+		{name: "email in another case", identifier: "alice@EXAMPLE.COM", want: alice},
+		{name: "username", identifier: "bob", want: bob},
+		{name: "username in another case", identifier: "aLiCe", want: alice},
+		{name: "unknown email", identifier: "carol@example.com"},
+		{name: "unknown username", identifier: "carol"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.GetByLoginIdentifier(tt.identifier)
+			if tt.want == nil {
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					t.Fatalf("GetByLoginIdentifier(%q) = %v, %v, want ErrRecordNotFound", tt.identifier, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetByLoginIdentifier(%q) failed: %v", tt.identifier, err)
+			}
+			if got.ID != tt.want.ID {
+				t.Errorf("GetByLoginIdentifier(%q) = %s, want %s", tt.identifier, got.Username, tt.want.Username)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"user-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// VerificationTokenStore abstracts email verification token persistence
+type VerificationTokenStore interface {
+	Issue(userID uuid.UUID, ttl time.Duration) (string, error)
+	GetByToken(token string) (*models.EmailVerificationToken, error)
+	MarkUsed(verificationToken *models.EmailVerificationToken) error
+}
+
+// VerificationTokenRepository handles email verification token database operations
+type VerificationTokenRepository struct {
+	db *gorm.DB
+}
+
+// Ensure VerificationTokenRepository implements VerificationTokenStore
+var _ VerificationTokenStore = (*VerificationTokenRepository)(nil)
+
+// NewVerificationTokenRepository creates a new verification token repository
+func NewVerificationTokenRepository(db *gorm.DB) *VerificationTokenRepository {
+	return &VerificationTokenRepository{
+		db: db,
+	}
+}
+
+// Issue creates a new random token for the user and returns its plain value
+func (r *VerificationTokenRepository) Issue(userID uuid.UUID, ttl time.Duration) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	verificationToken := models.EmailVerificationToken{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := r.db.Create(&verificationToken).Error; err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// GetByToken retrieves a token record by its plain value
+func (r *VerificationTokenRepository) GetByToken(token string) (*models.EmailVerificationToken, error) {
+	var verificationToken models.EmailVerificationToken
+	err := r.db.Where("token_hash = ?", hashToken(token)).First(&verificationToken).Error
+	if err != nil {
+		return nil, err
+	}
+	return &verificationToken, nil
+}
+
+// MarkUsed marks the token as consumed, failing if it was already used concurrently
+func (r *VerificationTokenRepository) MarkUsed(verificationToken *models.EmailVerificationToken) error {
+	now := time.Now()
+	result := r.db.Model(&models.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", verificationToken.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("token already used")
+	}
+
+	verificationToken.UsedAt = &now
+	return nil
+}
+
+// hashToken returns the hex encoded SHA-256 of a token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"user-service/internal/events"
+	"user-service/internal/repository"
+)
+
+// Actions taken on unverified accounts once their final reminder has gone unanswered
+const (
+	StaleAccountActionDelete = "delete"
+	StaleAccountActionFlag   = "flag"
+)
+
+// cleanupBatchSize bounds the accounts handled per step in a single run
+const cleanupBatchSize = 500
+
+// CleanupResult describes what a cleanup run did (or would do in dry-run mode)
+type CleanupResult struct {
+	DryRun          bool      `json:"dry_run"`
+	OTPsExpired     int64     `json:"otps_expired"`
+	RemindersSent   int       `json:"reminders_sent"`
+	AccountsDeleted int       `json:"accounts_deleted"`
+	AccountsFlagged int       `json:"accounts_flagged"`
+	Errors          int       `json:"errors"`
+	StartedAt       time.Time `json:"started_at"`
+	Duration        string    `json:"duration"`
+}
+
+// AccountCleanupService periodically expires stale OTPs and removes accounts that never
+// verified their email: a final reminder is sent after UNVERIFIED_ACCOUNT_MAX_DAYS and the
+// account is deleted (or flagged) when still unverified UNVERIFIED_ACCOUNT_GRACE_DAYS later
+type AccountCleanupService struct {
+	userRepo     *repository.UserRepository
+	eventService *events.EventService
+
+	interval   time.Duration
+	otpMaxAge  time.Duration
+	maxAge     time.Duration
+	grace      time.Duration
+	action     string
+	dryRun     bool
+	runMu      sync.Mutex
+	stopCh     chan struct{}
+	stopOnce   sync.Once
+	lastResult atomic.Pointer[CleanupResult]
+	metrics    *AuthMetrics // counts expired OTPs in the auth funnel, optional
+
+	// Totals since start, reported on /api/v1/admin/runtime
+	runs            atomic.Int64
+	otpsExpired     atomic.Int64
+	remindersSent   atomic.Int64
+	accountsDeleted atomic.Int64
+	accountsFlagged atomic.Int64
+	errors          atomic.Int64
+}
+
+// NewAccountCleanupService creates the cleanup worker from environment configuration
+func NewAccountCleanupService(userRepo *repository.UserRepository, eventService *events.EventService) *AccountCleanupService {
+	action := os.Getenv("UNVERIFIED_ACCOUNT_ACTION")
+	if action != StaleAccountActionFlag {
+		action = StaleAccountActionDelete
+	}
+
+	return &AccountCleanupService{
+		userRepo:     userRepo,
+		eventService: eventService,
+		interval:     getEnvDuration("ACCOUNT_CLEANUP_INTERVAL", time.Hour),
+		otpMaxAge:    getEnvDuration("OTP_MAX_AGE", time.Hour),
+		maxAge:       time.Duration(getEnvInt("UNVERIFIED_ACCOUNT_MAX_DAYS", 30)) * 24 * time.Hour,
+		grace:        time.Duration(getEnvInt("UNVERIFIED_ACCOUNT_GRACE_DAYS", 3)) * 24 * time.Hour,
+		action:       action,
+		dryRun:       getEnvBool("ACCOUNT_CLEANUP_DRY_RUN", false),
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start runs the cleanup immediately and then every ACCOUNT_CLEANUP_INTERVAL
+func (s *AccountCleanupService) Start() {
+	log.Printf("🧹 Account cleanup worker started (interval: %s, action: %s, dry run: %t)", s.interval, s.action, s.dryRun)
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.Run(s.dryRun)
+		for {
+			select {
+			case <-ticker.C:
+				s.Run(s.dryRun)
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// SetAuthMetrics counts the OTPs a real run expires in metrics
+func (s *AccountCleanupService) SetAuthMetrics(metrics *AuthMetrics) {
+	s.metrics = metrics
+}
+
+// Stop stops the background worker
+func (s *AccountCleanupService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+}
+
+// Run performs one cleanup pass. In dry-run mode nothing is changed and no email is sent,
+// the result reports what a real run would have done.
+func (s *AccountCleanupService) Run(dryRun bool) CleanupResult {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	now := time.Now()
+	result := CleanupResult{DryRun: dryRun, StartedAt: now}
+
+	s.expireOTPs(now, &result)
+	s.sendReminders(now, &result)
+	s.removeStaleAccounts(now, &result)
+
+	result.Duration = time.Since(now).String()
+	s.lastResult.Store(&result)
+
+	if !dryRun {
+		s.runs.Add(1)
+		s.otpsExpired.Add(result.OTPsExpired)
+		s.metrics.OTPsExpired(result.OTPsExpired)
+		s.remindersSent.Add(int64(result.RemindersSent))
+		s.accountsDeleted.Add(int64(result.AccountsDeleted))
+		s.accountsFlagged.Add(int64(result.AccountsFlagged))
+	}
+	s.errors.Add(int64(result.Errors))
+
+	log.Printf("🧹 Account cleanup finished (dry run: %t): %d OTPs expired, %d reminders, %d deleted, %d flagged, %d errors",
+		dryRun, result.OTPsExpired, result.RemindersSent, result.AccountsDeleted, result.AccountsFlagged, result.Errors)
+
+	return result
+}
+
+// expireOTPs clears OTP codes older than OTP_MAX_AGE
+func (s *AccountCleanupService) expireOTPs(now time.Time, result *CleanupResult) {
+	cutoff := now.Add(-s.otpMaxAge)
+
+	var err error
+	if result.DryRun {
+		result.OTPsExpired, err = s.userRepo.CountStaleOTPs(cutoff)
+	} else {
+		result.OTPsExpired, err = s.userRepo.ExpireOTPs(cutoff)
+	}
+	if err != nil {
+		log.Printf("❌ Failed to expire stale OTPs: %v", err)
+		result.Errors++
+	}
+}
+
+// sendReminders sends the final verification reminder to accounts unverified for too long
+func (s *AccountCleanupService) sendReminders(now time.Time, result *CleanupResult) {
+	if s.eventService == nil {
+		// Without a reminder no account ever becomes eligible for removal
+		log.Println("⚠️ Event service not available, skipping verification reminders")
+		return
+	}
+
+	users, err := s.userRepo.GetUnverifiedForReminder(now.Add(-s.maxAge), cleanupBatchSize)
+	if err != nil {
+		log.Printf("❌ Failed to load unverified accounts: %v", err)
+		result.Errors++
+		return
+	}
+
+	deadline := now.Add(s.grace)
+	for i := range users {
+		user := &users[i]
+		if result.DryRun {
+			result.RemindersSent++
+			continue
+		}
+
+		if err := s.eventService.PublishVerificationReminder(user.ID.String(), user.Username, user.Email, user.Locale, deadline); err != nil {
+			log.Printf("❌ Failed to publish verification reminder for %s: %v", user.Email, err)
+			result.Errors++
+			continue
+		}
+
+		if err := s.userRepo.MarkReminded(user); err != nil {
+			log.Printf("❌ Failed to mark reminder sent for %s: %v", user.Email, err)
+			result.Errors++
+			continue
+		}
+		result.RemindersSent++
+	}
+}
+
+// removeStaleAccounts deletes or flags accounts still unverified after the grace period
+func (s *AccountCleanupService) removeStaleAccounts(now time.Time, result *CleanupResult) {
+	if s.action != StaleAccountActionFlag && !result.DryRun && s.eventService == nil {
+		// The other services drop their data of a deleted account only on user.deleted
+		log.Println("⚠️ Event service not available, skipping stale account deletion")
+		return
+	}
+
+	users, err := s.userRepo.GetStaleUnverified(now.Add(-s.grace), cleanupBatchSize)
+	if err != nil {
+		log.Printf("❌ Failed to load stale accounts: %v", err)
+		result.Errors++
+		return
+	}
+
+	for i := range users {
+		user := &users[i]
+
+		if s.action == StaleAccountActionFlag {
+			if !result.DryRun {
+				if err := s.userRepo.FlagStale(user); err != nil {
+					log.Printf("❌ Failed to flag stale account %s: %v", user.Email, err)
+					result.Errors++
+					continue
+				}
+			}
+			result.AccountsFlagged++
+			continue
+		}
+
+		if result.DryRun {
+			result.AccountsDeleted++
+			continue
+		}
+
+		deleted, err := s.userRepo.DeleteUnverified(user)
+		if err != nil {
+			log.Printf("❌ Failed to delete stale account %s: %v", user.Email, err)
+			result.Errors++
+			continue
+		}
+		if deleted {
+			log.Printf("🗑️ Deleted unverified account %s (%s)", user.Username, user.Email)
+			result.AccountsDeleted++
+			if err := s.eventService.PublishUserDeleted(user.ID.String(), "unverified"); err != nil {
+				log.Printf("⚠️ Failed to publish user deleted event for %s: %v", user.ID, err)
+			}
+		}
+	}
+}
+
+// DryRun reports whether ACCOUNT_CLEANUP_DRY_RUN configures dry-run passes
+func (s *AccountCleanupService) DryRun() bool {
+	return s.dryRun
+}
+
+// Stats reports the worker configuration, totals and the last run
+func (s *AccountCleanupService) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"interval":         s.interval.String(),
+		"otp_max_age":      s.otpMaxAge.String(),
+		"max_age":          s.maxAge.String(),
+		"grace":            s.grace.String(),
+		"action":           s.action,
+		"dry_run":          s.dryRun,
+		"runs":             s.runs.Load(),
+		"otps_expired":     s.otpsExpired.Load(),
+		"reminders_sent":   s.remindersSent.Load(),
+		"accounts_deleted": s.accountsDeleted.Load(),
+		"accounts_flagged": s.accountsFlagged.Load(),
+		"errors":           s.errors.Load(),
+		"last_run":         s.lastResult.Load(),
+	}
+}
+
+// getEnvDuration reads a duration environment variable with a default
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
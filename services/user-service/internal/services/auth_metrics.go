@@ -0,0 +1,197 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// AuthMetrics counts the registration → OTP verification funnel of this instance: how many
+// accounts register, how many OTP emails go out or fail, how often users ask for another
+// code and how many codes are verified or expire unused. A low verification rate with few
+// send failures points at deliverability (spam folders, bounces) rather than SMTP.
+//
+// Methods are safe on a nil *AuthMetrics, which counts nothing.
+type AuthMetrics struct {
+	startedAt time.Time
+
+	registrations     atomic.Int64
+	otpSent           atomic.Int64
+	otpSendFailed     atomic.Int64
+	resendRequested   atomic.Int64
+	resendThrottled   atomic.Int64
+	verifiedOTP       atomic.Int64
+	verifiedLink      atomic.Int64
+	invalidOTP        atomic.Int64
+	expiredOTP        atomic.Int64
+	expiredLink       atomic.Int64
+	otpsExpiredUnused atomic.Int64 // cleared by the account cleanup
+}
+
+// AuthFunnelSummary is the GET /api/v1/admin/auth/metrics response
+type AuthFunnelSummary struct {
+	Since             string  `json:"since"`
+	Registrations     int64   `json:"registrations"`
+	OTPSent           int64   `json:"otp_sent"`
+	OTPSendFailed     int64   `json:"otp_send_failed"`
+	ResendRequested   int64   `json:"resend_requested"`
+	ResendThrottled   int64   `json:"resend_throttled"`
+	Verified          int64   `json:"verified"`
+	VerifiedByOTP     int64   `json:"verified_by_otp"`
+	VerifiedByLink    int64   `json:"verified_by_link"`
+	InvalidOTP        int64   `json:"invalid_otp"`
+	ExpiredOTP        int64   `json:"expired_otp"`  // verification attempted after the code was cleared
+	ExpiredLink       int64   `json:"expired_link"` // expired or used verification link clicked
+	OTPsExpiredUnused int64   `json:"otps_expired_unused"`
+	VerificationRate  float64 `json:"verification_rate"`  // verified / registrations
+	SendFailureRate   float64 `json:"send_failure_rate"`  // failed / (sent + failed)
+	ResendsPerSignup  float64 `json:"resends_per_signup"` // resend requests / registrations
+	ExpiredPerSignup  float64 `json:"expired_per_signup"` // unused expired OTPs / registrations
+}
+
+// NewAuthMetrics creates zeroed funnel counters
+func NewAuthMetrics() *AuthMetrics {
+	return &AuthMetrics{startedAt: time.Now()}
+}
+
+// Registered counts a new credential account
+func (m *AuthMetrics) Registered() {
+	if m != nil {
+		m.registrations.Add(1)
+	}
+}
+
+// OTPSent counts an OTP email handed to SMTP, or a failed attempt when err is set
+func (m *AuthMetrics) OTPSent(err error) {
+	if m == nil {
+		return
+	}
+	if err != nil {
+		m.otpSendFailed.Add(1)
+		return
+	}
+	m.otpSent.Add(1)
+}
+
+// ResendRequested counts a resend request, throttled ones separately
+func (m *AuthMetrics) ResendRequested(throttled bool) {
+	if m == nil {
+		return
+	}
+	if throttled {
+		m.resendThrottled.Add(1)
+		return
+	}
+	m.resendRequested.Add(1)
+}
+
+// VerifiedByOTP counts an account verified with the emailed code
+func (m *AuthMetrics) VerifiedByOTP() {
+	if m != nil {
+		m.verifiedOTP.Add(1)
+	}
+}
+
+// VerifiedByLink counts an account verified with the one-click link
+func (m *AuthMetrics) VerifiedByLink() {
+	if m != nil {
+		m.verifiedLink.Add(1)
+	}
+}
+
+// InvalidOTP counts a verification with a wrong code
+func (m *AuthMetrics) InvalidOTP() {
+	if m != nil {
+		m.invalidOTP.Add(1)
+	}
+}
+
+// ExpiredOTP counts a verification attempted after the code was cleared
+func (m *AuthMetrics) ExpiredOTP() {
+	if m != nil {
+		m.expiredOTP.Add(1)
+	}
+}
+
+// ExpiredLink counts a click on an expired or used verification link
+func (m *AuthMetrics) ExpiredLink() {
+	if m != nil {
+		m.expiredLink.Add(1)
+	}
+}
+
+// OTPsExpired counts codes the account cleanup cleared before they were used
+func (m *AuthMetrics) OTPsExpired(count int64) {
+	if m != nil {
+		m.otpsExpiredUnused.Add(count)
+	}
+}
+
+// Summary returns the counters and the funnel rates since start
+func (m *AuthMetrics) Summary() AuthFunnelSummary {
+	if m == nil {
+		return AuthFunnelSummary{}
+	}
+
+	s := AuthFunnelSummary{
+		Since:             m.startedAt.UTC().Format(time.RFC3339),
+		Registrations:     m.registrations.Load(),
+		OTPSent:           m.otpSent.Load(),
+		OTPSendFailed:     m.otpSendFailed.Load(),
+		ResendRequested:   m.resendRequested.Load(),
+		ResendThrottled:   m.resendThrottled.Load(),
+		VerifiedByOTP:     m.verifiedOTP.Load(),
+		VerifiedByLink:    m.verifiedLink.Load(),
+		InvalidOTP:        m.invalidOTP.Load(),
+		ExpiredOTP:        m.expiredOTP.Load(),
+		ExpiredLink:       m.expiredLink.Load(),
+		OTPsExpiredUnused: m.otpsExpiredUnused.Load(),
+	}
+	s.Verified = s.VerifiedByOTP + s.VerifiedByLink
+	s.VerificationRate = ratio(s.Verified, s.Registrations)
+	s.SendFailureRate = ratio(s.OTPSendFailed, s.OTPSent+s.OTPSendFailed)
+	s.ResendsPerSignup = ratio(s.ResendRequested, s.Registrations)
+	s.ExpiredPerSignup = ratio(s.OTPsExpiredUnused, s.Registrations)
+	return s
+}
+
+// Prometheus writes the counters in the Prometheus text format
+func (m *AuthMetrics) Prometheus() string {
+	s := m.Summary()
+
+	var b strings.Builder
+	writeCounter := func(name, help string, samples ...string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, sample := range samples {
+			fmt.Fprintf(&b, "%s%s\n", name, sample)
+		}
+	}
+
+	writeCounter("user_registrations_total", "Credential accounts registered.",
+		fmt.Sprintf(" %d", s.Registrations))
+	writeCounter("user_otp_emails_total", "OTP emails handed to SMTP per result.",
+		fmt.Sprintf(`{result="sent"} %d`, s.OTPSent),
+		fmt.Sprintf(`{result="failed"} %d`, s.OTPSendFailed))
+	writeCounter("user_otp_resends_total", "OTP resend requests per outcome.",
+		fmt.Sprintf(`{outcome="accepted"} %d`, s.ResendRequested),
+		fmt.Sprintf(`{outcome="throttled"} %d`, s.ResendThrottled))
+	writeCounter("user_verifications_total", "Accounts verified per method.",
+		fmt.Sprintf(`{method="otp"} %d`, s.VerifiedByOTP),
+		fmt.Sprintf(`{method="link"} %d`, s.VerifiedByLink))
+	writeCounter("user_verification_failures_total", "Verification attempts rejected per reason.",
+		fmt.Sprintf(`{reason="invalid_otp"} %d`, s.InvalidOTP),
+		fmt.Sprintf(`{reason="expired_otp"} %d`, s.ExpiredOTP),
+		fmt.Sprintf(`{reason="expired_link"} %d`, s.ExpiredLink))
+	writeCounter("user_otps_expired_total", "OTPs cleared by the account cleanup before they were used.",
+		fmt.Sprintf(" %d", s.OTPsExpiredUnused))
+	return b.String()
+}
+
+// ratio returns part / whole, 0 when whole is 0
+func ratio(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"user-service/internal/events"
+	"user-service/internal/models"
+	"user-service/internal/repository"
+)
+
+// CampaignService queues the emails of running announcement campaigns. Every
+// CAMPAIGN_INTERVAL (default 10s) it hands at most CAMPAIGN_BATCH_SIZE (default 50) emails
+// to the email consumer, one user.campaign.email event per recipient, so a campaign to the
+// whole user base trickles out instead of flooding the SMTP relay.
+type CampaignService struct {
+	campaignRepo repository.CampaignStore
+	eventService *events.EventService
+
+	interval  time.Duration
+	batchSize int
+	runMu     sync.Mutex
+	stopCh    chan struct{}
+	stopOnce  sync.Once
+
+	// Totals since start, reported on /api/v1/admin/runtime
+	queued    atomic.Int64
+	completed atomic.Int64
+	errors    atomic.Int64
+}
+
+// NewCampaignService creates the campaign worker from environment configuration
+func NewCampaignService(campaignRepo repository.CampaignStore, eventService *events.EventService) *CampaignService {
+	batchSize := getEnvInt("CAMPAIGN_BATCH_SIZE", 50)
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+
+	return &CampaignService{
+		campaignRepo: campaignRepo,
+		eventService: eventService,
+		interval:     getEnvDuration("CAMPAIGN_INTERVAL", 10*time.Second),
+		batchSize:    batchSize,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start queues emails every CAMPAIGN_INTERVAL until stopped
+func (s *CampaignService) Start() {
+	log.Printf("📣 Campaign worker started (%d emails every %s)", s.batchSize, s.interval)
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.Run()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background worker
+func (s *CampaignService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+}
+
+// Run queues the next batch, shared by the running campaigns oldest first
+func (s *CampaignService) Run() {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	if s.eventService == nil {
+		return
+	}
+
+	campaigns, err := s.campaignRepo.ListSending()
+	if err != nil {
+		log.Printf("❌ Failed to load running campaigns: %v", err)
+		s.errors.Add(1)
+		return
+	}
+
+	budget := s.batchSize
+	for i := range campaigns {
+		if budget <= 0 {
+			return
+		}
+		budget -= s.queueBatch(&campaigns[i], budget)
+	}
+}
+
+// queueBatch queues up to limit emails of campaign and returns how many it queued
+func (s *CampaignService) queueBatch(campaign *models.EmailCampaign, limit int) int {
+	users, err := s.campaignRepo.NextRecipients(campaign, limit)
+	if err != nil {
+		log.Printf("❌ Failed to load recipients of campaign %s: %v", campaign.ID, err)
+		s.errors.Add(1)
+		return 0
+	}
+
+	if len(users) == 0 {
+		if err := s.campaignRepo.Complete(campaign); err != nil {
+			log.Printf("❌ Failed to complete campaign %s: %v", campaign.ID, err)
+			s.errors.Add(1)
+			return 0
+		}
+		s.completed.Add(1)
+		log.Printf("📣 Campaign %s completed, %d emails queued", campaign.ID, campaign.Queued)
+		return 0
+	}
+
+	// Claim the batch first, another replica may be working on the same campaign
+	claimed, err := s.campaignRepo.Advance(campaign, users[len(users)-1].ID, len(users))
+	if err != nil {
+		log.Printf("❌ Failed to advance campaign %s: %v", campaign.ID, err)
+		s.errors.Add(1)
+		return 0
+	}
+	if !claimed {
+		return 0
+	}
+
+	campaignID := campaign.ID.String()
+	for i := range users {
+		user := &users[i]
+		if err := s.eventService.PublishCampaignEmail(campaignID, user.ID.String(), user.Username, user.Email, user.Locale); err != nil {
+			log.Printf("❌ Failed to queue campaign %s email for %s: %v", campaignID, user.Email, err)
+			s.errors.Add(1)
+		}
+	}
+
+	s.queued.Add(int64(len(users)))
+	log.Printf("📣 Campaign %s: queued %d emails (%d so far)", campaignID, len(users), campaign.Queued)
+	return len(users)
+}
+
+// Stats reports the worker configuration and totals
+func (s *CampaignService) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"interval":   s.interval.String(),
+		"batch_size": s.batchSize,
+		"queued":     s.queued.Load(),
+		"completed":  s.completed.Load(),
+		"errors":     s.errors.Load(),
+	}
+}
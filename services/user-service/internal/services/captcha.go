@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CAPTCHA providers selected with CAPTCHA_PROVIDER
+const (
+	CaptchaTurnstile = "turnstile"
+	CaptchaReCAPTCHA = "recaptcha"
+)
+
+// Verify errors for the client; other errors mean the provider couldn't be asked
+var (
+	ErrCaptchaRequired = errors.New("captcha token required")
+	ErrCaptchaInvalid  = errors.New("captcha token invalid")
+)
+
+// CaptchaVerifier validates the token a CAPTCHA widget gave the client, server side
+type CaptchaVerifier interface {
+	Name() string
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// NewCaptchaVerifierFromEnv creates the verifier selected by CAPTCHA_PROVIDER: "turnstile"
+// (Cloudflare) or "recaptcha" (Google, v2 or v3 with CAPTCHA_MIN_SCORE, default 0.5), both
+// with CAPTCHA_SECRET. It returns nil when CAPTCHA_PROVIDER is unset, suspicious clients are
+// then only locked out of verification.
+func NewCaptchaVerifierFromEnv() (CaptchaVerifier, error) {
+	secret := os.Getenv("CAPTCHA_SECRET")
+	switch provider := strings.ToLower(os.Getenv("CAPTCHA_PROVIDER")); provider {
+	case "":
+		return nil, nil
+	case CaptchaTurnstile:
+		return NewSiteVerifyCaptcha(CaptchaTurnstile, "https://challenges.cloudflare.com/turnstile/v0/siteverify", secret, 0)
+	case CaptchaReCAPTCHA:
+		minScore := 0.5
+		if value := os.Getenv("CAPTCHA_MIN_SCORE"); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				return nil, fmt.Errorf("invalid CAPTCHA_MIN_SCORE %q, expected 0 to 1", value)
+			}
+			minScore = parsed
+		}
+		return NewSiteVerifyCaptcha(CaptchaReCAPTCHA, "https://www.google.com/recaptcha/api/siteverify", secret, minScore)
+	default:
+		return nil, fmt.Errorf("unsupported CAPTCHA_PROVIDER %q, expected turnstile or recaptcha", provider)
+	}
+}
+
+// SiteVerifyCaptcha checks tokens with a siteverify endpoint, the API Turnstile shares with
+// reCAPTCHA
+type SiteVerifyCaptcha struct {
+	name     string
+	endpoint string
+	secret   string
+	minScore float64 // reCAPTCHA v3 scores below it fail, v2 and Turnstile send no score
+	client   *http.Client
+}
+
+// NewSiteVerifyCaptcha creates a verifier posting tokens to endpoint
+func NewSiteVerifyCaptcha(name, endpoint, secret string, minScore float64) (*SiteVerifyCaptcha, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("CAPTCHA_SECRET is required for CAPTCHA_PROVIDER=%s", name)
+	}
+	return &SiteVerifyCaptcha{
+		name:     name,
+		endpoint: endpoint,
+		secret:   secret,
+		minScore: minScore,
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}, nil
+}
+
+// Name returns the provider name
+func (sc *SiteVerifyCaptcha) Name() string {
+	return sc.name
+}
+
+// siteVerifyResponse is the siteverify result, score is only sent by reCAPTCHA v3
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	Score      *float64 `json:"score"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// Verify posts the token with the client's IP and fails unless the provider accepts it
+func (sc *SiteVerifyCaptcha) Verify(ctx context.Context, token, remoteIP string) error {
+	if strings.TrimSpace(token) == "" {
+		return ErrCaptchaRequired
+	}
+
+	form := url.Values{}
+	form.Set("secret", sc.secret)
+	form.Set("response", token)
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sc.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", sc.name, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := sc.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", sc.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned status %d: %s", sc.name, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result siteVerifyResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", sc.name, err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: %s rejected it (%s)", ErrCaptchaInvalid, sc.name, strings.Join(result.ErrorCodes, ", "))
+	}
+	if result.Score != nil && *result.Score < sc.minScore {
+		return fmt.Errorf("%w: %s score %.1f is below %.1f", ErrCaptchaInvalid, sc.name, *result.Score, sc.minScore)
+	}
+	return nil
+}
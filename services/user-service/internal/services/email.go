@@ -2,10 +2,15 @@ package services
 
 import (
 	"fmt"
+	"html"
 	"log"
 	"os"
+	"strings"
 	"time"
 
+	"user-service/internal/i18n"
+
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	"gopkg.in/gomail.v2"
 )
@@ -77,12 +82,24 @@ func NewEmailService() (*EmailService, error) {
 	}, nil
 }
 
-// SendOTPEmail sends OTP verification email
-func (es *EmailService) SendOTPEmail(to, username, otp string) error {
-	subject := "Verifikasi Email - ZACloth"
+// SendOTPEmail sends OTP verification email and returns the message ID.
+// When verificationURL is set the email also contains a one-click verification link.
+func (es *EmailService) SendOTPEmail(to, username, otp, verificationURL string, locale i18n.Locale) (string, error) {
+	subject := i18n.T(locale, "email.otp.subject")
+
+	verificationLink := ""
+	if verificationURL != "" {
+		verificationLink = fmt.Sprintf(`<p>%s</p>
+            <p style="text-align: center;"><a href="%s" class="button">%s</a></p>`,
+			i18n.T(locale, "email.otp.link_intro"),
+			html.EscapeString(verificationURL),
+			i18n.T(locale, "email.otp.link_button"),
+		)
+	}
+
 	body := fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
     <meta charset="UTF-8">
     <title>%s</title>
@@ -99,26 +116,40 @@ func (es *EmailService) SendOTPEmail(to, username, otp string) error {
 <body>
     <div class="container">
         <div class="header">
-            <h1>🎉 Selamat Datang di ZACloth!</h1>
+            <h1>%s</h1>
         </div>
         <div class="content">
-            <h2>Halo %s!</h2>
-            <p>Terima kasih telah mendaftar di ZACloth. Untuk melengkapi proses pendaftaran, silakan verifikasi email Anda dengan kode OTP berikut:</p>
+            <h2>%s</h2>
+            <p>%s</p>
             
             <div class="otp-code">%s</div>
             
-            <p><strong>Kode ini berlaku selama 10 menit.</strong></p>
+            %s
             
-            <p>Jika Anda tidak mendaftar di ZACloth, silakan abaikan email ini.</p>
+            <p><strong>%s</strong></p>
             
-            <p>Terima kasih,<br>Tim ZACloth</p>
+            <p>%s</p>
+            
+            <p>%s</p>
         </div>
         <div class="footer">
-            <p>Email ini dikirim secara otomatis, mohon tidak membalas email ini.</p>
+            <p>%s</p>
         </div>
     </div>
 </body>
-</html>`, subject, username, otp)
+</html>`,
+		locale,
+		subject,
+		i18n.T(locale, "email.otp.heading"),
+		i18n.T(locale, "email.greeting", username),
+		i18n.T(locale, "email.otp.intro"),
+		otp,
+		verificationLink,
+		i18n.T(locale, "email.otp.validity"),
+		i18n.T(locale, "email.otp.ignore"),
+		i18n.T(locale, "email.signoff"),
+		i18n.T(locale, "email.footer"),
+	)
 
 	return es.SendEmail(EmailData{
 		To:      to,
@@ -128,11 +159,11 @@ func (es *EmailService) SendOTPEmail(to, username, otp string) error {
 }
 
 // SendWelcomeEmail sends welcome email after verification
-func (es *EmailService) SendWelcomeEmail(to, username string) error {
-	subject := "Selamat! Akun Anda Telah Terverifikasi - ZACloth"
+func (es *EmailService) SendWelcomeEmail(to, username string, locale i18n.Locale) (string, error) {
+	subject := i18n.T(locale, "email.welcome.subject")
 	body := fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
     <meta charset="UTF-8">
     <title>%s</title>
@@ -148,30 +179,44 @@ func (es *EmailService) SendWelcomeEmail(to, username string) error {
 <body>
     <div class="container">
         <div class="header">
-            <h1>🎉 Selamat Datang di ZACloth!</h1>
+            <h1>%s</h1>
         </div>
         <div class="content">
-            <h2>Halo %s!</h2>
-            <p>Selamat! Email Anda telah berhasil diverifikasi. Akun ZACloth Anda sekarang sudah aktif dan siap digunakan.</p>
+            <h2>%s</h2>
+            <p>%s</p>
             
-            <p>Anda sekarang dapat:</p>
+            <p>%s</p>
             <ul>
-                <li>✅ Login ke akun Anda</li>
-                <li>🛍️ Berbelanja produk terbaru</li>
-                <li>💳 Mengelola profil dan preferensi</li>
-                <li>📱 Mengakses semua fitur ZACloth</li>
+                <li>%s</li>
+                <li>%s</li>
+                <li>%s</li>
+                <li>%s</li>
             </ul>
             
-            <p>Terima kasih telah bergabung dengan ZACloth!</p>
+            <p>%s</p>
             
-            <p>Terima kasih,<br>Tim ZACloth</p>
+            <p>%s</p>
         </div>
         <div class="footer">
-            <p>Email ini dikirim secara otomatis, mohon tidak membalas email ini.</p>
+            <p>%s</p>
         </div>
     </div>
 </body>
-</html>`, subject, username)
+</html>`,
+		locale,
+		subject,
+		i18n.T(locale, "email.welcome.heading"),
+		i18n.T(locale, "email.greeting", username),
+		i18n.T(locale, "email.welcome.intro"),
+		i18n.T(locale, "email.welcome.features"),
+		i18n.T(locale, "email.welcome.feature1"),
+		i18n.T(locale, "email.welcome.feature2"),
+		i18n.T(locale, "email.welcome.feature3"),
+		i18n.T(locale, "email.welcome.feature4"),
+		i18n.T(locale, "email.welcome.closing"),
+		i18n.T(locale, "email.signoff"),
+		i18n.T(locale, "email.footer"),
+	)
 
 	return es.SendEmail(EmailData{
 		To:      to,
@@ -181,11 +226,11 @@ func (es *EmailService) SendWelcomeEmail(to, username string) error {
 }
 
 // SendPasswordResetEmail sends password reset OTP email
-func (es *EmailService) SendPasswordResetEmail(to, username, otp string) error {
-	subject := "Reset Password - ZACloth"
+func (es *EmailService) SendPasswordResetEmail(to, username, otp string, locale i18n.Locale) (string, error) {
+	subject := i18n.T(locale, "email.reset.subject")
 	body := fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
     <meta charset="UTF-8">
     <title>%s</title>
@@ -202,33 +247,47 @@ func (es *EmailService) SendPasswordResetEmail(to, username, otp string) error {
 <body>
     <div class="container">
         <div class="header">
-            <h1>🔐 Reset Password - ZACloth</h1>
+            <h1>%s</h1>
         </div>
         <div class="content">
-            <h2>Halo %s!</h2>
-            <p>Kami menerima permintaan untuk mereset password akun ZACloth Anda. Gunakan kode verifikasi berikut untuk melanjutkan:</p>
+            <h2>%s</h2>
+            <p>%s</p>
             
             <div class="otp-code">%s</div>
             
             <div class="warning">
-                <strong>⚠️ Penting:</strong>
+                <strong>%s</strong>
                 <ul>
-                    <li>Kode ini berlaku selama 10 menit</li>
-                    <li>Jangan bagikan kode ini kepada siapa pun</li>
-                    <li>Jika Anda tidak meminta reset password, abaikan email ini</li>
+                    <li>%s</li>
+                    <li>%s</li>
+                    <li>%s</li>
                 </ul>
             </div>
             
-            <p>Jika Anda tidak meminta reset password, silakan abaikan email ini dan password Anda akan tetap aman.</p>
+            <p>%s</p>
             
-            <p>Terima kasih,<br>Tim ZACloth</p>
+            <p>%s</p>
         </div>
         <div class="footer">
-            <p>Email ini dikirim secara otomatis, mohon tidak membalas email ini.</p>
+            <p>%s</p>
         </div>
     </div>
 </body>
-</html>`, subject, username, otp)
+</html>`,
+		locale,
+		subject,
+		i18n.T(locale, "email.reset.heading"),
+		i18n.T(locale, "email.greeting", username),
+		i18n.T(locale, "email.reset.intro"),
+		otp,
+		i18n.T(locale, "email.reset.important"),
+		i18n.T(locale, "email.reset.note1"),
+		i18n.T(locale, "email.reset.note2"),
+		i18n.T(locale, "email.reset.note3"),
+		i18n.T(locale, "email.reset.ignore"),
+		i18n.T(locale, "email.signoff"),
+		i18n.T(locale, "email.footer"),
+	)
 
 	return es.SendEmail(EmailData{
 		To:      to,
@@ -238,11 +297,12 @@ func (es *EmailService) SendPasswordResetEmail(to, username, otp string) error {
 }
 
 // SendPasswordResetSuccessEmail sends password reset success email
-func (es *EmailService) SendPasswordResetSuccessEmail(to, username string) error {
-	subject := "Password Berhasil Direset - ZACloth"
+func (es *EmailService) SendPasswordResetSuccessEmail(to, username string, locale i18n.Locale) (string, error) {
+	subject := i18n.T(locale, "email.reset_success.subject")
+	resetAt := time.Now().Format(i18n.T(locale, "email.datetime_layout"))
 	body := fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
     <meta charset="UTF-8">
     <title>%s</title>
@@ -258,31 +318,403 @@ func (es *EmailService) SendPasswordResetSuccessEmail(to, username string) error
 <body>
     <div class="container">
         <div class="header">
-            <h1>✅ Password Berhasil Direset!</h1>
+            <h1>%s</h1>
         </div>
         <div class="content">
-            <h2>Halo %s!</h2>
-            <p>Password akun ZACloth Anda telah berhasil direset pada %s.</p>
+            <h2>%s</h2>
+            <p>%s</p>
             
             <div class="success">
-                <strong>✅ Konfirmasi:</strong>
+                <strong>%s</strong>
+                <ul>
+                    <li>%s</li>
+                    <li>%s</li>
+                    <li>%s</li>
+                </ul>
+            </div>
+            
+            <p>%s</p>
+            
+            <p>%s</p>
+        </div>
+        <div class="footer">
+            <p>%s</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		locale,
+		subject,
+		i18n.T(locale, "email.reset_success.heading"),
+		i18n.T(locale, "email.greeting", username),
+		i18n.T(locale, "email.reset_success.intro", resetAt),
+		i18n.T(locale, "email.reset_success.confirm"),
+		i18n.T(locale, "email.reset_success.note1"),
+		i18n.T(locale, "email.reset_success.note2"),
+		i18n.T(locale, "email.reset_success.note3"),
+		i18n.T(locale, "email.reset_success.support"),
+		i18n.T(locale, "email.signoff"),
+		i18n.T(locale, "email.footer"),
+	)
+
+	return es.SendEmail(EmailData{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// SendVerificationReminderEmail sends the final reminder before an unverified account is removed
+func (es *EmailService) SendVerificationReminderEmail(to, username, verificationURL string, deadline time.Time, locale i18n.Locale) (string, error) {
+	subject := i18n.T(locale, "email.reminder.subject")
+
+	verificationLink := ""
+	if verificationURL != "" {
+		verificationLink = fmt.Sprintf(`<p style="text-align: center;"><a href="%s" class="button">%s</a></p>`,
+			html.EscapeString(verificationURL),
+			i18n.T(locale, "email.reminder.button"),
+		)
+	}
+
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="%s">
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .content { background: #f9f9f9; padding: 30px; border-radius: 0 0 10px 10px; }
+        .footer { text-align: center; margin-top: 30px; color: #666; font-size: 14px; }
+        .button { background: #667eea; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; display: inline-block; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>%s</h1>
+        </div>
+        <div class="content">
+            <h2>%s</h2>
+            <p>%s</p>
+            
+            <p><strong>%s</strong></p>
+            
+            %s
+            
+            <p>%s</p>
+            
+            <p>%s</p>
+        </div>
+        <div class="footer">
+            <p>%s</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		locale,
+		subject,
+		i18n.T(locale, "email.reminder.heading"),
+		i18n.T(locale, "email.greeting", username),
+		i18n.T(locale, "email.reminder.intro"),
+		i18n.T(locale, "email.reminder.deadline", deadline.Format(i18n.T(locale, "email.datetime_layout"))),
+		verificationLink,
+		i18n.T(locale, "email.reminder.ignore"),
+		i18n.T(locale, "email.signoff"),
+		i18n.T(locale, "email.footer"),
+	)
+
+	return es.SendEmail(EmailData{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// SendLoginAlertEmail sends the "was this you?" alert for a login from a new device or location.
+// When revokeURL is set the email contains a one-click link signing out every session.
+func (es *EmailService) SendLoginAlertEmail(to, username, ipAddress, userAgent string, loginAt time.Time, revokeURL string, locale i18n.Locale) (string, error) {
+	subject := i18n.T(locale, "email.login_alert.subject")
+
+	revokeLink := ""
+	if revokeURL != "" {
+		revokeLink = fmt.Sprintf(`<p>%s</p>
+            <p style="text-align: center;"><a href="%s" class="button">%s</a></p>`,
+			i18n.T(locale, "email.login_alert.if_not"),
+			html.EscapeString(revokeURL),
+			i18n.T(locale, "email.login_alert.button"),
+		)
+	}
+
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="%s">
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #e67e22 0%%, #e74c3c 100%%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .content { background: #f9f9f9; padding: 30px; border-radius: 0 0 10px 10px; }
+        .footer { text-align: center; margin-top: 30px; color: #666; font-size: 14px; }
+        .details { background: #fff3cd; border: 1px solid #ffeaa7; color: #856404; padding: 15px; border-radius: 5px; margin: 20px 0; }
+        .button { background: #e74c3c; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; display: inline-block; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>%s</h1>
+        </div>
+        <div class="content">
+            <h2>%s</h2>
+            <p>%s</p>
+            
+            <div class="details">
                 <ul>
-                    <li>Password baru Anda telah aktif</li>
-                    <li>Anda telah otomatis login ke akun</li>
-                    <li>Semua sesi sebelumnya telah diakhiri</li>
+                    <li>%s</li>
+                    <li>%s</li>
+                    <li>%s</li>
+                </ul>
+            </div>
+            
+            <p>%s</p>
+            
+            %s
+            
+            <p>%s</p>
+            
+            <p>%s</p>
+        </div>
+        <div class="footer">
+            <p>%s</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		locale,
+		subject,
+		i18n.T(locale, "email.login_alert.heading"),
+		i18n.T(locale, "email.greeting", username),
+		i18n.T(locale, "email.login_alert.intro"),
+		i18n.T(locale, "email.login_alert.time", loginAt.Format(i18n.T(locale, "email.datetime_layout"))),
+		i18n.T(locale, "email.login_alert.ip", html.EscapeString(ipAddress)),
+		i18n.T(locale, "email.login_alert.device", html.EscapeString(userAgent)),
+		i18n.T(locale, "email.login_alert.if_you"),
+		revokeLink,
+		i18n.T(locale, "email.login_alert.configure"),
+		i18n.T(locale, "email.signoff"),
+		i18n.T(locale, "email.footer"),
+	)
+
+	return es.SendEmail(EmailData{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// OrderSummary is the paid order shown in receipt and sale emails
+type OrderSummary struct {
+	OrderID       string
+	ProductName   string
+	Quantity      int
+	TotalAmount   int64 // Minor units of Currency
+	Currency      string
+	PaymentMethod string
+	Bank          string
+	VANumber      string // Already masked by Payment-Service
+	PaidAt        time.Time
+}
+
+// SendOrderReceiptEmail sends the buyer the summary of a paid order
+func (es *EmailService) SendOrderReceiptEmail(to, username string, order OrderSummary, locale i18n.Locale) (string, error) {
+	subject := i18n.T(locale, "email.receipt.subject", order.OrderID)
+	return es.sendOrderEmail(to, subject, "email.receipt", username, order, locale)
+}
+
+// SendSaleNotificationEmail tells the product owner one of their products was paid for
+func (es *EmailService) SendSaleNotificationEmail(to, username string, order OrderSummary, locale i18n.Locale) (string, error) {
+	subject := i18n.T(locale, "email.sale.subject", order.OrderID)
+	return es.sendOrderEmail(to, subject, "email.sale", username, order, locale)
+}
+
+// sendOrderEmail renders the order summary with the heading, intro and closing of the
+// email.receipt or email.sale messages
+func (es *EmailService) sendOrderEmail(to, subject, messages, username string, order OrderSummary, locale i18n.Locale) (string, error) {
+	details := []string{i18n.T(locale, "email.order.id", html.EscapeString(order.OrderID))}
+	if order.ProductName != "" {
+		details = append(details, i18n.T(locale, "email.order.product", html.EscapeString(order.ProductName)))
+	}
+	details = append(details,
+		i18n.T(locale, "email.order.quantity", order.Quantity),
+		i18n.T(locale, "email.order.amount", formatAmount(order.TotalAmount, order.Currency)),
+	)
+
+	method := order.PaymentMethod
+	if order.Bank != "" {
+		method = fmt.Sprintf("%s (%s)", method, strings.ToUpper(order.Bank))
+	}
+	details = append(details, i18n.T(locale, "email.order.method", html.EscapeString(method)))
+	if order.VANumber != "" {
+		details = append(details, i18n.T(locale, "email.order.va", html.EscapeString(order.VANumber)))
+	}
+	details = append(details, i18n.T(locale, "email.order.paid_at", order.PaidAt.Format(i18n.T(locale, "email.datetime_layout"))))
+
+	return es.sendDetailsEmail(to, subject, messages, username, details, locale)
+}
+
+// InvoiceReminder is the unpaid invoice shown in invoice reminder emails
+type InvoiceReminder struct {
+	OrderID     string
+	ProductName string
+	TotalAmount int64 // Minor units of Currency
+	Currency    string
+	DueDate     time.Time
+	Overdue     bool
+}
+
+// SendInvoiceReminderEmail reminds the buyer of an invoice due soon, or past its due date
+func (es *EmailService) SendInvoiceReminderEmail(to, username string, invoice InvoiceReminder, locale i18n.Locale) (string, error) {
+	messages := "email.invoice_due"
+	if invoice.Overdue {
+		messages = "email.invoice_overdue"
+	}
+	subject := i18n.T(locale, messages+".subject", invoice.OrderID)
+
+	details := []string{i18n.T(locale, "email.order.id", html.EscapeString(invoice.OrderID))}
+	if invoice.ProductName != "" {
+		details = append(details, i18n.T(locale, "email.order.product", html.EscapeString(invoice.ProductName)))
+	}
+	details = append(details,
+		i18n.T(locale, "email.order.amount", formatAmount(invoice.TotalAmount, invoice.Currency)),
+		i18n.T(locale, "email.order.due_date", invoice.DueDate.Format(i18n.T(locale, "email.datetime_layout"))),
+	)
+
+	return es.sendDetailsEmail(to, subject, messages, username, details, locale)
+}
+
+// sendDetailsEmail renders the order details template with the heading, intro and closing
+// of messages
+func (es *EmailService) sendDetailsEmail(to, subject, messages, username string, details []string, locale i18n.Locale) (string, error) {
+	items := ""
+	for _, detail := range details {
+		items += fmt.Sprintf("\n                    <li>%s</li>", detail)
+	}
+
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="%s">
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #27ae60 0%%, #2ecc71 100%%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .content { background: #f9f9f9; padding: 30px; border-radius: 0 0 10px 10px; }
+        .footer { text-align: center; margin-top: 30px; color: #666; font-size: 14px; }
+        .details { background: #ffffff; border: 1px solid #dddddd; padding: 15px; border-radius: 5px; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>%s</h1>
+        </div>
+        <div class="content">
+            <h2>%s</h2>
+            <p>%s</p>
+            
+            <div class="details">
+                <ul>%s
                 </ul>
             </div>
             
-            <p>Jika Anda tidak melakukan reset password ini, segera hubungi tim support kami.</p>
+            <p>%s</p>
+            
+            <p>%s</p>
+        </div>
+        <div class="footer">
+            <p>%s</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		locale,
+		subject,
+		i18n.T(locale, messages+".heading"),
+		i18n.T(locale, "email.greeting", username),
+		i18n.T(locale, messages+".intro"),
+		items,
+		i18n.T(locale, messages+".closing"),
+		i18n.T(locale, "email.signoff"),
+		i18n.T(locale, "email.footer"),
+	)
+
+	return es.SendEmail(EmailData{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// SendCampaignEmail sends an announcement campaign email. The body is plain text: {{username}}
+// is replaced with the recipient's username, it is HTML escaped and blank lines separate
+// paragraphs.
+func (es *EmailService) SendCampaignEmail(to, username, subject, text string, locale i18n.Locale) (string, error) {
+	text = strings.ReplaceAll(text, "{{username}}", username)
+
+	paragraphs := ""
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			paragraphs += fmt.Sprintf("\n            <p>%s</p>", strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br>"))
+		}
+	}
+
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="%s">
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .content { background: #f9f9f9; padding: 30px; border-radius: 0 0 10px 10px; }
+        .footer { text-align: center; margin-top: 30px; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>%s</h1>
+        </div>
+        <div class="content">
+            <h2>%s</h2>%s
             
-            <p>Terima kasih,<br>Tim ZACloth</p>
+            <p>%s</p>
         </div>
         <div class="footer">
-            <p>Email ini dikirim secara otomatis, mohon tidak membalas email ini.</p>
+            <p>%s</p>
+            <p>%s</p>
         </div>
     </div>
 </body>
-</html>`, subject, username, time.Now().Format("02 Januari 2006, 15:04 WIB"))
+</html>`,
+		locale,
+		html.EscapeString(subject),
+		html.EscapeString(subject),
+		i18n.T(locale, "email.greeting", html.EscapeString(username)),
+		paragraphs,
+		i18n.T(locale, "email.signoff"),
+		i18n.T(locale, "email.campaign.preferences"),
+		i18n.T(locale, "email.footer"),
+	)
 
 	return es.SendEmail(EmailData{
 		To:      to,
@@ -291,9 +723,30 @@ func (es *EmailService) SendPasswordResetSuccessEmail(to, username string) error
 	})
 }
 
-// SendEmail sends a generic email
-func (es *EmailService) SendEmail(emailData EmailData) error {
+// formatAmount formats minor units for display: "Rp 150.000" for rupiah, "USD 1,500" otherwise
+func formatAmount(amount int64, currency string) string {
+	separator, prefix := ",", currency+" "
+	if currency == "" || currency == "IDR" {
+		separator, prefix = ".", "Rp "
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	digits := fmt.Sprint(amount)
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + separator + digits[i:]
+	}
+	return sign + prefix + digits
+}
+
+// SendEmail sends a generic email and returns the Message-ID it was sent with
+func (es *EmailService) SendEmail(emailData EmailData) (string, error) {
+	messageID := es.newMessageID()
+
 	m := gomail.NewMessage()
+	m.SetHeader("Message-ID", messageID)
 	m.SetHeader("From", fmt.Sprintf("%s <%s>", es.fromName, es.fromEmail))
 	m.SetHeader("To", emailData.To)
 	m.SetHeader("Subject", emailData.Subject)
@@ -302,11 +755,31 @@ func (es *EmailService) SendEmail(emailData EmailData) error {
 	d := gomail.NewDialer(es.smtpHost, es.smtpPort, es.smtpUsername, es.smtpPassword)
 
 	if err := d.DialAndSend(m); err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+		return messageID, fmt.Errorf("failed to send email: %w", err)
 	}
 
 	log.Printf("✅ Email sent successfully to: %s", emailData.To)
-	return nil
+	return messageID, nil
+}
+
+// newMessageID generates a unique RFC 5322 Message-ID on the sender domain
+func (es *EmailService) newMessageID() string {
+	domain := "localhost"
+	if idx := strings.LastIndex(es.fromEmail, "@"); idx >= 0 {
+		domain = es.fromEmail[idx+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", uuid.New().String(), domain)
+}
+
+// Ping connects and authenticates to the SMTP server without sending anything
+func (es *EmailService) Ping() error {
+	d := gomail.NewDialer(es.smtpHost, es.smtpPort, es.smtpUsername, es.smtpPassword)
+
+	closer, err := d.Dial()
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server %s:%d: %w", es.smtpHost, es.smtpPort, err)
+	}
+	return closer.Close()
 }
 
 // HealthCheck checks if email service is properly configured
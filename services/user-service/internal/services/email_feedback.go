@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"user-service/internal/models"
+)
+
+// Email providers posting bounce and complaint webhooks
+const (
+	EmailProviderSES      = "ses"
+	EmailProviderSendGrid = "sendgrid"
+)
+
+// EmailFeedback is a bounce or complaint the email provider reported for one recipient
+type EmailFeedback struct {
+	Email     string
+	Reason    string // models.SuppressionReasonBounce or models.SuppressionReasonComplaint
+	Detail    string
+	MessageID string
+}
+
+// snsMessage is the envelope Amazon SNS posts SES notifications in
+type snsMessage struct {
+	Type         string `json:"Type"`
+	TopicArn     string `json:"TopicArn"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+// sesNotification is an SES bounce, complaint or delivery notification
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	Bounce           struct {
+		BounceType        string `json:"bounceType"`
+		BounceSubType     string `json:"bounceSubType"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplaintFeedbackType string `json:"complaintFeedbackType"`
+		ComplainedRecipients  []struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"complainedRecipients"`
+	} `json:"complaint"`
+	Mail struct {
+		MessageID string `json:"messageId"`
+	} `json:"mail"`
+}
+
+// ParseSESNotification reads an SNS post of SES notifications. Subscription confirmations
+// return the URL to confirm the subscription with and no feedback. Transient bounces
+// (a full mailbox, a greylisting server) are left out, SES keeps retrying those itself.
+func ParseSESNotification(body []byte) (feedback []EmailFeedback, subscribeURL string, err error) {
+	var envelope snsMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, "", fmt.Errorf("invalid SNS message: %w", err)
+	}
+
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		return nil, envelope.SubscribeURL, nil
+	case "Notification":
+	default:
+		return nil, "", nil
+	}
+
+	var notification sesNotification
+	if err := json.Unmarshal([]byte(envelope.Message), &notification); err != nil {
+		return nil, "", fmt.Errorf("invalid SES notification: %w", err)
+	}
+
+	switch notification.NotificationType {
+	case "Bounce":
+		if notification.Bounce.BounceType != "Permanent" {
+			return nil, "", nil
+		}
+		for _, recipient := range notification.Bounce.BouncedRecipients {
+			detail := recipient.DiagnosticCode
+			if detail == "" {
+				detail = "permanent bounce: " + notification.Bounce.BounceSubType
+			}
+			feedback = append(feedback, EmailFeedback{
+				Email:     recipient.EmailAddress,
+				Reason:    models.SuppressionReasonBounce,
+				Detail:    detail,
+				MessageID: notification.Mail.MessageID,
+			})
+		}
+	case "Complaint":
+		for _, recipient := range notification.Complaint.ComplainedRecipients {
+			feedback = append(feedback, EmailFeedback{
+				Email:     recipient.EmailAddress,
+				Reason:    models.SuppressionReasonComplaint,
+				Detail:    notification.Complaint.ComplaintFeedbackType,
+				MessageID: notification.Mail.MessageID,
+			})
+		}
+	}
+	return feedback, "", nil
+}
+
+// sendGridEvent is one entry of a SendGrid Event Webhook post
+type sendGridEvent struct {
+	Email     string `json:"email"`
+	Event     string `json:"event"`
+	Type      string `json:"type"` // bounce or blocked, for bounce events
+	Reason    string `json:"reason"`
+	MessageID string `json:"sg_message_id"`
+}
+
+// ParseSendGridEvents reads a SendGrid Event Webhook post. Hard bounces, drops of addresses
+// SendGrid already knows bounce, and spam reports are kept; blocks are temporary and every
+// other event (delivered, open, click...) is ignored.
+func ParseSendGridEvents(body []byte) ([]EmailFeedback, error) {
+	var sgEvents []sendGridEvent
+	if err := json.Unmarshal(body, &sgEvents); err != nil {
+		return nil, fmt.Errorf("invalid SendGrid events: %w", err)
+	}
+
+	var feedback []EmailFeedback
+	for _, event := range sgEvents {
+		entry := EmailFeedback{Email: event.Email, Detail: event.Reason, MessageID: event.MessageID}
+		switch {
+		case event.Event == "bounce" && event.Type != "blocked":
+			entry.Reason = models.SuppressionReasonBounce
+		case event.Event == "dropped" && strings.Contains(strings.ToLower(event.Reason), "bounce"):
+			entry.Reason = models.SuppressionReasonBounce
+		case event.Event == "spamreport":
+			entry.Reason = models.SuppressionReasonComplaint
+		default:
+			continue
+		}
+		feedback = append(feedback, entry)
+	}
+	return feedback, nil
+}
+
+// ConfirmSNSSubscription visits the SubscribeURL of an SNS subscription confirmation. Only
+// HTTPS URLs on amazonaws.com are followed, the request body is not trusted otherwise.
+func ConfirmSNSSubscription(ctx context.Context, subscribeURL string) error {
+	parsed, err := url.Parse(subscribeURL)
+	if err != nil || parsed.Scheme != "https" || !strings.HasSuffix(parsed.Hostname(), ".amazonaws.com") {
+		return fmt.Errorf("refusing SNS SubscribeURL %q", subscribeURL)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to confirm SNS subscription: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("SNS subscription confirmation returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Password policy rules
+const (
+	PasswordRuleMinLength = "min_length"
+	PasswordRuleUppercase = "uppercase"
+	PasswordRuleLowercase = "lowercase"
+	PasswordRuleDigit     = "digit"
+	PasswordRuleSymbol    = "symbol"
+	PasswordRuleCommon    = "common_password"
+	PasswordRulePersonal  = "personal_info"
+	PasswordRuleBreached  = "breached"
+)
+
+// PasswordViolation describes a single failed password rule
+type PasswordViolation struct {
+	Rule  string `json:"rule"`
+	Param int    `json:"param,omitempty"`
+}
+
+// PasswordPolicyService validates passwords against the configured policy
+type PasswordPolicyService struct {
+	minLength     int
+	requireUpper  bool
+	requireLower  bool
+	requireDigit  bool
+	requireSymbol bool
+	checkBreached bool
+	pwnedAPIURL   string
+	httpClient    *http.Client
+}
+
+// commonPasswords is a small blacklist of the most used passwords
+var commonPasswords = map[string]bool{
+	"123456": true, "1234567": true, "12345678": true, "123456789": true, "1234567890": true,
+	"password": true, "password1": true, "password123": true, "passw0rd": true, "p@ssw0rd": true,
+	"qwerty": true, "qwerty123": true, "qwertyuiop": true, "abc123": true, "abcd1234": true,
+	"111111": true, "000000": true, "123123": true, "654321": true, "iloveyou": true,
+	"admin": true, "admin123": true, "welcome": true, "welcome1": true, "letmein": true,
+	"monkey": true, "dragon": true, "football": true, "sunshine": true, "princess": true,
+	"indonesia": true, "bismillah": true, "sayang": true, "rahasia": true, "katasandi": true,
+}
+
+// NewPasswordPolicyService creates a password policy from environment configuration
+func NewPasswordPolicyService() *PasswordPolicyService {
+	pwnedAPIURL := os.Getenv("PWNED_PASSWORDS_API_URL")
+	if pwnedAPIURL == "" {
+		pwnedAPIURL = "https://api.pwnedpasswords.com/range/"
+	}
+
+	return &PasswordPolicyService{
+		minLength:     getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		requireUpper:  getEnvBool("PASSWORD_REQUIRE_UPPERCASE", true),
+		requireLower:  getEnvBool("PASSWORD_REQUIRE_LOWERCASE", true),
+		requireDigit:  getEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+		requireSymbol: getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		checkBreached: getEnvBool("PASSWORD_CHECK_BREACHED", false),
+		pwnedAPIURL:   pwnedAPIURL,
+		httpClient:    &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// Validate checks a password and returns every rule it violates.
+// username and email are used to reject passwords containing personal info.
+func (pp *PasswordPolicyService) Validate(password, username, email string) []PasswordViolation {
+	var violations []PasswordViolation
+
+	if len([]rune(password)) < pp.minLength {
+		violations = append(violations, PasswordViolation{Rule: PasswordRuleMinLength, Param: pp.minLength})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char) || unicode.IsSpace(char):
+			hasSymbol = true
+		}
+	}
+
+	if pp.requireUpper && !hasUpper {
+		violations = append(violations, PasswordViolation{Rule: PasswordRuleUppercase})
+	}
+	if pp.requireLower && !hasLower {
+		violations = append(violations, PasswordViolation{Rule: PasswordRuleLowercase})
+	}
+	if pp.requireDigit && !hasDigit {
+		violations = append(violations, PasswordViolation{Rule: PasswordRuleDigit})
+	}
+	if pp.requireSymbol && !hasSymbol {
+		violations = append(violations, PasswordViolation{Rule: PasswordRuleSymbol})
+	}
+
+	lower := strings.ToLower(password)
+	if commonPasswords[lower] {
+		violations = append(violations, PasswordViolation{Rule: PasswordRuleCommon})
+	}
+
+	localPart := email
+	if idx := strings.Index(email, "@"); idx > 0 {
+		localPart = email[:idx]
+	}
+	for _, personal := range []string{username, localPart} {
+		personal = strings.ToLower(personal)
+		if len(personal) >= 3 && strings.Contains(lower, personal) {
+			violations = append(violations, PasswordViolation{Rule: PasswordRulePersonal})
+			break
+		}
+	}
+
+	// Only hit the breach API when the password passes the local rules
+	if len(violations) == 0 && pp.checkBreached {
+		breached, err := pp.isBreached(password)
+		if err != nil {
+			log.Printf("⚠️ Password breach check failed, skipping: %v", err)
+		} else if breached {
+			violations = append(violations, PasswordViolation{Rule: PasswordRuleBreached})
+		}
+	}
+
+	return violations
+}
+
+// isBreached checks the password against HaveIBeenPwned using k-anonymity:
+// only the first 5 characters of the SHA-1 hash are sent to the API
+func (pp *PasswordPolicyService) isBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequest("GET", pp.pwnedAPIURL+prefix, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := pp.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to call breach API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("breach API returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		// Each line is "<HASH SUFFIX>:<COUNT>", padded entries have a count of 0
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), ":", 2)
+		if len(parts) != 2 || parts[0] != suffix {
+			continue
+		}
+		count, _ := strconv.Atoi(parts[1])
+		return count > 0, nil
+	}
+
+	return false, scanner.Err()
+}
+
+// getEnvInt reads an integer environment variable with a default
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvBool reads a boolean environment variable with a default
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
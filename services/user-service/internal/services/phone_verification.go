@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"user-service/internal/i18n"
+)
+
+// ErrInvalidPhoneNumber is returned for numbers that can't be normalized to E.164
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// PhoneVerificationService normalizes phone numbers and sends their verification codes
+type PhoneVerificationService struct {
+	provider    SMSProvider
+	countryCode string
+	codeTTL     time.Duration
+	maxAttempts int
+}
+
+// NewPhoneVerificationService creates a phone verification service sending through provider.
+// PHONE_DEFAULT_COUNTRY_CODE (62) completes local numbers, codes expire after PHONE_OTP_TTL
+// (5m) and are discarded after PHONE_OTP_MAX_ATTEMPTS (5) wrong guesses.
+func NewPhoneVerificationService(provider SMSProvider) *PhoneVerificationService {
+	countryCode := strings.TrimPrefix(os.Getenv("PHONE_DEFAULT_COUNTRY_CODE"), "+")
+	if countryCode == "" {
+		countryCode = "62"
+	}
+
+	codeTTL := 5 * time.Minute
+	if value := os.Getenv("PHONE_OTP_TTL"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			codeTTL = parsed
+		} else {
+			log.Printf("⚠️ Ignoring invalid PHONE_OTP_TTL=%q", value)
+		}
+	}
+
+	maxAttempts := 5
+	if value := os.Getenv("PHONE_OTP_MAX_ATTEMPTS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			maxAttempts = parsed
+		} else {
+			log.Printf("⚠️ Ignoring invalid PHONE_OTP_MAX_ATTEMPTS=%q", value)
+		}
+	}
+
+	return &PhoneVerificationService{
+		provider:    provider,
+		countryCode: countryCode,
+		codeTTL:     codeTTL,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// ProviderName returns the name of the SMS provider
+func (ps *PhoneVerificationService) ProviderName() string {
+	return ps.provider.Name()
+}
+
+// CodeTTL returns how long a verification code is valid
+func (ps *PhoneVerificationService) CodeTTL() time.Duration {
+	return ps.codeTTL
+}
+
+// MaxAttempts returns how many wrong codes are accepted before the code is discarded
+func (ps *PhoneVerificationService) MaxAttempts() int {
+	return ps.maxAttempts
+}
+
+// Normalize converts a phone number to E.164. Spaces, dashes, dots and parentheses are
+// ignored, "00" is read as "+" and numbers without a country code get the default one
+// (0812... becomes +62812...).
+func (ps *PhoneVerificationService) Normalize(raw string) (string, error) {
+	number := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(raw))
+
+	switch {
+	case number == "":
+		return "", ErrInvalidPhoneNumber
+	case strings.HasPrefix(number, "+"):
+	case strings.HasPrefix(number, "00"):
+		number = "+" + number[2:]
+	case strings.HasPrefix(number, "0"):
+		number = "+" + ps.countryCode + number[1:]
+	case strings.HasPrefix(number, ps.countryCode):
+		number = "+" + number
+	default:
+		number = "+" + ps.countryCode + number
+	}
+
+	if !e164Pattern.MatchString(number) {
+		return "", ErrInvalidPhoneNumber
+	}
+	return number, nil
+}
+
+// SendCode sends a verification code over channel (sms or whatsapp) in the user's locale
+func (ps *PhoneVerificationService) SendCode(ctx context.Context, channel, phoneNumber, code, locale string) error {
+	minutes := int(ps.codeTTL.Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	message := i18n.T(i18n.Locale(locale), "sms.phone_otp", code, minutes)
+
+	if err := ps.provider.Send(ctx, channel, phoneNumber, message); err != nil {
+		return fmt.Errorf("failed to send code via %s: %w", ps.provider.Name(), err)
+	}
+	return nil
+}
+
+// IsCodeExpired reports whether a code issued at issuedAt can no longer be used
+func (ps *PhoneVerificationService) IsCodeExpired(issuedAt *time.Time) bool {
+	return issuedAt == nil || time.Since(*issuedAt) > ps.codeTTL
+}
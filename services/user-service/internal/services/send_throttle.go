@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"user-service/internal/redact"
+)
+
+// Throttled actions
+const (
+	ThrottleResendOTP     = "resend_otp"
+	ThrottleResetPassword = "reset_password"
+	ThrottlePhoneOTP      = "phone_otp" // keyed by phone number instead of email
+)
+
+// RateLimitStore counts attempts per key in fixed windows. cache.RedisService implements it
+// so limits hold across instances; MemoryRateLimitStore is the per-instance fallback.
+type RateLimitStore interface {
+	IncrementRateLimit(ctx context.Context, key string, window time.Duration) (int, error)
+	RateLimitTTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// ThrottleResult tells the client how many sends are left and when it may retry
+type ThrottleResult struct {
+	Allowed    bool
+	Remaining  int           // sends left for the email in the current window
+	RetryAfter time.Duration // until the blocking window resets, set when not allowed
+	Reason     string        // "email" or "ip" when not allowed
+}
+
+// SendThrottle limits emails triggered by unauthenticated requests (OTP resends, reset codes)
+// per recipient and per client IP, so nobody can bombard a victim's inbox
+type SendThrottle struct {
+	store      RateLimitStore
+	emailLimit int
+	ipLimit    int
+	window     time.Duration
+
+	mu    sync.Mutex
+	stats map[string]int64 // "<action>.<allowed|blocked_email|blocked_ip|alerts>"
+}
+
+// sendThrottleVars publishes throttle counters on /debug/vars
+var sendThrottleVars = expvar.NewMap("send_throttle")
+
+// NewSendThrottle creates a send throttle from SEND_LIMIT_PER_EMAIL (3), SEND_LIMIT_PER_IP (10)
+// and SEND_LIMIT_WINDOW (15m)
+func NewSendThrottle(store RateLimitStore) *SendThrottle {
+	window := 15 * time.Minute
+	if value := os.Getenv("SEND_LIMIT_WINDOW"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			window = parsed
+		} else {
+			log.Printf("⚠️ Ignoring invalid SEND_LIMIT_WINDOW=%q", value)
+		}
+	}
+
+	return &SendThrottle{
+		store:      store,
+		emailLimit: getEnvInt("SEND_LIMIT_PER_EMAIL", 3),
+		ipLimit:    getEnvInt("SEND_LIMIT_PER_IP", 10),
+		window:     window,
+		stats:      make(map[string]int64),
+	}
+}
+
+// Window returns the throttling window
+func (st *SendThrottle) Window() time.Duration {
+	return st.window
+}
+
+// Allow records a send attempt for action and reports whether it may go ahead. The IP is
+// checked first so one client can't probe many addresses. Store errors let the send through.
+func (st *SendThrottle) Allow(ctx context.Context, action, email, ip string) ThrottleResult {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	if ip != "" {
+		ipKey := fmt.Sprintf("throttle:%s:ip:%s", action, ip)
+		if result, blocked := st.check(ctx, action, ipKey, st.ipLimit, "ip", ip); blocked {
+			return result
+		}
+	}
+
+	emailKey := fmt.Sprintf("throttle:%s:email:%s", action, email)
+	result, blocked := st.check(ctx, action, emailKey, st.emailLimit, "email", redact.Email(email))
+	if blocked {
+		return result
+	}
+
+	st.record(action, "allowed")
+	return result
+}
+
+// check counts an attempt against key and reports whether it exceeds limit
+func (st *SendThrottle) check(ctx context.Context, action, key string, limit int, reason, subject string) (ThrottleResult, bool) {
+	count, err := st.store.IncrementRateLimit(ctx, key, st.window)
+	if err != nil {
+		log.Printf("⚠️ Send throttle unavailable, allowing %s: %v", action, err)
+		return ThrottleResult{Allowed: true, Remaining: limit}, false
+	}
+
+	if count <= limit {
+		return ThrottleResult{Allowed: true, Remaining: limit - count}, false
+	}
+
+	retryAfter, err := st.store.RateLimitTTL(ctx, key)
+	if err != nil || retryAfter <= 0 {
+		retryAfter = st.window
+	}
+
+	st.record(action, "blocked_"+reason)
+	// Sustained hammering well past the limit looks like abuse rather than an impatient user
+	if count == limit*3 {
+		st.record(action, "alerts")
+		log.Printf("🚨 Possible %s abuse: %d attempts from %s %s within %s", action, count, reason, subject, st.window)
+	}
+
+	return ThrottleResult{Allowed: false, RetryAfter: retryAfter, Reason: reason}, true
+}
+
+// record counts an outcome in the stats and on expvar
+func (st *SendThrottle) record(action, outcome string) {
+	key := action + "." + outcome
+	st.mu.Lock()
+	st.stats[key]++
+	st.mu.Unlock()
+	sendThrottleVars.Add(key, 1)
+}
+
+// Stats returns the outcome counters since startup
+func (st *SendThrottle) Stats() map[string]interface{} {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	stats := map[string]interface{}{
+		"limit_per_email": st.emailLimit,
+		"limit_per_ip":    st.ipLimit,
+		"window":          st.window.String(),
+	}
+	for key, value := range st.stats {
+		stats[key] = value
+	}
+	return stats
+}
+
+// MemoryRateLimitStore is a per-instance RateLimitStore for deployments without Redis
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	counters  map[string]*memoryCounter
+	lastSweep time.Time
+}
+
+type memoryCounter struct {
+	count     int
+	expiresAt time.Time
+}
+
+// NewMemoryRateLimitStore creates an in-memory rate limit store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{counters: make(map[string]*memoryCounter), lastSweep: time.Now()}
+}
+
+// IncrementRateLimit increments the counter of key, starting a window on the first increment
+func (ms *MemoryRateLimitStore) IncrementRateLimit(ctx context.Context, key string, window time.Duration) (int, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(ms.lastSweep) > time.Minute {
+		for k, counter := range ms.counters {
+			if now.After(counter.expiresAt) {
+				delete(ms.counters, k)
+			}
+		}
+		ms.lastSweep = now
+	}
+
+	counter, ok := ms.counters[key]
+	if !ok || now.After(counter.expiresAt) {
+		counter = &memoryCounter{expiresAt: now.Add(window)}
+		ms.counters[key] = counter
+	}
+	counter.count++
+	return counter.count, nil
+}
+
+// RateLimitTTL returns how long until the counter of key resets
+func (ms *MemoryRateLimitStore) RateLimitTTL(ctx context.Context, key string) (time.Duration, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	counter, ok := ms.counters[key]
+	if !ok {
+		return 0, nil
+	}
+	if ttl := time.Until(counter.expiresAt); ttl > 0 {
+		return ttl, nil
+	}
+	return 0, nil
+}
+
+// RetryAfterSeconds returns RetryAfter in whole seconds, rounded up for Retry-After
+func (tr ThrottleResult) RetryAfterSeconds() int {
+	seconds := int(tr.RetryAfter / time.Second)
+	if tr.RetryAfter%time.Second != 0 {
+		seconds++
+	}
+	return seconds
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"user-service/internal/redact"
+)
+
+// SMS delivery channels
+const (
+	SMSChannelSMS      = "sms"
+	SMSChannelWhatsApp = "whatsapp"
+)
+
+// SMSProvider delivers text messages to phone numbers in E.164 format
+type SMSProvider interface {
+	Name() string
+	Send(ctx context.Context, channel, to, message string) error
+}
+
+// NewSMSProviderFromEnv creates the provider selected by SMS_PROVIDER: "log" (default,
+// prints messages for development) or "twilio" (TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN,
+// TWILIO_SMS_FROM and TWILIO_WHATSAPP_FROM)
+func NewSMSProviderFromEnv() (SMSProvider, error) {
+	switch provider := strings.ToLower(os.Getenv("SMS_PROVIDER")); provider {
+	case "", "log":
+		return LogSMSProvider{}, nil
+	case "twilio":
+		return NewTwilioSMSProvider(
+			os.Getenv("TWILIO_ACCOUNT_SID"),
+			os.Getenv("TWILIO_AUTH_TOKEN"),
+			os.Getenv("TWILIO_SMS_FROM"),
+			os.Getenv("TWILIO_WHATSAPP_FROM"),
+		)
+	default:
+		return nil, fmt.Errorf("unsupported SMS_PROVIDER %q, expected log or twilio", provider)
+	}
+}
+
+// LogSMSProvider prints messages instead of sending them, for development
+type LogSMSProvider struct{}
+
+// Name returns the provider name
+func (LogSMSProvider) Name() string {
+	return "log"
+}
+
+// Send logs the message
+func (LogSMSProvider) Send(ctx context.Context, channel, to, message string) error {
+	log.Printf("📱 [%s to %s] %s", channel, redact.Tail(to), message)
+	return nil
+}
+
+// TwilioSMSProvider sends SMS and WhatsApp messages through the Twilio Messages API
+type TwilioSMSProvider struct {
+	accountSID   string
+	authToken    string
+	smsFrom      string
+	whatsAppFrom string
+	baseURL      string
+	client       *http.Client
+}
+
+// NewTwilioSMSProvider creates a Twilio provider; whatsAppFrom may be empty to only send SMS
+func NewTwilioSMSProvider(accountSID, authToken, smsFrom, whatsAppFrom string) (*TwilioSMSProvider, error) {
+	if accountSID == "" || authToken == "" || smsFrom == "" {
+		return nil, fmt.Errorf("TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_SMS_FROM are required")
+	}
+
+	return &TwilioSMSProvider{
+		accountSID:   accountSID,
+		authToken:    authToken,
+		smsFrom:      smsFrom,
+		whatsAppFrom: whatsAppFrom,
+		baseURL:      "https://api.twilio.com/2010-04-01",
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name returns the provider name
+func (tp *TwilioSMSProvider) Name() string {
+	return "twilio"
+}
+
+// Send posts the message to Twilio, prefixing both numbers with "whatsapp:" for WhatsApp
+func (tp *TwilioSMSProvider) Send(ctx context.Context, channel, to, message string) error {
+	from := tp.smsFrom
+	if channel == SMSChannelWhatsApp {
+		if tp.whatsAppFrom == "" {
+			return fmt.Errorf("WhatsApp delivery is not configured (TWILIO_WHATSAPP_FROM)")
+		}
+		from = "whatsapp:" + tp.whatsAppFrom
+		to = "whatsapp:" + to
+	}
+
+	form := url.Values{}
+	form.Set("From", from)
+	form.Set("To", to)
+	form.Set("Body", message)
+
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", tp.baseURL, tp.accountSID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create Twilio request: %w", err)
+	}
+	req.SetBasicAuth(tp.accountSID, tp.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := tp.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Twilio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("twilio returned status %d: %s", resp.StatusCode, redact.JSON(body))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"os"
+	"strings"
+)
+
+// Username policy rules
+const (
+	UsernameRuleMinLength  = "min_length"
+	UsernameRuleMaxLength  = "max_length"
+	UsernameRuleCharacters = "characters"
+	UsernameRuleReserved   = "reserved"
+)
+
+// UsernameViolation describes a single failed username rule
+type UsernameViolation struct {
+	Rule  string `json:"rule"`
+	Param int    `json:"param,omitempty"`
+}
+
+// UsernamePolicyService normalizes usernames and validates them against the policy
+type UsernamePolicyService struct {
+	minLength int
+	maxLength int
+	reserved  map[string]bool
+}
+
+// reservedUsernames could be mistaken for the platform, its staff or its routes
+var reservedUsernames = []string{
+	"admin", "administrator", "root", "system", "sysadmin", "superuser", "moderator", "mod", "staff",
+	"support", "help", "helpdesk", "info", "contact", "security", "abuse", "noreply", "no-reply",
+	"official", "zacloth", "api", "www", "mail", "email", "webmaster", "postmaster", "billing",
+	"payment", "payments", "seller", "sellers", "store", "stores", "shop", "checkout", "cart",
+	"login", "logout", "register", "signup", "signin", "settings", "profile", "account", "me",
+	"user", "users", "null", "undefined", "anonymous", "guest", "test",
+}
+
+// NewUsernamePolicyService creates a username policy from environment configuration.
+// USERNAME_RESERVED adds comma separated words to the built-in reserved list.
+func NewUsernamePolicyService() *UsernamePolicyService {
+	reserved := make(map[string]bool, len(reservedUsernames))
+	for _, word := range reservedUsernames {
+		reserved[word] = true
+	}
+	for _, word := range strings.Split(os.Getenv("USERNAME_RESERVED"), ",") {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			reserved[word] = true
+		}
+	}
+
+	return &UsernamePolicyService{
+		minLength: getEnvInt("USERNAME_MIN_LENGTH", 3),
+		maxLength: getEnvInt("USERNAME_MAX_LENGTH", 30),
+		reserved:  reserved,
+	}
+}
+
+// Normalize returns the stored form of a username: trimmed and lowercased, so "John" and
+// "john" can't both be registered
+func (up *UsernamePolicyService) Normalize(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// Validate checks a normalized username and returns every rule it violates. Usernames are
+// letters, digits, ".", "_" and "-", starting and ending with a letter or digit.
+func (up *UsernamePolicyService) Validate(username string) []UsernameViolation {
+	var violations []UsernameViolation
+
+	length := len([]rune(username))
+	if length < up.minLength {
+		violations = append(violations, UsernameViolation{Rule: UsernameRuleMinLength, Param: up.minLength})
+	}
+	if length > up.maxLength {
+		violations = append(violations, UsernameViolation{Rule: UsernameRuleMaxLength, Param: up.maxLength})
+	}
+
+	if !validUsernameCharacters(username) {
+		violations = append(violations, UsernameViolation{Rule: UsernameRuleCharacters})
+	}
+
+	if up.reserved[strings.Trim(username, "._-")] {
+		violations = append(violations, UsernameViolation{Rule: UsernameRuleReserved})
+	}
+
+	return violations
+}
+
+// validUsernameCharacters reports whether username only uses a-z, 0-9, ".", "_" and "-"
+// and starts and ends with a letter or digit
+func validUsernameCharacters(username string) bool {
+	for i, char := range username {
+		switch {
+		case char >= 'a' && char <= 'z', char >= '0' && char <= '9':
+		case (char == '.' || char == '_' || char == '-') && i > 0 && i < len(username)-1:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"user-service/internal/redact"
+)
+
+// Guarded actions, for stats and logs
+const (
+	GuardVerifyOTP       = "verify_otp"
+	GuardVerifyResetCode = "verify_reset_password"
+	GuardLogin           = "login"
+	GuardRegister        = "register"
+)
+
+// lockoutMemory is how long a lockout counts towards the length of the next one
+const lockoutMemory = 24 * time.Hour
+
+// captchaFlagTTL is how long a suspicious client or account has to solve a CAPTCHA
+const captchaFlagTTL = time.Hour
+
+// VerificationGuard defends OTP verification against guessing. Wrong codes are counted per
+// email and per client IP in OTP_LOCKOUT_WINDOW (15m); after OTP_LOCKOUT_PER_EMAIL (5) or
+// OTP_LOCKOUT_PER_IP (20) verification is locked for OTP_LOCKOUT_DURATION (15m), doubled for
+// each earlier lockout within a day up to OTP_LOCKOUT_MAX (24h).
+//
+// Failed verifications and logins also count towards CAPTCHA_AFTER_FAILURES (3) per IP and
+// per account; once reached, register and login from that IP or for that account need a
+// CAPTCHA token for an hour. Without a CaptchaVerifier no CAPTCHA is asked for.
+type VerificationGuard struct {
+	store        RateLimitStore
+	captcha      CaptchaVerifier
+	emailLimit   int
+	ipLimit      int
+	window       time.Duration
+	lockout      time.Duration
+	maxLockout   time.Duration
+	captchaAfter int
+
+	mu    sync.Mutex
+	stats map[string]int64 // "<action>.<failed|lockouts|locked_email|locked_ip>", "captcha.<outcome>"
+}
+
+// verificationGuardVars publishes guard counters on /debug/vars
+var verificationGuardVars = expvar.NewMap("verification_guard")
+
+// NewVerificationGuard creates a guard keeping its counters in store, captcha may be nil
+func NewVerificationGuard(store RateLimitStore, captcha CaptchaVerifier) *VerificationGuard {
+	return &VerificationGuard{
+		store:        store,
+		captcha:      captcha,
+		emailLimit:   getEnvInt("OTP_LOCKOUT_PER_EMAIL", 5),
+		ipLimit:      getEnvInt("OTP_LOCKOUT_PER_IP", 20),
+		window:       getEnvDuration("OTP_LOCKOUT_WINDOW", 15*time.Minute),
+		lockout:      getEnvDuration("OTP_LOCKOUT_DURATION", 15*time.Minute),
+		maxLockout:   getEnvDuration("OTP_LOCKOUT_MAX", 24*time.Hour),
+		captchaAfter: getEnvInt("CAPTCHA_AFTER_FAILURES", 3),
+		stats:        make(map[string]int64),
+	}
+}
+
+// SetCaptcha replaces the CAPTCHA verifier, nil stops asking for CAPTCHAs
+func (vg *VerificationGuard) SetCaptcha(captcha CaptchaVerifier) {
+	vg.captcha = captcha
+}
+
+// Locked reports whether verification is locked for the email or the IP, with how long the
+// lockout lasts. The IP is checked first, like SendThrottle. Store errors don't lock anyone.
+func (vg *VerificationGuard) Locked(ctx context.Context, action, email, ip string) ThrottleResult {
+	if ip != "" {
+		if ttl := vg.ttl(ctx, "otp_lock:ip:"+ip); ttl > 0 {
+			vg.record(action, "locked_ip")
+			return ThrottleResult{Allowed: false, RetryAfter: ttl, Reason: "ip"}
+		}
+	}
+	if ttl := vg.ttl(ctx, "otp_lock:email:"+normalizeEmail(email)); ttl > 0 {
+		vg.record(action, "locked_email")
+		return ThrottleResult{Allowed: false, RetryAfter: ttl, Reason: "email"}
+	}
+	return ThrottleResult{Allowed: true}
+}
+
+// Failed counts a wrong code for the email from ip, locking verification once either limit
+// is reached. Remaining is the number of wrong codes the email has left.
+func (vg *VerificationGuard) Failed(ctx context.Context, action, email, ip string) ThrottleResult {
+	vg.record(action, "failed")
+	email = normalizeEmail(email)
+	vg.suspicious(ctx, email, ip)
+
+	if ip != "" {
+		if count, ok := vg.increment(ctx, "otp_fail:ip:"+ip, vg.window); ok && count >= vg.ipLimit {
+			return vg.lock(ctx, action, "ip", ip, ip)
+		}
+	}
+
+	count, ok := vg.increment(ctx, "otp_fail:email:"+email, vg.window)
+	if !ok {
+		return ThrottleResult{Allowed: true, Remaining: vg.emailLimit}
+	}
+	if count >= vg.emailLimit {
+		return vg.lock(ctx, action, "email", email, redact.Email(email))
+	}
+	return ThrottleResult{Allowed: true, Remaining: vg.emailLimit - count}
+}
+
+// LoginFailed counts a failed login for the account (email or username) towards asking for
+// a CAPTCHA
+func (vg *VerificationGuard) LoginFailed(ctx context.Context, account, ip string) {
+	vg.record(GuardLogin, "failed")
+	vg.suspicious(ctx, normalizeEmail(account), ip)
+}
+
+// CheckCaptcha verifies the CAPTCHA token of a register or login request when the IP or the
+// account was flagged as suspicious. It returns ErrCaptchaRequired or ErrCaptchaInvalid for
+// the client; requests pass when no verifier is configured or the provider can't be reached.
+func (vg *VerificationGuard) CheckCaptcha(ctx context.Context, action, account, ip, token string) error {
+	if vg.captcha == nil || !vg.CaptchaRequired(ctx, account, ip) {
+		return nil
+	}
+
+	err := vg.captcha.Verify(ctx, token, ip)
+	switch {
+	case err == nil:
+		vg.record("captcha", "passed")
+		return nil
+	case errors.Is(err, ErrCaptchaRequired) || errors.Is(err, ErrCaptchaInvalid):
+		vg.record("captcha", "rejected")
+		log.Printf("🛡️ CAPTCHA for %s from %s rejected: %v", action, ip, err)
+		return err
+	default:
+		vg.record("captcha", "unavailable")
+		log.Printf("⚠️ CAPTCHA provider unavailable, allowing %s from %s: %v", action, ip, err)
+		return nil
+	}
+}
+
+// CaptchaRequired reports whether the IP or the account has to solve a CAPTCHA
+func (vg *VerificationGuard) CaptchaRequired(ctx context.Context, account, ip string) bool {
+	if vg.captcha == nil {
+		return false
+	}
+	if ip != "" && vg.ttl(ctx, "captcha:ip:"+ip) > 0 {
+		return true
+	}
+	account = normalizeEmail(account)
+	return account != "" && vg.ttl(ctx, "captcha:account:"+account) > 0
+}
+
+// CaptchaProvider names the configured CAPTCHA provider, empty without one
+func (vg *VerificationGuard) CaptchaProvider() string {
+	if vg.captcha == nil {
+		return ""
+	}
+	return vg.captcha.Name()
+}
+
+// suspicious counts a failure of the account and the IP, flagging them for a CAPTCHA once
+// CAPTCHA_AFTER_FAILURES is reached
+func (vg *VerificationGuard) suspicious(ctx context.Context, account, ip string) {
+	subjects := map[string]string{"account": account, "ip": ip}
+	for kind, subject := range subjects {
+		if subject == "" {
+			continue
+		}
+		count, ok := vg.increment(ctx, fmt.Sprintf("auth_fail:%s:%s", kind, subject), vg.window)
+		if ok && count == vg.captchaAfter {
+			vg.increment(ctx, fmt.Sprintf("captcha:%s:%s", kind, subject), captchaFlagTTL)
+		}
+	}
+}
+
+// lock locks verification for subject, longer for each lockout it had within lockoutMemory
+func (vg *VerificationGuard) lock(ctx context.Context, action, kind, subject, logSubject string) ThrottleResult {
+	lockKey := fmt.Sprintf("otp_lock:%s:%s", kind, subject)
+	if ttl := vg.ttl(ctx, lockKey); ttl > 0 {
+		return ThrottleResult{Allowed: false, RetryAfter: ttl, Reason: kind} // already locked
+	}
+
+	level, ok := vg.increment(ctx, fmt.Sprintf("otp_lockouts:%s:%s", kind, subject), lockoutMemory)
+	if !ok {
+		level = 1
+	}
+	duration := vg.lockout
+	for i := 1; i < level && duration < vg.maxLockout; i++ {
+		duration *= 2
+	}
+	if duration > vg.maxLockout {
+		duration = vg.maxLockout
+	}
+
+	vg.increment(ctx, lockKey, duration)
+	vg.record(action, "lockouts")
+	log.Printf("🔒 OTP verification locked for %s %s for %s (lockout %d today)", kind, logSubject, duration, level)
+	return ThrottleResult{Allowed: false, RetryAfter: duration, Reason: kind}
+}
+
+// increment counts key in a window starting now, reporting false when the store failed
+func (vg *VerificationGuard) increment(ctx context.Context, key string, window time.Duration) (int, bool) {
+	count, err := vg.store.IncrementRateLimit(ctx, key, window)
+	if err != nil {
+		log.Printf("⚠️ Verification guard unavailable: %v", err)
+		return 0, false
+	}
+	return count, true
+}
+
+// ttl returns how long key lasts, 0 when it doesn't exist or the store failed
+func (vg *VerificationGuard) ttl(ctx context.Context, key string) time.Duration {
+	ttl, err := vg.store.RateLimitTTL(ctx, key)
+	if err != nil {
+		log.Printf("⚠️ Verification guard unavailable: %v", err)
+		return 0
+	}
+	return ttl
+}
+
+// record counts an outcome in the stats and on expvar
+func (vg *VerificationGuard) record(action, outcome string) {
+	key := action + "." + outcome
+	vg.mu.Lock()
+	vg.stats[key]++
+	vg.mu.Unlock()
+	verificationGuardVars.Add(key, 1)
+}
+
+// Stats returns the limits and the outcome counters since startup
+func (vg *VerificationGuard) Stats() map[string]interface{} {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+
+	stats := map[string]interface{}{
+		"lockout_per_email":      vg.emailLimit,
+		"lockout_per_ip":         vg.ipLimit,
+		"window":                 vg.window.String(),
+		"lockout":                vg.lockout.String(),
+		"max_lockout":            vg.maxLockout.String(),
+		"captcha_provider":       vg.CaptchaProvider(),
+		"captcha_after_failures": vg.captchaAfter,
+	}
+	for key, value := range vg.stats {
+		stats[key] = value
+	}
+	return stats
+}
+
+// normalizeEmail lowercases and trims an email or username for use in keys
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}